@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The admission-policy command generates the ValidatingAdmissionPolicies
+// enforcing the structural validations of the webhook, in
+// v1alpha1.AdmissionRules, for clusters to keep enforcing them when the
+// webhook is unavailable.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+const header = `# Copyright 2019 The Tekton Authors
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     https://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Code generated by cmd/admission-policy. DO NOT EDIT.
+`
+
+var output = flag.String("output", "", "The file to write the policies to, stdout if empty")
+
+// policyPrefix is the prefix of the names of the policies, which are
+// followed by the resource they apply to.
+const policyPrefix = "validation.pipeline.tekton.dev"
+
+type validation struct {
+	Expression string `json:"expression"`
+	Message    string `json:"message"`
+}
+
+type resourceRule struct {
+	APIGroups   []string `json:"apiGroups"`
+	APIVersions []string `json:"apiVersions"`
+	Operations  []string `json:"operations"`
+	Resources   []string `json:"resources"`
+}
+
+type object struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	Spec       map[string]interface{} `json:"spec"`
+}
+
+func main() {
+	flag.Parse()
+
+	b, err := generate(v1alpha1.AdmissionRules)
+	if err != nil {
+		log.Fatalf("Error generating the admission policies: %v", err)
+	}
+	if *output == "" {
+		if _, err := os.Stdout.Write(b); err != nil {
+			log.Fatalf("Error writing the admission policies: %v", err)
+		}
+		return
+	}
+	if err := ioutil.WriteFile(*output, b, 0644); err != nil {
+		log.Fatalf("Error writing the admission policies to %s: %v", *output, err)
+	}
+}
+
+// generate returns the YAML of a ValidatingAdmissionPolicy, and of its
+// binding, per resource the rules apply to, in the order they first appear.
+func generate(rules []v1alpha1.AdmissionRule) ([]byte, error) {
+	var resources []string
+	validations := map[string][]validation{}
+	for _, r := range rules {
+		for _, res := range r.Resources {
+			if _, ok := validations[res]; !ok {
+				resources = append(resources, res)
+			}
+			validations[res] = append(validations[res], validation{Expression: r.Expression, Message: r.Message})
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	for _, res := range resources {
+		name := res + "." + policyPrefix
+		metadata := map[string]interface{}{
+			"name":   name,
+			"labels": map[string]string{"app.kubernetes.io/name": "tekton-pipelines"},
+		}
+		for _, obj := range []object{{
+			APIVersion: "admissionregistration.k8s.io/v1",
+			Kind:       "ValidatingAdmissionPolicy",
+			Metadata:   metadata,
+			Spec: map[string]interface{}{
+				"failurePolicy": "Fail",
+				"matchConstraints": map[string]interface{}{
+					"resourceRules": []resourceRule{{
+						APIGroups:   []string{pipeline.GroupName},
+						APIVersions: []string{v1alpha1.SchemeGroupVersion.Version},
+						Operations:  []string{"CREATE", "UPDATE"},
+						Resources:   []string{res},
+					}},
+				},
+				"validations": validations[res],
+			},
+		}, {
+			APIVersion: "admissionregistration.k8s.io/v1",
+			Kind:       "ValidatingAdmissionPolicyBinding",
+			Metadata:   metadata,
+			Spec: map[string]interface{}{
+				"policyName":        name,
+				"validationActions": []string{"Deny"},
+			},
+		}} {
+			b, err := yaml.Marshal(obj)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString("---\n")
+			buf.Write(b)
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+// TestGeneratedPolicies checks that the policies in config/admission-policy
+// were generated from the current v1alpha1.AdmissionRules.
+func TestGeneratedPolicies(t *testing.T) {
+	want, err := generate(v1alpha1.AdmissionRules)
+	if err != nil {
+		t.Fatalf("Error generating the admission policies: %v", err)
+	}
+	got, err := ioutil.ReadFile("../../config/admission-policy/policies.yaml")
+	if err != nil {
+		t.Fatalf("Error reading the generated admission policies: %v", err)
+	}
+	if d := cmp.Diff(string(want), string(got)); d != "" {
+		t.Errorf("config/admission-policy/policies.yaml is out of date, run ./hack/update-codegen.sh: %s", d)
+	}
+}
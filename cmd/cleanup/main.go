@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
+	"knative.dev/pkg/logging"
+)
+
+var (
+	kubeconfig        = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	namespace         = flag.String("namespace", "", "The namespace to clean up, all of them if empty")
+	ttl               = flag.Duration("ttl", 0, "The TTL applied to finished TaskRuns without an expirationSecondsTTL of their own, e.g. 168h. If zero, only TaskRuns with a TTL are deleted")
+	selector          = flag.String("selector", "", "If set, only delete the TaskRuns matching this label selector")
+	excludeNamespaces = flag.String("exclude-namespaces", "", "A comma separated list of namespaces in which TaskRuns are never deleted")
+	qps               = flag.Float64("qps", 5, "The maximum number of deletions per second")
+	dryRun            = flag.Bool("dry-run", false, "If set, only log the TaskRuns which would be deleted")
+)
+
+func main() {
+	flag.Parse()
+
+	// ignore atomic level because we are not watching this config for any updates
+	logger, _ := logging.NewLogger("", "cleanup")
+	defer logger.Sync()
+
+	scope, err := taskrun.ParseExpirationScope(*selector, *excludeNamespaces)
+	if err != nil {
+		logger.Fatalf("Invalid -selector %q: %v", *selector, err)
+	}
+	cfg, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err != nil {
+		logger.Fatalf("Error building kubeconfig: %v", err)
+	}
+	client, err := versioned.NewForConfig(cfg)
+	if err != nil {
+		logger.Fatalf("Error building pipeline clientset: %v", err)
+	}
+
+	deleted, err := taskrun.Sweep(client, taskrun.SweepOptions{
+		Namespace: *namespace,
+		TTL:       *ttl,
+		Scope:     scope,
+		Limiter:   flowcontrol.NewTokenBucketRateLimiter(float32(*qps), 1),
+		DryRun:    *dryRun,
+	}, logger)
+	if err != nil {
+		logger.Fatalf("Error cleaning up TaskRuns after deleting %d of them: %v", len(deleted), err)
+	}
+	logger.Infof("Cleaned up %d expired TaskRuns", len(deleted))
+}
@@ -18,20 +18,56 @@ package main
 
 import (
 	"flag"
+	"log"
+	"math"
+	"os"
+	"time"
 
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
 	"knative.dev/pkg/injection/sharedmain"
+	"knative.dev/pkg/signals"
+	"knative.dev/pkg/system"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
-	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/catalog"
+	"github.com/tektoncd/pipeline/pkg/reconciler/controllers"
+	"github.com/tektoncd/pipeline/pkg/reconciler/prepull"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
+	"github.com/tektoncd/pipeline/pkg/secrets"
+	"github.com/tektoncd/pipeline/pkg/secrets/vault"
 )
 
 const (
 	// ControllerLogKey is the name of the logger for the controller cmd
 	ControllerLogKey = "tekton"
+	// defaultUserAgent is the user agent of the requests of the controller
+	// to the API server, unless -user-agent is set.
+	defaultUserAgent = "tekton-pipelines-controller"
+	// defaultShutdownGracePeriod leaves some of the default termination
+	// grace period of the pod to exit once the reconciles are done.
+	defaultShutdownGracePeriod = 25 * time.Second
+	// cleanupLeaseName is the prefix of the names of the Leases of the
+	// buckets of the expiration controllers.
+	cleanupLeaseName = "tekton-pipelines-cleanup"
 )
 
 var (
+	masterURL = flag.String("master", "",
+		"The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	kubeconfig = flag.String("kubeconfig", "",
+		"Path to a kubeconfig. Only required if out-of-cluster.")
+	userAgent = flag.String("user-agent", defaultUserAgent,
+		"The user agent of the requests to the API server, to tell them apart in its audit logs and metrics.")
+	kubeAPIQPS = flag.Float64("kube-api-qps", 0,
+		"If set, the maximum number of requests per second to the API server, e.g. when API Priority and Fairness throttles the controller instead.")
+	kubeAPIBurst = flag.Int("kube-api-burst", 0,
+		"The maximum burst of requests to the API server when -kube-api-qps is set. Defaults to -kube-api-qps.")
+	shutdownGracePeriod = flag.Duration("shutdown-grace-period", defaultShutdownGracePeriod,
+		"How long to wait, once told to stop, for the queued and in flight reconciles to finish before exiting.")
 	entrypointImage = flag.String("entrypoint-image", "override-with-entrypoint:latest",
 		"The container image containing our entrypoint binary.")
 	nopImage = flag.String("nop-image", "override-with-nop:latest",
@@ -52,6 +88,34 @@ var (
 		"The container image containing our PR binary.")
 	imageDigestExporterImage = flag.String("imagedigest-exporter-image", "override-with-imagedigest-exporter-image:latest",
 		"The container image containing our image digest exporter binary.")
+	windowsShellImage = flag.String("windows-shell-image", "mcr.microsoft.com/powershell:lts-nanoserver-1809",
+		"The Windows container image containing PowerShell 7 (pwsh), used to place the scripts of the steps of Windows pods.")
+	vaultSecretsImage = flag.String("vault-secrets-image", "override-with-vault-secrets:latest",
+		"The container image containing our Vault secrets binary.")
+	vaultAddr = flag.String("vault-addr", "",
+		"If set, the URL of the Vault server steps can read secrets from with secretRefs.")
+	vaultAuthPath = flag.String("vault-auth-path", vault.DefaultAuthPath,
+		"The mount path of the Kubernetes auth method in Vault.")
+	vaultRole = flag.String("vault-role", "",
+		"The Vault role used to log in with the service account of the TaskRuns.")
+	prePullNodeSelector = flag.String("prepull-node-selector", "",
+		"If set, pre-pull the images of every Pipeline onto the nodes matching this label selector (e.g. ci=true).")
+	cleanupSelector = flag.String("cleanup-selector", "",
+		"If set, only delete the finished runs matching this label selector when their TTL elapses.")
+	cleanupExcludeNamespaces = flag.String("cleanup-exclude-namespaces", "",
+		"A comma separated list of namespaces in which finished runs are never deleted when their TTL elapses.")
+	cleanupDryRun = flag.Bool("cleanup-dry-run", false,
+		"If set, log and record an event for the finished runs which would be deleted, instead of deleting them.")
+	cleanupBuckets = flag.Int("cleanup-buckets", 0,
+		"If set, split the namespaces whose finished runs are deleted when their TTL elapses into this many buckets, each cleaned up by the replica of the controller holding its Lease, so that replicas running side by side don't delete the same runs twice. The other controllers aren't elected.")
+	cleanupLeaseDuration = flag.Duration("cleanup-lease-duration", reconciler.DefaultLeaseDuration,
+		"How long the buckets of a replica which stopped renewing their Leases wait before being taken over by the other replicas, when -cleanup-buckets is set.")
+	clusterTaskAccessReview = flag.Bool("clustertask-access-review", false,
+		"If set, fail the TaskRuns whose creators, or the creators of their PipelineRuns, aren't allowed to use the ClusterTask they reference.")
+	reconcileDebounce = flag.Duration("reconcile-debounce", 0,
+		"If set, reconcile the TaskRuns and PipelineRuns whose pods or TaskRuns keep being updated at most once per this duration, the last update always being reconciled.")
+	catalogVerification = flag.Bool("catalog-verification", false,
+		"If set, annotate Tasks and Pipelines with their checksum and whether they match the catalog pinned in config-catalog.")
 )
 
 func main() {
@@ -67,9 +131,91 @@ func main() {
 		BuildGCSFetcherImage:     *buildGCSFetcherImage,
 		PRImage:                  *prImage,
 		ImageDigestExporterImage: *imageDigestExporterImage,
+		WindowsShellImage:        *windowsShellImage,
+	}
+	if *vaultAddr != "" {
+		secrets.Register(vault.ProviderName, &vault.Provider{
+			Image:    *vaultSecretsImage,
+			Address:  *vaultAddr,
+			AuthPath: *vaultAuthPath,
+			Role:     *vaultRole,
+		})
+	}
+	expirationScope, err := taskrun.ParseExpirationScope(*cleanupSelector, *cleanupExcludeNamespaces)
+	if err != nil {
+		log.Fatalf("Invalid -cleanup-selector %q: %v", *cleanupSelector, err)
+	}
+	expirationScope.DryRun = *cleanupDryRun
+	var opts []reconciler.ControllerOption
+	if *clusterTaskAccessReview {
+		opts = append(opts, reconciler.WithClusterTaskAccessReview())
+	}
+	if *reconcileDebounce > 0 {
+		opts = append(opts, reconciler.WithDebounceInterval(*reconcileDebounce))
+	}
+	if *cleanupBuckets > 0 {
+		elector, err := reconciler.NewBucketElector(cleanupLeaseName, system.Namespace(), replicaIdentity(),
+			*cleanupBuckets, *cleanupLeaseDuration, clock.RealClock{})
+		if err != nil {
+			log.Fatalf("Invalid -cleanup-buckets %d: %v", *cleanupBuckets, err)
+		}
+		opts = append(opts, reconciler.WithBucketElector(elector))
+	}
+	ctors, err := controllers.Core(images, expirationScope, opts...)
+	if err != nil {
+		log.Fatalf("Error setting up the controllers: %v", err)
+	}
+	if *prePullNodeSelector != "" {
+		nodeSelector, err := labels.ConvertSelectorToLabelsMap(*prePullNodeSelector)
+		if err != nil {
+			log.Fatalf("Invalid -prepull-node-selector %q: %v", *prePullNodeSelector, err)
+		}
+		ctors = append(ctors, prepull.NewController(images, nodeSelector))
+	}
+	if *catalogVerification {
+		ctors = append(ctors, catalog.NewTaskController(images), catalog.NewPipelineController(images))
+	}
+	cfg, err := sharedmain.GetConfig(*masterURL, *kubeconfig)
+	if err != nil {
+		log.Fatalf("Error building kubeconfig: %v", err)
+	}
+	configureClient(cfg, *userAgent, *kubeAPIQPS, *kubeAPIBurst)
+	// MainWithConfig returns as soon as the controllers are told to stop,
+	// which stops the informers, without waiting for them.
+	drain := reconciler.NewDrain()
+	sharedmain.MainWithConfig(signals.NewContext(), ControllerLogKey, cfg, drain.Wrap(ctors...)...)
+	log.Printf("Finishing the queued and in flight reconciles, for at most %s", *shutdownGracePeriod)
+	if err := drain.Wait(*shutdownGracePeriod); err != nil {
+		log.Printf("Exiting with reconciles left after %s: %v", *shutdownGracePeriod, err)
+	}
+}
+
+// replicaIdentity returns the name of the pod of this replica of the
+// controller, or its host name outside of a pod.
+func replicaIdentity() string {
+	if name := os.Getenv("POD_NAME"); name != "" {
+		return name
+	}
+	name, err := os.Hostname()
+	if err != nil {
+		log.Fatalf("Error getting the host name of the controller: %v", err)
+	}
+	return name
+}
+
+// configureClient sets the user agent of the requests of cfg and, if qps is
+// set, replaces the client side rate limit sharedmain derives from the
+// number of controllers with qps and burst.
+func configureClient(cfg *rest.Config, userAgent string, qps float64, burst int) {
+	if userAgent != "" {
+		cfg.UserAgent = userAgent
+	}
+	if qps > 0 {
+		if burst <= 0 {
+			burst = int(math.Ceil(qps))
+		}
+		// The rate limiter takes precedence over QPS and Burst, which
+		// sharedmain overrides.
+		cfg.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(float32(qps), burst)
 	}
-	sharedmain.Main(ControllerLogKey,
-		taskrun.NewController(images),
-		pipelinerun.NewController(images),
-	)
 }
@@ -21,35 +21,111 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/entrypoint"
 )
 
 var (
-	ep              = flag.String("entrypoint", "", "Original specified entrypoint to execute")
-	waitFiles       = flag.String("wait_file", "", "Comma-separated list of paths to wait for")
-	waitFileContent = flag.Bool("wait_file_content", false, "If specified, expect wait_file to have content")
-	postFile        = flag.String("post_file", "", "If specified, file to write upon completion")
+	ep               = flag.String("entrypoint", "", "Original specified entrypoint to execute")
+	waitFiles        = flag.String("wait_file", "", "Comma-separated list of paths to wait for")
+	waitFileContent  = flag.Bool("wait_file_content", false, "If specified, expect wait_file to have content")
+	waitFileTimeout  = flag.Duration("wait_file_timeout", 0, "If specified, how long to wait for each wait_file before failing")
+	waitStartFile    = flag.String("wait_start_file", "", "If specified, wait_file_timeout only counts from when this file exists")
+	startFile        = flag.String("start_file", "", "If specified, file to write when the command starts")
+	timeout          = flag.Duration("timeout", 0, "If specified, how long the command may run before it is killed and the step fails")
+	postFile         = flag.String("post_file", "", "If specified, file to write upon completion")
+	skipExitCodes    = flag.String("skip_exit_codes", "", "Comma-separated list of exit codes which mean the step was skipped")
+	onError          = flag.String("on_error", "", "If continue, a non-zero exit code other than skip_exit_codes is written to termination_path and the step succeeds")
+	terminationPath  = flag.String("termination_path", "/dev/termination-log", "If specified, file to write the skipped result to")
+	stepsDir         = flag.String("steps_dir", "", "If specified, directory of the exit codes and results of the steps, which replace the $(steps.<name>.<field>) variables")
+	stepName         = flag.String("step_name", "", "If specified, name of the step whose exit code and results are written to steps_dir")
+	progressFile     = flag.String("progress_file", "", "If specified, file the step writes its progress to, which is reported in termination_path")
+	progressInterval = flag.Duration("progress_interval", 10*time.Second, "How often to report the progress written to progress_file, if it changed, so that it survives the step being killed")
+	progressStep     = flag.String("progress_step", "", "If specified, name of the step whose progress is reported")
+	results          = flag.String("results", "", "If specified, comma-separated list of the results of the Task, which are written to termination_path if the step writes them")
+	startChecksums   = flag.String("start_checksums", "", "If specified, comma-separated list of result=dir pairs, the checksum of each dir is written to termination_path as result before the command runs")
+	endChecksums     = flag.String("end_checksums", "", "If specified, comma-separated list of result=dir pairs, the checksum of each dir is written to termination_path as result once the command ran")
+	logTimestamps    = flag.Bool("log_timestamps", false, "If specified, prefix each line of the output of the step with the RFC3339 time it was written at")
+	logStepName      = flag.String("log_step_name", "", "If specified, prefix each line of the output of the step with this name in brackets")
 
 	waitPollingInterval = time.Second
 )
 
+// newRealRunner returns a realRunner which kills the commands after timeout,
+// and prefixes the lines of their output if timestamps or stepName is set.
+func newRealRunner(timeout time.Duration, timestamps bool, stepName string) *realRunner {
+	rr := &realRunner{timeout: timeout}
+	if timestamps || stepName != "" {
+		rr.stdout = &entrypoint.LinePrefixer{W: os.Stdout, Timestamps: timestamps, StepName: stepName}
+		rr.stderr = &entrypoint.LinePrefixer{W: os.Stderr, Timestamps: timestamps, StepName: stepName}
+	}
+	return rr
+}
+
+// parseChecksums returns the directories of the comma-separated result=dir
+// pairs of the checksums flag name, by result.
+func parseChecksums(name, checksums string) map[string]string {
+	if checksums == "" {
+		return nil
+	}
+	dirs := map[string]string{}
+	for _, pair := range strings.Split(checksums, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			log.Fatalf("Invalid result=dir pair %q in -%s", pair, name)
+		}
+		dirs[parts[0]] = parts[1]
+	}
+	return dirs
+}
+
 func main() {
 	flag.Parse()
 
+	var codes []int
+	if *skipExitCodes != "" {
+		for _, c := range strings.Split(*skipExitCodes, ",") {
+			code, err := strconv.Atoi(c)
+			if err != nil {
+				log.Fatalf("Invalid exit code %q in -skip_exit_codes: %v", c, err)
+			}
+			codes = append(codes, code)
+		}
+	}
+
 	e := entrypoint.Entrypointer{
 		Entrypoint:      *ep,
 		WaitFiles:       strings.Split(*waitFiles, ","),
 		WaitFileContent: *waitFileContent,
 		PostFile:        *postFile,
+		StartFile:       *startFile,
+		SkipExitCodes:   codes,
+		OnError:         v1alpha1.OnErrorType(*onError),
+		TerminationPath: *terminationPath,
+		StepsDir:        *stepsDir,
+		StepName:        *stepName,
+		ResultsDir:      v1alpha1.ResultsDir,
 		Args:            flag.Args(),
-		Waiter:          &realWaiter{},
-		Runner:          &realRunner{},
+		Waiter:          &realWaiter{timeout: *waitFileTimeout, startFile: *waitStartFile},
+		Runner:          newRealRunner(*timeout, *logTimestamps, *logStepName),
 		PostWriter:      &realPostWriter{},
 	}
+	if *results != "" {
+		e.Results = strings.Split(*results, ",")
+	}
+	e.StartChecksums = parseChecksums("start_checksums", *startChecksums)
+	e.EndChecksums = parseChecksums("end_checksums", *endChecksums)
+	if *progressFile != "" {
+		e.ProgressFile = *progressFile
+		e.ProgressInterval = *progressInterval
+		e.ProgressStep = *progressStep
+		e.ProgressReporter = &realProgressReporter{terminationPath: *terminationPath}
+	}
 	if err := e.Go(); err != nil {
 		switch t := err.(type) {
 		case skipError:
@@ -0,0 +1,40 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"log"
+
+	"github.com/tektoncd/pipeline/pkg/entrypoint"
+)
+
+// realProgressReporter reports the progress of the step in its termination
+// message, which the controller copies to the TaskRun.
+type realProgressReporter struct {
+	terminationPath string
+}
+
+var _ entrypoint.ProgressReporter = (*realProgressReporter)(nil)
+
+// Report replaces the progress in the termination message with p. The
+// progress is best effort, so failing to report it is logged, and doesn't
+// fail the step.
+func (r *realProgressReporter) Report(p entrypoint.Progress) {
+	if err := entrypoint.WriteProgress(r.terminationPath, p); err != nil {
+		log.Printf("Reporting the progress of the step: %v", err)
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"io"
 	"os"
 	"os/exec"
+	"time"
 
 	"github.com/tektoncd/pipeline/pkg/entrypoint"
 )
@@ -10,23 +12,103 @@ import (
 // TODO(jasonhall): Test that original exit code is propagated and that
 // stdout/stderr are collected -- needs e2e tests.
 
-// realRunner actually runs commands.
-type realRunner struct{}
+// realRunner actually runs commands, and kills them after timeout if it
+// isn't zero. Their output goes to stdout and stderr, or to the ones of the
+// entrypoint if nil.
+type realRunner struct {
+	timeout        time.Duration
+	stdout, stderr io.Writer
+}
 
 var _ entrypoint.Runner = (*realRunner)(nil)
 
-func (*realRunner) Run(args ...string) error {
+// outputDrainTimeout bounds how long the output left in the pipes of a
+// command is copied once it exited: the processes it left in the background,
+// e.g. a daemon, may hold the pipes open for as long as they run.
+const outputDrainTimeout = time.Second
+
+func (rr *realRunner) Run(args ...string) error {
 	if len(args) == 0 {
 		return nil
 	}
 	name, args := args[0], args[1:]
-
 	cmd := exec.Command(name, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
-	if err := cmd.Run(); err != nil {
+	// The command writes to the writers which aren't files through pipes
+	// copied by the runner rather than by exec, whose Wait would wait for
+	// the pipes to be closed by every process holding them.
+	var writeEnds []*os.File
+	var copied []chan struct{}
+	closeWriteEnds := func() {
+		for _, f := range writeEnds {
+			f.Close()
+		}
+	}
+	output := func(w io.Writer, std *os.File) (*os.File, error) {
+		if w == nil {
+			return std, nil
+		}
+		if f, ok := w.(*os.File); ok {
+			return f, nil
+		}
+		r, f, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer r.Close()
+			io.Copy(w, r)
+		}()
+		writeEnds = append(writeEnds, f)
+		copied = append(copied, done)
+		return f, nil
+	}
+	var err error
+	if cmd.Stdout, err = output(rr.stdout, os.Stdout); err != nil {
+		closeWriteEnds()
+		return err
+	}
+	if cmd.Stderr, err = output(rr.stderr, os.Stderr); err != nil {
+		closeWriteEnds()
+		return err
+	}
+	err = cmd.Start()
+	// The command holds its own ends of the pipes.
+	closeWriteEnds()
+	if err != nil {
+		return err
+	}
+	err = rr.wait(cmd)
+	drain := time.After(outputDrainTimeout)
+	for _, done := range copied {
+		select {
+		case <-done:
+		case <-drain:
+			return err
+		}
+	}
+	return err
+}
+
+// wait waits for cmd to exit, and kills it after the timeout of rr.
+func (rr *realRunner) wait(cmd *exec.Cmd) error {
+	if rr.timeout <= 0 {
+		return cmd.Wait()
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case err := <-done:
 		return err
+	case <-time.After(rr.timeout):
+		// The command may have exited meanwhile, in which case it didn't
+		// time out.
+		if err := cmd.Process.Kill(); err != nil {
+			return <-done
+		}
+		<-done
+		return entrypoint.TimeoutError{Timeout: rr.timeout}
 	}
-	return nil
 }
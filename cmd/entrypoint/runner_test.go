@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/entrypoint"
+)
+
+func TestRealRunnerTimeout(t *testing.T) {
+	rr := realRunner{timeout: 10 * time.Millisecond}
+	err := rr.Run("sleep", "10")
+	if _, ok := err.(entrypoint.TimeoutError); !ok {
+		t.Errorf("Expected a TimeoutError, got %v", err)
+	}
+}
+
+func TestRealRunnerWithinTimeout(t *testing.T) {
+	rr := realRunner{timeout: time.Minute}
+	if err := rr.Run("true"); err != nil {
+		t.Errorf("Expected the command to succeed, got %v", err)
+	}
+	err := rr.Run("false")
+	if _, ok := err.(interface{ ExitCode() int }); !ok {
+		t.Errorf("Expected the exit error of the command, got %v", err)
+	}
+}
+
+func TestRealRunnerPrefixesOutput(t *testing.T) {
+	var stdout bytes.Buffer
+	rr := realRunner{stdout: &entrypoint.LinePrefixer{W: &stdout, StepName: "build"}}
+	if err := rr.Run("echo", "hello"); err != nil {
+		t.Fatalf("Expected the command to succeed, got %v", err)
+	}
+	if got, want := stdout.String(), "[build] hello\n"; got != want {
+		t.Errorf("Expected the output %q, got %q", want, got)
+	}
+}
+
+func TestRealRunnerWithBackgroundProcess(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	rr := realRunner{
+		stdout: &entrypoint.LinePrefixer{W: &stdout, StepName: "build"},
+		stderr: &entrypoint.LinePrefixer{W: &stderr, StepName: "build"},
+	}
+	start := time.Now()
+	// The background sleep holds the stdout and stderr of the command.
+	if err := rr.Run("sh", "-c", "echo started; sleep 30 &"); err != nil {
+		t.Fatalf("Expected the command to succeed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the runner to return once the command exited, took %s", elapsed)
+	}
+	if got, want := stdout.String(), "[build] started\n"; got != want {
+		t.Errorf("Expected the output %q, got %q", want, got)
+	}
+}
+
+func TestRealRunnerTimeoutWithBackgroundProcess(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	rr := realRunner{
+		timeout: 10 * time.Millisecond,
+		stdout:  &entrypoint.LinePrefixer{W: &stdout},
+		stderr:  &entrypoint.LinePrefixer{W: &stderr},
+	}
+	start := time.Now()
+	err := rr.Run("sh", "-c", "sleep 30 & sleep 30")
+	if _, ok := err.(entrypoint.TimeoutError); !ok {
+		t.Errorf("Expected a TimeoutError, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the runner to return once the command was killed, took %s", elapsed)
+	}
+}
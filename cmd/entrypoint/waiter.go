@@ -8,8 +8,13 @@ import (
 	"golang.org/x/xerrors"
 )
 
-// realWaiter actually waits for files, by polling.
-type realWaiter struct{}
+// realWaiter actually waits for files, by polling, for at most timeout if it
+// isn't zero. If startFile is set, the timeout only counts from when it
+// exists, i.e. from when the previous step started.
+type realWaiter struct {
+	timeout   time.Duration
+	startFile string
+}
 
 var _ entrypoint.Waiter = (*realWaiter)(nil)
 
@@ -22,10 +27,18 @@ var _ entrypoint.Waiter = (*realWaiter)(nil)
 //
 // If a file of the same name with a ".err" extension exists then this Wait
 // will end with a skipError.
-func (*realWaiter) Wait(file string, expectContent bool) error {
+//
+// If the file isn't there after the timeout of the waiter, this Wait ends
+// with an entrypoint.WaitTimeoutError. The timeout doesn't count while the
+// start file of the waiter doesn't exist.
+func (rw *realWaiter) Wait(file string, expectContent bool) error {
 	if file == "" {
 		return nil
 	}
+	var start time.Time
+	if rw.startFile == "" {
+		start = time.Now()
+	}
 	for ; ; time.Sleep(waitPollingInterval) {
 		if info, err := os.Stat(file); err == nil {
 			if !expectContent || info.Size() > 0 {
@@ -37,6 +50,15 @@ func (*realWaiter) Wait(file string, expectContent bool) error {
 		if _, err := os.Stat(file + ".err"); err == nil {
 			return skipError("error file present, bail and skip the step")
 		}
+		if start.IsZero() {
+			if _, err := os.Stat(rw.startFile); err == nil {
+				start = time.Now()
+			}
+			continue
+		}
+		if rw.timeout > 0 && time.Since(start) >= rw.timeout {
+			return entrypoint.WaitTimeoutError{File: file, Timeout: rw.timeout}
+		}
 	}
 }
 
@@ -19,8 +19,11 @@ package main
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/tektoncd/pipeline/pkg/entrypoint"
 )
 
 func TestRealWaiterWaitMissingFile(t *testing.T) {
@@ -120,3 +123,57 @@ func TestRealWaiterWaitWithContent(t *testing.T) {
 		t.Errorf("expected Wait() to have detected a non-zero file size by now")
 	}
 }
+
+func TestRealWaiterWaitTimeout(t *testing.T) {
+	tmp, err := ioutil.TempFile("", "real_waiter_test_file")
+	if err != nil {
+		t.Errorf("error creating temp file: %v", err)
+	}
+	os.Remove(tmp.Name())
+	rw := realWaiter{timeout: waitPollingInterval}
+	errCh := make(chan error)
+	go func() {
+		errCh <- rw.Wait(tmp.Name(), false)
+	}()
+	select {
+	case err := <-errCh:
+		want := entrypoint.WaitTimeoutError{File: tmp.Name(), Timeout: waitPollingInterval}
+		if err != want {
+			t.Errorf("expected Wait() to fail with %v, got %v", want, err)
+		}
+	case <-time.After(3 * waitPollingInterval):
+		t.Errorf("expected Wait() to have timed out by now")
+	}
+}
+
+func TestRealWaiterWaitTimeoutFromStartFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "real_waiter_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "0")
+	rw := realWaiter{timeout: waitPollingInterval, startFile: file + ".start"}
+	errCh := make(chan error)
+	go func() {
+		errCh <- rw.Wait(file, false)
+	}()
+	select {
+	case err := <-errCh:
+		t.Fatalf("expected Wait() not to time out before the start file exists, got %v", err)
+	case <-time.After(3 * waitPollingInterval):
+		// Success
+	}
+	if err := ioutil.WriteFile(file+".start", nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errCh:
+		want := entrypoint.WaitTimeoutError{File: file, Timeout: waitPollingInterval}
+		if err != want {
+			t.Errorf("expected Wait() to fail with %v, got %v", want, err)
+		}
+	case <-time.After(4 * waitPollingInterval):
+		t.Errorf("expected Wait() to have timed out by now")
+	}
+}
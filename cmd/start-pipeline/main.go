@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The start-pipeline command creates a PipelineRun of a Pipeline, with the
+// defaults of its params and the PipelineResources named after its
+// resources, e.g. "build-source" for the "source" resource of the "build"
+// Pipeline.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"github.com/tektoncd/pipeline/pkg/launch"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+)
+
+// keyValues is a flag which can be given several times with key=value.
+type keyValues [][2]string
+
+func (kv *keyValues) String() string {
+	var s []string
+	for _, p := range *kv {
+		s = append(s, p[0]+"="+p[1])
+	}
+	return strings.Join(s, ",")
+}
+
+func (kv *keyValues) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("%q isn't of the form key=value", value)
+	}
+	*kv = append(*kv, [2]string{parts[0], parts[1]})
+	return nil
+}
+
+var (
+	kubeconfig     = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	namespace      = flag.String("namespace", "default", "The namespace of the Pipeline")
+	pipelineName   = flag.String("pipeline", "", "The name of the Pipeline to run")
+	serviceAccount = flag.String("service-account", "", "The service account the PipelineRun runs as, the default one of the namespace if empty")
+	dryRun         = flag.Bool("dry-run", false, "If set, print the PipelineRun instead of creating it")
+	params         keyValues
+	resources      keyValues
+	labels         keyValues
+)
+
+func main() {
+	flag.Var(&params, "param", "A param of the PipelineRun, as name=value, taking precedence over its default. Give an array param several times, once per item")
+	flag.Var(&resources, "resource", "The PipelineResource bound to a resource of the Pipeline, as name=resource, instead of the one named after the Pipeline and the resource")
+	flag.Var(&labels, "label", "A label of the PipelineRun, as key=value")
+	flag.Parse()
+	if *pipelineName == "" {
+		log.Fatal("-pipeline is required")
+	}
+
+	cfg, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err != nil {
+		log.Fatalf("Error building kubeconfig: %v", err)
+	}
+	client, err := versioned.NewForConfig(cfg)
+	if err != nil {
+		log.Fatalf("Error building pipeline clientset: %v", err)
+	}
+
+	opts := launch.Options{
+		Params:             makeParams(params),
+		Resources:          map[string]string{},
+		ServiceAccountName: *serviceAccount,
+		Labels:             map[string]string{},
+	}
+	for _, r := range resources {
+		opts.Resources[r[0]] = r[1]
+	}
+	for _, l := range labels {
+		opts.Labels[l[0]] = l[1]
+	}
+	pr, err := launch.New(client, *namespace, *pipelineName, opts)
+	if err != nil {
+		log.Fatalf("Error making the PipelineRun: %v", err)
+	}
+	if *dryRun {
+		pr.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("PipelineRun"))
+		b, err := yaml.Marshal(pr)
+		if err != nil {
+			log.Fatalf("Error marshalling the PipelineRun: %v", err)
+		}
+		os.Stdout.Write(b)
+		return
+	}
+	if pr, err = client.TektonV1alpha1().PipelineRuns(*namespace).Create(pr); err != nil {
+		log.Fatalf("Error creating the PipelineRun: %v", err)
+	}
+	fmt.Println(pr.Name)
+}
+
+// makeParams returns the params given with -param, in the order they were
+// first given. The params given several times are arrays.
+func makeParams(kv keyValues) []v1alpha1.Param {
+	var params []v1alpha1.Param
+	index := map[string]int{}
+	for _, p := range kv {
+		i, ok := index[p[0]]
+		if !ok {
+			index[p[0]] = len(params)
+			params = append(params, v1alpha1.Param{Name: p[0], Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString, StringVal: p[1]}})
+			continue
+		}
+		v := &params[i].Value
+		if v.Type == v1alpha1.ParamTypeString {
+			*v = v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeArray, ArrayVal: []string{v.StringVal}}
+		}
+		v.ArrayVal = append(v.ArrayVal, p[1])
+	}
+	return params
+}
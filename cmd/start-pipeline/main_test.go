@@ -0,0 +1,45 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+func TestMakeParams(t *testing.T) {
+	var kv keyValues
+	for _, v := range []string{"revision=main", "tags=latest", "url=https://example.com/?a=b", "tags=stable", "tags=v1"} {
+		if err := kv.Set(v); err != nil {
+			t.Fatalf("Set(%q): %v", v, err)
+		}
+	}
+	want := []v1alpha1.Param{
+		{Name: "revision", Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString, StringVal: "main"}},
+		{Name: "tags", Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeArray, ArrayVal: []string{"latest", "stable", "v1"}}},
+		{Name: "url", Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString, StringVal: "https://example.com/?a=b"}},
+	}
+	if d := cmp.Diff(want, makeParams(kv)); d != "" {
+		t.Errorf("Diff params -want, +got: %s", d)
+	}
+
+	if err := kv.Set("=main"); err == nil {
+		t.Error("Expected an error for a param without a name")
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/secrets/vault"
+	"knative.dev/pkg/logging"
+)
+
+var (
+	addr      = flag.String("addr", "", "The URL of the Vault server")
+	authPath  = flag.String("auth-path", vault.DefaultAuthPath, "The mount path of the Kubernetes auth method in Vault")
+	role      = flag.String("role", "", "The Vault role to log in with")
+	jwtPath   = flag.String("jwt-path", "/var/run/secrets/kubernetes.io/serviceaccount/token", "The service account token used to log into Vault")
+	dir       = flag.String("dir", "", "The directory to write the secrets to")
+	leasesDir = flag.String("leases-dir", "", "The directory to record the Vault token and the leases of the secrets in")
+	renew     = flag.Bool("renew", false, "If specified, keep renewing the Vault token and the leases recorded in -leases-dir")
+
+	// retryInterval is how long to wait before retrying a failed renewal.
+	retryInterval = 10 * time.Second
+)
+
+func main() {
+	flag.Parse()
+
+	// ignore atomic level because we are not watching this config for any updates
+	logger, _ := logging.NewLogger("", "vault-secrets")
+	defer logger.Sync()
+
+	if *renew {
+		leases, err := vault.ReadLeases(*leasesDir)
+		if err != nil {
+			logger.Fatalf("Error reading the leases to renew: %v", err)
+		}
+		c := &vault.Client{Address: *addr, Token: leases.Token}
+		for {
+			wait, err := vault.Renew(c, leases)
+			if err != nil {
+				logger.Errorf("Error renewing leases: %v", err)
+				wait = retryInterval
+			}
+			if wait <= 0 {
+				// Nothing expires, there is nothing left to renew.
+				logger.Info("Nothing to renew, exiting.")
+				return
+			}
+			time.Sleep(wait)
+		}
+	}
+
+	jwt, err := ioutil.ReadFile(*jwtPath)
+	if err != nil {
+		logger.Fatalf("Error reading the service account token: %v", err)
+	}
+	c := &vault.Client{Address: *addr}
+	auth, err := c.Login(*authPath, *role, strings.TrimSpace(string(jwt)))
+	if err != nil {
+		logger.Fatalf("Error logging into Vault: %v", err)
+	}
+	if err := vault.Fetch(c, auth, flag.Args(), *dir, *leasesDir); err != nil {
+		logger.Fatalf("Error fetching secrets: %v", err)
+	}
+	logger.Infof("Secrets written to %s.", *dir)
+}
@@ -18,14 +18,21 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"flag"
 	"log"
+	"net/http"
+	"sync"
+	"time"
 
+	"github.com/tektoncd/pipeline/pkg/admission"
 	apiconfig "github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	tklogging "github.com/tektoncd/pipeline/pkg/logging"
 	"github.com/tektoncd/pipeline/pkg/system"
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -39,6 +46,10 @@ import (
 // WebhookLogKey is the name of the logger for the webhook cmd
 const WebhookLogKey = "webhook"
 
+// validatePort is the port the synchronous validation of the resources is
+// served on, next to the admission webhook.
+const validatePort = ":8444"
+
 func main() {
 	flag.Parse()
 	cm, err := configmap.Load("/etc/config-logging")
@@ -67,6 +78,10 @@ func main() {
 	if err != nil {
 		logger.Fatal("Failed to get the client set", zap.Error(err))
 	}
+	pipelineClient, err := versioned.NewForConfig(clusterConfig)
+	if err != nil {
+		logger.Fatal("Failed to get the pipeline client set", zap.Error(err))
+	}
 	// Watch the logging config map and dynamically update logging levels.
 	configMapWatcher := configmap.NewInformedWatcher(kubeClient, system.GetNamespace())
 	configMapWatcher.Watch(tklogging.ConfigName, logging.UpdateLevelFromConfigMap(logger, atomicLevel, WebhookLogKey))
@@ -95,18 +110,54 @@ func main() {
 		v1alpha1.SchemeGroupVersion.WithKind("TaskRun"):          &v1alpha1.TaskRun{},
 		v1alpha1.SchemeGroupVersion.WithKind("PipelineRun"):      &v1alpha1.PipelineRun{},
 		v1alpha1.SchemeGroupVersion.WithKind("Condition"):        &v1alpha1.Condition{},
+		v1alpha1.SchemeGroupVersion.WithKind("StepAction"):       &v1alpha1.StepAction{},
+		v1alpha1.SchemeGroupVersion.WithKind("CleanupPolicy"):    &v1alpha1.CleanupPolicy{},
+		v1alpha1.SchemeGroupVersion.WithKind("ImagePrefetch"):    &v1alpha1.ImagePrefetch{},
+		v1alpha1.SchemeGroupVersion.WithKind("TaskRunArchive"):   &v1alpha1.TaskRunArchive{},
+		v1alpha1.SchemeGroupVersion.WithKind("PipelineQuota"):    &v1alpha1.PipelineQuota{},
 	}
 
-	resourceAdmissionController := webhook.NewResourceAdmissionController(resourceHandlers, options, true)
+	resourceAdmissionController := &admission.StructuredErrorsController{
+		AdmissionController: webhook.NewResourceAdmissionController(resourceHandlers, options, true),
+	}
 	admissionControllers := map[string]webhook.AdmissionController{
 		options.ResourceAdmissionControllerPath: resourceAdmissionController,
 	}
 
-	// Decorate contexts with the current state of the config.
+	// Decorate contexts with the current state of the config, and with
+	// how to get the PipelineRuns new ones re-run.
+	getPipelineRun := func(namespace, name string) (*v1alpha1.PipelineRun, error) {
+		return pipelineClient.TektonV1alpha1().PipelineRuns(namespace).Get(name, metav1.GetOptions{})
+	}
 	ctxFunc := func(ctx context.Context) context.Context {
+		ctx = v1alpha1.WithPipelineRunGetter(ctx, getPipelineRun)
 		return v1alpha1.WithDefaultConfigurationName(store.ToContext(ctx))
 	}
 
+	validateServer := &http.Server{
+		Addr: validatePort,
+		Handler: &admission.ValidateHandler{
+			Handlers:     resourceHandlers,
+			Authenticate: admission.TokenReviewAuthenticator(kubeClient),
+			WithContext:  ctxFunc,
+			Logger:       logger.Named("validate"),
+		},
+		TLSConfig: &tls.Config{
+			GetCertificate: secretCertificate(kubeClient, options.Namespace, options.SecretName),
+		},
+	}
+	go func() {
+		if err := validateServer.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("Error serving the validation endpoint", zap.Error(err))
+		}
+	}()
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		validateServer.Shutdown(ctx)
+	}()
+
 	controller, err := webhook.New(kubeClient, options, admissionControllers, logger, ctxFunc)
 	if err != nil {
 		logger.Fatal("Error creating admission controller", zap.Error(err))
@@ -116,3 +167,29 @@ func main() {
 		logger.Fatal("Error running admission controller", zap.Error(err))
 	}
 }
+
+// secretCertificate returns the certificate of the webhook, which it
+// generates into the secret name on its start, for the validation endpoint
+// to be served with the same certificate. The certificate is cached once
+// the webhook generated it.
+func secretCertificate(kubeClient kubernetes.Interface, namespace, name string) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	var mu sync.Mutex
+	var cached *tls.Certificate
+	return func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if cached != nil {
+			return cached, nil
+		}
+		secret, err := kubeClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.X509KeyPair(secret.Data["server-cert.pem"], secret.Data["server-key.pem"])
+		if err != nil {
+			return nil, err
+		}
+		cached = &cert
+		return cached, nil
+	}
+}
@@ -45,5 +45,9 @@ ${KNATIVE_CODEGEN_PKG}/hack/generate-knative.sh "injection" \
   "pipeline:v1alpha1" \
   --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt
 
+# The ValidatingAdmissionPolicies mirroring the structural validations of the
+# webhook, which TestGeneratedPolicies checks are up-to-date.
+go run ${REPO_ROOT_DIR}/cmd/admission-policy -output ${REPO_ROOT_DIR}/config/admission-policy/policies.yaml
+
 # Make sure our dependencies are up-to-date
 ${REPO_ROOT_DIR}/hack/update-deps.sh
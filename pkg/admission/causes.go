@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admission adds machine-readable details to the denials of the
+// admission webhook.
+package admission
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"knative.dev/pkg/apis"
+)
+
+// causeTypes are the types of the causes of the errors of the apis helpers,
+// by their message. The errors of other messages are field.ErrorTypeInvalid.
+var causeTypes = map[string]field.ErrorType{
+	apis.ErrMissingField().Message:                     field.ErrorTypeRequired,
+	apis.ErrMissingOneOf().Message:                     field.ErrorTypeRequired,
+	apis.ErrDisallowedFields().Message:                 field.ErrorTypeForbidden,
+	apis.ErrDisallowedUpdateDeprecatedFields().Message: field.ErrorTypeForbidden,
+	apis.ErrMultipleOneOf().Message:                    field.ErrorTypeForbidden,
+	"Internal Error":                                   field.ErrorTypeInternal,
+}
+
+// pathMarker prefixes the paths of the errors Causes renders to tell them
+// apart from their messages and details.
+const pathMarker = "\x00"
+
+// Causes returns a cause for each field of each of the errors aggregated by
+// err, sorted by field, so that clients can map them back to the fields of
+// the rejected resource. Their type, e.g. FieldValueRequired, is the rule
+// the field breaks.
+func Causes(err *apis.FieldError) []metav1.StatusCause {
+	if err == nil {
+		return nil
+	}
+	var causes []metav1.StatusCause
+	for _, e := range leaves(err) {
+		t, ok := causeTypes[e.Message]
+		if !ok {
+			t = field.ErrorTypeInvalid
+		}
+		message := e.Message
+		if e.Details != "" {
+			message += "\n" + e.Details
+		}
+		for _, p := range e.Paths {
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseType(t),
+				Message: message,
+				Field:   p,
+			})
+		}
+	}
+	sort.SliceStable(causes, func(i, j int) bool {
+		if causes[i].Field == causes[j].Field {
+			return causes[i].Message < causes[j].Message
+		}
+		return causes[i].Field < causes[j].Field
+	})
+	return causes
+}
+
+// leaves returns the errors aggregated by the apis.FieldError fe, which it
+// only exposes through its message: a "<message>: <path>, <path>" line for
+// each error, merging the ones of the same message and details, followed
+// by their details, if any. The paths are told apart by prefixing them with
+// pathMarker, the errors of the current field having that prefix alone and
+// the ones without paths none.
+func leaves(fe *apis.FieldError) []apis.FieldError {
+	var errs []apis.FieldError
+	for _, line := range strings.Split(fe.ViaField(pathMarker).Error(), "\n") {
+		i := strings.Index(line, ": "+pathMarker)
+		if i < 0 && strings.HasSuffix(line, ": ") {
+			errs = append(errs, apis.FieldError{
+				Message: strings.TrimSuffix(line, ": "),
+				Paths:   []string{""},
+			})
+			continue
+		}
+		if i < 0 {
+			// The line is one of the details of the last error.
+			if last := len(errs) - 1; last >= 0 {
+				errs[last].Details = strings.TrimSpace(errs[last].Details + "\n" + line)
+			}
+			continue
+		}
+		e := apis.FieldError{Message: line[:i]}
+		for _, p := range strings.Split(line[i+2:], ", "+pathMarker) {
+			e.Paths = append(e.Paths, strings.TrimPrefix(strings.TrimPrefix(p, pathMarker), "."))
+		}
+		errs = append(errs, e)
+	}
+	return errs
+}
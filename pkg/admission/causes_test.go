@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestCauses(t *testing.T) {
+	err := apis.ErrMissingField("image").ViaIndex(1).ViaField("steps").
+		Also(apis.ErrInvalidValue("-1", "timeout")).
+		Also(&apis.FieldError{
+			Message: "invalid sh script: line 2: unexpected \"fi\"",
+			Paths:   []string{"steps.script", "initSteps.script"},
+		}).
+		Also(apis.ErrMultipleOneOf("taskRef", "taskSpec").ViaField("spec")).
+		Also(apis.ErrMissingField("image").ViaIndex(1).ViaField("steps")).
+		ViaField("spec")
+	want := []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: "invalid sh script: line 2: unexpected \"fi\"",
+		Field:   "spec.initSteps.script",
+	}, {
+		Type:    "FieldValueForbidden",
+		Message: "expected exactly one, got both",
+		Field:   "spec.spec.taskRef",
+	}, {
+		Type:    "FieldValueForbidden",
+		Message: "expected exactly one, got both",
+		Field:   "spec.spec.taskSpec",
+	}, {
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: "invalid sh script: line 2: unexpected \"fi\"",
+		Field:   "spec.steps.script",
+	}, {
+		Type:    metav1.CauseTypeFieldValueRequired,
+		Message: "missing field(s)",
+		Field:   "spec.steps[1].image",
+	}, {
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: "invalid value: -1",
+		Field:   "spec.timeout",
+	}}
+	if d := cmp.Diff(want, Causes(err)); d != "" {
+		t.Errorf("Causes() -want, +got: %s", d)
+	}
+}
+
+func TestCauses_Details(t *testing.T) {
+	err := &apis.FieldError{
+		Message: "invalid value: foo",
+		Details: "must be a DNS label",
+	}
+	want := []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: "invalid value: foo\nmust be a DNS label",
+	}}
+	if d := cmp.Diff(want, Causes(err)); d != "" {
+		t.Errorf("Causes() -want, +got: %s", d)
+	}
+
+	err = apis.ErrInvalidValue("echo", "script").Also(&apis.FieldError{
+		Message: "invalid sh script: line 1: unexpected EOF",
+		Paths:   []string{"steps[0].script"},
+		Details: "while parsing:\nif true; then",
+	})
+	want = []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: "invalid value: echo",
+		Field:   "script",
+	}, {
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Message: "invalid sh script: line 1: unexpected EOF\nwhile parsing:\nif true; then",
+		Field:   "steps[0].script",
+	}}
+	if d := cmp.Diff(want, Causes(err)); d != "" {
+		t.Errorf("Causes() of multi-line details -want, +got: %s", d)
+	}
+	if causes := Causes(nil); causes != nil {
+		t.Errorf("Causes(nil) = %v, want nil", causes)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/webhook"
+)
+
+// StructuredErrorsController is an admission controller adding the causes
+// of the validation errors, i.e. the field, rule and message of each error,
+// to the denials of the resources the AdmissionController it embeds
+// validates, when the structured-validation-errors setting of
+// config-defaults is enabled.
+type StructuredErrorsController struct {
+	// AdmissionController admits the resources, e.g. a
+	// webhook.ResourceAdmissionController, and registers the webhook.
+	webhook.AdmissionController
+}
+
+var _ webhook.AdmissionController = (*StructuredErrorsController)(nil)
+
+// Admit admits the request with c.AdmissionController and adds the causes of its
+// denial, if any, to the details of its result. The causes are the errors
+// of the validation of the resource by c.AdmissionController, noted on the
+// context, rather than the ones of validating it again.
+func (c *StructuredErrorsController) Admit(ctx context.Context, request *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	if !config.FromContextOrDefaults(ctx).Defaults.StructuredValidationErrors {
+		return c.AdmissionController.Admit(ctx, request)
+	}
+	ctx, validationErrors := v1alpha1.WithValidationErrors(ctx)
+	response := c.AdmissionController.Admit(ctx, request)
+	if response == nil || response.Allowed || response.Result == nil {
+		return response
+	}
+	// The requests denied before their resource was validated, e.g. failing
+	// to be decoded, have no causes.
+	if err := validationErrors(); err != nil {
+		response.Result.Details = &metav1.StatusDetails{
+			Name:   request.Name,
+			Group:  request.Kind.Group,
+			Kind:   request.Kind.Kind,
+			Causes: Causes(err),
+		}
+	}
+	return response
+}
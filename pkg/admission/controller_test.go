@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/webhook"
+)
+
+func taskRequest(t *testing.T, task *v1alpha1.Task) *admissionv1beta1.AdmissionRequest {
+	t.Helper()
+	raw, err := json.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Create,
+		Kind:      metav1.GroupVersionKind{Group: "tekton.dev", Version: "v1alpha1", Kind: "Task"},
+		Name:      task.Name,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}
+
+func structuredErrorsContext(enabled bool) context.Context {
+	return config.ToContext(context.Background(), &config.Config{
+		Defaults: &config.Defaults{StructuredValidationErrors: enabled},
+	})
+}
+
+func TestStructuredErrorsController(t *testing.T) {
+	c := &StructuredErrorsController{
+		AdmissionController: webhook.NewResourceAdmissionController(map[schema.GroupVersionKind]webhook.GenericCRD{
+			v1alpha1.SchemeGroupVersion.WithKind("Task"): &v1alpha1.Task{},
+		}, webhook.ControllerOptions{}, true),
+	}
+	task := tb.Task("build", "foo", tb.TaskSpec(
+		tb.Step("compile", ""),
+		tb.Step("compile", "golang"),
+	))
+
+	response := c.Admit(structuredErrorsContext(true), taskRequest(t, task))
+	want := &metav1.StatusDetails{
+		Name:  "build",
+		Group: "tekton.dev",
+		Kind:  "Task",
+		Causes: []metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldValueRequired,
+			Message: "missing field(s)",
+			Field:   "steps[0].Image",
+		}, {
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: "invalid value: compile",
+			Field:   "steps[1].name",
+		}},
+	}
+	if d := cmp.Diff(want, response.Result.Details); d != "" {
+		t.Errorf("Admit() details -want, +got: %s", d)
+	}
+	if !strings.HasPrefix(response.Result.Message, "mutation failed: ") {
+		t.Errorf("Admit() message = %q, want the one of the controller", response.Result.Message)
+	}
+
+	response = c.Admit(structuredErrorsContext(false), taskRequest(t, task))
+	if response.Result.Details != nil {
+		t.Errorf("Admit() details = %v without structured-validation-errors, want none", response.Result.Details)
+	}
+
+	// A resource which can't be decoded isn't validated.
+	request := taskRequest(t, task)
+	request.Object.Raw = []byte(`{"spec": {"unknown": true}}`)
+	response = c.Admit(structuredErrorsContext(true), request)
+	if response.Allowed || response.Result.Details != nil {
+		t.Errorf("Admit() = %v for an unknown field, want a denial without details", response)
+	}
+}
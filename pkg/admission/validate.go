@@ -0,0 +1,242 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"go.uber.org/zap"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/webhook"
+)
+
+// ValidatePath is the path the ValidateHandler is served on.
+const ValidatePath = "/validate"
+
+// maxValidateBodySize bounds the size of the resources to validate, like
+// the API server bounds the size of the resources it stores.
+const maxValidateBodySize = 3 * 1024 * 1024
+
+// Authenticator returns the user a bearer token belongs to.
+type Authenticator func(token string) (*authenticationv1.UserInfo, error)
+
+// TokenReviewAuthenticator authenticates the bearer tokens with the API
+// server, through TokenReviews.
+func TokenReviewAuthenticator(kubeclient kubernetes.Interface) Authenticator {
+	return func(token string) (*authenticationv1.UserInfo, error) {
+		review, err := kubeclient.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !review.Status.Authenticated {
+			return nil, fmt.Errorf("invalid token: %s", review.Status.Error)
+		}
+		return &review.Status.User, nil
+	}
+}
+
+// ValidateHandler validates the resource, in YAML or JSON, of the POST
+// requests it serves without creating it, as the webhook would on its
+// creation by the authenticated user, i.e. with the configuration and
+// feature flags of the cluster, and responds with a ValidateResponse.
+type ValidateHandler struct {
+	// Handlers are the types of the resources, by kind.
+	Handlers map[schema.GroupVersionKind]webhook.GenericCRD
+	// Authenticate returns the user of the bearer token of the requests.
+	Authenticate Authenticator
+	// WithContext decorates the contexts the resources are defaulted and
+	// validated in, like the webhook does.
+	WithContext func(context.Context) context.Context
+	// Logger logs the failures to serve the requests.
+	Logger *zap.SugaredLogger
+}
+
+var _ http.Handler = (*ValidateHandler)(nil)
+
+// ValidateResponse is the verdict of the webhook on a resource.
+type ValidateResponse struct {
+	// Allowed is whether the webhook would admit the resource.
+	Allowed bool `json:"allowed"`
+	// Message is the validation error of the resource, if any.
+	Message string `json:"message,omitempty"`
+	// Causes are the field, rule and message of each validation error.
+	Causes []metav1.StatusCause `json:"causes,omitempty"`
+	// Warnings are about what the webhook admits but which is deprecated
+	// or will fail to run with the configuration of the cluster.
+	Warnings []string `json:"warnings,omitempty"`
+	// Object is the resource, once defaulted.
+	Object webhook.GenericCRD `json:"object,omitempty"`
+}
+
+// ServeHTTP authenticates the request, defaults and validates its resource
+// and writes the verdict.
+func (h *ValidateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == r.Header.Get("Authorization") {
+		http.Error(w, "a bearer token is required", http.StatusUnauthorized)
+		return
+	}
+	user, err := h.Authenticate(token)
+	if err != nil {
+		h.Logger.Infow("Failed to authenticate a validation request", zap.Error(err))
+		http.Error(w, "failed to authenticate the bearer token", http.StatusUnauthorized)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxValidateBodySize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read the body: %v", err), http.StatusRequestEntityTooLarge)
+		return
+	}
+	raw, err := yaml.YAMLToJSON(body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("the body is neither YAML nor JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		http.Error(w, fmt.Sprintf("the body isn't a resource: %v", err), http.StatusBadRequest)
+		return
+	}
+	handler, ok := h.Handlers[typeMeta.GroupVersionKind()]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported kind %q of apiVersion %q", typeMeta.Kind, typeMeta.APIVersion), http.StatusBadRequest)
+		return
+	}
+
+	var response *ValidateResponse
+	obj, err := decode(handler, raw)
+	if err != nil {
+		response = &ValidateResponse{Message: fmt.Sprintf("cannot decode the resource: %v", err)}
+	} else {
+		// The resource is defaulted with the defaults of its namespace, which
+		// is the one of the namespace parameter if it has none, like kubectl
+		// create --namespace does.
+		namespace := r.URL.Query().Get("namespace")
+		if m, ok := obj.(metav1.Object); ok && !isClusterScoped(obj) {
+			switch {
+			case m.GetNamespace() == "" && namespace == "":
+				http.Error(w, "the resource has no metadata.namespace and no namespace parameter was given", http.StatusBadRequest)
+				return
+			case m.GetNamespace() == "":
+				m.SetNamespace(namespace)
+			case namespace != "" && m.GetNamespace() != namespace:
+				http.Error(w, fmt.Sprintf("the metadata.namespace %q of the resource doesn't match the namespace parameter %q", m.GetNamespace(), namespace), http.StatusBadRequest)
+				return
+			}
+		}
+		ctx := r.Context()
+		if h.WithContext != nil {
+			ctx = h.WithContext(ctx)
+		}
+		ctx = apis.WithUserInfo(apis.WithinCreate(ctx), user)
+		response = validate(ctx, obj)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.Logger.Errorw("Failed to write the verdict of a validation request", zap.Error(err))
+	}
+}
+
+// decode decodes raw into a resource of the type of handler, like the
+// webhook does, strictly.
+func decode(handler webhook.GenericCRD, raw []byte) (webhook.GenericCRD, error) {
+	obj := handler.DeepCopyObject().(webhook.GenericCRD)
+	decoder := json.NewDecoder(bytes.NewBuffer(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// isClusterScoped returns whether obj is a resource without a namespace.
+func isClusterScoped(obj webhook.GenericCRD) bool {
+	_, ok := obj.(*v1alpha1.ClusterTask)
+	return ok
+}
+
+// validate defaults and validates obj.
+func validate(ctx context.Context, obj webhook.GenericCRD) *ValidateResponse {
+	obj.SetDefaults(ctx)
+	response := &ValidateResponse{Allowed: true, Warnings: warnings(ctx, obj), Object: obj}
+	if err := obj.Validate(ctx); err != nil {
+		response.Allowed = false
+		response.Message = err.Error()
+		response.Causes = Causes(err)
+	}
+	return response
+}
+
+// warnings returns what the webhook admits in obj but is deprecated, or
+// will fail to run with the configuration of ctx.
+func warnings(ctx context.Context, obj webhook.GenericCRD) []string {
+	var warnings []string
+	deprecated := func(path, replacement string) {
+		warnings = append(warnings, fmt.Sprintf("%s is deprecated, use %s instead", path, replacement))
+	}
+	forbidPrivileged := config.FromContextOrDefaults(ctx).Defaults.ForbidPrivilegedSteps
+	privileged := func(path string, spec v1alpha1.TaskSpec) {
+		if !forbidPrivileged {
+			return
+		}
+		for _, c := range resources.PrivilegedContainers(spec) {
+			warnings = append(warnings, fmt.Sprintf("%s: %s runs privileged, which the cluster forbids", path, c))
+		}
+	}
+	switch o := obj.(type) {
+	case *v1alpha1.Task:
+		privileged("spec", o.Spec)
+	case *v1alpha1.ClusterTask:
+		privileged("spec", o.Spec)
+	case *v1alpha1.TaskRun:
+		if o.Spec.DeprecatedServiceAccount != "" {
+			deprecated("spec.serviceAccount", "spec.serviceAccountName")
+		}
+		if o.Spec.TaskSpec != nil {
+			privileged("spec.taskSpec", *o.Spec.TaskSpec)
+		}
+	case *v1alpha1.PipelineRun:
+		if o.Spec.DeprecatedServiceAccount != "" {
+			deprecated("spec.serviceAccount", "spec.serviceAccountName")
+		}
+		if len(o.Spec.DeprecatedServiceAccounts) != 0 {
+			deprecated("spec.serviceAccounts", "spec.serviceAccountNames")
+		}
+	}
+	return warnings
+}
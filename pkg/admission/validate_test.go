@@ -0,0 +1,268 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"go.uber.org/zap"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/webhook"
+)
+
+const validTask = `
+apiVersion: tekton.dev/v1alpha1
+kind: Task
+metadata:
+  name: build
+  namespace: foo
+spec:
+  steps:
+  - name: compile
+    image: golang
+    securityContext:
+      privileged: true
+`
+
+func validateHandler() *ValidateHandler {
+	return &ValidateHandler{
+		Handlers: map[schema.GroupVersionKind]webhook.GenericCRD{
+			v1alpha1.SchemeGroupVersion.WithKind("Task"):    &v1alpha1.Task{},
+			v1alpha1.SchemeGroupVersion.WithKind("TaskRun"): &v1alpha1.TaskRun{},
+		},
+		Authenticate: func(token string) (*authenticationv1.UserInfo, error) {
+			if token != "secret" {
+				return nil, errors.New("invalid token")
+			}
+			return &authenticationv1.UserInfo{Username: "alice"}, nil
+		},
+		WithContext: func(ctx context.Context) context.Context {
+			return config.ToContext(ctx, &config.Config{
+				Defaults: &config.Defaults{ForbidPrivilegedSteps: true},
+			})
+		},
+		Logger: zap.NewNop().Sugar(),
+	}
+}
+
+func serveValidate(h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func decodeVerdict(t *testing.T, w *httptest.ResponseRecorder) map[string]json.RawMessage {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var verdict map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &verdict); err != nil {
+		t.Fatalf("ServeHTTP() body isn't a verdict: %v", err)
+	}
+	return verdict
+}
+
+func TestValidateHandler(t *testing.T) {
+	h := validateHandler()
+
+	verdict := decodeVerdict(t, serveValidate(h, http.MethodPost, ValidatePath, "secret", validTask))
+	if string(verdict["allowed"]) != "true" {
+		t.Errorf("ServeHTTP() allowed = %s, want true", verdict["allowed"])
+	}
+	var warnings []string
+	if err := json.Unmarshal(verdict["warnings"], &warnings); err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff([]string{"spec: step compile runs privileged, which the cluster forbids"}, warnings); d != "" {
+		t.Errorf("ServeHTTP() warnings -want, +got: %s", d)
+	}
+	var task v1alpha1.Task
+	if err := json.Unmarshal(verdict["object"], &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "build" || len(task.Spec.Steps) != 1 {
+		t.Errorf("ServeHTTP() object = %v, want the Task", task)
+	}
+
+	invalid := strings.Replace(validTask, "image: golang", "image: \"\"", 1)
+	verdict = decodeVerdict(t, serveValidate(h, http.MethodPost, ValidatePath, "secret", invalid))
+	if string(verdict["allowed"]) != "false" {
+		t.Errorf("ServeHTTP() allowed = %s for an invalid Task, want false", verdict["allowed"])
+	}
+	var causes []metav1.StatusCause
+	if err := json.Unmarshal(verdict["causes"], &causes); err != nil {
+		t.Fatal(err)
+	}
+	want := []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueRequired,
+		Message: "missing field(s)",
+		Field:   "steps[0].Image",
+	}}
+	if d := cmp.Diff(want, causes); d != "" {
+		t.Errorf("ServeHTTP() causes -want, +got: %s", d)
+	}
+
+	unknown := strings.Replace(validTask, "image: golang", "image: golang\n    imageTag: latest", 1)
+	verdict = decodeVerdict(t, serveValidate(h, http.MethodPost, ValidatePath, "secret", unknown))
+	if string(verdict["allowed"]) != "false" {
+		t.Errorf("ServeHTTP() allowed = %s for an unknown field, want false", verdict["allowed"])
+	}
+
+	taskRun := `{"apiVersion": "tekton.dev/v1alpha1", "kind": "TaskRun", "metadata": {"name": "run", "namespace": "foo"},
+		"spec": {"serviceAccount": "builder", "taskRef": {"name": "build"}}}`
+	verdict = decodeVerdict(t, serveValidate(h, http.MethodPost, ValidatePath, "secret", taskRun))
+	if err := json.Unmarshal(verdict["warnings"], &warnings); err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff([]string{"spec.serviceAccount is deprecated, use spec.serviceAccountName instead"}, warnings); d != "" {
+		t.Errorf("ServeHTTP() warnings -want, +got: %s", d)
+	}
+}
+
+func TestValidateHandler_NamespaceParameter(t *testing.T) {
+	h := validateHandler()
+	h.WithContext = func(ctx context.Context) context.Context {
+		return config.ToContext(ctx, &config.Config{
+			Defaults: &config.Defaults{
+				NamespaceContainerResourceRequirements: map[string]corev1.ResourceRequirements{
+					"bar": {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+				},
+			},
+		})
+	}
+	noNamespace := strings.Replace(validTask, "  namespace: foo\n", "", 1)
+
+	verdict := decodeVerdict(t, serveValidate(h, http.MethodPost, ValidatePath+"?namespace=bar", "secret", noNamespace))
+	var task v1alpha1.Task
+	if err := json.Unmarshal(verdict["object"], &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Namespace != "bar" {
+		t.Errorf("ServeHTTP() object namespace = %q, want the one of the parameter", task.Namespace)
+	}
+	if cpu := task.Spec.Steps[0].Resources.Requests[corev1.ResourceCPU]; cpu.String() != "1" {
+		t.Errorf("ServeHTTP() object CPU request = %s, want the default of the namespace", cpu.String())
+	}
+}
+
+func TestValidateHandler_Rejected(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		method string
+		token  string
+		body   string
+		want   int
+		path   string
+	}{{
+		name:   "GET",
+		method: http.MethodGet,
+		token:  "secret",
+		want:   http.StatusMethodNotAllowed,
+	}, {
+		name:   "no token",
+		method: http.MethodPost,
+		body:   validTask,
+		want:   http.StatusUnauthorized,
+	}, {
+		name:   "invalid token",
+		method: http.MethodPost,
+		token:  "guess",
+		body:   validTask,
+		want:   http.StatusUnauthorized,
+	}, {
+		name:   "not YAML",
+		method: http.MethodPost,
+		token:  "secret",
+		body:   "kind: [Task",
+		want:   http.StatusBadRequest,
+	}, {
+		name:   "unsupported kind",
+		method: http.MethodPost,
+		token:  "secret",
+		body:   strings.Replace(validTask, "kind: Task", "kind: Pod", 1),
+		want:   http.StatusBadRequest,
+	}, {
+		name:   "no namespace",
+		method: http.MethodPost,
+		token:  "secret",
+		body:   strings.Replace(validTask, "  namespace: foo\n", "", 1),
+		want:   http.StatusBadRequest,
+	}, {
+		name:   "other namespace",
+		method: http.MethodPost,
+		path:   ValidatePath + "?namespace=bar",
+		token:  "secret",
+		body:   validTask,
+		want:   http.StatusBadRequest,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := tc.path
+			if path == "" {
+				path = ValidatePath
+			}
+			w := serveValidate(validateHandler(), tc.method, path, tc.token, tc.body)
+			if w.Code != tc.want {
+				t.Errorf("ServeHTTP() status = %d, want %d", w.Code, tc.want)
+			}
+		})
+	}
+}
+
+func TestTokenReviewAuthenticator(t *testing.T) {
+	kubeclient := fakekubeclientset.NewSimpleClientset()
+	kubeclient.PrependReactor("create", "tokenreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		review.Status.Authenticated = review.Spec.Token == "secret"
+		if review.Status.Authenticated {
+			review.Status.User = authenticationv1.UserInfo{Username: "alice"}
+		}
+		return true, review, nil
+	})
+	authenticate := TokenReviewAuthenticator(kubeclient)
+
+	user, err := authenticate("secret")
+	if err != nil {
+		t.Fatalf("authenticate() = %v", err)
+	}
+	if user.Username != "alice" {
+		t.Errorf("authenticate() user = %q, want alice", user.Username)
+	}
+	if _, err := authenticate("guess"); err == nil {
+		t.Error("authenticate() of an invalid token succeeded, want an error")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// CapabilitiesConfigName is the name of the configmap mapping the
+// capabilities Tasks require to the nodes which have them
+const CapabilitiesConfigName = "config-capabilities"
+
+// Capability is how the pods requiring a capability are scheduled on the
+// nodes which have it.
+// +k8s:deepcopy-gen=true
+type Capability struct {
+	// NodeSelector selects the nodes with the capability.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations tolerate the taints keeping the other pods off the nodes
+	// with the capability.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// RuntimeClassName is the runtime class the containers need, e.g. to
+	// run Docker in Docker.
+	RuntimeClassName string `json:"runtimeClassName,omitempty"`
+	// CheckNodes fails the TaskRuns requiring the capability, along with
+	// others checking their nodes too, when no node has them, rather than
+	// leaving their pod pending. The nodes autoscaled from zero mustn't be
+	// checked.
+	CheckNodes bool `json:"checkNodes,omitempty"`
+}
+
+// Capabilities holds the capabilities Tasks can require
+// +k8s:deepcopy-gen=true
+type Capabilities struct {
+	// Capabilities are indexed by name, e.g. gpu.
+	Capabilities map[string]Capability
+}
+
+// NewCapabilitiesFromMap returns Capabilities given a map corresponding to a
+// ConfigMap, whose values are Capabilities in YAML
+func NewCapabilitiesFromMap(cfgMap map[string]string) (*Capabilities, error) {
+	c := Capabilities{
+		Capabilities: map[string]Capability{},
+	}
+	for k, v := range cfgMap {
+		if strings.HasPrefix(k, "_") {
+			// Examples and other comments.
+			continue
+		}
+		if msgs := validation.IsDNS1123Label(k); len(msgs) > 0 {
+			return nil, fmt.Errorf("invalid capability name %q: %s", k, strings.Join(msgs, "; "))
+		}
+		j, err := yaml.YAMLToJSON([]byte(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid capability %q: %v", k, err)
+		}
+		// Misspelt fields would silently let the pods run anywhere.
+		d := json.NewDecoder(bytes.NewReader(j))
+		d.DisallowUnknownFields()
+		var capability Capability
+		if err := d.Decode(&capability); err != nil {
+			return nil, fmt.Errorf("invalid capability %q: %v", k, err)
+		}
+		if len(capability.NodeSelector) == 0 && len(capability.Tolerations) == 0 && capability.RuntimeClassName == "" {
+			return nil, fmt.Errorf("invalid capability %q: expected a nodeSelector, tolerations or a runtimeClassName", k)
+		}
+		if name := capability.RuntimeClassName; name != "" {
+			if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+				return nil, fmt.Errorf("invalid runtimeClassName %q of capability %q: %s", name, k, strings.Join(msgs, "; "))
+			}
+		}
+		c.Capabilities[k] = capability
+	}
+	return &c, nil
+}
+
+// NewCapabilitiesFromConfigMap returns Capabilities for the given configmap
+func NewCapabilitiesFromConfigMap(config *corev1.ConfigMap) (*Capabilities, error) {
+	return NewCapabilitiesFromMap(config.Data)
+}
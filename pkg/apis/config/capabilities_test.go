@@ -0,0 +1,69 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	test "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNewCapabilitiesFromConfigMap(t *testing.T) {
+	cm := test.ConfigMapFromTestFile(t, CapabilitiesConfigName)
+	got, err := NewCapabilitiesFromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("NewCapabilitiesFromConfigMap() = %v", err)
+	}
+	want := &Capabilities{Capabilities: map[string]Capability{
+		"gpu": {
+			NodeSelector: map[string]string{"cloud.google.com/gke-accelerator": "nvidia-tesla-t4"},
+			Tolerations:  []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+			CheckNodes:   true,
+		},
+		"dind":      {RuntimeClassName: "sysbox"},
+		"largedisk": {NodeSelector: map[string]string{"tekton.dev/disk": "large"}},
+	}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff:\n%s", d)
+	}
+}
+
+func TestNewCapabilitiesFromEmptyMap(t *testing.T) {
+	got, err := NewCapabilitiesFromMap(map[string]string{"_example": "gpu: |\n  runtimeClassName: nvidia\n"})
+	if err != nil {
+		t.Fatalf("NewCapabilitiesFromMap() = %v", err)
+	}
+	if want := (&Capabilities{Capabilities: map[string]Capability{}}); !cmp.Equal(want, got) {
+		t.Errorf("NewCapabilitiesFromMap() = %v, want %v", got, want)
+	}
+}
+
+func TestNewCapabilitiesFromMapErrors(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{"GPU": "runtimeClassName: nvidia"},
+		{"gpu": "runtimeClassName: [nvidia"},
+		{"gpu": "nodeSelectors:\n  accelerator: nvidia"},
+		{"gpu": "{}"},
+		{"gpu": "runtimeClassName: Nvidia_GPU"},
+	} {
+		if _, err := NewCapabilitiesFromMap(cfg); err == nil {
+			t.Errorf("Expected an error for %v", cfg)
+		}
+	}
+}
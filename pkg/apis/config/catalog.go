@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CatalogPolicy is what happens to Tasks and Pipelines which drift from the
+// pinned catalog revision.
+type CatalogPolicy string
+
+const (
+	// CatalogConfigName is the name of the configmap pinning the catalog
+	CatalogConfigName = "config-catalog"
+
+	// CatalogPolicyAnnotate only marks drifting definitions as unverified.
+	CatalogPolicyAnnotate CatalogPolicy = "annotate"
+	// CatalogPolicyReject rejects drifting definitions when they are
+	// created or updated.
+	CatalogPolicyReject CatalogPolicy = "reject"
+
+	catalogRevisionKey = "revision"
+	catalogPolicyKey   = "policy"
+
+	// ChecksumPrefix prefixes the checksums of definitions.
+	ChecksumPrefix = "sha256:"
+)
+
+// Catalog holds the checksums of the Tasks and Pipelines of a pinned catalog
+// revision
+// +k8s:deepcopy-gen=true
+type Catalog struct {
+	Revision string
+	Policy   CatalogPolicy
+	// Checksums are indexed by <kind>.<name>, e.g. task.golang-build.
+	Checksums map[string]string
+}
+
+// NewCatalogFromMap returns a Catalog given a map corresponding to a ConfigMap
+func NewCatalogFromMap(cfgMap map[string]string) (*Catalog, error) {
+	c := Catalog{
+		Policy:    CatalogPolicyAnnotate,
+		Checksums: map[string]string{},
+	}
+	for k, v := range cfgMap {
+		switch {
+		case k == catalogRevisionKey:
+			c.Revision = v
+		case k == catalogPolicyKey:
+			switch p := CatalogPolicy(v); p {
+			case CatalogPolicyAnnotate, CatalogPolicyReject:
+				c.Policy = p
+			default:
+				return nil, fmt.Errorf("invalid catalog config %q: %q", catalogPolicyKey, v)
+			}
+		case strings.HasPrefix(k, "_"):
+			// Examples and other comments.
+		default:
+			if !strings.HasPrefix(v, ChecksumPrefix) {
+				return nil, fmt.Errorf("invalid checksum for %q, expected %s<hex>", k, ChecksumPrefix)
+			}
+			c.Checksums[k] = v
+		}
+	}
+	return &c, nil
+}
+
+// NewCatalogFromConfigMap returns a Catalog for the given configmap
+func NewCatalogFromConfigMap(config *corev1.ConfigMap) (*Catalog, error) {
+	return NewCatalogFromMap(config.Data)
+}
+
+// Pinned returns the checksum the catalog pins for the definition of the
+// given kind and name, if any.
+func (c *Catalog) Pinned(kind, name string) (string, bool) {
+	checksum, ok := c.Checksums[strings.ToLower(kind)+"."+name]
+	return checksum, ok
+}
+
+// Checksum returns the checksum of the spec of a definition, as stored in
+// the cluster.
+func Checksum(spec interface{}) (string, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return ChecksumPrefix + hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	test "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+)
+
+func TestNewCatalogFromConfigMap(t *testing.T) {
+	cm := test.ConfigMapFromTestFile(t, CatalogConfigName)
+	got, err := NewCatalogFromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("NewCatalogFromConfigMap() = %v", err)
+	}
+	want := &Catalog{
+		Revision: "v0.8.0",
+		Policy:   CatalogPolicyReject,
+		Checksums: map[string]string{
+			"task.golang-build": "sha256:0123",
+			"pipeline.release":  "sha256:4567",
+		},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff:\n%s", d)
+	}
+	if checksum, ok := got.Pinned("Task", "golang-build"); !ok || checksum != "sha256:0123" {
+		t.Errorf("Pinned(Task, golang-build) = %q, %t", checksum, ok)
+	}
+	if _, ok := got.Pinned("Pipeline", "golang-build"); ok {
+		t.Error("Expected Pipeline golang-build not to be pinned")
+	}
+}
+
+func TestNewCatalogFromMapErrors(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{"policy": "warn"},
+		{"task.golang-build": "0123"},
+	} {
+		if _, err := NewCatalogFromMap(cfg); err == nil {
+			t.Errorf("Expected an error for %v", cfg)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	a, err := Checksum(map[string]string{"image": "golang", "name": "build"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Checksum(map[string]string{"name": "build", "image": "golang"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b || !strings.HasPrefix(a, ChecksumPrefix) {
+		t.Errorf("Expected equal specs to have the same checksum, got %s and %s", a, b)
+	}
+	c, err := Checksum(map[string]string{"name": "build", "image": "golang:1.12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == c {
+		t.Errorf("Expected different specs to have different checksums, got %s", c)
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// CleanupConfigName is the name of the configmap of the cleanup of
+	// finished runs
+	CleanupConfigName = "config-cleanup"
+
+	archiveLocationKey           = "archive.location"
+	dryRunKey                    = "dry-run"
+	eventsSinkKey                = "events.sink"
+	resourcesKey                 = "cleanup-resources"
+	deletesPerSecondKey          = "deletes-per-second"
+	maxConcurrentDeletionsKey    = "max-concurrent-deletions"
+	minimumRetentionKey          = "minimum-retention"
+	allowedClockSkewKey          = "allowed-clock-skew"
+	blockingFinalizersKey        = "blocking-finalizers"
+	sweepIntervalKey             = "sweep-interval"
+	excludedNamespacesKey        = "excluded-namespaces"
+	excludedNamespaceSelectorKey = "excluded-namespace-selector"
+
+	// DefaultAllowedClockSkew is how far in the future the finish time of a
+	// run may be by default before its TTL isn't computed from it.
+	DefaultAllowedClockSkew = time.Minute
+	// DefaultSweepInterval is how often the expiration controllers sweep
+	// the runs whose TTL elapsed by default.
+	DefaultSweepInterval = time.Hour
+)
+
+// CleanupResources is what is deleted along with the TaskRuns the
+// expiration controller deletes.
+type CleanupResources string
+
+const (
+	// CleanupResourcesNone only deletes the TaskRuns, and leaves their pods
+	// to the garbage collector, which keeps the ones which lost their owner
+	// reference to them.
+	CleanupResourcesNone CleanupResources = "none"
+	// CleanupResourcesPods deletes the pods of the deleted TaskRuns, even
+	// the ones which lost their owner reference to them.
+	CleanupResourcesPods CleanupResources = "pods"
+	// CleanupResourcesPodsAndPVCs also deletes the PersistentVolumeClaims
+	// created for the deleted TaskRuns which their pods mounted.
+	CleanupResourcesPodsAndPVCs CleanupResources = "pods-and-pvcs"
+)
+
+// Cleanup holds the configuration of the cleanup of finished runs
+// +k8s:deepcopy-gen=true
+type Cleanup struct {
+	// ArchiveLocation is the URL of the sink the TaskRuns and their logs are
+	// archived to before being deleted, e.g. gs://bucket/prefix. Empty
+	// disables archiving.
+	ArchiveLocation string
+	// DryRun makes the expiration controllers log, and record events for,
+	// the runs they would delete instead of deleting them.
+	DryRun bool
+	// EventsSink is the URL the CloudEvents of the TaskRuns deleted by the
+	// expiration controller are sent to. Empty disables them.
+	EventsSink string
+	// Resources is what is deleted along with the TaskRuns. Defaults to
+	// their pods.
+	Resources CleanupResources
+	// DeletesPerSecond limits the rate of the deletions of each expiration
+	// controller. Zero doesn't limit it.
+	DeletesPerSecond float64
+	// MaxConcurrentDeletions limits the number of runs each expiration
+	// controller deletes at the same time. Zero doesn't limit it.
+	MaxConcurrentDeletions int
+	// MinimumRetention is how long finished runs are kept at least,
+	// whatever their TTL or history limit, e.g. so that a tiny TTL doesn't
+	// delete the evidence of a failure right away. Zero doesn't keep them.
+	MinimumRetention time.Duration
+	// AllowedClockSkew is how far in the future the finish time of a run,
+	// as seen by the controller, may be. The TTL of a run which finished
+	// further in the future is only computed once the clocks agree.
+	AllowedClockSkew time.Duration
+	// BlockingFinalizers are the finalizers which, while set on an expired
+	// run, hold off its cleanup, e.g. for the controllers archiving or
+	// signing the runs to process it first.
+	BlockingFinalizers []string
+	// SweepInterval is how often the expiration controllers go through all
+	// the finished runs to clean up the ones whose TTL elapsed, in case
+	// they weren't when it did, e.g. as the controller was restarting.
+	// Zero disables the sweeps.
+	SweepInterval time.Duration
+	// ExcludedNamespaces are the namespaces in which runs are never cleaned
+	// up, whatever their TTL or history limit.
+	ExcludedNamespaces []string
+	// ExcludedNamespaceSelector, if set, selects by their labels more
+	// namespaces in which runs are never cleaned up.
+	ExcludedNamespaceSelector labels.Selector
+}
+
+// ExcludesNamespace returns whether the runs of namespace ns are never
+// cleaned up: it is one of the ExcludedNamespaces, or matches the
+// ExcludedNamespaceSelector. ns is only looked up, by get, when it has to be
+// matched against the selector; runs are excluded if that fails, unless ns
+// doesn't exist anymore.
+func (c *Cleanup) ExcludesNamespace(ns string, get func(name string) (*corev1.Namespace, error)) bool {
+	for _, excluded := range c.ExcludedNamespaces {
+		if excluded == ns {
+			return true
+		}
+	}
+	if c.ExcludedNamespaceSelector == nil || c.ExcludedNamespaceSelector.Empty() {
+		return false
+	}
+	namespace, err := get(ns)
+	if err != nil {
+		return !errors.IsNotFound(err)
+	}
+	return c.ExcludedNamespaceSelector.Matches(labels.Set(namespace.Labels))
+}
+
+// NewCleanupFromMap returns a Cleanup given a map corresponding to a ConfigMap
+func NewCleanupFromMap(cfgMap map[string]string) (*Cleanup, error) {
+	c := Cleanup{Resources: CleanupResourcesPods, AllowedClockSkew: DefaultAllowedClockSkew, SweepInterval: DefaultSweepInterval}
+	if location, ok := cfgMap[archiveLocationKey]; ok && location != "" {
+		u, err := url.Parse(location)
+		if err != nil || u.Scheme == "" {
+			return nil, fmt.Errorf("invalid cleanup config %q: %q should be a URL", archiveLocationKey, location)
+		}
+		c.ArchiveLocation = location
+	}
+	if sink, ok := cfgMap[eventsSinkKey]; ok && sink != "" {
+		u, err := url.Parse(sink)
+		if err != nil || u.Scheme == "" {
+			return nil, fmt.Errorf("invalid cleanup config %q: %q should be a URL", eventsSinkKey, sink)
+		}
+		c.EventsSink = sink
+	}
+	if dryRun, ok := cfgMap[dryRunKey]; ok {
+		b, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cleanup config %q: %q should be a boolean", dryRunKey, dryRun)
+		}
+		c.DryRun = b
+	}
+	if resources, ok := cfgMap[resourcesKey]; ok {
+		switch r := CleanupResources(resources); r {
+		case CleanupResourcesNone, CleanupResourcesPods, CleanupResourcesPodsAndPVCs:
+			c.Resources = r
+		default:
+			return nil, fmt.Errorf("invalid cleanup config %q: %q should be one of %q, %q or %q", resourcesKey, resources,
+				CleanupResourcesNone, CleanupResourcesPods, CleanupResourcesPodsAndPVCs)
+		}
+	}
+	if deletesPerSecond, ok := cfgMap[deletesPerSecondKey]; ok {
+		qps, err := strconv.ParseFloat(deletesPerSecond, 64)
+		if err != nil || qps < 0 {
+			return nil, fmt.Errorf("invalid cleanup config %q: %q should be a non-negative number", deletesPerSecondKey, deletesPerSecond)
+		}
+		c.DeletesPerSecond = qps
+	}
+	if maxConcurrentDeletions, ok := cfgMap[maxConcurrentDeletionsKey]; ok {
+		n, err := strconv.Atoi(maxConcurrentDeletions)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid cleanup config %q: %q should be a non-negative integer", maxConcurrentDeletionsKey, maxConcurrentDeletions)
+		}
+		c.MaxConcurrentDeletions = n
+	}
+	if minimumRetention, ok := cfgMap[minimumRetentionKey]; ok {
+		d, err := time.ParseDuration(minimumRetention)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid cleanup config %q: %q should be a non-negative duration", minimumRetentionKey, minimumRetention)
+		}
+		c.MinimumRetention = d
+	}
+	if allowedClockSkew, ok := cfgMap[allowedClockSkewKey]; ok {
+		d, err := time.ParseDuration(allowedClockSkew)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid cleanup config %q: %q should be a non-negative duration", allowedClockSkewKey, allowedClockSkew)
+		}
+		c.AllowedClockSkew = d
+	}
+	if sweepInterval, ok := cfgMap[sweepIntervalKey]; ok {
+		d, err := time.ParseDuration(sweepInterval)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid cleanup config %q: %q should be a non-negative duration", sweepIntervalKey, sweepInterval)
+		}
+		c.SweepInterval = d
+	}
+	if excludedNamespaces, ok := cfgMap[excludedNamespacesKey]; ok {
+		for _, ns := range strings.Split(excludedNamespaces, ",") {
+			ns = strings.TrimSpace(ns)
+			if ns == "" {
+				continue
+			}
+			if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid cleanup config %q: %q isn't a valid namespace: %s", excludedNamespacesKey, ns, strings.Join(errs, ", "))
+			}
+			c.ExcludedNamespaces = append(c.ExcludedNamespaces, ns)
+		}
+	}
+	if selector, ok := cfgMap[excludedNamespaceSelectorKey]; ok && strings.TrimSpace(selector) != "" {
+		s, err := labels.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cleanup config %q: %q isn't a valid label selector: %v", excludedNamespaceSelectorKey, selector, err)
+		}
+		c.ExcludedNamespaceSelector = s
+	}
+	if blockingFinalizers, ok := cfgMap[blockingFinalizersKey]; ok {
+		for _, f := range strings.Split(blockingFinalizers, ",") {
+			f = strings.TrimSpace(f)
+			if f == "" {
+				continue
+			}
+			if errs := validation.IsQualifiedName(f); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid cleanup config %q: %q isn't a valid finalizer: %s", blockingFinalizersKey, f, strings.Join(errs, ", "))
+			}
+			c.BlockingFinalizers = append(c.BlockingFinalizers, f)
+		}
+	}
+	return &c, nil
+}
+
+// NewCleanupFromConfigMap returns a Cleanup for the given configmap
+func NewCleanupFromConfigMap(config *corev1.ConfigMap) (*Cleanup, error) {
+	return NewCleanupFromMap(config.Data)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	test "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// compareSelectors compares label selectors by their string representation,
+// as their requirements have unexported fields.
+var compareSelectors = cmp.Comparer(func(x, y labels.Selector) bool {
+	if x == nil || y == nil {
+		return x == nil && y == nil
+	}
+	return x.String() == y.String()
+})
+
+func TestNewCleanupFromConfigMap(t *testing.T) {
+	cm := test.ConfigMapFromTestFile(t, CleanupConfigName)
+	got, err := NewCleanupFromConfigMap(cm)
+	if err != nil {
+		t.Fatalf("NewCleanupFromConfigMap() = %v", err)
+	}
+	want := &Cleanup{ArchiveLocation: "gs://archive-bucket/taskruns", DryRun: true, EventsSink: "http://audit.example.com/events", Resources: CleanupResourcesPodsAndPVCs,
+		DeletesPerSecond: 2.5, MaxConcurrentDeletions: 4, MinimumRetention: 30 * time.Minute, AllowedClockSkew: 5 * time.Minute,
+		BlockingFinalizers: []string{"results.tekton.dev/archive", "chains.tekton.dev"}, SweepInterval: 10 * time.Minute,
+		ExcludedNamespaces: []string{"prod-audit", "compliance"}, ExcludedNamespaceSelector: labels.SelectorFromSet(labels.Set{"tekton.dev/cleanup": "disabled"})}
+	if d := cmp.Diff(want, got, compareSelectors); d != "" {
+		t.Errorf("Diff:\n%s", d)
+	}
+}
+
+func TestNewCleanupFromEmptyMap(t *testing.T) {
+	got, err := NewCleanupFromMap(map[string]string{})
+	if err != nil {
+		t.Fatalf("NewCleanupFromMap() = %v", err)
+	}
+	if want := (&Cleanup{Resources: CleanupResourcesPods, AllowedClockSkew: DefaultAllowedClockSkew, SweepInterval: DefaultSweepInterval}); !cmp.Equal(want, got, compareSelectors) {
+		t.Errorf("NewCleanupFromMap() = %v, want %v", got, want)
+	}
+}
+
+func TestNewCleanupFromMapErrors(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{"archive.location": "archive-bucket"},
+		{"archive.location": "gs://archive bucket/%zz"},
+		{"dry-run": "maybe"},
+		{"events.sink": "audit"},
+		{"cleanup-resources": "pvcs"},
+		{"deletes-per-second": "fast"},
+		{"deletes-per-second": "-1"},
+		{"max-concurrent-deletions": "1.5"},
+		{"max-concurrent-deletions": "-1"},
+		{"minimum-retention": "30"},
+		{"minimum-retention": "-1h"},
+		{"allowed-clock-skew": "5"},
+		{"allowed-clock-skew": "-1m"},
+		{"sweep-interval": "10"},
+		{"sweep-interval": "-10m"},
+		{"excluded-namespaces": "prod-audit,Prod_Audit"},
+		{"excluded-namespace-selector": "tekton.dev/cleanup in disabled"},
+		{"blocking-finalizers": "results.tekton.dev/archive,not a finalizer"},
+	} {
+		if _, err := NewCleanupFromMap(cfg); err == nil {
+			t.Errorf("Expected an error for %v", cfg)
+		}
+	}
+}
+
+func TestExcludesNamespace(t *testing.T) {
+	namespaces := map[string]*corev1.Namespace{
+		"protected": {ObjectMeta: metav1.ObjectMeta{Name: "protected", Labels: map[string]string{"tekton.dev/cleanup": "disabled"}}},
+		"default":   {ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	}
+	get := func(name string) (*corev1.Namespace, error) {
+		if name == "unreachable" {
+			return nil, errors.NewServiceUnavailable("unreachable")
+		}
+		if ns, ok := namespaces[name]; ok {
+			return ns, nil
+		}
+		return nil, errors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, name)
+	}
+
+	c, err := NewCleanupFromMap(map[string]string{
+		"excluded-namespaces":         "prod-audit",
+		"excluded-namespace-selector": "tekton.dev/cleanup=disabled",
+	})
+	if err != nil {
+		t.Fatalf("NewCleanupFromMap() = %v", err)
+	}
+	for ns, want := range map[string]bool{
+		"prod-audit":  true,
+		"protected":   true,
+		"default":     false,
+		"deleted":     false,
+		"unreachable": true,
+	} {
+		if got := c.ExcludesNamespace(ns, get); got != want {
+			t.Errorf("ExcludesNamespace(%q) = %t, want %t", ns, got, want)
+		}
+	}
+
+	if (&Cleanup{}).ExcludesNamespace("unreachable", get) {
+		t.Error("Expected no namespace to be excluded by default")
+	}
+}
@@ -17,20 +17,54 @@ limitations under the License.
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ghodss/yaml"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
 	// ConfigName is the name of the configmap
-	DefaultsConfigName       = "config-defaults"
-	DefaultTimeoutMinutes    = 60
-	NoTimeoutDuration        = 0 * time.Minute
-	defaultTimeoutMinutesKey = "default-timeout-minutes"
-	defaultServiceAccountKey = "default-service-account"
+	DefaultsConfigName           = "config-defaults"
+	DefaultTimeoutMinutes        = 60
+	NoTimeoutDuration            = 0 * time.Minute
+	defaultTimeoutMinutesKey     = "default-timeout-minutes"
+	defaultServiceAccountKey     = "default-service-account"
+	defaultBuildProfileKey       = "default-build-profile"
+	forbidPrivilegedStepsKey     = "forbid-privileged-steps"
+	defaultTaskRunTTLKey         = "default-task-run-ttl"
+	registryMirrorsKey           = "registry-mirrors"
+	defaultFSGroupKey            = "default-fs-group"
+	defaultSupplementalGroupsKey = "default-supplemental-groups"
+
+	defaultScriptEphemeralStorageRequestKey = "default-script-ephemeral-storage-request"
+	defaultScriptEphemeralStorageLimitKey   = "default-script-ephemeral-storage-limit"
+	defaultWorkspaceSizeLimitKey            = "default-workspace-size-limit"
+	defaultContainerResourceRequirementsKey = "default-container-resource-requirements"
+	disableWorkingDirInitKey                = "disable-working-dir-init"
+
+	entrypointReadyTimeoutKey    = "entrypoint-ready-timeout"
+	entrypointWaitFileTimeoutKey = "entrypoint-wait-file-timeout"
+	stepProgressIntervalKey      = "step-progress-interval"
+	stepLogTimestampsKey         = "step-log-timestamps"
+	stepLogStepNamesKey          = "step-log-step-names"
+
+	lintScriptsKey                = "lint-scripts"
+	structuredValidationErrorsKey = "structured-validation-errors"
+	strictStepTemplateKey         = "strict-step-template"
+	requireImageDigestsKey        = "require-image-digests"
+	bannedImageRegistriesKey      = "banned-image-registries"
+
+	clusterNameKey = "cluster-name"
 )
 
 // Defaults holds the default configurations
@@ -38,12 +72,174 @@ const (
 type Defaults struct {
 	DefaultTimeoutMinutes int
 	DefaultServiceAccount string
+	DefaultBuildProfile   string
+	ForbidPrivilegedSteps bool
+	// DefaultTaskRunTTL is the time after which finished TaskRuns without a
+	// TTL of their own are deleted. Zero means they are never deleted.
+	DefaultTaskRunTTL time.Duration
+	// RegistryMirrors maps registries to the mirror the config of their
+	// images is fetched from when it can't be fetched from them.
+	RegistryMirrors map[string]string
+	// DefaultFSGroup is the fsGroup of the pods of the TaskRuns and
+	// PipelineRuns whose podTemplate doesn't specify one, so that the
+	// volumes of their steps are writable by non-root users.
+	DefaultFSGroup *int64
+	// DefaultSupplementalGroups are the supplementalGroups of the pods of
+	// the TaskRuns and PipelineRuns whose podTemplate doesn't specify any.
+	DefaultSupplementalGroups []int64
+	// DefaultScriptEphemeralStorageRequest and
+	// DefaultScriptEphemeralStorageLimit are the ephemeral-storage request
+	// and limit of the script steps which don't set them, so that the pods
+	// writing to the disk of their node are scheduled and evicted
+	// accordingly.
+	DefaultScriptEphemeralStorageRequest *resource.Quantity
+	DefaultScriptEphemeralStorageLimit   *resource.Quantity
+	// DefaultWorkspaceSizeLimit is the sizeLimit of the emptyDirs of the
+	// workspace and home directories of the steps.
+	DefaultWorkspaceSizeLimit *resource.Quantity
+	// DefaultContainerResourceRequirements are the requests and limits of
+	// the steps which don't set them, so that the pods of the Tasks whose
+	// authors omit them are scheduled on nodes with room for them.
+	// NamespaceContainerResourceRequirements replace them in the namespaces
+	// they are indexed by.
+	DefaultContainerResourceRequirements   *corev1.ResourceRequirements
+	NamespaceContainerResourceRequirements map[string]corev1.ResourceRequirements
+	// DisableWorkingDirInit is whether the pods go without the init
+	// container creating the workingDirs of their steps under /workspace,
+	// e.g. where init containers can't run as root to hand them over to
+	// the users the steps run as.
+	DisableWorkingDirInit bool
+	// EntrypointReadyTimeout is how long the first step waits for the pod
+	// to be ready, which the Downward API tells it, before failing. Zero
+	// means it waits until the TaskRun times out.
+	EntrypointReadyTimeout time.Duration
+	// EntrypointWaitFileTimeout is how long the other steps wait for the
+	// previous one to finish before failing. Zero means they wait until the
+	// TaskRun times out.
+	EntrypointWaitFileTimeout time.Duration
+	// StepProgressInterval is how often the entrypoints report the progress
+	// their steps write to /tekton/progress, if it changed. Zero means it
+	// isn't reported.
+	StepProgressInterval time.Duration
+	// StepLogTimestamps and StepLogStepNames are whether the entrypoints
+	// prefix each line of the output of their steps with the time it was
+	// written at, and with the name of the step.
+	StepLogTimestamps bool
+	StepLogStepNames  bool
+	// LintScripts is whether the webhook rejects the Tasks and StepActions
+	// whose scripts a linter of their interpreter finds broken.
+	LintScripts bool
+	// StructuredValidationErrors is whether the webhook details the field,
+	// rule and message of each validation error of the resources it
+	// rejects, for the clients to map them back to the fields.
+	StructuredValidationErrors bool
+	// StrictStepTemplate is whether the webhook rejects the Tasks whose
+	// steps override a value of their stepTemplate, rather than silently
+	// letting the value of the step win.
+	StrictStepTemplate bool
+	// RequireImageDigests is whether the webhook rejects the Tasks whose
+	// steps, init steps or sidecars reference their image by tag rather
+	// than by digest.
+	RequireImageDigests bool
+	// BannedImageRegistries are the registries, or repository prefixes,
+	// e.g. docker.io/library, the webhook rejects the Tasks pulling images
+	// from.
+	BannedImageRegistries []string
+	// ClusterName is the name of the cluster in the identity of the runs,
+	// e.g. in the labels of their pods and in their CloudEvents, for the
+	// systems collecting them from several clusters.
+	ClusterName string
 }
 
 // Equals returns true if two Configs are identical
 func (cfg *Defaults) Equals(other *Defaults) bool {
 	return other.DefaultTimeoutMinutes == cfg.DefaultTimeoutMinutes &&
-		other.DefaultServiceAccount == cfg.DefaultServiceAccount
+		other.DefaultServiceAccount == cfg.DefaultServiceAccount &&
+		other.DefaultBuildProfile == cfg.DefaultBuildProfile &&
+		other.ForbidPrivilegedSteps == cfg.ForbidPrivilegedSteps &&
+		other.DefaultTaskRunTTL == cfg.DefaultTaskRunTTL &&
+		reflect.DeepEqual(other.RegistryMirrors, cfg.RegistryMirrors) &&
+		reflect.DeepEqual(other.DefaultFSGroup, cfg.DefaultFSGroup) &&
+		reflect.DeepEqual(other.DefaultSupplementalGroups, cfg.DefaultSupplementalGroups) &&
+		equalQuantities(other.DefaultScriptEphemeralStorageRequest, cfg.DefaultScriptEphemeralStorageRequest) &&
+		equalQuantities(other.DefaultScriptEphemeralStorageLimit, cfg.DefaultScriptEphemeralStorageLimit) &&
+		equalQuantities(other.DefaultWorkspaceSizeLimit, cfg.DefaultWorkspaceSizeLimit) &&
+		equality.Semantic.DeepEqual(other.DefaultContainerResourceRequirements, cfg.DefaultContainerResourceRequirements) &&
+		equality.Semantic.DeepEqual(other.NamespaceContainerResourceRequirements, cfg.NamespaceContainerResourceRequirements) &&
+		other.DisableWorkingDirInit == cfg.DisableWorkingDirInit &&
+		other.EntrypointReadyTimeout == cfg.EntrypointReadyTimeout &&
+		other.EntrypointWaitFileTimeout == cfg.EntrypointWaitFileTimeout &&
+		other.StepProgressInterval == cfg.StepProgressInterval &&
+		other.StepLogTimestamps == cfg.StepLogTimestamps &&
+		other.StepLogStepNames == cfg.StepLogStepNames &&
+		other.LintScripts == cfg.LintScripts &&
+		other.StructuredValidationErrors == cfg.StructuredValidationErrors &&
+		other.StrictStepTemplate == cfg.StrictStepTemplate &&
+		other.RequireImageDigests == cfg.RequireImageDigests &&
+		reflect.DeepEqual(other.BannedImageRegistries, cfg.BannedImageRegistries) &&
+		other.ClusterName == cfg.ClusterName
+}
+
+// equalQuantities returns whether the optional quantities a and b are equal.
+func equalQuantities(a, b *resource.Quantity) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(*b) == 0
+}
+
+// parsePositiveQuantity parses the quantity of key in cfgMap, if any.
+func parsePositiveQuantity(cfgMap map[string]string, key string) (*resource.Quantity, error) {
+	value, ok := cfgMap[key]
+	if !ok || value == "" {
+		return nil, nil
+	}
+	q, err := resource.ParseQuantity(value)
+	if err != nil || q.Sign() <= 0 {
+		return nil, fmt.Errorf("failed parsing defaults config %q: %q isn't a positive quantity", key, value)
+	}
+	return &q, nil
+}
+
+// parseResourceRequirements parses the requests and limits, in YAML, of key
+// in cfgMap.
+func parseResourceRequirements(key, value string) (*corev1.ResourceRequirements, error) {
+	j, err := yaml.YAMLToJSON([]byte(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing defaults config %q: %v", key, err)
+	}
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.DisallowUnknownFields()
+	var requirements corev1.ResourceRequirements
+	if err := d.Decode(&requirements); err != nil {
+		return nil, fmt.Errorf("failed parsing defaults config %q: %v", key, err)
+	}
+	for name, q := range requirements.Requests {
+		if q.Sign() <= 0 {
+			return nil, fmt.Errorf("failed parsing defaults config %q: the %s request isn't a positive quantity", key, name)
+		}
+		if limit, ok := requirements.Limits[name]; ok && q.Cmp(limit) > 0 {
+			return nil, fmt.Errorf("failed parsing defaults config %q: the %s request is greater than its limit", key, name)
+		}
+	}
+	for name, q := range requirements.Limits {
+		if q.Sign() <= 0 {
+			return nil, fmt.Errorf("failed parsing defaults config %q: the %s limit isn't a positive quantity", key, name)
+		}
+	}
+	return &requirements, nil
+}
+
+// ContainerResourceRequirements returns the requests and limits of the steps
+// of the TaskRuns of namespace which don't set them, if any.
+func (cfg *Defaults) ContainerResourceRequirements(namespace string) *corev1.ResourceRequirements {
+	if cfg == nil {
+		return nil
+	}
+	if requirements, ok := cfg.NamespaceContainerResourceRequirements[namespace]; ok {
+		return &requirements
+	}
+	return cfg.DefaultContainerResourceRequirements
 }
 
 // NewDefaultsFromMap returns a Config given a map corresponding to a ConfigMap
@@ -63,6 +259,143 @@ func NewDefaultsFromMap(cfgMap map[string]string) (*Defaults, error) {
 		tc.DefaultServiceAccount = defaultServiceAccount
 	}
 
+	if defaultBuildProfile, ok := cfgMap[defaultBuildProfileKey]; ok {
+		tc.DefaultBuildProfile = defaultBuildProfile
+	}
+
+	// The cluster name is a label value of the pods.
+	if clusterName, ok := cfgMap[clusterNameKey]; ok {
+		if errs := validation.IsValidLabelValue(clusterName); len(errs) > 0 {
+			return nil, fmt.Errorf("failed parsing defaults config %q: %s", clusterNameKey, strings.Join(errs, ", "))
+		}
+		tc.ClusterName = clusterName
+	}
+
+	if forbidPrivilegedSteps, ok := cfgMap[forbidPrivilegedStepsKey]; ok {
+		forbid, err := strconv.ParseBool(forbidPrivilegedSteps)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing defaults config %q", forbidPrivilegedStepsKey)
+		}
+		tc.ForbidPrivilegedSteps = forbid
+	}
+
+	for key, enabled := range map[string]*bool{
+		stepLogTimestampsKey:          &tc.StepLogTimestamps,
+		stepLogStepNamesKey:           &tc.StepLogStepNames,
+		lintScriptsKey:                &tc.LintScripts,
+		structuredValidationErrorsKey: &tc.StructuredValidationErrors,
+		strictStepTemplateKey:         &tc.StrictStepTemplate,
+		requireImageDigestsKey:        &tc.RequireImageDigests,
+		disableWorkingDirInitKey:      &tc.DisableWorkingDirInit,
+	} {
+		if value, ok := cfgMap[key]; ok {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing defaults config %q", key)
+			}
+			*enabled = b
+		}
+	}
+
+	if defaultTaskRunTTL, ok := cfgMap[defaultTaskRunTTLKey]; ok {
+		ttl, err := time.ParseDuration(defaultTaskRunTTL)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("failed parsing defaults config %q", defaultTaskRunTTLKey)
+		}
+		tc.DefaultTaskRunTTL = ttl
+	}
+
+	if registryMirrors, ok := cfgMap[registryMirrorsKey]; ok && registryMirrors != "" {
+		tc.RegistryMirrors = map[string]string{}
+		for _, m := range strings.Split(registryMirrors, ",") {
+			parts := strings.Split(strings.TrimSpace(m), "=")
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return nil, fmt.Errorf("failed parsing defaults config %q: %q should be registry=mirror", registryMirrorsKey, m)
+			}
+			tc.RegistryMirrors[parts[0]] = parts[1]
+		}
+	}
+
+	if bannedImageRegistries, ok := cfgMap[bannedImageRegistriesKey]; ok && bannedImageRegistries != "" {
+		for _, r := range strings.Split(bannedImageRegistries, ",") {
+			r = strings.TrimSuffix(strings.TrimSpace(r), "/")
+			if r == "" {
+				return nil, fmt.Errorf("failed parsing defaults config %q: empty registry", bannedImageRegistriesKey)
+			}
+			tc.BannedImageRegistries = append(tc.BannedImageRegistries, r)
+		}
+	}
+
+	if defaultFSGroup, ok := cfgMap[defaultFSGroupKey]; ok && defaultFSGroup != "" {
+		group, err := strconv.ParseInt(defaultFSGroup, 10, 64)
+		if err != nil || group < 0 {
+			return nil, fmt.Errorf("failed parsing defaults config %q", defaultFSGroupKey)
+		}
+		tc.DefaultFSGroup = &group
+	}
+
+	if defaultSupplementalGroups, ok := cfgMap[defaultSupplementalGroupsKey]; ok && defaultSupplementalGroups != "" {
+		for _, g := range strings.Split(defaultSupplementalGroups, ",") {
+			group, err := strconv.ParseInt(strings.TrimSpace(g), 10, 64)
+			if err != nil || group < 0 {
+				return nil, fmt.Errorf("failed parsing defaults config %q: %q isn't a group ID", defaultSupplementalGroupsKey, g)
+			}
+			tc.DefaultSupplementalGroups = append(tc.DefaultSupplementalGroups, group)
+		}
+	}
+
+	var err error
+	if tc.DefaultScriptEphemeralStorageRequest, err = parsePositiveQuantity(cfgMap, defaultScriptEphemeralStorageRequestKey); err != nil {
+		return nil, err
+	}
+	if tc.DefaultScriptEphemeralStorageLimit, err = parsePositiveQuantity(cfgMap, defaultScriptEphemeralStorageLimitKey); err != nil {
+		return nil, err
+	}
+	if request, limit := tc.DefaultScriptEphemeralStorageRequest, tc.DefaultScriptEphemeralStorageLimit; request != nil && limit != nil && request.Cmp(*limit) > 0 {
+		return nil, fmt.Errorf("failed parsing defaults config: %q is greater than %q", defaultScriptEphemeralStorageRequestKey, defaultScriptEphemeralStorageLimitKey)
+	}
+	if tc.DefaultWorkspaceSizeLimit, err = parsePositiveQuantity(cfgMap, defaultWorkspaceSizeLimitKey); err != nil {
+		return nil, err
+	}
+
+	// The requirements of a namespace are keyed by
+	// default-container-resource-requirements.<namespace>.
+	for key, value := range cfgMap {
+		namespace := strings.TrimPrefix(key, defaultContainerResourceRequirementsKey+".")
+		if (key != defaultContainerResourceRequirementsKey && namespace == key) || value == "" {
+			continue
+		}
+		requirements, err := parseResourceRequirements(key, value)
+		if err != nil {
+			return nil, err
+		}
+		if key == defaultContainerResourceRequirementsKey {
+			tc.DefaultContainerResourceRequirements = requirements
+			continue
+		}
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return nil, fmt.Errorf("failed parsing defaults config %q: %s", key, strings.Join(errs, ", "))
+		}
+		if tc.NamespaceContainerResourceRequirements == nil {
+			tc.NamespaceContainerResourceRequirements = map[string]corev1.ResourceRequirements{}
+		}
+		tc.NamespaceContainerResourceRequirements[namespace] = *requirements
+	}
+
+	for key, timeout := range map[string]*time.Duration{
+		entrypointReadyTimeoutKey:    &tc.EntrypointReadyTimeout,
+		entrypointWaitFileTimeoutKey: &tc.EntrypointWaitFileTimeout,
+		stepProgressIntervalKey:      &tc.StepProgressInterval,
+	} {
+		if value, ok := cfgMap[key]; ok {
+			d, err := time.ParseDuration(value)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("failed parsing defaults config %q", key)
+			}
+			*timeout = d
+		}
+	}
+
 	return &tc, nil
 }
 
@@ -18,15 +18,55 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	test "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestNewDefaultsFromConfigMap(t *testing.T) {
+	fsGroup := int64(65532)
+	scriptRequest, scriptLimit, workspaceLimit := resource.MustParse("1Gi"), resource.MustParse("10Gi"), resource.MustParse("20Gi")
+	containerRequirements := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
 	expectedConfig := &Defaults{
 		DefaultTimeoutMinutes: 50,
 		DefaultServiceAccount: "tekton",
+		DefaultBuildProfile:   "rootless",
+		ForbidPrivilegedSteps: true,
+		DefaultTaskRunTTL:     24 * time.Hour,
+		RegistryMirrors: map[string]string{
+			"docker.io": "mirror.gcr.io",
+			"quay.io":   "quay-mirror.example.com",
+		},
+		DefaultFSGroup:            &fsGroup,
+		DefaultSupplementalGroups: []int64{1000, 2000},
+
+		DefaultScriptEphemeralStorageRequest: &scriptRequest,
+		DefaultScriptEphemeralStorageLimit:   &scriptLimit,
+		DefaultWorkspaceSizeLimit:            &workspaceLimit,
+
+		DefaultContainerResourceRequirements: containerRequirements,
+		NamespaceContainerResourceRequirements: map[string]corev1.ResourceRequirements{
+			"ml-team": {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("8Gi")}},
+		},
+		DisableWorkingDirInit: true,
+
+		EntrypointReadyTimeout:     5 * time.Minute,
+		EntrypointWaitFileTimeout:  2 * time.Hour,
+		StepProgressInterval:       30 * time.Second,
+		StepLogTimestamps:          true,
+		StepLogStepNames:           true,
+		LintScripts:                true,
+		StructuredValidationErrors: true,
+		StrictStepTemplate:         true,
+		RequireImageDigests:        true,
+		BannedImageRegistries:      []string{"docker.io", "quay.io/untrusted"},
+		ClusterName:                "ci-east",
 	}
 	verifyConfigFileWithExpectedConfig(t, DefaultsConfigName, expectedConfig)
 }
@@ -39,13 +79,117 @@ func TestNewDefaultsFromEmptyConfigMap(t *testing.T) {
 	verifyConfigFileWithExpectedConfig(t, DefaultsConfigEmptyName, expectedConfig)
 }
 
+func TestNewDefaultsFromMapInvalidGroups(t *testing.T) {
+	for _, cfgMap := range []map[string]string{
+		{defaultFSGroupKey: "root"},
+		{defaultFSGroupKey: "-1"},
+		{defaultSupplementalGroupsKey: "1000,,2000"},
+		{defaultSupplementalGroupsKey: "1000,wheel"},
+	} {
+		if _, err := NewDefaultsFromMap(cfgMap); err == nil {
+			t.Errorf("Expected an error parsing %v", cfgMap)
+		}
+	}
+}
+
+func TestNewDefaultsFromMapInvalidEphemeralStorage(t *testing.T) {
+	for _, cfgMap := range []map[string]string{
+		{defaultScriptEphemeralStorageRequestKey: "a lot"},
+		{defaultScriptEphemeralStorageLimitKey: "-1Gi"},
+		{defaultWorkspaceSizeLimitKey: "0"},
+		{disableWorkingDirInitKey: "maybe"},
+		{defaultScriptEphemeralStorageRequestKey: "2Gi", defaultScriptEphemeralStorageLimitKey: "1Gi"},
+		{defaultContainerResourceRequirementsKey: "requests: [cpu"},
+		{defaultContainerResourceRequirementsKey: "request:\n  cpu: 100m"},
+		{defaultContainerResourceRequirementsKey: "requests:\n  cpu: lots"},
+		{defaultContainerResourceRequirementsKey: "requests:\n  cpu: \"0\""},
+		{defaultContainerResourceRequirementsKey: "limits:\n  memory: -1Gi"},
+		{defaultContainerResourceRequirementsKey: "requests:\n  memory: 2Gi\nlimits:\n  memory: 1Gi"},
+		{defaultContainerResourceRequirementsKey + ".ML_Team": "requests:\n  cpu: \"2\""},
+	} {
+		if _, err := NewDefaultsFromMap(cfgMap); err == nil {
+			t.Errorf("Expected an error parsing %v", cfgMap)
+		}
+	}
+}
+
+func TestNewDefaultsFromMapInvalidEntrypointTimeouts(t *testing.T) {
+	for _, cfgMap := range []map[string]string{
+		{entrypointReadyTimeoutKey: "10"},
+		{entrypointWaitFileTimeoutKey: "-1h"},
+		{stepProgressIntervalKey: "often"},
+	} {
+		if _, err := NewDefaultsFromMap(cfgMap); err == nil {
+			t.Errorf("Expected an error parsing %v", cfgMap)
+		}
+	}
+}
+
+func TestNewDefaultsFromMapInvalidStepLogPrefixes(t *testing.T) {
+	for _, cfgMap := range []map[string]string{
+		{stepLogTimestampsKey: "rfc3339"},
+		{stepLogStepNamesKey: "yes please"},
+	} {
+		if _, err := NewDefaultsFromMap(cfgMap); err == nil {
+			t.Errorf("Expected an error parsing %v", cfgMap)
+		}
+	}
+}
+
+func TestNewDefaultsFromMapInvalidWebhookSettings(t *testing.T) {
+	for _, cfgMap := range []map[string]string{
+		{lintScriptsKey: "sometimes"},
+		{structuredValidationErrorsKey: "json"},
+		{strictStepTemplateKey: "warn"},
+		{requireImageDigestsKey: "sha256"},
+		{bannedImageRegistriesKey: "docker.io,,quay.io"},
+	} {
+		if _, err := NewDefaultsFromMap(cfgMap); err == nil {
+			t.Errorf("Expected an error parsing %v", cfgMap)
+		}
+	}
+}
+
+func TestNewDefaultsFromMapInvalidClusterName(t *testing.T) {
+	cfgMap := map[string]string{clusterNameKey: "ci/east"}
+	if _, err := NewDefaultsFromMap(cfgMap); err == nil {
+		t.Errorf("Expected an error parsing %v", cfgMap)
+	}
+}
+
+var resourceQuantityCmp = cmp.Comparer(func(x, y resource.Quantity) bool {
+	return x.Cmp(y) == 0
+})
+
 func verifyConfigFileWithExpectedConfig(t *testing.T, fileName string, expectedConfig *Defaults) {
 	cm := test.ConfigMapFromTestFile(t, fileName)
 	if Defaults, err := NewDefaultsFromConfigMap(cm); err == nil {
-		if d := cmp.Diff(Defaults, expectedConfig); d != "" {
+		if d := cmp.Diff(Defaults, expectedConfig, resourceQuantityCmp); d != "" {
 			t.Errorf("Diff:\n%s", d)
 		}
 	} else {
 		t.Errorf("NewDefaultsFromConfigMap(actual) = %v", err)
 	}
 }
+
+func TestContainerResourceRequirements(t *testing.T) {
+	cfg, err := NewDefaultsFromMap(map[string]string{
+		defaultContainerResourceRequirementsKey:              "requests:\n  cpu: 100m",
+		defaultContainerResourceRequirementsKey + ".ml-team": "requests:\n  cpu: 2",
+	})
+	if err != nil {
+		t.Fatalf("NewDefaultsFromMap() = %v", err)
+	}
+	for namespace, want := range map[string]string{
+		"default": "100m",
+		"ml-team": "2",
+	} {
+		got := cfg.ContainerResourceRequirements(namespace).Requests[corev1.ResourceCPU]
+		if got.Cmp(resource.MustParse(want)) != 0 {
+			t.Errorf("ContainerResourceRequirements(%q) requests %s of CPU, want %s", namespace, got.String(), want)
+		}
+	}
+	if got := (&Defaults{}).ContainerResourceRequirements("default"); got != nil {
+		t.Errorf("Expected no requirements by default, got %v", got)
+	}
+}
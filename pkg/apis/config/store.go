@@ -27,7 +27,10 @@ type cfgKey struct{}
 // Config holds the collection of configurations that we attach to contexts.
 // +k8s:deepcopy-gen=false
 type Config struct {
-	Defaults *Defaults
+	Defaults     *Defaults
+	Catalog      *Catalog
+	Cleanup      *Cleanup
+	Capabilities *Capabilities
 }
 
 // FromContext extracts a Config from the provided context.
@@ -46,8 +49,14 @@ func FromContextOrDefaults(ctx context.Context) *Config {
 		return cfg
 	}
 	defaults, _ := NewDefaultsFromMap(map[string]string{})
+	catalog, _ := NewCatalogFromMap(map[string]string{})
+	cleanup, _ := NewCleanupFromMap(map[string]string{})
+	capabilities, _ := NewCapabilitiesFromMap(map[string]string{})
 	return &Config{
-		Defaults: defaults,
+		Defaults:     defaults,
+		Catalog:      catalog,
+		Cleanup:      cleanup,
+		Capabilities: capabilities,
 	}
 }
 
@@ -70,7 +79,10 @@ func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value i
 			"defaults",
 			logger,
 			configmap.Constructors{
-				DefaultsConfigName: NewDefaultsFromConfigMap,
+				DefaultsConfigName:     NewDefaultsFromConfigMap,
+				CatalogConfigName:      NewCatalogFromConfigMap,
+				CleanupConfigName:      NewCleanupFromConfigMap,
+				CapabilitiesConfigName: NewCapabilitiesFromConfigMap,
 			},
 			onAfterStore...,
 		),
@@ -86,7 +98,27 @@ func (s *Store) ToContext(ctx context.Context) context.Context {
 
 // Load creates a Config from the current config state of the Store.
 func (s *Store) Load() *Config {
+	defaults, ok := s.UntypedLoad(DefaultsConfigName).(*Defaults)
+	if !ok {
+		// The ConfigMap hasn't been seen yet.
+		defaults, _ = NewDefaultsFromMap(map[string]string{})
+	}
+	catalog, ok := s.UntypedLoad(CatalogConfigName).(*Catalog)
+	if !ok {
+		catalog, _ = NewCatalogFromMap(map[string]string{})
+	}
+	cleanup, ok := s.UntypedLoad(CleanupConfigName).(*Cleanup)
+	if !ok {
+		cleanup, _ = NewCleanupFromMap(map[string]string{})
+	}
+	capabilities, ok := s.UntypedLoad(CapabilitiesConfigName).(*Capabilities)
+	if !ok {
+		capabilities, _ = NewCapabilitiesFromMap(map[string]string{})
+	}
 	return &Config{
-		Defaults: s.UntypedLoad(DefaultsConfigName).(*Defaults).DeepCopy(),
+		Defaults:     defaults.DeepCopy(),
+		Catalog:      catalog.DeepCopy(),
+		Cleanup:      cleanup.DeepCopy(),
+		Capabilities: capabilities.DeepCopy(),
 	}
 }
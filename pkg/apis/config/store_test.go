@@ -33,7 +33,7 @@ func TestStoreLoadWithContext(t *testing.T) {
 	config := FromContext(store.ToContext(context.Background()))
 
 	expected, _ := NewDefaultsFromConfigMap(defaultConfig)
-	if diff := cmp.Diff(config.Defaults, expected); diff != "" {
+	if diff := cmp.Diff(config.Defaults, expected, resourceQuantityCmp); diff != "" {
 		t.Errorf("Unexpected default config (-want, +got): %v", diff)
 	}
 }
@@ -0,0 +1,38 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"context"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"knative.dev/pkg/configmap"
+)
+
+// StaticConfigStore makes a reconciler read Config instead of the
+// ConfigMaps.
+type StaticConfigStore struct {
+	Config *config.Config
+}
+
+// ToContext attaches Config to ctx.
+func (s StaticConfigStore) ToContext(ctx context.Context) context.Context {
+	return config.ToContext(ctx, s.Config)
+}
+
+// WatchConfigs does nothing, Config never changes.
+func (StaticConfigStore) WatchConfigs(configmap.Watcher) {}
@@ -39,4 +39,6 @@ type Images struct {
 	PRImage string
 	// ImageDigestExporterImage is the container image containing our image digest exporter binary.
 	ImageDigestExporterImage string
+	// WindowsShellImage is the Windows container image containing PowerShell.
+	WindowsShellImage string
 }
@@ -37,4 +37,10 @@ const (
 
 	// ConditionCheckKey is used as the label identifier for a ConditionCheck
 	ConditionCheckKey = "/conditionCheck"
+
+	// ClusterLabelKey, RunUIDLabelKey and RunAttemptLabelKey are used as the
+	// label identifiers for the identity of a run
+	ClusterLabelKey    = "/cluster"
+	RunUIDLabelKey     = "/runUID"
+	RunAttemptLabelKey = "/runAttempt"
 )
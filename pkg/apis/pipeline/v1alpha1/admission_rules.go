@@ -0,0 +1,227 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// The constraints below are checked by Validate, and expressed in CEL for
+// the AdmissionRules from the same definitions, so that both don't drift.
+
+// durationConstraint is a duration field of the spec of a run which must be
+// at least 0 if set.
+type durationConstraint struct {
+	// field is the JSON name of the field.
+	field string
+}
+
+var (
+	timeoutConstraint                  = durationConstraint{"timeout"}
+	expirationSecondsTTLConstraint     = durationConstraint{"expirationSecondsTTL"}
+	ttlSecondsAfterSucceededConstraint = durationConstraint{"ttlSecondsAfterSucceeded"}
+	ttlSecondsAfterFailedConstraint    = durationConstraint{"ttlSecondsAfterFailed"}
+	taskRunTTLConstraint               = durationConstraint{"taskRunTTL"}
+)
+
+// validate returns an error if d, the value of the field, is negative.
+func (c durationConstraint) validate(d *metav1.Duration) *apis.FieldError {
+	if d != nil && d.Duration < 0 {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be >= 0", d.Duration.String()), "spec."+c.field)
+	}
+	return nil
+}
+
+func (c durationConstraint) rule(name string, resources ...string) AdmissionRule {
+	return AdmissionRule{
+		Name:       name,
+		Resources:  resources,
+		Expression: fmt.Sprintf("!has(object.spec.%[1]s) || duration(object.spec.%[1]s) >= duration('0s')", c.field),
+		Message:    fmt.Sprintf("spec.%s must be >= 0", c.field),
+		Field:      "spec." + c.field,
+	}
+}
+
+// taskRunCleanupModes are the valid cleanup modes of the TaskRuns, besides
+// the empty one.
+var taskRunCleanupModes = []TaskRunCleanupMode{TaskRunCleanupModeDelete, TaskRunCleanupModeCompact}
+
+// validateCleanupMode returns an error if mode isn't empty or one of
+// taskRunCleanupModes.
+func validateCleanupMode(mode TaskRunCleanupMode) *apis.FieldError {
+	if mode == "" {
+		return nil
+	}
+	for _, m := range taskRunCleanupModes {
+		if mode == m {
+			return nil
+		}
+	}
+	return apis.ErrInvalidValue(string(mode), "spec.cleanupMode")
+}
+
+func cleanupModeRule(name string) AdmissionRule {
+	quoted := []string{"''"}
+	names := make([]string, len(taskRunCleanupModes))
+	for i, m := range taskRunCleanupModes {
+		quoted = append(quoted, celString(string(m)))
+		names[i] = string(m)
+	}
+	return AdmissionRule{
+		Name:       name,
+		Resources:  []string{"taskruns"},
+		Expression: fmt.Sprintf("!has(object.spec.cleanupMode) || object.spec.cleanupMode in [%s]", strings.Join(quoted, ", ")),
+		Message:    "spec.cleanupMode must be " + strings.Join(names, " or "),
+		Field:      "spec.cleanupMode",
+	}
+}
+
+// validateChecksum returns an error if checksum, the optional checksum of a
+// TaskRef, isn't matched by checksumRegexp.
+func validateChecksum(checksum string) *apis.FieldError {
+	if checksum != "" && !checksumRegexp.MatchString(checksum) {
+		return apis.ErrInvalidValue(checksum+" isn't a "+config.ChecksumPrefix+"<hex> checksum", "checksum")
+	}
+	return nil
+}
+
+// checksumCEL holds if the optional checksum of the TaskRef ref is matched
+// by checksumRegexp.
+func checksumCEL(ref string) string {
+	return fmt.Sprintf("!has(%[1]s.checksum) || %[1]s.checksum == '' || %[1]s.checksum.matches(%[2]s)", ref, celString(checksumRegexp.String()))
+}
+
+// dns1123LabelRegexp matches the DNS-1123 labels, up to
+// validation.DNS1123LabelMaxLength long, like validation.IsDNS1123Label.
+var dns1123LabelRegexp = regexp.MustCompile("^[a-z0-9]([-a-z0-9]*[a-z0-9])?$")
+
+// isDNS1123Label returns whether s is a DNS-1123 label.
+func isDNS1123Label(s string) bool {
+	return len(s) <= validation.DNS1123LabelMaxLength && dns1123LabelRegexp.MatchString(s)
+}
+
+// dns1123LabelCEL holds if s is a DNS-1123 label.
+func dns1123LabelCEL(s string) string {
+	return fmt.Sprintf("size(%[1]s) <= %[2]d && %[1]s.matches(%[3]s)", s, validation.DNS1123LabelMaxLength, celString(dns1123LabelRegexp.String()))
+}
+
+// celString returns s as a CEL string literal.
+func celString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// AdmissionRule is one of the structural validations of the webhook,
+// expressed in CEL for a ValidatingAdmissionPolicy to enforce it when the
+// webhook is unavailable.
+type AdmissionRule struct {
+	// Name identifies the rule.
+	Name string
+	// Resources are the resources the rule applies to, e.g. tasks.
+	Resources []string
+	// Expression is the CEL expression, over object, which holds for the
+	// valid resources.
+	Expression string
+	// Message is reported when Expression doesn't hold.
+	Message string
+	// Field is the field of the error Validate returns for the resources
+	// violating the rule.
+	Field string
+}
+
+// AdmissionRules are the structural validations of the webhook which can be
+// expressed in CEL. The ones depending on other resources, e.g. the Tasks of
+// a Pipeline, or on the config of the controller are left to the webhook.
+// The rules derived from a constraint share its definition with Validate,
+// the others are checked against it by the tests.
+var AdmissionRules = []AdmissionRule{{
+	Name:       "task-steps",
+	Resources:  []string{"tasks", "clustertasks"},
+	Expression: "has(object.spec.steps) && size(object.spec.steps) > 0",
+	Message:    "spec.steps must have at least one step",
+	Field:      "steps",
+}, {
+	Name:      "task-step-image",
+	Resources: []string{"tasks", "clustertasks"},
+	// The image of the steps referencing a StepAction is the one of the
+	// StepAction, the step template may set the image of the others.
+	Expression: "!has(object.spec.steps) || (has(object.spec.stepTemplate) && has(object.spec.stepTemplate.image) && object.spec.stepTemplate.image != '') || " +
+		"object.spec.steps.all(s, has(s.ref) || (has(s.image) && s.image != ''))",
+	Message: "the steps must have an image, or reference a StepAction",
+	Field:   "steps[0].Image",
+}, {
+	Name:       "task-step-name",
+	Resources:  []string{"tasks", "clustertasks"},
+	Expression: "!has(object.spec.steps) || object.spec.steps.all(s, !has(s.name) || s.name == '' || (" + dns1123LabelCEL("s.name") + "))",
+	Message:    "the names of the steps must be DNS-1123 labels",
+	Field:      "taskspec.steps.name",
+}, {
+	Name:       "task-step-name-unique",
+	Resources:  []string{"tasks", "clustertasks"},
+	Expression: "!has(object.spec.steps) || object.spec.steps.all(s, !has(s.name) || s.name == '' || object.spec.steps.exists_one(o, has(o.name) && o.name == s.name))",
+	Message:    "the names of the steps must be unique",
+	Field:      "steps[1].name",
+}, {
+	Name:      "taskrun-task",
+	Resources: []string{"taskruns"},
+	Expression: "has(object.spec.taskSpec) != " +
+		"(has(object.spec.taskRef) && has(object.spec.taskRef.name) && object.spec.taskRef.name != '')",
+	Message: "exactly one of spec.taskRef.name and spec.taskSpec must be set",
+	Field:   "spec.taskspec",
+}, {
+	Name:       "taskrun-task-checksum",
+	Resources:  []string{"taskruns"},
+	Expression: "!has(object.spec.taskRef) || (" + checksumCEL("object.spec.taskRef") + ")",
+	Message:    "spec.taskRef.checksum must be a " + config.ChecksumPrefix + "<hex> checksum",
+	Field:      "spec.taskref.checksum",
+},
+	timeoutConstraint.rule("taskrun-timeout", "taskruns"),
+	expirationSecondsTTLConstraint.rule("taskrun-expiration-ttl", "taskruns"),
+	ttlSecondsAfterSucceededConstraint.rule("taskrun-ttl-after-succeeded", "taskruns"),
+	ttlSecondsAfterFailedConstraint.rule("taskrun-ttl-after-failed", "taskruns"),
+	cleanupModeRule("taskrun-cleanup-mode"),
+	{
+		Name:      "pipelinerun-pipeline",
+		Resources: []string{"pipelineruns"},
+		Expression: "has(object.spec.pipelineSpec) != " +
+			"(has(object.spec.pipelineRef) && has(object.spec.pipelineRef.name) && object.spec.pipelineRef.name != '')",
+		Message: "exactly one of spec.pipelineRef.name and spec.pipelineSpec must be set",
+		Field:   "spec.pipelineSpec",
+	},
+	timeoutConstraint.rule("pipelinerun-timeout", "pipelineruns"),
+	expirationSecondsTTLConstraint.rule("pipelinerun-expiration-ttl", "pipelineruns"),
+	taskRunTTLConstraint.rule("pipelinerun-taskrun-ttl", "pipelineruns"),
+	{
+		Name:       "pipeline-task-name-unique",
+		Resources:  []string{"pipelines"},
+		Expression: "!has(object.spec.tasks) || object.spec.tasks.all(t, object.spec.tasks.exists_one(o, o.name == t.name))",
+		Message:    "the names of the tasks of spec.tasks must be unique",
+		Field:      "spec.tasks[1].name",
+	}, {
+		Name:       "pipeline-task-checksum",
+		Resources:  []string{"pipelines"},
+		Expression: "!has(object.spec.tasks) || object.spec.tasks.all(t, !has(t.taskRef) || (" + checksumCEL("t.taskRef") + "))",
+		Message:    "the checksums of the taskRefs of spec.tasks must be " + config.ChecksumPrefix + "<hex> checksums",
+		Field:      "spec.tasks[0].taskRef.checksum",
+	},
+}
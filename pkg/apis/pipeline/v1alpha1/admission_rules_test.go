@@ -0,0 +1,297 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// TestAdmissionRulesMatchValidate checks that the webhook rejects a resource
+// violating each of the AdmissionRules with an error on the field of the
+// rule, and accepts one on the edge of what the rule allows, so that the
+// rules don't drift from the validation of the webhook.
+func TestAdmissionRulesMatchValidate(t *testing.T) {
+	meta := metav1.ObjectMeta{Namespace: "foo", Name: "bar"}
+	negative := &metav1.Duration{Duration: -time.Hour}
+	taskRun := func(spec v1alpha1.TaskRunSpec) apis.Validatable {
+		spec.TaskRef = &v1alpha1.TaskRef{Name: "task"}
+		return &v1alpha1.TaskRun{ObjectMeta: meta, Spec: spec}
+	}
+	invalid := map[string]apis.Validatable{
+		"task-steps": &v1alpha1.Task{ObjectMeta: meta, Spec: v1alpha1.TaskSpec{
+			Inputs: &v1alpha1.Inputs{Params: []v1alpha1.ParamSpec{{Name: "param", Type: v1alpha1.ParamTypeString}}},
+		}},
+		"task-step-image": &v1alpha1.Task{ObjectMeta: meta, Spec: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{Name: "build"}}},
+		}},
+		"task-step-name": &v1alpha1.Task{ObjectMeta: meta, Spec: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{Name: "Build", Image: "image"}}},
+		}},
+		"task-step-name-unique": &v1alpha1.ClusterTask{ObjectMeta: metav1.ObjectMeta{Name: "bar"}, Spec: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{
+				{Container: corev1.Container{Name: "build", Image: "image"}},
+				{Container: corev1.Container{Name: "build", Image: "image"}},
+			},
+		}},
+		"taskrun-task": &v1alpha1.TaskRun{ObjectMeta: meta, Spec: v1alpha1.TaskRunSpec{
+			Timeout: &metav1.Duration{Duration: time.Hour},
+		}},
+		"taskrun-task-checksum": &v1alpha1.TaskRun{ObjectMeta: meta, Spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{Name: "task", Checksum: "sha256:E3B0C442"},
+		}},
+		"taskrun-timeout":             taskRun(v1alpha1.TaskRunSpec{Timeout: negative}),
+		"taskrun-expiration-ttl":      taskRun(v1alpha1.TaskRunSpec{ExpirationSecondsTTL: negative}),
+		"taskrun-ttl-after-succeeded": taskRun(v1alpha1.TaskRunSpec{TTLSecondsAfterSucceeded: negative}),
+		"taskrun-ttl-after-failed":    taskRun(v1alpha1.TaskRunSpec{TTLSecondsAfterFailed: negative}),
+		"taskrun-cleanup-mode":        taskRun(v1alpha1.TaskRunSpec{CleanupMode: "Archive"}),
+		"pipelinerun-pipeline": &v1alpha1.PipelineRun{ObjectMeta: meta, Spec: v1alpha1.PipelineRunSpec{
+			Timeout: &metav1.Duration{Duration: time.Hour},
+		}},
+		"pipelinerun-timeout": &v1alpha1.PipelineRun{ObjectMeta: meta, Spec: v1alpha1.PipelineRunSpec{
+			PipelineRef: v1alpha1.PipelineRef{Name: "pipeline"},
+			Timeout:     negative,
+		}},
+		"pipelinerun-expiration-ttl": &v1alpha1.PipelineRun{ObjectMeta: meta, Spec: v1alpha1.PipelineRunSpec{
+			PipelineRef:          v1alpha1.PipelineRef{Name: "pipeline"},
+			ExpirationSecondsTTL: negative,
+		}},
+		"pipelinerun-taskrun-ttl": &v1alpha1.PipelineRun{ObjectMeta: meta, Spec: v1alpha1.PipelineRunSpec{
+			PipelineRef: v1alpha1.PipelineRef{Name: "pipeline"},
+			TaskRunTTL:  negative,
+		}},
+		"pipeline-task-name-unique": &v1alpha1.Pipeline{ObjectMeta: meta, Spec: v1alpha1.PipelineSpec{
+			Tasks: []v1alpha1.PipelineTask{
+				{Name: "build", TaskRef: v1alpha1.TaskRef{Name: "task"}},
+				{Name: "build", TaskRef: v1alpha1.TaskRef{Name: "task"}},
+			},
+		}},
+		"pipeline-task-checksum": &v1alpha1.Pipeline{ObjectMeta: meta, Spec: v1alpha1.PipelineSpec{
+			Tasks: []v1alpha1.PipelineTask{
+				{Name: "build", TaskRef: v1alpha1.TaskRef{Name: "task", Checksum: "md5:d41d8cd98f00b204e9800998ecf8427e"}},
+			},
+		}},
+	}
+	zero := &metav1.Duration{}
+	checksum := "sha256:" + strings.Repeat("e3b0c442", 8)
+	step := v1alpha1.Step{Container: corev1.Container{Name: "build", Image: "image"}}
+	valid := map[string]apis.Validatable{
+		"task-steps": &v1alpha1.Task{ObjectMeta: meta, Spec: v1alpha1.TaskSpec{Steps: []v1alpha1.Step{step}}},
+		"task-step-image": &v1alpha1.Task{ObjectMeta: meta, Spec: v1alpha1.TaskSpec{
+			StepTemplate: &corev1.Container{Image: "image"},
+			Steps:        []v1alpha1.Step{{Container: corev1.Container{Name: "build"}}},
+		}},
+		"task-step-name": &v1alpha1.Task{ObjectMeta: meta, Spec: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{Image: "image"}}},
+		}},
+		"task-step-name-unique": &v1alpha1.ClusterTask{ObjectMeta: metav1.ObjectMeta{Name: "bar"}, Spec: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{Image: "image"}}, {Container: corev1.Container{Image: "image"}}},
+		}},
+		"taskrun-task": &v1alpha1.TaskRun{ObjectMeta: meta, Spec: v1alpha1.TaskRunSpec{
+			TaskSpec: &v1alpha1.TaskSpec{Steps: []v1alpha1.Step{step}},
+		}},
+		"taskrun-task-checksum": &v1alpha1.TaskRun{ObjectMeta: meta, Spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{Name: "task", Checksum: checksum},
+		}},
+		"taskrun-timeout":             taskRun(v1alpha1.TaskRunSpec{Timeout: zero}),
+		"taskrun-expiration-ttl":      taskRun(v1alpha1.TaskRunSpec{ExpirationSecondsTTL: zero}),
+		"taskrun-ttl-after-succeeded": taskRun(v1alpha1.TaskRunSpec{TTLSecondsAfterSucceeded: zero}),
+		"taskrun-ttl-after-failed":    taskRun(v1alpha1.TaskRunSpec{TTLSecondsAfterFailed: zero}),
+		"taskrun-cleanup-mode":        taskRun(v1alpha1.TaskRunSpec{CleanupMode: v1alpha1.TaskRunCleanupModeCompact}),
+		"pipelinerun-pipeline": &v1alpha1.PipelineRun{ObjectMeta: meta, Spec: v1alpha1.PipelineRunSpec{
+			PipelineSpec: &v1alpha1.PipelineSpec{Tasks: []v1alpha1.PipelineTask{{Name: "build", TaskRef: v1alpha1.TaskRef{Name: "task"}}}},
+		}},
+		"pipelinerun-timeout": &v1alpha1.PipelineRun{ObjectMeta: meta, Spec: v1alpha1.PipelineRunSpec{
+			PipelineRef: v1alpha1.PipelineRef{Name: "pipeline"},
+			Timeout:     zero,
+		}},
+		"pipelinerun-expiration-ttl": &v1alpha1.PipelineRun{ObjectMeta: meta, Spec: v1alpha1.PipelineRunSpec{
+			PipelineRef:          v1alpha1.PipelineRef{Name: "pipeline"},
+			ExpirationSecondsTTL: zero,
+		}},
+		"pipelinerun-taskrun-ttl": &v1alpha1.PipelineRun{ObjectMeta: meta, Spec: v1alpha1.PipelineRunSpec{
+			PipelineRef: v1alpha1.PipelineRef{Name: "pipeline"},
+			TaskRunTTL:  zero,
+		}},
+		"pipeline-task-name-unique": &v1alpha1.Pipeline{ObjectMeta: meta, Spec: v1alpha1.PipelineSpec{
+			Tasks: []v1alpha1.PipelineTask{
+				{Name: "build", TaskRef: v1alpha1.TaskRef{Name: "task"}},
+				{Name: "test", TaskRef: v1alpha1.TaskRef{Name: "task"}},
+			},
+		}},
+		"pipeline-task-checksum": &v1alpha1.Pipeline{ObjectMeta: meta, Spec: v1alpha1.PipelineSpec{
+			Tasks: []v1alpha1.PipelineTask{
+				{Name: "build", TaskRef: v1alpha1.TaskRef{Name: "task", Checksum: checksum}},
+			},
+		}},
+	}
+	names := map[string]struct{}{}
+	for _, rule := range v1alpha1.AdmissionRules {
+		t.Run(rule.Name, func(t *testing.T) {
+			if _, ok := names[rule.Name]; ok {
+				t.Fatalf("Duplicate admission rule %s", rule.Name)
+			}
+			names[rule.Name] = struct{}{}
+			obj, ok := invalid[rule.Name]
+			if !ok {
+				t.Fatalf("No resource violating admission rule %s to check it against Validate", rule.Name)
+			}
+			err := obj.Validate(context.Background())
+			if err == nil {
+				t.Fatalf("Expected Validate to reject the resource violating admission rule %s", rule.Name)
+			}
+			if !strings.Contains(err.Error(), rule.Field) {
+				t.Errorf("Expected Validate to reject the resource violating admission rule %s on %s, got %v", rule.Name, rule.Field, err)
+			}
+			obj, ok = valid[rule.Name]
+			if !ok {
+				t.Fatalf("No resource on the edge of admission rule %s to check it against Validate", rule.Name)
+			}
+			if err := obj.Validate(context.Background()); err != nil {
+				t.Errorf("Expected Validate to accept the resource admission rule %s allows, got %v", rule.Name, err)
+			}
+		})
+	}
+}
+
+// TestAdmissionRulesStepName checks that the DNS-1123 labels the
+// task-step-name rule and Validate share agree with
+// validation.IsDNS1123Label.
+func TestAdmissionRulesStepName(t *testing.T) {
+	for _, name := range []string{"a", "build-1", "1build", strings.Repeat("a", 63), strings.Repeat("a", 64), "Build", "-build", "build-", "build.1", "build_1"} {
+		task := &v1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "bar"}, Spec: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{Name: name, Image: "image"}}},
+		}}
+		err := task.Validate(context.Background())
+		if want := len(validation.IsDNS1123Label(name)) == 0; (err == nil) != want {
+			t.Errorf("Expected the step name %q to be valid: %t, got %v", name, want, err)
+		}
+	}
+}
+
+var (
+	// celString matches the string literals of the CEL expressions.
+	celString = regexp.MustCompile(`'[^']*'`)
+	// celMacro matches the macros of the CEL expressions binding a variable
+	// to the items of a list.
+	celMacro = regexp.MustCompile(`([a-z]\w*(?:\.\w+)+)\.(?:all|exists|exists_one)\((\w+),`)
+	// celSelect matches the field selections of the CEL expressions,
+	// followed by a parenthesis for the method calls.
+	celSelect = regexp.MustCompile(`\b([a-z]\w*)((?:\.\w+)+)(\()?`)
+)
+
+// TestAdmissionRulesFields checks that the fields the CEL expressions of the
+// AdmissionRules select are JSON fields of the resources they apply to, as
+// nothing evaluates the expressions in the tests.
+func TestAdmissionRulesFields(t *testing.T) {
+	resources := map[string]reflect.Type{
+		"tasks":        reflect.TypeOf(v1alpha1.Task{}),
+		"clustertasks": reflect.TypeOf(v1alpha1.ClusterTask{}),
+		"taskruns":     reflect.TypeOf(v1alpha1.TaskRun{}),
+		"pipelineruns": reflect.TypeOf(v1alpha1.PipelineRun{}),
+		"pipelines":    reflect.TypeOf(v1alpha1.Pipeline{}),
+	}
+	for _, rule := range v1alpha1.AdmissionRules {
+		t.Run(rule.Name, func(t *testing.T) {
+			expression := celString.ReplaceAllString(rule.Expression, "''")
+			for _, resource := range rule.Resources {
+				object, ok := resources[resource]
+				if !ok {
+					t.Fatalf("Unknown resource %s", resource)
+				}
+				vars := map[string]reflect.Type{"object": object}
+				for _, m := range celMacro.FindAllStringSubmatch(expression, -1) {
+					list, err := celFieldType(vars, m[1])
+					if err != "" {
+						t.Errorf("%s: %s", resource, err)
+						continue
+					}
+					if list.Kind() != reflect.Slice {
+						t.Errorf("%s: %s isn't a list", resource, m[1])
+						continue
+					}
+					vars[m[2]] = list.Elem()
+				}
+				for _, m := range celSelect.FindAllStringSubmatch(expression, -1) {
+					path := m[1] + m[2]
+					if m[3] != "" {
+						// Drop the method, e.g. matches.
+						path = path[:strings.LastIndex(path, ".")]
+					}
+					if _, err := celFieldType(vars, path); err != "" {
+						t.Errorf("%s: %s", resource, err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// celFieldType returns the type of the field path selects, from one of the
+// variables vars, or why it can't.
+func celFieldType(vars map[string]reflect.Type, path string) (reflect.Type, string) {
+	segments := strings.Split(path, ".")
+	typ, ok := vars[segments[0]]
+	if !ok {
+		return nil, "unknown variable " + segments[0] + " in " + path
+	}
+	for _, segment := range segments[1:] {
+		if typ = jsonField(typ, segment); typ == nil {
+			return nil, "no JSON field " + segment + " in " + path
+		}
+	}
+	return typ, ""
+}
+
+// jsonField returns the type of the JSON field name of typ, or nil if there
+// is none.
+func jsonField(typ reflect.Type, name string) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "" && f.Anonymous {
+			if inlined := jsonField(f.Type, name); inlined != nil {
+				return inlined
+			}
+			continue
+		}
+		if tag == name {
+			typ := f.Type
+			for typ.Kind() == reflect.Ptr {
+				typ = typ.Elem()
+			}
+			return typ
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"knative.dev/pkg/apis"
+)
+
+// validateCatalogChecksum rejects a definition whose spec drifts from the
+// checksum pinned by the catalog, if the catalog policy says so.
+func validateCatalogChecksum(ctx context.Context, kind, name string, spec interface{}) *apis.FieldError {
+	catalog := config.FromContextOrDefaults(ctx).Catalog
+	if catalog == nil || catalog.Policy != config.CatalogPolicyReject {
+		return nil
+	}
+	pinned, ok := catalog.Pinned(kind, name)
+	if !ok {
+		return nil
+	}
+	checksum, err := config.Checksum(spec)
+	if err != nil {
+		return &apis.FieldError{Message: err.Error(), Paths: []string{"spec"}}
+	}
+	if checksum != pinned {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("%s %s differs from catalog revision %q", kind, name, catalog.Revision),
+			Paths:   []string{"spec"},
+			Details: fmt.Sprintf("expected checksum %s, got %s", pinned, checksum),
+		}
+	}
+	return nil
+}
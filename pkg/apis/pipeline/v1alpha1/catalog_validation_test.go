@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+)
+
+func TestValidateCatalogChecksum(t *testing.T) {
+	task := tb.Task("golang-build", "foo", tb.TaskSpec(tb.Step("build", "golang:1.12")))
+	pipeline := tb.Pipeline("release", "foo", tb.PipelineSpec(tb.PipelineTask("build", "golang-build")))
+	taskChecksum, err := config.Checksum(&task.Spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drifted := tb.Task("golang-build", "foo", tb.TaskSpec(tb.Step("build", "golang:1.13")))
+	unpinned := tb.Task("unpinned", "foo", tb.TaskSpec(tb.Step("build", "golang:1.13")))
+
+	catalog := func(policy config.CatalogPolicy) context.Context {
+		return config.ToContext(context.Background(), &config.Config{
+			Catalog: &config.Catalog{
+				Revision: "v0.8.0",
+				Policy:   policy,
+				Checksums: map[string]string{
+					"task.golang-build": taskChecksum,
+					"pipeline.release":  "sha256:0123",
+				},
+			},
+		})
+	}
+
+	for _, tc := range []struct {
+		name    string
+		policy  config.CatalogPolicy
+		task    *v1alpha1.Task
+		wantErr bool
+	}{{
+		name:   "matching task",
+		policy: config.CatalogPolicyReject,
+		task:   task,
+	}, {
+		name:    "drifted task",
+		policy:  config.CatalogPolicyReject,
+		task:    drifted,
+		wantErr: true,
+	}, {
+		name:   "drifted task annotated only",
+		policy: config.CatalogPolicyAnnotate,
+		task:   drifted,
+	}, {
+		name:   "unpinned task",
+		policy: config.CatalogPolicyReject,
+		task:   unpinned,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.task.Validate(catalog(tc.policy))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+
+	if err := pipeline.Validate(catalog(config.CatalogPolicyReject)); err == nil {
+		t.Error("Expected a drifted pipeline to be rejected")
+	}
+}
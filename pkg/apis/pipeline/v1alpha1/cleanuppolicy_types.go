@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+// Check that CleanupPolicy may be validated and defaulted.
+var _ apis.Validatable = (*CleanupPolicy)(nil)
+var _ apis.Defaultable = (*CleanupPolicy)(nil)
+
+// TTLDefaultedAnnotationKey is the annotation set to "true" on the TaskRuns
+// whose TTL is the default one of the cluster, which the TTLs of a
+// CleanupPolicy override.
+const TTLDefaultedAnnotationKey = "pipeline.tekton.dev/ttl-defaulted"
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CleanupPolicy declares how long the finished TaskRuns and PipelineRuns of
+// its namespace it selects are kept, overriding the cluster defaults.
+// +k8s:openapi-gen=true
+type CleanupPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec holds the desired state of the CleanupPolicy from the client
+	// +optional
+	Spec CleanupPolicySpec `json:"spec"`
+}
+
+// CleanupPolicySpec defines the desired state of the CleanupPolicy
+type CleanupPolicySpec struct {
+	// Selector selects the runs of the namespace the policy applies to, all
+	// of them if empty.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// ExpirationSecondsTTL is the time the runs are kept for once finished,
+	// unless the TTL for their outcome is set.
+	// +optional
+	ExpirationSecondsTTL *metav1.Duration `json:"expirationSecondsTTL,omitempty"`
+	// TTLSecondsAfterSucceeded is the time the runs are kept for once
+	// succeeded.
+	// +optional
+	TTLSecondsAfterSucceeded *metav1.Duration `json:"ttlSecondsAfterSucceeded,omitempty"`
+	// TTLSecondsAfterFailed is the time the runs are kept for once failed.
+	// +optional
+	TTLSecondsAfterFailed *metav1.Duration `json:"ttlSecondsAfterFailed,omitempty"`
+
+	// SuccessfulHistoryLimit is the number of succeeded TaskRuns, and of
+	// succeeded PipelineRuns, kept. The older ones are deleted even if their
+	// TTL hasn't elapsed.
+	// +optional
+	SuccessfulHistoryLimit *int32 `json:"successfulHistoryLimit,omitempty"`
+	// FailedHistoryLimit is the number of failed TaskRuns, and of failed
+	// PipelineRuns, kept. The older ones are deleted even if their TTL
+	// hasn't elapsed.
+	// +optional
+	FailedHistoryLimit *int32 `json:"failedHistoryLimit,omitempty"`
+}
+
+// TTL returns the TTL of the runs finished with the Succeeded condition c:
+// TTLSecondsAfterSucceeded or TTLSecondsAfterFailed depending on it, falling
+// back to ExpirationSecondsTTL when the one for the outcome isn't set.
+func (cs *CleanupPolicySpec) TTL(c *apis.Condition) *metav1.Duration {
+	switch {
+	case c != nil && c.IsTrue() && cs.TTLSecondsAfterSucceeded != nil:
+		return cs.TTLSecondsAfterSucceeded
+	case c != nil && c.IsFalse() && cs.TTLSecondsAfterFailed != nil:
+		return cs.TTLSecondsAfterFailed
+	}
+	return cs.ExpirationSecondsTTL
+}
+
+// HistoryLimit returns the history limit of the runs finished with the
+// Succeeded condition c, or nil if there is none.
+func (cs *CleanupPolicySpec) HistoryLimit(c *apis.Condition) *int32 {
+	switch {
+	case c != nil && c.IsTrue():
+		return cs.SuccessfulHistoryLimit
+	case c != nil && c.IsFalse():
+		return cs.FailedHistoryLimit
+	}
+	return nil
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CleanupPolicyList contains a list of CleanupPolicies
+type CleanupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CleanupPolicy `json:"items"`
+}
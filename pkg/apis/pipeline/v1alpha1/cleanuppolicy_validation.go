@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func (cp *CleanupPolicy) Validate(ctx context.Context) *apis.FieldError {
+	return noteValidationErrors(ctx, cp.validate(ctx))
+}
+
+func (cp *CleanupPolicy) validate(ctx context.Context) *apis.FieldError {
+	if err := validateObjectMetadata(cp.GetObjectMeta()); err != nil {
+		return err.ViaField("metadata")
+	}
+	return cp.Spec.Validate(ctx)
+}
+
+func (cs *CleanupPolicySpec) Validate(ctx context.Context) *apis.FieldError {
+	if cs.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(cs.Selector); err != nil {
+			return apis.ErrInvalidValue(err.Error(), "spec.selector")
+		}
+	}
+	for _, ttl := range []struct {
+		field string
+		value *metav1.Duration
+	}{
+		{"spec.expirationSecondsTTL", cs.ExpirationSecondsTTL},
+		{"spec.ttlSecondsAfterSucceeded", cs.TTLSecondsAfterSucceeded},
+		{"spec.ttlSecondsAfterFailed", cs.TTLSecondsAfterFailed},
+	} {
+		if ttl.value != nil && ttl.value.Duration < 0 {
+			return apis.ErrInvalidValue(fmt.Sprintf("%s should be >= 0", ttl.value.Duration.String()), ttl.field)
+		}
+	}
+	for _, limit := range []struct {
+		field string
+		value *int32
+	}{
+		{"spec.successfulHistoryLimit", cs.SuccessfulHistoryLimit},
+		{"spec.failedHistoryLimit", cs.FailedHistoryLimit},
+	} {
+		if limit.value != nil && *limit.value < 0 {
+			return apis.ErrInvalidValue(fmt.Sprintf("%d should be >= 0", *limit.value), limit.field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+)
+
+func TestCleanupPolicy_Validate(t *testing.T) {
+	for _, cp := range []*v1alpha1.CleanupPolicy{
+		tb.CleanupPolicy("everything", "foo"),
+		tb.CleanupPolicy("nightly", "foo",
+			tb.CleanupPolicySelector(map[string]string{"trigger": "nightly"}),
+			tb.CleanupPolicyExpirationSecondsTTL(24*time.Hour),
+			tb.CleanupPolicyTTLSecondsAfterFailed(7*24*time.Hour),
+			tb.CleanupPolicyHistoryLimits(3, 0),
+		),
+	} {
+		t.Run(cp.Name, func(t *testing.T) {
+			if err := cp.Validate(context.Background()); err != nil {
+				t.Errorf("CleanupPolicy.Validate() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCleanupPolicy_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		cp            *v1alpha1.CleanupPolicy
+		expectedError apis.FieldError
+	}{{
+		name: "invalid name",
+		cp:   tb.CleanupPolicy("invalid.name", "foo"),
+		expectedError: apis.FieldError{
+			Message: "Invalid resource name: special character . must not be present",
+			Paths:   []string{"metadata.name"},
+		},
+	}, {
+		name: "invalid selector",
+		cp: tb.CleanupPolicy("nightly", "foo", func(cp *v1alpha1.CleanupPolicy) {
+			cp.Spec.Selector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "trigger",
+				Operator: "Within",
+			}}}
+		}),
+		expectedError: apis.FieldError{
+			Message: `invalid value: "Within" is not a valid pod selector operator`,
+			Paths:   []string{"spec.selector"},
+		},
+	}, {
+		name: "negative ttl",
+		cp:   tb.CleanupPolicy("nightly", "foo", tb.CleanupPolicyTTLSecondsAfterFailed(-time.Hour)),
+		expectedError: apis.FieldError{
+			Message: "invalid value: -1h0m0s should be >= 0",
+			Paths:   []string{"spec.ttlSecondsAfterFailed"},
+		},
+	}, {
+		name: "negative history limit",
+		cp:   tb.CleanupPolicy("nightly", "foo", tb.CleanupPolicyHistoryLimits(-1, 3)),
+		expectedError: apis.FieldError{
+			Message: "invalid value: -1 should be >= 0",
+			Paths:   []string{"spec.successfulHistoryLimit"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cp.Validate(context.Background())
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing for %v", tc.cp)
+			}
+			if d := cmp.Diff(tc.expectedError, *err, cmpopts.IgnoreUnexported(apis.FieldError{})); d != "" {
+				t.Errorf("CleanupPolicy.Validate() errors diff -want, +got: %v", d)
+			}
+		})
+	}
+}
+
+func TestCleanupPolicySpec_TTL(t *testing.T) {
+	spec := tb.CleanupPolicy("nightly", "foo",
+		tb.CleanupPolicyExpirationSecondsTTL(time.Hour),
+		tb.CleanupPolicyTTLSecondsAfterFailed(24*time.Hour),
+		tb.CleanupPolicyHistoryLimits(3, 10),
+	).Spec
+	succeeded := &apis.Condition{Type: apis.ConditionSucceeded, Status: "True"}
+	failed := &apis.Condition{Type: apis.ConditionSucceeded, Status: "False"}
+
+	if d := cmp.Diff(&metav1.Duration{Duration: time.Hour}, spec.TTL(succeeded)); d != "" {
+		t.Errorf("TTL of the succeeded runs diff -want, +got: %v", d)
+	}
+	if d := cmp.Diff(&metav1.Duration{Duration: 24 * time.Hour}, spec.TTL(failed)); d != "" {
+		t.Errorf("TTL of the failed runs diff -want, +got: %v", d)
+	}
+	if got := spec.HistoryLimit(succeeded); got == nil || *got != 3 {
+		t.Errorf("Expected a history limit of 3 succeeded runs, got %v", got)
+	}
+	if got := spec.HistoryLimit(failed); got == nil || *got != 10 {
+		t.Errorf("Expected a history limit of 10 failed runs, got %v", got)
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 )
 
+// SetDefaults sets the defaults of the spec of t, but not the resource
+// requirements of its steps, which depend on the namespaces of its TaskRuns.
 func (t *ClusterTask) SetDefaults(ctx context.Context) {
 	t.Spec.SetDefaults(ctx)
 }
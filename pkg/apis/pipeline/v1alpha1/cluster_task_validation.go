@@ -23,6 +23,10 @@ import (
 )
 
 func (t *ClusterTask) Validate(ctx context.Context) *apis.FieldError {
+	return noteValidationErrors(ctx, t.validate(ctx))
+}
+
+func (t *ClusterTask) validate(ctx context.Context) *apis.FieldError {
 	if err := validateObjectMetadata(t.GetObjectMeta()); err != nil {
 		return err.ViaField("metadata")
 	}
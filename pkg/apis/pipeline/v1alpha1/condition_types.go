@@ -110,3 +110,21 @@ func (cc *ConditionCheck) IsDone() bool {
 func (cc *ConditionCheck) IsSuccessful() bool {
 	return cc.Status.GetCondition(apis.ConditionSucceeded).IsTrue()
 }
+
+// HasCompleted returns whether the ConditionCheck finished, whatever its
+// outcome.
+func (cc *ConditionCheck) HasCompleted() bool {
+	return cc.IsDone()
+}
+
+// FinishTime returns the time at which the ConditionCheck finished, or nil
+// if it can't be told.
+func (cc *ConditionCheck) FinishTime() *metav1.Time {
+	return (*TaskRun)(cc).FinishTime()
+}
+
+// TTL returns the time the finished ConditionCheck is kept for, or nil if it
+// is never deleted. Being a TaskRun, it has the same TTLs as one.
+func (cc *ConditionCheck) TTL() *metav1.Duration {
+	return (*TaskRun)(cc).TTL()
+}
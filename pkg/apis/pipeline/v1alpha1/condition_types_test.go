@@ -18,8 +18,10 @@ package v1alpha1_test
 
 import (
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
@@ -53,3 +55,30 @@ func TestConditionCheck_IsSuccessful(t *testing.T) {
 		t.Fatal("Expected conditionCheck status to be done")
 	}
 }
+
+func TestConditionCheck_Expiration(t *testing.T) {
+	finishedAt := time.Date(2019, 8, 12, 18, 22, 57, 0, time.UTC)
+	tr := tb.TaskRun("", "",
+		tb.TaskRunSpec(
+			tb.TaskRunExpirationSecondsTTL(time.Hour),
+			tb.TaskRunTTLSecondsAfterFailed(time.Minute),
+		),
+		tb.TaskRunStatus(tb.StatusCondition(
+			apis.Condition{
+				Type:               apis.ConditionSucceeded,
+				Status:             corev1.ConditionFalse,
+				LastTransitionTime: apis.VolatileTime{Inner: metav1.NewTime(finishedAt)},
+			},
+		)))
+
+	cc := v1alpha1.ConditionCheck(*tr)
+	if !cc.HasCompleted() {
+		t.Error("Expected conditionCheck to have completed")
+	}
+	if got := cc.FinishTime(); got == nil || !got.Time.Equal(finishedAt) {
+		t.Errorf("Expected conditionCheck to have finished at %s, got %v", finishedAt, got)
+	}
+	if got := cc.TTL(); got == nil || got.Duration != time.Minute {
+		t.Errorf("Expected the TTL after failure of the conditionCheck, got %v", got)
+	}
+}
@@ -24,6 +24,10 @@ import (
 )
 
 func (c Condition) Validate(ctx context.Context) *apis.FieldError {
+	return noteValidationErrors(ctx, c.validate(ctx))
+}
+
+func (c Condition) validate(ctx context.Context) *apis.FieldError {
 	if err := validateObjectMetadata(c.GetObjectMeta()); err != nil {
 		return err.ViaField("metadata")
 	}
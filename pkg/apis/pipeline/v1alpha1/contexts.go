@@ -16,7 +16,12 @@ limitations under the License.
 
 package v1alpha1
 
-import "context"
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
 
 // hdcnKey is used as the key for associating information
 // with a context.Context.
@@ -52,3 +57,63 @@ func WithUpgradeViaDefaulting(ctx context.Context) context.Context {
 func IsUpgradeViaDefaulting(ctx context.Context) bool {
 	return ctx.Value(lemonadeKey{}) != nil
 }
+
+// podTemplateVolumesKey is used as the key for associating the volumes of the
+// pod template of a TaskRun with a context.Context.
+type podTemplateVolumesKey struct{}
+
+// WithPodTemplateVolumes notes on the context for the validation of the
+// taskSpec of a TaskRun the volumes its pod template adds, which the
+// containers of the taskSpec can mount.
+func WithPodTemplateVolumes(ctx context.Context, volumes []corev1.Volume) context.Context {
+	return context.WithValue(ctx, podTemplateVolumesKey{}, volumes)
+}
+
+// podTemplateVolumes returns the volumes of the pod template noted on the
+// context, and whether they were noted: only the taskSpecs of TaskRuns are
+// validated knowing the volumes of their pod template.
+func podTemplateVolumes(ctx context.Context) ([]corev1.Volume, bool) {
+	volumes, ok := ctx.Value(podTemplateVolumesKey{}).([]corev1.Volume)
+	return volumes, ok
+}
+
+// windowsKey is used as the key for associating with a context.Context that
+// the pods of a TaskRun run on Windows nodes.
+type windowsKey struct{}
+
+// WithWindows notes on the context for the validation of the taskSpec of a
+// TaskRun that its pod runs on Windows nodes, where the scripts without a
+// shebang are run with PowerShell.
+func WithWindows(ctx context.Context) context.Context {
+	return context.WithValue(ctx, windowsKey{}, struct{}{})
+}
+
+// isWindows checks whether the context was noted as validating the taskSpec
+// of a TaskRun running on Windows nodes.
+func isWindows(ctx context.Context) bool {
+	return ctx.Value(windowsKey{}) != nil
+}
+
+// validationErrorsKey is used as the key for associating where to note the
+// errors of the validation of a resource with a context.Context.
+type validationErrorsKey struct{}
+
+// WithValidationErrors notes on the context that the errors of the
+// validation of the resources validated with it are wanted, e.g. by the
+// webhook detailing its denials, and returns a function returning the ones
+// of the last resource validated.
+func WithValidationErrors(ctx context.Context) (context.Context, func() *apis.FieldError) {
+	var errs *apis.FieldError
+	return context.WithValue(ctx, validationErrorsKey{}, &errs), func() *apis.FieldError {
+		return errs
+	}
+}
+
+// noteValidationErrors notes on the context errs, the errors of the
+// validation of a resource, if they are wanted, and returns them.
+func noteValidationErrors(ctx context.Context, errs *apis.FieldError) *apis.FieldError {
+	if wanted, ok := ctx.Value(validationErrorsKey{}).(**apis.FieldError); ok {
+		*wanted = errs
+	}
+	return errs
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// CreatorAnnotationKey is the annotation the webhook sets to the user
+	// who created a TaskRun or a PipelineRun.
+	CreatorAnnotationKey = pipeline.GroupName + apis.CreatorAnnotationSuffix
+	// CreatorGroupsAnnotationKey is the annotation the webhook sets to the
+	// comma separated groups of the user who created a TaskRun or a
+	// PipelineRun, so that their access can be reviewed later on.
+	CreatorGroupsAnnotationKey = pipeline.GroupName + "/creatorGroups"
+)
+
+// setCreatorGroups records the groups of the user creating the run with
+// the given metadata, as seen by the webhook. The user itself is recorded by
+// the webhook, since runs have a spec.
+func setCreatorGroups(ctx context.Context, meta *metav1.ObjectMeta) {
+	ui := apis.GetUserInfo(ctx)
+	if ui == nil || apis.IsInUpdate(ctx) {
+		return
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[CreatorGroupsAnnotationKey] = strings.Join(ui.Groups, ",")
+}
+
+// validateCreator validates that an update of a run doesn't change who
+// created it.
+func validateCreator(oldMeta, newMeta metav1.ObjectMeta, oldSpec, newSpec interface{}) *apis.FieldError {
+	errs := apis.ValidateCreatorAndModifier(oldSpec, newSpec, oldMeta.Annotations, newMeta.Annotations, pipeline.GroupName)
+	if oldMeta.Annotations[CreatorGroupsAnnotationKey] != newMeta.Annotations[CreatorGroupsAnnotationKey] {
+		errs = errs.Also(&apis.FieldError{
+			Message: "annotation value is immutable",
+			Paths:   []string{CreatorGroupsAnnotationKey},
+		})
+	}
+	return errs.ViaField("metadata.annotations")
+}
+
+// Creator returns the user who created the run with the given metadata,
+// and their groups, as recorded by the webhook. The user is empty if it
+// wasn't recorded.
+func Creator(obj metav1.Object) (string, []string) {
+	var groups []string
+	if g := obj.GetAnnotations()[CreatorGroupsAnnotationKey]; g != "" {
+		groups = strings.Split(g, ",")
+	}
+	return obj.GetAnnotations()[CreatorAnnotationKey], groups
+}
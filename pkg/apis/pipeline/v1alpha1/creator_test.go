@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestSetCreatorGroups(t *testing.T) {
+	ctx := apis.WithUserInfo(context.Background(), &authenticationv1.UserInfo{
+		Username: "alice",
+		Groups:   []string{"dev", "system:authenticated"},
+	})
+	tr := tb.TaskRun("test-taskrun", "foo", tb.TaskRunSpec(tb.TaskRunTaskRef("task")))
+	tr.SetDefaults(ctx)
+	pr := tb.PipelineRun("test-pipelinerun", "foo", tb.PipelineRunSpec("pipeline"))
+	pr.SetDefaults(ctx)
+	for _, run := range []interface {
+		GetAnnotations() map[string]string
+	}{tr, pr} {
+		if got := run.GetAnnotations()[v1alpha1.CreatorGroupsAnnotationKey]; got != "dev,system:authenticated" {
+			t.Errorf("Expected the groups of the creator to be recorded, got %q", got)
+		}
+	}
+
+	// The groups of the creator aren't replaced by the ones of who updates
+	// the run.
+	updated := tb.TaskRun("test-taskrun", "foo", tb.TaskRunSpec(tb.TaskRunTaskRef("task")))
+	updated.SetDefaults(apis.WithinUpdate(ctx, tr))
+	if _, ok := updated.Annotations[v1alpha1.CreatorGroupsAnnotationKey]; ok {
+		t.Errorf("Expected the groups of the updater not to be recorded, got %v", updated.Annotations)
+	}
+}
+
+func TestCreator(t *testing.T) {
+	tr := tb.TaskRun("test-taskrun", "foo",
+		tb.TaskRunAnnotation(v1alpha1.CreatorAnnotationKey, "alice"),
+		tb.TaskRunAnnotation(v1alpha1.CreatorGroupsAnnotationKey, "dev,system:authenticated"),
+	)
+	user, groups := v1alpha1.Creator(tr)
+	if user != "alice" {
+		t.Errorf("Expected alice to be the creator, got %q", user)
+	}
+	if d := cmp.Diff([]string{"dev", "system:authenticated"}, groups); d != "" {
+		t.Errorf("Creator() groups diff -want, +got: %v", d)
+	}
+}
+
+func TestValidateCreatorImmutable(t *testing.T) {
+	old := tb.TaskRun("test-taskrun", "foo",
+		tb.TaskRunSpec(tb.TaskRunTaskRef("task")),
+		tb.TaskRunAnnotation(v1alpha1.CreatorAnnotationKey, "alice"),
+		tb.TaskRunAnnotation(v1alpha1.CreatorGroupsAnnotationKey, "dev"),
+	)
+	for _, tc := range []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{{
+		name: "unchanged",
+		key:  "other",
+	}, {
+		name:    "creator changed",
+		key:     v1alpha1.CreatorAnnotationKey,
+		value:   "bob",
+		wantErr: true,
+	}, {
+		name:    "groups changed",
+		key:     v1alpha1.CreatorGroupsAnnotationKey,
+		value:   "dev,admin",
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := old.DeepCopy()
+			tr.Annotations[tc.key] = tc.value
+			err := tr.Validate(apis.WithinUpdate(context.Background(), old))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected an error: %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	pr := tb.PipelineRun("test-pipelinerun", "foo",
+		tb.PipelineRunSpec("pipeline"),
+		tb.PipelineRunAnnotation(v1alpha1.CreatorAnnotationKey, "alice"),
+	)
+	updated := pr.DeepCopy()
+	updated.Annotations[v1alpha1.CreatorAnnotationKey] = "bob"
+	if err := updated.Validate(apis.WithinUpdate(context.Background(), pr)); err == nil {
+		t.Error("Expected an error changing the creator of a PipelineRun")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/cron"
+	"golang.org/x/xerrors"
+	"knative.dev/pkg/apis"
+)
+
+// ExecutionWindow restricts when a run may start. A run created outside of
+// its window waits for it to open, and isn't timed out meanwhile. Once
+// started, a run isn't stopped when its window closes.
+type ExecutionWindow struct {
+	// Schedules are cron expressions, e.g. "* 9-17 * * mon-fri", telling
+	// the minutes in which the run may start.
+	Schedules []string `json:"schedules"`
+	// TimeZone is the IANA name of the time zone of the schedules, e.g.
+	// "Europe/Paris". Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Validate checks that the window has valid schedules and time zone.
+func (w *ExecutionWindow) Validate(ctx context.Context) *apis.FieldError {
+	if len(w.Schedules) == 0 {
+		return apis.ErrMissingField("schedules")
+	}
+	for i, s := range w.Schedules {
+		if _, err := cron.Parse(s); err != nil {
+			return apis.ErrInvalidValue(err.Error(), fmt.Sprintf("schedules[%d]", i))
+		}
+	}
+	if _, err := time.LoadLocation(w.TimeZone); err != nil {
+		return apis.ErrInvalidValue(w.TimeZone, "timeZone")
+	}
+	return nil
+}
+
+// NextOpening returns now if the window is open at now, and otherwise the
+// next time it opens. It returns false if the window doesn't open in the
+// next five years.
+func (w *ExecutionWindow) NextOpening(now time.Time) (time.Time, bool, error) {
+	loc, err := time.LoadLocation(w.TimeZone)
+	if err != nil {
+		return time.Time{}, false, xerrors.Errorf("invalid time zone %q: %w", w.TimeZone, err)
+	}
+	now = now.In(loc)
+	var next time.Time
+	found := false
+	for _, expr := range w.Schedules {
+		s, err := cron.Parse(expr)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if s.Matches(now) {
+			return now, true, nil
+		}
+		if t, ok := s.Next(now); ok && (!found || t.Before(next)) {
+			next, found = t, true
+		}
+	}
+	return next, found, nil
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// FilesDir is the directory the inline files of a run are mounted at in
+// its steps.
+const FilesDir = "/tekton/files"
+
+// MaxFilesSize is the size, in bytes, the contents of the inline files of a
+// run, copied into a ConfigMap and into each TaskRun of a PipelineRun, are
+// limited to.
+const MaxFilesSize = 64 * 1024
+
+// InlineFile is a small file a run declares along with its spec, which the
+// TaskRun controller writes to a ConfigMap owned by the TaskRun, mounted at
+// FilesDir in its steps.
+type InlineFile struct {
+	// Name is the name of the file in FilesDir, a valid ConfigMap key.
+	Name string `json:"name"`
+	// Content is the content of the file.
+	Content string `json:"content"`
+}
+
+// FilePath returns the path the inline file name is mounted at.
+func FilePath(name string) string {
+	return FilesDir + "/" + name
+}
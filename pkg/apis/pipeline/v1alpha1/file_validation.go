@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// validateFiles checks that the inline files of a run have unique names,
+// which are valid ConfigMap keys, and fit in MaxFilesSize.
+func validateFiles(files []InlineFile) *apis.FieldError {
+	var errs *apis.FieldError
+	names := map[string]struct{}{}
+	size := 0
+	for i, f := range files {
+		if msgs := validation.IsConfigMapKey(f.Name); len(msgs) > 0 {
+			errs = errs.Also((&apis.FieldError{
+				Message: fmt.Sprintf("invalid value %q", f.Name),
+				Paths:   []string{"name"},
+				Details: strings.Join(msgs, ", "),
+			}).ViaIndex(i))
+		} else if _, ok := names[f.Name]; ok {
+			errs = errs.Also(apis.ErrMultipleOneOf("name").ViaIndex(i))
+		}
+		names[f.Name] = struct{}{}
+		size += len(f.Content)
+	}
+	if size > MaxFilesSize {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("invalid value: the files hold %d bytes, must be no more than %d", size, MaxFilesSize),
+			Paths:   []string{apis.CurrentField},
+		})
+	}
+	return errs
+}
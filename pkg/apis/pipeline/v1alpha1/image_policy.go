@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"knative.dev/pkg/apis"
+)
+
+// ValidateImagePolicy checks the images of the steps, merged with the step
+// template, init steps and sidecars of ts against the image policy of the
+// cluster. The webhook checks the Tasks and the StepActions, and the
+// reconciler checks the images again once the variables in them are replaced
+// and the StepActions resolved, before creating the pod.
+func (ts *TaskSpec) ValidateImagePolicy(ctx context.Context) *apis.FieldError {
+	mergedSteps, err := MergeStepsWithStepTemplate(ts.StepTemplate, ts.Steps)
+	if err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("error merging step template and steps: %s", err),
+			Paths:   []string{"stepTemplate"},
+		}
+	}
+	mergedInitSteps, err := MergeStepsWithStepTemplate(ts.StepTemplate, initStepsAsSteps(ts.InitSteps))
+	if err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("error merging step template and init steps: %s", err),
+			Paths:   []string{"stepTemplate"},
+		}
+	}
+	errs := validateImagePolicy(ctx, mergedSteps).ViaField("steps")
+	errs = errs.Also(validateImagePolicy(ctx, mergedInitSteps).ViaField("initSteps"))
+	return errs.Also(validateImagePolicy(ctx, initStepsAsSteps(ts.Sidecars)).ViaField("sidecars"))
+}
+
+func validateImagePolicy(ctx context.Context, steps []Step) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, s := range steps {
+		errs = errs.Also(validateImage(ctx, s.Image).ViaIndex(i))
+	}
+	return errs
+}
+
+// validateImage checks that image is pinned to a digest, and doesn't come
+// from a banned registry, if the config-defaults ConfigMap says so. Only the
+// registry of the images set through variables, e.g.
+// gcr.io/$(inputs.params.image), can be checked before the TaskRuns run.
+func validateImage(ctx context.Context, image string) *apis.FieldError {
+	cfg := config.FromContextOrDefaults(ctx).Defaults
+	if cfg == nil || !cfg.RequireImageDigests && len(cfg.BannedImageRegistries) == 0 || image == "" {
+		return nil
+	}
+	if i := strings.Index(image, "$("); i >= 0 {
+		repo, ok := knownRepository(image[:i])
+		if !ok {
+			return nil
+		}
+		if banned, ok := bannedRegistry(repo, cfg.BannedImageRegistries); ok {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("image %q comes from the banned registry %q", image, banned),
+				Paths:   []string{"image"},
+			}
+		}
+		return nil
+	}
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return apis.ErrInvalidValue(err.Error(), "image")
+	}
+	var errs *apis.FieldError
+	if banned, ok := bannedRegistry(ref.Context(), cfg.BannedImageRegistries); ok {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("image %q comes from the banned registry %q", image, banned),
+			Paths:   []string{"image"},
+		})
+	}
+	if _, ok := ref.(name.Digest); cfg.RequireImageDigests && !ok {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("image %q isn't pinned to a digest", image),
+			Paths:   []string{"image"},
+			Details: "The images must be referenced by digest, e.g. ubuntu@sha256:<digest>",
+		})
+	}
+	return errs
+}
+
+// knownRepository returns the repository made of the path segments of prefix,
+// the part of an image before its first variable, which the variable can't
+// change, e.g. gcr.io/build for gcr.io/build/$(inputs.params.tool). The
+// repository is followed by a placeholder segment, so that it only matches
+// the banned prefixes it's under.
+func knownRepository(prefix string) (name.Repository, bool) {
+	i := strings.LastIndex(prefix, "/")
+	if i < 0 {
+		return name.Repository{}, false
+	}
+	repo, err := name.NewRepository(prefix[:i]+"/variable", name.WeakValidation)
+	if err != nil {
+		return name.Repository{}, false
+	}
+	return repo, true
+}
+
+// bannedRegistry returns the first of the banned registries, or repository
+// prefixes, repo comes from.
+func bannedRegistry(repo name.Repository, banned []string) (string, bool) {
+	for _, b := range banned {
+		parts := strings.SplitN(b, "/", 2)
+		// docker.io is index.docker.io, as in the references.
+		registry, err := name.NewRegistry(parts[0], name.WeakValidation)
+		if err != nil {
+			continue
+		}
+		if len(parts) == 1 {
+			if repo.RegistryStr() == registry.RegistryStr() {
+				return b, true
+			}
+			continue
+		}
+		prefix := registry.RegistryStr() + "/" + parts[1]
+		if repo.Name() == prefix || strings.HasPrefix(repo.Name(), prefix+"/") {
+			return b, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (p *ImagePrefetch) SetDefaults(ctx context.Context) {
+	p.Spec.SetDefaults(ctx)
+}
+
+func (ps *ImagePrefetchSpec) SetDefaults(ctx context.Context) {
+	if ps.RefreshInterval == nil {
+		ps.RefreshInterval = &metav1.Duration{Duration: DefaultImagePrefetchRefreshInterval}
+	}
+}
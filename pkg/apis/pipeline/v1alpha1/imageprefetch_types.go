@@ -0,0 +1,149 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+// Check that ImagePrefetch may be validated and defaulted.
+var _ apis.Validatable = (*ImagePrefetch)(nil)
+var _ apis.Defaultable = (*ImagePrefetch)(nil)
+
+const (
+	// DefaultImagePrefetchRefreshInterval is how often the images of an
+	// ImagePrefetch are resolved again when it doesn't say.
+	DefaultImagePrefetchRefreshInterval = 30 * time.Minute
+	// MinImagePrefetchRefreshInterval is the shortest refresh interval of an
+	// ImagePrefetch, which keeps it from hammering the registries.
+	MinImagePrefetchRefreshInterval = time.Minute
+
+	// ImagePrefetchReasonResolved is the reason of the Ready condition of the
+	// ImagePrefetches whose images were all resolved.
+	ImagePrefetchReasonResolved = "Resolved"
+	// ImagePrefetchReasonResolutionFailed is the reason of the Ready condition
+	// of the ImagePrefetches some images of which couldn't be resolved.
+	ImagePrefetchReasonResolutionFailed = "ResolutionFailed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ImagePrefetch lists step images whose digest and entrypoint the controller
+// resolves ahead of time, and refreshes periodically, so that the TaskRuns
+// using them don't wait for their registry.
+// +k8s:openapi-gen=true
+type ImagePrefetch struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec holds the desired state of the ImagePrefetch from the client
+	// +optional
+	Spec ImagePrefetchSpec `json:"spec"`
+	// Status communicates the observed state of the ImagePrefetch from the
+	// controller
+	// +optional
+	Status ImagePrefetchStatus `json:"status"`
+}
+
+// ImagePrefetchSpec defines the desired state of the ImagePrefetch
+type ImagePrefetchSpec struct {
+	// Images are the references of the step images to resolve, as written in
+	// the steps using them.
+	Images []string `json:"images"`
+	// ServiceAccountName is the service account of the namespace whose pull
+	// secrets are used to resolve the images.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// RefreshInterval is how often the images are resolved again. A tag is
+	// resolved to the digest it was last resolved to for at most that long.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// GetRefreshInterval returns the refresh interval of the ImagePrefetch, or
+// the default one if it isn't set.
+func (ps *ImagePrefetchSpec) GetRefreshInterval() time.Duration {
+	if ps.RefreshInterval == nil {
+		return DefaultImagePrefetchRefreshInterval
+	}
+	return ps.RefreshInterval.Duration
+}
+
+var imagePrefetchCondSet = apis.NewLivingConditionSet()
+
+// ImagePrefetchStatus defines the observed state of the ImagePrefetch
+type ImagePrefetchStatus struct {
+	duckv1beta1.Status `json:",inline"`
+
+	// Images are the results of the last resolution of the images.
+	// +optional
+	Images []PrefetchedImage `json:"images,omitempty"`
+	// LastRefreshTime is when the images were last resolved.
+	// +optional
+	LastRefreshTime *metav1.Time `json:"lastRefreshTime,omitempty"`
+}
+
+// PrefetchedImage is the result of the resolution of an image of an
+// ImagePrefetch.
+type PrefetchedImage struct {
+	// Image is the reference of the image in the spec.
+	Image string `json:"image"`
+	// Digest is the digest the image was resolved to.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+	// Error tells why the image couldn't be resolved.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// GetCondition returns the Condition matching the given type.
+func (ps *ImagePrefetchStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return imagePrefetchCondSet.Manage(ps).GetCondition(t)
+}
+
+// MarkResolved sets the Ready condition to True.
+func (ps *ImagePrefetchStatus) MarkResolved(messageFormat string, messageA ...interface{}) {
+	imagePrefetchCondSet.Manage(ps).SetCondition(apis.Condition{
+		Type:    apis.ConditionReady,
+		Status:  corev1.ConditionTrue,
+		Reason:  ImagePrefetchReasonResolved,
+		Message: fmt.Sprintf(messageFormat, messageA...),
+	})
+}
+
+// MarkResolutionFailed sets the Ready condition to False.
+func (ps *ImagePrefetchStatus) MarkResolutionFailed(messageFormat string, messageA ...interface{}) {
+	imagePrefetchCondSet.Manage(ps).MarkFalse(apis.ConditionReady, ImagePrefetchReasonResolutionFailed, messageFormat, messageA...)
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ImagePrefetchList contains a list of ImagePrefetches
+type ImagePrefetchList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ImagePrefetch `json:"items"`
+}
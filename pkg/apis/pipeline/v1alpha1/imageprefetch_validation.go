@@ -0,0 +1,51 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"knative.dev/pkg/apis"
+)
+
+func (p *ImagePrefetch) Validate(ctx context.Context) *apis.FieldError {
+	return noteValidationErrors(ctx, p.validate(ctx))
+}
+
+func (p *ImagePrefetch) validate(ctx context.Context) *apis.FieldError {
+	if err := validateObjectMetadata(p.GetObjectMeta()); err != nil {
+		return err.ViaField("metadata")
+	}
+	return p.Spec.Validate(ctx)
+}
+
+func (ps *ImagePrefetchSpec) Validate(ctx context.Context) *apis.FieldError {
+	if len(ps.Images) == 0 {
+		return apis.ErrMissingField("spec.images")
+	}
+	for i, image := range ps.Images {
+		if _, err := name.ParseReference(image, name.WeakValidation); err != nil {
+			return apis.ErrInvalidValue(err.Error(), fmt.Sprintf("spec.images[%d]", i))
+		}
+	}
+	if ps.RefreshInterval != nil && ps.RefreshInterval.Duration < MinImagePrefetchRefreshInterval {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be >= %s", ps.RefreshInterval.Duration, MinImagePrefetchRefreshInterval), "spec.refreshInterval")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"knative.dev/pkg/apis"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+)
+
+func TestImagePrefetch_Validate(t *testing.T) {
+	for _, p := range []*v1alpha1.ImagePrefetch{
+		tb.ImagePrefetch("tag", "foo", []string{"ubuntu"}),
+		tb.ImagePrefetch("several", "foo", []string{
+			"gcr.io/foo/bar:v1",
+			"gcr.io/foo/baz@sha256:ba5ce7b9c4eb40e4b9c2c4e0f0d5e6b8d7c6a5f4e3d2c1b0a9f8e7d6c5b4a3f2",
+		},
+			tb.ImagePrefetchServiceAccountName("builder"),
+			tb.ImagePrefetchRefreshInterval(time.Hour),
+		),
+	} {
+		t.Run(p.Name, func(t *testing.T) {
+			if err := p.Validate(context.Background()); err != nil {
+				t.Errorf("ImagePrefetch.Validate() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestImagePrefetch_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		p             *v1alpha1.ImagePrefetch
+		expectedError apis.FieldError
+	}{{
+		name: "no images",
+		p:    tb.ImagePrefetch("none", "foo", nil),
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.images"},
+		},
+	}, {
+		name: "invalid image",
+		p:    tb.ImagePrefetch("invalid", "foo", []string{"ubuntu", "Ubuntu:"}),
+		expectedError: apis.FieldError{
+			Paths: []string{"spec.images[1]"},
+		},
+	}, {
+		name: "refresh interval too short",
+		p:    tb.ImagePrefetch("eager", "foo", []string{"ubuntu"}, tb.ImagePrefetchRefreshInterval(time.Second)),
+		expectedError: apis.FieldError{
+			Message: "invalid value: 1s should be >= 1m0s",
+			Paths:   []string{"spec.refreshInterval"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.p.Validate(context.Background())
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing for %v", tc.p)
+			}
+			opts := []cmp.Option{cmpopts.IgnoreUnexported(apis.FieldError{})}
+			if tc.expectedError.Message == "" {
+				opts = append(opts, cmpopts.IgnoreFields(apis.FieldError{}, "Message"))
+			}
+			if d := cmp.Diff(tc.expectedError, *err, opts...); d != "" {
+				t.Errorf("ImagePrefetch.Validate() errors diff -want, +got: %v", d)
+			}
+		})
+	}
+}
+
+func TestImagePrefetch_SetDefaults(t *testing.T) {
+	p := tb.ImagePrefetch("tag", "foo", []string{"ubuntu"})
+	p.SetDefaults(context.Background())
+	if got := p.Spec.GetRefreshInterval(); got != v1alpha1.DefaultImagePrefetchRefreshInterval {
+		t.Errorf("Expected the default refresh interval %s, got %s", v1alpha1.DefaultImagePrefetchRefreshInterval, got)
+	}
+}
@@ -18,14 +18,18 @@ package v1alpha1
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 )
 
 // MergeStepsWithStepTemplate takes a possibly nil container template and a
 // list of steps, merging each of the steps with the container template, if
-// it's not nil, and returning the resulting list.
+// it's not nil, and returning the resulting list. The steps are left as is.
 func MergeStepsWithStepTemplate(template *v1.Container, steps []Step) ([]Step, error) {
 	if template == nil {
 		return steps, nil
@@ -44,6 +48,7 @@ func MergeStepsWithStepTemplate(template *v1.Container, steps []Step) ([]Step, e
 		return nil, err
 	}
 
+	mergedSteps := make([]Step, len(steps))
 	for i, s := range steps {
 		// Marshal the step's to JSON
 		stepAsJSON, err := json.Marshal(s.Container)
@@ -83,8 +88,140 @@ func MergeStepsWithStepTemplate(template *v1.Container, steps []Step) ([]Step, e
 		if merged.Args == nil && s.Args != nil {
 			merged.Args = []string{}
 		}
+		// The envFrom sources of the step are added to the template's,
+		// which the strategic merge would replace.
+		merged.EnvFrom = mergeEnvFrom(template.EnvFrom, s.EnvFrom)
+
+		// The fields of the step which aren't the ones of its container,
+		// e.g. its script or StepAction, are kept as they are.
+		step := s
+		step.Container = *merged
+		mergedSteps[i] = step
+	}
+	return mergedSteps, nil
+}
+
+// mergeEnvFrom returns the envFrom sources of the template followed by the
+// ones of the step which the template doesn't have, so that the variables
+// of the step's sources take precedence over the template's ones.
+func mergeEnvFrom(template, step []v1.EnvFromSource) []v1.EnvFromSource {
+	if len(template) == 0 {
+		return step
+	}
+	merged := append([]v1.EnvFromSource{}, template...)
+	for _, s := range step {
+		if !containsEnvFrom(template, s) {
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
+
+func containsEnvFrom(sources []v1.EnvFromSource, source v1.EnvFromSource) bool {
+	for _, s := range sources {
+		if equality.Semantic.DeepEqual(s, source) {
+			return true
+		}
+	}
+	return false
+}
+
+// stepTemplateMergeKeys are the keys the strategic merge matches the items
+// of the lists of containers by, which are merged item by item.
+var stepTemplateMergeKeys = map[string]string{
+	"env":           "name",
+	"volumeMounts":  "mountPath",
+	"volumeDevices": "devicePath",
+	"ports":         "containerPort",
+}
+
+// stepTemplateConflict is a value of a step which overrides a different
+// value of the step template.
+type stepTemplateConflict struct {
+	// field is the path of the value in the container, e.g. command or
+	// env[FOO].value.
+	field    string
+	template interface{}
+	step     interface{}
+}
+
+// stepTemplateConflicts returns the values of step which override a
+// different value of template when they're merged, sorted by field. The
+// envFrom sources are added to the template's rather than overriding them.
+func stepTemplateConflicts(template, step v1.Container) ([]stepTemplateConflict, error) {
+	t, err := toJSONMap(template)
+	if err != nil {
+		return nil, err
+	}
+	s, err := toJSONMap(step)
+	if err != nil {
+		return nil, err
+	}
+	delete(t, "envFrom")
+	var conflicts []stepTemplateConflict
+	collectStepTemplateConflicts("", t, s, true, &conflicts)
+	return conflicts, nil
+}
+
+func collectStepTemplateConflicts(prefix string, template, step map[string]interface{}, top bool, conflicts *[]stepTemplateConflict) {
+	keys := make([]string, 0, len(template))
+	for k := range template {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		tv, sv := template[k], step[k]
+		if isZeroJSON(tv) || isZeroJSON(sv) {
+			continue
+		}
+		field := prefix + k
+		if mergeKey, ok := stepTemplateMergeKeys[k]; ok && top {
+			tItems, _ := tv.([]interface{})
+			sItems, _ := sv.([]interface{})
+			for _, ti := range tItems {
+				tm, _ := ti.(map[string]interface{})
+				for _, si := range sItems {
+					if sm, _ := si.(map[string]interface{}); sm != nil && reflect.DeepEqual(tm[mergeKey], sm[mergeKey]) {
+						collectStepTemplateConflicts(fmt.Sprintf("%s[%v].", field, tm[mergeKey]), tm, sm, false, conflicts)
+					}
+				}
+			}
+			continue
+		}
+		tm, tIsMap := tv.(map[string]interface{})
+		sm, sIsMap := sv.(map[string]interface{})
+		if tIsMap && sIsMap {
+			collectStepTemplateConflicts(field+".", tm, sm, false, conflicts)
+			continue
+		}
+		if !reflect.DeepEqual(tv, sv) {
+			*conflicts = append(*conflicts, stepTemplateConflict{field: field, template: tv, step: sv})
+		}
+	}
+}
+
+// toJSONMap returns the JSON representation of c as a map.
+func toJSONMap(c v1.Container) (map[string]interface{}, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	return m, json.Unmarshal(b, &m)
+}
 
-		steps[i] = Step{Container: *merged}
+// isZeroJSON returns whether the JSON value v is unset. The false booleans
+// and zero numbers of containers are only marshalled when set explicitly.
+func isZeroJSON(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
 	}
-	return steps, nil
+	return false
 }
@@ -100,6 +100,61 @@ func TestMergeStepsWithStepTemplate(t *testing.T) {
 				Value: "NEW_VALUE",
 			}},
 		}}},
+	}, {
+		name: "merge-envFrom",
+		template: &corev1.Container{
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "common"}},
+			}, {
+				Prefix:    "DB_",
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}},
+			}},
+		},
+		steps: []Step{{Container: corev1.Container{
+			EnvFrom: []corev1.EnvFromSource{{
+				Prefix:    "DB_",
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}},
+			}, {
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "step"}},
+			}},
+		}}, {Container: corev1.Container{
+			Image: "some-image",
+		}}},
+		expected: []Step{{Container: corev1.Container{
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "common"}},
+			}, {
+				Prefix:    "DB_",
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}},
+			}, {
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "step"}},
+			}},
+		}}, {Container: corev1.Container{
+			Image: "some-image",
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "common"}},
+			}, {
+				Prefix:    "DB_",
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}},
+			}},
+		}}},
+	}, {
+		name: "keep-step-fields",
+		template: &corev1.Container{
+			WorkingDir: "/workspace/src",
+		},
+		steps: []Step{{
+			Container: corev1.Container{Name: "build"},
+			Ref:       &StepActionRef{Name: "git-clone"},
+			Params:    []Param{{Name: "url", Value: ArrayOrString{Type: ParamTypeString, StringVal: "https://github.com/tektoncd/pipeline"}}},
+			OnError:   Continue,
+		}},
+		expected: []Step{{
+			Container: corev1.Container{Name: "build", WorkingDir: "/workspace/src"},
+			Ref:       &StepActionRef{Name: "git-clone"},
+			Params:    []Param{{Name: "url", Value: ArrayOrString{Type: ParamTypeString, StringVal: "https://github.com/tektoncd/pipeline"}}},
+			OnError:   Continue,
+		}},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			result, err := MergeStepsWithStepTemplate(tc.template, tc.steps)
@@ -113,3 +168,54 @@ func TestMergeStepsWithStepTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestStepTemplateConflicts(t *testing.T) {
+	privileged, unprivileged := true, false
+	template := corev1.Container{
+		Image:      "template-image",
+		Command:    []string{"/bin/sh"},
+		WorkingDir: "/workspace",
+		Env: []corev1.EnvVar{
+			{Name: "FOO", Value: "bar"},
+			{Name: "KEEP", Value: "this"},
+		},
+		EnvFrom: []corev1.EnvFromSource{{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "common"}},
+		}},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		},
+		SecurityContext: &corev1.SecurityContext{Privileged: &unprivileged},
+	}
+	step := corev1.Container{
+		Name:       "build",
+		Image:      "step-image",
+		Command:    []string{"/bin/sh"},
+		WorkingDir: "/workspace",
+		Env: []corev1.EnvVar{
+			{Name: "FOO", Value: "baz"},
+			{Name: "NEW", Value: "value"},
+		},
+		EnvFrom: []corev1.EnvFromSource{{
+			ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "step"}},
+		}},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi"), corev1.ResourceCPU: resource.MustParse("1")},
+		},
+		SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+	}
+
+	got, err := stepTemplateConflicts(template, step)
+	if err != nil {
+		t.Fatalf("stepTemplateConflicts: %v", err)
+	}
+	want := []stepTemplateConflict{
+		{field: "env[FOO].value", template: "bar", step: "baz"},
+		{field: "image", template: "template-image", step: "step-image"},
+		{field: "resources.requests.memory", template: "1Gi", step: "2Gi"},
+		{field: "securityContext.privileged", template: false, step: true},
+	}
+	if d := cmp.Diff(want, got, cmp.AllowUnexported(stepTemplateConflict{})); d != "" {
+		t.Errorf("Diff conflicts -want, +got: %s", d)
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 // ParamSpec defines arbitrary parameters needed beyond typed inputs (such as
@@ -29,9 +31,14 @@ type ParamSpec struct {
 	// Name declares the name by which a parameter is referenced.
 	Name string `json:"name"`
 	// Type is the user-specified type of the parameter. The possible types
-	// are currently "string" and "array", and "string" is the default.
+	// are currently "string", "array" and "object", and "string" is the
+	// default, unless Properties are declared.
 	// +optional
 	Type ParamType `json:"type,omitempty"`
+	// Properties are the keys of an object parameter, which its values
+	// must all provide.
+	// +optional
+	Properties map[string]PropertySpec `json:"properties,omitempty"`
 	// Description is a user-facing description of the parameter that may be
 	// used to populate a UI.
 	// +optional
@@ -41,6 +48,23 @@ type ParamSpec struct {
 	// parameter.
 	// +optional
 	Default *ArrayOrString `json:"default,omitempty"`
+	// Enum restricts the value of the parameter, or its elements or values
+	// for arrays and objects, to these.
+	// +optional
+	Enum []string `json:"enum,omitempty"`
+	// Pattern is a regular expression the value of the parameter, or its
+	// elements or values for arrays and objects, must match. Like in JSON
+	// schemas, it isn't anchored.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// PropertySpec declares a key of an object parameter.
+type PropertySpec struct {
+	// Type is the type of the values of the key. Only "string" is
+	// supported, and is the default.
+	// +optional
+	Type ParamType `json:"type,omitempty"`
 }
 
 func (pp *ParamSpec) SetDefaults(ctx context.Context) {
@@ -48,11 +72,20 @@ func (pp *ParamSpec) SetDefaults(ctx context.Context) {
 		if pp.Default != nil {
 			// propagate the parsed ArrayOrString's type to the parent ParamSpec's type
 			pp.Type = pp.Default.Type
+		} else if pp.Properties != nil {
+			pp.Type = ParamTypeObject
 		} else {
 			// ParamTypeString is the default value (when no type can be inferred from the default value)
 			pp.Type = ParamTypeString
 		}
 	}
+	if pp != nil && pp.Type == ParamTypeObject {
+		for key, property := range pp.Properties {
+			if property.Type == "" {
+				pp.Properties[key] = PropertySpec{Type: ParamTypeString}
+			}
+		}
+	}
 }
 
 // ResourceParam declares a string value to use for the parameter called Name, and is used in
@@ -66,37 +99,58 @@ type ResourceParam struct {
 type Param struct {
 	Name  string        `json:"name"`
 	Value ArrayOrString `json:"value"`
+	// ValueFrom is where the string value of the parameter is read from
+	// when the steps run, instead of Value, so that the value is never
+	// written to the run.
+	// +optional
+	ValueFrom *ParamValueSource `json:"valueFrom,omitempty"`
+}
+
+// ParamValueSource is where the value of a parameter is read from.
+type ParamValueSource struct {
+	// SecretKeyRef is a key of a Secret in the namespace of the run, which
+	// the steps read through an environment variable.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
 }
 
 // ParamType indicates the type of an input parameter;
-// Used to distinguish between a single string and an array of strings.
+// Used to distinguish between a single string, an array of strings and an
+// object of strings.
 type ParamType string
 
 // Valid ParamTypes:
 const (
 	ParamTypeString ParamType = "string"
 	ParamTypeArray  ParamType = "array"
+	ParamTypeObject ParamType = "object"
 )
 
 // AllParamTypes can be used for ParamType validation.
-var AllParamTypes = []ParamType{ParamTypeString, ParamTypeArray}
+var AllParamTypes = []ParamType{ParamTypeString, ParamTypeArray, ParamTypeObject}
 
 // ArrayOrString is modeled after IntOrString in kubernetes/apimachinery:
 
-// ArrayOrString is a type that can hold a single string or string array.
+// ArrayOrString is a type that can hold a single string, a string array or
+// an object of strings.
 // Used in JSON unmarshalling so that a single JSON field can accept
-// either an individual string or an array of strings.
+// either an individual string, an array of strings or an object of strings.
 type ArrayOrString struct {
 	Type      ParamType // Represents the stored type of ArrayOrString.
 	StringVal string
 	ArrayVal  []string
+	ObjectVal map[string]string
 }
 
 // UnmarshalJSON implements the json.Unmarshaller interface.
 func (arrayOrString *ArrayOrString) UnmarshalJSON(value []byte) error {
-	if value[0] == '"' {
+	switch value[0] {
+	case '"':
 		arrayOrString.Type = ParamTypeString
 		return json.Unmarshal(value, &arrayOrString.StringVal)
+	case '{':
+		arrayOrString.Type = ParamTypeObject
+		return json.Unmarshal(value, &arrayOrString.ObjectVal)
 	}
 	arrayOrString.Type = ParamTypeArray
 	return json.Unmarshal(value, &arrayOrString.ArrayVal)
@@ -109,15 +163,22 @@ func (arrayOrString ArrayOrString) MarshalJSON() ([]byte, error) {
 		return json.Marshal(arrayOrString.StringVal)
 	case ParamTypeArray:
 		return json.Marshal(arrayOrString.ArrayVal)
+	case ParamTypeObject:
+		return json.Marshal(arrayOrString.ObjectVal)
 	default:
 		return []byte{}, fmt.Errorf("impossible ArrayOrString.Type: %q", arrayOrString.Type)
 	}
 }
 
 func (arrayOrString *ArrayOrString) ApplyReplacements(stringReplacements map[string]string, arrayReplacements map[string][]string) {
-	if arrayOrString.Type == ParamTypeString {
+	switch arrayOrString.Type {
+	case ParamTypeString:
 		arrayOrString.StringVal = ApplyReplacements(arrayOrString.StringVal, stringReplacements)
-	} else {
+	case ParamTypeObject:
+		for k, v := range arrayOrString.ObjectVal {
+			arrayOrString.ObjectVal[k] = ApplyReplacements(v, stringReplacements)
+		}
+	default:
 		var newArrayVal []string
 		for _, v := range arrayOrString.ArrayVal {
 			newArrayVal = append(newArrayVal, ApplyArrayReplacements(v, stringReplacements, arrayReplacements)...)
@@ -52,6 +52,20 @@ func TestParamSpec_SetDefaults(t *testing.T) {
 			Type:    v1alpha1.ParamTypeArray,
 			Default: builder.ArrayOrString("an", "array"),
 		},
+	}, {
+		name: "inferred object type from properties",
+		before: &v1alpha1.ParamSpec{
+			Name:       "parametername",
+			Properties: map[string]v1alpha1.PropertySpec{"url": {}, "revision": {Type: v1alpha1.ParamTypeString}},
+		},
+		defaultsApplied: &v1alpha1.ParamSpec{
+			Name: "parametername",
+			Type: v1alpha1.ParamTypeObject,
+			Properties: map[string]v1alpha1.PropertySpec{
+				"url":      {Type: v1alpha1.ParamTypeString},
+				"revision": {Type: v1alpha1.ParamTypeString},
+			},
+		},
 	}, {
 		name: "fully defined ParamSpec",
 		before: &v1alpha1.ParamSpec{
@@ -128,6 +142,14 @@ func TestArrayOrString_ApplyReplacements(t *testing.T) {
 			arrayReplacements:  map[string][]string{"arraykey": {}},
 		},
 		expectedOutput: builder.ArrayOrString("firstvalue", "lastvalue"),
+	}, {
+		name: "string replacements on object",
+		args: args{
+			input:              builder.ObjectValue(map[string]string{"url": "$(some)", "revision": "v$(anotherkey)"}),
+			stringReplacements: map[string]string{"some": "value", "anotherkey": "1"},
+			arrayReplacements:  map[string][]string{"arraykey": {"array", "value"}},
+		},
+		expectedOutput: builder.ObjectValue(map[string]string{"url": "value", "revision": "v1"}),
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -153,6 +175,7 @@ func TestArrayOrString_UnmarshalJSON(t *testing.T) {
 		{"{\"val\":[]}", v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeArray, ArrayVal: []string{}}},
 		{"{\"val\":[\"oneelement\"]}", v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeArray, ArrayVal: []string{"oneelement"}}},
 		{"{\"val\":[\"multiple\", \"elements\"]}", v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeArray, ArrayVal: []string{"multiple", "elements"}}},
+		{"{\"val\":{\"url\": \"a\", \"revision\": \"b\"}}", *builder.ObjectValue(map[string]string{"url": "a", "revision": "b"})},
 	}
 
 	for _, c := range cases {
@@ -174,6 +197,7 @@ func TestArrayOrString_MarshalJSON(t *testing.T) {
 		{*builder.ArrayOrString("123"), "{\"val\":\"123\"}"},
 		{*builder.ArrayOrString("123", "1234"), "{\"val\":[\"123\",\"1234\"]}"},
 		{*builder.ArrayOrString("a", "a", "a"), "{\"val\":[\"a\",\"a\",\"a\"]}"},
+		{*builder.ObjectValue(map[string]string{"url": "a", "revision": "b"}), "{\"val\":{\"revision\":\"b\",\"url\":\"a\"}}"},
 	}
 
 	for _, c := range cases {
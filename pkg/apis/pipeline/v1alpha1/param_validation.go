@@ -0,0 +1,190 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+// validateObjectParamSpec checks that object params declare their keys,
+// which are strings, and that their default provides all of them. Only
+// object params may declare keys. path is the path of the param.
+func validateObjectParamSpec(p ParamSpec, path string) *apis.FieldError {
+	if p.Type != ParamTypeObject {
+		if p.Properties != nil {
+			return apis.ErrDisallowedFields(path + ".properties")
+		}
+		return nil
+	}
+	if len(p.Properties) == 0 {
+		return apis.ErrMissingField(path + ".properties")
+	}
+	var errs *apis.FieldError
+	for key, property := range p.Properties {
+		if property.Type != ParamTypeString {
+			errs = errs.Also(apis.ErrInvalidValue(string(property.Type), fmt.Sprintf("%s.properties.%s.type", path, key)))
+		}
+	}
+	if p.Default != nil && p.Default.Type == ParamTypeObject {
+		if missing := MissingObjectKeys(p, p.Default.ObjectVal); len(missing) > 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("default of object param %q is missing keys %s", p.Name, strings.Join(missing, ", ")),
+				Paths:   []string{path + ".default"},
+			})
+		}
+	}
+	return errs
+}
+
+// MissingObjectKeys returns the sorted keys declared by the object param p
+// which value doesn't provide.
+func MissingObjectKeys(p ParamSpec, value map[string]string) []string {
+	var missing []string
+	for key := range p.Properties {
+		if _, ok := value[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// objectParamKeys returns the declared keys of the object params among
+// params, by name.
+func objectParamKeys(params []ParamSpec) map[string]map[string]struct{} {
+	objects := map[string]map[string]struct{}{}
+	for _, p := range params {
+		if p.Type != ParamTypeObject {
+			continue
+		}
+		keys := map[string]struct{}{}
+		for key := range p.Properties {
+			keys[key] = struct{}{}
+		}
+		objects[p.Name] = keys
+	}
+	return objects
+}
+
+// validateParamConstraints checks that the pattern of p is a regular
+// expression, that its enum has no duplicates, and that its default satisfies
+// them. path is the path of the param.
+func validateParamConstraints(p ParamSpec, path string) *apis.FieldError {
+	var errs *apis.FieldError
+	if _, err := regexp.Compile(p.Pattern); err != nil {
+		errs = errs.Also(apis.ErrInvalidValue(p.Pattern, path+".pattern"))
+	}
+	seen := map[string]struct{}{}
+	for i, v := range p.Enum {
+		if _, ok := seen[v]; ok {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("duplicate enum value %q", v),
+				Paths:   []string{fmt.Sprintf("%s.enum[%d]", path, i)},
+			})
+		}
+		seen[v] = struct{}{}
+	}
+	if errs == nil && p.Default != nil {
+		errs = ValidateParamValue(p, *p.Default, path+".default")
+	}
+	return errs
+}
+
+// ValidateParamValue checks that value, or each of its elements or values
+// for arrays and objects, is one of the enum of p and matches its pattern,
+// if p has them. The errors point at path, followed by the index of the
+// element or the key of the value, and don't quote the value, which may be
+// secret. The pattern of p must be valid.
+func ValidateParamValue(p ParamSpec, value ArrayOrString, path string) *apis.FieldError {
+	if len(p.Enum) == 0 && p.Pattern == "" {
+		return nil
+	}
+	var pattern *regexp.Regexp
+	if p.Pattern != "" {
+		pattern = regexp.MustCompile(p.Pattern)
+	}
+	var errs *apis.FieldError
+	switch value.Type {
+	case ParamTypeString:
+		errs = errs.Also(validateParamConstrainedValue(p, pattern, value.StringVal, path))
+	case ParamTypeArray:
+		for i, v := range value.ArrayVal {
+			errs = errs.Also(validateParamConstrainedValue(p, pattern, v, fmt.Sprintf("%s[%d]", path, i)))
+		}
+	case ParamTypeObject:
+		keys := make([]string, 0, len(value.ObjectVal))
+		for key := range value.ObjectVal {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			errs = errs.Also(validateParamConstrainedValue(p, pattern, value.ObjectVal[key], path+"."+key))
+		}
+	}
+	return errs
+}
+
+// ValidateParamValues checks the values of params against the enum and the
+// pattern of the specs of the same name, see ValidateParamValue. path is the
+// path of params, e.g. spec.inputs.params, the errors point at the value of
+// the param at their index.
+func ValidateParamValues(specs []ParamSpec, params []Param, path string) *apis.FieldError {
+	byName := make(map[string]ParamSpec, len(specs))
+	for _, spec := range specs {
+		byName[spec.Name] = spec
+	}
+	var errs *apis.FieldError
+	for i, param := range params {
+		// The values read from Secrets are only known to the steps.
+		if param.ValueFrom != nil {
+			continue
+		}
+		if spec, ok := byName[param.Name]; ok {
+			errs = errs.Also(ValidateParamValue(spec, param.Value, fmt.Sprintf("%s[%d].value", path, i)))
+		}
+	}
+	return errs
+}
+
+func validateParamConstrainedValue(p ParamSpec, pattern *regexp.Regexp, v, path string) *apis.FieldError {
+	if len(p.Enum) > 0 {
+		allowed := false
+		for _, e := range p.Enum {
+			if v == e {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("value of param %q isn't one of %s", p.Name, strings.Join(p.Enum, ", ")),
+				Paths:   []string{path},
+			}
+		}
+	}
+	if pattern != nil && !pattern.MatchString(v) {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("value of param %q doesn't match the pattern %q", p.Name, p.Pattern),
+			Paths:   []string{path},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/test/builder"
+	"knative.dev/pkg/apis"
+)
+
+func TestValidateParamValue(t *testing.T) {
+	enum := v1alpha1.ParamSpec{Name: "env", Enum: []string{"dev", "prod"}}
+	pattern := v1alpha1.ParamSpec{Name: "tag", Pattern: "^v[0-9]+$"}
+	for _, c := range []struct {
+		desc  string
+		spec  v1alpha1.ParamSpec
+		value *v1alpha1.ArrayOrString
+		want  *apis.FieldError
+	}{{
+		desc:  "unconstrained",
+		spec:  v1alpha1.ParamSpec{Name: "anything"},
+		value: builder.ArrayOrString("staging"),
+	}, {
+		desc:  "string in enum",
+		spec:  enum,
+		value: builder.ArrayOrString("prod"),
+	}, {
+		desc:  "string not in enum",
+		spec:  enum,
+		value: builder.ArrayOrString("staging"),
+		want: &apis.FieldError{
+			Message: `value of param "env" isn't one of dev, prod`,
+			Paths:   []string{"value"},
+		},
+	}, {
+		desc:  "array elements matching the pattern",
+		spec:  pattern,
+		value: builder.ArrayOrString("v1", "v2"),
+	}, {
+		desc:  "array elements not matching the pattern",
+		spec:  pattern,
+		value: builder.ArrayOrString("v1", "latest", "1"),
+		want: (&apis.FieldError{
+			Message: `value of param "tag" doesn't match the pattern "^v[0-9]+$"`,
+			Paths:   []string{"value[1]"},
+		}).Also(&apis.FieldError{
+			Message: `value of param "tag" doesn't match the pattern "^v[0-9]+$"`,
+			Paths:   []string{"value[2]"},
+		}),
+	}, {
+		desc:  "object values not matching the pattern",
+		spec:  pattern,
+		value: builder.ObjectValue(map[string]string{"from": "v1", "to": "latest"}),
+		want: &apis.FieldError{
+			Message: `value of param "tag" doesn't match the pattern "^v[0-9]+$"`,
+			Paths:   []string{"value.to"},
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			got := v1alpha1.ValidateParamValue(c.spec, *c.value, "value")
+			if c.want == nil {
+				if got != nil {
+					t.Errorf("ValidateParamValue() = %v, wanted no error", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("ValidateParamValue() = nil, wanted %v", c.want)
+			}
+			if d := cmp.Diff(c.want.Error(), got.Error()); d != "" {
+				t.Errorf("ValidateParamValue() (-want, +got) = %s", d)
+			}
+		})
+	}
+}
@@ -103,6 +103,13 @@ type PipelineTask struct {
 	// +optional
 	Retries int `json:"retries,omitempty"`
 
+	// RetryPodTemplates are the pod templates of the retries, in order, the
+	// last one being used for the retries beyond them. The first attempt runs
+	// with the pod template of the PipelineRun, which the template of a retry
+	// replaces.
+	// +optional
+	RetryPodTemplates []PodTemplate `json:"retryPodTemplates,omitempty"`
+
 	// RunAfter is the list of PipelineTask names that should be executed before
 	// this Task executes. (Used to force a specific ordering in graph execution.)
 	// +optional
@@ -115,6 +122,14 @@ type PipelineTask struct {
 	// Parameters declares parameters passed to this task.
 	// +optional
 	Params []Param `json:"params,omitempty"`
+	// Checkout clones a Git repository before the steps of the task run,
+	// instead of the one the task checks out itself, if any.
+	// +optional
+	Checkout *Checkout `json:"checkout,omitempty"`
+	// Stage groups this task with the other PipelineTasks of the same stage,
+	// whose status the PipelineRun reports together.
+	// +optional
+	Stage string `json:"stage,omitempty"`
 }
 
 // PipelineTaskParam is used to provide arbitrary string parameters to a Task.
@@ -204,6 +219,10 @@ type TaskRef struct {
 	// API version of the referent
 	// +optional
 	APIVersion string `json:"apiVersion,omitempty"`
+	// Checksum is the expected checksum of the spec of the referent,
+	// sha256:<hex>. The TaskRun fails if the Task doesn't match it.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
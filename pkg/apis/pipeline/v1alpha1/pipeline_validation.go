@@ -31,10 +31,13 @@ import (
 // Validate checks that the Pipeline structure is valid but does not validate
 // that any references resources exist, that is done at run time.
 func (p *Pipeline) Validate(ctx context.Context) *apis.FieldError {
-	if err := validateObjectMetadata(p.GetObjectMeta()); err != nil {
-		return err.ViaField("metadata")
-	}
-	return p.Spec.Validate(ctx)
+	return noteValidationErrors(ctx, p.validate(ctx))
+}
+
+func (p *Pipeline) validate(ctx context.Context) *apis.FieldError {
+	errs := validateObjectMetadata(p.GetObjectMeta()).ViaField("metadata")
+	errs = errs.Also(validateCatalogChecksum(ctx, "Pipeline", p.Name, &p.Spec))
+	return errs.Also(p.Spec.Validate(ctx))
 }
 
 func validateDeclaredResources(ps *PipelineSpec) error {
@@ -123,20 +126,40 @@ func (ps *PipelineSpec) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrMissingField(apis.CurrentField)
 	}
 
+	var errs *apis.FieldError
+
 	// Names cannot be duplicated
 	taskNames := map[string]struct{}{}
 	for i, t := range ps.Tasks {
 		// Task names are appended to the container name, which must exist and
 		// must be a valid k8s name
 		if errSlice := validation.IsQualifiedName(t.Name); len(errSlice) != 0 {
-			return apis.ErrInvalidValue(strings.Join(errSlice, ","), fmt.Sprintf("spec.tasks[%d].name", i))
+			errs = errs.Also(apis.ErrInvalidValue(strings.Join(errSlice, ","), fmt.Sprintf("spec.tasks[%d].name", i)))
 		}
 		// TaskRef name must be a valid k8s name
 		if errSlice := validation.IsQualifiedName(t.TaskRef.Name); len(errSlice) != 0 {
-			return apis.ErrInvalidValue(strings.Join(errSlice, ","), fmt.Sprintf("spec.tasks[%d].taskRef.name", i))
+			errs = errs.Also(apis.ErrInvalidValue(strings.Join(errSlice, ","), fmt.Sprintf("spec.tasks[%d].taskRef.name", i)))
 		}
+		errs = errs.Also(t.TaskRef.Validate(ctx).ViaField(fmt.Sprintf("spec.tasks[%d].taskRef", i)))
 		if _, ok := taskNames[t.Name]; ok {
-			return apis.ErrMultipleOneOf(fmt.Sprintf("spec.tasks[%d].name", i))
+			errs = errs.Also(apis.ErrMultipleOneOf(fmt.Sprintf("spec.tasks[%d].name", i)))
+		}
+		if t.Checkout != nil {
+			errs = errs.Also(t.Checkout.Validate(ctx).ViaField(fmt.Sprintf("spec.tasks[%d].checkout", i)))
+		}
+		if len(t.RetryPodTemplates) > 0 && t.Retries == 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: "retryPodTemplates can't be set without retries",
+				Paths:   []string{fmt.Sprintf("spec.tasks[%d].retryPodTemplates", i)},
+			})
+		}
+		for j, pt := range t.RetryPodTemplates {
+			errs = errs.Also(pt.Validate(fmt.Sprintf("spec.tasks[%d].retryPodTemplates[%d]", i, j)))
+		}
+		if t.Stage != "" {
+			if errSlice := validation.IsQualifiedName(t.Stage); len(errSlice) != 0 {
+				errs = errs.Also(apis.ErrInvalidValue(strings.Join(errSlice, ","), fmt.Sprintf("spec.tasks[%d].stage", i)))
+			}
 		}
 		taskNames[t.Name] = struct{}{}
 	}
@@ -144,28 +167,27 @@ func (ps *PipelineSpec) Validate(ctx context.Context) *apis.FieldError {
 	// All declared resources should be used, and the Pipeline shouldn't try to use any resources
 	// that aren't declared
 	if err := validateDeclaredResources(ps); err != nil {
-		return apis.ErrInvalidValue(err.Error(), "spec.resources")
+		errs = errs.Also(apis.ErrInvalidValue(err.Error(), "spec.resources"))
 	}
 
 	// The from values should make sense
 	if err := validateFrom(ps.Tasks); err != nil {
-		return apis.ErrInvalidValue(err.Error(), "spec.tasks.resources.inputs.from")
+		errs = errs.Also(apis.ErrInvalidValue(err.Error(), "spec.tasks.resources.inputs.from"))
 	}
 
 	// Validate the pipeline task graph
 	if err := validateGraph(ps.Tasks); err != nil {
-		return apis.ErrInvalidValue(err.Error(), "spec.tasks")
+		errs = errs.Also(apis.ErrInvalidValue(err.Error(), "spec.tasks"))
 	}
 
 	// The parameter variables should be valid
-	if err := validatePipelineParameterVariables(ps.Tasks, ps.Params); err != nil {
-		return err
-	}
+	errs = errs.Also(validatePipelineParameterVariables(ps.Tasks, ps.Params))
 
-	return nil
+	return errs
 }
 
 func validatePipelineParameterVariables(tasks []PipelineTask, params []ParamSpec) *apis.FieldError {
+	var errs *apis.FieldError
 	parameterNames := map[string]struct{}{}
 	arrayParameterNames := map[string]struct{}{}
 
@@ -178,21 +200,24 @@ func validatePipelineParameterVariables(tasks []PipelineTask, params []ParamSpec
 			}
 		}
 		if !validType {
-			return apis.ErrInvalidValue(string(p.Type), fmt.Sprintf("spec.params.%s.type", p.Name))
+			errs = errs.Also(apis.ErrInvalidValue(string(p.Type), fmt.Sprintf("spec.params.%s.type", p.Name)))
 		}
 
 		// If a default value is provided, ensure its type matches param's declared type.
 		if (p.Default != nil) && (p.Default.Type != p.Type) {
-			return &apis.FieldError{
+			errs = errs.Also(&apis.FieldError{
 				Message: fmt.Sprintf(
 					"\"%v\" type does not match default value's type: \"%v\"", p.Type, p.Default.Type),
 				Paths: []string{
 					fmt.Sprintf("spec.params.%s.type", p.Name),
 					fmt.Sprintf("spec.params.%s.default.type", p.Name),
 				},
-			}
+			})
 		}
 
+		errs = errs.Also(validateObjectParamSpec(p, fmt.Sprintf("spec.params.%s", p.Name)))
+		errs = errs.Also(validateParamConstraints(p, fmt.Sprintf("spec.params.%s", p.Name)))
+
 		// Add parameter name to parameterNames, and to arrayParameterNames if type is array.
 		parameterNames[p.Name] = struct{}{}
 		if p.Type == ParamTypeArray {
@@ -200,32 +225,47 @@ func validatePipelineParameterVariables(tasks []PipelineTask, params []ParamSpec
 		}
 	}
 
-	return validatePipelineVariables(tasks, "params", parameterNames, arrayParameterNames)
+	return errs.Also(validatePipelineVariables(tasks, "params", parameterNames, arrayParameterNames, objectParamKeys(params)))
 }
 
-func validatePipelineVariables(tasks []PipelineTask, prefix string, paramNames map[string]struct{}, arrayParamNames map[string]struct{}) *apis.FieldError {
+func validatePipelineVariables(tasks []PipelineTask, prefix string, paramNames map[string]struct{}, arrayParamNames map[string]struct{}, objectParamKeys map[string]map[string]struct{}) *apis.FieldError {
+	var errs *apis.FieldError
 	for _, task := range tasks {
+		if task.Checkout != nil {
+			for _, f := range []struct{ name, value string }{
+				{"checkout.repo", task.Checkout.Repo},
+				{"checkout.revision", task.Checkout.Revision},
+				{"checkout.workspace", task.Checkout.Workspace},
+			} {
+				errs = errs.Also(validatePipelineVariable(f.name, f.value, prefix, paramNames))
+				errs = errs.Also(validatePipelineNoArrayReferenced(f.name, f.value, prefix, arrayParamNames))
+				errs = errs.Also(validatePipelineObjectKeys(f.name, f.value, prefix, objectParamKeys))
+			}
+		}
 		for _, param := range task.Params {
-			if param.Value.Type == ParamTypeString {
-				if err := validatePipelineVariable(fmt.Sprintf("param[%s]", param.Name), param.Value.StringVal, prefix, paramNames); err != nil {
-					return err
-				}
-				if err := validatePipelineNoArrayReferenced(fmt.Sprintf("param[%s]", param.Name), param.Value.StringVal, prefix, arrayParamNames); err != nil {
-					return err
+			name := fmt.Sprintf("param[%s]", param.Name)
+			switch param.Value.Type {
+			case ParamTypeString:
+				errs = errs.Also(validatePipelineVariable(name, param.Value.StringVal, prefix, paramNames))
+				errs = errs.Also(validatePipelineNoArrayReferenced(name, param.Value.StringVal, prefix, arrayParamNames))
+				errs = errs.Also(validatePipelineObjectKeys(name, param.Value.StringVal, prefix, objectParamKeys))
+			case ParamTypeObject:
+				for key, value := range param.Value.ObjectVal {
+					name := fmt.Sprintf("param[%s].%s", param.Name, key)
+					errs = errs.Also(validatePipelineVariable(name, value, prefix, paramNames))
+					errs = errs.Also(validatePipelineNoArrayReferenced(name, value, prefix, arrayParamNames))
+					errs = errs.Also(validatePipelineObjectKeys(name, value, prefix, objectParamKeys))
 				}
-			} else {
+			default:
 				for _, arrayElement := range param.Value.ArrayVal {
-					if err := validatePipelineVariable(fmt.Sprintf("param[%s]", param.Name), arrayElement, prefix, paramNames); err != nil {
-						return err
-					}
-					if err := validatePipelineArraysIsolated(fmt.Sprintf("param[%s]", param.Name), arrayElement, prefix, arrayParamNames); err != nil {
-						return err
-					}
+					errs = errs.Also(validatePipelineVariable(name, arrayElement, prefix, paramNames))
+					errs = errs.Also(validatePipelineArraysIsolated(name, arrayElement, prefix, arrayParamNames))
+					errs = errs.Also(validatePipelineObjectKeys(name, arrayElement, prefix, objectParamKeys))
 				}
 			}
 		}
 	}
-	return nil
+	return errs
 }
 
 func validatePipelineVariable(name, value, prefix string, vars map[string]struct{}) *apis.FieldError {
@@ -236,6 +276,10 @@ func validatePipelineNoArrayReferenced(name, value, prefix string, vars map[stri
 	return ValidateVariableProhibited(name, value, prefix, "", "task parameter", "pipelinespec.params", vars)
 }
 
+func validatePipelineObjectKeys(name, value, prefix string, objects map[string]map[string]struct{}) *apis.FieldError {
+	return ValidateObjectKeys(name, value, prefix, "", "task parameter", "pipelinespec.params", objects)
+}
+
 func validatePipelineArraysIsolated(name, value, prefix string, vars map[string]struct{}) *apis.FieldError {
 	return ValidateVariableIsolated(name, value, prefix, "", "task parameter", "pipelinespec.params", vars)
 }
@@ -18,6 +18,7 @@ package v1alpha1_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
@@ -66,6 +67,12 @@ func TestPipeline_Validate(t *testing.T) {
 			tb.PipelineTask("foo", "foo-task"),
 		)),
 		failureExpected: true,
+	}, {
+		name: "pipeline spec invalid task stage",
+		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+			tb.PipelineTask("foo", "foo-task", tb.PipelineTaskStage("build stage")),
+		)),
+		failureExpected: true,
 	}, {
 		name: "pipeline spec empty task name",
 		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
@@ -111,6 +118,76 @@ func TestPipelineSpec_Validate(t *testing.T) {
 			tb.PipelineTask("bar", "bar-task"),
 		)),
 		failureExpected: false,
+	}, {
+		name: "retry pod templates",
+		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+			tb.PipelineTask("foo", "foo-task", tb.Retries(2), tb.RetryPodTemplates(v1alpha1.PodTemplate{
+				NodeSelector: map[string]string{"lifecycle": "on-demand"},
+			})),
+		)),
+		failureExpected: false,
+	}, {
+		name: "retry pod templates without retries",
+		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+			tb.PipelineTask("foo", "foo-task", tb.RetryPodTemplates(v1alpha1.PodTemplate{
+				NodeSelector: map[string]string{"lifecycle": "on-demand"},
+			})),
+		)),
+		failureExpected: true,
+	}, {
+		name: "invalid retry pod template",
+		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+			tb.PipelineTask("foo", "foo-task", tb.Retries(1), tb.RetryPodTemplates(v1alpha1.PodTemplate{
+				BuildProfile: &v1alpha1.BuildProfile{Mode: "privileged"},
+			})),
+		)),
+		failureExpected: true,
+	}, {
+		name: "valid object parameter keys",
+		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+			tb.PipelineParamSpec("repo", v1alpha1.ParamTypeObject, tb.ParamSpecProperties("url", "revision"),
+				tb.ParamSpecObjectDefault(map[string]string{"url": "https://github.com/tektoncd/pipeline", "revision": "master"})),
+			tb.PipelineTask("foo", "foo-task",
+				tb.PipelineTaskParam("url", "$(params.repo.url)"),
+				tb.PipelineTaskParam("revision", "$(params.repo.revision)")),
+		)),
+		failureExpected: false,
+	}, {
+		name: "object parameter without properties",
+		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+			tb.PipelineParamSpec("repo", v1alpha1.ParamTypeObject),
+			tb.PipelineTask("foo", "foo-task"),
+		)),
+		failureExpected: true,
+	}, {
+		name: "object parameter referenced without a key",
+		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+			tb.PipelineParamSpec("repo", v1alpha1.ParamTypeObject, tb.ParamSpecProperties("url")),
+			tb.PipelineTask("foo", "foo-task",
+				tb.PipelineTaskParam("url", "$(params.repo)")),
+		)),
+		failureExpected: true,
+	}, {
+		name: "object parameter referenced by an undeclared key",
+		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+			tb.PipelineParamSpec("repo", v1alpha1.ParamTypeObject, tb.ParamSpecProperties("url")),
+			tb.PipelineTask("foo", "foo-task",
+				tb.PipelineTaskParam("revision", "$(params.repo.revision)")),
+		)),
+		failureExpected: true,
+	}, {
+		name: "task checksum",
+		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+			tb.PipelineTask("foo", "foo-task",
+				tb.PipelineTaskRefChecksum("sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")),
+		)),
+		failureExpected: false,
+	}, {
+		name: "invalid task checksum",
+		p: tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+			tb.PipelineTask("foo", "foo-task", tb.PipelineTaskRefChecksum("sha256:E3B0C442")),
+		)),
+		failureExpected: true,
 	}, {
 		// Adding this case because `task.Resources` is a pointer, explicitly making sure this is handled
 		name: "task without resources",
@@ -315,3 +392,20 @@ func TestPipelineSpec_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelineSpec_ValidateReportsAllErrors(t *testing.T) {
+	p := tb.Pipeline("pipeline", "namespace", tb.PipelineSpec(
+		tb.PipelineTask("_foo", "foo-task"),
+		tb.PipelineTask("bar", "bar-task",
+			tb.PipelineTaskParam("a-param", "$(params.does-not-exist)")),
+	))
+	err := p.Spec.Validate(context.Background())
+	if err == nil {
+		t.Fatal("PipelineSpec.Validate() did not return error, wanted error")
+	}
+	for _, want := range []string{"spec.tasks[0].name", "pipelinespec.params.param[a-param]"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected PipelineSpec.Validate() to report %s, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+// Check that PipelineQuota may be validated and defaulted.
+var _ apis.Validatable = (*PipelineQuota)(nil)
+var _ apis.Defaultable = (*PipelineQuota)(nil)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PipelineQuota limits how many of the PipelineRuns and TaskRuns of its
+// namespace it selects run at the same time, and start every hour. The runs
+// over quota wait for it to free up before starting. The TaskRuns of
+// PipelineRuns aren't counted, nor held, on their own.
+// +k8s:openapi-gen=true
+type PipelineQuota struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec holds the desired state of the PipelineQuota from the client
+	// +optional
+	Spec PipelineQuotaSpec `json:"spec"`
+}
+
+// PipelineQuotaSpec defines the desired state of the PipelineQuota
+type PipelineQuotaSpec struct {
+	// Selector selects the runs of the namespace the quota applies to, all
+	// of them if empty.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// MaxConcurrentRuns is the number of the selected runs which may be
+	// running at the same time.
+	// +optional
+	MaxConcurrentRuns *int32 `json:"maxConcurrentRuns,omitempty"`
+	// MaxRunsPerHour is the number of the selected runs which may start in
+	// any hour.
+	// +optional
+	MaxRunsPerHour *int32 `json:"maxRunsPerHour,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PipelineQuotaList contains a list of PipelineQuotas
+type PipelineQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PipelineQuota `json:"items"`
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func (pq *PipelineQuota) Validate(ctx context.Context) *apis.FieldError {
+	return noteValidationErrors(ctx, pq.validate(ctx))
+}
+
+func (pq *PipelineQuota) validate(ctx context.Context) *apis.FieldError {
+	if err := validateObjectMetadata(pq.GetObjectMeta()); err != nil {
+		return err.ViaField("metadata")
+	}
+	return pq.Spec.Validate(ctx)
+}
+
+func (qs *PipelineQuotaSpec) Validate(ctx context.Context) *apis.FieldError {
+	if qs.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(qs.Selector); err != nil {
+			return apis.ErrInvalidValue(err.Error(), "spec.selector")
+		}
+	}
+	if qs.MaxConcurrentRuns == nil && qs.MaxRunsPerHour == nil {
+		return &apis.FieldError{
+			Message: "expected at least one, got neither",
+			Paths:   []string{"spec.maxConcurrentRuns", "spec.maxRunsPerHour"},
+		}
+	}
+	for _, limit := range []struct {
+		field string
+		value *int32
+	}{
+		{"spec.maxConcurrentRuns", qs.MaxConcurrentRuns},
+		{"spec.maxRunsPerHour", qs.MaxRunsPerHour},
+	} {
+		if limit.value != nil && *limit.value < 0 {
+			return apis.ErrInvalidValue(fmt.Sprintf("%d should be >= 0", *limit.value), limit.field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+)
+
+func TestPipelineQuota_Validate(t *testing.T) {
+	for _, pq := range []*v1alpha1.PipelineQuota{
+		tb.PipelineQuota("concurrency", "foo", tb.PipelineQuotaMaxConcurrentRuns(5)),
+		tb.PipelineQuota("nightly", "foo",
+			tb.PipelineQuotaSelector(map[string]string{"trigger": "nightly"}),
+			tb.PipelineQuotaMaxConcurrentRuns(2),
+			tb.PipelineQuotaMaxRunsPerHour(0),
+		),
+	} {
+		t.Run(pq.Name, func(t *testing.T) {
+			if err := pq.Validate(context.Background()); err != nil {
+				t.Errorf("PipelineQuota.Validate() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPipelineQuota_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		pq            *v1alpha1.PipelineQuota
+		expectedError apis.FieldError
+	}{{
+		name: "invalid name",
+		pq:   tb.PipelineQuota("invalid.name", "foo", tb.PipelineQuotaMaxConcurrentRuns(5)),
+		expectedError: apis.FieldError{
+			Message: "Invalid resource name: special character . must not be present",
+			Paths:   []string{"metadata.name"},
+		},
+	}, {
+		name: "invalid selector",
+		pq: tb.PipelineQuota("nightly", "foo", tb.PipelineQuotaMaxConcurrentRuns(5), func(pq *v1alpha1.PipelineQuota) {
+			pq.Spec.Selector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "trigger",
+				Operator: "Within",
+			}}}
+		}),
+		expectedError: apis.FieldError{
+			Message: `invalid value: "Within" is not a valid pod selector operator`,
+			Paths:   []string{"spec.selector"},
+		},
+	}, {
+		name: "no limit",
+		pq:   tb.PipelineQuota("nightly", "foo"),
+		expectedError: apis.FieldError{
+			Message: "expected at least one, got neither",
+			Paths:   []string{"spec.maxConcurrentRuns", "spec.maxRunsPerHour"},
+		},
+	}, {
+		name: "negative limit",
+		pq:   tb.PipelineQuota("nightly", "foo", tb.PipelineQuotaMaxRunsPerHour(-1)),
+		expectedError: apis.FieldError{
+			Message: "invalid value: -1 should be >= 0",
+			Paths:   []string{"spec.maxRunsPerHour"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.pq.Validate(context.Background())
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing for %v", tc.pq)
+			}
+			if d := cmp.Diff(tc.expectedError, *err, cmpopts.IgnoreUnexported(apis.FieldError{})); d != "" {
+				t.Errorf("PipelineQuota.Validate() errors diff -want, +got: %v", d)
+			}
+		})
+	}
+}
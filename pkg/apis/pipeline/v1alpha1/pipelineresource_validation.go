@@ -27,6 +27,10 @@ import (
 )
 
 func (r *PipelineResource) Validate(ctx context.Context) *apis.FieldError {
+	return noteValidationErrors(ctx, r.validate(ctx))
+}
+
+func (r *PipelineResource) validate(ctx context.Context) *apis.FieldError {
 	if err := validateObjectMetadata(r.GetObjectMeta()); err != nil {
 		return err.ViaField("metadata")
 	}
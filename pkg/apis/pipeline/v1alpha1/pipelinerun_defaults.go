@@ -25,7 +25,9 @@ import (
 )
 
 func (pr *PipelineRun) SetDefaults(ctx context.Context) {
+	pr.setRerunDefaults(ctx)
 	pr.Spec.SetDefaults(ctx)
+	setCreatorGroups(ctx, &pr.ObjectMeta)
 }
 
 func (prs *PipelineRunSpec) SetDefaults(ctx context.Context) {
@@ -47,4 +49,10 @@ func (prs *PipelineRunSpec) SetDefaults(ctx context.Context) {
 	if prs.ServiceAccountName == "" && defaultSA != "" {
 		prs.ServiceAccountName = defaultSA
 	}
+
+	defaultBuildProfile := cfg.Defaults.DefaultBuildProfile
+	if prs.PodTemplate.BuildProfile == nil && defaultBuildProfile != "" {
+		prs.PodTemplate.BuildProfile = &BuildProfile{Mode: BuildProfileMode(defaultBuildProfile)}
+	}
+	prs.PodTemplate.setDefaultGroups(cfg.Defaults.DefaultFSGroup, cfg.Defaults.DefaultSupplementalGroups)
 }
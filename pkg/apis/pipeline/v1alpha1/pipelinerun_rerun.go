@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"knative.dev/pkg/apis"
+)
+
+// RerunOfAnnotationKey is the annotation naming the PipelineRun of the same
+// namespace a new PipelineRun re-runs. The webhook copies the spec of that
+// run into the new one when it's created.
+const RerunOfAnnotationKey = "pipeline.tekton.dev/re-run-of"
+
+// PipelineRunGetter gets the PipelineRun with the given namespace and name.
+type PipelineRunGetter func(namespace, name string) (*PipelineRun, error)
+
+// pipelineRunGetterKey is used as the key for associating a
+// PipelineRunGetter with a context.Context.
+type pipelineRunGetterKey struct{}
+
+// WithPipelineRunGetter notes on the context the function getting the
+// PipelineRuns which new PipelineRuns re-run.
+func WithPipelineRunGetter(ctx context.Context, get PipelineRunGetter) context.Context {
+	return context.WithValue(ctx, pipelineRunGetterKey{}, get)
+}
+
+// pipelineRunGetter returns the function noted on the context to get the
+// PipelineRuns which new PipelineRuns re-run, if any.
+func pipelineRunGetter(ctx context.Context) PipelineRunGetter {
+	get, _ := ctx.Value(pipelineRunGetterKey{}).(PipelineRunGetter)
+	return get
+}
+
+// setRerunDefaults copies the spec of the PipelineRun pr re-runs into pr
+// when it's created. The params of pr take precedence over the ones of the
+// same name of the run it re-runs, while the rest of its spec is replaced.
+// A run which can't be found is left to validation to report.
+func (pr *PipelineRun) setRerunDefaults(ctx context.Context) {
+	name := pr.Annotations[RerunOfAnnotationKey]
+	get := pipelineRunGetter(ctx)
+	if name == "" || get == nil || !apis.IsInCreate(ctx) {
+		return
+	}
+	prior, err := get(pr.Namespace, name)
+	if err != nil {
+		return
+	}
+	spec := prior.Spec.DeepCopy()
+	spec.Status = ""
+	spec.Params = mergeParams(spec.Params, pr.Spec.Params)
+	pr.Spec = *spec
+}
+
+// mergeParams returns the params of base, with the value of the params of
+// overrides of the same name, followed by the other params of overrides.
+func mergeParams(base, overrides []Param) []Param {
+	if len(overrides) == 0 {
+		return base
+	}
+	values := map[string]ArrayOrString{}
+	for _, p := range overrides {
+		values[p.Name] = p.Value
+	}
+	var params []Param
+	for _, p := range base {
+		if v, ok := values[p.Name]; ok {
+			p.Value = v
+			delete(values, p.Name)
+		}
+		params = append(params, p)
+	}
+	for _, p := range overrides {
+		if _, ok := values[p.Name]; ok {
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// validateRerunOf validates that the PipelineRun a new PipelineRun re-runs
+// exists, and that an update doesn't change which one it is.
+func (pr *PipelineRun) validateRerunOf(ctx context.Context) *apis.FieldError {
+	name := pr.Annotations[RerunOfAnnotationKey]
+	if old, ok := apis.GetBaseline(ctx).(*PipelineRun); ok && apis.IsInUpdate(ctx) {
+		if old.Annotations[RerunOfAnnotationKey] != name {
+			return (&apis.FieldError{
+				Message: "annotation value is immutable",
+				Paths:   []string{RerunOfAnnotationKey},
+			}).ViaField("metadata.annotations")
+		}
+		return nil
+	}
+	get := pipelineRunGetter(ctx)
+	if name == "" || get == nil || !apis.IsInCreate(ctx) {
+		return nil
+	}
+	if name == pr.Name {
+		return (&apis.FieldError{
+			Message: "a PipelineRun can't re-run itself",
+			Paths:   []string{RerunOfAnnotationKey},
+		}).ViaField("metadata.annotations")
+	}
+	if _, err := get(pr.Namespace, name); err != nil {
+		return (&apis.FieldError{
+			Message: fmt.Sprintf("can't get the PipelineRun %q to re-run", name),
+			Paths:   []string{RerunOfAnnotationKey},
+			Details: err.Error(),
+		}).ViaField("metadata.annotations")
+	}
+	return nil
+}
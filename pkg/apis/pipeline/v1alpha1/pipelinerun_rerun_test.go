@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/apis"
+)
+
+func getPipelineRuns(runs ...*v1alpha1.PipelineRun) v1alpha1.PipelineRunGetter {
+	return func(namespace, name string) (*v1alpha1.PipelineRun, error) {
+		for _, pr := range runs {
+			if pr.Namespace == namespace && pr.Name == name {
+				return pr, nil
+			}
+		}
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "tekton.dev", Resource: "pipelineruns"}, name)
+	}
+}
+
+func TestPipelineRun_SetRerunDefaults(t *testing.T) {
+	prior := tb.PipelineRun("prior", "foo", tb.PipelineRunSpec("pipeline",
+		tb.PipelineRunCancelled,
+		tb.PipelineRunServiceAccountName("builder"),
+		tb.PipelineRunTimeout(2*time.Hour),
+		tb.PipelineRunParam("revision", "main"),
+		tb.PipelineRunParam("env", "staging"),
+	))
+	ctx := v1alpha1.WithPipelineRunGetter(apis.WithinCreate(context.Background()), getPipelineRuns(prior))
+
+	pr := tb.PipelineRun("rerun", "foo", tb.PipelineRunAnnotation(v1alpha1.RerunOfAnnotationKey, "prior"))
+	pr.Spec.Params = []v1alpha1.Param{
+		{Name: "env", Value: *tb.ArrayOrString("production")},
+		{Name: "debug", Value: *tb.ArrayOrString("true")},
+	}
+	pr.SetDefaults(ctx)
+
+	want := tb.PipelineRun("rerun", "foo", tb.PipelineRunSpec("pipeline",
+		tb.PipelineRunServiceAccountName("builder"),
+		tb.PipelineRunTimeout(2*time.Hour),
+		tb.PipelineRunParam("revision", "main"),
+		tb.PipelineRunParam("env", "production"),
+		tb.PipelineRunParam("debug", "true"),
+	))
+	if d := cmp.Diff(want.Spec, pr.Spec); d != "" {
+		t.Errorf("Diff spec -want, +got: %s", d)
+	}
+	if err := pr.Validate(ctx); err != nil {
+		t.Errorf("Expected the re-run to be valid, got %v", err)
+	}
+
+	// The spec of a run isn't replaced when it's updated.
+	updated := tb.PipelineRun("rerun", "foo",
+		tb.PipelineRunSpec("other"),
+		tb.PipelineRunAnnotation(v1alpha1.RerunOfAnnotationKey, "prior"),
+	)
+	updated.SetDefaults(v1alpha1.WithPipelineRunGetter(apis.WithinUpdate(context.Background(), pr), getPipelineRuns(prior)))
+	if updated.Spec.PipelineRef.Name != "other" {
+		t.Errorf("Expected the spec of the updated run to be kept, got %v", updated.Spec)
+	}
+}
+
+func TestPipelineRun_ValidateRerunOf(t *testing.T) {
+	prior := tb.PipelineRun("prior", "foo", tb.PipelineRunSpec("pipeline"))
+	get := getPipelineRuns(prior)
+	for _, tc := range []struct {
+		name    string
+		pr      *v1alpha1.PipelineRun
+		wantErr *apis.FieldError
+	}{{
+		name: "missing run",
+		pr: tb.PipelineRun("rerun", "foo",
+			tb.PipelineRunSpec("pipeline"),
+			tb.PipelineRunAnnotation(v1alpha1.RerunOfAnnotationKey, "missing"),
+		),
+		wantErr: &apis.FieldError{
+			Message: `can't get the PipelineRun "missing" to re-run`,
+			Paths:   []string{"metadata.annotations." + v1alpha1.RerunOfAnnotationKey},
+			Details: `pipelineruns.tekton.dev "missing" not found`,
+		},
+	}, {
+		name: "run of another namespace",
+		pr: tb.PipelineRun("rerun", "bar",
+			tb.PipelineRunSpec("pipeline"),
+			tb.PipelineRunAnnotation(v1alpha1.RerunOfAnnotationKey, "prior"),
+		),
+		wantErr: &apis.FieldError{
+			Message: `can't get the PipelineRun "prior" to re-run`,
+			Paths:   []string{"metadata.annotations." + v1alpha1.RerunOfAnnotationKey},
+			Details: `pipelineruns.tekton.dev "prior" not found`,
+		},
+	}, {
+		name: "itself",
+		pr: tb.PipelineRun("prior", "foo",
+			tb.PipelineRunSpec("pipeline"),
+			tb.PipelineRunAnnotation(v1alpha1.RerunOfAnnotationKey, "prior"),
+		),
+		wantErr: &apis.FieldError{
+			Message: "a PipelineRun can't re-run itself",
+			Paths:   []string{"metadata.annotations." + v1alpha1.RerunOfAnnotationKey},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.pr.Validate(v1alpha1.WithPipelineRunGetter(apis.WithinCreate(context.Background()), get))
+			if err == nil {
+				t.Fatalf("Expected an error, got nil")
+			}
+			if d := cmp.Diff(tc.wantErr.Error(), err.Error()); d != "" {
+				t.Errorf("PipelineRun.Validate() error diff -want, +got: %s", d)
+			}
+		})
+	}
+
+	old := tb.PipelineRun("rerun", "foo",
+		tb.PipelineRunSpec("pipeline"),
+		tb.PipelineRunAnnotation(v1alpha1.RerunOfAnnotationKey, "prior"),
+	)
+	updated := old.DeepCopy()
+	updated.Annotations[v1alpha1.RerunOfAnnotationKey] = "other"
+	if err := updated.Validate(v1alpha1.WithPipelineRunGetter(apis.WithinUpdate(context.Background(), old), get)); err == nil {
+		t.Error("Expected an error changing the run a PipelineRun re-runs")
+	}
+}
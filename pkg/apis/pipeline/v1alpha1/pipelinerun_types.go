@@ -54,6 +54,12 @@ type PipelineRunSpec struct {
 	Resources []PipelineResourceBinding `json:"resources,omitempty"`
 	// Params is a list of parameter names and values.
 	Params []Param `json:"params,omitempty"`
+	// ParamsFrom is a list of ConfigMaps and Secrets whose keys are used as
+	// parameter names and values. When a key exists in multiple sources, the
+	// value of the last source takes precedence. Params take precedence over
+	// all of them.
+	// +optional
+	ParamsFrom []ParamsFromSource `json:"paramsFrom,omitempty"`
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
 	// DeprecatedServiceAccount is a depreciated alias for ServiceAccountName.
@@ -71,11 +77,40 @@ type PipelineRunSpec struct {
 	// Refer to Go's ParseDuration documentation for expected format: https://golang.org/pkg/time/#ParseDuration
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Time after which a finished PipelineRun is deleted, along with its
+	// TaskRuns. Unset means the PipelineRun is never deleted automatically.
+	// +optional
+	ExpirationSecondsTTL *metav1.Duration `json:"expirationSecondsTTL,omitempty"`
+	// TaskRunTTL is set as the expirationSecondsTTL of the TaskRuns of the
+	// PipelineRun, which applies once the PipelineRun is deleted without
+	// them, or has expired itself.
+	// +optional
+	TaskRunTTL *metav1.Duration `json:"taskRunTTL,omitempty"`
+	// ExecutionWindow restricts when the PipelineRun may start. Its
+	// TaskRuns start whenever the Pipeline needs them.
+	// +optional
+	ExecutionWindow *ExecutionWindow `json:"executionWindow,omitempty"`
+	// Files are small files mounted in /tekton/files in the steps of all the
+	// TaskRuns of the PipelineRun.
+	// +optional
+	Files []InlineFile `json:"files,omitempty"`
 
 	// PodTemplate holds pod specific configuration
 	PodTemplate PodTemplate `json:"podTemplate,omitempty"`
 }
 
+// ParamsFromSource is a ConfigMap or a Secret, exactly one of them, whose
+// keys are used as parameters.
+type ParamsFromSource struct {
+	// Prefix is prepended to each key to make up the parameter name.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// +optional
+	ConfigMapRef *corev1.ConfigMapEnvSource `json:"configMapRef,omitempty"`
+	// +optional
+	SecretRef *corev1.SecretEnvSource `json:"secretRef,omitempty"`
+}
+
 // PipelineRunSpecStatus defines the pipelinerun spec status the user can provide
 type PipelineRunSpecStatus string
 
@@ -119,6 +154,53 @@ type PipelineRunStatus struct {
 	// map of PipelineRunTaskRunStatus with the taskRun name as the key
 	// +optional
 	TaskRuns map[string]*PipelineRunTaskRunStatus `json:"taskRuns,omitempty"`
+
+	// PipelineSpec is the spec of the Pipeline resolved when the PipelineRun
+	// started, which it keeps running even if the Pipeline is edited since.
+	// +optional
+	PipelineSpec *PipelineSpec `json:"pipelineSpec,omitempty"`
+
+	// Stages is the status of each stage the PipelineTasks are grouped in,
+	// in the order the stages first appear in the Pipeline.
+	// +optional
+	Stages []PipelineRunStageStatus `json:"stages,omitempty"`
+
+	// StageSummary sums up the status of the stages on one line, e.g.
+	// "build: Succeeded, test: Running 3/5".
+	// +optional
+	StageSummary string `json:"stageSummary,omitempty"`
+
+	// RerunOf is the name of the PipelineRun this one re-runs, from its
+	// pipeline.tekton.dev/re-run-of annotation.
+	// +optional
+	RerunOf string `json:"rerunOf,omitempty"`
+}
+
+// PipelineRunStageStatus is the aggregate status of the PipelineTasks of a
+// stage.
+type PipelineRunStageStatus struct {
+	// Name is the name of the stage.
+	Name string `json:"name"`
+	// Reason is Pending until a PipelineTask of the stage starts, then
+	// Running, and Succeeded once they have all succeeded or been skipped,
+	// or Failed as soon as one of them has failed.
+	Reason string `json:"reason"`
+	// Tasks is the number of PipelineTasks in the stage.
+	Tasks int `json:"tasks"`
+	// Completed is the number of PipelineTasks of the stage which have
+	// succeeded or been skipped.
+	Completed int `json:"completed"`
+	// Failed is the number of PipelineTasks of the stage which have failed.
+	// +optional
+	Failed int `json:"failed,omitempty"`
+}
+
+// String sums up the status of the stage, e.g. "test: Running 3/5".
+func (s PipelineRunStageStatus) String() string {
+	if s.Reason == "Running" {
+		return fmt.Sprintf("%s: %s %d/%d", s.Name, s.Reason, s.Completed, s.Tasks)
+	}
+	return fmt.Sprintf("%s: %s", s.Name, s.Reason)
 }
 
 // PipelineRunTaskRunStatus contains the name of the PipelineTask for this TaskRun and the TaskRun's Status
@@ -240,11 +322,41 @@ func (pr *PipelineRun) GetOwnerReference() []metav1.OwnerReference {
 	}
 }
 
+// GetUntypedSpec returns the spec of the PipelineRun, for the webhook to
+// record who created and last modified it.
+func (pr *PipelineRun) GetUntypedSpec() interface{} {
+	return pr.Spec
+}
+
 // IsDone returns true if the PipelineRun's status indicates that it is done.
 func (pr *PipelineRun) IsDone() bool {
 	return !pr.Status.GetCondition(apis.ConditionSucceeded).IsUnknown()
 }
 
+// HasCompleted returns whether the PipelineRun finished, whatever its outcome.
+func (pr *PipelineRun) HasCompleted() bool {
+	return pr.IsDone()
+}
+
+// FinishTime returns the time at which the PipelineRun finished, or nil if it
+// can't be told.
+func (pr *PipelineRun) FinishTime() *metav1.Time {
+	if pr.Status.CompletionTime != nil {
+		return pr.Status.CompletionTime
+	}
+	if c := pr.Status.GetCondition(apis.ConditionSucceeded); c != nil && !c.LastTransitionTime.Inner.IsZero() {
+		t := c.LastTransitionTime.Inner
+		return &t
+	}
+	return nil
+}
+
+// TTL returns the time the finished PipelineRun is kept for, or nil if it
+// is never deleted.
+func (pr *PipelineRun) TTL() *metav1.Duration {
+	return pr.Spec.ExpirationSecondsTTL
+}
+
 // HasStarted function check whether pipelinerun has valid start time set in its status
 func (pr *PipelineRun) HasStarted() bool {
 	return pr.Status.StartTime != nil && !pr.Status.StartTime.IsZero()
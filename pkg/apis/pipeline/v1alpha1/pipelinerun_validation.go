@@ -26,9 +26,21 @@ import (
 
 // Validate pipelinerun
 func (pr *PipelineRun) Validate(ctx context.Context) *apis.FieldError {
+	return noteValidationErrors(ctx, pr.validate(ctx))
+}
+
+func (pr *PipelineRun) validate(ctx context.Context) *apis.FieldError {
 	if err := validateObjectMetadata(pr.GetObjectMeta()).ViaField("metadata"); err != nil {
 		return err
 	}
+	if old, ok := apis.GetBaseline(ctx).(*PipelineRun); ok && apis.IsInUpdate(ctx) {
+		if err := validateCreator(old.ObjectMeta, pr.ObjectMeta, old.GetUntypedSpec(), pr.GetUntypedSpec()); err != nil {
+			return err
+		}
+	}
+	if err := pr.validateRerunOf(ctx); err != nil {
+		return err
+	}
 	return pr.Spec.Validate(ctx)
 }
 
@@ -55,12 +67,54 @@ func (ps *PipelineRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
-	if ps.Timeout != nil {
-		// timeout should be a valid duration of at least 0.
-		if ps.Timeout.Duration < 0 {
-			return apis.ErrInvalidValue(fmt.Sprintf("%s should be >= 0", ps.Timeout.Duration.String()), "spec.timeout")
+	if err := timeoutConstraint.validate(ps.Timeout); err != nil {
+		return err
+	}
+	if err := expirationSecondsTTLConstraint.validate(ps.ExpirationSecondsTTL); err != nil {
+		return err
+	}
+	if err := taskRunTTLConstraint.validate(ps.TaskRunTTL); err != nil {
+		return err
+	}
+
+	if ps.ExecutionWindow != nil {
+		if err := ps.ExecutionWindow.Validate(ctx).ViaField("spec.executionWindow"); err != nil {
+			return err
 		}
 	}
 
+	if err := validateFiles(ps.Files).ViaField("spec.files"); err != nil {
+		return err
+	}
+
+	for i, pf := range ps.ParamsFrom {
+		if err := pf.Validate(fmt.Sprintf("spec.paramsFrom[%d]", i)); err != nil {
+			return err
+		}
+	}
+
+	if err := ps.PodTemplate.Validate("spec.podTemplate"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate checks that exactly one of the ConfigMap and the Secret is named.
+func (pf ParamsFromSource) Validate(path string) *apis.FieldError {
+	switch {
+	case pf.ConfigMapRef != nil && pf.SecretRef != nil:
+		return apis.ErrMultipleOneOf(path+".configMapRef", path+".secretRef")
+	case pf.ConfigMapRef != nil:
+		if pf.ConfigMapRef.Name == "" {
+			return apis.ErrMissingField(path + ".configMapRef.name")
+		}
+	case pf.SecretRef != nil:
+		if pf.SecretRef.Name == "" {
+			return apis.ErrMissingField(path + ".secretRef.name")
+		}
+	default:
+		return apis.ErrMissingOneOf(path+".configMapRef", path+".secretRef")
+	}
 	return nil
 }
@@ -78,6 +78,48 @@ func TestPipelineRun_Invalidate(t *testing.T) {
 				},
 			},
 			want: apis.ErrInvalidValue("-48h0m0s should be >= 0", "spec.timeout"),
+		}, {
+			name: "negative expiration ttl",
+			pr: v1alpha1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1alpha1.PipelineRunSpec{
+					PipelineRef: v1alpha1.PipelineRef{
+						Name: "prname",
+					},
+					ExpirationSecondsTTL: &metav1.Duration{Duration: -time.Hour},
+				},
+			},
+			want: apis.ErrInvalidValue("-1h0m0s should be >= 0", "spec.expirationSecondsTTL"),
+		}, {
+			name: "negative taskrun ttl",
+			pr: v1alpha1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1alpha1.PipelineRunSpec{
+					PipelineRef: v1alpha1.PipelineRef{
+						Name: "prname",
+					},
+					TaskRunTTL: &metav1.Duration{Duration: -time.Hour},
+				},
+			},
+			want: apis.ErrInvalidValue("-1h0m0s should be >= 0", "spec.taskRunTTL"),
+		}, {
+			name: "invalid execution window schedule",
+			pr: v1alpha1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1alpha1.PipelineRunSpec{
+					PipelineRef: v1alpha1.PipelineRef{
+						Name: "prname",
+					},
+					ExecutionWindow: &v1alpha1.ExecutionWindow{Schedules: []string{"* 9-17 * *"}},
+				},
+			},
+			want: apis.ErrInvalidValue(`cron expression "* 9-17 * *" should have 5 fields, not 4`, "spec.executionWindow.schedules[0]"),
 		},
 	}
 
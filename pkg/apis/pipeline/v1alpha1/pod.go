@@ -17,7 +17,13 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"knative.dev/pkg/apis"
 )
 
 // PodTemplate holds pod specific configuration
@@ -55,4 +61,151 @@ type PodTemplate struct {
 	// This is a beta feature as of Kubernetes v1.14.
 	// +optional
 	RuntimeClassName *string `json:"runtimeClassName,omitempty" protobuf:"bytes,2,opt,name=runtimeClassName"`
+
+	// ServiceAccountToken projects short-lived tokens of the service account
+	// into the steps, instead of mounting its long-lived token secret.
+	// +optional
+	ServiceAccountToken *ServiceAccountTokenProjection `json:"serviceAccountToken,omitempty"`
+
+	// BuildProfile configures the pod for building container images in the
+	// steps without a Docker daemon or privileged containers.
+	// +optional
+	BuildProfile *BuildProfile `json:"buildProfile,omitempty"`
+}
+
+// BuildProfileMode is the kind of image building a BuildProfile sets the pod up for.
+type BuildProfileMode string
+
+// BuildProfileRootless sets the pod up for rootless BuildKit or Kaniko.
+const BuildProfileRootless BuildProfileMode = "rootless"
+
+// BuildProfile configures the pod of a run for building container images.
+type BuildProfile struct {
+	// Mode is the kind of image building to set the pod up for. The only
+	// supported mode is "rootless".
+	Mode BuildProfileMode `json:"mode"`
+	// RegistryConfigSecret is the name of a kubernetes.io/dockerconfigjson
+	// Secret used as the Docker config of the steps, to authenticate to the
+	// registries images are pulled from and pushed to.
+	// +optional
+	RegistryConfigSecret string `json:"registryConfigSecret,omitempty"`
+	// CacheSizeLimit limits the size of the build cache volume shared by the
+	// steps.
+	// +optional
+	CacheSizeLimit *resource.Quantity `json:"cacheSizeLimit,omitempty"`
+}
+
+// setDefaultGroups sets the fsGroup and the supplementalGroups of the
+// security context of the pod template, unless it specifies them.
+func (pt *PodTemplate) setDefaultGroups(fsGroup *int64, supplementalGroups []int64) {
+	if fsGroup == nil && len(supplementalGroups) == 0 {
+		return
+	}
+	if pt.SecurityContext == nil {
+		pt.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	if pt.SecurityContext.FSGroup == nil && fsGroup != nil {
+		group := *fsGroup
+		pt.SecurityContext.FSGroup = &group
+	}
+	if len(pt.SecurityContext.SupplementalGroups) == 0 && len(supplementalGroups) > 0 {
+		pt.SecurityContext.SupplementalGroups = append([]int64{}, supplementalGroups...)
+	}
+}
+
+// osNodeLabels are the labels of the nodes with their operating system.
+var osNodeLabels = []string{"kubernetes.io/os", "beta.kubernetes.io/os"}
+
+// IsWindows returns whether the nodeSelector of the pod template schedules
+// the pods on Windows nodes.
+func (pt PodTemplate) IsWindows() bool {
+	for _, l := range osNodeLabels {
+		if pt.NodeSelector[l] == "windows" {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultServiceAccountTokenMountPath is the directory the projected service
+// account tokens are mounted at when no MountPath is specified.
+const DefaultServiceAccountTokenMountPath = "/var/run/secrets/tekton.dev/serviceaccount"
+
+// ServiceAccountTokenProjection configures the service account tokens
+// projected into the steps of a run.
+type ServiceAccountTokenProjection struct {
+	// MountPath is the directory the tokens are mounted at in every step.
+	// Defaults to /var/run/secrets/tekton.dev/serviceaccount.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+	// Tokens are the tokens to project, e.g. one per audience.
+	Tokens []ProjectedServiceAccountToken `json:"tokens"`
+}
+
+// ProjectedServiceAccountToken is a token of the service account projected
+// into a file of the steps. The kubelet refreshes the token before it expires.
+type ProjectedServiceAccountToken struct {
+	// Path is the path of the token file, relative to the MountPath.
+	Path string `json:"path"`
+	// Audience is the intended audience of the token. Defaults to the
+	// identifier of the API server.
+	// +optional
+	Audience string `json:"audience,omitempty"`
+	// ExpirationSeconds is the requested validity of the token, which must
+	// be at least 10 minutes. Defaults to 1 hour.
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
+// minTokenExpirationSeconds is the shortest validity the API server accepts
+// for projected service account tokens.
+const minTokenExpirationSeconds = 600
+
+// Validate checks the service account token projection and the build
+// profile of the pod template.
+func (pt PodTemplate) Validate(path string) *apis.FieldError {
+	if err := validateServiceAccountToken(pt.ServiceAccountToken, path+".serviceAccountToken"); err != nil {
+		return err
+	}
+	return validateBuildProfile(pt.BuildProfile, path+".buildProfile")
+}
+
+func validateServiceAccountToken(sat *ServiceAccountTokenProjection, path string) *apis.FieldError {
+	if sat == nil {
+		return nil
+	}
+	if sat.MountPath != "" && !filepath.IsAbs(sat.MountPath) {
+		return apis.ErrInvalidValue(sat.MountPath, path+".mountPath")
+	}
+	if len(sat.Tokens) == 0 {
+		return apis.ErrMissingField(path + ".tokens")
+	}
+	paths := map[string]struct{}{}
+	for _, t := range sat.Tokens {
+		clean := filepath.Clean(t.Path)
+		if t.Path == "" || filepath.IsAbs(t.Path) || clean == "." || strings.HasPrefix(clean, "..") {
+			return apis.ErrInvalidValue(t.Path, path+".tokens.path")
+		}
+		if _, ok := paths[clean]; ok {
+			return apis.ErrInvalidValue(t.Path, path+".tokens.path")
+		}
+		paths[clean] = struct{}{}
+		if t.ExpirationSeconds != nil && *t.ExpirationSeconds < minTokenExpirationSeconds {
+			return apis.ErrInvalidValue(fmt.Sprintf("%d should be >= %d", *t.ExpirationSeconds, minTokenExpirationSeconds), path+".tokens.expirationSeconds")
+		}
+	}
+	return nil
+}
+
+func validateBuildProfile(bp *BuildProfile, path string) *apis.FieldError {
+	if bp == nil {
+		return nil
+	}
+	if bp.Mode != BuildProfileRootless {
+		return apis.ErrInvalidValue(string(bp.Mode), path+".mode")
+	}
+	if bp.CacheSizeLimit != nil && bp.CacheSizeLimit.Sign() <= 0 {
+		return apis.ErrInvalidValue(bp.CacheSizeLimit.String(), path+".cacheSizeLimit")
+	}
+	return nil
 }
@@ -50,10 +50,20 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&TaskList{},
 		&Condition{},
 		&ConditionList{},
+		&StepAction{},
+		&StepActionList{},
+		&CleanupPolicy{},
+		&CleanupPolicyList{},
+		&ImagePrefetch{},
+		&ImagePrefetchList{},
+		&PipelineQuota{},
+		&PipelineQuotaList{},
 		&ClusterTask{},
 		&ClusterTaskList{},
 		&TaskRun{},
 		&TaskRunList{},
+		&TaskRunArchive{},
+		&TaskRunArchiveList{},
 		&Pipeline{},
 		&PipelineList{},
 		&PipelineRun{},
@@ -52,6 +52,19 @@ const (
 // AllResourceTypes can be used for validation to check if a provided Resource type is one of the known types.
 var AllResourceTypes = []PipelineResourceType{PipelineResourceTypeGit, PipelineResourceTypeStorage, PipelineResourceTypeImage, PipelineResourceTypeCluster, PipelineResourceTypePullRequest, PipelineResourceTypeCloudEvent}
 
+// resourceAttributes are the attributes of the resources of each type which
+// the $(inputs.resources.<name>.<attribute>) and
+// $(outputs.resources.<name>.<attribute>) variables can reference: the keys
+// of their Replacements, and their path.
+var resourceAttributes = map[PipelineResourceType][]string{
+	PipelineResourceTypeGit:         {"name", "type", "url", "revision", "path"},
+	PipelineResourceTypeStorage:     {"name", "type", "location", "path"},
+	PipelineResourceTypeImage:       {"name", "type", "url", "digest", "path"},
+	PipelineResourceTypeCluster:     {"name", "type", "url", "revision", "username", "password", "namespace", "token", "insecure", "cadata", "path"},
+	PipelineResourceTypePullRequest: {"name", "type", "url", "path"},
+	PipelineResourceTypeCloudEvent:  {"name", "type", "target-uri", "path"},
+}
+
 // PipelineResourceInterface interface to be implemented by different PipelineResource types
 type PipelineResourceInterface interface {
 	// GetName returns the name of this PipelineResource instance.
@@ -179,8 +192,18 @@ type PipelineResourceResult struct {
 	Key         string              `json:"key"`
 	Value       string              `json:"value"`
 	ResourceRef PipelineResourceRef `json:"resourceRef,omitempty"`
+	// ResultType tells the results of the Task apart from the results of
+	// its resources, which have no type.
+	ResultType ResultType `json:"type,omitempty"`
 }
 
+// ResultType is the type of a result written to the termination message of
+// a step.
+type ResultType string
+
+// TaskRunResultType is the type of the results of the Task of a TaskRun.
+const TaskRunResultType ResultType = "TaskRunResult"
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // PipelineResourceList contains a list of PipelineResources
@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RunIdentity is the fully-qualified identity of an attempt of a TaskRun or
+// a PipelineRun. The labels of its pods, its CloudEvents and its metrics
+// are derived from it, so that the systems consuming them can join them.
+type RunIdentity struct {
+	// Cluster is the name of the cluster the run runs on, the cluster-name
+	// setting of config-defaults, if any.
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace"`
+	// Pipeline and PipelineRun are the names of the Pipeline and of the
+	// PipelineRun the run is part of, if any.
+	Pipeline    string `json:"pipeline,omitempty"`
+	PipelineRun string `json:"pipelineRun,omitempty"`
+	// Run is the name of the run, and UID its UID, which unlike its name
+	// isn't reused once it's deleted.
+	Run string    `json:"run"`
+	UID types.UID `json:"uid"`
+	// Attempt is the number of times the run was retried.
+	Attempt int `json:"attempt"`
+}
+
+// RunIdentity returns the identity of the current attempt of tr on cluster.
+func (tr *TaskRun) RunIdentity(cluster string) RunIdentity {
+	_, p, pr := tr.IsPartOfPipeline()
+	return RunIdentity{
+		Cluster:     cluster,
+		Namespace:   tr.Namespace,
+		Pipeline:    p,
+		PipelineRun: pr,
+		Run:         tr.Name,
+		UID:         tr.UID,
+		Attempt:     len(tr.Status.RetriesStatus),
+	}
+}
+
+// RunIdentity returns the identity of pr on cluster.
+func (pr *PipelineRun) RunIdentity(cluster string) RunIdentity {
+	p := pr.Labels[pipeline.GroupName+pipeline.PipelineLabelKey]
+	if p == "" {
+		p = pr.Spec.PipelineRef.Name
+	}
+	return RunIdentity{
+		Cluster:     cluster,
+		Namespace:   pr.Namespace,
+		Pipeline:    p,
+		PipelineRun: pr.Name,
+		Run:         pr.Name,
+		UID:         pr.UID,
+	}
+}
+
+// String returns the canonical form of id,
+// <cluster>/<namespace>/<pipeline>/<run UID>/<attempt>, where the missing
+// cluster and pipeline are "-".
+func (id RunIdentity) String() string {
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	return strings.Join([]string{
+		orDash(id.Cluster),
+		id.Namespace,
+		orDash(id.Pipeline),
+		string(id.UID),
+		strconv.Itoa(id.Attempt),
+	}, "/")
+}
+
+// Labels returns the labels identifying id, which the name labels, e.g.
+// tekton.dev/taskRun, complement. They are only returned for the runs the
+// API server assigned a UID to.
+func (id RunIdentity) Labels() map[string]string {
+	if id.UID == "" {
+		return nil
+	}
+	labels := map[string]string{
+		pipeline.GroupName + pipeline.RunUIDLabelKey:     string(id.UID),
+		pipeline.GroupName + pipeline.RunAttemptLabelKey: strconv.Itoa(id.Attempt),
+	}
+	if id.Cluster != "" {
+		labels[pipeline.GroupName+pipeline.ClusterLabelKey] = id.Cluster
+	}
+	return labels
+}
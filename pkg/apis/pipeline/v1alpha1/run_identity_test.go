@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+)
+
+func TestTaskRun_RunIdentity(t *testing.T) {
+	tr := tb.TaskRun("nightly-build", "ci",
+		tb.TaskRunLabel("tekton.dev/pipeline", "release"),
+		tb.TaskRunLabel("tekton.dev/pipelineRun", "nightly"),
+		tb.TaskRunStatus(tb.Retry(v1alpha1.TaskRunStatus{}), tb.Retry(v1alpha1.TaskRunStatus{})),
+	)
+	tr.UID = "5f3c1a2e"
+
+	id := tr.RunIdentity("ci-east")
+	want := v1alpha1.RunIdentity{
+		Cluster:     "ci-east",
+		Namespace:   "ci",
+		Pipeline:    "release",
+		PipelineRun: "nightly",
+		Run:         "nightly-build",
+		UID:         "5f3c1a2e",
+		Attempt:     2,
+	}
+	if d := cmp.Diff(want, id); d != "" {
+		t.Errorf("RunIdentity() -want, +got: %s", d)
+	}
+	if got, want := id.String(), "ci-east/ci/release/5f3c1a2e/2"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	wantLabels := map[string]string{
+		"tekton.dev/cluster":    "ci-east",
+		"tekton.dev/runUID":     "5f3c1a2e",
+		"tekton.dev/runAttempt": "2",
+	}
+	if d := cmp.Diff(wantLabels, id.Labels()); d != "" {
+		t.Errorf("Labels() -want, +got: %s", d)
+	}
+}
+
+func TestTaskRun_RunIdentity_Standalone(t *testing.T) {
+	tr := tb.TaskRun("build", "ci")
+	id := tr.RunIdentity("")
+	if got, want := id.String(), "-/ci/-//0"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if labels := id.Labels(); labels != nil {
+		t.Errorf("Labels() = %v without UID, want none", labels)
+	}
+
+	tr.UID = "9b0d"
+	wantLabels := map[string]string{
+		"tekton.dev/runUID":     "9b0d",
+		"tekton.dev/runAttempt": "0",
+	}
+	if d := cmp.Diff(wantLabels, tr.RunIdentity("").Labels()); d != "" {
+		t.Errorf("Labels() -want, +got: %s", d)
+	}
+}
+
+func TestPipelineRun_RunIdentity(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		pr   *v1alpha1.PipelineRun
+		want string
+	}{{
+		name: "resolved pipeline",
+		pr: tb.PipelineRun("nightly", "ci", tb.PipelineRunSpec("release-ref"),
+			tb.PipelineRunLabel("tekton.dev/pipeline", "release")),
+		want: "ci-east/ci/release/7e2a/0",
+	}, {
+		name: "unresolved pipeline",
+		pr:   tb.PipelineRun("nightly", "ci", tb.PipelineRunSpec("release")),
+		want: "ci-east/ci/release/7e2a/0",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.pr.UID = "7e2a"
+			id := tc.pr.RunIdentity("ci-east")
+			if got := id.String(); got != tc.want {
+				t.Errorf("String() = %q, want %q", got, tc.want)
+			}
+			if id.Run != "nightly" || id.PipelineRun != "nightly" {
+				t.Errorf("RunIdentity() = %+v, want the run and the PipelineRun nightly", id)
+			}
+		})
+	}
+}
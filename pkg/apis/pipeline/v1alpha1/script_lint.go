@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/shlint"
+	"knative.dev/pkg/apis"
+)
+
+// ScriptLinter checks the scripts of steps run by an interpreter.
+type ScriptLinter interface {
+	// Lint returns an error describing why the script is broken, if it is.
+	Lint(script string) error
+}
+
+// ScriptLinterFunc is a ScriptLinter which is a func.
+type ScriptLinterFunc func(script string) error
+
+// Lint calls f.
+func (f ScriptLinterFunc) Lint(script string) error {
+	return f(script)
+}
+
+// scriptLinters are the linters of the scripts, by the base name of their
+// interpreter.
+var scriptLinters = map[string]ScriptLinter{
+	"sh":   ScriptLinterFunc(shlint.Check),
+	"ash":  ScriptLinterFunc(shlint.Check),
+	"dash": ScriptLinterFunc(shlint.Check),
+}
+
+// RegisterScriptLinter makes the webhook check the scripts of steps run by
+// interpreter, e.g. "bash" or "python", with l when the lint-scripts
+// setting of config-defaults is enabled. It replaces the linter of
+// interpreter, if any, and isn't safe to call once the webhook started.
+func RegisterScriptLinter(interpreter string, l ScriptLinter) {
+	scriptLinters[interpreter] = l
+}
+
+// scriptInterpreter returns the base name of the interpreter the shebang
+// of script runs it with, e.g. "sh" for "#!/bin/sh -e" or
+// "#!/usr/bin/env sh".
+func scriptInterpreter(script string) string {
+	script = strings.TrimSpace(script)
+	if !strings.HasPrefix(script, "#!") {
+		return ""
+	}
+	shebang := strings.SplitN(script[2:], "\n", 2)[0]
+	fields := strings.Fields(shebang)
+	if len(fields) == 0 {
+		return ""
+	}
+	if filepath.Base(fields[0]) == "env" {
+		fields = fields[1:]
+		// The options of env, e.g. -S, precede the interpreter.
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			return ""
+		}
+	}
+	return filepath.Base(fields[0])
+}
+
+// lintScript checks script with the linter of its interpreter, if the
+// lint-scripts setting is enabled and there is one.
+func lintScript(ctx context.Context, script, path string) *apis.FieldError {
+	if script == "" || !config.FromContextOrDefaults(ctx).Defaults.LintScripts {
+		return nil
+	}
+	interpreter := scriptInterpreter(script)
+	l, ok := scriptLinters[interpreter]
+	if !ok {
+		return nil
+	}
+	if err := l.Lint(script); err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("invalid %s script: %v", interpreter, err),
+			Paths:   []string{path},
+		}
+	}
+	return nil
+}
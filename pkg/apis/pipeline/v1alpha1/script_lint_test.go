@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+func lintScriptsContext(enabled bool) context.Context {
+	return config.ToContext(context.Background(), &config.Config{
+		Defaults: &config.Defaults{LintScripts: enabled},
+	})
+}
+
+func scriptTaskSpec(script string) *v1alpha1.TaskSpec {
+	return &v1alpha1.TaskSpec{
+		Steps: []v1alpha1.Step{{
+			Container: corev1.Container{Image: "my-image"},
+			Script:    script,
+		}},
+	}
+}
+
+func TestTaskSpecValidate_LintScripts(t *testing.T) {
+	v1alpha1.RegisterScriptLinter("tekton-test-lang", v1alpha1.ScriptLinterFunc(func(script string) error {
+		if strings.Contains(script, "boom") {
+			return errors.New("boom isn't allowed")
+		}
+		return nil
+	}))
+
+	for _, tc := range []struct {
+		name          string
+		enabled       bool
+		script        string
+		expectedError *apis.FieldError
+	}{{
+		name:    "valid sh script",
+		enabled: true,
+		script:  "#!/bin/sh\nif [ -f $(inputs.params.file) ]; then\n  cat $(inputs.params.file)\nfi\n",
+	}, {
+		name:    "broken sh script",
+		enabled: true,
+		script:  "#!/bin/sh -e\necho start\nif true; then\n  echo 'unclosed\nfi\n",
+		expectedError: &apis.FieldError{
+			Message: "invalid sh script: line 4: single quote isn't closed",
+			Paths:   []string{"steps[0].script"},
+		},
+	}, {
+		name:    "broken env sh script",
+		enabled: true,
+		script:  "#!/usr/bin/env dash\nfor i in a b; do\n  echo $i\n",
+		expectedError: &apis.FieldError{
+			Message: "invalid dash script: line 2: \"for\" isn't closed with \"done\"",
+			Paths:   []string{"steps[0].script"},
+		},
+	}, {
+		name:   "broken sh script not linted",
+		script: "#!/bin/sh\nfi\n",
+	}, {
+		name:    "interpreter without linter",
+		enabled: true,
+		script:  "#!/usr/bin/env python\nprint('unclosed\n",
+	}, {
+		name:    "registered linter",
+		enabled: true,
+		script:  "#!/usr/bin/tekton-test-lang\nboom\n",
+		expectedError: &apis.FieldError{
+			Message: "invalid tekton-test-lang script: boom isn't allowed",
+			Paths:   []string{"steps[0].script"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			withStepTemplate := scriptTaskSpec(tc.script)
+			withStepTemplate.StepTemplate = &corev1.Container{WorkingDir: "/workspace/src"}
+			for _, ts := range []*v1alpha1.TaskSpec{scriptTaskSpec(tc.script), withStepTemplate} {
+				err := ts.Validate(lintScriptsContext(tc.enabled))
+				if tc.expectedError == nil {
+					if err != nil {
+						t.Errorf("TaskSpec.Validate() = %v", err)
+					}
+					continue
+				}
+				if err == nil {
+					t.Fatalf("Expected an error, got nothing for %v", tc.script)
+				}
+				if d := cmp.Diff(tc.expectedError.Error(), err.Error()); d != "" {
+					t.Errorf("TaskSpec.Validate() errors diff -want, +got: %v", d)
+				}
+			}
+		})
+	}
+}
+
+func TestStepActionSpecValidate_LintScripts(t *testing.T) {
+	sa := tb.StepAction("hello", "foo", tb.StepActionSpec("ubuntu",
+		tb.StepActionScript("#!/bin/sh\necho \"hello\n"),
+	))
+	if err := sa.Validate(lintScriptsContext(false)); err != nil {
+		t.Errorf("StepAction.Validate() = %v", err)
+	}
+	expectedError := &apis.FieldError{
+		Message: "invalid sh script: line 2: double quote isn't closed",
+		Paths:   []string{"spec.script"},
+	}
+	err := sa.Validate(lintScriptsContext(true))
+	if err == nil {
+		t.Fatal("Expected an error, got nothing")
+	}
+	if d := cmp.Diff(expectedError.Error(), err.Error()); d != "" {
+		t.Errorf("StepAction.Validate() errors diff -want, +got: %v", d)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+// Check that StepAction may be validated and defaulted.
+var _ apis.Validatable = (*StepAction)(nil)
+var _ apis.Defaultable = (*StepAction)(nil)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StepAction is a single reusable step, which the steps of Tasks can
+// reference instead of repeating its definition.
+// +k8s:openapi-gen=true
+type StepAction struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec holds the desired state of the StepAction from the client
+	// +optional
+	Spec StepActionSpec `json:"spec"`
+}
+
+// StepActionSpec defines the desired state of the StepAction
+type StepActionSpec struct {
+	// Image is the container image the step runs.
+	Image string `json:"image"`
+
+	// Command is the entrypoint of the step.
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Args are the arguments of the entrypoint of the step.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// Env are the environment variables set in the step.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Script is the contents of an executable file to execute.
+	//
+	// If Script is not empty, the StepAction cannot have an Command or Args.
+	// +optional
+	Script string `json:"script,omitempty"`
+
+	// Params are the parameters the steps referencing the StepAction bind,
+	// used as $(params.name) in the other fields.
+	// +optional
+	Params []ParamSpec `json:"params,omitempty"`
+}
+
+// StepActionRef references a StepAction in the namespace of the TaskRun.
+type StepActionRef struct {
+	// Name of the referenced StepAction.
+	Name string `json:"name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StepActionList contains a list of StepActions
+type StepActionList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StepAction `json:"items"`
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"knative.dev/pkg/apis"
+)
+
+func (sa *StepAction) Validate(ctx context.Context) *apis.FieldError {
+	return noteValidationErrors(ctx, sa.validate(ctx))
+}
+
+func (sa *StepAction) validate(ctx context.Context) *apis.FieldError {
+	if err := validateObjectMetadata(sa.GetObjectMeta()); err != nil {
+		return err.ViaField("metadata")
+	}
+	return sa.Spec.Validate(ctx)
+}
+
+func (ss *StepActionSpec) Validate(ctx context.Context) *apis.FieldError {
+	if ss.Image == "" {
+		return apis.ErrMissingField("spec.image")
+	}
+	if err := validateImage(ctx, ss.Image); err != nil {
+		return err.ViaField("spec")
+	}
+	if ss.Script != "" {
+		if len(ss.Args) > 0 || len(ss.Command) > 0 {
+			return &apis.FieldError{
+				Message: "script cannot be used with args or command",
+				Paths:   []string{"spec.script"},
+			}
+		}
+		if !strings.HasPrefix(strings.TrimSpace(ss.Script), "#!") {
+			return &apis.FieldError{
+				Message: "script must start with a shebang (#!)",
+				Paths:   []string{"spec.script"},
+			}
+		}
+		if err := lintScript(ctx, ss.Script, "spec.script"); err != nil {
+			return err
+		}
+	}
+
+	names := map[string]struct{}{}
+	arrayNames := map[string]struct{}{}
+	for _, p := range ss.Params {
+		if _, ok := names[p.Name]; ok {
+			return apis.ErrInvalidValue(p.Name, "spec.params.name")
+		}
+		names[p.Name] = struct{}{}
+		if p.Type != ParamTypeString && p.Type != ParamTypeArray {
+			return apis.ErrInvalidValue(p.Type, fmt.Sprintf("spec.params.%s.type", p.Name))
+		}
+		if p.Default != nil && p.Default.Type != p.Type {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("\"%v\" type does not match default value's type: \"%v\"", p.Type, p.Default.Type),
+				Paths:   []string{fmt.Sprintf("spec.params.%s.type", p.Name), fmt.Sprintf("spec.params.%s.default.type", p.Name)},
+			}
+		}
+		if p.Type == ParamTypeArray {
+			arrayNames[p.Name] = struct{}{}
+		}
+	}
+
+	// Array params may only be used on their own in the items of command
+	// and args.
+	fields := []struct{ name, value string }{{"image", ss.Image}, {"script", ss.Script}}
+	for _, env := range ss.Env {
+		fields = append(fields, struct{ name, value string }{fmt.Sprintf("env[%s]", env.Name), env.Value})
+	}
+	for _, f := range fields {
+		if err := validateStepActionVariable(f.name, f.value, names); err != nil {
+			return err
+		}
+		if err := ValidateVariableProhibited(f.name, f.value, "params", "", "stepaction", "spec", arrayNames); err != nil {
+			return err
+		}
+	}
+	for i, cmd := range ss.Command {
+		if err := validateStepActionArrayItem(fmt.Sprintf("command[%d]", i), cmd, names, arrayNames); err != nil {
+			return err
+		}
+	}
+	for i, arg := range ss.Args {
+		if err := validateStepActionArrayItem(fmt.Sprintf("args[%d]", i), arg, names, arrayNames); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateStepActionArrayItem(name, value string, names, arrayNames map[string]struct{}) *apis.FieldError {
+	if err := validateStepActionVariable(name, value, names); err != nil {
+		return err
+	}
+	return ValidateVariableIsolated(name, value, "params", "", "stepaction", "spec", arrayNames)
+}
+
+func validateStepActionVariable(name, value string, vars map[string]struct{}) *apis.FieldError {
+	return ValidateVariable(name, value, "params", "", "stepaction", "spec", vars)
+}
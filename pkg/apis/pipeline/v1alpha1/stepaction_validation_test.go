@@ -0,0 +1,178 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/pkg/apis"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+)
+
+func TestStepAction_Validate(t *testing.T) {
+	for _, sa := range []*v1alpha1.StepAction{
+		tb.StepAction("go-build", "foo", tb.StepActionSpec("golang:$(params.version)",
+			tb.StepActionCommand("go", "build"),
+			tb.StepActionArgs("$(params.flags)", "$(params.package)"),
+			tb.StepActionParamSpec("version", v1alpha1.ParamTypeString, tb.ParamSpecDefault("1.13")),
+			tb.StepActionParamSpec("flags", v1alpha1.ParamTypeArray, tb.ParamSpecDefault("-v", "-x")),
+			tb.StepActionParamSpec("package", v1alpha1.ParamTypeString),
+		)),
+		tb.StepAction("hello", "foo", tb.StepActionSpec("ubuntu",
+			tb.StepActionScript("#!/bin/sh\necho hello $(params.who)"),
+			tb.StepActionParamSpec("who", v1alpha1.ParamTypeString),
+		)),
+	} {
+		t.Run(sa.Name, func(t *testing.T) {
+			if err := sa.Validate(context.Background()); err != nil {
+				t.Errorf("StepAction.Validate() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestStepAction_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		sa            *v1alpha1.StepAction
+		expectedError apis.FieldError
+	}{{
+		name: "invalid name",
+		sa:   tb.StepAction("invalid.name", "foo", tb.StepActionSpec("ubuntu")),
+		expectedError: apis.FieldError{
+			Message: `Invalid resource name: special character . must not be present`,
+			Paths:   []string{"metadata.name"},
+		},
+	}, {
+		name: "no image",
+		sa:   tb.StepAction("hello", "foo", tb.StepActionSpec("")),
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.image"},
+		},
+	}, {
+		name: "script with args",
+		sa:   tb.StepAction("hello", "foo", tb.StepActionSpec("ubuntu", tb.StepActionArgs("hello"), tb.StepActionScript("#!/bin/sh\necho"))),
+		expectedError: apis.FieldError{
+			Message: "script cannot be used with args or command",
+			Paths:   []string{"spec.script"},
+		},
+	}, {
+		name: "undeclared param",
+		sa:   tb.StepAction("hello", "foo", tb.StepActionSpec("ubuntu", tb.StepActionArgs("$(params.who)"))),
+		expectedError: apis.FieldError{
+			Message: `non-existent variable "who" in "$(params.who)" for stepaction args[0]`,
+			Paths:   []string{"spec.args[0]"},
+		},
+	}, {
+		name: "array param in image",
+		sa: tb.StepAction("hello", "foo", tb.StepActionSpec("ubuntu:$(params.tags)",
+			tb.StepActionParamSpec("tags", v1alpha1.ParamTypeArray),
+		)),
+		expectedError: apis.FieldError{
+			Message: `variable "tags" type invalid in "ubuntu:$(params.tags)" for stepaction image`,
+			Paths:   []string{"spec.image"},
+		},
+	}, {
+		name: "array param not isolated",
+		sa: tb.StepAction("hello", "foo", tb.StepActionSpec("ubuntu",
+			tb.StepActionArgs("--tags=$(params.tags)"),
+			tb.StepActionParamSpec("tags", v1alpha1.ParamTypeArray),
+		)),
+		expectedError: apis.FieldError{
+			Message: `variable "tags" is not properly isolated in "--tags=$(params.tags)" for stepaction args[0]`,
+			Paths:   []string{"spec.args[0]"},
+		},
+	}, {
+		name: "duplicate param",
+		sa: tb.StepAction("hello", "foo", tb.StepActionSpec("ubuntu",
+			tb.StepActionParamSpec("who", v1alpha1.ParamTypeString),
+			tb.StepActionParamSpec("who", v1alpha1.ParamTypeString),
+		)),
+		expectedError: apis.FieldError{
+			Message: "invalid value: who",
+			Paths:   []string{"spec.params.name"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.sa.Validate(context.Background())
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing for %v", tc.sa)
+			}
+			if d := cmp.Diff(tc.expectedError.Error(), err.Error()); d != "" {
+				t.Errorf("StepAction.Validate() errors diff -want, +got: %v", d)
+			}
+		})
+	}
+}
+
+func TestStepAction_ImagePolicy(t *testing.T) {
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Defaults: &config.Defaults{
+			RequireImageDigests:   true,
+			BannedImageRegistries: []string{"quay.io/untrusted"},
+		},
+	})
+	for _, tc := range []struct {
+		name          string
+		sa            *v1alpha1.StepAction
+		expectedError *apis.FieldError
+	}{{
+		name: "tagged image",
+		sa:   tb.StepAction("hello", "foo", tb.StepActionSpec("gcr.io/build/builder:v1")),
+		expectedError: &apis.FieldError{
+			Message: `image "gcr.io/build/builder:v1" isn't pinned to a digest`,
+			Paths:   []string{"spec.image"},
+			Details: "The images must be referenced by digest, e.g. ubuntu@sha256:<digest>",
+		},
+	}, {
+		name: "banned registry through a variable",
+		sa: tb.StepAction("hello", "foo", tb.StepActionSpec("quay.io/untrusted/$(params.tool)",
+			tb.StepActionParamSpec("tool", v1alpha1.ParamTypeString),
+		)),
+		expectedError: &apis.FieldError{
+			Message: `image "quay.io/untrusted/$(params.tool)" comes from the banned registry "quay.io/untrusted"`,
+			Paths:   []string{"spec.image"},
+		},
+	}, {
+		name: "variable image",
+		sa: tb.StepAction("hello", "foo", tb.StepActionSpec("gcr.io/build/$(params.tool)",
+			tb.StepActionParamSpec("tool", v1alpha1.ParamTypeString),
+		)),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.sa.Validate(ctx)
+			if tc.expectedError == nil {
+				if err != nil {
+					t.Errorf("StepAction.Validate() unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing for %v", tc.sa)
+			}
+			if d := cmp.Diff(tc.expectedError.Error(), err.Error()); d != "" {
+				t.Errorf("StepAction.Validate() errors diff -want, +got: %v", d)
+			}
+		})
+	}
+}
@@ -28,51 +28,177 @@ const parameterSubstitution = "[_a-zA-Z][_a-zA-Z0-9.-]*"
 
 const braceMatchingRegex = "(\\$(\\(%s.(?P<var>%s)\\)))"
 
+// stepVariableRegex matches the $(steps.<name>.<field>) variables, which
+// aren't replaced when the pod of a TaskRun is created but by the entrypoint
+// when the step starts.
+var stepVariableRegex = regexp.MustCompile(`\$\(steps\.([^.()]*)\.([^()]*)\)`)
+
+// stepResultKeyRegex matches the keys of the results of a step, which are
+// the names of the files the step writes them to.
+var stepResultKeyRegex = regexp.MustCompile(`^[_a-zA-Z][-_a-zA-Z0-9]*$`)
+
+const (
+	// StepExitCodeField is the field of the $(steps.<name>.exitCode)
+	// variables.
+	StepExitCodeField = "exitCode"
+	// StepResultsField is the prefix of the field of the
+	// $(steps.<name>.results.<key>) variables.
+	StepResultsField = "results."
+)
+
+// StepVariable is a $(steps.<name>.<field>) variable, referencing the exit
+// code or a result of an earlier step of the same TaskRun.
+type StepVariable struct {
+	// Expression is the whole variable, e.g. $(steps.lint.exitCode).
+	Expression string
+	// Step is the name of the referenced step.
+	Step string
+	// Field is what is referenced, e.g. exitCode or results.digest.
+	Field string
+}
+
+// ResultKey returns the key of the result referenced by v, if it references
+// one.
+func (v StepVariable) ResultKey() (string, bool) {
+	if !strings.HasPrefix(v.Field, StepResultsField) {
+		return "", false
+	}
+	return strings.TrimPrefix(v.Field, StepResultsField), true
+}
+
+// Valid returns whether v references the exit code, or a result with a valid
+// key, of a step.
+func (v StepVariable) Valid() bool {
+	if v.Field == StepExitCodeField {
+		return true
+	}
+	key, ok := v.ResultKey()
+	return ok && stepResultKeyRegex.MatchString(key)
+}
+
+// StepVariables returns the $(steps.<name>.<field>) variables in s.
+func StepVariables(s string) []StepVariable {
+	var vs []StepVariable
+	for _, m := range stepVariableRegex.FindAllStringSubmatch(s, -1) {
+		vs = append(vs, StepVariable{Expression: m[0], Step: m[1], Field: m[2]})
+	}
+	return vs
+}
+
+// Verifies that variables matching the relevant string expressions reference
+// one of the names present in vars. Every variable which doesn't is reported.
 func ValidateVariable(name, value, prefix, contextPrefix, locationName, path string, vars map[string]struct{}) *apis.FieldError {
+	var errs *apis.FieldError
 	if vs, present := extractVariablesFromString(value, contextPrefix+prefix); present {
 		for _, v := range vs {
 			if _, ok := vars[v]; !ok {
-				return &apis.FieldError{
-					Message: fmt.Sprintf("non-existent variable in %q for %s %s", value, locationName, name),
+				errs = errs.Also(&apis.FieldError{
+					Message: fmt.Sprintf("non-existent variable %q in %q for %s %s", v, value, locationName, name),
 					Paths:   []string{path + "." + name},
-				}
+				})
 			}
 		}
 	}
-	return nil
+	return errs
 }
 
 // Verifies that variables matching the relevant string expressions do not reference any of the names present in vars.
+// Every variable which does is reported.
 func ValidateVariableProhibited(name, value, prefix, contextPrefix, locationName, path string, vars map[string]struct{}) *apis.FieldError {
+	var errs *apis.FieldError
 	if vs, present := extractVariablesFromString(value, contextPrefix+prefix); present {
 		for _, v := range vs {
 			if _, ok := vars[v]; ok {
-				return &apis.FieldError{
-					Message: fmt.Sprintf("variable type invalid in %q for %s %s", value, locationName, name),
+				errs = errs.Also(&apis.FieldError{
+					Message: fmt.Sprintf("variable %q type invalid in %q for %s %s", v, value, locationName, name),
 					Paths:   []string{path + "." + name},
-				}
+				})
 			}
 		}
 	}
-	return nil
+	return errs
 }
 
 // Verifies that variables matching the relevant string expressions are completely isolated if present.
+// Every variable which isn't is reported.
 func ValidateVariableIsolated(name, value, prefix, contextPrefix, locationName, path string, vars map[string]struct{}) *apis.FieldError {
+	var errs *apis.FieldError
 	if vs, present := extractVariablesFromString(value, contextPrefix+prefix); present {
 		firstMatch, _ := extractExpressionFromString(value, contextPrefix+prefix)
 		for _, v := range vs {
 			if _, ok := vars[v]; ok {
 				if len(value) != len(firstMatch) {
-					return &apis.FieldError{
-						Message: fmt.Sprintf("variable is not properly isolated in %q for %s %s", value, locationName, name),
+					errs = errs.Also(&apis.FieldError{
+						Message: fmt.Sprintf("variable %q is not properly isolated in %q for %s %s", v, value, locationName, name),
 						Paths:   []string{path + "." + name},
-					}
+					})
 				}
 			}
 		}
 	}
-	return nil
+	return errs
+}
+
+// Verifies that variables matching the relevant string expressions which
+// reference the object params in objects reference one of their declared
+// keys, e.g. $(params.foo.key), since objects can't be substituted in
+// strings themselves. Every variable which doesn't is reported.
+func ValidateObjectKeys(name, value, prefix, contextPrefix, locationName, path string, objects map[string]map[string]struct{}) *apis.FieldError {
+	pattern := fmt.Sprintf(braceMatchingRegex, contextPrefix+prefix, parameterSubstitution)
+	re := regexp.MustCompile(pattern)
+	var errs *apis.FieldError
+	for _, match := range re.FindAllStringSubmatch(value, -1) {
+		parts := strings.SplitN(matchGroups(match, re)["var"], ".", 2)
+		keys, ok := objects[parts[0]]
+		if !ok {
+			continue
+		}
+		if len(parts) == 1 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("object param %q must be referenced by key in %q for %s %s", parts[0], value, locationName, name),
+				Paths:   []string{path + "." + name},
+			})
+			continue
+		}
+		if _, ok := keys[parts[1]]; !ok {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("non-existent key %q of object param %q in %q for %s %s", parts[1], parts[0], value, locationName, name),
+				Paths:   []string{path + "." + name},
+			})
+		}
+	}
+	return errs
+}
+
+// Verifies that variables matching the relevant string expressions reference
+// the resources in resources, which map their names to their attributes, and
+// one of their attributes, e.g. $(inputs.resources.source.revision). Every
+// variable which doesn't is reported.
+func ValidateResourceAttributes(name, value, prefix, contextPrefix, locationName, path string, resources map[string]map[string]struct{}) *apis.FieldError {
+	pattern := fmt.Sprintf(braceMatchingRegex, contextPrefix+prefix, parameterSubstitution)
+	re := regexp.MustCompile(pattern)
+	var errs *apis.FieldError
+	for _, match := range re.FindAllStringSubmatch(value, -1) {
+		parts := strings.SplitN(matchGroups(match, re)["var"], ".", 2)
+		attributes, ok := resources[parts[0]]
+		if !ok {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("non-existent variable %q in %q for %s %s", parts[0], value, locationName, name),
+				Paths:   []string{path + "." + name},
+			})
+			continue
+		}
+		if len(parts) == 1 {
+			continue
+		}
+		if _, ok := attributes[parts[1]]; !ok {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("non-existent attribute %q of resource %q in %q for %s %s", parts[1], parts[0], value, locationName, name),
+				Paths:   []string{path + "." + name},
+			})
+		}
+	}
+	return errs
 }
 
 // Extract a the first full string expressions found (e.g "$(input.params.foo)"). Return
@@ -90,7 +90,7 @@ func TestValidateVariables(t *testing.T) {
 			},
 		},
 		expectedError: &apis.FieldError{
-			Message: `non-existent variable in "--flag=$(inputs.params.baz)" for step somefield`,
+			Message: `non-existent variable "baz" in "--flag=$(inputs.params.baz)" for step somefield`,
 			Paths:   []string{"taskspec.steps.somefield"},
 		},
 	}, {
@@ -106,15 +106,39 @@ func TestValidateVariables(t *testing.T) {
 			},
 		},
 		expectedError: &apis.FieldError{
-			Message: `non-existent variable in "--flag=$(inputs.params.baz) $(input.params.foo)" for step somefield`,
+			Message: `non-existent variable "baz" in "--flag=$(inputs.params.baz) $(input.params.foo)" for step somefield`,
 			Paths:   []string{"taskspec.steps.somefield"},
 		},
+	}, {
+		name: "undefined variables",
+		args: args{
+			input:         "--flag=$(inputs.params.baz) $(inputs.params.foo) $(inputs.params.qux)",
+			prefix:        "params",
+			contextPrefix: "inputs.",
+			locationName:  "step",
+			path:          "taskspec.steps",
+			vars: map[string]struct{}{
+				"foo": {},
+			},
+		},
+		expectedError: (&apis.FieldError{
+			Message: `non-existent variable "baz" in "--flag=$(inputs.params.baz) $(inputs.params.foo) $(inputs.params.qux)" for step somefield`,
+			Paths:   []string{"taskspec.steps.somefield"},
+		}).Also(&apis.FieldError{
+			Message: `non-existent variable "qux" in "--flag=$(inputs.params.baz) $(inputs.params.foo) $(inputs.params.qux)" for step somefield`,
+			Paths:   []string{"taskspec.steps.somefield"},
+		}),
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			got := v1alpha1.ValidateVariable("somefield", tc.args.input, tc.args.prefix, tc.args.contextPrefix, tc.args.locationName, tc.args.path, tc.args.vars)
 
-			if d := cmp.Diff(got, tc.expectedError, cmp.AllowUnexported(apis.FieldError{})); d != "" {
-				t.Errorf("ValidateVariable() error did not match expected error %s", d)
+			if (got == nil) != (tc.expectedError == nil) {
+				t.Fatalf("ValidateVariable() = %v, want %v", got, tc.expectedError)
+			}
+			if got != nil {
+				if d := cmp.Diff(tc.expectedError.Error(), got.Error()); d != "" {
+					t.Errorf("ValidateVariable() error did not match expected error %s", d)
+				}
 			}
 		})
 	}
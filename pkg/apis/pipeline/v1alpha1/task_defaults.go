@@ -19,10 +19,14 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func (t *Task) SetDefaults(ctx context.Context) {
 	t.Spec.SetDefaults(ctx)
+	t.Spec.SetDefaultResourceRequirements(config.FromContextOrDefaults(ctx).Defaults.ContainerResourceRequirements(t.Namespace))
 }
 
 // SetDefaults set any defaults for the task spec
@@ -41,6 +45,58 @@ func (ts *TaskSpec) SetDefaults(ctx context.Context) {
 	}
 }
 
+// SetDefaultResourceRequirements sets the requests and limits of defaults on
+// the steps which neither set them nor inherit them from the step template.
+// The request of a resource a step only limits is left for Kubernetes to
+// default to the limit, and a default limit lower than the request of a
+// step isn't set.
+func (ts *TaskSpec) SetDefaultResourceRequirements(defaults *corev1.ResourceRequirements) {
+	if defaults == nil {
+		return
+	}
+	var template corev1.ResourceRequirements
+	if ts.StepTemplate != nil {
+		template = ts.StepTemplate.Resources
+	}
+	for i := range ts.Steps {
+		r := &ts.Steps[i].Resources
+		for name, q := range defaults.Requests {
+			if hasResource(name, r.Requests, r.Limits, template.Requests, template.Limits) {
+				continue
+			}
+			if r.Requests == nil {
+				r.Requests = corev1.ResourceList{}
+			}
+			r.Requests[name] = q.DeepCopy()
+		}
+		for name, q := range defaults.Limits {
+			if hasResource(name, r.Limits, template.Limits) {
+				continue
+			}
+			request, ok := r.Requests[name]
+			if !ok {
+				request, ok = template.Requests[name]
+			}
+			if ok && request.Cmp(q) > 0 {
+				continue
+			}
+			if r.Limits == nil {
+				r.Limits = corev1.ResourceList{}
+			}
+			r.Limits[name] = q.DeepCopy()
+		}
+	}
+}
+
+func hasResource(name corev1.ResourceName, lists ...corev1.ResourceList) bool {
+	for _, l := range lists {
+		if _, ok := l[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (inputs *Inputs) SetDefaults(ctx context.Context) {
 	for i := range inputs.Params {
 		inputs.Params[i].SetDefaults(ctx)
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var compareQuantities = cmp.Comparer(func(x, y resource.Quantity) bool {
+	return x.Cmp(y) == 0
+})
+
+func resourceRequirements(requests, limits map[corev1.ResourceName]string) corev1.ResourceRequirements {
+	var r corev1.ResourceRequirements
+	for name, q := range requests {
+		if r.Requests == nil {
+			r.Requests = corev1.ResourceList{}
+		}
+		r.Requests[name] = resource.MustParse(q)
+	}
+	for name, q := range limits {
+		if r.Limits == nil {
+			r.Limits = corev1.ResourceList{}
+		}
+		r.Limits[name] = resource.MustParse(q)
+	}
+	return r
+}
+
+func TestTaskSpec_SetDefaultResourceRequirements(t *testing.T) {
+	defaults := resourceRequirements(
+		map[corev1.ResourceName]string{corev1.ResourceCPU: "100m", corev1.ResourceMemory: "128Mi"},
+		map[corev1.ResourceName]string{corev1.ResourceMemory: "1Gi"},
+	)
+	ts := &v1alpha1.TaskSpec{
+		StepTemplate: &corev1.Container{Resources: resourceRequirements(map[corev1.ResourceName]string{corev1.ResourceCPU: "500m"}, nil)},
+		Steps: []v1alpha1.Step{{Container: corev1.Container{
+			Name: "omitted",
+		}}, {Container: corev1.Container{
+			Name:      "limited",
+			Resources: resourceRequirements(nil, map[corev1.ResourceName]string{corev1.ResourceMemory: "512Mi"}),
+		}}, {Container: corev1.Container{
+			Name:      "hungry",
+			Resources: resourceRequirements(map[corev1.ResourceName]string{corev1.ResourceMemory: "4Gi"}, nil),
+		}}},
+	}
+	ts.SetDefaultResourceRequirements(&defaults)
+
+	want := []corev1.ResourceRequirements{
+		// The CPU request comes from the step template.
+		resourceRequirements(map[corev1.ResourceName]string{corev1.ResourceMemory: "128Mi"}, map[corev1.ResourceName]string{corev1.ResourceMemory: "1Gi"}),
+		resourceRequirements(nil, map[corev1.ResourceName]string{corev1.ResourceMemory: "512Mi"}),
+		resourceRequirements(map[corev1.ResourceName]string{corev1.ResourceMemory: "4Gi"}, nil),
+	}
+	for i, s := range ts.Steps {
+		if d := cmp.Diff(want[i], s.Resources, compareQuantities); d != "" {
+			t.Errorf("Resources of step %s (-want, +got): %s", s.Name, d)
+		}
+	}
+
+	ts.SetDefaultResourceRequirements(nil)
+	if d := cmp.Diff(want[0], ts.Steps[0].Resources, compareQuantities); d != "" {
+		t.Errorf("Expected no defaults to leave the steps unchanged (-want, +got): %s", d)
+	}
+}
+
+func TestTask_SetDefaultResourceRequirements(t *testing.T) {
+	ctx := config.ToContext(context.Background(), &config.Config{Defaults: &config.Defaults{
+		DefaultContainerResourceRequirements: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+		},
+		NamespaceContainerResourceRequirements: map[string]corev1.ResourceRequirements{
+			"ml-team": {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}},
+		},
+	}})
+	for namespace, want := range map[string]string{
+		"default": "100m",
+		"ml-team": "2",
+	} {
+		task := &v1alpha1.Task{
+			ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: namespace},
+			Spec:       v1alpha1.TaskSpec{Steps: []v1alpha1.Step{{Container: corev1.Container{Name: "build", Image: "golang"}}}},
+		}
+		task.SetDefaults(ctx)
+		got := task.Spec.Steps[0].Resources.Requests[corev1.ResourceCPU]
+		if got.Cmp(resource.MustParse(want)) != 0 {
+			t.Errorf("Expected the steps of the Tasks of %s to request %s of CPU, got %s", namespace, want, got.String())
+		}
+	}
+
+	clusterTask := &v1alpha1.ClusterTask{
+		Spec: v1alpha1.TaskSpec{Steps: []v1alpha1.Step{{Container: corev1.Container{Name: "build", Image: "golang"}}}},
+	}
+	clusterTask.SetDefaults(ctx)
+	if r := clusterTask.Spec.Steps[0].Resources; r.Requests != nil || r.Limits != nil {
+		t.Errorf("Expected the ClusterTask steps to be left for their TaskRuns to default, got %v", r)
+	}
+}
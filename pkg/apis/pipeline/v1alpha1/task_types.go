@@ -36,6 +36,14 @@ func (t *Task) Copy() TaskInterface {
 
 // TaskSpec defines the desired state of Task.
 type TaskSpec struct {
+	// DisplayName is a human-friendly name of the Task, reported in the
+	// status of its TaskRuns for UIs to show.
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+	// Description is what the Task does.
+	// +optional
+	Description string `json:"description,omitempty"`
+
 	// Inputs is an optional set of parameters and resources which must be
 	// supplied by the user when a Task is executed by a TaskRun.
 	// +optional
@@ -44,6 +52,31 @@ type TaskSpec struct {
 	// Task is run.
 	// +optional
 	Outputs *Outputs `json:"outputs,omitempty"`
+	// Results are the small values the steps emit, by writing them to
+	// $(results.<name>.path), which are reported in the status of the
+	// TaskRuns.
+	// +optional
+	Results []TaskResult `json:"results,omitempty"`
+	// Workspaces are the directories the steps share, which the TaskRuns
+	// bind to volumes.
+	// +optional
+	Workspaces []WorkspaceDeclaration `json:"workspaces,omitempty"`
+	// Requires are the capabilities, e.g. gpu, the nodes running the Task
+	// must have. The cluster maps them to node selectors, tolerations and
+	// runtime classes in the config-capabilities ConfigMap.
+	// +optional
+	Requires []string `json:"requires,omitempty"`
+
+	// Checkout clones a Git repository into the workspace before the steps
+	// run, with the Git image the controller is configured with.
+	// +optional
+	Checkout *Checkout `json:"checkout,omitempty"`
+
+	// InitSteps are setup containers which run one after the other, and
+	// must all complete successfully before the sidecars and the steps
+	// start. They get the same variable substitutions as the steps.
+	// +optional
+	InitSteps []corev1.Container `json:"initSteps,omitempty"`
 
 	// Steps are the steps of the build; each step is run sequentially with the
 	// source mounted into /workspace.
@@ -62,6 +95,38 @@ type TaskSpec struct {
 	Sidecars []corev1.Container `json:"sidecars,omitempty"`
 }
 
+// ResultsDir is the directory the steps write the results of their Task to.
+const ResultsDir = "/tekton/results"
+
+// ResultPath returns the file the steps write the result name to.
+func ResultPath(name string) string {
+	return ResultsDir + "/" + name
+}
+
+// TaskResult is a value a Task emits.
+type TaskResult struct {
+	// Name is the name of the result, and of the file under
+	// /tekton/results the steps write it to.
+	Name string `json:"name"`
+	// Description is what the result is.
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+// Checkout is a Git repository to clone before the steps of a Task run.
+type Checkout struct {
+	// Repo is the URL of the repository.
+	Repo string `json:"repo"`
+	// Revision is the branch, tag, commit SHA or ref to clone. Defaults to
+	// master.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+	// Workspace is the directory, relative to /workspace, the repository is
+	// cloned into. Defaults to /workspace itself.
+	// +optional
+	Workspace string `json:"workspace,omitempty"`
+}
+
 // Step embeds the Container type, which allows it to include fields not
 // provided by Container.
 type Step struct {
@@ -71,6 +136,72 @@ type Step struct {
 	//
 	// If Script is not empty, the Step cannot have an Command or Args.
 	Script string `json:"script,omitempty"`
+
+	// SkipExitCodes are exit codes which mean the Step was skipped rather
+	// than failed. When the Step exits with one of them, the following Steps
+	// still run and the Step is reported as skipped in the TaskRun status.
+	// +optional
+	SkipExitCodes []int32 `json:"skipExitCodes,omitempty"`
+
+	// OnError is what happens when the Step exits with a non-zero exit code
+	// other than its SkipExitCodes. Defaults to stopAndFail.
+	// +optional
+	OnError OnErrorType `json:"onError,omitempty"`
+
+	// Timeout is how long the Step may run before the entrypoint kills it
+	// and fails it, along with the TaskRun, independently of the timeout
+	// of the TaskRun. Zero means no timeout.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// SecretRefs are secrets of external secrets providers which are
+	// written to files in /builder/secrets before the Step starts.
+	// +optional
+	SecretRefs []SecretRef `json:"secretRefs,omitempty"`
+
+	// Ref references a StepAction providing the image, command, args, env
+	// and script of the Step, which then cannot set them itself.
+	// +optional
+	Ref *StepActionRef `json:"ref,omitempty"`
+
+	// Params bind the params of the StepAction referenced by Ref.
+	// +optional
+	Params []Param `json:"params,omitempty"`
+
+	// DisplayName is a human-friendly name of the Step, reported along with
+	// its state in the status of the TaskRuns.
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+	// Description is what the Step does, reported along with its state in
+	// the status of the TaskRuns.
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
+// OnErrorType is what happens when a Step exits with a non-zero exit code.
+type OnErrorType string
+
+const (
+	// StopAndFail fails the Step and the TaskRun, and the following Steps
+	// don't run.
+	StopAndFail OnErrorType = "stopAndFail"
+	// Continue records the exit code of the Step in the TaskRun status, and
+	// the following Steps still run.
+	Continue OnErrorType = "continue"
+)
+
+// SecretRef references a secret stored in an external secrets provider.
+type SecretRef struct {
+	// Provider is the name of the secrets provider storing the secret, e.g.
+	// vault.
+	Provider string `json:"provider"`
+	// Key identifies the secret in the provider. For vault, it is the path
+	// of the secret followed by # and the name of the field, e.g.
+	// secret/data/ci#token.
+	Key string `json:"key"`
+	// Path is the path of the file the secret is written to, relative to
+	// /builder/secrets.
+	Path string `json:"path"`
 }
 
 // Check that Task may be validated and defaulted.
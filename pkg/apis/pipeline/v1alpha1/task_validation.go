@@ -18,9 +18,16 @@ package v1alpha1
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/names"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -28,10 +35,13 @@ import (
 )
 
 func (t *Task) Validate(ctx context.Context) *apis.FieldError {
-	if err := validateObjectMetadata(t.GetObjectMeta()); err != nil {
-		return err.ViaField("metadata")
-	}
-	return t.Spec.Validate(ctx)
+	return noteValidationErrors(ctx, t.validate(ctx))
+}
+
+func (t *Task) validate(ctx context.Context) *apis.FieldError {
+	errs := validateObjectMetadata(t.GetObjectMeta()).ViaField("metadata")
+	errs = errs.Also(validateCatalogChecksum(ctx, "Task", t.Name, &t.Spec))
+	return errs.Also(t.Spec.Validate(ctx))
 }
 
 func (ts *TaskSpec) Validate(ctx context.Context) *apis.FieldError {
@@ -42,9 +52,10 @@ func (ts *TaskSpec) Validate(ctx context.Context) *apis.FieldError {
 	if len(ts.Steps) == 0 {
 		return apis.ErrMissingField("steps")
 	}
-	if err := ValidateVolumes(ts.Volumes).ViaField("volumes"); err != nil {
-		return err
-	}
+	// The conflicts are looked for in the steps before they are merged with
+	// the step template.
+	conflicts := validateStepTemplateConflicts(ctx, ts.StepTemplate, ts.Steps).ViaField("steps")
+	conflicts = conflicts.Also(validateStepTemplateConflicts(ctx, ts.StepTemplate, initStepsAsSteps(ts.InitSteps)).ViaField("initSteps"))
 	mergedSteps, err := MergeStepsWithStepTemplate(ts.StepTemplate, ts.Steps)
 	if err != nil {
 		return &apis.FieldError{
@@ -52,9 +63,34 @@ func (ts *TaskSpec) Validate(ctx context.Context) *apis.FieldError {
 			Paths:   []string{"stepTemplate"},
 		}
 	}
+	initSteps := initStepsAsSteps(ts.InitSteps)
+	mergedInitSteps, err := MergeStepsWithStepTemplate(ts.StepTemplate, initSteps)
+	if err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("error merging step template and init steps: %s", err),
+			Paths:   []string{"stepTemplate"},
+		}
+	}
 
-	if err := validateSteps(mergedSteps).ViaField("steps"); err != nil {
-		return err
+	errs := conflicts.Also(ValidateVolumes(ts.Volumes).ViaField("volumes"))
+	errs = errs.Also(validateSteps(ctx, mergedSteps).ViaField("steps"))
+	// The step template doesn't conflict with the StepActions, which are
+	// merged with it once resolved.
+	for i, s := range ts.Steps {
+		if s.Ref != nil {
+			errs = errs.Also(validateStepActionRef(s).ViaFieldIndex("steps", i))
+		}
+	}
+	errs = errs.Also(validateInitSteps(mergedInitSteps).ViaField("initSteps"))
+	errs = errs.Also(validateSidecars(ts.Sidecars).ViaField("sidecars"))
+	errs = errs.Also(ts.ValidateImagePolicy(ctx))
+	if volumes := mountableVolumes(ctx, ts); volumes != nil {
+		errs = errs.Also(validateVolumeMounts(mergedSteps, volumes).ViaField("steps"))
+		errs = errs.Also(validateVolumeMounts(mergedInitSteps, volumes).ViaField("initSteps"))
+		errs = errs.Also(validateVolumeMounts(initStepsAsSteps(ts.Sidecars), volumes).ViaField("sidecars"))
+	}
+	if ts.Checkout != nil {
+		errs = errs.Also(ts.Checkout.Validate(ctx).ViaField("checkout"))
 	}
 
 	// A task doesn't have to have inputs or outputs, but if it does they must be valid.
@@ -62,98 +98,381 @@ func (ts *TaskSpec) Validate(ctx context.Context) *apis.FieldError {
 
 	if ts.Inputs != nil {
 		for _, resource := range ts.Inputs.Resources {
-			if err := validateResourceType(resource, fmt.Sprintf("taskspec.Inputs.Resources.%s.Type", resource.Name)); err != nil {
-				return err
-			}
-		}
-		if err := checkForDuplicates(ts.Inputs.Resources, "taskspec.Inputs.Resources.Name"); err != nil {
-			return err
-		}
-		if err := validateInputParameterTypes(ts.Inputs); err != nil {
-			return err
+			errs = errs.Also(validateResourceType(resource, fmt.Sprintf("taskspec.Inputs.Resources.%s.Type", resource.Name)))
 		}
+		errs = errs.Also(checkForDuplicates(ts.Inputs.Resources, "taskspec.Inputs.Resources.Name"))
+		errs = errs.Also(validateInputParameterTypes(ts.Inputs))
 	}
 	if ts.Outputs != nil {
 		for _, resource := range ts.Outputs.Resources {
-			if err := validateResourceType(resource, fmt.Sprintf("taskspec.Outputs.Resources.%s.Type", resource.Name)); err != nil {
-				return err
-			}
-		}
-		if err := checkForDuplicates(ts.Outputs.Resources, "taskspec.Outputs.Resources.Name"); err != nil {
-			return err
+			errs = errs.Also(validateResourceType(resource, fmt.Sprintf("taskspec.Outputs.Resources.%s.Type", resource.Name)))
 		}
+		errs = errs.Also(checkForDuplicates(ts.Outputs.Resources, "taskspec.Outputs.Resources.Name"))
+	}
+
+	errs = errs.Also(validateDescription(ts.DisplayName, ts.Description))
+	for i, s := range ts.Steps {
+		errs = errs.Also(validateDescription(s.DisplayName, s.Description).ViaFieldIndex("steps", i))
 	}
 
+	errs = errs.Also(validateRequires(ts.Requires).ViaField("requires"))
+
 	// Validate task step names
 	for _, step := range ts.Steps {
-		if errs := validation.IsDNS1123Label(step.Name); step.Name != "" && len(errs) > 0 {
-			return &apis.FieldError{
+		if step.Name != "" && !isDNS1123Label(step.Name) {
+			errs = errs.Also(&apis.FieldError{
 				Message: fmt.Sprintf("invalid value %q", step.Name),
 				Paths:   []string{"taskspec.steps.name"},
 				Details: "Task step name must be a valid DNS Label, For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
-			}
+			})
+		}
+	}
+
+	// The variables of the init steps and sidecars are replaced like the
+	// ones of the steps.
+	sidecars := initStepsAsSteps(ts.Sidecars)
+	steps := append(append(append([]Step{}, mergedInitSteps...), sidecars...), mergedSteps...)
+	errs = errs.Also(validateInputParameterVariables(steps, ts.Inputs))
+	errs = errs.Also(validateResourceVariables(steps, ts.Inputs, ts.Outputs))
+	// The workspaces are only mounted in the steps. The init steps can't
+	// write results, which the entrypoint of the steps reports.
+	for _, kind := range []string{"workspaces", "results"} {
+		errs = errs.Also(validateDeclaredVariables(mergedInitSteps, kind, map[string]struct{}{}).ViaField("initSteps"))
+		errs = errs.Also(validateDeclaredVariables(sidecars, kind, map[string]struct{}{}).ViaField("sidecars"))
+	}
+	errs = errs.Also(validateWorkspaces(ts.Workspaces).ViaField("workspaces"))
+	workspaces := map[string]struct{}{}
+	for _, w := range ts.Workspaces {
+		workspaces[w.Name] = struct{}{}
+	}
+	errs = errs.Also(validateDeclaredVariables(mergedSteps, "workspaces", workspaces).ViaField("steps"))
+	errs = errs.Also(validatePathVariables(mergedSteps, "workspaces").ViaField("steps"))
+	errs = errs.Also(validateResults(ts.Results).ViaField("results"))
+	errs = errs.Also(validateChecksumResults(ts.Workspaces, ts.Results).ViaField("results"))
+	results := map[string]struct{}{}
+	for _, r := range ts.Results {
+		results[r.Name] = struct{}{}
+	}
+	errs = errs.Also(validateDeclaredVariables(mergedSteps, "results", results).ViaField("steps"))
+	errs = errs.Also(validatePathVariables(mergedSteps, "results").ViaField("steps"))
+	errs = errs.Also(validateStepVariables(mergedSteps).ViaField("steps"))
+	// The init steps and sidecars don't run the entrypoint, which replaces
+	// the variables of the steps.
+	errs = errs.Also(validateDeclaredVariables(sidecars, "steps", map[string]struct{}{}).ViaField("sidecars"))
+	errs = errs.Also(validateDeclaredVariables(mergedInitSteps, "steps", map[string]struct{}{}).ViaField("initSteps"))
+	return errs
+}
+
+func initStepsAsSteps(initSteps []corev1.Container) []Step {
+	var steps []Step
+	for _, c := range initSteps {
+		steps = append(steps, Step{Container: c})
+	}
+	return steps
+}
+
+func validateInitSteps(initSteps []Step) *apis.FieldError {
+	var errs *apis.FieldError
+	// The init steps are reported by name in the TaskRun status.
+	names := map[string]struct{}{}
+	for _, s := range initSteps {
+		if s.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("name"))
+		} else if msgs := validation.IsDNS1123Label(s.Name); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(s.Name, "name"))
+		} else if _, ok := names[s.Name]; ok {
+			errs = errs.Also(apis.ErrInvalidValue(s.Name, "name"))
+		}
+		names[s.Name] = struct{}{}
+		if s.Image == "" {
+			errs = errs.Also(apis.ErrMissingField("image"))
+		}
+		errs = errs.Also(validateEphemeralStorage(s.Resources))
+	}
+	return errs
+}
+
+const (
+	// maxDisplayNameLength and maxDescriptionLength are the number of
+	// characters the display names and descriptions of the Tasks and steps,
+	// reported in the status of every TaskRun, are limited to.
+	maxDisplayNameLength = 64
+	maxDescriptionLength = 1024
+)
+
+func validateDescription(displayName, description string) *apis.FieldError {
+	var errs *apis.FieldError
+	if n := utf8.RuneCountInString(displayName); n > maxDisplayNameLength {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("invalid value: %d characters, must be no more than %d", n, maxDisplayNameLength),
+			Paths:   []string{"displayName"},
+		})
+	}
+	if strings.ContainsAny(displayName, "\r\n") {
+		errs = errs.Also(&apis.FieldError{
+			Message: "invalid value: must be a single line",
+			Paths:   []string{"displayName"},
+		})
+	}
+	if n := utf8.RuneCountInString(description); n > maxDescriptionLength {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("invalid value: %d characters, must be no more than %d", n, maxDescriptionLength),
+			Paths:   []string{"description"},
+		})
+	}
+	return errs
+}
+
+// validateRequires checks the capabilities a Task requires are distinct
+// names, which are only looked up in the config-capabilities ConfigMap when
+// the Task runs.
+func validateRequires(requires []string) *apis.FieldError {
+	var errs *apis.FieldError
+	seen := map[string]bool{}
+	for i, capability := range requires {
+		if msgs := validation.IsDNS1123Label(capability); len(msgs) > 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("invalid value %q", capability),
+				Paths:   []string{fmt.Sprintf("[%d]", i)},
+				Details: strings.Join(msgs, "; "),
+			})
+		} else if seen[capability] {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("duplicate capability %q", capability),
+				Paths:   []string{fmt.Sprintf("[%d]", i)},
+			})
+		}
+		seen[capability] = true
+	}
+	return errs
+}
+
+// sidecarNamePrefix is the prefix of the containers of the steps, which
+// the sidecars can't use since their status is told apart by name.
+const sidecarNamePrefix = "step-"
+
+func validateSidecars(sidecars []corev1.Container) *apis.FieldError {
+	var errs *apis.FieldError
+	names := map[string]struct{}{}
+	for i, s := range sidecars {
+		if s.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("name").ViaIndex(i))
+		} else if msgs := validation.IsDNS1123Label(s.Name); len(msgs) > 0 || strings.HasPrefix(s.Name, sidecarNamePrefix) {
+			errs = errs.Also(apis.ErrInvalidValue(s.Name, "name").ViaIndex(i))
+		} else if _, ok := names[s.Name]; ok {
+			errs = errs.Also(apis.ErrMultipleOneOf("name").ViaIndex(i))
 		}
+		names[s.Name] = struct{}{}
+		if s.Image == "" {
+			errs = errs.Also(apis.ErrMissingField("image").ViaIndex(i))
+		}
+		errs = errs.Also(validateEphemeralStorage(s.Resources).ViaIndex(i))
 	}
+	return errs
+}
 
-	if err := validateInputParameterVariables(ts.Steps, ts.Inputs); err != nil {
-		return err
+// validateEphemeralStorage validates the ephemeral-storage request and limit
+// of a container, which the kubelet evicts the pod for exceeding.
+func validateEphemeralStorage(resources corev1.ResourceRequirements) *apis.FieldError {
+	request, hasRequest := resources.Requests[corev1.ResourceEphemeralStorage]
+	if hasRequest && request.Sign() < 0 {
+		return apis.ErrInvalidValue(request.String(), "resources.requests.ephemeral-storage")
+	}
+	limit, hasLimit := resources.Limits[corev1.ResourceEphemeralStorage]
+	if hasLimit && limit.Sign() < 0 {
+		return apis.ErrInvalidValue(limit.String(), "resources.limits.ephemeral-storage")
 	}
-	if err := validateResourceVariables(ts.Steps, ts.Inputs, ts.Outputs); err != nil {
-		return err
+	if hasRequest && hasLimit && request.Cmp(limit) > 0 {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("ephemeral-storage request %s must be less than or equal to its limit %s", request.String(), limit.String()),
+			Paths:   []string{"resources.requests.ephemeral-storage"},
+		}
 	}
 	return nil
 }
 
+// implicitVolumeNames are the volumes the pods of the TaskRuns have without
+// their Task declaring them, which its containers can mount: the volumes of
+// the workspace and home directories, and of the entrypoint.
+var implicitVolumeNames = []string{"workspace", "home", "tools", "downward", "tekton-internal"}
+
+// validateVolumeMounts checks that the volumeMounts of the steps reference
+// one of the volumes in volumes.
+func validateVolumeMounts(steps []Step, volumes map[string]struct{}) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, s := range steps {
+		for j, m := range s.VolumeMounts {
+			// The names with variables are only known once replaced.
+			if strings.Contains(m.Name, "$(") {
+				continue
+			}
+			if _, ok := volumes[m.Name]; !ok {
+				errs = errs.Also((&apis.FieldError{
+					Message: fmt.Sprintf("volumeMount %q doesn't reference a declared volume", m.Name),
+					Paths:   []string{fmt.Sprintf("volumeMounts[%d].name", j)},
+				}).ViaIndex(i))
+			}
+		}
+	}
+	return errs
+}
+
+// mountableVolumes returns the names of the volumes the containers of ts
+// can mount, or nil if they are only known once its variables are replaced,
+// or once it runs: the pod templates of the TaskRuns of a Task can add the
+// volumes it mounts.
+func mountableVolumes(ctx context.Context, ts *TaskSpec) map[string]struct{} {
+	podVolumes, ok := podTemplateVolumes(ctx)
+	if !ok {
+		return nil
+	}
+	volumes := map[string]struct{}{}
+	for _, v := range append(append([]corev1.Volume{}, ts.Volumes...), podVolumes...) {
+		if strings.Contains(v.Name, "$(") {
+			return nil
+		}
+		volumes[v.Name] = struct{}{}
+	}
+	for _, name := range implicitVolumeNames {
+		volumes[name] = struct{}{}
+	}
+	for _, w := range ts.Workspaces {
+		volumes[names.SimpleNameGenerator.RestrictLength("ws-"+w.Name)] = struct{}{}
+	}
+	return volumes
+}
+
 func ValidateVolumes(volumes []corev1.Volume) *apis.FieldError {
+	var errs *apis.FieldError
 	// Task must not have duplicate volume names.
 	vols := map[string]struct{}{}
 	for _, v := range volumes {
 		if _, ok := vols[v.Name]; ok {
-			return &apis.FieldError{
+			errs = errs.Also(&apis.FieldError{
 				Message: fmt.Sprintf("multiple volumes with same name %q", v.Name),
 				Paths:   []string{"name"},
-			}
+			})
 		}
 		vols[v.Name] = struct{}{}
 	}
-	return nil
+	return errs
 }
 
-func validateSteps(steps []Step) *apis.FieldError {
+func validateSteps(ctx context.Context, steps []Step) *apis.FieldError {
+	var errs *apis.FieldError
 	// Task must not have duplicate step names.
 	names := map[string]struct{}{}
-	for _, s := range steps {
-		if s.Image == "" {
-			return apis.ErrMissingField("Image")
+	// All the secrets of the Task are written to the same directory.
+	secretPaths := map[string]struct{}{}
+	for i, s := range steps {
+		var stepErrs *apis.FieldError
+		// The image of steps referencing a StepAction is the one of the
+		// StepAction.
+		if s.Ref == nil {
+			if s.Image == "" {
+				stepErrs = stepErrs.Also(apis.ErrMissingField("Image"))
+			}
+			if len(s.Params) > 0 {
+				stepErrs = stepErrs.Also(apis.ErrDisallowedFields("params"))
+			}
 		}
 
 		if s.Script != "" {
 			if len(s.Args) > 0 || len(s.Command) > 0 {
-				return &apis.FieldError{
+				stepErrs = stepErrs.Also(&apis.FieldError{
 					Message: "script cannot be used with args or command",
 					Paths:   []string{"script"},
-				}
+				})
 			}
-			if !strings.HasPrefix(strings.TrimSpace(s.Script), "#!") {
-				return &apis.FieldError{
+			// The scripts of Windows pods without a shebang are run with
+			// PowerShell.
+			if !strings.HasPrefix(strings.TrimSpace(s.Script), "#!") && !isWindows(ctx) {
+				stepErrs = stepErrs.Also(&apis.FieldError{
 					Message: "script must start with a shebang (#!)",
 					Paths:   []string{"script"},
-				}
+				})
 			}
+			stepErrs = stepErrs.Also(lintScript(ctx, s.Script, "script"))
 		}
 
-		if s.Name == "" {
-			continue
+		stepErrs = stepErrs.Also(validateEphemeralStorage(s.Resources))
+
+		for _, code := range s.SkipExitCodes {
+			if code < 1 || code > 255 {
+				stepErrs = stepErrs.Also(apis.ErrOutOfBoundsValue(code, 1, 255, "skipExitCodes"))
+			}
 		}
-		if _, ok := names[s.Name]; ok {
-			return apis.ErrInvalidValue(s.Name, "name")
+
+		switch s.OnError {
+		case "", StopAndFail, Continue:
+		default:
+			stepErrs = stepErrs.Also(apis.ErrInvalidValue(s.OnError, "onError"))
 		}
-		names[s.Name] = struct{}{}
+
+		if s.Timeout != nil && s.Timeout.Duration < 0 {
+			stepErrs = stepErrs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s should be >= 0", s.Timeout.Duration), "timeout"))
+		}
+
+		for _, e := range s.EnvFrom {
+			switch {
+			case e.ConfigMapRef == nil && e.SecretRef == nil:
+				stepErrs = stepErrs.Also(apis.ErrMissingOneOf("envFrom.configMapRef", "envFrom.secretRef"))
+			case e.ConfigMapRef != nil && e.SecretRef != nil:
+				stepErrs = stepErrs.Also(apis.ErrMultipleOneOf("envFrom.configMapRef", "envFrom.secretRef"))
+			}
+		}
+
+		for _, ref := range s.SecretRefs {
+			if ref.Provider == "" {
+				stepErrs = stepErrs.Also(apis.ErrMissingField("secretRefs.provider"))
+			}
+			if ref.Key == "" {
+				stepErrs = stepErrs.Also(apis.ErrMissingField("secretRefs.key"))
+			}
+			clean := filepath.Clean(ref.Path)
+			if ref.Path == "" || filepath.IsAbs(ref.Path) || clean == "." || strings.HasPrefix(clean, "..") {
+				stepErrs = stepErrs.Also(apis.ErrInvalidValue(ref.Path, "secretRefs.path"))
+			}
+			if _, ok := secretPaths[clean]; ok {
+				stepErrs = stepErrs.Also(apis.ErrInvalidValue(ref.Path, "secretRefs.path"))
+			}
+			secretPaths[clean] = struct{}{}
+		}
+
+		if s.Name != "" {
+			if _, ok := names[s.Name]; ok {
+				stepErrs = stepErrs.Also(apis.ErrInvalidValue(s.Name, "name"))
+			}
+			names[s.Name] = struct{}{}
+		}
+		// The errors of each step are reported at its index.
+		errs = errs.Also(stepErrs.ViaIndex(i))
 	}
-	return nil
+	return errs
+}
+
+// validateStepActionRef validates a step referencing a StepAction, which
+// provides its image, command, args and script.
+func validateStepActionRef(s Step) *apis.FieldError {
+	var errs *apis.FieldError
+	if s.Ref.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("ref.name"))
+	}
+	if s.Image != "" || len(s.Command) > 0 || len(s.Args) > 0 || s.Script != "" {
+		errs = errs.Also(apis.ErrMultipleOneOf("ref", "image", "command", "args", "script"))
+	}
+	names := map[string]struct{}{}
+	for _, p := range s.Params {
+		if _, ok := names[p.Name]; ok {
+			errs = errs.Also(apis.ErrInvalidValue(p.Name, "params.name"))
+		}
+		names[p.Name] = struct{}{}
+	}
+	return errs
 }
 
 func validateInputParameterTypes(inputs *Inputs) *apis.FieldError {
+	var errs *apis.FieldError
 	for _, p := range inputs.Params {
 		// Ensure param has a valid type.
 		validType := false
@@ -163,27 +482,32 @@ func validateInputParameterTypes(inputs *Inputs) *apis.FieldError {
 			}
 		}
 		if !validType {
-			return apis.ErrInvalidValue(p.Type, fmt.Sprintf("taskspec.inputs.params.%s.type", p.Name))
+			errs = errs.Also(apis.ErrInvalidValue(p.Type, fmt.Sprintf("taskspec.inputs.params.%s.type", p.Name)))
+			continue
 		}
 
 		// If a default value is provided, ensure its type matches param's declared type.
 		if (p.Default != nil) && (p.Default.Type != p.Type) {
-			return &apis.FieldError{
+			errs = errs.Also(&apis.FieldError{
 				Message: fmt.Sprintf(
 					"\"%v\" type does not match default value's type: \"%v\"", p.Type, p.Default.Type),
 				Paths: []string{
 					fmt.Sprintf("taskspec.inputs.params.%s.type", p.Name),
 					fmt.Sprintf("taskspec.inputs.params.%s.default.type", p.Name),
 				},
-			}
+			})
 		}
+
+		errs = errs.Also(validateObjectParamSpec(p, fmt.Sprintf("taskspec.inputs.params.%s", p.Name)))
+		errs = errs.Also(validateParamConstraints(p, fmt.Sprintf("taskspec.inputs.params.%s", p.Name)))
 	}
-	return nil
+	return errs
 }
 
 func validateInputParameterVariables(steps []Step, inputs *Inputs) *apis.FieldError {
 	parameterNames := map[string]struct{}{}
 	arrayParameterNames := map[string]struct{}{}
+	objectParameterKeys := map[string]map[string]struct{}{}
 
 	if inputs != nil {
 		for _, p := range inputs.Params {
@@ -192,114 +516,313 @@ func validateInputParameterVariables(steps []Step, inputs *Inputs) *apis.FieldEr
 				arrayParameterNames[p.Name] = struct{}{}
 			}
 		}
+		objectParameterKeys = objectParamKeys(inputs.Params)
 	}
 
-	if err := validateVariables(steps, "params", parameterNames); err != nil {
-		return err
-	}
-	return validateArrayUsage(steps, "params", arrayParameterNames)
+	return validateVariables(steps, "params", parameterNames).
+		Also(validateArrayUsage(steps, "params", arrayParameterNames)).
+		Also(validateObjectUsage(steps, "params", objectParameterKeys))
 }
 
+// validateResourceVariables checks that the $(inputs.resources.<name>...)
+// and $(outputs.resources.<name>...) variables of the steps reference an
+// input, respectively output, resource and, if any, one of the attributes of
+// its type.
 func validateResourceVariables(steps []Step, inputs *Inputs, outputs *Outputs) *apis.FieldError {
-	resourceNames := map[string]struct{}{}
+	var errs *apis.FieldError
+	var inputResources, outputResources []TaskResource
 	if inputs != nil {
-		for _, r := range inputs.Resources {
-			resourceNames[r.Name] = struct{}{}
-		}
+		inputResources = inputs.Resources
 	}
 	if outputs != nil {
+		outputResources = outputs.Resources
 		for _, r := range outputs.Resources {
-			resourceNames[r.Name] = struct{}{}
 			if r.Type == PipelineResourceTypeImage {
 				if r.OutputImageDir == "" {
-					return apis.ErrMissingField("OutputImageDir")
+					errs = errs.Also(apis.ErrMissingField("OutputImageDir"))
 				}
 			}
 		}
 	}
-	return validateVariables(steps, "resources", resourceNames)
+	inputAttributes := taskResourceAttributes(inputResources)
+	outputAttributes := taskResourceAttributes(outputResources)
+	return errs.Also(validateStepFields(steps, func(name, value string) *apis.FieldError {
+		return ValidateResourceAttributes(name, value, "resources", "inputs.", "step", "taskspec.steps", inputAttributes).
+			Also(ValidateResourceAttributes(name, value, "resources", "outputs.", "step", "taskspec.steps", outputAttributes))
+	}))
 }
 
-func validateArrayUsage(steps []Step, prefix string, vars map[string]struct{}) *apis.FieldError {
-	for _, step := range steps {
-		if err := validateTaskNoArrayReferenced("name", step.Name, prefix, vars); err != nil {
-			return err
+// taskResourceAttributes maps the names of resources to the attributes of
+// their types.
+func taskResourceAttributes(resources []TaskResource) map[string]map[string]struct{} {
+	attributes := make(map[string]map[string]struct{}, len(resources))
+	for _, r := range resources {
+		attributes[r.Name] = map[string]struct{}{}
+		for _, a := range resourceAttributes[r.Type] {
+			attributes[r.Name][a] = struct{}{}
 		}
-		if err := validateTaskNoArrayReferenced("image", step.Image, prefix, vars); err != nil {
-			return err
+	}
+	return attributes
+}
+
+// validateStepTemplateConflicts reports the values of the steps which
+// override a different value of the step template, with both values, if
+// the webhook is strict about the step template.
+func validateStepTemplateConflicts(ctx context.Context, template *corev1.Container, steps []Step) *apis.FieldError {
+	if template == nil || !config.FromContextOrDefaults(ctx).Defaults.StrictStepTemplate {
+		return nil
+	}
+	var errs *apis.FieldError
+	for i, s := range steps {
+		conflicts, err := stepTemplateConflicts(*template, s.Container)
+		if err != nil {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("error comparing step template and steps: %s", err),
+				Paths:   []string{"stepTemplate"},
+			}
 		}
-		if err := validateTaskNoArrayReferenced("workingDir", step.WorkingDir, prefix, vars); err != nil {
-			return err
+		for _, c := range conflicts {
+			t, _ := json.Marshal(c.template)
+			v, _ := json.Marshal(c.step)
+			errs = errs.Also((&apis.FieldError{
+				Message: "step overrides the value of the stepTemplate",
+				Paths:   []string{c.field},
+				Details: fmt.Sprintf("stepTemplate: %s, step: %s", t, v),
+			}).ViaIndex(i))
 		}
+	}
+	return errs
+}
+
+func validateArrayUsage(steps []Step, prefix string, vars map[string]struct{}) *apis.FieldError {
+	var errs *apis.FieldError
+	for _, step := range steps {
+		errs = errs.Also(validateTaskNoArrayReferenced("name", step.Name, prefix, vars))
+		errs = errs.Also(validateTaskNoArrayReferenced("image", step.Image, prefix, vars))
+		errs = errs.Also(validateTaskNoArrayReferenced("workingDir", step.WorkingDir, prefix, vars))
 		for i, cmd := range step.Command {
-			if err := validateTaskArraysIsolated(fmt.Sprintf("command[%d]", i), cmd, prefix, vars); err != nil {
-				return err
-			}
+			errs = errs.Also(validateTaskArraysIsolated(fmt.Sprintf("command[%d]", i), cmd, prefix, vars))
 		}
 		for i, arg := range step.Args {
-			if err := validateTaskArraysIsolated(fmt.Sprintf("arg[%d]", i), arg, prefix, vars); err != nil {
-				return err
-			}
+			errs = errs.Also(validateTaskArraysIsolated(fmt.Sprintf("arg[%d]", i), arg, prefix, vars))
 		}
 		for _, env := range step.Env {
-			if err := validateTaskNoArrayReferenced(fmt.Sprintf("env[%s]", env.Name), env.Value, prefix, vars); err != nil {
-				return err
-			}
+			errs = errs.Also(validateTaskNoArrayReferenced(fmt.Sprintf("env[%s]", env.Name), env.Value, prefix, vars))
 		}
-		for i, v := range step.VolumeMounts {
-			if err := validateTaskNoArrayReferenced(fmt.Sprintf("volumeMount[%d].Name", i), v.Name, prefix, vars); err != nil {
-				return err
+		for i, e := range step.EnvFrom {
+			errs = errs.Also(validateTaskNoArrayReferenced(fmt.Sprintf("envFrom[%d].prefix", i), e.Prefix, prefix, vars))
+			if e.ConfigMapRef != nil {
+				errs = errs.Also(validateTaskNoArrayReferenced(fmt.Sprintf("envFrom[%d].configMapRef.name", i), e.ConfigMapRef.Name, prefix, vars))
 			}
-			if err := validateTaskNoArrayReferenced(fmt.Sprintf("volumeMount[%d].MountPath", i), v.MountPath, prefix, vars); err != nil {
-				return err
-			}
-			if err := validateTaskNoArrayReferenced(fmt.Sprintf("volumeMount[%d].SubPath", i), v.SubPath, prefix, vars); err != nil {
-				return err
+			if e.SecretRef != nil {
+				errs = errs.Also(validateTaskNoArrayReferenced(fmt.Sprintf("envFrom[%d].secretRef.name", i), e.SecretRef.Name, prefix, vars))
 			}
 		}
+		for i, v := range step.VolumeMounts {
+			errs = errs.Also(validateTaskNoArrayReferenced(fmt.Sprintf("volumeMount[%d].Name", i), v.Name, prefix, vars))
+			errs = errs.Also(validateTaskNoArrayReferenced(fmt.Sprintf("volumeMount[%d].MountPath", i), v.MountPath, prefix, vars))
+			errs = errs.Also(validateTaskNoArrayReferenced(fmt.Sprintf("volumeMount[%d].SubPath", i), v.SubPath, prefix, vars))
+		}
 	}
-	return nil
+	return errs
 }
 
-func validateVariables(steps []Step, prefix string, vars map[string]struct{}) *apis.FieldError {
+// validateObjectUsage checks that the object params in objects are only
+// referenced by one of their keys in the steps.
+func validateObjectUsage(steps []Step, prefix string, objects map[string]map[string]struct{}) *apis.FieldError {
+	var errs *apis.FieldError
 	for _, step := range steps {
-		if err := validateTaskVariable("name", step.Name, prefix, vars); err != nil {
-			return err
-		}
-		if err := validateTaskVariable("image", step.Image, prefix, vars); err != nil {
-			return err
+		values, fields := stepValues(step)
+		for _, f := range fields {
+			errs = errs.Also(validateTaskObjectKeys(f, values[f], prefix, objects))
 		}
-		if err := validateTaskVariable("workingDir", step.WorkingDir, prefix, vars); err != nil {
-			return err
+	}
+	return errs
+}
+
+func validateVariables(steps []Step, prefix string, vars map[string]struct{}) *apis.FieldError {
+	return validateStepFields(steps, func(name, value string) *apis.FieldError {
+		return validateTaskVariable(name, value, prefix, vars)
+	})
+}
+
+// validateStepFields checks the fields of the steps in which variables are
+// replaced with validate, which is passed the name and the value of each.
+func validateStepFields(steps []Step, validate func(name, value string) *apis.FieldError) *apis.FieldError {
+	var errs *apis.FieldError
+	for _, step := range steps {
+		for _, p := range step.Params {
+			for _, v := range append([]string{p.Value.StringVal}, p.Value.ArrayVal...) {
+				errs = errs.Also(validate(fmt.Sprintf("params[%s]", p.Name), v))
+			}
 		}
+		errs = errs.Also(validate("name", step.Name))
+		errs = errs.Also(validate("image", step.Image))
+		errs = errs.Also(validate("workingDir", step.WorkingDir))
 		for i, cmd := range step.Command {
-			if err := validateTaskVariable(fmt.Sprintf("command[%d]", i), cmd, prefix, vars); err != nil {
-				return err
-			}
+			errs = errs.Also(validate(fmt.Sprintf("command[%d]", i), cmd))
 		}
 		for i, arg := range step.Args {
-			if err := validateTaskVariable(fmt.Sprintf("arg[%d]", i), arg, prefix, vars); err != nil {
-				return err
-			}
+			errs = errs.Also(validate(fmt.Sprintf("arg[%d]", i), arg))
 		}
 		for _, env := range step.Env {
-			if err := validateTaskVariable(fmt.Sprintf("env[%s]", env.Name), env.Value, prefix, vars); err != nil {
-				return err
+			errs = errs.Also(validate(fmt.Sprintf("env[%s]", env.Name), env.Value))
+		}
+		for i, e := range step.EnvFrom {
+			errs = errs.Also(validate(fmt.Sprintf("envFrom[%d].prefix", i), e.Prefix))
+			if e.ConfigMapRef != nil {
+				errs = errs.Also(validate(fmt.Sprintf("envFrom[%d].configMapRef.name", i), e.ConfigMapRef.Name))
+			}
+			if e.SecretRef != nil {
+				errs = errs.Also(validate(fmt.Sprintf("envFrom[%d].secretRef.name", i), e.SecretRef.Name))
 			}
 		}
 		for i, v := range step.VolumeMounts {
-			if err := validateTaskVariable(fmt.Sprintf("volumeMount[%d].Name", i), v.Name, prefix, vars); err != nil {
-				return err
+			errs = errs.Also(validate(fmt.Sprintf("volumeMount[%d].Name", i), v.Name))
+			errs = errs.Also(validate(fmt.Sprintf("volumeMount[%d].MountPath", i), v.MountPath))
+			errs = errs.Also(validate(fmt.Sprintf("volumeMount[%d].SubPath", i), v.SubPath))
+		}
+	}
+	return errs
+}
+
+// validateDeclaredVariables checks that the $(<kind>.<name>...) variables
+// of the steps, e.g. $(workspaces.source.path), reference declared names.
+// The error points at the index of the step and the field of the variable.
+func validateDeclaredVariables(steps []Step, kind string, declared map[string]struct{}) *apis.FieldError {
+	var errs *apis.FieldError
+	for i, step := range steps {
+		values, fields := stepValues(step)
+		for _, f := range fields {
+			vs, _ := extractVariablesFromString(values[f], kind)
+			for _, v := range vs {
+				if _, ok := declared[v]; !ok {
+					errs = errs.Also((&apis.FieldError{
+						Message: fmt.Sprintf("undeclared %s %q in %q", strings.TrimSuffix(kind, "s"), v, values[f]),
+						Paths:   []string{f},
+					}).ViaIndex(i))
+				}
 			}
-			if err := validateTaskVariable(fmt.Sprintf("volumeMount[%d].MountPath", i), v.MountPath, prefix, vars); err != nil {
-				return err
+		}
+	}
+	return errs
+}
+
+// resultNameRegex matches the valid result names, which are file names
+// without dots, so that $(results.<name>.path) can be parsed.
+var resultNameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([-_a-zA-Z0-9]*[a-zA-Z0-9])?$`)
+
+// validateResults checks that the results have unique and valid names.
+func validateResults(results []TaskResult) *apis.FieldError {
+	var errs *apis.FieldError
+	names := map[string]struct{}{}
+	for i, r := range results {
+		if !resultNameRegex.MatchString(r.Name) {
+			errs = errs.Also((&apis.FieldError{
+				Message: fmt.Sprintf("invalid result name %q", r.Name),
+				Paths:   []string{"name"},
+				Details: "Result names must consist of alphanumeric characters, '-' and '_', and start and end with an alphanumeric character",
+			}).ViaIndex(i))
+		} else if _, ok := names[r.Name]; ok {
+			errs = errs.Also(apis.ErrMultipleOneOf("name").ViaIndex(i))
+		}
+		names[r.Name] = struct{}{}
+	}
+	return errs
+}
+
+// validatePathVariables checks that the $(<kind>.<name>.<field>) variables
+// of the steps, e.g. $(results.digest.path), reference the path of what
+// they name, which is the only field replaced.
+func validatePathVariables(steps []Step, kind string) *apis.FieldError {
+	var errs *apis.FieldError
+	re := regexp.MustCompile(`\$\(` + kind + `\.[^.)]*(\.[^)]*)?\)`)
+	for i, step := range steps {
+		values, fields := stepValues(step)
+		for _, f := range fields {
+			for _, m := range re.FindAllStringSubmatch(values[f], -1) {
+				if m[1] != ".path" {
+					errs = errs.Also((&apis.FieldError{
+						Message: fmt.Sprintf("%s in %q doesn't reference the path of a %s", m[0], values[f], strings.TrimSuffix(kind, "s")),
+						Paths:   []string{f},
+					}).ViaIndex(i))
+				}
 			}
-			if err := validateTaskVariable(fmt.Sprintf("volumeMount[%d].SubPath", i), v.SubPath, prefix, vars); err != nil {
-				return err
+		}
+	}
+	return errs
+}
+
+// validateStepVariables checks that the $(steps.<name>.<field>) variables of
+// the steps reference the exit code, or a result, of an earlier named step.
+// The entrypoint only replaces them in the command, args and env of a step.
+func validateStepVariables(steps []Step) *apis.FieldError {
+	var errs *apis.FieldError
+	earlier := map[string]struct{}{}
+	for i, step := range steps {
+		values, fields := stepValues(step)
+		for _, f := range fields {
+			for _, v := range StepVariables(values[f]) {
+				_, isEarlier := earlier[v.Step]
+				switch {
+				case !strings.HasPrefix(f, "command[") && !strings.HasPrefix(f, "args[") && !strings.HasPrefix(f, "env["):
+					errs = errs.Also((&apis.FieldError{
+						Message: fmt.Sprintf("%s in %q can only be used in the command, args and env of a step", v.Expression, values[f]),
+						Paths:   []string{f},
+					}).ViaIndex(i))
+				case !isEarlier:
+					errs = errs.Also((&apis.FieldError{
+						Message: fmt.Sprintf("%s in %q doesn't reference an earlier step", v.Expression, values[f]),
+						Paths:   []string{f},
+					}).ViaIndex(i))
+				case !v.Valid():
+					errs = errs.Also((&apis.FieldError{
+						Message: fmt.Sprintf("%s in %q doesn't reference the exitCode or a result of step %q", v.Expression, values[f], v.Step),
+						Paths:   []string{f},
+					}).ViaIndex(i))
+				}
 			}
 		}
+		if step.Name != "" {
+			earlier[step.Name] = struct{}{}
+		}
 	}
-	return nil
+	return errs
+}
+
+// stepValues returns the values of the fields of step which may hold
+// variables, and the sorted names of those fields.
+func stepValues(step Step) (map[string]string, []string) {
+	values := map[string]string{
+		"name":       step.Name,
+		"image":      step.Image,
+		"workingDir": step.WorkingDir,
+		"script":     step.Script,
+	}
+	for j, cmd := range step.Command {
+		values[fmt.Sprintf("command[%d]", j)] = cmd
+	}
+	for j, arg := range step.Args {
+		values[fmt.Sprintf("args[%d]", j)] = arg
+	}
+	for _, env := range step.Env {
+		values[fmt.Sprintf("env[%s]", env.Name)] = env.Value
+	}
+	for j, e := range step.EnvFrom {
+		values[fmt.Sprintf("envFrom[%d].prefix", j)] = e.Prefix
+		if e.ConfigMapRef != nil {
+			values[fmt.Sprintf("envFrom[%d].configMapRef.name", j)] = e.ConfigMapRef.Name
+		}
+		if e.SecretRef != nil {
+			values[fmt.Sprintf("envFrom[%d].secretRef.name", j)] = e.SecretRef.Name
+		}
+	}
+	fields := make([]string, 0, len(values))
+	for f := range values {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return values, fields
 }
 
 func validateTaskVariable(name, value, prefix string, vars map[string]struct{}) *apis.FieldError {
@@ -314,15 +837,20 @@ func validateTaskArraysIsolated(name, value, prefix string, arrayNames map[strin
 	return ValidateVariableIsolated(name, value, prefix, "(?:inputs|outputs).", "step", "taskspec.steps", arrayNames)
 }
 
+func validateTaskObjectKeys(name, value, prefix string, objects map[string]map[string]struct{}) *apis.FieldError {
+	return ValidateObjectKeys(name, value, prefix, "(?:inputs|outputs).", "step", "taskspec.steps", objects)
+}
+
 func checkForDuplicates(resources []TaskResource, path string) *apis.FieldError {
+	var errs *apis.FieldError
 	encountered := map[string]struct{}{}
 	for _, r := range resources {
 		if _, ok := encountered[strings.ToLower(r.Name)]; ok {
-			return apis.ErrMultipleOneOf(path)
+			errs = errs.Also(apis.ErrMultipleOneOf(path))
 		}
 		encountered[strings.ToLower(r.Name)] = struct{}{}
 	}
-	return nil
+	return errs
 }
 
 func validateResourceType(r TaskResource, path string) *apis.FieldError {
@@ -333,3 +861,17 @@ func validateResourceType(r TaskResource, path string) *apis.FieldError {
 	}
 	return apis.ErrInvalidValue(string(r.Type), path)
 }
+
+// Validate checks that the repository is set and that it is cloned under
+// /workspace.
+func (c *Checkout) Validate(ctx context.Context) *apis.FieldError {
+	if c.Repo == "" {
+		return apis.ErrMissingField("repo")
+	}
+	if c.Workspace != "" {
+		if filepath.IsAbs(c.Workspace) || strings.HasPrefix(filepath.Clean(c.Workspace), "..") {
+			return apis.ErrInvalidValue(c.Workspace+" should be a relative path under "+WorkspaceDir, "workspace")
+		}
+	}
+	return nil
+}
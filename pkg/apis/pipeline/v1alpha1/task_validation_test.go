@@ -18,13 +18,17 @@ package v1alpha1_test
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/test/builder"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 )
 
@@ -65,6 +69,12 @@ func TestTaskSpecValidate(t *testing.T) {
 		Outputs      *v1alpha1.Outputs
 		Steps        []v1alpha1.Step
 		StepTemplate *corev1.Container
+		InitSteps    []corev1.Container
+		Sidecars     []corev1.Container
+		Volumes      []corev1.Volume
+		Checkout     *v1alpha1.Checkout
+		Results      []v1alpha1.TaskResult
+		Workspaces   []v1alpha1.WorkspaceDeclaration
 	}
 	tests := []struct {
 		name   string
@@ -105,6 +115,43 @@ func TestTaskSpecValidate(t *testing.T) {
 			},
 			Steps: validSteps,
 		},
+	}, {
+		name: "valid object inputs",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Params: []v1alpha1.ParamSpec{{
+					Name:       "repo",
+					Type:       v1alpha1.ParamTypeObject,
+					Properties: map[string]v1alpha1.PropertySpec{"url": {Type: v1alpha1.ParamTypeString}, "revision": {Type: v1alpha1.ParamTypeString}},
+					Default:    builder.ObjectValue(map[string]string{"url": "https://github.com/tektoncd/pipeline", "revision": "master"}),
+				}},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Image: "alpine/git",
+				Args:  []string{"clone", "$(inputs.params.repo.url)", "--branch=$(inputs.params.repo.revision)"},
+			}}, {
+				Container: corev1.Container{Image: "alpine"},
+				Script:    "#!/bin/sh\necho $(inputs.params.repo.url)",
+			}},
+		},
+	}, {
+		name: "valid constrained inputs",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Params: []v1alpha1.ParamSpec{{
+					Name:    "env",
+					Type:    v1alpha1.ParamTypeString,
+					Enum:    []string{"dev", "prod"},
+					Default: builder.ArrayOrString("dev"),
+				}, {
+					Name:    "tags",
+					Type:    v1alpha1.ParamTypeArray,
+					Pattern: `^v[0-9]+$`,
+					Default: builder.ArrayOrString("v1", "v2"),
+				}},
+			},
+			Steps: validSteps,
+		},
 	}, {
 		name: "valid outputs",
 		fields: fields{
@@ -156,6 +203,28 @@ func TestTaskSpecValidate(t *testing.T) {
 				WorkingDir: "/foo/bar/$(outputs.resources.source)",
 			}}},
 		},
+	}, {
+		name: "valid resource attribute variables",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+			},
+			Outputs: &v1alpha1.Outputs{
+				Resources: []v1alpha1.TaskResource{{
+					ResourceDeclaration: v1alpha1.ResourceDeclaration{
+						Name: "builtImage",
+						Type: "image",
+					},
+					OutputImageDir: "/workspace/output/builtImage",
+				}},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:       "mystep",
+				Image:      "myimage",
+				Args:       []string{"--url=$(inputs.resources.source.url)", "--revision=$(inputs.resources.source.revision)", "--image=$(outputs.resources.builtImage.url)"},
+				WorkingDir: "$(inputs.resources.source.path)",
+			}}},
+		},
 	}, {
 		name: "valid array template variable",
 		fields: fields{
@@ -180,6 +249,63 @@ func TestTaskSpecValidate(t *testing.T) {
 				WorkingDir: "/foo/bar/$(outputs.resources.source)",
 			}}},
 		},
+	}, {
+		name: "envFrom with param variables",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Params: []v1alpha1.ParamSpec{{Name: "env", Type: v1alpha1.ParamTypeString}},
+			},
+			StepTemplate: &corev1.Container{
+				EnvFrom: []corev1.EnvFromSource{{
+					ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "common"}},
+				}},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+				EnvFrom: []corev1.EnvFromSource{{
+					Prefix:    "$(inputs.params.env)_",
+					SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "$(inputs.params.env)-credentials"}},
+				}},
+			}}},
+		},
+	}, {
+		name: "volume mounts of declared, workspace and implicit volumes",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Params: []v1alpha1.ParamSpec{{Name: "volume", Type: v1alpha1.ParamTypeString}},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "cache", MountPath: "/cache"},
+					{Name: "ws-source", MountPath: "/source"},
+					{Name: "tekton-internal", MountPath: "/tekton"},
+					{Name: "$(inputs.params.volume)", MountPath: "/param"},
+				},
+			}}},
+			Sidecars: []corev1.Container{{
+				Name:         "sidecar",
+				Image:        "myimage",
+				VolumeMounts: []corev1.VolumeMount{{Name: "cache", MountPath: "/cache"}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         "cache",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}},
+			Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source"}},
+		},
+	}, {
+		// The pod templates of the TaskRuns can add them.
+		name: "volume mounts of undeclared volumes",
+		fields: fields{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:         "mystep",
+				Image:        "myimage",
+				VolumeMounts: []corev1.VolumeMount{{Name: "cache", MountPath: "/cache"}},
+			}}},
+		},
 	}, {
 		name: "step template included in validation",
 		fields: fields{
@@ -204,6 +330,167 @@ func TestTaskSpecValidate(t *testing.T) {
 				hello world`,
 			}},
 		},
+	}, {
+		name: "valid step with skip exit codes",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				SkipExitCodes: []int32{78, 255},
+			}},
+		},
+	}, {
+		name: "valid steps with onError",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Name:  "lint",
+					Image: "my-image",
+				},
+				OnError: v1alpha1.Continue,
+			}, {
+				Container: corev1.Container{
+					Name:  "build",
+					Image: "my-image",
+				},
+				OnError: v1alpha1.StopAndFail,
+			}},
+		},
+	}, {
+		name: "valid step with timeout",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+			}},
+		},
+	}, {
+		name: "valid step with secret refs",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				SecretRefs: []v1alpha1.SecretRef{
+					{Provider: "vault", Key: "secret/data/ci#token", Path: "token"},
+					{Provider: "vault", Key: "database/creds/ci#password", Path: "db/password"},
+				},
+			}},
+		},
+	}, {
+		name: "valid init steps using params",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Params: []v1alpha1.ParamSpec{{Name: "baz", Type: v1alpha1.ParamTypeString}},
+			},
+			InitSteps: []corev1.Container{{
+				Name:  "fetch-cache",
+				Image: "myimage",
+				Args:  []string{"$(inputs.params.baz)"},
+			}, {
+				Name:  "warm-up",
+				Image: "myimage",
+			}},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Image: "myimage",
+			}}},
+		},
+	}, {
+		name: "step referencing a step action",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Params: []v1alpha1.ParamSpec{{Name: "package", Type: v1alpha1.ParamTypeString}},
+			},
+			StepTemplate: &corev1.Container{Image: "ubuntu"},
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{Name: "build", WorkingDir: "/workspace/src"},
+				Ref:       &v1alpha1.StepActionRef{Name: "go-build"},
+				Params: []v1alpha1.Param{{
+					Name:  "package",
+					Value: *builder.ArrayOrString("$(inputs.params.package)"),
+				}},
+			}},
+		},
+	}, {
+		name: "step referencing a step action with a step template without image",
+		fields: fields{
+			StepTemplate: &corev1.Container{
+				Env: []corev1.EnvVar{{Name: "GOFLAGS", Value: "-mod=vendor"}},
+			},
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{Name: "build"},
+				Ref:       &v1alpha1.StepActionRef{Name: "go-build"},
+			}},
+		},
+	}, {
+		name: "valid checkout",
+		fields: fields{
+			Checkout: &v1alpha1.Checkout{
+				Repo:      "https://github.com/tektoncd/pipeline",
+				Revision:  "v0.8.0",
+				Workspace: "src/pipeline",
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Image: "myimage",
+			}}},
+		},
+	}, {
+		name: "step variables",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Name:    "lint",
+					Image:   "myimage",
+					Command: []string{"lint"},
+				},
+				SkipExitCodes: []int32{78},
+			}, {
+				Container: corev1.Container{
+					Name:    "build",
+					Image:   "myimage",
+					Command: []string{"build", "--lint-exit-code=$(steps.lint.exitCode)"},
+				},
+			}, {
+				Container: corev1.Container{
+					Image: "myimage",
+					Args:  []string{"$(steps.build.results.image-digest)"},
+					Env:   []corev1.EnvVar{{Name: "LINTED", Value: "$(steps.lint.exitCode)"}},
+				},
+			}},
+		},
+	}, {
+		name: "results",
+		fields: fields{
+			Results: []v1alpha1.TaskResult{{Name: "image-digest", Description: "The digest of the image"}, {Name: "commit_sha"}},
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{Name: "build", Image: "myimage"},
+				Script:    "#!/bin/sh\nbuild --digest-file $(results.image-digest.path)",
+			}, {
+				Container: corev1.Container{
+					Image:   "myimage",
+					Command: []string{"sh", "-c", "git rev-parse HEAD > $(results.commit_sha.path)"},
+				},
+			}},
+		},
+	}, {
+		name: "workspaces",
+		fields: fields{
+			Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source"}, {Name: "cache", MountPath: "/cache", ReadOnly: true}},
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{Name: "build", Image: "myimage", WorkingDir: "$(workspaces.source.path)"},
+				Script:    "#!/bin/sh\nbuild --cache $(workspaces.cache.path)",
+			}},
+		},
+	}, {
+		name: "workspace checksums",
+		fields: fields{
+			Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source", Checksum: true}},
+			Results:    []v1alpha1.TaskResult{{Name: "cache-checksum-start"}},
+			Steps:      validSteps,
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -212,6 +499,12 @@ func TestTaskSpecValidate(t *testing.T) {
 				Outputs:      tt.fields.Outputs,
 				Steps:        tt.fields.Steps,
 				StepTemplate: tt.fields.StepTemplate,
+				InitSteps:    tt.fields.InitSteps,
+				Sidecars:     tt.fields.Sidecars,
+				Volumes:      tt.fields.Volumes,
+				Checkout:     tt.fields.Checkout,
+				Results:      tt.fields.Results,
+				Workspaces:   tt.fields.Workspaces,
 			}
 			ctx := context.Background()
 			ts.SetDefaults(ctx)
@@ -222,12 +515,24 @@ func TestTaskSpecValidate(t *testing.T) {
 	}
 }
 
+// envStepTemplate is a step template which doesn't conflict with the steps,
+// for their own fields to be validated once merged with it.
+var envStepTemplate = &corev1.Container{
+	Env: []corev1.EnvVar{{Name: "GOFLAGS", Value: "-mod=vendor"}},
+}
+
 func TestTaskSpecValidateError(t *testing.T) {
 	type fields struct {
-		Inputs  *v1alpha1.Inputs
-		Outputs *v1alpha1.Outputs
-		Steps   []v1alpha1.Step
-		Volumes []corev1.Volume
+		Inputs       *v1alpha1.Inputs
+		Outputs      *v1alpha1.Outputs
+		Steps        []v1alpha1.Step
+		StepTemplate *corev1.Container
+		Volumes      []corev1.Volume
+		InitSteps    []corev1.Container
+		Sidecars     []corev1.Container
+		Checkout     *v1alpha1.Checkout
+		Results      []v1alpha1.TaskResult
+		Workspaces   []v1alpha1.WorkspaceDeclaration
 	}
 	tests := []struct {
 		name          string
@@ -240,145 +545,296 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Paths:   []string{""},
 		},
 	}, {
-		name: "no build",
+		name: "invalid param pattern",
 		fields: fields{
 			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{validResource},
+				Params: []v1alpha1.ParamSpec{{
+					Name:    "tag",
+					Type:    v1alpha1.ParamTypeString,
+					Pattern: "v[0-9",
+				}},
 			},
+			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `missing field(s)`,
-			Paths:   []string{"steps"},
+			Message: `invalid value: v[0-9`,
+			Paths:   []string{"taskspec.inputs.params.tag.pattern"},
 		},
 	}, {
-		name: "one invalid input",
+		name: "duplicate param enum value",
 		fields: fields{
 			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{
-					invalidResource,
-					validResource,
-				},
-			},
-			Outputs: &v1alpha1.Outputs{
-				Resources: []v1alpha1.TaskResource{
-					validResource,
-				},
+				Params: []v1alpha1.ParamSpec{{
+					Name: "env",
+					Type: v1alpha1.ParamTypeString,
+					Enum: []string{"dev", "prod", "dev"},
+				}},
 			},
 			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `invalid value: what`,
-			Paths:   []string{"taskspec.Inputs.Resources.source.Type"},
+			Message: `duplicate enum value "dev"`,
+			Paths:   []string{"taskspec.inputs.params.env.enum[2]"},
 		},
 	}, {
-		name: "invalid input type",
+		name: "param default violating its enum",
 		fields: fields{
 			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{validResource},
 				Params: []v1alpha1.ParamSpec{{
-					Name:        "validparam",
-					Type:        v1alpha1.ParamTypeString,
-					Description: "parameter",
-					Default:     builder.ArrayOrString("default"),
-				}, {
-					Name:        "param-with-invalid-type",
-					Type:        "invalidtype",
-					Description: "invalidtypedesc",
-					Default:     builder.ArrayOrString("default"),
+					Name:    "env",
+					Type:    v1alpha1.ParamTypeString,
+					Enum:    []string{"dev", "prod"},
+					Default: builder.ArrayOrString("staging"),
 				}},
 			},
 			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `invalid value: invalidtype`,
-			Paths:   []string{"taskspec.inputs.params.param-with-invalid-type.type"},
+			Message: `value of param "env" isn't one of dev, prod`,
+			Paths:   []string{"taskspec.inputs.params.env.default"},
 		},
 	}, {
-		name: "input mismatching default/type 1",
+		name: "object param without properties",
 		fields: fields{
 			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{validResource},
 				Params: []v1alpha1.ParamSpec{{
-					Name:        "task",
-					Type:        v1alpha1.ParamTypeArray,
-					Description: "param",
-					Default:     builder.ArrayOrString("default"),
+					Name: "repo",
+					Type: v1alpha1.ParamTypeObject,
 				}},
 			},
 			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `"array" type does not match default value's type: "string"`,
-			Paths:   []string{"taskspec.inputs.params.task.type", "taskspec.inputs.params.task.default.type"},
+			Message: `missing field(s)`,
+			Paths:   []string{"taskspec.inputs.params.repo.properties"},
 		},
 	}, {
-		name: "input mismatching default/type 2",
+		name: "object param with a non-string property",
 		fields: fields{
 			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{validResource},
 				Params: []v1alpha1.ParamSpec{{
-					Name:        "task",
-					Type:        v1alpha1.ParamTypeString,
-					Description: "param",
-					Default:     builder.ArrayOrString("default", "array"),
+					Name:       "repo",
+					Type:       v1alpha1.ParamTypeObject,
+					Properties: map[string]v1alpha1.PropertySpec{"urls": {Type: v1alpha1.ParamTypeArray}},
 				}},
 			},
 			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `"string" type does not match default value's type: "array"`,
-			Paths:   []string{"taskspec.inputs.params.task.type", "taskspec.inputs.params.task.default.type"},
+			Message: `invalid value: array`,
+			Paths:   []string{"taskspec.inputs.params.repo.properties.urls.type"},
 		},
 	}, {
-		name: "one invalid output",
+		name: "object param default missing a key",
 		fields: fields{
 			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{
-					validResource,
-				},
-			},
-			Outputs: &v1alpha1.Outputs{
-				Resources: []v1alpha1.TaskResource{
-					invalidResource,
-					validResource,
-				},
+				Params: []v1alpha1.ParamSpec{{
+					Name:       "repo",
+					Type:       v1alpha1.ParamTypeObject,
+					Properties: map[string]v1alpha1.PropertySpec{"url": {Type: v1alpha1.ParamTypeString}, "revision": {Type: v1alpha1.ParamTypeString}},
+					Default:    builder.ObjectValue(map[string]string{"url": "https://github.com/tektoncd/pipeline"}),
+				}},
 			},
 			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `invalid value: what`,
-			Paths:   []string{"taskspec.Outputs.Resources.source.Type"},
+			Message: `default of object param "repo" is missing keys revision`,
+			Paths:   []string{"taskspec.inputs.params.repo.default"},
 		},
 	}, {
-		name: "duplicated inputs",
+		name: "properties on a string param",
 		fields: fields{
 			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{
-					validResource,
-					validResource,
-				},
-			},
-			Outputs: &v1alpha1.Outputs{
-				Resources: []v1alpha1.TaskResource{
-					validResource,
-				},
+				Params: []v1alpha1.ParamSpec{{
+					Name:       "repo",
+					Type:       v1alpha1.ParamTypeString,
+					Properties: map[string]v1alpha1.PropertySpec{"url": {Type: v1alpha1.ParamTypeString}},
+				}},
 			},
 			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: "expected exactly one, got both",
-			Paths:   []string{"taskspec.Inputs.Resources.Name"},
+			Message: `must not set the field(s)`,
+			Paths:   []string{"taskspec.inputs.params.repo.properties"},
 		},
 	}, {
-		name: "duplicated outputs",
+		name: "object param referenced without a key",
 		fields: fields{
 			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{
-					validResource,
-				},
+				Params: []v1alpha1.ParamSpec{{
+					Name:       "repo",
+					Type:       v1alpha1.ParamTypeObject,
+					Properties: map[string]v1alpha1.PropertySpec{"url": {Type: v1alpha1.ParamTypeString}},
+				}},
 			},
-			Outputs: &v1alpha1.Outputs{
-				Resources: []v1alpha1.TaskResource{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Image: "myimage",
+				Args:  []string{"$(inputs.params.repo)"},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `object param "repo" must be referenced by key in "$(inputs.params.repo)" for step args[0]`,
+			Paths:   []string{"taskspec.steps.args[0]"},
+		},
+	}, {
+		name: "object param referenced by an undeclared key",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Params: []v1alpha1.ParamSpec{{
+					Name:       "repo",
+					Type:       v1alpha1.ParamTypeObject,
+					Properties: map[string]v1alpha1.PropertySpec{"url": {Type: v1alpha1.ParamTypeString}},
+				}},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Image: "myimage",
+				Env:   []corev1.EnvVar{{Name: "REVISION", Value: "$(inputs.params.repo.revision)"}},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent key "revision" of object param "repo" in "$(inputs.params.repo.revision)" for step env[REVISION]`,
+			Paths:   []string{"taskspec.steps.env[REVISION]"},
+		},
+	}, {
+		name: "no build",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+			},
+		},
+		expectedError: apis.FieldError{
+			Message: `missing field(s)`,
+			Paths:   []string{"steps"},
+		},
+	}, {
+		name: "one invalid input",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{
+					invalidResource,
+					validResource,
+				},
+			},
+			Outputs: &v1alpha1.Outputs{
+				Resources: []v1alpha1.TaskResource{
+					validResource,
+				},
+			},
+			Steps: validSteps,
+		},
+		// The invalid resource has the same name as the valid one.
+		expectedError: *apis.ErrInvalidValue("what", "taskspec.Inputs.Resources.source.Type").Also(
+			apis.ErrMultipleOneOf("taskspec.Inputs.Resources.Name"),
+		),
+	}, {
+		name: "invalid input type",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+				Params: []v1alpha1.ParamSpec{{
+					Name:        "validparam",
+					Type:        v1alpha1.ParamTypeString,
+					Description: "parameter",
+					Default:     builder.ArrayOrString("default"),
+				}, {
+					Name:        "param-with-invalid-type",
+					Type:        "invalidtype",
+					Description: "invalidtypedesc",
+					Default:     builder.ArrayOrString("default"),
+				}},
+			},
+			Steps: validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: invalidtype`,
+			Paths:   []string{"taskspec.inputs.params.param-with-invalid-type.type"},
+		},
+	}, {
+		name: "input mismatching default/type 1",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+				Params: []v1alpha1.ParamSpec{{
+					Name:        "task",
+					Type:        v1alpha1.ParamTypeArray,
+					Description: "param",
+					Default:     builder.ArrayOrString("default"),
+				}},
+			},
+			Steps: validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: `"array" type does not match default value's type: "string"`,
+			Paths:   []string{"taskspec.inputs.params.task.type", "taskspec.inputs.params.task.default.type"},
+		},
+	}, {
+		name: "input mismatching default/type 2",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+				Params: []v1alpha1.ParamSpec{{
+					Name:        "task",
+					Type:        v1alpha1.ParamTypeString,
+					Description: "param",
+					Default:     builder.ArrayOrString("default", "array"),
+				}},
+			},
+			Steps: validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: `"string" type does not match default value's type: "array"`,
+			Paths:   []string{"taskspec.inputs.params.task.type", "taskspec.inputs.params.task.default.type"},
+		},
+	}, {
+		name: "one invalid output",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{
+					validResource,
+				},
+			},
+			Outputs: &v1alpha1.Outputs{
+				Resources: []v1alpha1.TaskResource{
+					invalidResource,
+					validResource,
+				},
+			},
+			Steps: validSteps,
+		},
+		// The invalid resource has the same name as the valid one.
+		expectedError: *apis.ErrInvalidValue("what", "taskspec.Outputs.Resources.source.Type").Also(
+			apis.ErrMultipleOneOf("taskspec.Outputs.Resources.Name"),
+		),
+	}, {
+		name: "duplicated inputs",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{
+					validResource,
+					validResource,
+				},
+			},
+			Outputs: &v1alpha1.Outputs{
+				Resources: []v1alpha1.TaskResource{
+					validResource,
+				},
+			},
+			Steps: validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: "expected exactly one, got both",
+			Paths:   []string{"taskspec.Inputs.Resources.Name"},
+		},
+	}, {
+		name: "duplicated outputs",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{
+					validResource,
+				},
+			},
+			Outputs: &v1alpha1.Outputs{
+				Resources: []v1alpha1.TaskResource{
 					validResource,
 					validResource,
 				},
@@ -402,274 +858,968 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Paths:   []string{"steps"},
 		},
 	}, {
-		name: "invalid build step name",
+		name: "invalid build step name",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+			},
+			Steps: invalidSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value "replaceImage"`,
+			Paths:   []string{"taskspec.steps.name"},
+			Details: "Task step name must be a valid DNS Label, For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
+		},
+	}, {
+		name: "inexistent input param variable",
+		fields: fields{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+				Args:  []string{"--flag=$(inputs.params.inexistent)"},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable "inexistent" in "--flag=$(inputs.params.inexistent)" for step arg[0]`,
+			Paths:   []string{"taskspec.steps.arg[0]"},
+		},
+	}, {
+		name: "undeclared workspace",
+		fields: fields{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "first",
+				Image: "myimage",
+			}}, {
+				Container: corev1.Container{Name: "second", Image: "myimage"},
+				Script:    "#!/bin/sh\ncd $(workspaces.source.path)",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `undeclared workspace "source" in "#!/bin/sh\ncd $(workspaces.source.path)"`,
+			Paths:   []string{"steps[1].script"},
+		},
+	}, {
+		name: "undeclared result",
+		fields: fields{
+			InitSteps: []corev1.Container{{
+				Name:    "init",
+				Image:   "myimage",
+				Command: []string{"sh", "-c", "date > $(results.time.path)"},
+			}},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `undeclared result "time" in "date > $(results.time.path)"`,
+			Paths:   []string{"initSteps[0].command[2]"},
+		},
+	}, {
+		name: "undeclared result of a step",
+		fields: fields{
+			Results: []v1alpha1.TaskResult{{Name: "digest"}},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:    "mystep",
+				Image:   "myimage",
+				Command: []string{"sh", "-c", "date > $(results.time.path)"},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `undeclared result "time" in "date > $(results.time.path)"`,
+			Paths:   []string{"steps[0].command[2]"},
+		},
+	}, {
+		name: "invalid result name",
+		fields: fields{
+			Results: []v1alpha1.TaskResult{{Name: "digest"}, {Name: "image.digest"}},
+			Steps:   validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid result name "image.digest"`,
+			Paths:   []string{"results[1].name"},
+			Details: "Result names must consist of alphanumeric characters, '-' and '_', and start and end with an alphanumeric character",
+		},
+	}, {
+		name: "duplicate result name",
+		fields: fields{
+			Results: []v1alpha1.TaskResult{{Name: "digest"}, {Name: "digest"}},
+			Steps:   validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: `expected exactly one, got both`,
+			Paths:   []string{"results[1].name"},
+		},
+	}, {
+		name: "result variable without path",
+		fields: fields{
+			Results: []v1alpha1.TaskResult{{Name: "digest"}},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+				Args:  []string{"$(results.digest)"},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `$(results.digest) in "$(results.digest)" doesn't reference the path of a result`,
+			Paths:   []string{"steps[0].args[0]"},
+		},
+	}, {
+		name: "invalid workspace name",
+		fields: fields{
+			Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source_dir"}},
+			Steps:      validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value "source_dir"`,
+			Paths:   []string{"workspaces[0].name"},
+			Details: "Workspace names must be valid DNS Labels, For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
+		},
+	}, {
+		name: "duplicate workspace mount path",
+		fields: fields{
+			Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source"}, {Name: "other", MountPath: "/workspace/source/"}},
+			Steps:      validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: `expected exactly one, got both`,
+			Paths:   []string{"workspaces[1].mountPath"},
+		},
+	}, {
+		name: "relative workspace mount path",
+		fields: fields{
+			Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source", MountPath: "src"}},
+			Steps:      validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: src should be an absolute path`,
+			Paths:   []string{"workspaces[0].mountPath"},
+		},
+	}, {
+		name: "result named after a workspace checksum",
+		fields: fields{
+			Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source", Checksum: true}},
+			Results:    []v1alpha1.TaskResult{{Name: "source-checksum-end"}},
+			Steps:      validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: `result "source-checksum-end" is the checksum of workspace "source"`,
+			Paths:   []string{"results[0].name"},
+		},
+	}, {
+		name: "workspace variable without path",
+		fields: fields{
+			Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source"}},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+				Args:  []string{"$(workspaces.source.claim)"},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `$(workspaces.source.claim) in "$(workspaces.source.claim)" doesn't reference the path of a workspace`,
+			Paths:   []string{"steps[0].args[0]"},
+		},
+	}, {
+		name: "step variable of a later step",
+		fields: fields{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "first",
+				Image: "myimage",
+				Args:  []string{"$(steps.second.exitCode)"},
+			}}, {Container: corev1.Container{
+				Name:  "second",
+				Image: "myimage",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `$(steps.second.exitCode) in "$(steps.second.exitCode)" doesn't reference an earlier step`,
+			Paths:   []string{"steps[0].args[0]"},
+		},
+	}, {
+		name: "step variable of an unknown field",
+		fields: fields{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "first",
+				Image: "myimage",
+			}}, {Container: corev1.Container{
+				Name:  "second",
+				Image: "myimage",
+				Env:   []corev1.EnvVar{{Name: "DIGEST", Value: "$(steps.first.results.../digest)"}},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `$(steps.first.results.../digest) in "$(steps.first.results.../digest)" doesn't reference the exitCode or a result of step "first"`,
+			Paths:   []string{"steps[1].env[DIGEST]"},
+		},
+	}, {
+		name: "step variable in a script",
+		fields: fields{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "first",
+				Image: "myimage",
+			}}, {
+				Container: corev1.Container{Name: "second", Image: "myimage"},
+				Script:    "#!/bin/sh\nexit $(steps.first.exitCode)",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `$(steps.first.exitCode) in "#!/bin/sh\nexit $(steps.first.exitCode)" can only be used in the command, args and env of a step`,
+			Paths:   []string{"steps[1].script"},
+		},
+	}, {
+		name: "step variable in an init step",
+		fields: fields{
+			InitSteps: []corev1.Container{{
+				Name:  "init",
+				Image: "myimage",
+				Args:  []string{"$(steps.mystep.exitCode)"},
+			}},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `undeclared step "mystep" in "$(steps.mystep.exitCode)"`,
+			Paths:   []string{"initSteps[0].args[0]"},
+		},
+	}, {
+		name: "array used in unaccepted field",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validImageResource},
+				Params: []v1alpha1.ParamSpec{{
+					Name: "baz",
+					Type: v1alpha1.ParamTypeArray,
+				}, {
+					Name: "foo-is-baz",
+					Type: v1alpha1.ParamTypeArray,
+				}},
+			},
+			Outputs: &v1alpha1.Outputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:       "mystep",
+				Image:      "$(inputs.params.baz)",
+				Command:    []string{"$(inputs.param.foo-is-baz)"},
+				Args:       []string{"$(inputs.params.baz)", "middle string", "$(input.resources.foo.url)"},
+				WorkingDir: "/foo/bar/$(outputs.resources.source)",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `variable "baz" type invalid in "$(inputs.params.baz)" for step image`,
+			Paths:   []string{"taskspec.steps.image"},
+		},
+	}, {
+		name: "array used in envFrom prefix",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Params: []v1alpha1.ParamSpec{{
+					Name: "baz",
+					Type: v1alpha1.ParamTypeArray,
+				}},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+				EnvFrom: []corev1.EnvFromSource{{
+					Prefix:       "$(inputs.params.baz)_",
+					ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}},
+				}},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `variable "baz" type invalid in "$(inputs.params.baz)_" for step envFrom[0].prefix`,
+			Paths:   []string{"taskspec.steps.envFrom[0].prefix"},
+		},
+	}, {
+		name: "inexistent param in envFrom",
+		fields: fields{
+			StepTemplate: &corev1.Container{
+				EnvFrom: []corev1.EnvFromSource{{
+					SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "$(inputs.params.inexistent)"}},
+				}},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable "inexistent" in "$(inputs.params.inexistent)" for step envFrom[0].secretRef.name`,
+			Paths:   []string{"taskspec.steps.envFrom[0].secretRef.name"},
+		},
+	}, {
+		name: "envFrom without source",
+		fields: fields{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:    "mystep",
+				Image:   "myimage",
+				EnvFrom: []corev1.EnvFromSource{{Prefix: "APP_"}},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: "expected exactly one, got neither",
+			Paths:   []string{"steps[0].envFrom.configMapRef", "steps[0].envFrom.secretRef"},
+		},
+	}, {
+		name: "array not properly isolated",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validImageResource},
+				Params: []v1alpha1.ParamSpec{{
+					Name: "baz",
+					Type: v1alpha1.ParamTypeArray,
+				}, {
+					Name: "foo-is-baz",
+					Type: v1alpha1.ParamTypeArray,
+				}},
+			},
+			Outputs: &v1alpha1.Outputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:       "mystep",
+				Image:      "someimage",
+				Command:    []string{"$(inputs.param.foo-is-baz)"},
+				Args:       []string{"not isolated: $(inputs.params.baz)", "middle string", "$(input.resources.foo.url)"},
+				WorkingDir: "/foo/bar/$(outputs.resources.source)",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `variable "baz" is not properly isolated in "not isolated: $(inputs.params.baz)" for step arg[0]`,
+			Paths:   []string{"taskspec.steps.arg[0]"},
+		},
+	}, {
+		name: "array not properly isolated",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validImageResource},
+				Params: []v1alpha1.ParamSpec{{
+					Name: "baz",
+					Type: v1alpha1.ParamTypeArray,
+				}, {
+					Name: "foo-is-baz",
+					Type: v1alpha1.ParamTypeArray,
+				}},
+			},
+			Outputs: &v1alpha1.Outputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:       "mystep",
+				Image:      "someimage",
+				Command:    []string{"$(inputs.param.foo-is-baz)"},
+				Args:       []string{"not isolated: $(inputs.params.baz)", "middle string", "$(input.resources.foo.url)"},
+				WorkingDir: "/foo/bar/$(outputs.resources.source)",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `variable "baz" is not properly isolated in "not isolated: $(inputs.params.baz)" for step arg[0]`,
+			Paths:   []string{"taskspec.steps.arg[0]"},
+		},
+	}, {
+		name: "inexistent input resource variable",
+		fields: fields{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage:$(inputs.resources.inputs)",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable "inputs" in "myimage:$(inputs.resources.inputs)" for step image`,
+			Paths:   []string{"taskspec.steps.image"},
+		},
+	}, {
+		name: "inferred array not properly isolated",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validImageResource},
+				Params: []v1alpha1.ParamSpec{{
+					Name: "baz",
+					Default: &v1alpha1.ArrayOrString{
+						Type:     v1alpha1.ParamTypeArray,
+						ArrayVal: []string{"implied", "array", "type"},
+					},
+				}, {
+					Name: "foo-is-baz",
+					Default: &v1alpha1.ArrayOrString{
+						Type:     v1alpha1.ParamTypeArray,
+						ArrayVal: []string{"implied", "array", "type"},
+					},
+				}},
+			},
+			Outputs: &v1alpha1.Outputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:       "mystep",
+				Image:      "someimage",
+				Command:    []string{"$(inputs.param.foo-is-baz)"},
+				Args:       []string{"not isolated: $(inputs.params.baz)", "middle string", "$(input.resources.foo.url)"},
+				WorkingDir: "/foo/bar/$(outputs.resources.source)",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `variable "baz" is not properly isolated in "not isolated: $(inputs.params.baz)" for step arg[0]`,
+			Paths:   []string{"taskspec.steps.arg[0]"},
+		},
+	}, {
+		name: "inexistent input resource variable",
+		fields: fields{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage:$(inputs.resources.inputs)",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable "inputs" in "myimage:$(inputs.resources.inputs)" for step image`,
+			Paths:   []string{"taskspec.steps.image"},
+		},
+	}, {
+		name: "output resource referenced as an input",
+		fields: fields{
+			Outputs: &v1alpha1.Outputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:       "mystep",
+				Image:      "myimage",
+				WorkingDir: "$(inputs.resources.source.path)",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable "source" in "$(inputs.resources.source.path)" for step workingDir`,
+			Paths:   []string{"taskspec.steps.workingDir"},
+		},
+	}, {
+		name: "invalid resource attribute variable",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validResource},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+				Args:  []string{"--digest=$(inputs.resources.source.digest)"},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent attribute "digest" of resource "source" in "--digest=$(inputs.resources.source.digest)" for step arg[0]`,
+			Paths:   []string{"taskspec.steps.arg[0]"},
+		},
+	}, {
+		name: "inexistent output param variable",
+		fields: fields{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:       "mystep",
+				Image:      "myimage",
+				WorkingDir: "/foo/bar/$(outputs.resources.inexistent)",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable "inexistent" in "/foo/bar/$(outputs.resources.inexistent)" for step workingDir`,
+			Paths:   []string{"taskspec.steps.workingDir"},
+		},
+	}, {
+		name: "Inexistent param variable with existing",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Params: []v1alpha1.ParamSpec{
+					{
+						Name:        "foo",
+						Description: "param",
+						Default:     builder.ArrayOrString("default"),
+					},
+				},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+				Args:  []string{"$(inputs.params.foo) && $(inputs.params.inexistent)"},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable "inexistent" in "$(inputs.params.foo) && $(inputs.params.inexistent)" for step arg[0]`,
+			Paths:   []string{"taskspec.steps.arg[0]"},
+		},
+	}, {
+		name: "Multiple volumes with same name",
+		fields: fields{
+			Steps: validSteps,
+			Volumes: []corev1.Volume{{
+				Name: "workspace",
+			}, {
+				Name: "workspace",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `multiple volumes with same name "workspace"`,
+			Paths:   []string{"volumes.name"},
+		},
+	}, {
+		name: "step with script and args",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Image: "myimage",
+					Args:  []string{"arg"},
+				},
+				Script: "#!/bin/sh\nscript",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "script cannot be used with args or command",
+			Paths:   []string{"steps[0].script"},
+		},
+	}, {
+		name: "step with script without shebang",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				Script: "does not begin with shebang",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "script must start with a shebang (#!)",
+			Paths:   []string{"steps[0].script"},
+		},
+	}, {
+		name: "step with script and command",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Image:   "myimage",
+					Command: []string{"command"},
+				},
+				Script: "#!/bin/sh\nscript",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "script cannot be used with args or command",
+			Paths:   []string{"steps[0].script"},
+		},
+	}, {
+		name: "step with invalid skip exit code",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Image: "myimage",
+				},
+				SkipExitCodes: []int32{0},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "expected 1 <= 0 <= 255",
+			Paths:   []string{"steps[0].skipExitCodes"},
+		},
+	}, {
+		name: "step with invalid onError",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Image: "myimage",
+				},
+				OnError: "ignore",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: ignore",
+			Paths:   []string{"steps[0].onError"},
+		},
+	}, {
+		name: "step with negative timeout",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{
+					Image: "myimage",
+				},
+				Timeout: &metav1.Duration{Duration: -time.Minute},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: -1m0s should be >= 0",
+			Paths:   []string{"steps[0].timeout"},
+		},
+	}, {
+		name: "secret ref without provider",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container:  corev1.Container{Image: "myimage"},
+				SecretRefs: []v1alpha1.SecretRef{{Key: "secret/data/ci#token", Path: "token"}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"steps[0].secretRefs.provider"},
+		},
+	}, {
+		name: "secret ref path outside the secrets directory",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container:  corev1.Container{Image: "myimage"},
+				SecretRefs: []v1alpha1.SecretRef{{Provider: "vault", Key: "secret/data/ci#token", Path: "../token"}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: ../token",
+			Paths:   []string{"steps[0].secretRefs.path"},
+		},
+	}, {
+		name: "secret ref paths not unique across steps",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container:  corev1.Container{Image: "myimage"},
+				SecretRefs: []v1alpha1.SecretRef{{Provider: "vault", Key: "secret/data/ci#token", Path: "token"}},
+			}, {
+				Container:  corev1.Container{Image: "myimage"},
+				SecretRefs: []v1alpha1.SecretRef{{Provider: "vault", Key: "secret/data/other#token", Path: "./token"}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: ./token",
+			Paths:   []string{"steps[1].secretRefs.path"},
+		},
+	}, {
+		name: "steps without image",
+		fields: fields{
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{Name: "build"},
+			}, {
+				Container: corev1.Container{Name: "test"},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"steps[0].Image", "steps[1].Image"},
+		},
+	}, {
+		name: "step with script without shebang and a step template",
+		fields: fields{
+			StepTemplate: envStepTemplate,
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{Image: "my-image"},
+				Script:    "does not begin with shebang",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "script must start with a shebang (#!)",
+			Paths:   []string{"steps[0].script"},
+		},
+	}, {
+		name: "step with invalid skip exit code and a step template",
+		fields: fields{
+			StepTemplate: envStepTemplate,
+			Steps: []v1alpha1.Step{{
+				Container:     corev1.Container{Image: "myimage"},
+				SkipExitCodes: []int32{999},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "expected 1 <= 999 <= 255",
+			Paths:   []string{"steps[0].skipExitCodes"},
+		},
+	}, {
+		name: "step with invalid onError and a step template",
+		fields: fields{
+			StepTemplate: envStepTemplate,
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{Image: "myimage"},
+				OnError:   "bogus",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: bogus",
+			Paths:   []string{"steps[0].onError"},
+		},
+	}, {
+		name: "step with negative timeout and a step template",
+		fields: fields{
+			StepTemplate: envStepTemplate,
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{Image: "myimage"},
+				Timeout:   &metav1.Duration{Duration: -time.Minute},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: -1m0s should be >= 0",
+			Paths:   []string{"steps[0].timeout"},
+		},
+	}, {
+		name: "secret ref without provider and a step template",
+		fields: fields{
+			StepTemplate: envStepTemplate,
+			Steps: []v1alpha1.Step{{
+				Container:  corev1.Container{Image: "myimage"},
+				SecretRefs: []v1alpha1.SecretRef{{Key: "secret/data/ci#token", Path: "token"}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"steps[0].secretRefs.provider"},
+		},
+	}, {
+		name: "unnamed init step",
+		fields: fields{
+			InitSteps: []corev1.Container{{Image: "myimage"}},
+			Steps:     validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"initSteps.name"},
+		},
+	}, {
+		name: "duplicate init step names",
+		fields: fields{
+			InitSteps: []corev1.Container{{Name: "setup", Image: "myimage"}, {Name: "setup", Image: "myimage"}},
+			Steps:     validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: setup",
+			Paths:   []string{"initSteps.name"},
+		},
+	}, {
+		name: "init step without image",
+		fields: fields{
+			InitSteps: []corev1.Container{{Name: "setup"}},
+			Steps:     validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"initSteps.image"},
+		},
+	}, {
+		name: "init step using undeclared param",
 		fields: fields{
-			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{validResource},
-			},
-			Steps: invalidSteps,
+			InitSteps: []corev1.Container{{Name: "setup", Image: "myimage", Args: []string{"$(inputs.params.inexistent)"}}},
+			Steps:     validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `invalid value "replaceImage"`,
-			Paths:   []string{"taskspec.steps.name"},
-			Details: "Task step name must be a valid DNS Label, For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
+			Message: `non-existent variable "inexistent" in "$(inputs.params.inexistent)" for step arg[0]`,
+			Paths:   []string{"taskspec.steps.arg[0]"},
 		},
 	}, {
-		name: "inexistent input param variable",
+		name: "step referencing a step action with an image",
 		fields: fields{
-			Steps: []v1alpha1.Step{{Container: corev1.Container{
-				Name:  "mystep",
-				Image: "myimage",
-				Args:  []string{"--flag=$(inputs.params.inexistent)"},
-			}}},
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{Image: "myimage"},
+				Ref:       &v1alpha1.StepActionRef{Name: "go-build"},
+			}},
 		},
 		expectedError: apis.FieldError{
-			Message: `non-existent variable in "--flag=$(inputs.params.inexistent)" for step arg[0]`,
-			Paths:   []string{"taskspec.steps.arg[0]"},
+			Message: "expected exactly one, got both",
+			Paths:   []string{"steps[0].ref", "steps[0].image", "steps[0].command", "steps[0].args", "steps[0].script"},
 		},
 	}, {
-		name: "array used in unaccepted field",
+		name: "step action params without reference",
 		fields: fields{
-			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{validImageResource},
-				Params: []v1alpha1.ParamSpec{{
-					Name: "baz",
-					Type: v1alpha1.ParamTypeArray,
-				}, {
-					Name: "foo-is-baz",
-					Type: v1alpha1.ParamTypeArray,
-				}},
-			},
-			Outputs: &v1alpha1.Outputs{
-				Resources: []v1alpha1.TaskResource{validResource},
-			},
-			Steps: []v1alpha1.Step{{Container: corev1.Container{
-				Name:       "mystep",
-				Image:      "$(inputs.params.baz)",
-				Command:    []string{"$(inputs.param.foo-is-baz)"},
-				Args:       []string{"$(inputs.params.baz)", "middle string", "$(input.resources.foo.url)"},
-				WorkingDir: "/foo/bar/$(outputs.resources.source)",
-			}}},
+			Steps: []v1alpha1.Step{{
+				Container: corev1.Container{Image: "myimage"},
+				Params:    []v1alpha1.Param{{Name: "package", Value: *builder.ArrayOrString("./...")}},
+			}},
 		},
 		expectedError: apis.FieldError{
-			Message: `variable type invalid in "$(inputs.params.baz)" for step image`,
-			Paths:   []string{"taskspec.steps.image"},
+			Message: "must not set the field(s)",
+			Paths:   []string{"steps[0].params"},
 		},
 	}, {
-		name: "array not properly isolated",
+		name: "step action param using undeclared param",
 		fields: fields{
-			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{validImageResource},
-				Params: []v1alpha1.ParamSpec{{
-					Name: "baz",
-					Type: v1alpha1.ParamTypeArray,
-				}, {
-					Name: "foo-is-baz",
-					Type: v1alpha1.ParamTypeArray,
-				}},
-			},
-			Outputs: &v1alpha1.Outputs{
-				Resources: []v1alpha1.TaskResource{validResource},
-			},
-			Steps: []v1alpha1.Step{{Container: corev1.Container{
-				Name:       "mystep",
-				Image:      "someimage",
-				Command:    []string{"$(inputs.param.foo-is-baz)"},
-				Args:       []string{"not isolated: $(inputs.params.baz)", "middle string", "$(input.resources.foo.url)"},
-				WorkingDir: "/foo/bar/$(outputs.resources.source)",
-			}}},
+			Steps: []v1alpha1.Step{{
+				Ref:    &v1alpha1.StepActionRef{Name: "go-build"},
+				Params: []v1alpha1.Param{{Name: "package", Value: *builder.ArrayOrString("$(inputs.params.inexistent)")}},
+			}},
 		},
 		expectedError: apis.FieldError{
-			Message: `variable is not properly isolated in "not isolated: $(inputs.params.baz)" for step arg[0]`,
-			Paths:   []string{"taskspec.steps.arg[0]"},
+			Message: `non-existent variable "inexistent" in "$(inputs.params.inexistent)" for step params[package]`,
+			Paths:   []string{"taskspec.steps.params[package]"},
 		},
 	}, {
-		name: "array not properly isolated",
+		name: "checkout without repo",
 		fields: fields{
-			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{validImageResource},
-				Params: []v1alpha1.ParamSpec{{
-					Name: "baz",
-					Type: v1alpha1.ParamTypeArray,
-				}, {
-					Name: "foo-is-baz",
-					Type: v1alpha1.ParamTypeArray,
-				}},
-			},
-			Outputs: &v1alpha1.Outputs{
-				Resources: []v1alpha1.TaskResource{validResource},
-			},
-			Steps: []v1alpha1.Step{{Container: corev1.Container{
-				Name:       "mystep",
-				Image:      "someimage",
-				Command:    []string{"$(inputs.param.foo-is-baz)"},
-				Args:       []string{"not isolated: $(inputs.params.baz)", "middle string", "$(input.resources.foo.url)"},
-				WorkingDir: "/foo/bar/$(outputs.resources.source)",
-			}}},
+			Checkout: &v1alpha1.Checkout{Revision: "master"},
+			Steps:    validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `variable is not properly isolated in "not isolated: $(inputs.params.baz)" for step arg[0]`,
-			Paths:   []string{"taskspec.steps.arg[0]"},
+			Message: "missing field(s)",
+			Paths:   []string{"checkout.repo"},
 		},
 	}, {
-		name: "inexistent input resource variable",
+		name: "checkout outside of the workspace",
 		fields: fields{
-			Steps: []v1alpha1.Step{{Container: corev1.Container{
-				Name:  "mystep",
-				Image: "myimage:$(inputs.resources.inputs)",
-			}}},
+			Checkout: &v1alpha1.Checkout{Repo: "https://github.com/tektoncd/pipeline", Workspace: "../src"},
+			Steps:    validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `non-existent variable in "myimage:$(inputs.resources.inputs)" for step image`,
-			Paths:   []string{"taskspec.steps.image"},
+			Message: "invalid value: ../src should be a relative path under /workspace",
+			Paths:   []string{"checkout.workspace"},
 		},
 	}, {
-		name: "inferred array not properly isolated",
+		name: "step ephemeral-storage request greater than its limit",
 		fields: fields{
-			Inputs: &v1alpha1.Inputs{
-				Resources: []v1alpha1.TaskResource{validImageResource},
-				Params: []v1alpha1.ParamSpec{{
-					Name: "baz",
-					Default: &v1alpha1.ArrayOrString{
-						Type:     v1alpha1.ParamTypeArray,
-						ArrayVal: []string{"implied", "array", "type"},
-					},
-				}, {
-					Name: "foo-is-baz",
-					Default: &v1alpha1.ArrayOrString{
-						Type:     v1alpha1.ParamTypeArray,
-						ArrayVal: []string{"implied", "array", "type"},
-					},
-				}},
-			},
-			Outputs: &v1alpha1.Outputs{
-				Resources: []v1alpha1.TaskResource{validResource},
-			},
 			Steps: []v1alpha1.Step{{Container: corev1.Container{
-				Name:       "mystep",
-				Image:      "someimage",
-				Command:    []string{"$(inputs.param.foo-is-baz)"},
-				Args:       []string{"not isolated: $(inputs.params.baz)", "middle string", "$(input.resources.foo.url)"},
-				WorkingDir: "/foo/bar/$(outputs.resources.source)",
+				Image: "myimage",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("2Gi")},
+					Limits:   corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+				},
 			}}},
 		},
 		expectedError: apis.FieldError{
-			Message: `variable is not properly isolated in "not isolated: $(inputs.params.baz)" for step arg[0]`,
-			Paths:   []string{"taskspec.steps.arg[0]"},
+			Message: "ephemeral-storage request 2Gi must be less than or equal to its limit 1Gi",
+			Paths:   []string{"steps[0].resources.requests.ephemeral-storage"},
 		},
 	}, {
-		name: "inexistent input resource variable",
+		name: "init step negative ephemeral-storage limit",
 		fields: fields{
-			Steps: []v1alpha1.Step{{Container: corev1.Container{
-				Name:  "mystep",
-				Image: "myimage:$(inputs.resources.inputs)",
-			}}},
+			InitSteps: []corev1.Container{{
+				Name:  "setup",
+				Image: "myimage",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("-1Gi")},
+				},
+			}},
+			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `non-existent variable in "myimage:$(inputs.resources.inputs)" for step image`,
-			Paths:   []string{"taskspec.steps.image"},
+			Message: "invalid value: -1Gi",
+			Paths:   []string{"initSteps.resources.limits.ephemeral-storage"},
 		},
 	}, {
-		name: "inexistent output param variable",
+		name: "sidecar negative ephemeral-storage request",
 		fields: fields{
-			Steps: []v1alpha1.Step{{Container: corev1.Container{
-				Name:       "mystep",
-				Image:      "myimage",
-				WorkingDir: "/foo/bar/$(outputs.resources.inexistent)",
-			}}},
+			Sidecars: []corev1.Container{{
+				Name:  "cache",
+				Image: "myimage",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("-1Gi")},
+				},
+			}},
+			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `non-existent variable in "/foo/bar/$(outputs.resources.inexistent)" for step workingDir`,
-			Paths:   []string{"taskspec.steps.workingDir"},
+			Message: "invalid value: -1Gi",
+			Paths:   []string{"sidecars[0].resources.requests.ephemeral-storage"},
 		},
 	}, {
-		name: "Inexistent param variable with existing",
+		name: "sidecar without image",
 		fields: fields{
-			Inputs: &v1alpha1.Inputs{
-				Params: []v1alpha1.ParamSpec{
-					{
-						Name:        "foo",
-						Description: "param",
-						Default:     builder.ArrayOrString("default"),
-					},
-				},
-			},
-			Steps: []v1alpha1.Step{{Container: corev1.Container{
-				Name:  "mystep",
-				Image: "myimage",
-				Args:  []string{"$(inputs.params.foo) && $(inputs.params.inexistent)"},
-			}}},
+			Sidecars: []corev1.Container{{Name: "server"}},
+			Steps:    validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `non-existent variable in "$(inputs.params.foo) && $(inputs.params.inexistent)" for step arg[0]`,
-			Paths:   []string{"taskspec.steps.arg[0]"},
+			Message: "missing field(s)",
+			Paths:   []string{"sidecars[0].image"},
 		},
 	}, {
-		name: "Multiple volumes with same name",
+		name: "sidecar named like a step",
 		fields: fields{
-			Steps: validSteps,
-			Volumes: []corev1.Volume{{
-				Name: "workspace",
-			}, {
-				Name: "workspace",
-			}},
+			Sidecars: []corev1.Container{{Name: "step-server", Image: "myimage"}},
+			Steps:    validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: `multiple volumes with same name "workspace"`,
-			Paths:   []string{"volumes.name"},
+			Message: "invalid value: step-server",
+			Paths:   []string{"sidecars[0].name"},
 		},
 	}, {
-		name: "step with script and args",
+		name: "sidecar with the workspace of the steps",
 		fields: fields{
-			Steps: []v1alpha1.Step{{
-				Container: corev1.Container{
-					Image: "myimage",
-					Args:  []string{"arg"},
-				},
-				Script: "script",
+			Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source"}},
+			Sidecars: []corev1.Container{{
+				Name:       "server",
+				Image:      "myimage",
+				WorkingDir: "$(workspaces.source.path)",
 			}},
+			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: "script cannot be used with args or command",
-			Paths:   []string{"steps.script"},
+			Message: `undeclared workspace "source" in "$(workspaces.source.path)"`,
+			Paths:   []string{"sidecars[0].workingDir"},
 		},
 	}, {
-		name: "step with script without shebang",
+		name: "undeclared param in sidecar",
 		fields: fields{
-			Steps: []v1alpha1.Step{{
-				Container: corev1.Container{
-					Image: "my-image",
-				},
-				Script: "does not begin with shebang",
+			Sidecars: []corev1.Container{{
+				Name:  "server",
+				Image: "$(inputs.params.image)",
 			}},
+			Steps: validSteps,
 		},
 		expectedError: apis.FieldError{
-			Message: "script must start with a shebang (#!)",
-			Paths:   []string{"steps.script"},
+			Message: `non-existent variable "image" in "$(inputs.params.image)" for step image`,
+			Paths:   []string{"taskspec.steps.image"},
 		},
 	}, {
-		name: "step with script and command",
+		name: "every invalid field is reported",
 		fields: fields{
 			Steps: []v1alpha1.Step{{
 				Container: corev1.Container{
-					Image:   "myimage",
-					Command: []string{"command"},
+					Image: "$(inputs.params.image)",
+					Args:  []string{"$(workspaces.source.path)"},
 				},
-				Script: "script",
+			}, {
+				Container: corev1.Container{Name: "no-image"},
 			}},
 		},
-		expectedError: apis.FieldError{
-			Message: "script cannot be used with args or command",
-			Paths:   []string{"steps.script"},
+		expectedError: *apis.ErrMissingField("steps[1].Image").Also(
+			&apis.FieldError{
+				Message: `non-existent variable "image" in "$(inputs.params.image)" for step image`,
+				Paths:   []string{"taskspec.steps.image"},
+			},
+			&apis.FieldError{
+				Message: `undeclared workspace "source" in "$(workspaces.source.path)"`,
+				Paths:   []string{"steps[0].args[0]"},
+			},
+		),
+	}, {
+		name: "volume, param, resource and sidecar errors are reported together",
+		fields: fields{
+			Inputs: &v1alpha1.Inputs{
+				Resources: []v1alpha1.TaskResource{validResource, validResource},
+				Params: []v1alpha1.ParamSpec{{
+					Name: "param-with-invalid-type",
+					Type: "invalidtype",
+				}},
+			},
+			Steps: validSteps,
+			Volumes: []corev1.Volume{{
+				Name: "workspace",
+			}, {
+				Name: "workspace",
+			}},
+			Sidecars: []corev1.Container{{
+				Name: "no-image",
+			}, {
+				Image: "myimage",
+			}},
 		},
+		expectedError: *(&apis.FieldError{
+			Message: `multiple volumes with same name "workspace"`,
+			Paths:   []string{"volumes.name"},
+		}).Also(
+			apis.ErrMissingField("sidecars[0].image"),
+			apis.ErrMissingField("sidecars[1].name"),
+			apis.ErrMultipleOneOf("taskspec.Inputs.Resources.Name"),
+			apis.ErrInvalidValue("invalidtype", "taskspec.inputs.params.param-with-invalid-type.type"),
+		),
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ts := &v1alpha1.TaskSpec{
-				Inputs:  tt.fields.Inputs,
-				Outputs: tt.fields.Outputs,
-				Steps:   tt.fields.Steps,
-				Volumes: tt.fields.Volumes,
+				Inputs:       tt.fields.Inputs,
+				Outputs:      tt.fields.Outputs,
+				Steps:        tt.fields.Steps,
+				StepTemplate: tt.fields.StepTemplate,
+				Volumes:      tt.fields.Volumes,
+				InitSteps:    tt.fields.InitSteps,
+				Sidecars:     tt.fields.Sidecars,
+				Checkout:     tt.fields.Checkout,
+				Results:      tt.fields.Results,
+				Workspaces:   tt.fields.Workspaces,
 			}
 			ctx := context.Background()
 			ts.SetDefaults(ctx)
@@ -677,9 +1827,181 @@ func TestTaskSpecValidateError(t *testing.T) {
 			if err == nil {
 				t.Fatalf("Expected an error, got nothing for %v", ts)
 			}
-			if d := cmp.Diff(tt.expectedError, *err, cmpopts.IgnoreUnexported(apis.FieldError{})); d != "" {
+			if d := cmp.Diff(tt.expectedError.Error(), err.Error()); d != "" {
 				t.Errorf("TaskSpec.Validate() errors diff -want, +got: %v", d)
 			}
 		})
 	}
 }
+
+func TestTaskSpecValidate_StrictStepTemplate(t *testing.T) {
+	ts := func() *v1alpha1.TaskSpec {
+		return &v1alpha1.TaskSpec{
+			StepTemplate: &corev1.Container{
+				Command: []string{"/bin/sh", "-c"},
+				Env:     []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+			},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "build",
+				Image: "myimage",
+				Env:   []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+			}}, {Container: corev1.Container{
+				Name:    "test",
+				Image:   "myimage",
+				Command: []string{"/bin/bash", "-c"},
+			}}},
+		}
+	}
+
+	if err := ts().Validate(context.Background()); err != nil {
+		t.Errorf("Expected the step template to be overridden silently, got %v", err)
+	}
+
+	strict := config.ToContext(context.Background(), &config.Config{
+		Defaults: &config.Defaults{StrictStepTemplate: true},
+	})
+	err := ts().Validate(strict)
+	expectedError := &apis.FieldError{
+		Message: "step overrides the value of the stepTemplate",
+		Paths:   []string{"steps[1].command"},
+		Details: `stepTemplate: ["/bin/sh","-c"], step: ["/bin/bash","-c"]`,
+	}
+	if err == nil {
+		t.Fatal("Expected an error overriding the command of the step template")
+	}
+	if d := cmp.Diff(expectedError.Error(), err.Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() error diff -want, +got: %s", d)
+	}
+}
+
+func TestTaskSpecValidate_Descriptions(t *testing.T) {
+	ts := &v1alpha1.TaskSpec{
+		DisplayName: "Build and push",
+		Description: "Builds the image and pushes it.",
+		Steps: []v1alpha1.Step{{
+			Container:   corev1.Container{Name: "build", Image: "myimage"},
+			DisplayName: "Build the image",
+			Description: strings.Repeat("é", 1024),
+		}},
+	}
+	if err := ts.Validate(context.Background()); err != nil {
+		t.Errorf("TaskSpec.Validate() = %v", err)
+	}
+
+	ts.DisplayName = "Build\nand push"
+	ts.Steps[0].DisplayName = strings.Repeat("a", 65)
+	ts.Steps[0].Description = strings.Repeat("é", 1025)
+	err := ts.Validate(context.Background())
+	expectedError := (&apis.FieldError{
+		Message: "invalid value: must be a single line",
+		Paths:   []string{"displayName"},
+	}).Also(&apis.FieldError{
+		Message: "invalid value: 65 characters, must be no more than 64",
+		Paths:   []string{"steps[0].displayName"},
+	}).Also(&apis.FieldError{
+		Message: "invalid value: 1025 characters, must be no more than 1024",
+		Paths:   []string{"steps[0].description"},
+	})
+	if err == nil {
+		t.Fatal("Expected an error for the too long display name and description")
+	}
+	if d := cmp.Diff(expectedError.Error(), err.Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() error diff -want, +got: %s", d)
+	}
+}
+
+func TestTaskSpecValidate_Requires(t *testing.T) {
+	ts := &v1alpha1.TaskSpec{
+		Requires: []string{"gpu", "dind"},
+		Steps:    []v1alpha1.Step{{Container: corev1.Container{Name: "build", Image: "myimage"}}},
+	}
+	if err := ts.Validate(context.Background()); err != nil {
+		t.Errorf("TaskSpec.Validate() = %v", err)
+	}
+
+	ts.Requires = []string{"gpu", "Large_Disk", "gpu"}
+	err := ts.Validate(context.Background())
+	expectedError := (&apis.FieldError{
+		Message: `invalid value "Large_Disk"`,
+		Paths:   []string{"requires[1]"},
+		Details: "a DNS-1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')",
+	}).Also(&apis.FieldError{
+		Message: `duplicate capability "gpu"`,
+		Paths:   []string{"requires[2]"},
+	})
+	if err == nil {
+		t.Fatal("Expected an error for the invalid and duplicate capabilities")
+	}
+	if d := cmp.Diff(expectedError.Error(), err.Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() error diff -want, +got: %s", d)
+	}
+}
+
+func TestTaskSpecValidate_ImagePolicy(t *testing.T) {
+	const digest = "sha256:7f2e9d6c8a1b3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f"
+	ts := &v1alpha1.TaskSpec{
+		Inputs: &v1alpha1.Inputs{Params: []v1alpha1.ParamSpec{{Name: "image", Type: v1alpha1.ParamTypeString}}},
+		Steps: []v1alpha1.Step{{Container: corev1.Container{
+			Name:  "pinned",
+			Image: "gcr.io/build/builder@" + digest,
+		}}, {Container: corev1.Container{
+			Name:  "tagged",
+			Image: "gcr.io/build/builder:v1",
+		}}, {Container: corev1.Container{
+			Name:  "hub",
+			Image: "ubuntu@" + digest,
+		}}, {Container: corev1.Container{
+			Name:  "untrusted",
+			Image: "quay.io/untrusted/tool@" + digest,
+		}}, {Container: corev1.Container{
+			Name:  "variable",
+			Image: "$(inputs.params.image)",
+		}}, {Container: corev1.Container{
+			Name:  "untrusted-variable",
+			Image: "quay.io/untrusted/$(inputs.params.image)",
+		}}, {Container: corev1.Container{
+			Name:  "trusted-variable",
+			Image: "quay.io/untrusted-not/$(inputs.params.image)",
+		}}},
+		Sidecars: []corev1.Container{{Name: "db", Image: "postgres:12"}},
+	}
+
+	if err := ts.Validate(context.Background()); err != nil {
+		t.Errorf("Expected the images not to be checked by default, got %v", err)
+	}
+
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Defaults: &config.Defaults{
+			RequireImageDigests:   true,
+			BannedImageRegistries: []string{"docker.io", "quay.io/untrusted"},
+		},
+	})
+	err := ts.Validate(ctx)
+	expectedError := (&apis.FieldError{
+		Message: `image "gcr.io/build/builder:v1" isn't pinned to a digest`,
+		Paths:   []string{"steps[1].image"},
+		Details: "The images must be referenced by digest, e.g. ubuntu@sha256:<digest>",
+	}).Also(&apis.FieldError{
+		Message: `image "ubuntu@` + digest + `" comes from the banned registry "docker.io"`,
+		Paths:   []string{"steps[2].image"},
+	}).Also(&apis.FieldError{
+		Message: `image "quay.io/untrusted/tool@` + digest + `" comes from the banned registry "quay.io/untrusted"`,
+		Paths:   []string{"steps[3].image"},
+	}).Also(&apis.FieldError{
+		Message: `image "quay.io/untrusted/$(inputs.params.image)" comes from the banned registry "quay.io/untrusted"`,
+		Paths:   []string{"steps[5].image"},
+	}).Also(&apis.FieldError{
+		Message: `image "postgres:12" comes from the banned registry "docker.io"`,
+		Paths:   []string{"sidecars[0].image"},
+	}).Also(&apis.FieldError{
+		Message: `image "postgres:12" isn't pinned to a digest`,
+		Paths:   []string{"sidecars[0].image"},
+		Details: "The images must be referenced by digest, e.g. ubuntu@sha256:<digest>",
+	})
+	if err == nil {
+		t.Fatal("Expected an error for the images breaking the policy")
+	}
+	if d := cmp.Diff(expectedError.Error(), err.Error()); d != "" {
+		t.Errorf("TaskSpec.Validate() error diff -want, +got: %s", d)
+	}
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"knative.dev/pkg/apis"
+)
+
+// checksumRegexp matches the checksums of definitions, see config.Checksum.
+var checksumRegexp = regexp.MustCompile("^" + regexp.QuoteMeta(config.ChecksumPrefix) + "[0-9a-f]{64}$")
+
+// Validate checks that the checksum of the TaskRef, if any, is a SHA-256
+// checksum.
+func (tr *TaskRef) Validate(ctx context.Context) *apis.FieldError {
+	return validateChecksum(tr.Checksum)
+}
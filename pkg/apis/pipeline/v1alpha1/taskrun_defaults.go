@@ -22,10 +22,38 @@ import (
 
 	"github.com/tektoncd/pipeline/pkg/apis/config"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
 )
 
 func (tr *TaskRun) SetDefaults(ctx context.Context) {
 	tr.Spec.SetDefaults(ctx)
+	setCreatorGroups(ctx, &tr.ObjectMeta)
+	if tr.Spec.TaskSpec != nil {
+		tr.Spec.TaskSpec.SetDefaultResourceRequirements(config.FromContextOrDefaults(ctx).Defaults.ContainerResourceRequirements(tr.Namespace))
+	}
+
+	// Only the TaskRuns created from now on get the default TTL, the ones
+	// being updated or upgraded may be ones their authors expect to keep.
+	// The TaskRuns
+	// of a PipelineRun are deleted along with it. The annotation lets the
+	// CleanupPolicies of the namespace override the default.
+	defaultTTL := config.FromContextOrDefaults(ctx).Defaults.DefaultTaskRunTTL
+	if tr.Spec.ExpirationSecondsTTL == nil && defaultTTL > 0 && apis.IsInCreate(ctx) && !IsUpgradeViaDefaulting(ctx) && !tr.ownedByPipelineRun() {
+		tr.Spec.ExpirationSecondsTTL = &metav1.Duration{Duration: defaultTTL}
+		if tr.Annotations == nil {
+			tr.Annotations = map[string]string{}
+		}
+		tr.Annotations[TTLDefaultedAnnotationKey] = "true"
+	}
+}
+
+func (tr *TaskRun) ownedByPipelineRun() bool {
+	for _, ref := range tr.OwnerReferences {
+		if ref.Kind == pipelineRunControllerName {
+			return true
+		}
+	}
+	return false
 }
 
 func (trs *TaskRunSpec) SetDefaults(ctx context.Context) {
@@ -52,6 +80,12 @@ func (trs *TaskRunSpec) SetDefaults(ctx context.Context) {
 		trs.ServiceAccountName = defaultSA
 	}
 
+	defaultBuildProfile := cfg.Defaults.DefaultBuildProfile
+	if trs.PodTemplate.BuildProfile == nil && defaultBuildProfile != "" {
+		trs.PodTemplate.BuildProfile = &BuildProfile{Mode: BuildProfileMode(defaultBuildProfile)}
+	}
+	trs.PodTemplate.setDefaultGroups(cfg.Defaults.DefaultFSGroup, cfg.Defaults.DefaultSupplementalGroups)
+
 	// If this taskrun has an embedded task, apply the usual task defaults
 	if trs.TaskSpec != nil {
 		trs.TaskSpec.SetDefaults(ctx)
@@ -26,6 +26,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
 	logtesting "knative.dev/pkg/logging/testing"
 )
 
@@ -99,6 +100,7 @@ func TestTaskRunSpec_SetDefaults(t *testing.T) {
 }
 
 func TestTaskRunDefaulting(t *testing.T) {
+	fsGroup := int64(65532)
 	tests := []struct {
 		name string
 		in   *v1alpha1.TaskRun
@@ -191,6 +193,178 @@ func TestTaskRunDefaulting(t *testing.T) {
 			})
 			return s.ToContext(ctx)
 		},
+	}, {
+		name: "TaskRef default config context with build profile",
+		in: &v1alpha1.TaskRun{
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef: &v1alpha1.TaskRef{Name: "foo"},
+			},
+		},
+		want: &v1alpha1.TaskRun{
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef: &v1alpha1.TaskRef{Name: "foo", Kind: v1alpha1.NamespacedTaskKind},
+				Timeout: &metav1.Duration{Duration: 60 * time.Minute},
+				PodTemplate: v1alpha1.PodTemplate{
+					BuildProfile: &v1alpha1.BuildProfile{Mode: v1alpha1.BuildProfileRootless},
+				},
+			},
+		},
+		wc: func(ctx context.Context) context.Context {
+			s := config.NewStore(logtesting.TestLogger(t))
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: config.DefaultsConfigName,
+				},
+				Data: map[string]string{
+					"default-build-profile": "rootless",
+				},
+			})
+			return s.ToContext(ctx)
+		},
+	}, {
+		name: "TaskRef default config context with groups",
+		in: &v1alpha1.TaskRun{
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef: &v1alpha1.TaskRef{Name: "foo"},
+				PodTemplate: v1alpha1.PodTemplate{
+					SecurityContext: &corev1.PodSecurityContext{SupplementalGroups: []int64{3000}},
+				},
+			},
+		},
+		want: &v1alpha1.TaskRun{
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef: &v1alpha1.TaskRef{Name: "foo", Kind: v1alpha1.NamespacedTaskKind},
+				Timeout: &metav1.Duration{Duration: 60 * time.Minute},
+				PodTemplate: v1alpha1.PodTemplate{
+					SecurityContext: &corev1.PodSecurityContext{FSGroup: &fsGroup, SupplementalGroups: []int64{3000}},
+				},
+			},
+		},
+		wc: func(ctx context.Context) context.Context {
+			s := config.NewStore(logtesting.TestLogger(t))
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: config.DefaultsConfigName,
+				},
+				Data: map[string]string{
+					"default-fs-group":            "65532",
+					"default-supplemental-groups": "1000,2000",
+				},
+			})
+			return s.ToContext(ctx)
+		},
+	}, {
+		name: "TaskRef default config context with ttl",
+		in: &v1alpha1.TaskRun{
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef: &v1alpha1.TaskRef{Name: "foo"},
+			},
+		},
+		want: &v1alpha1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{v1alpha1.TTLDefaultedAnnotationKey: "true"},
+			},
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef:              &v1alpha1.TaskRef{Name: "foo", Kind: v1alpha1.NamespacedTaskKind},
+				Timeout:              &metav1.Duration{Duration: 60 * time.Minute},
+				ExpirationSecondsTTL: &metav1.Duration{Duration: 24 * time.Hour},
+			},
+		},
+		wc: func(ctx context.Context) context.Context {
+			s := config.NewStore(logtesting.TestLogger(t))
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: config.DefaultsConfigName,
+				},
+				Data: map[string]string{
+					"default-task-run-ttl": "24h",
+				},
+			})
+			return s.ToContext(apis.WithinCreate(ctx))
+		},
+	}, {
+		name: "TaskRef default config context with ttl on update",
+		in: &v1alpha1.TaskRun{
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef: &v1alpha1.TaskRef{Name: "foo"},
+			},
+		},
+		want: &v1alpha1.TaskRun{
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef: &v1alpha1.TaskRef{Name: "foo", Kind: v1alpha1.NamespacedTaskKind},
+				Timeout: &metav1.Duration{Duration: 60 * time.Minute},
+			},
+		},
+		wc: func(ctx context.Context) context.Context {
+			s := config.NewStore(logtesting.TestLogger(t))
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: config.DefaultsConfigName,
+				},
+				Data: map[string]string{
+					"default-task-run-ttl": "24h",
+				},
+			})
+			return s.ToContext(apis.WithinUpdate(ctx, &v1alpha1.TaskRun{}))
+		},
+	}, {
+		name: "TaskRef default config context with ttl owned by a PipelineRun",
+		in: &v1alpha1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{Kind: "PipelineRun", Name: "bar"}},
+			},
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef: &v1alpha1.TaskRef{Name: "foo"},
+			},
+		},
+		want: &v1alpha1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{Kind: "PipelineRun", Name: "bar"}},
+			},
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef: &v1alpha1.TaskRef{Name: "foo", Kind: v1alpha1.NamespacedTaskKind},
+				Timeout: &metav1.Duration{Duration: 60 * time.Minute},
+			},
+		},
+		wc: func(ctx context.Context) context.Context {
+			s := config.NewStore(logtesting.TestLogger(t))
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: config.DefaultsConfigName,
+				},
+				Data: map[string]string{
+					"default-task-run-ttl": "24h",
+				},
+			})
+			return s.ToContext(apis.WithinCreate(ctx))
+		},
+	}, {
+		name: "TaskRef default config context with ttl set",
+		in: &v1alpha1.TaskRun{
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef:              &v1alpha1.TaskRef{Name: "foo"},
+				ExpirationSecondsTTL: &metav1.Duration{Duration: time.Hour},
+			},
+		},
+		want: &v1alpha1.TaskRun{
+			Spec: v1alpha1.TaskRunSpec{
+				TaskRef:              &v1alpha1.TaskRef{Name: "foo", Kind: v1alpha1.NamespacedTaskKind},
+				Timeout:              &metav1.Duration{Duration: 60 * time.Minute},
+				ExpirationSecondsTTL: &metav1.Duration{Duration: time.Hour},
+			},
+		},
+		wc: func(ctx context.Context) context.Context {
+			s := config.NewStore(logtesting.TestLogger(t))
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: config.DefaultsConfigName,
+				},
+				Data: map[string]string{
+					"default-task-run-ttl": "24h",
+				},
+			})
+			return s.ToContext(apis.WithinCreate(ctx))
+		},
 	}}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -48,6 +48,17 @@ type TaskRunSpec struct {
 	TaskRef *TaskRef `json:"taskRef,omitempty"`
 	// +optional
 	TaskSpec *TaskSpec `json:"taskSpec,omitempty"`
+	// Checkout clones a Git repository before the steps run, instead of the
+	// one the Task checks out itself, if any.
+	// +optional
+	Checkout *Checkout `json:"checkout,omitempty"`
+	// Workspaces binds the workspaces declared by the Task to volumes.
+	// +optional
+	Workspaces []WorkspaceBinding `json:"workspaces,omitempty"`
+	// Files are small files mounted in /tekton/files in the steps, through
+	// a ConfigMap owned by the TaskRun.
+	// +optional
+	Files []InlineFile `json:"files,omitempty"`
 	// Used for cancelling a taskrun (and maybe more later on)
 	// +optional
 	Status TaskRunSpecStatus `json:"status,omitempty"`
@@ -56,6 +67,29 @@ type TaskRunSpec struct {
 	// Refer Go's ParseDuration documentation for expected format: https://golang.org/pkg/time/#ParseDuration
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Time after which a finished TaskRun is deleted. Unset means the
+	// TaskRun is never deleted automatically.
+	// +optional
+	ExpirationSecondsTTL *metav1.Duration `json:"expirationSecondsTTL,omitempty"`
+	// Time after which a succeeded TaskRun is deleted, instead of
+	// ExpirationSecondsTTL.
+	// +optional
+	TTLSecondsAfterSucceeded *metav1.Duration `json:"ttlSecondsAfterSucceeded,omitempty"`
+	// Time after which a failed TaskRun is deleted, instead of
+	// ExpirationSecondsTTL.
+	// +optional
+	TTLSecondsAfterFailed *metav1.Duration `json:"ttlSecondsAfterFailed,omitempty"`
+	// CleanupMode is how the TaskRun is cleaned up once its TTL elapses.
+	// Defaults to Delete.
+	// +optional
+	CleanupMode TaskRunCleanupMode `json:"cleanupMode,omitempty"`
+	// ArchiveOnExpire writes a TaskRunArchive of the TaskRun before the
+	// cleanup deletes it.
+	// +optional
+	ArchiveOnExpire bool `json:"archiveOnExpire,omitempty"`
+	// ExecutionWindow restricts when the TaskRun may start.
+	// +optional
+	ExecutionWindow *ExecutionWindow `json:"executionWindow,omitempty"`
 
 	// PodTemplate holds pod specific configuration
 	PodTemplate PodTemplate `json:"podTemplate,omitempty"`
@@ -70,6 +104,17 @@ const (
 	TaskRunSpecStatusCancelled = "TaskRunCancelled"
 )
 
+// TaskRunCleanupMode is how a TaskRun is cleaned up once its TTL elapses.
+type TaskRunCleanupMode string
+
+const (
+	// TaskRunCleanupModeDelete deletes the TaskRun.
+	TaskRunCleanupModeDelete TaskRunCleanupMode = "Delete"
+	// TaskRunCleanupModeCompact keeps the TaskRun as a record, but strips
+	// the state of its steps and its retries from its status.
+	TaskRunCleanupModeCompact TaskRunCleanupMode = "Compact"
+)
+
 // TaskRunInputs holds the input values that this task was invoked with.
 type TaskRunInputs struct {
 	// +optional
@@ -97,6 +142,14 @@ type TaskRunOutputs struct {
 
 var taskRunCondSet = apis.NewBatchConditionSet()
 
+// TaskRunConditionExpiring is the type of the condition of the finished
+// TaskRuns which will be cleaned up once their TTL elapses.
+const TaskRunConditionExpiring apis.ConditionType = "Expiring"
+
+// ProgressAnnotationKey is the annotation of the TaskRuns set to the latest
+// progress reported by their finished steps, as JSON.
+const ProgressAnnotationKey = pipeline.GroupName + "/progress"
+
 // TaskRunStatus defines the observed state of TaskRun
 type TaskRunStatus struct {
 	duckv1beta1.Status `json:",inline"`
@@ -104,6 +157,10 @@ type TaskRunStatus struct {
 	// PodName is the name of the pod responsible for executing this task's steps.
 	PodName string `json:"podName"`
 
+	// DisplayName is the display name of the Task.
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
 	// StartTime is the time the build is actually started.
 	// +optional
 	StartTime *metav1.Time `json:"startTime,omitempty"`
@@ -112,6 +169,15 @@ type TaskRunStatus struct {
 	// +optional
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
+	// ExpirationTime is the time the finished TaskRun will be cleaned up
+	// at, once its TTL elapses.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+
+	// InitSteps describes the state of each init step container.
+	// +optional
+	InitSteps []StepState `json:"initSteps,omitempty"`
+
 	// Steps describes the state of each build step container.
 	// +optional
 	Steps []StepState `json:"steps,omitempty"`
@@ -125,10 +191,55 @@ type TaskRunStatus struct {
 	// All TaskRunStatus stored in RetriesStatus will have no date within the RetriesStatus as is redundant.
 	// +optional
 	RetriesStatus []TaskRunStatus `json:"retriesStatus,omitempty"`
+	// PodTemplate is the pod template the attempt ran with. It is only set
+	// in RetriesStatus.
+	// +optional
+	PodTemplate *PodTemplate `json:"podTemplate,omitempty"`
 	// Results from Resources built during the taskRun. currently includes
 	// the digest of build container images
 	// optional
 	ResourcesResult []PipelineResourceResult `json:"resourcesResult,omitempty"`
+
+	// TaskResults are the results of the Task emitted by the steps.
+	// +optional
+	TaskResults []TaskRunResult `json:"taskResults,omitempty"`
+
+	// ImageLookups describe how the config of the images of the steps which
+	// don't specify a command was fetched, to find their command.
+	// +optional
+	ImageLookups []ImageLookup `json:"imageLookups,omitempty"`
+}
+
+// TaskRunResult is the value of a result of the Task of a TaskRun.
+type TaskRunResult struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ImageLookupAuth is how the controller authenticated to a registry to
+// fetch the config of an image.
+type ImageLookupAuth string
+
+const (
+	// ImageLookupAuthServiceAccount means the pull secrets of the service
+	// account of the TaskRun were used.
+	ImageLookupAuthServiceAccount ImageLookupAuth = "ServiceAccount"
+	// ImageLookupAuthController means the credentials of the controller
+	// were used.
+	ImageLookupAuthController ImageLookupAuth = "Controller"
+	// ImageLookupAuthAnonymous means no credentials were used.
+	ImageLookupAuthAnonymous ImageLookupAuth = "Anonymous"
+)
+
+// ImageLookup reports how the config of an image was fetched, to help
+// debugging registry authentication issues.
+type ImageLookup struct {
+	Image string `json:"image"`
+	// Registry is the registry the config was fetched from: the one of
+	// Image or, if the config couldn't be fetched from it, its mirror.
+	Registry string `json:"registry"`
+	// Auth is how the controller authenticated to Registry.
+	Auth ImageLookupAuth `json:"auth"`
 }
 
 // GetCondition returns the Condition matching the given type.
@@ -153,12 +264,48 @@ func (tr *TaskRunStatus) SetCondition(newCond *apis.Condition) {
 	}
 }
 
+// MarkExpiring sets ExpirationTime to at, and the Expiring condition to
+// true with the given reason and message.
+func (tr *TaskRunStatus) MarkExpiring(at metav1.Time, reason, messageFormat string, messageA ...interface{}) {
+	tr.ExpirationTime = &at
+	taskRunCondSet.Manage(tr).SetCondition(apis.Condition{
+		Type:     TaskRunConditionExpiring,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+		Reason:   reason,
+		Message:  fmt.Sprintf(messageFormat, messageA...),
+	})
+}
+
+// ClearExpiring unsets ExpirationTime and removes the Expiring condition,
+// e.g. once the TaskRun doesn't have a TTL anymore.
+func (tr *TaskRunStatus) ClearExpiring() {
+	tr.ExpirationTime = nil
+	// Expiring isn't a terminal condition, so it can always be cleared.
+	_ = taskRunCondSet.Manage(tr).ClearCondition(TaskRunConditionExpiring)
+}
+
 // StepState reports the results of running a step in the Task.
 type StepState struct {
 	corev1.ContainerState
 	Name          string `json:"name,omitempty"`
 	ContainerName string `json:"container,omitempty"`
 	ImageID       string `json:"imageID,omitempty"`
+	// DisplayName and Description are the ones of the step in the Task.
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Skipped is set when the step exited with one of its SkipExitCodes.
+	// +optional
+	Skipped *StepSkipped `json:"skipped,omitempty"`
+}
+
+// StepSkipped records that a step exited with one of the exit codes which
+// it declared as meaning it was skipped.
+type StepSkipped struct {
+	// ExitCode is the exit code the step exited with.
+	ExitCode int32 `json:"exitCode"`
 }
 
 // CloudEventDelivery is the target of a cloud event along with the state of
@@ -259,11 +406,51 @@ func (tr *TaskRun) HasPipelineRunOwnerReference() bool {
 	return false
 }
 
+// GetUntypedSpec returns the spec of the TaskRun, for the webhook to record
+// who created and last modified it.
+func (tr *TaskRun) GetUntypedSpec() interface{} {
+	return tr.Spec
+}
+
 // IsDone returns true if the TaskRun's status indicates that it is done.
 func (tr *TaskRun) IsDone() bool {
 	return !tr.Status.GetCondition(apis.ConditionSucceeded).IsUnknown()
 }
 
+// HasCompleted returns whether the TaskRun finished, whatever its outcome.
+func (tr *TaskRun) HasCompleted() bool {
+	return tr.IsDone()
+}
+
+// FinishTime returns the time at which the TaskRun finished, or nil if it
+// can't be told.
+func (tr *TaskRun) FinishTime() *metav1.Time {
+	if tr.Status.CompletionTime != nil {
+		return tr.Status.CompletionTime
+	}
+	if c := tr.Status.GetCondition(apis.ConditionSucceeded); c != nil && !c.LastTransitionTime.Inner.IsZero() {
+		t := c.LastTransitionTime.Inner
+		return &t
+	}
+	return nil
+}
+
+// TTL returns the time the finished TaskRun is kept for:
+// TTLSecondsAfterSucceeded or TTLSecondsAfterFailed depending on its outcome,
+// falling back to ExpirationSecondsTTL when the one for its outcome isn't
+// set. It is nil if the TaskRun is never deleted.
+func (tr *TaskRun) TTL() *metav1.Duration {
+	if c := tr.Status.GetCondition(apis.ConditionSucceeded); c != nil {
+		switch {
+		case c.IsTrue() && tr.Spec.TTLSecondsAfterSucceeded != nil:
+			return tr.Spec.TTLSecondsAfterSucceeded
+		case c.IsFalse() && tr.Spec.TTLSecondsAfterFailed != nil:
+			return tr.Spec.TTLSecondsAfterFailed
+		}
+	}
+	return tr.Spec.ExpirationSecondsTTL
+}
+
 // HasStarted function check whether taskrun has valid start time set in its status
 func (tr *TaskRun) HasStarted() bool {
 	return tr.Status.StartTime != nil && !tr.Status.StartTime.IsZero()
@@ -22,14 +22,24 @@ import (
 	"strings"
 
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 )
 
 // Validate taskrun
 func (tr *TaskRun) Validate(ctx context.Context) *apis.FieldError {
+	return noteValidationErrors(ctx, tr.validate(ctx))
+}
+
+func (tr *TaskRun) validate(ctx context.Context) *apis.FieldError {
 	if err := validateObjectMetadata(tr.GetObjectMeta()).ViaField("metadata"); err != nil {
 		return err
 	}
+	if old, ok := apis.GetBaseline(ctx).(*TaskRun); ok && apis.IsInUpdate(ctx) {
+		if err := validateCreator(old.ObjectMeta, tr.ObjectMeta, old.GetUntypedSpec(), tr.GetUntypedSpec()); err != nil {
+			return err
+		}
+	}
 	return tr.Spec.Validate(ctx)
 }
 
@@ -49,9 +59,39 @@ func (ts *TaskRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrMissingField("spec.taskref.name", "spec.taskspec")
 	}
 
+	if ts.TaskRef != nil {
+		if err := ts.TaskRef.Validate(ctx).ViaField("spec.taskref"); err != nil {
+			return err
+		}
+	}
+
 	// Validate TaskSpec if it's present
 	if ts.TaskSpec != nil {
-		if err := ts.TaskSpec.Validate(ctx); err != nil {
+		specCtx := WithPodTemplateVolumes(ctx, ts.PodTemplate.Volumes)
+		if ts.PodTemplate.IsWindows() {
+			specCtx = WithWindows(specCtx)
+		}
+		if err := ts.TaskSpec.Validate(specCtx); err != nil {
+			return err
+		}
+	}
+
+	if err := validateWorkspaceBindings(ctx, ts.Workspaces).ViaField("spec.workspaces"); err != nil {
+		return err
+	}
+	// The Task a TaskRun references is only known when it runs.
+	if ts.TaskSpec != nil {
+		if err := ValidateWorkspaceBindings(ts.TaskSpec.Workspaces, ts.Workspaces); err != nil {
+			return apis.ErrInvalidValue(err.Error(), "spec.workspaces")
+		}
+	}
+
+	if err := validateFiles(ts.Files).ViaField("spec.files"); err != nil {
+		return err
+	}
+
+	if ts.Checkout != nil {
+		if err := ts.Checkout.Validate(ctx).ViaField("spec.checkout"); err != nil {
 			return err
 		}
 	}
@@ -61,18 +101,47 @@ func (ts *TaskRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		return err
 	}
 
+	// The params of the Task a TaskRun references are only known when it
+	// runs.
+	if ts.TaskSpec != nil && ts.TaskSpec.Inputs != nil {
+		if err := ValidateParamValues(ts.TaskSpec.Inputs.Params, ts.Inputs.Params, "spec.inputs.params"); err != nil {
+			return err
+		}
+	}
+
 	// check for output resources
 	if err := ts.Outputs.Validate(ctx, "spec.Outputs"); err != nil {
 		return err
 	}
 
-	if ts.Timeout != nil {
-		// timeout should be a valid duration of at least 0.
-		if ts.Timeout.Duration < 0 {
-			return apis.ErrInvalidValue(fmt.Sprintf("%s should be >= 0", ts.Timeout.Duration.String()), "spec.timeout")
+	for _, d := range []struct {
+		constraint durationConstraint
+		value      *metav1.Duration
+	}{
+		{timeoutConstraint, ts.Timeout},
+		{expirationSecondsTTLConstraint, ts.ExpirationSecondsTTL},
+		{ttlSecondsAfterSucceededConstraint, ts.TTLSecondsAfterSucceeded},
+		{ttlSecondsAfterFailedConstraint, ts.TTLSecondsAfterFailed},
+	} {
+		if err := d.constraint.validate(d.value); err != nil {
+			return err
 		}
 	}
 
+	if err := validateCleanupMode(ts.CleanupMode); err != nil {
+		return err
+	}
+
+	if ts.ExecutionWindow != nil {
+		if err := ts.ExecutionWindow.Validate(ctx).ViaField("spec.executionWindow"); err != nil {
+			return err
+		}
+	}
+
+	if err := ts.PodTemplate.Validate("spec.podTemplate"); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -118,12 +187,30 @@ func validatePipelineResources(ctx context.Context, resources []TaskResourceBind
 
 func validateParameters(params []Param) *apis.FieldError {
 	// Template must not duplicate parameter names.
+	// Param names are case insensitive.
 	seen := map[string]struct{}{}
-	for _, p := range params {
-		if _, ok := seen[strings.ToLower(p.Name)]; ok {
-			return apis.ErrMultipleOneOf("spec.inputs.params")
+	for i, p := range params {
+		name := strings.ToLower(p.Name)
+		if _, ok := seen[name]; ok {
+			return &apis.FieldError{
+				Message: fmt.Sprintf("duplicate param %q", p.Name),
+				Paths:   []string{fmt.Sprintf("spec.inputs.params[%d].name", i)},
+			}
+		}
+		seen[name] = struct{}{}
+		if p.ValueFrom != nil {
+			path := fmt.Sprintf("spec.inputs.params[%d].valueFrom", i)
+			switch {
+			case p.ValueFrom.SecretKeyRef == nil:
+				return apis.ErrMissingField(path + ".secretKeyRef")
+			case p.ValueFrom.SecretKeyRef.Name == "":
+				return apis.ErrMissingField(path + ".secretKeyRef.name")
+			case p.ValueFrom.SecretKeyRef.Key == "":
+				return apis.ErrMissingField(path + ".secretKeyRef.key")
+			case p.Value.Type != "" && p.Value.Type != ParamTypeString || p.Value.StringVal != "":
+				return apis.ErrMultipleOneOf(fmt.Sprintf("spec.inputs.params[%d].value", i), path)
+			}
 		}
-		seen[p.Name] = struct{}{}
 	}
 	return nil
 }
@@ -18,6 +18,7 @@ package v1alpha1_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,7 +27,9 @@ import (
 	"github.com/tektoncd/pipeline/test/builder"
 	tb "github.com/tektoncd/pipeline/test/builder"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
@@ -67,6 +70,8 @@ func TestTaskRun_Validate(t *testing.T) {
 }
 
 func TestTaskRunSpec_Invalidate(t *testing.T) {
+	tooShort := int64(60)
+	zeroQuantity := resource.MustParse("0")
 	tests := []struct {
 		name    string
 		spec    v1alpha1.TaskRunSpec
@@ -95,6 +100,40 @@ func TestTaskRunSpec_Invalidate(t *testing.T) {
 			},
 		},
 		wantErr: apis.ErrDisallowedFields("spec.taskspec", "spec.taskref"),
+	}, {
+		name: "invalid taskref checksum",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name:     "taskrefname",
+				Checksum: "md5:d41d8cd98f00b204e9800998ecf8427e",
+			},
+		},
+		wantErr: apis.ErrInvalidValue("md5:d41d8cd98f00b204e9800998ecf8427e isn't a sha256:<hex> checksum", "spec.taskref.checksum"),
+	}, {
+		name: "workspace bound twice",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{Name: "taskrefname"},
+			Workspaces: []v1alpha1.WorkspaceBinding{{
+				Name:     "source",
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			}, {
+				Name:     "source",
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			}},
+		},
+		wantErr: apis.ErrMultipleOneOf("spec.workspaces[1].name"),
+	}, {
+		name: "workspace of the taskspec not bound",
+		spec: v1alpha1.TaskRunSpec{
+			TaskSpec: &v1alpha1.TaskSpec{
+				Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source"}},
+				Steps: []v1alpha1.Step{{Container: corev1.Container{
+					Name:  "mystep",
+					Image: "myimage",
+				}}},
+			},
+		},
+		wantErr: apis.ErrInvalidValue("workspaces declared by the Task aren't bound: [source]", "spec.workspaces"),
 	}, {
 		name: "negative pipeline timeout",
 		spec: v1alpha1.TaskRunSpec{
@@ -104,6 +143,110 @@ func TestTaskRunSpec_Invalidate(t *testing.T) {
 			Timeout: &metav1.Duration{Duration: -48 * time.Hour},
 		},
 		wantErr: apis.ErrInvalidValue("-48h0m0s should be >= 0", "spec.timeout"),
+	}, {
+		name: "negative expiration ttl",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			ExpirationSecondsTTL: &metav1.Duration{Duration: -time.Hour},
+		},
+		wantErr: apis.ErrInvalidValue("-1h0m0s should be >= 0", "spec.expirationSecondsTTL"),
+	}, {
+		name: "negative ttl after failure",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			TTLSecondsAfterFailed: &metav1.Duration{Duration: -time.Hour},
+		},
+		wantErr: apis.ErrInvalidValue("-1h0m0s should be >= 0", "spec.ttlSecondsAfterFailed"),
+	}, {
+		name: "execution window without schedules",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			ExecutionWindow: &v1alpha1.ExecutionWindow{TimeZone: "Europe/Paris"},
+		},
+		wantErr: apis.ErrMissingField("spec.executionWindow.schedules"),
+	}, {
+		name: "invalid execution window time zone",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			ExecutionWindow: &v1alpha1.ExecutionWindow{Schedules: []string{"* 9-17 * * *"}, TimeZone: "Nowhere/Town"},
+		},
+		wantErr: apis.ErrInvalidValue("Nowhere/Town", "spec.executionWindow.timeZone"),
+	}, {
+		name: "invalid cleanup mode",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			CleanupMode: "Archive",
+		},
+		wantErr: apis.ErrInvalidValue("Archive", "spec.cleanupMode"),
+	}, {
+		name: "service account token projection without tokens",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			PodTemplate: v1alpha1.PodTemplate{
+				ServiceAccountToken: &v1alpha1.ServiceAccountTokenProjection{},
+			},
+		},
+		wantErr: apis.ErrMissingField("spec.podTemplate.serviceAccountToken.tokens"),
+	}, {
+		name: "service account token outside of the mount path",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			PodTemplate: v1alpha1.PodTemplate{
+				ServiceAccountToken: &v1alpha1.ServiceAccountTokenProjection{
+					Tokens: []v1alpha1.ProjectedServiceAccountToken{{Path: "../token"}},
+				},
+			},
+		},
+		wantErr: apis.ErrInvalidValue("../token", "spec.podTemplate.serviceAccountToken.tokens.path"),
+	}, {
+		name: "service account token expiring too soon",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			PodTemplate: v1alpha1.PodTemplate{
+				ServiceAccountToken: &v1alpha1.ServiceAccountTokenProjection{
+					Tokens: []v1alpha1.ProjectedServiceAccountToken{{Path: "token", ExpirationSeconds: &tooShort}},
+				},
+			},
+		},
+		wantErr: apis.ErrInvalidValue("60 should be >= 600", "spec.podTemplate.serviceAccountToken.tokens.expirationSeconds"),
+	}, {
+		name: "unknown build profile mode",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			PodTemplate: v1alpha1.PodTemplate{
+				BuildProfile: &v1alpha1.BuildProfile{Mode: "dind"},
+			},
+		},
+		wantErr: apis.ErrInvalidValue("dind", "spec.podTemplate.buildProfile.mode"),
+	}, {
+		name: "build profile without cache",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			PodTemplate: v1alpha1.PodTemplate{
+				BuildProfile: &v1alpha1.BuildProfile{Mode: v1alpha1.BuildProfileRootless, CacheSizeLimit: &zeroQuantity},
+			},
+		},
+		wantErr: apis.ErrInvalidValue("0", "spec.podTemplate.buildProfile.cacheSizeLimit"),
 	}, {
 		name: "invalid taskspec",
 		spec: v1alpha1.TaskRunSpec{
@@ -119,6 +262,107 @@ func TestTaskRunSpec_Invalidate(t *testing.T) {
 			Paths:   []string{"taskspec.steps.name"},
 			Details: "Task step name must be a valid DNS Label, For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
 		},
+	}, {
+		name: "taskspec script without shebang on linux",
+		spec: v1alpha1.TaskRunSpec{
+			TaskSpec: &v1alpha1.TaskSpec{
+				Steps: []v1alpha1.Step{{
+					Container: corev1.Container{Name: "mystep", Image: "myimage"},
+					Script:    "Write-Output 'hello'",
+				}},
+			},
+		},
+		wantErr: &apis.FieldError{
+			Message: "script must start with a shebang (#!)",
+			Paths:   []string{"steps[0].script"},
+		},
+	}, {
+		name: "param violating the constraints of the embedded task",
+		spec: v1alpha1.TaskRunSpec{
+			Inputs: v1alpha1.TaskRunInputs{
+				Params: []v1alpha1.Param{{
+					Name:  "env",
+					Value: *builder.ArrayOrString("staging"),
+				}, {
+					Name:  "tags",
+					Value: *builder.ArrayOrString("v1.2.3", "latest"),
+				}},
+			},
+			TaskSpec: &v1alpha1.TaskSpec{
+				Inputs: &v1alpha1.Inputs{
+					Params: []v1alpha1.ParamSpec{{
+						Name: "env",
+						Type: v1alpha1.ParamTypeString,
+						Enum: []string{"dev", "prod"},
+					}, {
+						Name:    "tags",
+						Type:    v1alpha1.ParamTypeArray,
+						Pattern: `^v[0-9]+\.[0-9]+\.[0-9]+$`,
+					}},
+				},
+				Steps: []v1alpha1.Step{{Container: corev1.Container{
+					Name:  "mystep",
+					Image: "myimage",
+				}}},
+			},
+		},
+		wantErr: (&apis.FieldError{
+			Message: `value of param "env" isn't one of dev, prod`,
+			Paths:   []string{"spec.inputs.params[0].value"},
+		}).Also(&apis.FieldError{
+			Message: `value of param "tags" doesn't match the pattern "^v[0-9]+\\.[0-9]+\\.[0-9]+$"`,
+			Paths:   []string{"spec.inputs.params[1].value[1]"},
+		}),
+	}, {
+		name: "invalid files",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{Name: "taskrefname"},
+			Files: []v1alpha1.InlineFile{
+				{Name: "conf/settings.xml"},
+				{Name: "settings.xml", Content: strings.Repeat("a", v1alpha1.MaxFilesSize)},
+				{Name: "settings.xml", Content: "a"},
+			},
+		},
+		wantErr: (&apis.FieldError{
+			Message: `invalid value "conf/settings.xml"`,
+			Paths:   []string{"spec.files[0].name"},
+			Details: strings.Join(validation.IsConfigMapKey("conf/settings.xml"), ", "),
+		}).Also(apis.ErrMultipleOneOf("spec.files[2].name")).Also(&apis.FieldError{
+			Message: "invalid value: the files hold 65537 bytes, must be no more than 65536",
+			Paths:   []string{"spec.files"},
+		}),
+	}, {
+		name: "taskspec mounting undeclared volumes",
+		spec: v1alpha1.TaskRunSpec{
+			TaskSpec: &v1alpha1.TaskSpec{
+				Steps: []v1alpha1.Step{{Container: corev1.Container{
+					Name:  "mystep",
+					Image: "myimage",
+				}}, {Container: corev1.Container{
+					Name:         "otherstep",
+					Image:        "myimage",
+					VolumeMounts: []corev1.VolumeMount{{Name: "cache", MountPath: "/cache"}},
+				}}},
+				Sidecars: []corev1.Container{{
+					Name:         "sidecar",
+					Image:        "myimage",
+					VolumeMounts: []corev1.VolumeMount{{Name: "docker-socket", MountPath: "/var/run"}},
+				}},
+			},
+			PodTemplate: v1alpha1.PodTemplate{
+				Volumes: []corev1.Volume{{
+					Name:         "custom",
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				}},
+			},
+		},
+		wantErr: (&apis.FieldError{
+			Message: `volumeMount "cache" doesn't reference a declared volume`,
+			Paths:   []string{"steps[1].volumeMounts[0].name"},
+		}).Also(&apis.FieldError{
+			Message: `volumeMount "docker-socket" doesn't reference a declared volume`,
+			Paths:   []string{"sidecars[0].volumeMounts[0].name"},
+		}),
 	}}
 	for _, ts := range tests {
 		t.Run(ts.name, func(t *testing.T) {
@@ -155,6 +399,60 @@ func TestTaskRunSpec_Validate(t *testing.T) {
 				}}},
 			},
 		},
+	}, {
+		name: "taskref checksum",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name:     "taskrefname",
+				Checksum: "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			},
+		},
+	}, {
+		name: "taskspec mounting a volume of the pod template",
+		spec: v1alpha1.TaskRunSpec{
+			TaskSpec: &v1alpha1.TaskSpec{
+				Steps: []v1alpha1.Step{{Container: corev1.Container{
+					Name:         "mystep",
+					Image:        "myimage",
+					VolumeMounts: []corev1.VolumeMount{{Name: "custom", MountPath: "/custom"}},
+				}}},
+			},
+			PodTemplate: v1alpha1.PodTemplate{
+				Volumes: []corev1.Volume{{
+					Name:         "custom",
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+				}},
+			},
+		},
+	}, {
+		name: "taskspec script without shebang on windows",
+		spec: v1alpha1.TaskRunSpec{
+			TaskSpec: &v1alpha1.TaskSpec{
+				Steps: []v1alpha1.Step{{
+					Container: corev1.Container{Name: "mystep", Image: "myimage"},
+					Script:    "Write-Output 'hello'",
+				}},
+			},
+			PodTemplate: v1alpha1.PodTemplate{
+				NodeSelector: map[string]string{"kubernetes.io/os": "windows"},
+			},
+		},
+	}, {
+		name: "service account tokens",
+		spec: v1alpha1.TaskRunSpec{
+			TaskRef: &v1alpha1.TaskRef{
+				Name: "taskrefname",
+			},
+			PodTemplate: v1alpha1.PodTemplate{
+				ServiceAccountToken: &v1alpha1.ServiceAccountTokenProjection{
+					MountPath: "/var/run/secrets/tokens",
+					Tokens: []v1alpha1.ProjectedServiceAccountToken{
+						{Path: "vault", Audience: "vault"},
+						{Path: "aws/sts", Audience: "sts.amazonaws.com"},
+					},
+				},
+			},
+		},
 	}}
 	for _, ts := range tests {
 		t.Run(ts.name, func(t *testing.T) {
@@ -229,7 +527,46 @@ func TestInput_Invalidate(t *testing.T) {
 				Value: *builder.ArrayOrString("value"),
 			}},
 		},
-		wantErr: apis.ErrMultipleOneOf("spec.inputs.params"),
+		wantErr: &apis.FieldError{Message: `duplicate param "name"`, Paths: []string{"spec.inputs.params[1].name"}},
+	}, {
+		name: "task input params differing in case",
+		inputs: v1alpha1.TaskRunInputs{
+			Params: []v1alpha1.Param{{
+				Name:  "Name",
+				Value: *builder.ArrayOrString("value"),
+			}, {
+				Name:  "other",
+				Value: *builder.ArrayOrString("value"),
+			}, {
+				Name:  "name",
+				Value: *builder.ArrayOrString("value"),
+			}},
+		},
+		wantErr: &apis.FieldError{Message: `duplicate param "name"`, Paths: []string{"spec.inputs.params[2].name"}},
+	}, {
+		name: "task input param read from a secret without key",
+		inputs: v1alpha1.TaskRunInputs{
+			Params: []v1alpha1.Param{{
+				Name: "token",
+				ValueFrom: &v1alpha1.ParamValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "registry"},
+				}},
+			}},
+		},
+		wantErr: apis.ErrMissingField("spec.inputs.params[0].valueFrom.secretKeyRef.key"),
+	}, {
+		name: "task input param with a value read from a secret",
+		inputs: v1alpha1.TaskRunInputs{
+			Params: []v1alpha1.Param{{
+				Name:  "token",
+				Value: *builder.ArrayOrString("hunter2"),
+				ValueFrom: &v1alpha1.ParamValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "registry"},
+					Key:                  "token",
+				}},
+			}},
+		},
+		wantErr: apis.ErrMultipleOneOf("spec.inputs.params[0].value", "spec.inputs.params[0].valueFrom"),
 	}, {
 		name: "duplicate resource ref and resource spec",
 		inputs: v1alpha1.TaskRunInputs{
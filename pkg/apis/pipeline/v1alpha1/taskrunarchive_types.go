@@ -0,0 +1,173 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/kmeta"
+)
+
+// Check that TaskRunArchive may be validated and defaulted.
+var _ apis.Validatable = (*TaskRunArchive)(nil)
+var _ apis.Defaultable = (*TaskRunArchive)(nil)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TaskRunArchive is a compressed snapshot of a TaskRun written before the
+// cleanup deletes it, which keeps a queryable history of the TaskRuns without
+// keeping the TaskRuns themselves. It has the labels of the TaskRun, and the
+// tekton.dev/taskRun label set to its name.
+// +k8s:openapi-gen=true
+type TaskRunArchive struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec holds the summary and the snapshot of the archived TaskRun
+	// +optional
+	Spec TaskRunArchiveSpec `json:"spec"`
+}
+
+// TaskRunArchiveSpec summarizes the archived TaskRun, whose spec and status
+// are in its snapshot.
+type TaskRunArchiveSpec struct {
+	// TaskRunName and TaskRunUID identify the archived TaskRun.
+	TaskRunName string    `json:"taskRunName"`
+	TaskRunUID  types.UID `json:"taskRunUID,omitempty"`
+	// TaskName is the name of the Task the TaskRun referenced, if any.
+	// +optional
+	TaskName string `json:"taskName,omitempty"`
+	// Succeeded and Reason are the status and the reason of the Succeeded
+	// condition of the TaskRun.
+	// +optional
+	Succeeded corev1.ConditionStatus `json:"succeeded,omitempty"`
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// StartTime and CompletionTime are the ones of the TaskRun.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Snapshot is the gzipped JSON of the TaskRun, with only its name,
+	// namespace, UID, labels and annotations as metadata.
+	Snapshot []byte `json:"snapshot"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TaskRunArchiveList contains a list of TaskRunArchives
+type TaskRunArchiveList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TaskRunArchive `json:"items"`
+}
+
+// NewTaskRunArchive returns the TaskRunArchive of tr, in its namespace. It is
+// named after tr and its UID, so that a TaskRun replacing tr under the same
+// name gets its own TaskRunArchive.
+func NewTaskRunArchive(tr *TaskRun) (*TaskRunArchive, error) {
+	snapshot := &TaskRun{
+		TypeMeta: metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "TaskRun"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        tr.Name,
+			Namespace:   tr.Namespace,
+			UID:         tr.UID,
+			Labels:      tr.Labels,
+			Annotations: tr.Annotations,
+		},
+		Spec:   tr.Spec,
+		Status: tr.Status,
+	}
+	b, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't marshal TaskRun %s/%s: %w", tr.Namespace, tr.Name, err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, xerrors.Errorf("couldn't compress TaskRun %s/%s: %w", tr.Namespace, tr.Name, err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, xerrors.Errorf("couldn't compress TaskRun %s/%s: %w", tr.Namespace, tr.Name, err)
+	}
+
+	name := tr.Name
+	if uid := string(tr.UID); uid != "" {
+		if len(uid) > 8 {
+			uid = uid[:8]
+		}
+		name = kmeta.ChildName(tr.Name, "-"+uid)
+	}
+	labels := make(map[string]string, len(tr.Labels)+1)
+	for k, v := range tr.Labels {
+		labels[k] = v
+	}
+	labels[pipeline.GroupName+pipeline.TaskRunLabelKey] = tr.Name
+	a := &TaskRunArchive{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: tr.Namespace,
+			Labels:    labels,
+		},
+		Spec: TaskRunArchiveSpec{
+			TaskRunName:    tr.Name,
+			TaskRunUID:     tr.UID,
+			StartTime:      tr.Status.StartTime.DeepCopy(),
+			CompletionTime: tr.Status.CompletionTime.DeepCopy(),
+			Snapshot:       buf.Bytes(),
+		},
+	}
+	if tr.Spec.TaskRef != nil {
+		a.Spec.TaskName = tr.Spec.TaskRef.Name
+	}
+	if c := tr.Status.GetCondition(apis.ConditionSucceeded); c != nil {
+		a.Spec.Succeeded = c.Status
+		a.Spec.Reason = c.Reason
+	}
+	return a, nil
+}
+
+// TaskRun returns the TaskRun of the snapshot of the TaskRunArchive.
+func (a *TaskRunArchive) TaskRun() (*TaskRun, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(a.Spec.Snapshot))
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decompress the snapshot of TaskRunArchive %s/%s: %w", a.Namespace, a.Name, err)
+	}
+	defer zr.Close()
+	b, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't decompress the snapshot of TaskRunArchive %s/%s: %w", a.Namespace, a.Name, err)
+	}
+	tr := &TaskRun{}
+	if err := json.Unmarshal(b, tr); err != nil {
+		return nil, xerrors.Errorf("couldn't unmarshal the snapshot of TaskRunArchive %s/%s: %w", a.Namespace, a.Name, err)
+	}
+	return tr, nil
+}
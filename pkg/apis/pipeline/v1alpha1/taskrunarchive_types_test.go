@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+)
+
+func TestNewTaskRunArchive(t *testing.T) {
+	now := time.Date(2019, 11, 1, 12, 0, 0, 0, time.UTC)
+	tr := tb.TaskRun("build", "foo",
+		tb.TaskRunLabel("tekton.dev/pipelineRun", "nightly"),
+		tb.TaskRunAnnotation("ci", "true"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef("go-build"), tb.TaskRunArchiveOnExpire),
+		tb.TaskRunStatus(
+			tb.StatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: "Failed"}),
+			tb.TaskRunStartTime(now),
+			tb.TaskRunCompletionTime(now.Add(time.Minute)),
+			tb.PodName("build-pod"),
+		),
+	)
+	tr.UID = types.UID("8a7ec0dc-e5cd-4c9a-9a3b-5b3c2b7e9f10")
+
+	a, err := v1alpha1.NewTaskRunArchive(tr)
+	if err != nil {
+		t.Fatalf("NewTaskRunArchive() = %v", err)
+	}
+	if a.Name != "build-8a7ec0dc" || a.Namespace != "foo" {
+		t.Errorf("Expected the TaskRunArchive foo/build-8a7ec0dc, got %s/%s", a.Namespace, a.Name)
+	}
+	wantLabels := map[string]string{"tekton.dev/pipelineRun": "nightly", "tekton.dev/taskRun": "build"}
+	if d := cmp.Diff(wantLabels, a.Labels); d != "" {
+		t.Errorf("labels diff -want, +got: %v", d)
+	}
+	wantSpec := v1alpha1.TaskRunArchiveSpec{
+		TaskRunName:    "build",
+		TaskRunUID:     tr.UID,
+		TaskName:       "go-build",
+		Succeeded:      corev1.ConditionFalse,
+		Reason:         "Failed",
+		StartTime:      tr.Status.StartTime,
+		CompletionTime: tr.Status.CompletionTime,
+	}
+	if d := cmp.Diff(wantSpec, a.Spec, cmpopts.IgnoreFields(v1alpha1.TaskRunArchiveSpec{}, "Snapshot")); d != "" {
+		t.Errorf("spec diff -want, +got: %v", d)
+	}
+	if err := a.Validate(context.Background()); err != nil {
+		t.Errorf("TaskRunArchive.Validate() unexpected error = %v", err)
+	}
+
+	got, err := a.TaskRun()
+	if err != nil {
+		t.Fatalf("TaskRun() = %v", err)
+	}
+	want := tr.DeepCopy()
+	want.TypeMeta.APIVersion, want.TypeMeta.Kind = "tekton.dev/v1alpha1", "TaskRun"
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("snapshot diff -want, +got: %v", d)
+	}
+}
+
+func TestTaskRunArchive_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		a             *v1alpha1.TaskRunArchive
+		expectedError apis.FieldError
+	}{{
+		name: "no taskrun name",
+		a:    &v1alpha1.TaskRunArchive{Spec: v1alpha1.TaskRunArchiveSpec{Snapshot: []byte("snapshot")}},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.taskRunName"},
+		},
+	}, {
+		name: "no snapshot",
+		a:    &v1alpha1.TaskRunArchive{Spec: v1alpha1.TaskRunArchiveSpec{TaskRunName: "build"}},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"spec.snapshot"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.a.Name = "build"
+			err := tc.a.Validate(context.Background())
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing for %v", tc.a)
+			}
+			if d := cmp.Diff(tc.expectedError, *err, cmpopts.IgnoreUnexported(apis.FieldError{})); d != "" {
+				t.Errorf("TaskRunArchive.Validate() errors diff -want, +got: %v", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"knative.dev/pkg/apis"
+)
+
+func (a *TaskRunArchive) Validate(ctx context.Context) *apis.FieldError {
+	return noteValidationErrors(ctx, a.validate(ctx))
+}
+
+func (a *TaskRunArchive) validate(ctx context.Context) *apis.FieldError {
+	if err := validateObjectMetadata(a.GetObjectMeta()); err != nil {
+		return err.ViaField("metadata")
+	}
+	if a.Spec.TaskRunName == "" {
+		return apis.ErrMissingField("spec.taskRunName")
+	}
+	if len(a.Spec.Snapshot) == 0 {
+		return apis.ErrMissingField("spec.snapshot")
+	}
+	return nil
+}
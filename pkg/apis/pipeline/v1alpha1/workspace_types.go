@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// WorkspaceDeclaration is a directory the steps of a Task share, which the
+// TaskRuns running it bind to a volume.
+type WorkspaceDeclaration struct {
+	// Name is the name the steps refer to the workspace by, e.g. in
+	// $(workspaces.<name>.path).
+	Name string `json:"name"`
+	// Description is a user-facing description of the workspace.
+	// +optional
+	Description string `json:"description,omitempty"`
+	// MountPath is where the workspace is mounted in the steps. Defaults to
+	// /workspace/<name>.
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+	// ReadOnly mounts the workspace read-only in the steps.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Checksum reports the digests of the content of the workspace before
+	// the steps run and once they ran, as the results
+	// <name>-checksum-start and <name>-checksum-end of the TaskRuns.
+	// +optional
+	Checksum bool `json:"checksum,omitempty"`
+}
+
+// GetMountPath returns where the workspace is mounted in the steps.
+func (w WorkspaceDeclaration) GetMountPath() string {
+	if w.MountPath != "" {
+		return w.MountPath
+	}
+	return filepath.Join(WorkspaceDir, w.Name)
+}
+
+// ChecksumResults returns the names of the results the digests of the
+// content of the workspace are reported as, before the steps run and once
+// they ran.
+func (w WorkspaceDeclaration) ChecksumResults() (start, end string) {
+	return w.Name + "-checksum-start", w.Name + "-checksum-end"
+}
+
+// WorkspaceBinding binds a workspace declared by the Task to the volume a
+// TaskRun provides for it. Exactly one of the volume sources must be set.
+type WorkspaceBinding struct {
+	// Name is the name of the workspace declared by the Task.
+	Name string `json:"name"`
+	// SubPath is the directory of the volume mounted as the workspace.
+	// Defaults to the root of the volume.
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+	// PersistentVolumeClaim binds an existing PersistentVolumeClaim.
+	// +optional
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+	// EmptyDir binds a directory which lives as long as the pod of the
+	// TaskRun.
+	// +optional
+	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+	// ConfigMap binds the keys of a ConfigMap, as files.
+	// +optional
+	ConfigMap *corev1.ConfigMapVolumeSource `json:"configMap,omitempty"`
+	// Secret binds the keys of a Secret, as files.
+	// +optional
+	Secret *corev1.SecretVolumeSource `json:"secret,omitempty"`
+}
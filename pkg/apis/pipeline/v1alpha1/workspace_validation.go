@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/list"
+	"golang.org/x/xerrors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// validateWorkspaces checks that the workspaces declared by a Task have
+// unique and valid names, and unique mount paths.
+func validateWorkspaces(workspaces []WorkspaceDeclaration) *apis.FieldError {
+	var errs *apis.FieldError
+	names := map[string]struct{}{}
+	mountPaths := map[string]struct{}{}
+	for i, w := range workspaces {
+		if msgs := validation.IsDNS1123Label(w.Name); len(msgs) > 0 {
+			errs = errs.Also((&apis.FieldError{
+				Message: fmt.Sprintf("invalid value %q", w.Name),
+				Paths:   []string{"name"},
+				Details: "Workspace names must be valid DNS Labels, For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
+			}).ViaIndex(i))
+		} else if _, ok := names[w.Name]; ok {
+			errs = errs.Also(apis.ErrMultipleOneOf("name").ViaIndex(i))
+		}
+		names[w.Name] = struct{}{}
+		if w.MountPath != "" && !filepath.IsAbs(w.MountPath) {
+			errs = errs.Also(apis.ErrInvalidValue(w.MountPath+" should be an absolute path", "mountPath").ViaIndex(i))
+			continue
+		}
+		mountPath := filepath.Clean(w.GetMountPath())
+		if _, ok := mountPaths[mountPath]; ok {
+			errs = errs.Also(apis.ErrMultipleOneOf("mountPath").ViaIndex(i))
+		}
+		mountPaths[mountPath] = struct{}{}
+	}
+	return errs
+}
+
+// validateChecksumResults checks that the results don't take the names of
+// the results the checksums of the workspaces are reported as.
+func validateChecksumResults(workspaces []WorkspaceDeclaration, results []TaskResult) *apis.FieldError {
+	checksums := map[string]string{}
+	for _, w := range workspaces {
+		if w.Checksum {
+			start, end := w.ChecksumResults()
+			checksums[start] = w.Name
+			checksums[end] = w.Name
+		}
+	}
+	var errs *apis.FieldError
+	for i, r := range results {
+		if w, ok := checksums[r.Name]; ok {
+			errs = errs.Also((&apis.FieldError{
+				Message: fmt.Sprintf("result %q is the checksum of workspace %q", r.Name, w),
+				Paths:   []string{"name"},
+			}).ViaIndex(i))
+		}
+	}
+	return errs
+}
+
+// Validate checks that the binding has a name and exactly one volume
+// source, and that its sub path stays in the volume.
+func (b WorkspaceBinding) Validate(ctx context.Context) *apis.FieldError {
+	if b.Name == "" {
+		return apis.ErrMissingField("name")
+	}
+	var sources []string
+	if b.PersistentVolumeClaim != nil {
+		if b.PersistentVolumeClaim.ClaimName == "" {
+			return apis.ErrMissingField("persistentVolumeClaim.claimName")
+		}
+		sources = append(sources, "persistentVolumeClaim")
+	}
+	if b.EmptyDir != nil {
+		sources = append(sources, "emptyDir")
+	}
+	if b.ConfigMap != nil {
+		if b.ConfigMap.Name == "" {
+			return apis.ErrMissingField("configMap.name")
+		}
+		sources = append(sources, "configMap")
+	}
+	if b.Secret != nil {
+		if b.Secret.SecretName == "" {
+			return apis.ErrMissingField("secret.secretName")
+		}
+		sources = append(sources, "secret")
+	}
+	switch len(sources) {
+	case 0:
+		return apis.ErrMissingOneOf("persistentVolumeClaim", "emptyDir", "configMap", "secret")
+	case 1:
+	default:
+		return apis.ErrMultipleOneOf(sources...)
+	}
+	if b.SubPath != "" && (filepath.IsAbs(b.SubPath) || strings.HasPrefix(filepath.Clean(b.SubPath), "..")) {
+		return apis.ErrInvalidValue(b.SubPath+" should be a relative path in the volume", "subPath")
+	}
+	return nil
+}
+
+// validateWorkspaceBindings checks that the bindings of a TaskRun are valid
+// and bind each workspace at most once.
+func validateWorkspaceBindings(ctx context.Context, bindings []WorkspaceBinding) *apis.FieldError {
+	names := map[string]struct{}{}
+	for i, b := range bindings {
+		if err := b.Validate(ctx); err != nil {
+			return err.ViaIndex(i)
+		}
+		if _, ok := names[b.Name]; ok {
+			return apis.ErrMultipleOneOf("name").ViaIndex(i)
+		}
+		names[b.Name] = struct{}{}
+	}
+	return nil
+}
+
+// ValidateWorkspaceBindings checks that the bindings bind every workspace
+// declared by the Task, and only those.
+func ValidateWorkspaceBindings(declarations []WorkspaceDeclaration, bindings []WorkspaceBinding) error {
+	declared := make([]string, 0, len(declarations))
+	for _, w := range declarations {
+		declared = append(declared, w.Name)
+	}
+	bound := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		bound = append(bound, b.Name)
+	}
+	if missing := list.DiffLeft(declared, bound); len(missing) > 0 {
+		return xerrors.Errorf("workspaces declared by the Task aren't bound: %s", missing)
+	}
+	if extra := list.DiffLeft(bound, declared); len(extra) > 0 {
+		return xerrors.Errorf("workspaces bound by the TaskRun aren't declared by the Task: %s", extra)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestWorkspaceBinding_Validate(t *testing.T) {
+	for _, b := range []v1alpha1.WorkspaceBinding{{
+		Name:     "source",
+		EmptyDir: &corev1.EmptyDirVolumeSource{},
+	}, {
+		Name:                  "source",
+		SubPath:               "runs/1",
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc"},
+	}, {
+		Name:      "config",
+		ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "cm"}},
+	}, {
+		Name:   "creds",
+		Secret: &corev1.SecretVolumeSource{SecretName: "secret"},
+	}} {
+		if err := b.Validate(context.Background()); err != nil {
+			t.Errorf("Unexpected error validating %v: %v", b, err)
+		}
+	}
+}
+
+func TestWorkspaceBinding_Invalidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		binding v1alpha1.WorkspaceBinding
+		wantErr *apis.FieldError
+	}{{
+		name:    "no name",
+		binding: v1alpha1.WorkspaceBinding{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		wantErr: apis.ErrMissingField("name"),
+	}, {
+		name:    "no volume source",
+		binding: v1alpha1.WorkspaceBinding{Name: "source"},
+		wantErr: apis.ErrMissingOneOf("persistentVolumeClaim", "emptyDir", "configMap", "secret"),
+	}, {
+		name: "several volume sources",
+		binding: v1alpha1.WorkspaceBinding{
+			Name:                  "source",
+			EmptyDir:              &corev1.EmptyDirVolumeSource{},
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc"},
+		},
+		wantErr: apis.ErrMultipleOneOf("persistentVolumeClaim", "emptyDir"),
+	}, {
+		name: "no claim name",
+		binding: v1alpha1.WorkspaceBinding{
+			Name:                  "source",
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{},
+		},
+		wantErr: apis.ErrMissingField("persistentVolumeClaim.claimName"),
+	}, {
+		name: "sub path out of the volume",
+		binding: v1alpha1.WorkspaceBinding{
+			Name:     "source",
+			SubPath:  "../other",
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+		wantErr: apis.ErrInvalidValue("../other should be a relative path in the volume", "subPath"),
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.binding.Validate(context.Background())
+			if d := cmp.Diff(tc.wantErr.Error(), err.Error()); d != "" {
+				t.Errorf("WorkspaceBinding.Validate() errors diff -want, +got: %v", d)
+			}
+		})
+	}
+}
+
+func TestValidateWorkspaceBindings(t *testing.T) {
+	declarations := []v1alpha1.WorkspaceDeclaration{{Name: "source"}, {Name: "cache"}}
+	for _, tc := range []struct {
+		name     string
+		bindings []v1alpha1.WorkspaceBinding
+		wantErr  string
+	}{{
+		name:     "all bound",
+		bindings: []v1alpha1.WorkspaceBinding{{Name: "cache"}, {Name: "source"}},
+	}, {
+		name:     "missing",
+		bindings: []v1alpha1.WorkspaceBinding{{Name: "source"}},
+		wantErr:  "workspaces declared by the Task aren't bound: [cache]",
+	}, {
+		name:     "extra",
+		bindings: []v1alpha1.WorkspaceBinding{{Name: "source"}, {Name: "cache"}, {Name: "output"}},
+		wantErr:  "workspaces bound by the TaskRun aren't declared by the Task: [output]",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := v1alpha1.ValidateWorkspaceBindings(declarations, tc.bindings)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("Expected error %q but got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
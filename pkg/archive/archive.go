@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package archive uploads finished runs, and the logs of their steps, to a
+// sink before they are deleted.
+package archive
+
+import (
+	"context"
+	"net/url"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// Archiver uploads files to an archive sink.
+type Archiver interface {
+	// Put uploads content to path, relative to the location of the
+	// archive, replacing it if it already exists.
+	Put(ctx context.Context, path string, content []byte) error
+}
+
+// NewFunc returns the Archiver of the sink at location.
+type NewFunc func(ctx context.Context, location *url.URL) (Archiver, error)
+
+var (
+	mu       sync.RWMutex
+	backends = map[string]NewFunc{
+		"gs":    newGCS,
+		"http":  newHTTP,
+		"https": newHTTP,
+	}
+)
+
+// Register makes the backend created by f available for the locations with
+// the URL scheme scheme, replacing the built-in one if any. It is meant to be
+// called when the controller starts.
+func Register(scheme string, f NewFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	backends[scheme] = f
+}
+
+// New returns the Archiver of the sink at location, e.g. gs://bucket/prefix.
+func New(ctx context.Context, location string) (Archiver, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid archive location %q: %w", location, err)
+	}
+	mu.RLock()
+	f, ok := backends[u.Scheme]
+	mu.RUnlock()
+	if !ok {
+		return nil, xerrors.Errorf("no archive backend for the location %q", location)
+	}
+	return f(ctx, u)
+}
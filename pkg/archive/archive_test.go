@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeArchiver map[string]string
+
+func (a fakeArchiver) Put(_ context.Context, path string, content []byte) error {
+	a[path] = string(content)
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	fake := fakeArchiver{}
+	Register("fake", func(_ context.Context, location *url.URL) (Archiver, error) {
+		fake["location"] = location.String()
+		return fake, nil
+	})
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(backends, "fake")
+	}()
+
+	a, err := New(context.Background(), "fake://bucket/prefix")
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	if err := a.Put(context.Background(), "foo", []byte("bar")); err != nil {
+		t.Fatalf("Put() = %v", err)
+	}
+	want := fakeArchiver{"location": "fake://bucket/prefix", "foo": "bar"}
+	if d := cmp.Diff(want, fake); d != "" {
+		t.Errorf("Diff:\n%s", d)
+	}
+
+	if _, err := New(context.Background(), "s3://bucket"); err == nil {
+		t.Error("Expected an error for a location without backend")
+	}
+}
+
+func TestHTTPArchiver(t *testing.T) {
+	got := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Path == "/archive/forbidden" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		got[r.URL.Path] = string(b)
+	}))
+	defer srv.Close()
+
+	a, err := New(context.Background(), srv.URL+"/archive/")
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	if err := a.Put(context.Background(), "ns/run/taskrun.yaml", []byte("kind: TaskRun")); err != nil {
+		t.Fatalf("Put() = %v", err)
+	}
+	if d := cmp.Diff(map[string]string{"/archive/ns/run/taskrun.yaml": "kind: TaskRun"}, got); d != "" {
+		t.Errorf("Diff:\n%s", d)
+	}
+	if err := a.Put(context.Background(), "forbidden", nil); err == nil {
+		t.Error("Expected an error for a non-2xx response")
+	}
+}
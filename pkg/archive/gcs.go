@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"golang.org/x/xerrors"
+)
+
+// gcsArchiver uploads files to a GCS bucket, under a prefix.
+type gcsArchiver struct {
+	client *storage.Client
+	bucket string
+	prefix string
+}
+
+// newGCS returns the Archiver of gs://bucket/prefix, authenticated with the
+// default credentials of the controller.
+func newGCS(ctx context.Context, location *url.URL) (Archiver, error) {
+	if location.Host == "" {
+		return nil, xerrors.Errorf("invalid archive location %q: missing bucket", location)
+	}
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't create the GCS client: %w", err)
+	}
+	return &gcsArchiver{
+		client: client,
+		bucket: location.Host,
+		prefix: strings.Trim(location.Path, "/"),
+	}, nil
+}
+
+func (a *gcsArchiver) Put(ctx context.Context, p string, content []byte) error {
+	name := path.Join(a.prefix, p)
+	w := a.client.Bucket(a.bucket).Object(name).NewWriter(ctx)
+	if _, err := w.Write(content); err != nil {
+		w.Close()
+		return xerrors.Errorf("couldn't upload gs://%s/%s: %w", a.bucket, name, err)
+	}
+	if err := w.Close(); err != nil {
+		return xerrors.Errorf("couldn't upload gs://%s/%s: %w", a.bucket, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+
+	"golang.org/x/xerrors"
+)
+
+// httpArchiver uploads files to an HTTP endpoint, with one PUT request per
+// file.
+type httpArchiver struct {
+	client *http.Client
+	base   *url.URL
+}
+
+func newHTTP(_ context.Context, location *url.URL) (Archiver, error) {
+	return &httpArchiver{client: http.DefaultClient, base: location}, nil
+}
+
+func (a *httpArchiver) Put(ctx context.Context, p string, content []byte) error {
+	u := *a.base
+	u.Path = path.Join("/", a.base.Path, p)
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return xerrors.Errorf("couldn't upload %s: %w", u.String(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return xerrors.Errorf("couldn't upload %s: %s", u.String(), resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/ghodss/yaml"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// podLogs returns the logs of container of pod. It is a variable so that the
+// tests can replace it, as the fake clientset doesn't serve logs.
+var podLogs = func(kube kubernetes.Interface, namespace, pod, container string) ([]byte, error) {
+	return kube.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: container}).DoRaw()
+}
+
+// TaskRunPath returns the directory tr is archived to, relative to the
+// location of the archive.
+func TaskRunPath(tr *v1alpha1.TaskRun) string {
+	return path.Join(tr.Namespace, fmt.Sprintf("%s-%s", tr.Name, tr.UID))
+}
+
+// TaskRun uploads tr to a, as taskrun.yaml, and the logs of the containers of
+// its pod, as <container>.log, if the pod still exists.
+func TaskRun(ctx context.Context, a Archiver, kube kubernetes.Interface, tr *v1alpha1.TaskRun) error {
+	dir := TaskRunPath(tr)
+
+	tr = tr.DeepCopy()
+	tr.TypeMeta = metav1.TypeMeta{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       "TaskRun",
+	}
+	b, err := yaml.Marshal(tr)
+	if err != nil {
+		return xerrors.Errorf("couldn't marshal TaskRun %s/%s: %w", tr.Namespace, tr.Name, err)
+	}
+	if err := a.Put(ctx, path.Join(dir, "taskrun.yaml"), b); err != nil {
+		return err
+	}
+
+	if tr.Status.PodName == "" {
+		return nil
+	}
+	pod, err := kube.CoreV1().Pods(tr.Namespace).Get(tr.Status.PodName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		logs, err := podLogs(kube, pod.Namespace, pod.Name, c.Name)
+		if err != nil {
+			return xerrors.Errorf("couldn't get the logs of container %s of pod %s/%s: %w", c.Name, pod.Namespace, pod.Name, err)
+		}
+		if err := a.Put(ctx, path.Join(dir, c.Name+".log"), logs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTaskRun(t *testing.T) {
+	defer func(f func(kubernetes.Interface, string, string, string) ([]byte, error)) { podLogs = f }(podLogs)
+	podLogs = func(_ kubernetes.Interface, namespace, pod, container string) ([]byte, error) {
+		return []byte(namespace + "/" + pod + "/" + container), nil
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "run-pod", Namespace: "foo"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "place-tools"}},
+			Containers:     []corev1.Container{{Name: "step-build"}},
+		},
+	}
+	tr := tb.TaskRun("run", "foo", tb.TaskRunStatus(tb.PodName("run-pod")))
+	tr.UID = types.UID("1234")
+
+	for _, tc := range []struct {
+		name string
+		objs []runtime.Object
+		want []string
+	}{{
+		name: "with pod",
+		objs: []runtime.Object{pod},
+		want: []string{"foo/run-1234/place-tools.log", "foo/run-1234/step-build.log", "foo/run-1234/taskrun.yaml"},
+	}, {
+		name: "pod deleted",
+		want: []string{"foo/run-1234/taskrun.yaml"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			a := fakeArchiver{}
+			if err := TaskRun(context.Background(), a, fakekube.NewSimpleClientset(tc.objs...), tr); err != nil {
+				t.Fatalf("TaskRun() = %v", err)
+			}
+			var got []string
+			for p := range a {
+				got = append(got, p)
+			}
+			sort.Strings(got)
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("Diff:\n%s", d)
+			}
+			if !strings.Contains(a["foo/run-1234/taskrun.yaml"], "kind: TaskRun") {
+				t.Errorf("Archived TaskRun has no kind:\n%s", a["foo/run-1234/taskrun.yaml"])
+			}
+			if tc.objs != nil && a["foo/run-1234/step-build.log"] != "foo/run-pod/step-build" {
+				t.Errorf("Archived logs = %q", a["foo/run-1234/step-build.log"])
+			}
+		})
+	}
+}
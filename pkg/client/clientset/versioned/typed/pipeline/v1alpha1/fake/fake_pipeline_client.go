@@ -28,6 +28,10 @@ type FakeTektonV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeTektonV1alpha1) CleanupPolicies(namespace string) v1alpha1.CleanupPolicyInterface {
+	return &FakeCleanupPolicies{c, namespace}
+}
+
 func (c *FakeTektonV1alpha1) ClusterTasks() v1alpha1.ClusterTaskInterface {
 	return &FakeClusterTasks{c}
 }
@@ -36,10 +40,18 @@ func (c *FakeTektonV1alpha1) Conditions(namespace string) v1alpha1.ConditionInte
 	return &FakeConditions{c, namespace}
 }
 
+func (c *FakeTektonV1alpha1) ImagePrefetches(namespace string) v1alpha1.ImagePrefetchInterface {
+	return &FakeImagePrefetches{c, namespace}
+}
+
 func (c *FakeTektonV1alpha1) Pipelines(namespace string) v1alpha1.PipelineInterface {
 	return &FakePipelines{c, namespace}
 }
 
+func (c *FakeTektonV1alpha1) PipelineQuotas(namespace string) v1alpha1.PipelineQuotaInterface {
+	return &FakePipelineQuotas{c, namespace}
+}
+
 func (c *FakeTektonV1alpha1) PipelineResources(namespace string) v1alpha1.PipelineResourceInterface {
 	return &FakePipelineResources{c, namespace}
 }
@@ -48,6 +60,10 @@ func (c *FakeTektonV1alpha1) PipelineRuns(namespace string) v1alpha1.PipelineRun
 	return &FakePipelineRuns{c, namespace}
 }
 
+func (c *FakeTektonV1alpha1) StepActions(namespace string) v1alpha1.StepActionInterface {
+	return &FakeStepActions{c, namespace}
+}
+
 func (c *FakeTektonV1alpha1) Tasks(namespace string) v1alpha1.TaskInterface {
 	return &FakeTasks{c, namespace}
 }
@@ -56,6 +72,10 @@ func (c *FakeTektonV1alpha1) TaskRuns(namespace string) v1alpha1.TaskRunInterfac
 	return &FakeTaskRuns{c, namespace}
 }
 
+func (c *FakeTektonV1alpha1) TaskRunArchives(namespace string) v1alpha1.TaskRunArchiveInterface {
+	return &FakeTaskRunArchives{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeTektonV1alpha1) RESTClient() rest.Interface {
@@ -18,16 +18,26 @@ limitations under the License.
 
 package v1alpha1
 
+type CleanupPolicyExpansion interface{}
+
 type ClusterTaskExpansion interface{}
 
 type ConditionExpansion interface{}
 
+type ImagePrefetchExpansion interface{}
+
 type PipelineExpansion interface{}
 
+type PipelineQuotaExpansion interface{}
+
 type PipelineResourceExpansion interface{}
 
 type PipelineRunExpansion interface{}
 
+type StepActionExpansion interface{}
+
 type TaskExpansion interface{}
 
 type TaskRunExpansion interface{}
+
+type TaskRunArchiveExpansion interface{}
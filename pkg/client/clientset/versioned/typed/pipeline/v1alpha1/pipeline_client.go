@@ -27,13 +27,18 @@ import (
 
 type TektonV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	CleanupPoliciesGetter
 	ClusterTasksGetter
 	ConditionsGetter
+	ImagePrefetchesGetter
 	PipelinesGetter
+	PipelineQuotasGetter
 	PipelineResourcesGetter
 	PipelineRunsGetter
+	StepActionsGetter
 	TasksGetter
 	TaskRunsGetter
+	TaskRunArchivesGetter
 }
 
 // TektonV1alpha1Client is used to interact with features provided by the tekton.dev group.
@@ -41,6 +46,10 @@ type TektonV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *TektonV1alpha1Client) CleanupPolicies(namespace string) CleanupPolicyInterface {
+	return newCleanupPolicies(c, namespace)
+}
+
 func (c *TektonV1alpha1Client) ClusterTasks() ClusterTaskInterface {
 	return newClusterTasks(c)
 }
@@ -49,10 +58,18 @@ func (c *TektonV1alpha1Client) Conditions(namespace string) ConditionInterface {
 	return newConditions(c, namespace)
 }
 
+func (c *TektonV1alpha1Client) ImagePrefetches(namespace string) ImagePrefetchInterface {
+	return newImagePrefetches(c, namespace)
+}
+
 func (c *TektonV1alpha1Client) Pipelines(namespace string) PipelineInterface {
 	return newPipelines(c, namespace)
 }
 
+func (c *TektonV1alpha1Client) PipelineQuotas(namespace string) PipelineQuotaInterface {
+	return newPipelineQuotas(c, namespace)
+}
+
 func (c *TektonV1alpha1Client) PipelineResources(namespace string) PipelineResourceInterface {
 	return newPipelineResources(c, namespace)
 }
@@ -61,6 +78,10 @@ func (c *TektonV1alpha1Client) PipelineRuns(namespace string) PipelineRunInterfa
 	return newPipelineRuns(c, namespace)
 }
 
+func (c *TektonV1alpha1Client) StepActions(namespace string) StepActionInterface {
+	return newStepActions(c, namespace)
+}
+
 func (c *TektonV1alpha1Client) Tasks(namespace string) TaskInterface {
 	return newTasks(c, namespace)
 }
@@ -69,6 +90,10 @@ func (c *TektonV1alpha1Client) TaskRuns(namespace string) TaskRunInterface {
 	return newTaskRuns(c, namespace)
 }
 
+func (c *TektonV1alpha1Client) TaskRunArchives(namespace string) TaskRunArchiveInterface {
+	return newTaskRunArchives(c, namespace)
+}
+
 // NewForConfig creates a new TektonV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*TektonV1alpha1Client, error) {
 	config := *c
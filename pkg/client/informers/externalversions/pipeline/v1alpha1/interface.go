@@ -24,20 +24,30 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// CleanupPolicies returns a CleanupPolicyInformer.
+	CleanupPolicies() CleanupPolicyInformer
 	// ClusterTasks returns a ClusterTaskInformer.
 	ClusterTasks() ClusterTaskInformer
 	// Conditions returns a ConditionInformer.
 	Conditions() ConditionInformer
+	// ImagePrefetches returns a ImagePrefetchInformer.
+	ImagePrefetches() ImagePrefetchInformer
 	// Pipelines returns a PipelineInformer.
 	Pipelines() PipelineInformer
+	// PipelineQuotas returns a PipelineQuotaInformer.
+	PipelineQuotas() PipelineQuotaInformer
 	// PipelineResources returns a PipelineResourceInformer.
 	PipelineResources() PipelineResourceInformer
 	// PipelineRuns returns a PipelineRunInformer.
 	PipelineRuns() PipelineRunInformer
+	// StepActions returns a StepActionInformer.
+	StepActions() StepActionInformer
 	// Tasks returns a TaskInformer.
 	Tasks() TaskInformer
 	// TaskRuns returns a TaskRunInformer.
 	TaskRuns() TaskRunInformer
+	// TaskRunArchives returns a TaskRunArchiveInformer.
+	TaskRunArchives() TaskRunArchiveInformer
 }
 
 type version struct {
@@ -51,6 +61,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// CleanupPolicies returns a CleanupPolicyInformer.
+func (v *version) CleanupPolicies() CleanupPolicyInformer {
+	return &cleanupPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ClusterTasks returns a ClusterTaskInformer.
 func (v *version) ClusterTasks() ClusterTaskInformer {
 	return &clusterTaskInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -61,11 +76,21 @@ func (v *version) Conditions() ConditionInformer {
 	return &conditionInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ImagePrefetches returns a ImagePrefetchInformer.
+func (v *version) ImagePrefetches() ImagePrefetchInformer {
+	return &imagePrefetchInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Pipelines returns a PipelineInformer.
 func (v *version) Pipelines() PipelineInformer {
 	return &pipelineInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// PipelineQuotas returns a PipelineQuotaInformer.
+func (v *version) PipelineQuotas() PipelineQuotaInformer {
+	return &pipelineQuotaInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // PipelineResources returns a PipelineResourceInformer.
 func (v *version) PipelineResources() PipelineResourceInformer {
 	return &pipelineResourceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -76,6 +101,11 @@ func (v *version) PipelineRuns() PipelineRunInformer {
 	return &pipelineRunInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// StepActions returns a StepActionInformer.
+func (v *version) StepActions() StepActionInformer {
+	return &stepActionInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Tasks returns a TaskInformer.
 func (v *version) Tasks() TaskInformer {
 	return &taskInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -85,3 +115,8 @@ func (v *version) Tasks() TaskInformer {
 func (v *version) TaskRuns() TaskRunInformer {
 	return &taskRunInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// TaskRunArchives returns a TaskRunArchiveInformer.
+func (v *version) TaskRunArchives() TaskRunArchiveInformer {
+	return &taskRunArchiveInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1alpha1
 
+// CleanupPolicyListerExpansion allows custom methods to be added to
+// CleanupPolicyLister.
+type CleanupPolicyListerExpansion interface{}
+
+// CleanupPolicyNamespaceListerExpansion allows custom methods to be added to
+// CleanupPolicyNamespaceLister.
+type CleanupPolicyNamespaceListerExpansion interface{}
+
 // ClusterTaskListerExpansion allows custom methods to be added to
 // ClusterTaskLister.
 type ClusterTaskListerExpansion interface{}
@@ -30,6 +38,14 @@ type ConditionListerExpansion interface{}
 // ConditionNamespaceLister.
 type ConditionNamespaceListerExpansion interface{}
 
+// ImagePrefetchListerExpansion allows custom methods to be added to
+// ImagePrefetchLister.
+type ImagePrefetchListerExpansion interface{}
+
+// ImagePrefetchNamespaceListerExpansion allows custom methods to be added to
+// ImagePrefetchNamespaceLister.
+type ImagePrefetchNamespaceListerExpansion interface{}
+
 // PipelineListerExpansion allows custom methods to be added to
 // PipelineLister.
 type PipelineListerExpansion interface{}
@@ -38,6 +54,14 @@ type PipelineListerExpansion interface{}
 // PipelineNamespaceLister.
 type PipelineNamespaceListerExpansion interface{}
 
+// PipelineQuotaListerExpansion allows custom methods to be added to
+// PipelineQuotaLister.
+type PipelineQuotaListerExpansion interface{}
+
+// PipelineQuotaNamespaceListerExpansion allows custom methods to be added to
+// PipelineQuotaNamespaceLister.
+type PipelineQuotaNamespaceListerExpansion interface{}
+
 // PipelineResourceListerExpansion allows custom methods to be added to
 // PipelineResourceLister.
 type PipelineResourceListerExpansion interface{}
@@ -54,6 +78,14 @@ type PipelineRunListerExpansion interface{}
 // PipelineRunNamespaceLister.
 type PipelineRunNamespaceListerExpansion interface{}
 
+// StepActionListerExpansion allows custom methods to be added to
+// StepActionLister.
+type StepActionListerExpansion interface{}
+
+// StepActionNamespaceListerExpansion allows custom methods to be added to
+// StepActionNamespaceLister.
+type StepActionNamespaceListerExpansion interface{}
+
 // TaskListerExpansion allows custom methods to be added to
 // TaskLister.
 type TaskListerExpansion interface{}
@@ -62,10 +94,10 @@ type TaskListerExpansion interface{}
 // TaskNamespaceLister.
 type TaskNamespaceListerExpansion interface{}
 
-// TaskRunListerExpansion allows custom methods to be added to
-// TaskRunLister.
-type TaskRunListerExpansion interface{}
+// TaskRunArchiveListerExpansion allows custom methods to be added to
+// TaskRunArchiveLister.
+type TaskRunArchiveListerExpansion interface{}
 
-// TaskRunNamespaceListerExpansion allows custom methods to be added to
-// TaskRunNamespaceLister.
-type TaskRunNamespaceListerExpansion interface{}
+// TaskRunArchiveNamespaceListerExpansion allows custom methods to be added to
+// TaskRunArchiveNamespaceLister.
+type TaskRunArchiveNamespaceListerExpansion interface{}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+const (
+	// TaskRunExpirationIndex is the name of the index of the finished
+	// TaskRuns with a TTL of their own, the ones ListExpired goes through.
+	TaskRunExpirationIndex = "expiration"
+
+	// expirableIndexKey is the key the expirable TaskRuns are indexed by.
+	expirableIndexKey = "completed-with-ttl"
+)
+
+// TaskRunExpirationIndexFunc indexes the finished TaskRuns with a TTL of
+// their own under the same key, for TaskRunExpirationIndex.
+func TaskRunExpirationIndexFunc(obj interface{}) ([]string, error) {
+	tr, ok := obj.(*v1alpha1.TaskRun)
+	if !ok || !tr.HasCompleted() || tr.TTL() == nil {
+		return nil, nil
+	}
+	return []string{expirableIndexKey}, nil
+}
+
+// TaskRunListerExpansion allows custom methods to be added to
+// TaskRunLister.
+type TaskRunListerExpansion interface {
+	// ListExpired lists the finished TaskRuns whose own TTL elapsed at now.
+	ListExpired(now time.Time) ([]*v1alpha1.TaskRun, error)
+}
+
+// TaskRunNamespaceListerExpansion allows custom methods to be added to
+// TaskRunNamespaceLister.
+type TaskRunNamespaceListerExpansion interface{}
+
+// ListExpired lists the finished TaskRuns whose own TTL elapsed at now. Only
+// the TaskRuns in TaskRunExpirationIndex are checked, or all of them if the
+// index wasn't added to the indexer, e.g. as the informer had already
+// started.
+func (s *taskRunLister) ListExpired(now time.Time) ([]*v1alpha1.TaskRun, error) {
+	var objs []interface{}
+	if _, ok := s.indexer.GetIndexers()[TaskRunExpirationIndex]; ok {
+		var err error
+		objs, err = s.indexer.ByIndex(TaskRunExpirationIndex, expirableIndexKey)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		objs = s.indexer.List()
+	}
+	var ret []*v1alpha1.TaskRun
+	for _, obj := range objs {
+		tr := obj.(*v1alpha1.TaskRun)
+		if !tr.HasCompleted() || tr.TTL() == nil || tr.FinishTime() == nil {
+			continue
+		}
+		if !tr.FinishTime().Add(tr.TTL().Duration).After(now) {
+			ret = append(ret, tr)
+		}
+	}
+	return ret, nil
+}
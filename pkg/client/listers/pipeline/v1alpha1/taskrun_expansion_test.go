@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+)
+
+func TestListExpired(t *testing.T) {
+	now := time.Date(2019, 11, 4, 10, 0, 0, 0, time.UTC)
+	ttl := &metav1.Duration{Duration: time.Hour}
+	taskRun := func(name string, status corev1.ConditionStatus, finishedAgo time.Duration, ttl *metav1.Duration) *v1alpha1.TaskRun {
+		tr := &v1alpha1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: name},
+			Spec:       v1alpha1.TaskRunSpec{ExpirationSecondsTTL: ttl},
+		}
+		tr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: status})
+		if status != corev1.ConditionUnknown {
+			tr.Status.CompletionTime = &metav1.Time{Time: now.Add(-finishedAgo)}
+		}
+		return tr
+	}
+	trs := []*v1alpha1.TaskRun{
+		taskRun("expired", corev1.ConditionTrue, 2*time.Hour, ttl),
+		taskRun("expired-now", corev1.ConditionFalse, time.Hour, ttl),
+		taskRun("not-expired", corev1.ConditionTrue, 30*time.Minute, ttl),
+		taskRun("no-ttl", corev1.ConditionTrue, 2*time.Hour, nil),
+		taskRun("running", corev1.ConditionUnknown, 0, ttl),
+	}
+	want := []string{"expired", "expired-now"}
+
+	for _, tc := range []struct {
+		name     string
+		indexers cache.Indexers
+	}{{
+		name:     "indexed",
+		indexers: cache.Indexers{TaskRunExpirationIndex: TaskRunExpirationIndexFunc},
+	}, {
+		name:     "not indexed",
+		indexers: cache.Indexers{},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, tc.indexers)
+			for _, tr := range trs {
+				if err := indexer.Add(tr); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expired, err := NewTaskRunLister(indexer).ListExpired(now)
+			if err != nil {
+				t.Fatalf("ListExpired() = %v", err)
+			}
+			got := sets.NewString()
+			for _, tr := range expired {
+				got.Insert(tr.Name)
+			}
+			if d := cmp.Diff(want, got.List()); d != "" {
+				t.Errorf("Expired TaskRuns diff -want, +got: %s", d)
+			}
+		})
+	}
+}
+
+func TestTaskRunExpirationIndexFunc(t *testing.T) {
+	running := &v1alpha1.TaskRun{Spec: v1alpha1.TaskRunSpec{ExpirationSecondsTTL: &metav1.Duration{Duration: time.Hour}}}
+	finished := running.DeepCopy()
+	finished.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+	noTTL := finished.DeepCopy()
+	noTTL.Spec.ExpirationSecondsTTL = nil
+
+	for _, tc := range []struct {
+		name string
+		obj  interface{}
+		want []string
+	}{
+		{name: "running", obj: running},
+		{name: "finished", obj: finished, want: []string{expirableIndexKey}},
+		{name: "no ttl", obj: noTTL},
+		{name: "not a taskrun", obj: &v1alpha1.PipelineRun{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := TaskRunExpirationIndexFunc(tc.obj)
+			if err != nil {
+				t.Fatalf("TaskRunExpirationIndexFunc() = %v", err)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("Index keys diff -want, +got: %s", d)
+			}
+		})
+	}
+}
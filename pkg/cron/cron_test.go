@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Expected an error parsing %q", expr)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		at   string
+		want bool
+	}{
+		{"* * * * *", "2019-11-04T13:27:45Z", true},
+		{"* 9-17 * * mon-fri", "2019-11-04T13:27:00Z", true},
+		{"* 9-17 * * mon-fri", "2019-11-04T18:00:00Z", false},
+		{"* 9-17 * * mon-fri", "2019-11-03T13:27:00Z", false},
+		{"*/15 * * * *", "2019-11-04T13:45:00Z", true},
+		{"*/15 * * * *", "2019-11-04T13:46:00Z", false},
+		{"30 22 * * 7", "2019-11-03T22:30:00Z", true},
+		{"0,30 1,13 * Nov *", "2019-11-04T13:30:00Z", true},
+		// Either day field matches when both are restricted.
+		{"* * 1 * mon", "2019-11-04T13:00:00Z", true},
+		{"* * 1 * mon", "2019-11-01T13:00:00Z", true},
+		{"* * 1 * mon", "2019-11-05T13:00:00Z", false},
+		// Both do when either is a wildcard.
+		{"* * */2 * mon", "2019-11-04T13:00:00Z", false},
+	} {
+		s, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", tc.expr, err)
+		}
+		if got := s.Matches(date(tc.at)); got != tc.want {
+			t.Errorf("Expected %q to match %s: %t, got %t", tc.expr, tc.at, tc.want, got)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		from string
+		want string
+	}{
+		{"* * * * *", "2019-11-04T13:27:00Z", "2019-11-04T13:27:00Z"},
+		{"* * * * *", "2019-11-04T13:27:01Z", "2019-11-04T13:28:00Z"},
+		{"* 9-17 * * mon-fri", "2019-11-04T13:27:00Z", "2019-11-04T13:27:00Z"},
+		{"* 9-17 * * mon-fri", "2019-11-04T18:00:00Z", "2019-11-05T09:00:00Z"},
+		{"* 9-17 * * mon-fri", "2019-11-08T18:00:00Z", "2019-11-11T09:00:00Z"},
+		{"0 0 1 jan *", "2019-11-04T13:27:00Z", "2020-01-01T00:00:00Z"},
+		{"0 0 29 2 *", "2019-03-01T00:00:00Z", "2020-02-29T00:00:00Z"},
+		{"0 9 * * *", "2019-11-04T10:00:00+05:30", "2019-11-05T09:00:00+05:30"},
+	} {
+		s, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing %q: %v", tc.expr, err)
+		}
+		got, ok := s.Next(date(tc.from))
+		if !ok || !got.Equal(date(tc.want)) {
+			t.Errorf("Expected the next minute of %q from %s to be %s, got %s (%t)", tc.expr, tc.from, tc.want, got, ok)
+		}
+	}
+
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got, ok := s.Next(date("2019-11-04T13:27:00Z")); ok {
+		t.Errorf("Expected no next minute for February 30th, got %s", got)
+	}
+}
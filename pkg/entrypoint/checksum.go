@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"golang.org/x/xerrors"
+)
+
+// Checksum returns the sha256 digest of the content of the directory root:
+// the paths of its entries, relative to root, the content of its files and
+// the targets of its symlinks. Their permissions and times are left out, so
+// that the same content has the same digest wherever it is copied.
+func Checksum(root string) (string, error) {
+	h := sha256.New()
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		// The NUL separators keep the entries from running into one
+		// another.
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "symlink %s\x00%s\x00", filepath.ToSlash(rel), target)
+		case info.Mode().IsRegular():
+			fmt.Fprintf(h, "file %s\x00%d\x00", filepath.ToSlash(rel), info.Size())
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(h, f); err != nil {
+				return err
+			}
+		case info.IsDir():
+			fmt.Fprintf(h, "dir %s\x00", filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", xerrors.Errorf("couldn't compute the checksum of %q: %w", root, err)
+	}
+	return config.ChecksumPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// writeChecksums writes the checksums of the directories of checksums, by
+// the names of the results they are reported as, to the termination message
+// file, as results of the Task.
+func (e Entrypointer) writeChecksums(checksums map[string]string) error {
+	if len(checksums) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	results := make([]v1alpha1.PipelineResourceResult, len(names))
+	for i, name := range names {
+		sum, err := Checksum(checksums[name])
+		if err != nil {
+			return err
+		}
+		results[i] = v1alpha1.PipelineResourceResult{
+			Key:        name,
+			Value:      sum,
+			ResultType: v1alpha1.TaskRunResultType,
+		}
+	}
+	return writeResults(e.TerminationPath, results...)
+}
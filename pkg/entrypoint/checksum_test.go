@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+// writeTree creates the files of tree, by path relative to root, with their
+// content.
+func writeTree(t *testing.T, root string, tree map[string]string) {
+	t.Helper()
+	for p, content := range tree {
+		p = filepath.Join(root, p)
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tree := map[string]string{"go.mod": "module foo\n", "cmd/main.go": "package main\n"}
+	for _, tc := range []struct {
+		name string
+		tree map[string]string
+		same bool
+	}{{
+		name: "same content",
+		tree: tree,
+		same: true,
+	}, {
+		name: "changed content",
+		tree: map[string]string{"go.mod": "module bar\n", "cmd/main.go": "package main\n"},
+	}, {
+		name: "renamed file",
+		tree: map[string]string{"go.mod": "module foo\n", "cmd/app.go": "package main\n"},
+	}, {
+		name: "content moved between files",
+		tree: map[string]string{"go.mod": "module foo\npackage main\n", "cmd/main.go": ""},
+	}, {
+		name: "added file",
+		tree: map[string]string{"go.mod": "module foo\n", "cmd/main.go": "package main\n", "go.sum": ""},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := filepath.Join(dir, tc.name, "a"), filepath.Join(dir, tc.name, "b")
+			writeTree(t, a, tree)
+			writeTree(t, b, tc.tree)
+			sumA, err := Checksum(a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sumB, err := Checksum(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(sumA, "sha256:") {
+				t.Errorf("Expected a sha256 checksum, got %q", sumA)
+			}
+			if same := sumA == sumB; same != tc.same {
+				t.Errorf("Expected the checksums to be the same: %t, got %q and %q", tc.same, sumA, sumB)
+			}
+		})
+	}
+
+	if _, err := Checksum(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error computing the checksum of a missing directory")
+	}
+}
+
+func TestEntrypointerChecksums(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entrypointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	terminationPath := filepath.Join(dir, "termination-log")
+	workspace := filepath.Join(dir, "source")
+	writeTree(t, workspace, map[string]string{"go.mod": "module foo\n"})
+	start, err := Checksum(workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Entrypointer{
+		Entrypoint:      "echo",
+		TerminationPath: terminationPath,
+		StartChecksums:  map[string]string{"source-checksum-start": workspace},
+		EndChecksums:    map[string]string{"source-checksum-end": workspace},
+		Runner:          &fakeResultRunner{file: filepath.Join(workspace, "go.sum"), content: "golang.org/x/xerrors v0.0.0\n"},
+		PostWriter:      &fakePostWriter{},
+	}.Go()
+	if err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+	end, err := Checksum(workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start == end {
+		t.Fatalf("Expected the checksum of the workspace to change, got %q", start)
+	}
+	results, err := readTerminationMessage(terminationPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []v1alpha1.PipelineResourceResult{
+		{Key: "source-checksum-start", Value: start, ResultType: v1alpha1.TaskRunResultType},
+		{Key: "source-checksum-end", Value: end, ResultType: v1alpha1.TaskRunResultType},
+	}
+	if d := cmp.Diff(expected, results); d != "" {
+		t.Errorf("termination message diff -want, +got: %v", d)
+	}
+}
@@ -17,9 +17,62 @@ limitations under the License.
 package entrypoint
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// SkippedResultKey is the key of the result written to the termination
+	// message when the step exits with one of its SkipExitCodes.
+	SkippedResultKey = "StepSkipped"
+	// WaitTimedOutResultKey is the key of the result written to the
+	// termination message when the step times out waiting for one of its
+	// WaitFiles. Its value is the error.
+	WaitTimedOutResultKey = "StepWaitTimedOut"
+	// TimedOutResultKey is the key of the result written to the termination
+	// message when the command of the step runs longer than its timeout.
+	// Its value is the error.
+	TimedOutResultKey = "StepTimedOut"
+	// ExitCodeResultKey is the key of the result written to the termination
+	// message when the command of a step whose OnError is continue exits
+	// with a non-zero exit code. Its value is the exit code.
+	ExitCodeResultKey = "StepExitCode"
+	// ProgressResultKey is the key of the result written to the termination
+	// message with the latest progress of the step. Its value is the
+	// Progress, as JSON.
+	ProgressResultKey = "StepProgress"
 )
 
+// WaitTimeoutError is the error of a Waiter which timed out waiting for a
+// file.
+type WaitTimeoutError struct {
+	File    string
+	Timeout time.Duration
+}
+
+func (e WaitTimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s waiting for %q", e.Timeout, e.File)
+}
+
+// TimeoutError is the error of a Runner which killed the command after it
+// ran longer than its timeout.
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s", e.Timeout)
+}
+
 // Entrypointer holds fields for running commands with redirected
 // entrypoints.
 type Entrypointer struct {
@@ -36,6 +89,46 @@ type Entrypointer struct {
 	// PostFile is the file to write when complete. If not specified, no
 	// file is written.
 	PostFile string
+	// StartFile is the file to write when the command starts, which the
+	// wait timeout of the next step counts from. If not specified, no file
+	// is written.
+	StartFile string
+	// SkipExitCodes are the exit codes of the command which mean the step
+	// was skipped. They are reported in the file at TerminationPath and the
+	// step is then considered successful.
+	SkipExitCodes []int
+	// OnError is what happens when the command exits with another non-zero
+	// exit code. If it is continue, the exit code is reported in the file
+	// at TerminationPath and the step is then considered successful.
+	OnError v1alpha1.OnErrorType
+	// TerminationPath is the file where the skipped, the exit code, the
+	// wait timed out and the timed out results are written.
+	TerminationPath string
+	// StepsDir is the directory shared by the steps, where the exit code and
+	// the results of the named steps are written. If specified, the
+	// $(steps.<name>.<field>) variables of the Entrypoint, the Args and the
+	// environment are replaced with them before running the command.
+	StepsDir string
+	// StepName is the name of the step, whose exit code and results later
+	// steps reference. If specified, its results directory is created under
+	// StepsDir and its exit code written there.
+	StepName string
+	// ProgressFile is the file the step writes its progress to, which is
+	// reported every ProgressInterval while it runs, as the progress of
+	// ProgressStep. If not specified, no progress is reported.
+	ProgressFile     string
+	ProgressInterval time.Duration
+	ProgressStep     string
+	// Results are the names of the results of the Task, which the command
+	// may write to files of the same names in ResultsDir. Those written
+	// are reported in the file at TerminationPath once it has run.
+	Results    []string
+	ResultsDir string
+	// StartChecksums and EndChecksums map the names of results of the Task
+	// to the directories whose checksums are reported as them in the file
+	// at TerminationPath, before the command runs and once it ran.
+	StartChecksums map[string]string
+	EndChecksums   map[string]string
 
 	// Waiter encapsulates waiting for files to exist.
 	Waiter Waiter
@@ -43,6 +136,8 @@ type Entrypointer struct {
 	Runner Runner
 	// PostWriter encapsulates writing files when complete.
 	PostWriter PostWriter
+	// ProgressReporter encapsulates reporting the progress of the step.
+	ProgressReporter ProgressReporter
 }
 
 // Waiter encapsulates waiting for files to exist.
@@ -53,6 +148,8 @@ type Waiter interface {
 
 // Runner encapsulates running commands.
 type Runner interface {
+	// Run runs the command, and fails with a TimeoutError if it kills it
+	// after it ran longer than its timeout.
 	Run(args ...string) error
 }
 
@@ -69,16 +166,84 @@ func (e Entrypointer) Go() error {
 		if err := e.Waiter.Wait(f, e.WaitFileContent); err != nil {
 			// An error happened while waiting, so we bail
 			// *but* we write postfile to make next steps bail too.
+			e.WritePostFile(e.PostFile, err)
+			var timeout WaitTimeoutError
+			if xerrors.As(err, &timeout) {
+				if werr := writeResult(e.TerminationPath, WaitTimedOutResultKey, timeout.Error()); werr != nil {
+					return werr
+				}
+			}
+			return err
+		}
+	}
+
+	if e.StepsDir != "" {
+		if err := e.prepareStepVariables(); err != nil {
+			e.WritePostFile(e.PostFile, err)
+			return err
+		}
+		var err error
+		if e.Entrypoint, err = e.resolveStepVariables(e.Entrypoint); err != nil {
 			e.WritePostFile(e.PostFile, err)
 			return err
 		}
+		for i, arg := range e.Args {
+			if e.Args[i], err = e.resolveStepVariables(arg); err != nil {
+				e.WritePostFile(e.PostFile, err)
+				return err
+			}
+		}
 	}
 
 	if e.Entrypoint != "" {
 		e.Args = append([]string{e.Entrypoint}, e.Args...)
 	}
 
+	if e.ProgressFile != "" {
+		// The progress left by the previous steps isn't this step's.
+		if err := os.Remove(e.ProgressFile); err != nil && !os.IsNotExist(err) {
+			e.WritePostFile(e.PostFile, err)
+			return err
+		}
+	}
+	if len(e.Results) > 0 {
+		if err := os.MkdirAll(e.ResultsDir, 0777); err != nil {
+			e.WritePostFile(e.PostFile, err)
+			return err
+		}
+	}
+	if err := e.writeChecksums(e.StartChecksums); err != nil {
+		e.WritePostFile(e.PostFile, err)
+		return err
+	}
+	if e.StartFile != "" {
+		e.PostWriter.Write(e.StartFile)
+	}
+	stopProgress := e.relayProgress()
 	err := e.Runner.Run(e.Args...)
+	stopProgress()
+	var timeout TimeoutError
+	if xerrors.As(err, &timeout) {
+		if werr := writeResult(e.TerminationPath, TimedOutResultKey, timeout.Error()); werr != nil {
+			err = werr
+		}
+	}
+	if werr := e.writeTaskResults(); werr != nil && err == nil {
+		err = werr
+	}
+	if werr := e.writeChecksums(e.EndChecksums); werr != nil && err == nil {
+		err = werr
+	}
+	if e.StepName != "" {
+		if werr := e.writeExitCode(err); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	if code, ok := e.skipExitCode(err); ok {
+		err = writeResult(e.TerminationPath, SkippedResultKey, strconv.Itoa(code))
+	} else if code, ok := exitCode(err); ok && e.OnError == v1alpha1.Continue {
+		err = writeResult(e.TerminationPath, ExitCodeResultKey, strconv.Itoa(code))
+	}
 
 	// Write the post file *no matter what*
 	e.WritePostFile(e.PostFile, err)
@@ -94,3 +259,110 @@ func (e Entrypointer) WritePostFile(postFile string, err error) {
 		e.PostWriter.Write(postFile)
 	}
 }
+
+// skipExitCode returns the exit code of the command if it is one of
+// SkipExitCodes.
+func (e Entrypointer) skipExitCode(err error) (int, bool) {
+	code, ok := exitCode(err)
+	if !ok {
+		return 0, false
+	}
+	for _, c := range e.SkipExitCodes {
+		if code == c {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// exitCode returns the exit code of the command if err is the error of a
+// command which exited with a non-zero exit code.
+func exitCode(err error) (int, bool) {
+	exitErr, ok := err.(interface{ ExitCode() int })
+	if !ok {
+		return 0, false
+	}
+	return exitErr.ExitCode(), true
+}
+
+// writeTaskResults writes the results of the Task found in ResultsDir to the
+// termination message file. Trailing newlines of the results are trimmed.
+func (e Entrypointer) writeTaskResults() error {
+	var results []v1alpha1.PipelineResourceResult
+	for _, name := range e.Results {
+		b, err := ioutil.ReadFile(filepath.Join(e.ResultsDir, name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return xerrors.Errorf("couldn't read result %q: %w", name, err)
+		}
+		results = append(results, v1alpha1.PipelineResourceResult{
+			Key:        name,
+			Value:      strings.TrimRight(string(b), "\n"),
+			ResultType: v1alpha1.TaskRunResultType,
+		})
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return writeResults(e.TerminationPath, results...)
+}
+
+// writeResult adds the result key to the results already in the
+// termination message file at path, if any.
+func writeResult(path, key, value string) error {
+	return writeResults(path, v1alpha1.PipelineResourceResult{
+		Key:   key,
+		Value: value,
+	})
+}
+
+// writeResults adds results to the results already in the termination
+// message file at path, if any.
+func writeResults(path string, added ...v1alpha1.PipelineResourceResult) error {
+	results, err := readTerminationMessage(path)
+	if err != nil {
+		return err
+	}
+	return writeTerminationMessage(path, append(results, added...))
+}
+
+// setResult replaces the result key in the termination message file at
+// path, if any, with value.
+func setResult(path, key, value string) error {
+	results, err := readTerminationMessage(path)
+	if err != nil {
+		return err
+	}
+	kept := results[:0]
+	for _, r := range results {
+		if r.Key != key {
+			kept = append(kept, r)
+		}
+	}
+	return writeTerminationMessage(path, append(kept, v1alpha1.PipelineResourceResult{Key: key, Value: value}))
+}
+
+// readTerminationMessage returns the results already in the termination
+// message file at path, if any.
+func readTerminationMessage(path string) ([]v1alpha1.PipelineResourceResult, error) {
+	var results []v1alpha1.PipelineResourceResult
+	if b, err := ioutil.ReadFile(path); err == nil && len(b) > 0 {
+		if err := json.Unmarshal(b, &results); err != nil {
+			return nil, xerrors.Errorf("couldn't parse existing termination message %q: %w", path, err)
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return nil, xerrors.Errorf("couldn't read termination message %q: %w", path, err)
+	}
+	return results, nil
+}
+
+// writeTerminationMessage writes results to the termination message file at
+// path.
+func writeTerminationMessage(path string, results []v1alpha1.PipelineResourceResult) error {
+	b, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0666)
+}
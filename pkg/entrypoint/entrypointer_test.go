@@ -17,10 +17,16 @@ limitations under the License.
 package entrypoint
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"golang.org/x/xerrors"
 )
 
@@ -177,6 +183,271 @@ func TestEntrypointer(t *testing.T) {
 	}
 }
 
+func TestEntrypointerExitCodes(t *testing.T) {
+	for _, c := range []struct {
+		desc          string
+		exitCode      int
+		onError       v1alpha1.OnErrorType
+		message       string
+		expectedError bool
+		expected      []v1alpha1.PipelineResourceResult
+	}{{
+		desc:     "skip exit code",
+		exitCode: 78,
+		expected: []v1alpha1.PipelineResourceResult{{Key: SkippedResultKey, Value: "78"}},
+	}, {
+		desc:     "skip exit code with existing results",
+		exitCode: 78,
+		message:  `[{"key":"digest","value":"sha256:1234"}]`,
+		expected: []v1alpha1.PipelineResourceResult{{Key: "digest", Value: "sha256:1234"}, {Key: SkippedResultKey, Value: "78"}},
+	}, {
+		desc:          "other exit code",
+		exitCode:      1,
+		expectedError: true,
+	}, {
+		desc:          "other exit code with stopAndFail",
+		exitCode:      1,
+		onError:       v1alpha1.StopAndFail,
+		expectedError: true,
+	}, {
+		desc:     "other exit code with continue",
+		exitCode: 1,
+		onError:  v1alpha1.Continue,
+		expected: []v1alpha1.PipelineResourceResult{{Key: ExitCodeResultKey, Value: "1"}},
+	}, {
+		desc:     "skip exit code with continue",
+		exitCode: 78,
+		onError:  v1alpha1.Continue,
+		expected: []v1alpha1.PipelineResourceResult{{Key: SkippedResultKey, Value: "78"}},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "entrypointer")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			terminationPath := filepath.Join(dir, "termination-log")
+			if c.message != "" {
+				if err := ioutil.WriteFile(terminationPath, []byte(c.message), 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fpw := &fakePostWriter{}
+			err = Entrypointer{
+				Entrypoint:      "echo",
+				PostFile:        "writeme",
+				SkipExitCodes:   []int{78, 79},
+				OnError:         c.onError,
+				TerminationPath: terminationPath,
+				Waiter:          &fakeWaiter{},
+				Runner:          &fakeExitCodeRunner{exitCode: c.exitCode},
+				PostWriter:      fpw,
+			}.Go()
+			if c.expectedError {
+				if err == nil {
+					t.Fatal("Entrypointer didn't fail")
+				}
+				if *fpw.wrote != "writeme.err" {
+					t.Errorf("Wrote post file %q, want %q", *fpw.wrote, "writeme.err")
+				}
+				if _, err := os.Stat(terminationPath); !os.IsNotExist(err) {
+					t.Errorf("Expected no termination message, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Entrypointer failed: %v", err)
+			}
+			if *fpw.wrote != "writeme" {
+				t.Errorf("Wrote post file %q, want %q", *fpw.wrote, "writeme")
+			}
+			b, err := ioutil.ReadFile(terminationPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var results []v1alpha1.PipelineResourceResult
+			if err := json.Unmarshal(b, &results); err != nil {
+				t.Fatal(err)
+			}
+			if d := cmp.Diff(c.expected, results); d != "" {
+				t.Errorf("termination message diff -want, +got: %v", d)
+			}
+		})
+	}
+}
+
+func TestEntrypointerWaitTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entrypointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	terminationPath := filepath.Join(dir, "termination-log")
+
+	fr, fpw := &fakeRunner{}, &fakePostWriter{}
+	err = Entrypointer{
+		Entrypoint:      "echo",
+		WaitFiles:       []string{"/builder/downward/ready"},
+		PostFile:        "writeme",
+		TerminationPath: terminationPath,
+		Waiter:          &fakeTimeoutWaiter{},
+		Runner:          fr,
+		PostWriter:      fpw,
+	}.Go()
+	if _, ok := err.(WaitTimeoutError); !ok {
+		t.Fatalf("Expected a WaitTimeoutError, got %v", err)
+	}
+	if fr.args != nil {
+		t.Errorf("Expected the command not to run, got %v", *fr.args)
+	}
+	if *fpw.wrote != "writeme.err" {
+		t.Errorf("Wrote post file %q, want %q", *fpw.wrote, "writeme.err")
+	}
+	b, err := ioutil.ReadFile(terminationPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []v1alpha1.PipelineResourceResult
+	if err := json.Unmarshal(b, &results); err != nil {
+		t.Fatal(err)
+	}
+	expected := []v1alpha1.PipelineResourceResult{{Key: WaitTimedOutResultKey, Value: `timed out after 1m0s waiting for "/builder/downward/ready"`}}
+	if d := cmp.Diff(expected, results); d != "" {
+		t.Errorf("termination message diff -want, +got: %v", d)
+	}
+}
+
+func TestEntrypointerStartFile(t *testing.T) {
+	fpw := &recordingPostWriter{}
+	err := Entrypointer{
+		Entrypoint: "echo",
+		WaitFiles:  []string{"/builder/tools/0"},
+		StartFile:  "/builder/tools/1.start",
+		PostFile:   "/builder/tools/1",
+		Waiter:     &fakeWaiter{},
+		Runner:     &fakeRunner{},
+		PostWriter: fpw,
+	}.Go()
+	if err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+	// The start file is written once the step is done waiting, before the
+	// command runs.
+	if d := cmp.Diff([]string{"/builder/tools/1.start", "/builder/tools/1"}, fpw.wrote); d != "" {
+		t.Errorf("written files diff -want, +got: %v", d)
+	}
+}
+
+func TestEntrypointerTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entrypointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	terminationPath := filepath.Join(dir, "termination-log")
+
+	fpw := &fakePostWriter{}
+	err = Entrypointer{
+		Entrypoint:      "sleep",
+		Args:            []string{"3600"},
+		PostFile:        "writeme",
+		TerminationPath: terminationPath,
+		Waiter:          &fakeWaiter{},
+		Runner:          &fakeTimeoutRunner{},
+		PostWriter:      fpw,
+	}.Go()
+	if _, ok := err.(TimeoutError); !ok {
+		t.Fatalf("Expected a TimeoutError, got %v", err)
+	}
+	if *fpw.wrote != "writeme.err" {
+		t.Errorf("Wrote post file %q, want %q", *fpw.wrote, "writeme.err")
+	}
+	b, err := ioutil.ReadFile(terminationPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []v1alpha1.PipelineResourceResult
+	if err := json.Unmarshal(b, &results); err != nil {
+		t.Fatal(err)
+	}
+	expected := []v1alpha1.PipelineResourceResult{{Key: TimedOutResultKey, Value: "timed out after 1m0s"}}
+	if d := cmp.Diff(expected, results); d != "" {
+		t.Errorf("termination message diff -want, +got: %v", d)
+	}
+}
+
+func TestEntrypointerStepVariables(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entrypointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv(StepResultsEnvVar)
+
+	// The lint step is skipped, and its exit code referenced by the build
+	// step.
+	fpw := &fakePostWriter{}
+	err = Entrypointer{
+		Entrypoint:      "lint",
+		PostFile:        "lint-done",
+		SkipExitCodes:   []int{78},
+		TerminationPath: filepath.Join(dir, "termination-log"),
+		StepsDir:        dir,
+		StepName:        "lint",
+		Waiter:          &fakeWaiter{},
+		Runner:          &fakeExitCodeRunner{exitCode: 78},
+		PostWriter:      fpw,
+	}.Go()
+	if err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(dir, "lint", "exitCode")); err != nil || string(b) != "78" {
+		t.Errorf("Expected the exit code 78 to be written, got %q, %v", b, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(os.Getenv(StepResultsEnvVar), "report"), []byte("lint.xml\n"), 0666); err != nil {
+		t.Fatalf("Couldn't write a result in %s: %v", StepResultsEnvVar, err)
+	}
+
+	os.Setenv("TEST_LINTED", "linted: $(steps.lint.exitCode)")
+	defer os.Unsetenv("TEST_LINTED")
+	fr := &fakeRunner{}
+	err = Entrypointer{
+		Entrypoint: "build",
+		Args:       []string{"--report=$(steps.lint.results.report)"},
+		PostFile:   "build-done",
+		StepsDir:   dir,
+		Waiter:     &fakeWaiter{},
+		Runner:     fr,
+		PostWriter: fpw,
+	}.Go()
+	if err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+	if d := cmp.Diff([]string{"build", "--report=lint.xml"}, *fr.args); d != "" {
+		t.Errorf("Args diff -want, +got: %v", d)
+	}
+	if got := os.Getenv("TEST_LINTED"); got != "linted: 78" {
+		t.Errorf("Expected the env to be resolved to %q, got %q", "linted: 78", got)
+	}
+
+	err = Entrypointer{
+		Entrypoint: "build",
+		Args:       []string{"$(steps.lint.results.missing)"},
+		PostFile:   "build-done",
+		StepsDir:   dir,
+		Waiter:     &fakeWaiter{},
+		Runner:     &fakeRunner{},
+		PostWriter: fpw,
+	}.Go()
+	if err == nil {
+		t.Fatal("Expected the Entrypointer to fail resolving a missing result")
+	}
+	if *fpw.wrote != "build-done.err" {
+		t.Errorf("Wrote post file %q, want %q", *fpw.wrote, "build-done.err")
+	}
+}
+
 type fakeWaiter struct{ waited []string }
 
 func (f *fakeWaiter) Wait(file string, _ bool) error {
@@ -195,6 +466,10 @@ type fakePostWriter struct{ wrote *string }
 
 func (f *fakePostWriter) Write(file string) { f.wrote = &file }
 
+type recordingPostWriter struct{ wrote []string }
+
+func (f *recordingPostWriter) Write(file string) { f.wrote = append(f.wrote, file) }
+
 type fakeErrorWaiter struct{ waited *string }
 
 func (f *fakeErrorWaiter) Wait(file string, expectContent bool) error {
@@ -202,9 +477,75 @@ func (f *fakeErrorWaiter) Wait(file string, expectContent bool) error {
 	return xerrors.New("waiter failed")
 }
 
+type fakeTimeoutWaiter struct{}
+
+func (f *fakeTimeoutWaiter) Wait(file string, _ bool) error {
+	return WaitTimeoutError{File: file, Timeout: time.Minute}
+}
+
 type fakeErrorRunner struct{ args *[]string }
 
 func (f *fakeErrorRunner) Run(args ...string) error {
 	f.args = &args
 	return xerrors.New("runner failed")
 }
+
+type fakeTimeoutRunner struct{}
+
+func (f *fakeTimeoutRunner) Run(args ...string) error {
+	return TimeoutError{Timeout: time.Minute}
+}
+
+type exitCodeError int
+
+func (e exitCodeError) Error() string { return "exit status" }
+
+func (e exitCodeError) ExitCode() int { return int(e) }
+
+type fakeExitCodeRunner struct{ exitCode int }
+
+func (f *fakeExitCodeRunner) Run(args ...string) error {
+	return exitCodeError(f.exitCode)
+}
+
+func TestEntrypointerTaskResults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entrypointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	terminationPath := filepath.Join(dir, "termination-log")
+	resultsDir := filepath.Join(dir, "results")
+
+	err = Entrypointer{
+		Entrypoint:      "echo",
+		TerminationPath: terminationPath,
+		Results:         []string{"digest", "unwritten"},
+		ResultsDir:      resultsDir,
+		Runner:          &fakeResultRunner{file: filepath.Join(resultsDir, "digest"), content: "sha256:1234\n"},
+		PostWriter:      &fakePostWriter{},
+	}.Go()
+	if err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+	b, err := ioutil.ReadFile(terminationPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []v1alpha1.PipelineResourceResult
+	if err := json.Unmarshal(b, &results); err != nil {
+		t.Fatal(err)
+	}
+	expected := []v1alpha1.PipelineResourceResult{{Key: "digest", Value: "sha256:1234", ResultType: v1alpha1.TaskRunResultType}}
+	if d := cmp.Diff(expected, results); d != "" {
+		t.Errorf("termination message diff -want, +got: %v", d)
+	}
+}
+
+// fakeResultRunner writes a result to file, whose directory must have been
+// created.
+type fakeResultRunner struct{ file, content string }
+
+func (f *fakeResultRunner) Run(args ...string) error {
+	return ioutil.WriteFile(f.file, []byte(f.content), 0666)
+}
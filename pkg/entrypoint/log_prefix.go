@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// LinePrefixer writes the output of a step to W, with each line prefixed by
+// the time it started being written, in RFC3339, if Timestamps is set, and
+// by the name of the step in brackets, if StepName is set, e.g.
+// "2019-12-03T10:04:05.123456789Z [build] compiling", so that the logs of
+// the steps can be merged and timed whatever the log settings of the
+// container runtime. The lines are written as they come, without waiting for
+// their end. A LinePrefixer is not safe for concurrent use: the stdout and
+// stderr of a step each need their own.
+type LinePrefixer struct {
+	W          io.Writer
+	Timestamps bool
+	StepName   string
+	// Now returns the time of the timestamps, time.Now if nil.
+	Now func() time.Time
+
+	// midLine is whether the last byte written wasn't the end of a line.
+	midLine bool
+}
+
+var _ io.Writer = (*LinePrefixer)(nil)
+
+// Write writes b to W, prefixing the lines starting in b. It returns how
+// many bytes of b were written, not counting the prefixes.
+func (p *LinePrefixer) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		if !p.midLine {
+			if _, err := io.WriteString(p.W, p.prefix()); err != nil {
+				return written, err
+			}
+			p.midLine = true
+		}
+		line := b
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			line = b[:i+1]
+		}
+		n, err := p.W.Write(line)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		if line[len(line)-1] == '\n' {
+			p.midLine = false
+		}
+		b = b[len(line):]
+	}
+	return written, nil
+}
+
+func (p *LinePrefixer) prefix() string {
+	var prefix string
+	if p.Timestamps {
+		now := time.Now
+		if p.Now != nil {
+			now = p.Now
+		}
+		prefix = now().UTC().Format(time.RFC3339Nano) + " "
+	}
+	if p.StepName != "" {
+		prefix += "[" + p.StepName + "] "
+	}
+	return prefix
+}
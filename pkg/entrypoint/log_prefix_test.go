@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLinePrefixer(t *testing.T) {
+	now := time.Date(2019, 12, 3, 10, 4, 5, 0, time.UTC)
+	for _, c := range []struct {
+		desc       string
+		timestamps bool
+		stepName   string
+		writes     []string
+		want       string
+	}{{
+		desc:   "no prefix",
+		writes: []string{"hello\n", "world"},
+		want:   "hello\nworld",
+	}, {
+		desc:       "timestamps",
+		timestamps: true,
+		writes:     []string{"hello\nworld\n"},
+		want:       "2019-12-03T10:04:05Z hello\n2019-12-03T10:04:06Z world\n",
+	}, {
+		desc:     "step name",
+		stepName: "build",
+		writes:   []string{"hel", "lo\n\nwor", "ld"},
+		want:     "[build] hello\n[build] \n[build] world",
+	}, {
+		desc:       "timestamps and step name",
+		timestamps: true,
+		stepName:   "build",
+		writes:     []string{"hello\n", "world\n"},
+		want:       "2019-12-03T10:04:05Z [build] hello\n2019-12-03T10:04:06Z [build] world\n",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			tick := now
+			p := &LinePrefixer{W: &out, Timestamps: c.timestamps, StepName: c.stepName, Now: func() time.Time {
+				t := tick
+				tick = tick.Add(time.Second)
+				return t
+			}}
+			for _, w := range c.writes {
+				n, err := p.Write([]byte(w))
+				if err != nil {
+					t.Fatalf("Write(%q) = %v", w, err)
+				}
+				if n != len(w) {
+					t.Errorf("Write(%q) wrote %d bytes, want %d", w, n, len(w))
+				}
+			}
+			if got := out.String(); got != c.want {
+				t.Errorf("LinePrefixer wrote %q, want %q", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxProgressMessage is how many characters of the message of a progress
+// are kept, since it shares the termination message of the step, which
+// is limited to 4096 bytes, with its results.
+const maxProgressMessage = 256
+
+// Progress is the latest progress a step reported.
+type Progress struct {
+	// Step is the name of the step.
+	Step string `json:"step,omitempty"`
+	// Percent is how much of its work the step did, between 0 and 100.
+	Percent int `json:"percent"`
+	// Message is what the step is doing, if it said.
+	Message string `json:"message,omitempty"`
+}
+
+// ProgressReporter encapsulates reporting the progress of a step.
+type ProgressReporter interface {
+	// Report reports p, replacing the progress reported before.
+	Report(p Progress)
+}
+
+// ParseProgress parses the progress written by a step to its progress file:
+// its last non-empty line, made of a percentage, optionally followed by a
+// percent sign, and of an optional message, e.g. "42% compiling". It
+// returns false if there is no such line.
+func ParseProgress(b []byte) (Progress, bool) {
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	line := strings.TrimSpace(string(lines[len(lines)-1]))
+	if line == "" {
+		return Progress{}, false
+	}
+	fields := strings.SplitN(line, " ", 2)
+	percent, err := strconv.Atoi(strings.TrimSuffix(fields[0], "%"))
+	if err != nil || percent < 0 || percent > 100 {
+		return Progress{}, false
+	}
+	p := Progress{Percent: percent}
+	if len(fields) > 1 {
+		p.Message = strings.TrimSpace(fields[1])
+		if r := []rune(p.Message); len(r) > maxProgressMessage {
+			p.Message = string(r[:maxProgressMessage])
+		}
+	}
+	return p, true
+}
+
+// WriteProgress replaces the progress in the termination message file at
+// path with p, under ProgressResultKey. The controller reads it from the
+// status of the container once the step has finished, including if it was
+// killed after the progress was written.
+func WriteProgress(path string, p Progress) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return setResult(path, ProgressResultKey, string(b))
+}
+
+// relayProgress reports the progress the step writes to ProgressFile every
+// ProgressInterval, if it changed, until the returned function is called,
+// which reports it one last time.
+func (e Entrypointer) relayProgress() func() {
+	if e.ProgressFile == "" || e.ProgressReporter == nil || e.ProgressInterval <= 0 {
+		return func() {}
+	}
+	var last *Progress
+	relay := func() {
+		b, err := ioutil.ReadFile(e.ProgressFile)
+		if err != nil {
+			return
+		}
+		p, ok := ParseProgress(b)
+		if !ok {
+			return
+		}
+		p.Step = e.ProgressStep
+		if last != nil && *last == p {
+			return
+		}
+		last = &p
+		e.ProgressReporter.Report(p)
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(e.ProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				relay()
+			case <-stop:
+				relay()
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
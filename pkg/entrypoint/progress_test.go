@@ -0,0 +1,266 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"golang.org/x/xerrors"
+)
+
+func TestParseProgress(t *testing.T) {
+	for _, c := range []struct {
+		desc    string
+		content string
+		want    Progress
+		wantOK  bool
+	}{{
+		desc:    "percentage",
+		content: "42",
+		want:    Progress{Percent: 42},
+		wantOK:  true,
+	}, {
+		desc:    "percentage and message",
+		content: "42% compiling module foo\n",
+		want:    Progress{Percent: 42, Message: "compiling module foo"},
+		wantOK:  true,
+	}, {
+		desc:    "last line",
+		content: "10 fetching\n\n90 pushing\n\n",
+		want:    Progress{Percent: 90, Message: "pushing"},
+		wantOK:  true,
+	}, {
+		desc:    "empty",
+		content: "\n",
+	}, {
+		desc:    "not a percentage",
+		content: "compiling",
+	}, {
+		desc:    "above 100",
+		content: "101 done",
+	}, {
+		desc:    "negative",
+		content: "-1",
+	}, {
+		desc:    "long message",
+		content: "42 " + strings.Repeat("é", maxProgressMessage+1),
+		want:    Progress{Percent: 42, Message: strings.Repeat("é", maxProgressMessage)},
+		wantOK:  true,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			got, ok := ParseProgress([]byte(c.content))
+			if ok != c.wantOK {
+				t.Fatalf("ParseProgress(%q) = %t, want %t", c.content, ok, c.wantOK)
+			}
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("ParseProgress(%q) diff -want, +got: %v", c.content, d)
+			}
+		})
+	}
+}
+
+func TestWriteProgress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entrypointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "termination-log")
+	if err := writeResult(path, SkippedResultKey, "3"); err != nil {
+		t.Fatalf("writeResult: %v", err)
+	}
+	for _, p := range []Progress{{Step: "build", Percent: 10}, {Step: "build", Percent: 60, Message: "compiling"}} {
+		if err := WriteProgress(path, p); err != nil {
+			t.Fatalf("WriteProgress: %v", err)
+		}
+	}
+	got, err := readTerminationMessage(path)
+	if err != nil {
+		t.Fatalf("readTerminationMessage: %v", err)
+	}
+	// The progress replaces the one written before, and keeps the other
+	// results.
+	want := []v1alpha1.PipelineResourceResult{
+		{Key: SkippedResultKey, Value: "3"},
+		{Key: ProgressResultKey, Value: `{"step":"build","percent":60,"message":"compiling"}`},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Termination message (-want, +got): %s", d)
+	}
+}
+
+func TestEntrypointerProgress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entrypointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	progressFile := filepath.Join(dir, "progress")
+	// Left by a previous step.
+	if err := ioutil.WriteFile(progressFile, []byte("100 done"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	fpr := &fakeProgressReporter{}
+	runner := &fakeProgressRunner{file: progressFile, reporter: fpr}
+	err = Entrypointer{
+		Entrypoint:       "echo",
+		Runner:           runner,
+		PostWriter:       &fakePostWriter{},
+		ProgressFile:     progressFile,
+		ProgressInterval: time.Millisecond,
+		ProgressStep:     "build",
+		ProgressReporter: fpr,
+	}.Go()
+	if err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+	want := []Progress{
+		{Step: "build", Percent: 10, Message: "fetching"},
+		{Step: "build", Percent: 90, Message: "pushing"},
+	}
+	if d := cmp.Diff(want, fpr.reported()); d != "" {
+		t.Errorf("Reported progress diff -want, +got: %v", d)
+	}
+}
+
+func TestEntrypointerProgressOnceDone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entrypointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	progressFile := filepath.Join(dir, "progress")
+
+	// The progress written last is reported once the step is done, before
+	// the interval elapses.
+	fpr := &fakeProgressReporter{}
+	err = Entrypointer{
+		Entrypoint:       "echo",
+		Runner:           &fakeWritingRunner{file: progressFile, content: "75 testing"},
+		PostWriter:       &fakePostWriter{},
+		ProgressFile:     progressFile,
+		ProgressInterval: time.Hour,
+		ProgressReporter: fpr,
+	}.Go()
+	if err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+	if d := cmp.Diff([]Progress{{Percent: 75, Message: "testing"}}, fpr.reported()); d != "" {
+		t.Errorf("Reported progress diff -want, +got: %v", d)
+	}
+}
+
+func TestEntrypointerProgressUnchanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entrypointer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	progressFile := filepath.Join(dir, "progress")
+
+	// The progress is read again every interval while the step runs, but
+	// only reported once as it doesn't change.
+	fpr := &fakeProgressReporter{}
+	err = Entrypointer{
+		Entrypoint:       "echo",
+		Runner:           &fakeSlowRunner{file: progressFile, content: "50 halfway", reporter: fpr},
+		PostWriter:       &fakePostWriter{},
+		ProgressFile:     progressFile,
+		ProgressInterval: time.Millisecond,
+		ProgressStep:     "build",
+		ProgressReporter: fpr,
+	}.Go()
+	if err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+	if d := cmp.Diff([]Progress{{Step: "build", Percent: 50, Message: "halfway"}}, fpr.reported()); d != "" {
+		t.Errorf("Reported progress diff -want, +got: %v", d)
+	}
+}
+
+type fakeProgressReporter struct {
+	mu       sync.Mutex
+	progress []Progress
+}
+
+func (f *fakeProgressReporter) Report(p Progress) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.progress = append(f.progress, p)
+}
+
+func (f *fakeProgressReporter) reported() []Progress {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Progress{}, f.progress...)
+}
+
+// fakeProgressRunner writes two updates to file, waiting for the first one
+// to be reported before writing the second.
+type fakeProgressRunner struct {
+	file     string
+	reporter *fakeProgressReporter
+}
+
+func (f *fakeProgressRunner) Run(args ...string) error {
+	if err := ioutil.WriteFile(f.file, []byte("10 fetching\n"), 0666); err != nil {
+		return err
+	}
+	for deadline := time.Now().Add(5 * time.Second); len(f.reporter.reported()) == 0; {
+		if time.Now().After(deadline) {
+			return xerrors.New("the first progress wasn't reported")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return ioutil.WriteFile(f.file, []byte("10 fetching\n90 pushing\n"), 0666)
+}
+
+type fakeWritingRunner struct{ file, content string }
+
+func (f *fakeWritingRunner) Run(args ...string) error {
+	return ioutil.WriteFile(f.file, []byte(f.content), 0666)
+}
+
+// fakeSlowRunner writes content to file and keeps running for a while once
+// it's reported.
+type fakeSlowRunner struct {
+	file, content string
+	reporter      *fakeProgressReporter
+}
+
+func (f *fakeSlowRunner) Run(args ...string) error {
+	if err := ioutil.WriteFile(f.file, []byte(f.content), 0666); err != nil {
+		return err
+	}
+	for deadline := time.Now().Add(5 * time.Second); len(f.reporter.reported()) == 0; {
+		if time.Now().After(deadline) {
+			return xerrors.New("the progress wasn't reported")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	return nil
+}
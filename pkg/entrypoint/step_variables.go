@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package entrypoint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// StepResultsEnvVar is the environment variable holding the directory
+	// where a step referenced by later steps writes its results, one file
+	// per result.
+	StepResultsEnvVar = "TEKTON_STEP_RESULTS"
+
+	exitCodeFile = "exitCode"
+	resultsDir   = "results"
+)
+
+// prepareStepVariables creates the results directory of the step, if it is
+// referenced by later steps, and replaces the $(steps.<name>.<field>)
+// variables of the environment of the command.
+func (e Entrypointer) prepareStepVariables() error {
+	if e.StepName != "" {
+		dir := filepath.Join(e.StepsDir, e.StepName, resultsDir)
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			return xerrors.Errorf("couldn't create the results directory of step %q: %w", e.StepName, err)
+		}
+		if err := os.Setenv(StepResultsEnvVar, dir); err != nil {
+			return err
+		}
+	}
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || !strings.Contains(parts[1], "$(steps.") {
+			continue
+		}
+		value, err := e.resolveStepVariables(parts[1])
+		if err != nil {
+			return err
+		}
+		if err := os.Setenv(parts[0], value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveStepVariables replaces the $(steps.<name>.<field>) variables in s
+// with the exit code or the result of the referenced step, read from
+// StepsDir. Trailing newlines of the results are trimmed.
+func (e Entrypointer) resolveStepVariables(s string) (string, error) {
+	for _, v := range v1alpha1.StepVariables(s) {
+		if !v.Valid() {
+			return "", xerrors.Errorf("invalid step variable %s", v.Expression)
+		}
+		path := filepath.Join(e.StepsDir, v.Step, exitCodeFile)
+		if key, ok := v.ResultKey(); ok {
+			path = filepath.Join(e.StepsDir, v.Step, resultsDir, key)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", xerrors.Errorf("couldn't resolve %s: %w", v.Expression, err)
+		}
+		s = strings.Replace(s, v.Expression, strings.TrimRight(string(b), "\n"), -1)
+	}
+	return s, nil
+}
+
+// writeExitCode writes the exit code of the command, which returned err, to
+// the directory of the step under StepsDir. Nothing is written if the
+// command couldn't be run at all.
+func (e Entrypointer) writeExitCode(err error) error {
+	code := 0
+	if err != nil {
+		exitErr, ok := err.(interface{ ExitCode() int })
+		if !ok {
+			return nil
+		}
+		code = exitErr.ExitCode()
+	}
+	dir := filepath.Join(e.StepsDir, e.StepName)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return xerrors.Errorf("couldn't create the directory of step %q: %w", e.StepName, err)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, exitCodeFile), []byte(strconv.Itoa(code)), 0666)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package launch materializes the PipelineRuns of Pipelines, with their
+// params defaulted and their resources bound by convention, so that
+// triggers and integrations don't need to write them out.
+package launch
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"github.com/tektoncd/pipeline/pkg/names"
+	"golang.org/x/xerrors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Options customizes the PipelineRuns of Pipelines.
+type Options struct {
+	// Params are the values of the params of the Pipeline, which take
+	// precedence over their defaults. The params without a default must
+	// all be given.
+	Params []v1alpha1.Param
+	// Resources are the names of the PipelineResources bound to the
+	// resources the Pipeline declares, by the name of the declared
+	// resource, instead of the ones named by ResourceName.
+	Resources map[string]string
+	// ServiceAccountName is the service account the PipelineRun runs as,
+	// the default one of the namespace if empty.
+	ServiceAccountName string
+	// Labels are added to the PipelineRun.
+	Labels map[string]string
+}
+
+// ResourceName returns the name of the PipelineResource bound by
+// convention to the resource the Pipeline pipeline declares, e.g.
+// "build-source" for its "source" resource.
+func ResourceName(pipeline, resource string) string {
+	return pipeline + "-" + resource
+}
+
+// New gets the Pipeline of namespace with the given name, and returns a
+// PipelineRun of it with PipelineRun.
+func New(client versioned.Interface, namespace, name string, opts Options) (*v1alpha1.PipelineRun, error) {
+	p, err := client.TektonV1alpha1().Pipelines(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("getting Pipeline %q: %w", name, err)
+	}
+	return PipelineRun(p, opts)
+}
+
+// PipelineRun returns a PipelineRun of p, with a generated name and the
+// params and resources of opts. The params of p not in opts take their
+// default value, and its resources not in opts are bound to the
+// PipelineResources named by ResourceName.
+func PipelineRun(p *v1alpha1.Pipeline, opts Options) (*v1alpha1.PipelineRun, error) {
+	params, err := params(p, opts.Params)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := resources(p, opts.Resources)
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]string{pipeline.GroupName + pipeline.PipelineLabelKey: p.Name}
+	for k, v := range opts.Labels {
+		labels[k] = v
+	}
+	return &v1alpha1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(p.Name + "-run"),
+			Namespace: p.Namespace,
+			Labels:    labels,
+		},
+		Spec: v1alpha1.PipelineRunSpec{
+			PipelineRef:        v1alpha1.PipelineRef{Name: p.Name},
+			Params:             params,
+			Resources:          resources,
+			ServiceAccountName: opts.ServiceAccountName,
+		},
+	}, nil
+}
+
+// params returns the params of the PipelineRun of p, in the order p
+// declares them. A string value given for an array param is its only item.
+func params(p *v1alpha1.Pipeline, given []v1alpha1.Param) ([]v1alpha1.Param, error) {
+	values := map[string]v1alpha1.ArrayOrString{}
+	for _, param := range given {
+		values[param.Name] = param.Value
+	}
+	var params []v1alpha1.Param
+	var missing []string
+	for _, spec := range p.Spec.Params {
+		value, ok := values[spec.Name]
+		delete(values, spec.Name)
+		switch {
+		case ok:
+			if spec.Type == v1alpha1.ParamTypeArray && value.Type == v1alpha1.ParamTypeString {
+				value = v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeArray, ArrayVal: []string{value.StringVal}}
+			}
+		case spec.Default != nil:
+			value = *spec.Default.DeepCopy()
+		default:
+			missing = append(missing, spec.Name)
+			continue
+		}
+		params = append(params, v1alpha1.Param{Name: spec.Name, Value: value})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("Pipeline %q has no default for params %s, which must be given", p.Name, strings.Join(missing, ", "))
+	}
+	if len(values) > 0 {
+		return nil, fmt.Errorf("Pipeline %q doesn't declare params %s", p.Name, strings.Join(sortedKeys(values), ", "))
+	}
+	return params, nil
+}
+
+// resources returns the bindings of the resources p declares, in the order
+// it declares them.
+func resources(p *v1alpha1.Pipeline, given map[string]string) ([]v1alpha1.PipelineResourceBinding, error) {
+	declared := map[string]bool{}
+	var bindings []v1alpha1.PipelineResourceBinding
+	for _, r := range p.Spec.Resources {
+		declared[r.Name] = true
+		name, ok := given[r.Name]
+		if !ok {
+			name = ResourceName(p.Name, r.Name)
+		}
+		bindings = append(bindings, v1alpha1.PipelineResourceBinding{
+			Name:        r.Name,
+			ResourceRef: v1alpha1.PipelineResourceRef{Name: name},
+		})
+	}
+	var undeclared []string
+	for name := range given {
+		if !declared[name] {
+			undeclared = append(undeclared, name)
+		}
+	}
+	if len(undeclared) > 0 {
+		sort.Strings(undeclared)
+		return nil, fmt.Errorf("Pipeline %q doesn't declare resources %s", p.Name, strings.Join(undeclared, ", "))
+	}
+	return bindings, nil
+}
+
+func sortedKeys(m map[string]v1alpha1.ArrayOrString) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package launch
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	fakeclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	"github.com/tektoncd/pipeline/test/names"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNew(t *testing.T) {
+	names.TestingSeed()
+	p := tb.Pipeline("build", "foo", tb.PipelineSpec(
+		tb.PipelineDeclaredResource("source", "git"),
+		tb.PipelineDeclaredResource("image", "image"),
+		tb.PipelineParamSpec("revision", v1alpha1.ParamTypeString),
+		tb.PipelineParamSpec("env", v1alpha1.ParamTypeString, tb.ParamSpecDefault("staging")),
+		tb.PipelineParamSpec("tags", v1alpha1.ParamTypeArray, tb.ParamSpecDefault("latest", "stable")),
+		tb.PipelineParamSpec("flags", v1alpha1.ParamTypeArray),
+	))
+	client := fakeclientset.NewSimpleClientset(p)
+
+	got, err := New(client, "foo", "build", Options{
+		Params: []v1alpha1.Param{
+			{Name: "revision", Value: *tb.ArrayOrString("main")},
+			{Name: "flags", Value: *tb.ArrayOrString("-v")},
+		},
+		Resources:          map[string]string{"image": "registry-image"},
+		ServiceAccountName: "builder",
+		Labels:             map[string]string{"trigger": "push"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	want := &v1alpha1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "build-run-9l9zj",
+			Namespace: "foo",
+			Labels:    map[string]string{"tekton.dev/pipeline": "build", "trigger": "push"},
+		},
+		Spec: v1alpha1.PipelineRunSpec{
+			PipelineRef: v1alpha1.PipelineRef{Name: "build"},
+			Params: []v1alpha1.Param{
+				{Name: "revision", Value: *tb.ArrayOrString("main")},
+				{Name: "env", Value: *tb.ArrayOrString("staging")},
+				{Name: "tags", Value: *tb.ArrayOrString("latest", "stable")},
+				{Name: "flags", Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeArray, ArrayVal: []string{"-v"}}},
+			},
+			Resources: []v1alpha1.PipelineResourceBinding{
+				{Name: "source", ResourceRef: v1alpha1.PipelineResourceRef{Name: "build-source"}},
+				{Name: "image", ResourceRef: v1alpha1.PipelineResourceRef{Name: "registry-image"}},
+			},
+			ServiceAccountName: "builder",
+		},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff PipelineRun -want, +got: %s", d)
+	}
+}
+
+func TestPipelineRun_Invalid(t *testing.T) {
+	p := tb.Pipeline("build", "foo", tb.PipelineSpec(
+		tb.PipelineDeclaredResource("source", "git"),
+		tb.PipelineParamSpec("revision", v1alpha1.ParamTypeString),
+		tb.PipelineParamSpec("url", v1alpha1.ParamTypeString),
+		tb.PipelineParamSpec("env", v1alpha1.ParamTypeString, tb.ParamSpecDefault("staging")),
+	))
+	for _, tc := range []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{{
+		name:    "missing params",
+		wantErr: `Pipeline "build" has no default for params revision, url, which must be given`,
+	}, {
+		name: "undeclared params",
+		opts: Options{Params: []v1alpha1.Param{
+			{Name: "revision", Value: *tb.ArrayOrString("main")},
+			{Name: "url", Value: *tb.ArrayOrString("https://github.com/tektoncd/pipeline")},
+			{Name: "target", Value: *tb.ArrayOrString("prod")},
+			{Name: "debug", Value: *tb.ArrayOrString("true")},
+		}},
+		wantErr: `Pipeline "build" doesn't declare params debug, target`,
+	}, {
+		name: "undeclared resources",
+		opts: Options{
+			Params: []v1alpha1.Param{
+				{Name: "revision", Value: *tb.ArrayOrString("main")},
+				{Name: "url", Value: *tb.ArrayOrString("https://github.com/tektoncd/pipeline")},
+			},
+			Resources: map[string]string{"cache": "build-cache"},
+		},
+		wantErr: `Pipeline "build" doesn't declare resources cache`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := PipelineRun(p, tc.opts)
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			}
+			if d := cmp.Diff(tc.wantErr, err.Error()); d != "" {
+				t.Errorf("Diff error -want, +got: %s", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+)
+
+const (
+	// catalogAgentName defines logging agent name for the catalog verification controller
+	catalogAgentName = "catalog-controller"
+
+	// TaskKind and PipelineKind are the kinds of definitions the controller verifies.
+	TaskKind     = "Task"
+	PipelineKind = "Pipeline"
+
+	// ChecksumAnnotation holds the checksum of the spec of a Task or Pipeline.
+	ChecksumAnnotation = "catalog.tekton.dev/checksum"
+	// RevisionAnnotation holds the catalog revision a Task or Pipeline was
+	// verified against.
+	RevisionAnnotation = "catalog.tekton.dev/revision"
+	// VerifiedAnnotation says whether the spec of a Task or Pipeline matches
+	// the checksum pinned by the catalog.
+	VerifiedAnnotation = "catalog.tekton.dev/verified"
+
+	// ReasonCatalogDrift is the reason of the events emitted for definitions
+	// which differ from the catalog.
+	ReasonCatalogDrift = "CatalogDrift"
+)
+
+type configStore interface {
+	ToContext(ctx context.Context) context.Context
+	WatchConfigs(w configmap.Watcher)
+}
+
+// definition is a Task or Pipeline.
+type definition interface {
+	metav1.Object
+	runtime.Object
+}
+
+// Reconciler annotates the Tasks or Pipelines, depending on its kind, with
+// the checksum of their spec and, for the ones the catalog pins, with
+// whether they match the pinned catalog revision.
+type Reconciler struct {
+	*reconciler.Base
+
+	kind           string
+	taskLister     listers.TaskLister
+	pipelineLister listers.PipelineLister
+	configStore    configStore
+}
+
+// Check that our Reconciler implements controller.Reconciler
+var _ controller.Reconciler = (*Reconciler)(nil)
+
+// Reconcile updates the catalog annotations of the definition identified by key.
+func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		c.Logger.Errorf("invalid resource key: %s", key)
+		return nil
+	}
+	ctx = c.configStore.ToContext(ctx)
+
+	original, spec, err := c.get(namespace, name)
+	if errors.IsNotFound(err) {
+		c.Logger.Infof("%s %q in work queue no longer exists", c.kind, key)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	checksum, err := config.Checksum(spec)
+	if err != nil {
+		c.Logger.Errorf("Failed to compute the checksum of %s %q: %v", c.kind, key, err)
+		return nil
+	}
+	current := original.GetAnnotations()
+	want := map[string]string{ChecksumAnnotation: checksum}
+	catalog := config.FromContextOrDefaults(ctx).Catalog
+	if pinned, ok := catalog.Pinned(c.kind, name); ok {
+		verified := pinned == checksum
+		want[RevisionAnnotation] = catalog.Revision
+		want[VerifiedAnnotation] = strconv.FormatBool(verified)
+		if !verified && current[VerifiedAnnotation] != "false" {
+			c.Recorder.Eventf(original, corev1.EventTypeWarning, ReasonCatalogDrift,
+				"%s %s differs from catalog revision %q: expected checksum %s, got %s", c.kind, name, catalog.Revision, pinned, checksum)
+		}
+		// The webhook rejects any update of a drifting definition under the
+		// reject policy, the one of its annotations too, so it is only
+		// verified again once its spec or the catalog changes.
+		if !verified && catalog.Policy == config.CatalogPolicyReject {
+			return controller.NewPermanentError(fmt.Errorf("%s %q differs from catalog revision %q and can't be annotated", c.kind, key, catalog.Revision))
+		}
+	}
+
+	annotations := make(map[string]string, len(current)+len(want))
+	for k, v := range current {
+		// Drop the provenance of definitions the catalog doesn't pin anymore.
+		if k != RevisionAnnotation && k != VerifiedAnnotation {
+			annotations[k] = v
+		}
+	}
+	for k, v := range want {
+		annotations[k] = v
+	}
+	if equalAnnotations(current, annotations) {
+		return nil
+	}
+	return c.updateAnnotations(original, annotations)
+}
+
+func (c *Reconciler) get(namespace, name string) (definition, interface{}, error) {
+	if c.kind == PipelineKind {
+		p, err := c.pipelineLister.Pipelines(namespace).Get(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		return p, &p.Spec, nil
+	}
+	t, err := c.taskLister.Tasks(namespace).Get(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, &t.Spec, nil
+}
+
+func (c *Reconciler) updateAnnotations(original definition, annotations map[string]string) error {
+	// Don't modify the informer's copy.
+	d := original.DeepCopyObject().(definition)
+	d.SetAnnotations(annotations)
+	var err error
+	switch d := d.(type) {
+	case *v1alpha1.Pipeline:
+		_, err = c.PipelineClientSet.TektonV1alpha1().Pipelines(d.Namespace).Update(d)
+	case *v1alpha1.Task:
+		_, err = c.PipelineClientSet.TektonV1alpha1().Tasks(d.Namespace).Update(d)
+	}
+	return err
+}
+
+func equalAnnotations(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
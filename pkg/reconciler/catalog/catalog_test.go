@@ -0,0 +1,153 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	cfgtesting "github.com/tektoncd/pipeline/pkg/apis/config/testing"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	"github.com/tektoncd/pipeline/pkg/system"
+	"github.com/tektoncd/pipeline/test"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+)
+
+func TestReconcileTask(t *testing.T) {
+	spec := tb.Task("golang-build", "foo", tb.TaskSpec(tb.Step("build", "golang:1.12"))).Spec
+	checksum, err := config.Checksum(&spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinned := map[string]string{"task.golang-build": checksum}
+
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		image       string
+		checksums   map[string]string
+		want        map[string]string
+	}{{
+		name:      "matches the catalog",
+		image:     "golang:1.12",
+		checksums: pinned,
+		want: map[string]string{
+			ChecksumAnnotation: checksum,
+			RevisionAnnotation: "v0.8.0",
+			VerifiedAnnotation: "true",
+		},
+	}, {
+		name:      "differs from the catalog",
+		image:     "golang:1.13",
+		checksums: pinned,
+		want: map[string]string{
+			RevisionAnnotation: "v0.8.0",
+			VerifiedAnnotation: "false",
+		},
+	}, {
+		name:        "not pinned anymore",
+		annotations: map[string]string{"owner": "ci", RevisionAnnotation: "v0.7.0", VerifiedAnnotation: "true"},
+		image:       "golang:1.12",
+		want: map[string]string{
+			"owner":            "ci",
+			ChecksumAnnotation: checksum,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			task := tb.Task("golang-build", "foo", tb.TaskSpec(tb.Step("build", tc.image)))
+			task.Annotations = tc.annotations
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			c, _ := test.SeedTestData(t, ctx, test.Data{Tasks: []*v1alpha1.Task{task}})
+			impl := NewTaskController(pipeline.Images{})(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+
+			impl.Reconciler.(*Reconciler).configStore = cfgtesting.StaticConfigStore{Config: &config.Config{
+				Catalog: &config.Catalog{Revision: "v0.8.0", Policy: config.CatalogPolicyAnnotate, Checksums: tc.checksums},
+			}}
+			if err := impl.Reconciler.Reconcile(ctx, "foo/golang-build"); err != nil {
+				t.Fatalf("Unexpected error reconciling task: %v", err)
+			}
+			got, err := c.Pipeline.TektonV1alpha1().Tasks("foo").Get("golang-build", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.want[ChecksumAnnotation] == "" {
+				// The checksum of a drifted Task is whatever it is.
+				delete(got.Annotations, ChecksumAnnotation)
+			}
+			if d := cmp.Diff(tc.want, got.Annotations); d != "" {
+				t.Errorf("annotations diff -want, +got: %v", d)
+			}
+		})
+	}
+}
+
+func TestReconcileTaskRejected(t *testing.T) {
+	task := tb.Task("golang-build", "foo", tb.TaskSpec(tb.Step("build", "golang:1.13")))
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, _ := test.SeedTestData(t, ctx, test.Data{Tasks: []*v1alpha1.Task{task}})
+	impl := NewTaskController(pipeline.Images{})(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+
+	c.Pipeline.ClearActions()
+	impl.Reconciler.(*Reconciler).configStore = cfgtesting.StaticConfigStore{Config: &config.Config{
+		Catalog: &config.Catalog{Revision: "v0.8.0", Policy: config.CatalogPolicyReject, Checksums: map[string]string{"task.golang-build": "sha256:pinned"}},
+	}}
+	err := impl.Reconciler.Reconcile(ctx, "foo/golang-build")
+	if !controller.IsPermanentError(err) {
+		t.Errorf("Expected a permanent error not to requeue the drifting task forever, got %v", err)
+	}
+	for _, a := range c.Pipeline.Actions() {
+		if a.GetVerb() == "update" {
+			t.Errorf("Expected no update the webhook would reject, got %v", a)
+		}
+	}
+}
+
+func TestReconcilePipelineUpToDate(t *testing.T) {
+	p := tb.Pipeline("release", "foo", tb.PipelineSpec(tb.PipelineTask("build", "golang-build")))
+	checksum, err := config.Checksum(&p.Spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Annotations = map[string]string{ChecksumAnnotation: checksum}
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, _ := test.SeedTestData(t, ctx, test.Data{Pipelines: []*v1alpha1.Pipeline{p}})
+	impl := NewPipelineController(pipeline.Images{})(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+
+	c.Pipeline.ClearActions()
+	impl.Reconciler.(*Reconciler).configStore = cfgtesting.StaticConfigStore{Config: &config.Config{Catalog: &config.Catalog{Policy: config.CatalogPolicyAnnotate}}}
+	if err := impl.Reconciler.Reconcile(ctx, "foo/release"); err != nil {
+		t.Fatalf("Unexpected error reconciling pipeline: %v", err)
+	}
+	for _, a := range c.Pipeline.Actions() {
+		if a.GetVerb() == "update" {
+			t.Errorf("Expected no update of an up to date Pipeline, got %v", a)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
+	pipelineinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipeline"
+	taskinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/task"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// resyncPeriod also picks up changes to the pinned catalog.
+	resyncPeriod = 10 * time.Hour
+)
+
+// NewTaskController returns a constructor for the controller verifying Tasks
+// against the catalog.
+func NewTaskController(images pipeline.Images) func(context.Context, configmap.Watcher) *controller.Impl {
+	return newController(images, TaskKind)
+}
+
+// NewPipelineController returns a constructor for the controller verifying
+// Pipelines against the catalog.
+func NewPipelineController(images pipeline.Images) func(context.Context, configmap.Watcher) *controller.Impl {
+	return newController(images, PipelineKind)
+}
+
+func newController(images pipeline.Images, kind string) func(context.Context, configmap.Watcher) *controller.Impl {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		logger := logging.FromContext(ctx)
+		taskInformer := taskinformer.Get(ctx)
+		pipelineInformer := pipelineinformer.Get(ctx)
+
+		opt := reconciler.Options{
+			KubeClientSet:     kubeclient.Get(ctx),
+			PipelineClientSet: pipelineclient.Get(ctx),
+			ConfigMapWatcher:  cmw,
+			ResyncPeriod:      resyncPeriod,
+			Logger:            logger,
+		}
+
+		c := &Reconciler{
+			Base:           reconciler.NewBase(opt, catalogAgentName, images),
+			kind:           kind,
+			taskLister:     taskInformer.Lister(),
+			pipelineLister: pipelineInformer.Lister(),
+		}
+		impl := controller.NewImpl(c, c.Logger, "Catalog"+kind)
+		if err := reconciler.TrackQueueLatency(impl, "Catalog"+kind, kind, clock.RealClock{}); err != nil {
+			logger.Errorf("Failed to track the queue latency of %s: %v", "Catalog"+kind, err)
+		}
+
+		c.configStore = config.NewStore(c.Logger.Named("config-store"), func(name string, _ interface{}) {
+			// Verify everything again against the new catalog.
+			if name == config.CatalogConfigName {
+				impl.GlobalResync(informerFor(kind, taskInformer.Informer(), pipelineInformer.Informer()))
+			}
+		})
+		c.configStore.WatchConfigs(opt.ConfigMapWatcher)
+
+		c.Logger.Info("Setting up event handlers")
+		informerFor(kind, taskInformer.Informer(), pipelineInformer.Informer()).AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    impl.Enqueue,
+			UpdateFunc: controller.PassNew(impl.Enqueue),
+		})
+
+		return impl
+	}
+}
+
+func informerFor(kind string, taskInformer, pipelineInformer cache.SharedIndexInformer) cache.SharedIndexInformer {
+	if kind == PipelineKind {
+		return pipelineInformer
+	}
+	return taskInformer
+}
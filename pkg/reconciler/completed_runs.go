@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"k8s.io/client-go/tools/cache"
+)
+
+// completable is a run which finishes, e.g. a TaskRun or a PipelineRun.
+type completable interface {
+	// HasCompleted returns whether the run finished, whatever its outcome.
+	HasCompleted() bool
+}
+
+// IsCompletedRun returns whether obj is a run whose terminal condition is
+// set. Objects which aren't runs, e.g. tombstones, never are.
+func IsCompletedRun(obj interface{}) bool {
+	run, ok := obj.(completable)
+	return ok && run.HasCompleted()
+}
+
+// SkipCompletedRuns returns the handlers h, whose UpdateFunc ignores the
+// updates of the runs which are completed both before and after them. Once
+// its run completed and the reconcile following the completion is done, a
+// reconciler has nothing left to do, the TTL of the run being handled by the
+// expiration controllers, so that the resyncs of the finished runs kept
+// around, possibly for months, don't keep it busy. The update completing a
+// run still goes through, as does the one running a completed run again,
+// e.g. the retry of a TaskRun of a PipelineRun. AddFunc and DeleteFunc are
+// left as is, for the runs which completed while the controller was down to
+// be reconciled once more on its start, e.g. to send their cloud events.
+func SkipCompletedRuns(h cache.ResourceEventHandlerFuncs) cache.ResourceEventHandlerFuncs {
+	skipping := h
+	if h.UpdateFunc != nil {
+		skipping.UpdateFunc = func(old, cur interface{}) {
+			if !IsCompletedRun(old) || !IsCompletedRun(cur) {
+				h.UpdateFunc(old, cur)
+			}
+		}
+	}
+	return skipping
+}
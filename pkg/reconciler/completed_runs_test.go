@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	fakepipelineclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	informers "github.com/tektoncd/pipeline/pkg/client/informers/externalversions"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+)
+
+func TestSkipCompletedRuns(t *testing.T) {
+	running := tb.TaskRun("running", "foo", tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionUnknown,
+	})))
+	completed := tb.TaskRun("completed", "foo", tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+	})))
+	completedPipelineRun := tb.PipelineRun("completed", "foo", tb.PipelineRunSpec("pipeline"), tb.PipelineRunStatus(tb.PipelineRunStatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionFalse,
+	})))
+
+	var got []string
+	record := func(event string) func(interface{}) {
+		return func(obj interface{}) {
+			if m, ok := obj.(interface{ GetName() string }); ok {
+				got = append(got, event+" "+m.GetName())
+			} else {
+				got = append(got, event)
+			}
+		}
+	}
+	h := SkipCompletedRuns(cache.ResourceEventHandlerFuncs{
+		AddFunc: record("add"),
+		UpdateFunc: func(_, cur interface{}) {
+			record("update")(cur)
+		},
+		DeleteFunc: record("delete"),
+	})
+
+	h.OnAdd(running)
+	h.OnAdd(completed)
+	h.OnAdd(completedPipelineRun)
+	h.OnUpdate(running, running)
+	h.OnUpdate(running, completed)
+	h.OnUpdate(completed, completed)
+	h.OnUpdate(completed, running)
+	h.OnUpdate(completedPipelineRun, completedPipelineRun)
+	h.OnDelete(completed)
+	h.OnDelete(cache.DeletedFinalStateUnknown{Key: "foo/completed", Obj: completed})
+
+	want := []string{"add running", "add completed", "add completed", "update running", "update completed", "update running", "delete completed", "delete"}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("SkipCompletedRuns() handled events (-want, +got): %s", d)
+	}
+}
+
+func TestSkipCompletedRunsWithoutHandlers(t *testing.T) {
+	h := SkipCompletedRuns(cache.ResourceEventHandlerFuncs{})
+	if h.AddFunc != nil || h.UpdateFunc != nil || h.DeleteFunc != nil {
+		t.Errorf("SkipCompletedRuns() set handlers which weren't set")
+	}
+	h.OnAdd(&v1alpha1.TaskRun{})
+}
+
+// TestSkipCompletedRunsRetry goes through an informer the updates the
+// PipelineRun reconciler makes to retry a failed TaskRun: the one of its
+// spec, e.g. with the pod template of the retry, which leaves it failed, then
+// the one of its status, which runs it again.
+func TestSkipCompletedRunsRetry(t *testing.T) {
+	failed := tb.TaskRun("retried", "foo", tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionFalse,
+	})))
+	client := fakepipelineclientset.NewSimpleClientset(failed)
+	factory := informers.NewSharedInformerFactory(client, 0)
+	informer := factory.Tekton().V1alpha1().TaskRuns().Informer()
+
+	events := make(chan string, 10)
+	informer.AddEventHandler(SkipCompletedRuns(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			events <- "add " + obj.(*v1alpha1.TaskRun).Name
+		},
+		UpdateFunc: func(_, cur interface{}) {
+			tr := cur.(*v1alpha1.TaskRun)
+			events <- "update " + tr.Name + " " + string(tr.Status.GetCondition(apis.ConditionSucceeded).Status)
+		},
+	}))
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+
+	next := func() string {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			return "none"
+		}
+	}
+	if event := next(); event != "add retried" {
+		t.Errorf("got event %q on start, want the completed TaskRun added", event)
+	}
+
+	tr := failed.DeepCopy()
+	tr.Spec.PodTemplate.NodeSelector = map[string]string{"retry": "true"}
+	tr, err := client.TektonV1alpha1().TaskRuns("foo").Update(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.Status.RetriesStatus = append(tr.Status.RetriesStatus, tr.Status)
+	tr.Status.SetCondition(&apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionUnknown,
+	})
+	if _, err := client.TektonV1alpha1().TaskRuns("foo").UpdateStatus(tr); err != nil {
+		t.Fatal(err)
+	}
+	if event := next(); event != "update retried Unknown" {
+		t.Errorf("got event %q on the retry, want the TaskRun updated", event)
+	}
+}
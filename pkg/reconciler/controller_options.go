@@ -0,0 +1,211 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"time"
+
+	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/entrypoint"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+)
+
+// ConfigStore is a store of the configuration of a reconciler, kept up to
+// date from ConfigMaps.
+type ConfigStore interface {
+	ToContext(ctx context.Context) context.Context
+	WatchConfigs(w configmap.Watcher)
+}
+
+// ControllerOptions are the dependencies of a controller which platforms
+// embedding it in their own binary may replace.
+type ControllerOptions struct {
+	// KubeClientSet and PipelineClientSet default to the clients injected in
+	// the context of the controller.
+	KubeClientSet     kubernetes.Interface
+	PipelineClientSet clientset.Interface
+	// Clock tells the expiration controllers the time runs expire at. It
+	// defaults to the real clock.
+	Clock clock.Clock
+	// NewConfigStore, if set, returns the store which replaces the one the
+	// controller named name watches the ConfigMaps of its configuration
+	// with. Each controller gets its own, since they don't read the same
+	// configuration, and each watches the ConfigMaps of its store.
+	NewConfigStore func(name string) ConfigStore
+	// Filter, if set, restricts the objects the controller reconciles to
+	// the ones for which it returns true. The events of the objects the
+	// runs own, e.g. their pods, are only handled if it accepts their run.
+	Filter func(obj interface{}) bool
+	// ClusterTaskAccessReview makes the TaskRun controller fail the
+	// TaskRuns whose creators aren't allowed to use the ClusterTask they
+	// reference, as told by a SubjectAccessReview.
+	ClusterTaskAccessReview bool
+	// EntrypointCache, if set, is the cache of the entrypoints of the step
+	// images the TaskRun controller shares with the ImagePrefetch controller.
+	EntrypointCache *entrypoint.Cache
+	// BucketElector, if set, restricts the runs the expiration controllers
+	// clean up to the buckets this replica leads, so that replicas running
+	// side by side don't clean them up twice. The other controllers aren't
+	// elected.
+	BucketElector *BucketElector
+	// DebounceInterval, if positive, bounds the rate at which the events of
+	// the pods of a TaskRun, and of the TaskRuns of a PipelineRun, enqueue
+	// their run to one every DebounceInterval.
+	DebounceInterval time.Duration
+}
+
+// ControllerOption sets one of the ControllerOptions.
+type ControllerOption func(*ControllerOptions)
+
+// WithKubeClientSet makes the controller use kubeClientSet.
+func WithKubeClientSet(kubeClientSet kubernetes.Interface) ControllerOption {
+	return func(o *ControllerOptions) {
+		o.KubeClientSet = kubeClientSet
+	}
+}
+
+// WithPipelineClientSet makes the controller use pipelineClientSet.
+func WithPipelineClientSet(pipelineClientSet clientset.Interface) ControllerOption {
+	return func(o *ControllerOptions) {
+		o.PipelineClientSet = pipelineClientSet
+	}
+}
+
+// WithClock makes the controller tell the time with c.
+func WithClock(c clock.Clock) ControllerOption {
+	return func(o *ControllerOptions) {
+		o.Clock = c
+	}
+}
+
+// WithConfigStore makes the controller read its configuration from s. s
+// can only serve a single controller, see WithConfigStores for several.
+func WithConfigStore(s ConfigStore) ControllerOption {
+	return WithConfigStores(func(string) ConfigStore { return s })
+}
+
+// WithConfigStores makes each controller read its configuration from the
+// store newStore returns for its name.
+func WithConfigStores(newStore func(name string) ConfigStore) ControllerOption {
+	return func(o *ControllerOptions) {
+		o.NewConfigStore = newStore
+	}
+}
+
+// WithFilter makes the controller only reconcile the objects for which
+// filter returns true.
+func WithFilter(filter func(obj interface{}) bool) ControllerOption {
+	return func(o *ControllerOptions) {
+		o.Filter = filter
+	}
+}
+
+// WithClusterTaskAccessReview makes the TaskRun controller review whether
+// the creators of the TaskRuns may use the ClusterTasks they reference.
+func WithClusterTaskAccessReview() ControllerOption {
+	return func(o *ControllerOptions) {
+		o.ClusterTaskAccessReview = true
+	}
+}
+
+// WithEntrypointCache makes the controller cache the entrypoints of the step
+// images in cache.
+func WithEntrypointCache(cache *entrypoint.Cache) ControllerOption {
+	return func(o *ControllerOptions) {
+		o.EntrypointCache = cache
+	}
+}
+
+// WithBucketElector makes the expiration controllers only clean up the runs
+// of the buckets e elects this replica to lead.
+func WithBucketElector(e *BucketElector) ControllerOption {
+	return func(o *ControllerOptions) {
+		o.BucketElector = e
+	}
+}
+
+// WithDebounceInterval makes the controllers enqueue the runs their pods or
+// TaskRuns update at most once every interval.
+func WithDebounceInterval(interval time.Duration) ControllerOption {
+	return func(o *ControllerOptions) {
+		o.DebounceInterval = interval
+	}
+}
+
+// NewControllerOptions returns the ControllerOptions set by opts, with the
+// defaults for the ones they don't set.
+func NewControllerOptions(ctx context.Context, opts ...ControllerOption) ControllerOptions {
+	o := ControllerOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.KubeClientSet == nil {
+		o.KubeClientSet = kubeclient.Get(ctx)
+	}
+	if o.PipelineClientSet == nil {
+		o.PipelineClientSet = pipelineclient.Get(ctx)
+	}
+	if o.Clock == nil {
+		o.Clock = clock.RealClock{}
+	}
+	return o
+}
+
+// ConfigStore returns the store the controller named name reads its
+// configuration from, or nil if it is to create its own.
+func (o ControllerOptions) ConfigStore(name string) ConfigStore {
+	if o.NewConfigStore == nil {
+		return nil
+	}
+	return o.NewConfigStore(name)
+}
+
+// FilterFunc returns the filter of the objects the controller reconciles,
+// which accepts all of them if no Filter is set.
+func (o ControllerOptions) FilterFunc() func(obj interface{}) bool {
+	if o.Filter == nil {
+		return func(interface{}) bool { return true }
+	}
+	return o.Filter
+}
+
+// OwnerFilterFunc returns the filter of the objects controlled by a run of
+// kind gvk, e.g. the pods of the TaskRuns, which only accepts the ones whose
+// run, as returned by get, the Filter accepts.
+func (o ControllerOptions) OwnerFilterFunc(gvk schema.GroupVersionKind, get func(namespace, name string) (interface{}, error)) func(obj interface{}) bool {
+	ownedByRun := controller.Filter(gvk)
+	if o.Filter == nil {
+		return ownedByRun
+	}
+	return func(obj interface{}) bool {
+		if !ownedByRun(obj) {
+			return false
+		}
+		object := obj.(metav1.Object)
+		run, err := get(object.GetNamespace(), metav1.GetControllerOf(object).Name)
+		// The runs which aren't known yet can't be reconciled either.
+		return err == nil && o.Filter(run)
+	}
+}
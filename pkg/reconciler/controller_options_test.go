@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	fakepipelineclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
+	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/configmap"
+)
+
+type fakeConfigStore struct{}
+
+func (fakeConfigStore) ToContext(ctx context.Context) context.Context { return ctx }
+func (fakeConfigStore) WatchConfigs(configmap.Watcher)                {}
+
+func TestNewControllerOptionsDefaults(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	o := NewControllerOptions(ctx)
+
+	if o.KubeClientSet != kubeclient.Get(ctx) {
+		t.Error("Expected the injected kube client by default")
+	}
+	if o.PipelineClientSet != pipelineclient.Get(ctx) {
+		t.Error("Expected the injected pipeline client by default")
+	}
+	if _, ok := o.Clock.(clock.RealClock); !ok {
+		t.Errorf("Expected the real clock by default, got %T", o.Clock)
+	}
+	if s := o.ConfigStore("TaskRun"); s != nil {
+		t.Errorf("Expected no config store by default, got %T", s)
+	}
+	if !o.FilterFunc()(tb.TaskRun("test-taskrun", "foo")) {
+		t.Error("Expected all objects to be reconciled by default")
+	}
+}
+
+func TestNewControllerOptions(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	kubeClientSet := fakekubeclientset.NewSimpleClientset()
+	pipelineClientSet := fakepipelineclientset.NewSimpleClientset()
+	c := clock.NewFakeClock(time.Now())
+	o := NewControllerOptions(ctx,
+		WithKubeClientSet(kubeClientSet),
+		WithPipelineClientSet(pipelineClientSet),
+		WithClock(c),
+		WithConfigStore(fakeConfigStore{}),
+		WithFilter(func(obj interface{}) bool {
+			m, ok := obj.(metav1.Object)
+			return ok && m.GetNamespace() == "foo"
+		}),
+	)
+
+	if o.KubeClientSet != kubeClientSet {
+		t.Error("Expected the kube client of the options")
+	}
+	if o.PipelineClientSet != pipelineClientSet {
+		t.Error("Expected the pipeline client of the options")
+	}
+	if o.Clock != c {
+		t.Error("Expected the clock of the options")
+	}
+	if s, ok := o.ConfigStore("TaskRun").(fakeConfigStore); !ok {
+		t.Errorf("Expected the config store of the options, got %T", s)
+	}
+	if !o.FilterFunc()(tb.TaskRun("test-taskrun", "foo")) {
+		t.Error("Expected the TaskRun in foo to be reconciled")
+	}
+	if o.FilterFunc()(tb.TaskRun("test-taskrun", "bar")) {
+		t.Error("Expected the TaskRun in bar not to be reconciled")
+	}
+}
+
+func TestConfigStores(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	var names []string
+	o := NewControllerOptions(ctx, WithConfigStores(func(name string) ConfigStore {
+		names = append(names, name)
+		return fakeConfigStore{}
+	}))
+
+	o.ConfigStore("TaskRun")
+	o.ConfigStore("PipelineRun")
+	if d := cmp.Diff([]string{"TaskRun", "PipelineRun"}, names); d != "" {
+		t.Errorf("Expected a config store for each controller, diff -want, +got: %v", d)
+	}
+}
+
+func TestOwnerFilterFunc(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	runs := map[string]*v1alpha1.TaskRun{
+		"foo/in":  tb.TaskRun("in", "foo", tb.TaskRunLabel("managed", "true")),
+		"foo/out": tb.TaskRun("out", "foo"),
+	}
+	get := func(namespace, name string) (interface{}, error) {
+		if tr, ok := runs[namespace+"/"+name]; ok {
+			return tr, nil
+		}
+		return nil, errors.NewNotFound(v1alpha1.Resource("taskrun"), name)
+	}
+	podOf := func(run string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            run + "-pod",
+			Namespace:       "foo",
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(tb.TaskRun(run, "foo"), v1alpha1.SchemeGroupVersion.WithKind("TaskRun"))},
+		}}
+	}
+	gvk := v1alpha1.SchemeGroupVersion.WithKind("TaskRun")
+
+	filter := NewControllerOptions(ctx, WithFilter(func(obj interface{}) bool {
+		m, ok := obj.(metav1.Object)
+		return ok && m.GetLabels()["managed"] == "true"
+	})).OwnerFilterFunc(gvk, get)
+	for _, tc := range []struct {
+		name string
+		obj  interface{}
+		want bool
+	}{{
+		name: "pod of an accepted run",
+		obj:  podOf("in"),
+		want: true,
+	}, {
+		name: "pod of a filtered out run",
+		obj:  podOf("out"),
+	}, {
+		name: "pod of an unknown run",
+		obj:  podOf("unknown"),
+	}, {
+		name: "pod of no run",
+		obj:  &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "foo"}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := filter(tc.obj); got != tc.want {
+				t.Errorf("OwnerFilterFunc() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+
+	if !NewControllerOptions(ctx).OwnerFilterFunc(gvk, get)(podOf("out")) {
+		t.Error("Expected the pods of all runs to be accepted without a Filter")
+	}
+}
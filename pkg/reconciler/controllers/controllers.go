@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controllers gathers the constructors of the core Tekton Pipelines
+// controllers, so that they can be run by binaries other than
+// cmd/controller, alongside controllers of their own.
+package controllers
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/imageprefetch"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/entrypoint"
+	"golang.org/x/xerrors"
+	"knative.dev/pkg/injection"
+)
+
+// Core returns the constructors of the TaskRun and PipelineRun controllers,
+// of their expiration controllers, which only clean up the runs in scope,
+// and of the ImagePrefetch controller. opts replace the dependencies of all
+// of them.
+func Core(images pipeline.Images, scope taskrun.ExpirationScope, opts ...reconciler.ControllerOption) ([]injection.ControllerConstructor, error) {
+	// The ImagePrefetch controller pre-warms the entrypoint cache of the
+	// TaskRun controller, unless opts replace it.
+	cache, err := entrypoint.NewCache()
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't create the entrypoint cache: %w", err)
+	}
+	opts = append([]reconciler.ControllerOption{reconciler.WithEntrypointCache(cache)}, opts...)
+	return []injection.ControllerConstructor{
+		taskrun.NewController(images, opts...),
+		taskrun.NewExpirationController(images, scope, opts...),
+		pipelinerun.NewController(images, opts...),
+		pipelinerun.NewExpirationController(images, scope, opts...),
+		imageprefetch.NewController(images, opts...),
+	}, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/kmeta"
+)
+
+// Debouncer bounds the rate at which the keys of a controller are enqueued,
+// e.g. a TaskRun whose pod gets many status updates per second, to one every
+// interval per key. The events of a key during the interval following its
+// last enqueue are collapsed into a single enqueue at the end of the
+// interval, so that the reconcile following the last event, which reads the
+// latest state from the listers, always happens.
+type Debouncer struct {
+	enqueueKeyAfter func(key string, delay time.Duration)
+	interval        time.Duration
+	clock           clock.Clock
+
+	mu sync.Mutex
+	// last holds the times the keys were last enqueued at, or will be if
+	// that time is to come.
+	last      map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewDebouncer returns a Debouncer enqueueing the keys with
+// enqueueKeyAfter, e.g. controller.Impl.EnqueueKeyAfter, at most once every
+// interval. The keys are enqueued right away if interval isn't positive.
+func NewDebouncer(enqueueKeyAfter func(key string, delay time.Duration), interval time.Duration, c clock.Clock) *Debouncer {
+	return &Debouncer{
+		enqueueKeyAfter: enqueueKeyAfter,
+		interval:        interval,
+		clock:           c,
+		last:            map[string]time.Time{},
+	}
+}
+
+// EnqueueKey enqueues key right away if it wasn't enqueued during the last
+// interval, and at the end of the interval following its last enqueue
+// otherwise.
+func (d *Debouncer) EnqueueKey(key string) {
+	if d.interval <= 0 {
+		d.enqueueKeyAfter(key, 0)
+		return
+	}
+	now := d.clock.Now()
+	d.mu.Lock()
+	d.sweep(now)
+	last, ok := d.last[key]
+	var delay time.Duration
+	switch {
+	case !ok || !now.Before(last.Add(d.interval)):
+		d.last[key] = now
+	case last.After(now):
+		// The enqueue to come picks up this event as well.
+		d.mu.Unlock()
+		return
+	default:
+		d.last[key] = last.Add(d.interval)
+		delay = last.Add(d.interval).Sub(now)
+	}
+	d.mu.Unlock()
+	d.enqueueKeyAfter(key, delay)
+}
+
+// EnqueueControllerOf enqueues the key of the controller of obj, as
+// controller.Impl.EnqueueControllerOf does, with EnqueueKey.
+func (d *Debouncer) EnqueueControllerOf(obj interface{}) {
+	object, err := kmeta.DeletionHandlingAccessor(obj)
+	if err != nil {
+		return
+	}
+	if owner := metav1.GetControllerOf(object); owner != nil {
+		d.EnqueueKey(object.GetNamespace() + "/" + owner.Name)
+	}
+}
+
+// sweep forgets, at most once every interval, the keys whose last enqueue
+// is older than the interval, so that the finished runs don't pile up.
+func (d *Debouncer) sweep(now time.Time) {
+	if now.Before(d.lastSweep.Add(d.interval)) {
+		return
+	}
+	d.lastSweep = now
+	for key, last := range d.last {
+		if !now.Before(last.Add(d.interval)) {
+			delete(d.last, key)
+		}
+	}
+}
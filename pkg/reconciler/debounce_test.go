@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+type enqueued struct {
+	Key   string
+	Delay time.Duration
+}
+
+func TestDebouncer(t *testing.T) {
+	c := clock.NewFakeClock(time.Date(2019, time.October, 1, 12, 0, 0, 0, time.UTC))
+	var got []enqueued
+	d := NewDebouncer(func(key string, delay time.Duration) {
+		got = append(got, enqueued{Key: key, Delay: delay})
+	}, 2*time.Second, c)
+
+	// The first event is enqueued right away, the next ones of the interval
+	// at its end, once.
+	d.EnqueueKey("foo/bar")
+	c.Step(500 * time.Millisecond)
+	d.EnqueueKey("foo/bar")
+	c.Step(500 * time.Millisecond)
+	d.EnqueueKey("foo/bar")
+	// The other keys aren't delayed.
+	d.EnqueueKey("foo/baz")
+	// The events of the next interval are enqueued at its end.
+	c.Step(1500 * time.Millisecond)
+	d.EnqueueKey("foo/bar")
+	// Once an interval elapsed without events, they are enqueued right
+	// away again.
+	c.Step(10 * time.Second)
+	d.EnqueueKey("foo/bar")
+
+	want := []enqueued{
+		{Key: "foo/bar"},
+		{Key: "foo/bar", Delay: 1500 * time.Millisecond},
+		{Key: "foo/baz"},
+		{Key: "foo/bar", Delay: 1500 * time.Millisecond},
+		{Key: "foo/bar"},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Enqueued keys (-want, +got): %s", d)
+	}
+	if len(d.last) != 1 {
+		t.Errorf("Tracked %d keys, want the finished ones to be forgotten: %v", len(d.last), d.last)
+	}
+}
+
+func TestDebouncer_EnqueueControllerOf(t *testing.T) {
+	c := clock.NewFakeClock(time.Date(2019, time.October, 1, 12, 0, 0, 0, time.UTC))
+	var got []enqueued
+	d := NewDebouncer(func(key string, delay time.Duration) {
+		got = append(got, enqueued{Key: key, Delay: delay})
+	}, 0, c)
+
+	controlled := true
+	d.EnqueueControllerOf(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "foo",
+		Name:      "bar-pod",
+		OwnerReferences: []metav1.OwnerReference{{
+			Kind:       "TaskRun",
+			Name:       "bar",
+			Controller: &controlled,
+		}},
+	}})
+	d.EnqueueControllerOf(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "orphan"}})
+	// Without an interval, the keys are never delayed.
+	d.EnqueueControllerOf(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "foo",
+		Name:      "bar-pod",
+		OwnerReferences: []metav1.OwnerReference{{
+			Kind:       "TaskRun",
+			Name:       "bar",
+			Controller: &controlled,
+		}},
+	}})
+
+	want := []enqueued{{Key: "foo/bar"}, {Key: "foo/bar"}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Enqueued keys (-want, +got): %s", d)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+)
+
+// drainPollInterval is how often Drain.Wait checks whether the controllers
+// are done.
+const drainPollInterval = 100 * time.Millisecond
+
+// Drain tracks the work of the controllers built by the constructors it
+// wraps, so that the controller process can finish it before exiting, e.g.
+// during a rolling upgrade, instead of leaving statuses half written.
+type Drain struct {
+	mu     sync.Mutex
+	queues []workqueue.RateLimitingInterface
+	// inFlight is the number of keys handed to the reconcilers and not done
+	// yet.
+	inFlight int
+}
+
+// NewDrain returns a Drain which doesn't track any controller yet.
+func NewDrain() *Drain {
+	return &Drain{}
+}
+
+// Wrap returns constructors of the same controllers as ctors, whose work
+// queues and reconciles d tracks.
+func (d *Drain) Wrap(ctors ...injection.ControllerConstructor) []injection.ControllerConstructor {
+	wrapped := make([]injection.ControllerConstructor, 0, len(ctors))
+	for _, ctor := range ctors {
+		ctor := ctor
+		wrapped = append(wrapped, func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+			impl := ctor(ctx, cmw)
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.queues = append(d.queues, impl.WorkQueue)
+			impl.WorkQueue = &drainQueue{RateLimitingInterface: impl.WorkQueue, drain: d}
+			return impl
+		})
+	}
+	return wrapped
+}
+
+// Wait waits, once the controllers have been told to stop, for the keys
+// left in their work queues to be reconciled, and for the reconciles in
+// flight to be done, for at most gracePeriod. The controllers stop taking
+// new keys once their work queues are empty. It returns an error if some
+// work was left when gracePeriod elapsed.
+func (d *Drain) Wait(gracePeriod time.Duration) error {
+	return wait.PollImmediate(drainPollInterval, gracePeriod, func() (bool, error) {
+		return d.done(), nil
+	})
+}
+
+// done returns whether the work queues are empty and no reconcile is in
+// flight.
+func (d *Drain) done() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.inFlight > 0 {
+		return false
+	}
+	for _, q := range d.queues {
+		if q.Len() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// drainQueue is a work queue which counts the keys its Drain's controllers
+// are reconciling.
+type drainQueue struct {
+	workqueue.RateLimitingInterface
+	drain *Drain
+}
+
+func (q *drainQueue) Get() (interface{}, bool) {
+	item, shutdown := q.RateLimitingInterface.Get()
+	if !shutdown {
+		q.drain.mu.Lock()
+		q.drain.inFlight++
+		q.drain.mu.Unlock()
+	}
+	return item, shutdown
+}
+
+func (q *drainQueue) Done(item interface{}) {
+	q.RateLimitingInterface.Done(item)
+	q.drain.mu.Lock()
+	q.drain.inFlight--
+	q.drain.mu.Unlock()
+}
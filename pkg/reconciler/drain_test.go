@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+// blockingReconciler records the keys it reconciles, once release is
+// closed.
+type blockingReconciler struct {
+	started chan string
+	release chan struct{}
+
+	mu         sync.Mutex
+	reconciled []string
+}
+
+func (r *blockingReconciler) Reconcile(_ context.Context, key string) error {
+	r.started <- key
+	<-r.release
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reconciled = append(r.reconciled, key)
+	return nil
+}
+
+func TestDrainWait(t *testing.T) {
+	r := &blockingReconciler{started: make(chan string, 10), release: make(chan struct{})}
+	drain := NewDrain()
+	ctors := drain.Wrap(func(context.Context, configmap.Watcher) *controller.Impl {
+		return controller.NewImpl(r, logtesting.TestLogger(t), "Drain")
+	})
+	impl := ctors[0](context.Background(), nil)
+
+	stopCh := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		impl.Run(1, stopCh)
+	}()
+	impl.EnqueueKey("foo/first")
+	<-r.started
+	impl.EnqueueKey("foo/second")
+	close(stopCh)
+
+	// The first key is still being reconciled, and the second one queued.
+	if err := drain.Wait(2 * drainPollInterval); err == nil {
+		t.Error("Expected Wait() to time out while a reconcile is in flight")
+	}
+
+	close(r.release)
+	if err := drain.Wait(10 * time.Second); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	<-stopped
+	if want := []string{"foo/first", "foo/second"}; len(r.reconciled) != len(want) || r.reconciled[0] != want[0] || r.reconciled[1] != want[1] {
+		t.Errorf("Reconciled %v, want %v", r.reconciled, want)
+	}
+}
+
+func TestDrainWaitIdle(t *testing.T) {
+	drain := NewDrain()
+	ctors := drain.Wrap(func(context.Context, configmap.Watcher) *controller.Impl {
+		return controller.NewImpl(nopReconciler{}, logtesting.TestLogger(t), "Idle")
+	})
+	impl := ctors[0](context.Background(), nil)
+	defer impl.WorkQueue.ShutDown()
+
+	if err := drain.Wait(time.Second); err != nil {
+		t.Errorf("Wait() = %v, want the controllers without work to be drained right away", err)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/status"
+)
+
+const (
+	// ReasonWaitingForExecutionWindow indicates that the run was created
+	// outside of its execution window and waits for it to open
+	ReasonWaitingForExecutionWindow = "WaitingForExecutionWindow"
+
+	// ReasonInvalidExecutionWindow indicates that the run failed because its
+	// execution window is invalid, or doesn't open in the next five years
+	ReasonInvalidExecutionWindow = "InvalidExecutionWindow"
+)
+
+// WaitForExecutionWindow returns how long a run that hasn't started yet
+// must wait at now for its execution window w to open, and marks s
+// accordingly. It returns 0 if the run may start right away, or if the
+// window never opens, in which case s is marked as failed.
+func WaitForExecutionWindow(s status.ConditionAccessor, w *v1alpha1.ExecutionWindow, now time.Time) time.Duration {
+	next, ok, err := w.NextOpening(now)
+	if err != nil {
+		status.MarkFailed(s, ReasonInvalidExecutionWindow, "Invalid execution window: %v", err)
+		return 0
+	}
+	if !ok {
+		status.MarkFailed(s, ReasonInvalidExecutionWindow, "The execution window doesn't open before %s", now.AddDate(5, 0, 0).Format(time.RFC3339))
+		return 0
+	}
+	wait := next.Sub(now)
+	if wait > 0 {
+		status.MarkRunning(s, ReasonWaitingForExecutionWindow, "Waiting for the execution window to open at %s", next.Format(time.RFC3339))
+	}
+	return wait
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestWaitForExecutionWindow(t *testing.T) {
+	// Saturday
+	now := time.Date(2019, time.November, 16, 10, 30, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name       string
+		window     v1alpha1.ExecutionWindow
+		wantWait   time.Duration
+		wantStatus corev1.ConditionStatus
+		wantReason string
+	}{{
+		name:   "open",
+		window: v1alpha1.ExecutionWindow{Schedules: []string{"* 9-17 * * *"}},
+	}, {
+		name:       "opens on monday",
+		window:     v1alpha1.ExecutionWindow{Schedules: []string{"* 9-17 * * mon-fri"}},
+		wantWait:   46*time.Hour + 30*time.Minute,
+		wantStatus: corev1.ConditionUnknown,
+		wantReason: ReasonWaitingForExecutionWindow,
+	}, {
+		name:       "earliest of the schedules",
+		window:     v1alpha1.ExecutionWindow{Schedules: []string{"* 9-17 * * mon-fri", "0 22 * * sat"}},
+		wantWait:   11*time.Hour + 30*time.Minute,
+		wantStatus: corev1.ConditionUnknown,
+		wantReason: ReasonWaitingForExecutionWindow,
+	}, {
+		name:       "time zone",
+		window:     v1alpha1.ExecutionWindow{Schedules: []string{"* 12 * * *"}, TimeZone: "Asia/Kolkata"},
+		wantWait:   20 * time.Hour,
+		wantStatus: corev1.ConditionUnknown,
+		wantReason: ReasonWaitingForExecutionWindow,
+	}, {
+		name:       "never opens",
+		window:     v1alpha1.ExecutionWindow{Schedules: []string{"0 0 30 feb *"}},
+		wantStatus: corev1.ConditionFalse,
+		wantReason: ReasonInvalidExecutionWindow,
+	}, {
+		name:       "invalid time zone",
+		window:     v1alpha1.ExecutionWindow{Schedules: []string{"* * * * *"}, TimeZone: "Nowhere/Town"},
+		wantStatus: corev1.ConditionFalse,
+		wantReason: ReasonInvalidExecutionWindow,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &v1alpha1.TaskRunStatus{}
+			if wait := WaitForExecutionWindow(s, &tc.window, now); wait != tc.wantWait {
+				t.Errorf("Expected to wait %s, got %s", tc.wantWait, wait)
+			}
+			cond := s.GetCondition(apis.ConditionSucceeded)
+			if tc.wantReason == "" {
+				if cond != nil {
+					t.Errorf("Expected no condition, got %v", cond)
+				}
+				return
+			}
+			if cond == nil || cond.Status != tc.wantStatus || cond.Reason != tc.wantReason {
+				t.Errorf("Expected condition %s with reason %s, got %v", tc.wantStatus, tc.wantReason, cond)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageprefetch
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	imageprefetchinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/imageprefetch"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/entrypoint"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	resyncPeriod = 10 * time.Hour
+)
+
+// NewController returns a constructor for the ImagePrefetch controller, with
+// the dependencies replaced by opts. It pre-warms the EntrypointCache of
+// opts, which must be the one of the TaskRun controller to be of any use.
+func NewController(images pipeline.Images, opts ...reconciler.ControllerOption) func(context.Context, configmap.Watcher) *controller.Impl {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		logger := logging.FromContext(ctx)
+		o := reconciler.NewControllerOptions(ctx, opts...)
+		imagePrefetchInformer := imageprefetchinformer.Get(ctx)
+
+		opt := reconciler.Options{
+			KubeClientSet:     o.KubeClientSet,
+			PipelineClientSet: o.PipelineClientSet,
+			ConfigMapWatcher:  cmw,
+			ResyncPeriod:      resyncPeriod,
+			Logger:            logger,
+		}
+
+		c := &Reconciler{
+			Base:                reconciler.NewBase(opt, imagePrefetchAgentName, images),
+			imagePrefetchLister: imagePrefetchInformer.Lister(),
+			cache:               o.EntrypointCache,
+			clock:               o.Clock,
+		}
+		if c.cache == nil {
+			c.cache, _ = entrypoint.NewCache()
+		}
+		impl := controller.NewImpl(c, c.Logger, imagePrefetchControllerName)
+		if err := reconciler.TrackQueueLatency(impl, imagePrefetchControllerName, "ImagePrefetch", o.Clock); err != nil {
+			logger.Errorf("Failed to track the queue latency of %s: %v", imagePrefetchControllerName, err)
+		}
+
+		c.configStore = o.ConfigStore(imagePrefetchControllerName)
+		if c.configStore == nil {
+			c.configStore = config.NewStore(c.Logger.Named("config-store"))
+		}
+		c.configStore.WatchConfigs(opt.ConfigMapWatcher)
+		c.enqueueAfter = impl.EnqueueAfter
+
+		c.Logger.Info("Setting up event handlers")
+		imagePrefetchInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: o.FilterFunc(),
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc:    impl.Enqueue,
+				UpdateFunc: controller.PassNew(impl.Enqueue),
+			},
+		})
+
+		return impl
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageprefetch
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/entrypoint"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+)
+
+const (
+	// imagePrefetchAgentName defines logging agent name for the ImagePrefetch controller
+	imagePrefetchAgentName = "imageprefetch-controller"
+	// imagePrefetchControllerName defines name for the ImagePrefetch controller
+	imagePrefetchControllerName = "ImagePrefetch"
+)
+
+// Reconciler resolves the images of the ImagePrefetches into the entrypoint
+// cache of the TaskRun controller, and resolves them again every refresh
+// interval.
+type Reconciler struct {
+	*reconciler.Base
+
+	imagePrefetchLister listers.ImagePrefetchLister
+	cache               *entrypoint.Cache
+	clock               clock.Clock
+	configStore         reconciler.ConfigStore
+
+	// enqueueAfter is the one of the controller.Impl the reconciler was
+	// created with.
+	enqueueAfter func(obj interface{}, after time.Duration)
+}
+
+// Check that our Reconciler implements controller.Reconciler
+var _ controller.Reconciler = (*Reconciler)(nil)
+
+// Reconcile resolves the images of the ImagePrefetch identified by key,
+// unless they were resolved less than a refresh interval ago, and schedules
+// their next resolution.
+func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		c.Logger.Errorf("invalid resource key: %s", key)
+		return nil
+	}
+	original, err := c.imagePrefetchLister.ImagePrefetches(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		// The digests it prefetched expire on their own.
+		c.Logger.Infof("ImagePrefetch %q in work queue no longer exists", key)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// Don't modify the informer's copy.
+	p := original.DeepCopy()
+	now := c.clock.Now()
+	if left := c.refreshLeft(p, now); left > 0 {
+		c.enqueueAfter(p, left)
+		return nil
+	}
+	mirrors := config.FromContextOrDefaults(c.configStore.ToContext(ctx)).Defaults.RegistryMirrors
+	c.refresh(p, mirrors, now)
+	c.enqueueAfter(p, p.Spec.GetRefreshInterval())
+
+	if equality.Semantic.DeepEqual(original.Status, p.Status) {
+		return nil
+	}
+	_, err = c.PipelineClientSet.TektonV1alpha1().ImagePrefetches(p.Namespace).UpdateStatus(p)
+	return err
+}
+
+// refreshLeft returns how long is left at now before the images of p must
+// be resolved again, or 0 if they must be now: because they never were, the
+// spec changed since, or the cache lost them, e.g. as the controller
+// restarted.
+func (c *Reconciler) refreshLeft(p *v1alpha1.ImagePrefetch, now time.Time) time.Duration {
+	if p.Status.LastRefreshTime == nil || p.Status.ObservedGeneration != p.Generation {
+		return 0
+	}
+	left := p.Status.LastRefreshTime.Add(p.Spec.GetRefreshInterval()).Sub(now)
+	if left <= 0 {
+		return 0
+	}
+	for _, image := range p.Status.Images {
+		// The images which failed are only retried on the next refresh.
+		if image.Error == "" && !entrypoint.Prefetched(c.cache, p.Namespace, image.Image, now) {
+			return 0
+		}
+	}
+	return left
+}
+
+// refresh resolves the images of p at now and records the results in its
+// status.
+func (c *Reconciler) refresh(p *v1alpha1.ImagePrefetch, mirrors map[string]string, now time.Time) {
+	until := now.Add(p.Spec.GetRefreshInterval())
+	var failed []string
+	images := make([]v1alpha1.PrefetchedImage, 0, len(p.Spec.Images))
+	for _, image := range p.Spec.Images {
+		digest, err := entrypoint.Prefetch(c.cache, image, c.KubeClientSet, p.Namespace, p.Spec.ServiceAccountName, mirrors, until)
+		if err != nil {
+			c.Logger.Warnf("Failed to prefetch image %s of ImagePrefetch %s/%s: %v", image, p.Namespace, p.Name, err)
+			images = append(images, v1alpha1.PrefetchedImage{Image: image, Error: err.Error()})
+			failed = append(failed, image)
+			continue
+		}
+		images = append(images, v1alpha1.PrefetchedImage{Image: image, Digest: digest})
+	}
+	p.Status.Images = images
+	p.Status.LastRefreshTime = &metav1.Time{Time: now}
+	p.Status.ObservedGeneration = p.Generation
+	if len(failed) > 0 {
+		p.Status.MarkResolutionFailed("Failed to resolve %d of %d images: %s", len(failed), len(images), strings.Join(failed, ", "))
+		return
+	}
+	p.Status.MarkResolved("Resolved %d images", len(images))
+}
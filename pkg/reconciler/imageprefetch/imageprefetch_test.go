@@ -0,0 +1,208 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageprefetch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/entrypoint"
+	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	"github.com/tektoncd/pipeline/pkg/system"
+	"github.com/tektoncd/pipeline/test"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	ktesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/configmap"
+)
+
+var (
+	testNow = time.Date(2019, time.October, 1, 12, 0, 0, 0, time.UTC)
+	images  = pipeline.Images{}
+)
+
+type image struct {
+	config *v1.ConfigFile
+}
+
+// RawConfigFile implements partial.UncompressedImageCore
+func (i *image) RawConfigFile() ([]byte, error) {
+	return partial.RawConfigFile(i)
+}
+
+// ConfigFile implements v1.Image
+func (i *image) ConfigFile() (*v1.ConfigFile, error) {
+	return i.config, nil
+}
+
+// MediaType implements partial.UncompressedImageCore
+func (i *image) MediaType() (types.MediaType, error) {
+	return types.DockerManifestSchema2, nil
+}
+
+// LayerByDiffID implements partial.UncompressedImageCore
+func (i *image) LayerByDiffID(diffID v1.Hash) (partial.UncompressedLayer, error) {
+	return nil, fmt.Errorf("unknown diff_id: %v", diffID)
+}
+
+// newRegistry returns a registry serving img as image:latest, and counting
+// the requests for its manifest in manifestRequests.
+func newRegistry(t *testing.T, img v1.Image, manifestRequests *int) *httptest.Server {
+	t.Helper()
+	configName, err := img.ConfigName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := img.RawConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := img.RawManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/image/manifests/latest":
+			*manifestRequests++
+			w.Write(manifest)
+		case "/v2/image/blobs/" + configName.String():
+			w.Write(config)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestReconcileImagePrefetch(t *testing.T) {
+	img, err := partial.UncompressedToImage(&image{config: &v1.ConfigFile{
+		Config: v1.Config{Entrypoint: []string{"/bin/expected"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifestRequests int
+	server := newRegistry(t, img, &manifestRequests)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	found, missing := host+"/image:latest", host+"/missing:latest"
+
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p := tb.ImagePrefetch("step-images", "foo", []string{found, missing},
+		tb.ImagePrefetchRefreshInterval(time.Hour),
+		tb.ImagePrefetchGeneration(1),
+	)
+	c, i := test.SeedTestData(t, ctx, test.Data{ImagePrefetches: []*v1alpha1.ImagePrefetch{p}})
+	if _, err := c.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	entrypointCache, err := entrypoint.NewCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+	impl := NewController(images, reconciler.WithClock(q.Clock), reconciler.WithEntrypointCache(entrypointCache))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*Reconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	// reconcile reconciles the ImagePrefetch, and returns its status if it
+	// was updated, after feeding it back to the informer.
+	reconcile := func() *v1alpha1.ImagePrefetchStatus {
+		t.Helper()
+		c.Pipeline.ClearActions()
+		if err := r.Reconcile(ctx, "foo/step-images"); err != nil {
+			t.Fatalf("Unexpected error reconciling the ImagePrefetch: %v", err)
+		}
+		for _, a := range c.Pipeline.Actions() {
+			if a.GetVerb() == "update" && a.GetSubresource() == "status" {
+				updated := a.(ktesting.UpdateAction).GetObject().(*v1alpha1.ImagePrefetch)
+				if err := i.ImagePrefetch.Informer().GetIndexer().Update(updated); err != nil {
+					t.Fatal(err)
+				}
+				return &updated.Status
+			}
+		}
+		return nil
+	}
+	expectEnqueue := func(at time.Time) {
+		t.Helper()
+		if next, ok := q.NextAt(); !ok || !next.Equal(at) {
+			t.Errorf("Expected the ImagePrefetch to be enqueued again at %s, got %s", at, next)
+		}
+		q.Travel(at)
+	}
+
+	status := reconcile()
+	if status == nil {
+		t.Fatal("Expected the status of the ImagePrefetch to be updated")
+	}
+	if len(status.Images) != 2 || status.Images[0].Digest != digest.String() || status.Images[1].Error == "" {
+		t.Errorf("Expected %s to be resolved to %s and %s to fail, got %v", found, digest, missing, status.Images)
+	}
+	if cond := status.GetCondition(apis.ConditionReady); cond == nil || cond.Status != corev1.ConditionFalse || cond.Reason != v1alpha1.ImagePrefetchReasonResolutionFailed {
+		t.Errorf("Expected the ImagePrefetch not to be ready as %s failed, got %v", missing, cond)
+	}
+	if !entrypoint.Prefetched(entrypointCache, "foo", found, testNow) {
+		t.Errorf("Expected %s to be prefetched", found)
+	}
+	if manifestRequests != 1 {
+		t.Errorf("Expected the manifest of %s to be fetched once, got %d", found, manifestRequests)
+	}
+
+	// The status update doesn't resolve the images again.
+	q.Clock.Step(10 * time.Minute)
+	if status := reconcile(); status != nil {
+		t.Errorf("Expected the images not to be resolved again before the refresh interval, got %v", status)
+	}
+	if manifestRequests != 1 {
+		t.Errorf("Expected the manifest of %s to be fetched once, got %d", found, manifestRequests)
+	}
+	expectEnqueue(testNow.Add(time.Hour))
+
+	status = reconcile()
+	if status == nil || !status.LastRefreshTime.Time.Equal(testNow.Add(time.Hour)) {
+		t.Errorf("Expected the images to be resolved again after the refresh interval, got %v", status)
+	}
+	if manifestRequests != 2 {
+		t.Errorf("Expected the manifest of %s to be fetched again, got %d requests", found, manifestRequests)
+	}
+	expectEnqueue(testNow.Add(2 * time.Hour))
+}
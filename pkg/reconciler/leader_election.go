@@ -0,0 +1,265 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/logging"
+)
+
+// DefaultLeaseDuration is how long a replica leads a bucket without
+// renewing its Lease, unless told otherwise.
+const DefaultLeaseDuration = 15 * time.Second
+
+// BucketElector splits the keys a controller reconciles into a fixed number
+// of buckets, and elects, among the replicas of the controller, the one
+// leading each bucket through a coordination.k8s.io Lease per bucket. Each
+// replica only reconciles the keys of the buckets it leads, so that running
+// several of them doesn't reconcile a key twice, and the buckets of a
+// replica which dies are taken over by the others once its Leases expire.
+// Only the controllers consulting it are split this way.
+type BucketElector struct {
+	name          string
+	namespace     string
+	identity      string
+	buckets       int
+	leaseDuration time.Duration
+	clock         clock.Clock
+
+	mu sync.RWMutex
+	// renewed are the times this replica last acquired or renewed the
+	// Leases of the buckets it leads.
+	renewed map[int]time.Time
+	// promoted are called with the buckets this replica starts leading.
+	promoted []func(bucket int)
+	start    sync.Once
+}
+
+// NewBucketElector returns a BucketElector of the given number of buckets,
+// whose Leases, named after name, are in namespace. identity tells the
+// replica apart from the others, e.g. its pod name.
+func NewBucketElector(name, namespace, identity string, buckets int, leaseDuration time.Duration, c clock.Clock) (*BucketElector, error) {
+	if buckets < 1 {
+		return nil, xerrors.Errorf("the number of buckets must be positive, got %d", buckets)
+	}
+	if identity == "" {
+		return nil, xerrors.New("the identity of the replica must not be empty")
+	}
+	if leaseDuration <= 0 {
+		leaseDuration = DefaultLeaseDuration
+	}
+	return &BucketElector{
+		name:          name,
+		namespace:     namespace,
+		identity:      identity,
+		buckets:       buckets,
+		leaseDuration: leaseDuration,
+		clock:         c,
+		renewed:       map[int]time.Time{},
+	}, nil
+}
+
+// Bucket returns the bucket of key.
+func (e *BucketElector) Bucket(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(e.buckets))
+}
+
+// Has returns whether this replica leads the bucket of key. A nil
+// BucketElector leads all of them, as a single replica does.
+func (e *BucketElector) Has(key string) bool {
+	if e == nil {
+		return true
+	}
+	return e.leads(e.Bucket(key))
+}
+
+func (e *BucketElector) leads(bucket int) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	renewed, ok := e.renewed[bucket]
+	return ok && e.clock.Since(renewed) < e.leaseDuration
+}
+
+// OnPromote makes the BucketElector call f with each of the buckets this
+// replica starts leading, e.g. to enqueue their keys again, which the
+// replica leading them before may have left unreconciled.
+func (e *BucketElector) OnPromote(f func(bucket int)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.promoted = append(e.promoted, f)
+}
+
+// Start runs the elections of the buckets with client until ctx is done,
+// and then releases the Leases this replica holds. Only the first call
+// starts them, so that the controllers sharing the BucketElector can all
+// call it.
+func (e *BucketElector) Start(ctx context.Context, client kubernetes.Interface) {
+	e.start.Do(func() {
+		go func() {
+			wait.Until(func() { e.elect(ctx, client) }, e.leaseDuration/3, ctx.Done())
+			e.release(ctx, client)
+		}()
+	})
+}
+
+// elect tries to acquire or renew the Lease of every bucket.
+func (e *BucketElector) elect(ctx context.Context, client kubernetes.Interface) {
+	logger := logging.FromContext(ctx)
+	for bucket := 0; bucket < e.buckets; bucket++ {
+		wasLeading := e.leads(bucket)
+		ok, err := e.tryAcquireOrRenew(client, bucket)
+		if err != nil {
+			logger.Warnf("Failed to acquire or renew Lease %s/%s: %v", e.namespace, e.leaseName(bucket), err)
+		}
+		if !ok {
+			// The bucket stays led until its Lease expires, unless
+			// another replica holds it.
+			if err == nil && wasLeading {
+				e.demote(bucket)
+				logger.Infof("Lost the lead of bucket %d of %s", bucket, e.name)
+			}
+			continue
+		}
+		if !wasLeading {
+			logger.Infof("Leading bucket %d of %s", bucket, e.name)
+			e.mu.RLock()
+			promoted := append([]func(int){}, e.promoted...)
+			e.mu.RUnlock()
+			for _, f := range promoted {
+				f(bucket)
+			}
+		}
+	}
+}
+
+// tryAcquireOrRenew updates the Lease of bucket if this replica holds it,
+// or if it expired, and returns whether it did. Conflicting updates of the
+// Lease by other replicas are not errors.
+func (e *BucketElector) tryAcquireOrRenew(client kubernetes.Interface, bucket int) (bool, error) {
+	leases := client.CoordinationV1beta1().Leases(e.namespace)
+	now := e.clock.Now()
+	microNow := metav1.NewMicroTime(now)
+	seconds := int32(e.leaseDuration / time.Second)
+	lease, err := leases.Get(e.leaseName(bucket), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		transitions := int32(0)
+		_, err := leases.Create(&coordinationv1beta1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: e.leaseName(bucket), Namespace: e.namespace},
+			Spec: coordinationv1beta1.LeaseSpec{
+				HolderIdentity:       &e.identity,
+				LeaseDurationSeconds: &seconds,
+				AcquireTime:          &microNow,
+				RenewTime:            &microNow,
+				LeaseTransitions:     &transitions,
+			},
+		})
+		if errors.IsAlreadyExists(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		e.renew(bucket, now)
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	spec := &lease.Spec
+	if holder := spec.HolderIdentity; holder == nil || *holder != e.identity {
+		if holder != nil && *holder != "" && !leaseExpired(spec, now) {
+			return false, nil
+		}
+		transitions := int32(1)
+		if spec.LeaseTransitions != nil {
+			transitions = *spec.LeaseTransitions + 1
+		}
+		spec.HolderIdentity = &e.identity
+		spec.AcquireTime = &microNow
+		spec.LeaseTransitions = &transitions
+	}
+	spec.LeaseDurationSeconds = &seconds
+	spec.RenewTime = &microNow
+	if _, err := leases.Update(lease); errors.IsConflict(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	e.renew(bucket, now)
+	return true, nil
+}
+
+// leaseExpired returns whether the holder of the Lease with spec failed to
+// renew it in time.
+func leaseExpired(spec *coordinationv1beta1.LeaseSpec, now time.Time) bool {
+	if spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return !now.Before(spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second))
+}
+
+// release gives up the Leases this replica holds, so that the other
+// replicas take over its buckets without waiting for them to expire.
+func (e *BucketElector) release(ctx context.Context, client kubernetes.Interface) {
+	logger := logging.FromContext(ctx)
+	leases := client.CoordinationV1beta1().Leases(e.namespace)
+	for bucket := 0; bucket < e.buckets; bucket++ {
+		if !e.leads(bucket) {
+			continue
+		}
+		e.demote(bucket)
+		lease, err := leases.Get(e.leaseName(bucket), metav1.GetOptions{})
+		if err != nil || lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != e.identity {
+			continue
+		}
+		released := ""
+		lease.Spec.HolderIdentity = &released
+		if _, err := leases.Update(lease); err != nil {
+			logger.Warnf("Failed to release Lease %s/%s: %v", e.namespace, lease.Name, err)
+		}
+	}
+}
+
+func (e *BucketElector) renew(bucket int, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.renewed[bucket] = now
+}
+
+func (e *BucketElector) demote(bucket int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.renewed, bucket)
+}
+
+// leaseName returns the name of the Lease of bucket.
+func (e *BucketElector) leaseName(bucket int) string {
+	return fmt.Sprintf("%s.%02d-of-%02d", e.name, bucket, e.buckets)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+var electionNow = time.Date(2019, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestElector(t *testing.T, identity string, buckets int, c clock.Clock) *BucketElector {
+	t.Helper()
+	e, err := NewBucketElector("cleanup", "tekton-pipelines", identity, buckets, 15*time.Second, c)
+	if err != nil {
+		t.Fatalf("NewBucketElector() = %v", err)
+	}
+	return e
+}
+
+func holder(t *testing.T, kube *fakekubeclientset.Clientset, name string) string {
+	t.Helper()
+	lease, err := kube.CoordinationV1beta1().Leases("tekton-pipelines").Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get Lease %s: %v", name, err)
+	}
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+func TestBucketElectorFailover(t *testing.T) {
+	ctx := logtesting.TestContextWithLogger(t)
+	c := clock.NewFakeClock(electionNow)
+	kube := fakekubeclientset.NewSimpleClientset()
+	a := newTestElector(t, "controller-a", 2, c)
+	b := newTestElector(t, "controller-b", 2, c)
+	var promoted []string
+	b.OnPromote(func(bucket int) { promoted = append(promoted, fmt.Sprintf("b%d", bucket)) })
+
+	// The first replica acquires all the buckets, leaving none to the other.
+	a.elect(ctx, kube)
+	b.elect(ctx, kube)
+	for bucket := 0; bucket < 2; bucket++ {
+		if !a.leads(bucket) || b.leads(bucket) {
+			t.Errorf("Bucket %d led by controller-a: %t, controller-b: %t, want only controller-a", bucket, a.leads(bucket), b.leads(bucket))
+		}
+	}
+	if got := holder(t, kube, "cleanup.00-of-02"); got != "controller-a" {
+		t.Errorf("Lease cleanup.00-of-02 held by %q, want controller-a", got)
+	}
+
+	// Renewed, the Leases stay with the first replica.
+	c.Step(10 * time.Second)
+	a.elect(ctx, kube)
+	c.Step(10 * time.Second)
+	b.elect(ctx, kube)
+	if len(promoted) != 0 || !a.leads(0) {
+		t.Errorf("Renewed buckets were taken over: %v", promoted)
+	}
+
+	// Once the first replica stops renewing them, the other takes them over.
+	c.Step(10 * time.Second)
+	b.elect(ctx, kube)
+	if a.leads(0) || a.leads(1) {
+		t.Error("Expired buckets still led by controller-a")
+	}
+	if !b.leads(0) || !b.leads(1) {
+		t.Error("Expired buckets not taken over by controller-b")
+	}
+	if want := []string{"b0", "b1"}; fmt.Sprint(promoted) != fmt.Sprint(want) {
+		t.Errorf("Promoted buckets %v, want %v", promoted, want)
+	}
+	lease, err := kube.CoordinationV1beta1().Leases("tekton-pipelines").Get("cleanup.01-of-02", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get Lease cleanup.01-of-02: %v", err)
+	}
+	if *lease.Spec.HolderIdentity != "controller-b" || *lease.Spec.LeaseTransitions != 1 {
+		t.Errorf("Lease held by %q after %d transitions, want controller-b after 1", *lease.Spec.HolderIdentity, *lease.Spec.LeaseTransitions)
+	}
+
+	// The first replica notices it lost them.
+	a.elect(ctx, kube)
+	if a.leads(0) || !b.leads(0) {
+		t.Error("Bucket 0 taken back by controller-a")
+	}
+}
+
+func TestBucketElectorRelease(t *testing.T) {
+	ctx := logtesting.TestContextWithLogger(t)
+	c := clock.NewFakeClock(electionNow)
+	other := "controller-b"
+	renewed := metav1.NewMicroTime(electionNow)
+	seconds := int32(15)
+	kube := fakekubeclientset.NewSimpleClientset(&coordinationv1beta1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "cleanup.01-of-02", Namespace: "tekton-pipelines"},
+		Spec: coordinationv1beta1.LeaseSpec{
+			HolderIdentity:       &other,
+			LeaseDurationSeconds: &seconds,
+			RenewTime:            &renewed,
+		},
+	})
+	e := newTestElector(t, "controller-a", 2, c)
+	e.elect(ctx, kube)
+	if !e.leads(0) || e.leads(1) {
+		t.Fatalf("Buckets led: %t, %t, want only bucket 0", e.leads(0), e.leads(1))
+	}
+
+	e.release(ctx, kube)
+	if e.leads(0) {
+		t.Error("Released bucket 0 still led")
+	}
+	if got := holder(t, kube, "cleanup.00-of-02"); got != "" {
+		t.Errorf("Released Lease held by %q", got)
+	}
+	if got := holder(t, kube, "cleanup.01-of-02"); got != other {
+		t.Errorf("Lease of another replica released, held by %q", got)
+	}
+
+	// A released Lease is acquired without waiting for it to expire.
+	b := newTestElector(t, other, 2, c)
+	b.elect(ctx, kube)
+	if !b.leads(0) {
+		t.Error("Released bucket 0 not acquired")
+	}
+}
+
+func TestBucketElectorHas(t *testing.T) {
+	var nilElector *BucketElector
+	if !nilElector.Has("foo") {
+		t.Error("A nil BucketElector doesn't lead all the buckets")
+	}
+
+	ctx := logtesting.TestContextWithLogger(t)
+	e := newTestElector(t, "controller-a", 4, clock.NewFakeClock(electionNow))
+	if e.Has("foo") {
+		t.Error("Bucket led before being elected")
+	}
+	e.elect(ctx, fakekubeclientset.NewSimpleClientset())
+	for _, ns := range []string{"foo", "bar", "tekton-pipelines"} {
+		if b := e.Bucket(ns); b != e.Bucket(ns) || b < 0 || b >= 4 {
+			t.Errorf("Bucket(%q) = %d, want the same bucket in [0, 4)", ns, b)
+		}
+		if !e.Has(ns) {
+			t.Errorf("Has(%q) = false once all the buckets are led", ns)
+		}
+	}
+}
+
+func TestNewBucketElectorInvalid(t *testing.T) {
+	c := clock.NewFakeClock(electionNow)
+	if _, err := NewBucketElector("cleanup", "tekton-pipelines", "controller-a", 0, 0, c); err == nil {
+		t.Error("Expected an error with no buckets")
+	}
+	if _, err := NewBucketElector("cleanup", "tekton-pipelines", "", 1, 0, c); err == nil {
+		t.Error("Expected an error with no identity")
+	}
+}
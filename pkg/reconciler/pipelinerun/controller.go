@@ -21,10 +21,11 @@ import (
 	"time"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
-	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	clustertaskinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/clustertask"
 	conditioninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/condition"
 	pipelineinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipeline"
+	pipelinequotainformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelinequota"
 	resourceinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelineresource"
 	pipelineruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelinerun"
 	taskinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/task"
@@ -32,7 +33,8 @@ import (
 	"github.com/tektoncd/pipeline/pkg/reconciler"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/config"
 	"k8s.io/client-go/tools/cache"
-	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	configmapinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap"
+	secretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
@@ -43,11 +45,14 @@ const (
 	resyncPeriod = 10 * time.Hour
 )
 
-func NewController(images pipeline.Images) func(context.Context, configmap.Watcher) *controller.Impl {
+// NewController returns a constructor for the PipelineRun controller, with
+// the dependencies replaced by opts.
+func NewController(images pipeline.Images, opts ...reconciler.ControllerOption) func(context.Context, configmap.Watcher) *controller.Impl {
 	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 		logger := logging.FromContext(ctx)
-		kubeclientset := kubeclient.Get(ctx)
-		pipelineclientset := pipelineclient.Get(ctx)
+		o := reconciler.NewControllerOptions(ctx, opts...)
+		kubeclientset := o.KubeClientSet
+		pipelineclientset := o.PipelineClientSet
 		taskRunInformer := taskruninformer.Get(ctx)
 		taskInformer := taskinformer.Get(ctx)
 		clusterTaskInformer := clustertaskinformer.Get(ctx)
@@ -78,28 +83,53 @@ func NewController(images pipeline.Images) func(context.Context, configmap.Watch
 			taskRunLister:     taskRunInformer.Lister(),
 			resourceLister:    resourceInformer.Lister(),
 			conditionLister:   conditionInformer.Lister(),
+			quotaLister:       pipelinequotainformer.Get(ctx).Lister(),
+			configMapLister:   configmapinformer.Get(ctx).Lister(),
+			secretLister:      secretinformer.Get(ctx).Lister(),
 			timeoutHandler:    timeoutHandler,
 			metrics:           metrics,
+			clock:             o.Clock,
 		}
 		impl := controller.NewImpl(c, c.Logger, pipelineRunControllerName)
+		c.enqueueAfter = impl.EnqueueAfter
+		if err := reconciler.TrackQueueLatency(impl, pipelineRunControllerName, "PipelineRun", o.Clock); err != nil {
+			logger.Errorf("Failed to track the queue latency of %s: %v", pipelineRunControllerName, err)
+		}
 
-		timeoutHandler.SetPipelineRunCallbackFunc(impl.Enqueue)
+		filter := o.FilterFunc()
+		timeoutHandler.SetPipelineRunCallbackFunc(func(obj interface{}) {
+			if filter(obj) {
+				impl.Enqueue(obj)
+			}
+		})
 		timeoutHandler.CheckTimeouts(kubeclientset, pipelineclientset)
 
 		c.Logger.Info("Setting up event handlers")
-		pipelineRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-			AddFunc:    impl.Enqueue,
-			UpdateFunc: controller.PassNew(impl.Enqueue),
-			DeleteFunc: impl.Enqueue,
+		pipelineRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: filter,
+			Handler: reconciler.SkipCompletedRuns(cache.ResourceEventHandlerFuncs{
+				AddFunc:    impl.Enqueue,
+				UpdateFunc: controller.PassNew(impl.Enqueue),
+				DeleteFunc: impl.Enqueue,
+			}),
 		})
 
 		c.tracker = tracker.New(impl.EnqueueKey, 30*time.Minute)
-		taskRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-			UpdateFunc: controller.PassNew(impl.EnqueueControllerOf),
+		debouncer := reconciler.NewDebouncer(impl.EnqueueKeyAfter, o.DebounceInterval, o.Clock)
+		taskRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: o.OwnerFilterFunc(v1alpha1.SchemeGroupVersion.WithKind("PipelineRun"), func(namespace, name string) (interface{}, error) {
+				return pipelineRunInformer.Lister().PipelineRuns(namespace).Get(name)
+			}),
+			Handler: reconciler.SkipCompletedRuns(cache.ResourceEventHandlerFuncs{
+				UpdateFunc: controller.PassNew(debouncer.EnqueueControllerOf),
+			}),
 		})
 
 		c.Logger.Info("Setting up ConfigMap receivers")
-		c.configStore = config.NewStore(images, c.Logger.Named("config-store"))
+		c.configStore = o.ConfigStore(pipelineRunControllerName)
+		if c.configStore == nil {
+			c.configStore = config.NewStore(images, c.Logger.Named("config-store"))
+		}
 		c.configStore.WatchConfigs(opt.ConfigMapWatcher)
 
 		return impl
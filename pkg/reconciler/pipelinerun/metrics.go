@@ -46,6 +46,11 @@ var (
 	runningPRsCount = stats.Float64("running_pipelineruns_count",
 		"Number of pipelineruns executing currently",
 		stats.UnitDimensionless)
+
+	reconcilePhaseDuration = stats.Float64("pipelinerun_reconcile_phase_duration_seconds",
+		"The time spent in each phase of the reconciliation of pipelineruns, in seconds",
+		stats.UnitDimensionless)
+	reconcilePhaseDistributions = view.Distribution(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10)
 )
 
 type Recorder struct {
@@ -55,6 +60,7 @@ type Recorder struct {
 	pipelineRun tag.Key
 	namespace   tag.Key
 	status      tag.Key
+	phase       tag.Key
 }
 
 // NewRecorder creates a new metrics recorder instance
@@ -88,6 +94,12 @@ func NewRecorder() (*Recorder, error) {
 	}
 	r.status = status
 
+	phase, err := tag.NewKey("phase")
+	if err != nil {
+		return nil, err
+	}
+	r.phase = phase
+
 	err = view.Register(
 		&view.View{
 			Description: prDuration.Description(),
@@ -106,6 +118,12 @@ func NewRecorder() (*Recorder, error) {
 			Measure:     runningPRsCount,
 			Aggregation: view.LastValue(),
 		},
+		&view.View{
+			Description: reconcilePhaseDuration.Description(),
+			Measure:     reconcilePhaseDuration,
+			Aggregation: reconcilePhaseDistributions,
+			TagKeys:     []tag.Key{r.phase},
+		},
 	)
 
 	if err != nil {
@@ -134,11 +152,12 @@ func (r *Recorder) DurationAndCount(pr *v1alpha1.PipelineRun) error {
 		status = "failed"
 	}
 
+	id := pr.RunIdentity("")
 	ctx, err := tag.New(
 		context.Background(),
-		tag.Insert(r.pipeline, pr.Spec.PipelineRef.Name),
-		tag.Insert(r.pipelineRun, pr.Name),
-		tag.Insert(r.namespace, pr.Namespace),
+		tag.Insert(r.pipeline, id.Pipeline),
+		tag.Insert(r.pipelineRun, id.PipelineRun),
+		tag.Insert(r.namespace, id.Namespace),
 		tag.Insert(r.status, status),
 	)
 
@@ -179,3 +198,20 @@ func (r *Recorder) RunningPipelineRuns(lister listers.PipelineRunLister) error {
 
 	return nil
 }
+
+// ReconcilePhaseDuration logs the time spent in a phase of the
+// reconciliation of a PipelineRun
+// returns an error if its failed to log the metrics
+func (r *Recorder) ReconcilePhaseDuration(phase string, duration time.Duration) error {
+	if r == nil || !r.initialized {
+		return fmt.Errorf("ignoring the metrics recording for phase %s, failed to initialize the metrics recorder", phase)
+	}
+
+	ctx, err := tag.New(context.Background(), tag.Insert(r.phase, phase))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, reconcilePhaseDuration.M(duration.Seconds()))
+
+	return nil
+}
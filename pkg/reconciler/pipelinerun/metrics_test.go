@@ -35,9 +35,11 @@ func TestUninitializedMetrics(t *testing.T) {
 
 	durationCountError := metrics.DurationAndCount(&v1alpha1.PipelineRun{})
 	prCountError := metrics.RunningPipelineRuns(nil)
+	phaseError := metrics.ReconcilePhaseDuration(phaseResolve, time.Second)
 
 	assertErrNotNil(durationCountError, "DurationAndCount recording expected to return error but got nil", t)
 	assertErrNotNil(prCountError, "Current PR count recording expected to return error but got nil", t)
+	assertErrNotNil(phaseError, "ReconcilePhaseDuration recording expected to return error but got nil", t)
 }
 
 func TestRecordPipelineRunDurationCount(t *testing.T) {
@@ -123,6 +125,17 @@ func TestRecordRunningPipelineRunsCount(t *testing.T) {
 	metricstest.CheckLastValueData(t, "running_pipelineruns_count", map[string]string{}, 1)
 }
 
+func TestRecordReconcilePhaseDuration(t *testing.T) {
+	defer unregisterMetrics()
+
+	metrics, err := NewRecorder()
+	assertErrIsNil(err, "Recorder initialization failed", t)
+
+	err = metrics.ReconcilePhaseDuration(phaseCreateRuns, 500*time.Millisecond)
+	assertErrIsNil(err, "ReconcilePhaseDuration recording got an error", t)
+	metricstest.CheckDistributionData(t, "pipelinerun_reconcile_phase_duration_seconds", map[string]string{"phase": "create-runs"}, 1, 0.5, 0.5)
+}
+
 func addPipelineRun(informer alpha1.PipelineRunInformer, run, pipeline, ns string, status corev1.ConditionStatus, t *testing.T) {
 	t.Helper()
 
@@ -155,5 +168,5 @@ func assertErrIsNil(err error, message string, t *testing.T) {
 }
 
 func unregisterMetrics() {
-	metricstest.Unregister("pipelinerun_duration_seconds", "pipelinerun_count", "running_pipelineruns_count")
+	metricstest.Unregister("pipelinerun_duration_seconds", "pipelinerun_count", "running_pipelineruns_count", "pipelinerun_reconcile_phase_duration_seconds")
 }
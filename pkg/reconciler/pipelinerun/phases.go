@@ -0,0 +1,279 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/artifacts"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
+	"github.com/tektoncd/pipeline/pkg/status"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+// The phases of the reconciliation of a PipelineRun, in order. The time
+// spent in each of them is recorded by the metrics Recorder.
+const (
+	// phaseResolve fetches the Pipeline, its Tasks, Conditions and
+	// PipelineResources, and the TaskRuns which already exist, and
+	// validates them together.
+	phaseResolve = "resolve"
+	// phaseSchedule computes which PipelineTasks can run next.
+	phaseSchedule = "schedule"
+	// phaseCreateRuns creates the TaskRuns and condition checks of the
+	// scheduled PipelineTasks.
+	phaseCreateRuns = "create-runs"
+	// phaseStatus aggregates the status of the TaskRuns into the status of
+	// the PipelineRun.
+	phaseStatus = "status"
+)
+
+// resolvedPipelineRun is the result of the resolve phase, which the
+// following phases work from.
+type resolvedPipelineRun struct {
+	pipelineMeta *metav1.ObjectMeta
+	pipelineSpec *v1alpha1.PipelineSpec
+	dag          *v1alpha1.DAG
+	state        resources.PipelineRunState
+}
+
+// recordPhase records the time spent in phase since start.
+func (c *Reconciler) recordPhase(phase string, start time.Time) {
+	if err := c.metrics.ReconcilePhaseDuration(phase, time.Since(start)); err != nil {
+		c.Logger.Debugf("Failed to record the duration of the %s phase: %v", phase, err)
+	}
+}
+
+// resolve fetches everything the PipelineRun refers to and validates it. If
+// the PipelineRun can't run, it is marked as failed and resolve returns nil.
+func (c *Reconciler) resolve(pr *v1alpha1.PipelineRun) *resolvedPipelineRun {
+	defer c.recordPhase(phaseResolve, time.Now())
+
+	getPipelineFunc := c.getPipelineFunc(pr)
+	pipelineMeta, pipelineSpec, err := resources.GetPipelineData(pr, getPipelineFunc)
+	if err != nil {
+		c.Logger.Errorf("Failed to determine Pipeline spec to use for pipelinerun %s: %v", pr.Name, err)
+		status.MarkFailed(&pr.Status, ReasonCouldntGetPipeline, "Error retrieving pipeline for pipelinerun %s: %s",
+			fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), err)
+		return nil
+	}
+	// The PipelineRun runs the spec resolved when it started until it's
+	// done, so that it never mixes versions of the Pipeline.
+	if pr.Status.PipelineSpec == nil && !pr.IsDone() {
+		pr.Status.PipelineSpec = pipelineSpec.DeepCopy()
+	}
+
+	// Propagate labels from Pipeline to PipelineRun.
+	if pr.ObjectMeta.Labels == nil {
+		pr.ObjectMeta.Labels = make(map[string]string, len(pipelineMeta.Labels)+1)
+	}
+	for key, value := range pipelineMeta.Labels {
+		pr.ObjectMeta.Labels[key] = value
+	}
+	pr.ObjectMeta.Labels[pipeline.GroupName+pipeline.PipelineLabelKey] = pipelineMeta.Name
+
+	// Propagate annotations from Pipeline to PipelineRun.
+	if pr.ObjectMeta.Annotations == nil {
+		pr.ObjectMeta.Annotations = make(map[string]string, len(pipelineMeta.Annotations))
+	}
+	for key, value := range pipelineMeta.Annotations {
+		pr.ObjectMeta.Annotations[key] = value
+	}
+
+	d, err := v1alpha1.BuildDAG(pipelineSpec.Tasks)
+	if err != nil {
+		status.MarkFailed(&pr.Status, ReasonInvalidGraph, "PipelineRun %s's Pipeline DAG is invalid: %s",
+			fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), err)
+		return nil
+	}
+	if err := resources.ValidateResourceBindings(pipelineSpec, pr); err != nil {
+		status.MarkFailed(&pr.Status, ReasonInvalidBindings, "PipelineRun %s doesn't bind Pipeline %s's PipelineResources correctly: %s",
+			fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), fmt.Sprintf("%s/%s", pr.Namespace, pr.Spec.PipelineRef.Name), err)
+		return nil
+	}
+	providedResources, err := resources.GetResourcesFromBindings(pr, c.resourceLister.PipelineResources(pr.Namespace).Get)
+	if err != nil {
+		status.MarkFailed(&pr.Status, ReasonCouldntGetResource, "PipelineRun %s can't be Run; it tries to bind Resources that don't exist: %s",
+			fmt.Sprintf("%s/%s", pipelineMeta.Namespace, pr.Name), err)
+		return nil
+	}
+
+	params, err := resources.ResolveParamsFrom(pr, c.configMapLister.ConfigMaps(pr.Namespace).Get, c.secretLister.Secrets(pr.Namespace).Get)
+	if err != nil {
+		status.MarkFailed(&pr.Status, ReasonCouldntGetParamsFrom, "PipelineRun %s can't be Run; couldn't read its paramsFrom: %s",
+			fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), err)
+		return nil
+	}
+	params, err = resources.ResolveParamReferences(pipelineSpec, params)
+	if err != nil {
+		status.MarkFailed(&pr.Status, ReasonInvalidParamReference, "PipelineRun %s can't be Run; its params are invalid: %s",
+			fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), err)
+		return nil
+	}
+	// The params read from paramsFrom are only set on a copy of the
+	// PipelineRun so they're never written back to it.
+	prWithParams := pr.DeepCopy()
+	prWithParams.Spec.Params = params
+
+	// Ensure that the parameters from the PipelineRun are overriding Pipeline parameters with the same type.
+	// Weird substitution issues can occur if this is not validated (ApplyParameters() does not verify type).
+	if err := resources.ValidateParamTypesMatching(pipelineSpec, prWithParams); err != nil {
+		status.MarkFailed(&pr.Status, ReasonParameterTypeMismatch, "PipelineRun %s parameters have mismatching types with Pipeline %s's parameters: %s",
+			fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), fmt.Sprintf("%s/%s", pr.Namespace, pr.Spec.PipelineRef.Name), err)
+		return nil
+	}
+
+	if err := resources.ValidateParamConstraints(pipelineSpec, prWithParams); err != nil {
+		status.MarkFailed(&pr.Status, ReasonParameterConstraintViolated, "PipelineRun %s parameters violate the constraints of Pipeline %s's parameters: %s",
+			fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), fmt.Sprintf("%s/%s", pr.Namespace, pr.Spec.PipelineRef.Name), err)
+		return nil
+	}
+
+	if err := resources.ValidateSecretParams(pipelineSpec, prWithParams); err != nil {
+		status.MarkFailed(&pr.Status, ReasonInvalidParamReference, "PipelineRun %s can't be Run; its params are invalid: %s",
+			fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), err)
+		return nil
+	}
+
+	// Apply parameter substitution from the PipelineRun
+	pipelineSpec = resources.ApplyParameters(pipelineSpec, prWithParams)
+
+	pipelineState, err := resources.ResolvePipelineRun(
+		*pr,
+		func(name string) (v1alpha1.TaskInterface, error) {
+			return c.taskLister.Tasks(pr.Namespace).Get(name)
+		},
+		func(name string) (*v1alpha1.TaskRun, error) {
+			return c.taskRunLister.TaskRuns(pr.Namespace).Get(name)
+		},
+		func(name string) (v1alpha1.TaskInterface, error) {
+			return c.clusterTaskLister.Get(name)
+		},
+		func(name string) (*v1alpha1.Condition, error) {
+			return c.conditionLister.Conditions(pr.Namespace).Get(name)
+		},
+		pipelineSpec.Tasks, providedResources,
+	)
+	if err != nil {
+		switch err := err.(type) {
+		case *resources.TaskNotFoundError:
+			status.MarkFailed(&pr.Status, ReasonCouldntGetTask, "Pipeline %s can't be Run; it contains Tasks that don't exist: %s",
+				fmt.Sprintf("%s/%s", pipelineMeta.Namespace, pipelineMeta.Name), err)
+		case *resources.ConditionNotFoundError:
+			status.MarkFailed(&pr.Status, ReasonCouldntGetCondition, "PipelineRun %s can't be Run; it contains Conditions that don't exist:  %s",
+				fmt.Sprintf("%s/%s", pipelineMeta.Namespace, pr.Name), err)
+		default:
+			status.MarkFailed(&pr.Status, ReasonFailedValidation, "PipelineRun %s can't be Run; couldn't resolve all references: %s",
+				fmt.Sprintf("%s/%s", pipelineMeta.Namespace, pr.Name), err)
+		}
+		return nil
+	}
+
+	return &resolvedPipelineRun{
+		pipelineMeta: pipelineMeta,
+		pipelineSpec: pipelineSpec,
+		dag:          d,
+		state:        pipelineState,
+	}
+}
+
+// validate checks the linkages and the resources of the resolved
+// PipelineTasks of a PipelineRun which isn't done. If they're invalid, the
+// PipelineRun is marked as failed and validate returns false.
+func (c *Reconciler) validate(pr *v1alpha1.PipelineRun, rpr *resolvedPipelineRun) bool {
+	if err := resources.ValidateFrom(rpr.state); err != nil {
+		status.MarkFailed(&pr.Status, ReasonFailedValidation, "Pipeline %s can't be Run; it invalid input/output linkages: %s",
+			fmt.Sprintf("%s/%s", rpr.pipelineMeta.Namespace, pr.Name), err)
+		return false
+	}
+	for _, rprt := range rpr.state {
+		if err := taskrun.ValidateResolvedTaskResources(rprt.PipelineTask.Params, rprt.ResolvedTaskResources); err != nil {
+			c.Logger.Errorf("Failed to validate pipelinerun %q with error %v", pr.Name, err)
+			status.MarkFailed(&pr.Status, ReasonFailedValidation, "%v", err)
+			return false
+		}
+	}
+	return true
+}
+
+// schedule returns the PipelineTasks which can run next: those whose
+// dependencies all succeeded and which haven't started yet.
+func (c *Reconciler) schedule(rpr *resolvedPipelineRun) ([]*resources.ResolvedPipelineRunTask, error) {
+	defer c.recordPhase(phaseSchedule, time.Now())
+
+	candidateTasks, err := dag.GetSchedulable(rpr.dag, rpr.state.SuccessfulPipelineTaskNames()...)
+	return rpr.state.GetNextTasks(candidateTasks), err
+}
+
+// createRuns creates the TaskRuns of the scheduled PipelineTasks, or their
+// condition checks first if they have conditions.
+func (c *Reconciler) createRuns(pr *v1alpha1.PipelineRun, rprts []*resources.ResolvedPipelineRunTask) error {
+	defer c.recordPhase(phaseCreateRuns, time.Now())
+
+	as, err := artifacts.InitializeArtifactStorage(c.Images, pr, c.KubeClientSet, c.Logger)
+	if err != nil {
+		c.Logger.Infof("PipelineRun failed to initialize artifact storage %s", pr.Name)
+		return err
+	}
+
+	for _, rprt := range rprts {
+		if rprt == nil {
+			continue
+		}
+		if rprt.ResolvedConditionChecks == nil || rprt.ResolvedConditionChecks.IsSuccess() {
+			rprt.TaskRun, err = c.createTaskRun(rprt, pr, as.StorageBasePath(pr))
+			if err != nil {
+				c.Recorder.Eventf(pr, corev1.EventTypeWarning, "TaskRunCreationFailed", "Failed to create TaskRun %q: %v", rprt.TaskRunName, err)
+				return xerrors.Errorf("error creating TaskRun called %s for PipelineTask %s from PipelineRun %s: %w", rprt.TaskRunName, rprt.PipelineTask.Name, pr.Name, err)
+			}
+		} else if !rprt.ResolvedConditionChecks.HasStarted() {
+			for _, rcc := range rprt.ResolvedConditionChecks {
+				rcc.ConditionCheck, err = c.makeConditionCheckContainer(rprt, rcc, pr)
+				if err != nil {
+					c.Recorder.Eventf(pr, corev1.EventTypeWarning, "ConditionCheckCreationFailed", "Failed to create TaskRun %q: %v", rcc.ConditionCheckName, err)
+					return xerrors.Errorf("error creating ConditionCheck container called %s for PipelineTask %s from PipelineRun %s: %w", rcc.ConditionCheckName, rprt.PipelineTask.Name, pr.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// aggregateStatus sets the condition of the PipelineRun and the status of
+// its TaskRuns from the resolved state, emitting an event if the condition
+// changed.
+func (c *Reconciler) aggregateStatus(pr *v1alpha1.PipelineRun, rpr *resolvedPipelineRun) {
+	defer c.recordPhase(phaseStatus, time.Now())
+
+	before := pr.Status.GetCondition(apis.ConditionSucceeded)
+	after := resources.GetPipelineConditionStatus(pr, rpr.state, c.Logger, rpr.dag)
+	pr.Status.SetCondition(after)
+	reconciler.EmitEvent(c.Recorder, before, after, pr)
+
+	pr.Status.TaskRuns = getTaskRunsStatus(pr, rpr.state)
+	pr.Status.Stages = resources.GetPipelineStagesStatus(rpr.state, rpr.dag)
+	pr.Status.StageSummary = resources.StagesSummary(pr.Status.Stages)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
+	taskrunresources "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+)
+
+func newPhasesReconciler() *Reconciler {
+	return &Reconciler{
+		Base: &reconciler.Base{
+			Logger:   zap.NewNop().Sugar(),
+			Recorder: record.NewFakeRecorder(10),
+		},
+	}
+}
+
+func resolvedPipelineRunForPhases(t *testing.T, firstStatus corev1.ConditionStatus) *resolvedPipelineRun {
+	t.Helper()
+	tasks := []v1alpha1.PipelineTask{{
+		Name:    "unit-tests",
+		TaskRef: v1alpha1.TaskRef{Name: "unit-test-task"},
+	}, {
+		Name:     "deploy",
+		TaskRef:  v1alpha1.TaskRef{Name: "deploy-task"},
+		RunAfter: []string{"unit-tests"},
+	}}
+	d, err := v1alpha1.BuildDAG(tasks)
+	if err != nil {
+		t.Fatalf("BuildDAG: %v", err)
+	}
+	return &resolvedPipelineRun{
+		pipelineSpec: &v1alpha1.PipelineSpec{Tasks: tasks},
+		dag:          d,
+		state: resources.PipelineRunState{{
+			TaskRunName:  "test-pipeline-run-unit-tests",
+			PipelineTask: &tasks[0],
+			TaskRun: tb.TaskRun("test-pipeline-run-unit-tests", "foo", tb.TaskRunStatus(
+				tb.StatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: firstStatus}),
+			)),
+		}, {
+			TaskRunName:  "test-pipeline-run-deploy",
+			PipelineTask: &tasks[1],
+		}},
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		firstStatus corev1.ConditionStatus
+		want        []string
+	}{{
+		desc:        "first task running",
+		firstStatus: corev1.ConditionUnknown,
+	}, {
+		desc:        "first task succeeded",
+		firstStatus: corev1.ConditionTrue,
+		want:        []string{"deploy"},
+	}, {
+		desc:        "first task failed",
+		firstStatus: corev1.ConditionFalse,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			rprts, err := newPhasesReconciler().schedule(resolvedPipelineRunForPhases(t, tc.firstStatus))
+			if err != nil {
+				t.Fatalf("schedule: %v", err)
+			}
+			var got []string
+			for _, rprt := range rprts {
+				got = append(got, rprt.PipelineTask.Name)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("scheduled tasks diff -want, +got: %v", d)
+			}
+		})
+	}
+}
+
+func TestAggregateStatus(t *testing.T) {
+	for _, tc := range []struct {
+		desc        string
+		firstStatus corev1.ConditionStatus
+		wantStatus  corev1.ConditionStatus
+		wantReason  string
+		wantEvent   bool
+	}{{
+		desc:        "running",
+		firstStatus: corev1.ConditionTrue,
+		wantStatus:  corev1.ConditionUnknown,
+		wantReason:  resources.ReasonRunning,
+	}, {
+		desc:        "failed",
+		firstStatus: corev1.ConditionFalse,
+		wantStatus:  corev1.ConditionFalse,
+		wantReason:  resources.ReasonFailed,
+		wantEvent:   true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := newPhasesReconciler()
+			pr := tb.PipelineRun("test-pipeline-run", "foo", tb.PipelineRunSpec("test-pipeline"))
+			pr.Status.InitializeConditions()
+
+			c.aggregateStatus(pr, resolvedPipelineRunForPhases(t, tc.firstStatus))
+
+			condition := pr.Status.GetCondition(apis.ConditionSucceeded)
+			if condition.Status != tc.wantStatus || condition.Reason != tc.wantReason {
+				t.Errorf("Expected the PipelineRun to be %s with reason %s, got %v", tc.wantStatus, tc.wantReason, condition)
+			}
+			if _, ok := pr.Status.TaskRuns["test-pipeline-run-unit-tests"]; !ok {
+				t.Errorf("Expected the status of TaskRun test-pipeline-run-unit-tests in the PipelineRun status, got %v", pr.Status.TaskRuns)
+			}
+			events := c.Recorder.(*record.FakeRecorder).Events
+			if gotEvent := len(events) > 0; gotEvent != tc.wantEvent {
+				t.Errorf("Expected an event: %t, got %d events", tc.wantEvent, len(events))
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	rpr := resolvedPipelineRunForPhases(t, corev1.ConditionTrue)
+	for _, rprt := range rpr.state {
+		rprt.ResolvedTaskResources = &taskrunresources.ResolvedTaskResources{TaskSpec: &v1alpha1.TaskSpec{}}
+	}
+	pr := tb.PipelineRun("test-pipeline-run", "foo")
+	pr.Status.InitializeConditions()
+	if !newPhasesReconciler().validate(pr, rpr) {
+		t.Fatalf("Expected the PipelineRun to be valid, got %v", pr.Status.GetCondition(apis.ConditionSucceeded))
+	}
+
+	// The Task of the first PipelineTask requires a param it isn't passed.
+	rpr.state[0].ResolvedTaskResources = &taskrunresources.ResolvedTaskResources{
+		TaskSpec: &v1alpha1.TaskSpec{Inputs: &v1alpha1.Inputs{
+			Params: []v1alpha1.ParamSpec{{Name: "required", Type: v1alpha1.ParamTypeString}},
+		}},
+	}
+	if newPhasesReconciler().validate(pr, rpr) {
+		t.Fatal("Expected the PipelineRun to be invalid")
+	}
+	if condition := pr.Status.GetCondition(apis.ConditionSucceeded); !condition.IsFalse() || condition.Reason != ReasonFailedValidation {
+		t.Errorf("Expected the PipelineRun to fail with %s, got %v", ReasonFailedValidation, condition)
+	}
+}
@@ -18,7 +18,6 @@ package pipelinerun
 
 import (
 	"context"
-	"fmt"
 	"reflect"
 	"time"
 
@@ -30,15 +29,17 @@ import (
 	"github.com/tektoncd/pipeline/pkg/artifacts"
 	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/reconciler"
-	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
-	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
+	"github.com/tektoncd/pipeline/pkg/status"
 	"go.uber.org/zap"
 	"golang.org/x/xerrors"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/configmap"
@@ -57,6 +58,17 @@ const (
 	// parameter(s) declared in the PipelineRun do not have the some declared type as the
 	// parameters(s) declared in the Pipeline that they are supposed to override.
 	ReasonParameterTypeMismatch = "ParameterTypeMismatch"
+	// ReasonParameterConstraintViolated indicates that the reason for the failure status is
+	// that parameter(s) declared in the PipelineRun aren't one of the enum or don't match the
+	// pattern of the parameter(s) declared in the Pipeline.
+	ReasonParameterConstraintViolated = "ParameterConstraintViolated"
+	// ReasonCouldntGetParamsFrom indicates that the reason for the failure status is that
+	// the ConfigMaps or Secrets the PipelineRun reads params from couldn't all be retrieved
+	ReasonCouldntGetParamsFrom = "CouldntGetParamsFrom"
+	// ReasonInvalidParamReference indicates that the reason for the failure status is that
+	// the params of the PipelineRun reference each other in a cycle, or that a param read
+	// from a Secret isn't only passed whole to the params of the Tasks
+	ReasonInvalidParamReference = "InvalidParamReference"
 	// ReasonCouldntGetTask indicates that the reason for the failure status is that the
 	// associated Pipeline's Tasks couldn't all be retrieved
 	ReasonCouldntGetTask = "CouldntGetTask"
@@ -98,10 +110,17 @@ type Reconciler struct {
 	clusterTaskLister listers.ClusterTaskLister
 	resourceLister    listers.PipelineResourceLister
 	conditionLister   listers.ConditionLister
+	quotaLister       listers.PipelineQuotaLister
+	configMapLister   corelisters.ConfigMapLister
+	secretLister      corelisters.SecretLister
 	tracker           tracker.Interface
 	configStore       configStore
 	timeoutHandler    *reconciler.TimeoutSet
 	metrics           *Recorder
+	clock             clock.Clock
+	// enqueueAfter is the one of the controller.Impl the reconciler was
+	// created with.
+	enqueueAfter func(obj interface{}, after time.Duration)
 }
 
 var (
@@ -136,8 +155,45 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 
 	// Don't modify the informer's copy.
 	pr := original.DeepCopy()
+
+	// A PipelineRun created outside of its execution window waits for it
+	// to open before starting, so that its timeout doesn't count down.
+	if !pr.HasStarted() && !pr.IsCancelled() && pr.Spec.ExecutionWindow != nil {
+		if wait := reconciler.WaitForExecutionWindow(&pr.Status, pr.Spec.ExecutionWindow, c.clock.Now()); wait > 0 {
+			c.enqueueAfter(pr, wait)
+			if !equality.Semantic.DeepEqual(original.Status, pr.Status) {
+				if _, err := c.updateStatus(pr); err != nil {
+					c.Logger.Warn("Failed to update PipelineRun status", zap.Error(err))
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	// A PipelineRun waits for the PipelineQuotas of its namespace to let
+	// it start.
+	if !pr.HasStarted() && !pr.IsCancelled() {
+		wait, err := c.waitForQuotas(pr)
+		if err != nil {
+			c.Logger.Errorf("Failed to check the quotas of PipelineRun %q: %v", pr.Name, err)
+			return err
+		}
+		if wait > 0 {
+			c.enqueueAfter(pr, wait)
+			if !equality.Semantic.DeepEqual(original.Status, pr.Status) {
+				if _, err := c.updateStatus(pr); err != nil {
+					c.Logger.Warn("Failed to update PipelineRun status", zap.Error(err))
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
 	if !pr.HasStarted() {
 		pr.Status.InitializeConditions()
+		pr.Status.RerunOf = pr.Annotations[v1alpha1.RerunOfAnnotationKey]
 		// In case node time was not synchronized, when controller has been scheduled to other nodes.
 		if pr.Status.StartTime.Sub(pr.CreationTimestamp.Time) < 0 {
 			c.Logger.Warnf("PipelineRun %s createTimestamp %s is after the pipelineRun started %s", pr.GetRunKey(), pr.CreationTimestamp, pr.Status.StartTime)
@@ -233,227 +289,48 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1alpha1.PipelineRun) er
 	// and may not have had all of the assumed default specified.
 	pr.SetDefaults(v1alpha1.WithUpgradeViaDefaulting(ctx))
 
-	getPipelineFunc := c.getPipelineFunc(pr)
-	pipelineMeta, pipelineSpec, err := resources.GetPipelineData(pr, getPipelineFunc)
-	if err != nil {
-		c.Logger.Errorf("Failed to determine Pipeline spec to use for pipelinerun %s: %v", pr.Name, err)
-		pr.Status.SetCondition(&apis.Condition{
-			Type:   apis.ConditionSucceeded,
-			Status: corev1.ConditionFalse,
-			Reason: ReasonCouldntGetPipeline,
-			Message: fmt.Sprintf("Error retrieving pipeline for pipelinerun %s: %s",
-				fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), err),
-		})
-		return nil
-	}
-
-	// Propagate labels from Pipeline to PipelineRun.
-	if pr.ObjectMeta.Labels == nil {
-		pr.ObjectMeta.Labels = make(map[string]string, len(pipelineMeta.Labels)+1)
-	}
-	for key, value := range pipelineMeta.Labels {
-		pr.ObjectMeta.Labels[key] = value
-	}
-	pr.ObjectMeta.Labels[pipeline.GroupName+pipeline.PipelineLabelKey] = pipelineMeta.Name
-
-	// Propagate annotations from Pipeline to PipelineRun.
-	if pr.ObjectMeta.Annotations == nil {
-		pr.ObjectMeta.Annotations = make(map[string]string, len(pipelineMeta.Annotations))
-	}
-	for key, value := range pipelineMeta.Annotations {
-		pr.ObjectMeta.Annotations[key] = value
-	}
-
-	d, err := v1alpha1.BuildDAG(pipelineSpec.Tasks)
-	if err != nil {
-		// This Run has failed, so we need to mark it as failed and stop reconciling it
-		pr.Status.SetCondition(&apis.Condition{
-			Type:   apis.ConditionSucceeded,
-			Status: corev1.ConditionFalse,
-			Reason: ReasonInvalidGraph,
-			Message: fmt.Sprintf("PipelineRun %s's Pipeline DAG is invalid: %s",
-				fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), err),
-		})
-		return nil
-	}
-	if err := resources.ValidateResourceBindings(pipelineSpec, pr); err != nil {
-		// This Run has failed, so we need to mark it as failed and stop reconciling it
-		pr.Status.SetCondition(&apis.Condition{
-			Type:   apis.ConditionSucceeded,
-			Status: corev1.ConditionFalse,
-			Reason: ReasonInvalidBindings,
-			Message: fmt.Sprintf("PipelineRun %s doesn't bind Pipeline %s's PipelineResources correctly: %s",
-				fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), fmt.Sprintf("%s/%s", pr.Namespace, pr.Spec.PipelineRef.Name), err),
-		})
-		return nil
-	}
-	providedResources, err := resources.GetResourcesFromBindings(pr, c.resourceLister.PipelineResources(pr.Namespace).Get)
-	if err != nil {
-		// This Run has failed, so we need to mark it as failed and stop reconciling it
-		pr.Status.SetCondition(&apis.Condition{
-			Type:   apis.ConditionSucceeded,
-			Status: corev1.ConditionFalse,
-			Reason: ReasonCouldntGetResource,
-			Message: fmt.Sprintf("PipelineRun %s can't be Run; it tries to bind Resources that don't exist: %s",
-				fmt.Sprintf("%s/%s", pipelineMeta.Namespace, pr.Name), err),
-		})
-		return nil
-	}
-
-	// Ensure that the parameters from the PipelineRun are overriding Pipeline parameters with the same type.
-	// Weird substitution issues can occur if this is not validated (ApplyParameters() does not verify type).
-	err = resources.ValidateParamTypesMatching(pipelineSpec, pr)
-	if err != nil {
-		// This Run has failed, so we need to mark it as failed and stop reconciling it
-		pr.Status.SetCondition(&apis.Condition{
-			Type:   apis.ConditionSucceeded,
-			Status: corev1.ConditionFalse,
-			Reason: ReasonParameterTypeMismatch,
-			Message: fmt.Sprintf("PipelineRun %s parameters have mismatching types with Pipeline %s's parameters: %s",
-				fmt.Sprintf("%s/%s", pr.Namespace, pr.Name), fmt.Sprintf("%s/%s", pr.Namespace, pr.Spec.PipelineRef.Name), err),
-		})
-		return nil
-	}
-
-	// Apply parameter substitution from the PipelineRun
-	pipelineSpec = resources.ApplyParameters(pipelineSpec, pr)
-
-	pipelineState, err := resources.ResolvePipelineRun(
-		*pr,
-		func(name string) (v1alpha1.TaskInterface, error) {
-			return c.taskLister.Tasks(pr.Namespace).Get(name)
-		},
-		func(name string) (*v1alpha1.TaskRun, error) {
-			return c.taskRunLister.TaskRuns(pr.Namespace).Get(name)
-		},
-		func(name string) (v1alpha1.TaskInterface, error) {
-			return c.clusterTaskLister.Get(name)
-		},
-		func(name string) (*v1alpha1.Condition, error) {
-			return c.conditionLister.Conditions(pr.Namespace).Get(name)
-		},
-		pipelineSpec.Tasks, providedResources,
-	)
-
-	if err != nil {
-		// This Run has failed, so we need to mark it as failed and stop reconciling it
-		switch err := err.(type) {
-		case *resources.TaskNotFoundError:
-			pr.Status.SetCondition(&apis.Condition{
-				Type:   apis.ConditionSucceeded,
-				Status: corev1.ConditionFalse,
-				Reason: ReasonCouldntGetTask,
-				Message: fmt.Sprintf("Pipeline %s can't be Run; it contains Tasks that don't exist: %s",
-					fmt.Sprintf("%s/%s", pipelineMeta.Namespace, pipelineMeta.Name), err),
-			})
-		case *resources.ConditionNotFoundError:
-			pr.Status.SetCondition(&apis.Condition{
-				Type:   apis.ConditionSucceeded,
-				Status: corev1.ConditionFalse,
-				Reason: ReasonCouldntGetCondition,
-				Message: fmt.Sprintf("PipelineRun %s can't be Run; it contains Conditions that don't exist:  %s",
-					fmt.Sprintf("%s/%s", pipelineMeta.Namespace, pr.Name), err),
-			})
-		default:
-			pr.Status.SetCondition(&apis.Condition{
-				Type:   apis.ConditionSucceeded,
-				Status: corev1.ConditionFalse,
-				Reason: ReasonFailedValidation,
-				Message: fmt.Sprintf("PipelineRun %s can't be Run; couldn't resolve all references: %s",
-					fmt.Sprintf("%s/%s", pipelineMeta.Namespace, pr.Name), err),
-			})
-		}
+	rpr := c.resolve(pr)
+	if rpr == nil {
+		// This Run has failed, and its status says why.
 		return nil
 	}
 
-	if pipelineState.IsDone() && pr.IsDone() {
+	if rpr.state.IsDone() && pr.IsDone() {
 		c.timeoutHandler.Release(pr)
 		c.Recorder.Event(pr, corev1.EventTypeNormal, eventReasonSucceeded, "PipelineRun completed successfully.")
 		return nil
 	}
 
-	if err := resources.ValidateFrom(pipelineState); err != nil {
-		// This Run has failed, so we need to mark it as failed and stop reconciling it
-		pr.Status.SetCondition(&apis.Condition{
-			Type:   apis.ConditionSucceeded,
-			Status: corev1.ConditionFalse,
-			Reason: ReasonFailedValidation,
-			Message: fmt.Sprintf("Pipeline %s can't be Run; it invalid input/output linkages: %s",
-				fmt.Sprintf("%s/%s", pipelineMeta.Namespace, pr.Name), err),
-		})
+	// The PipelineTasks are only validated while the PipelineRun runs, so
+	// that a done PipelineRun isn't failed by changes made since.
+	if !c.validate(pr, rpr) {
 		return nil
 	}
 
-	for _, rprt := range pipelineState {
-		err := taskrun.ValidateResolvedTaskResources(rprt.PipelineTask.Params, rprt.ResolvedTaskResources)
-		if err != nil {
-			c.Logger.Errorf("Failed to validate pipelinerun %q with error %v", pr.Name, err)
-			pr.Status.SetCondition(&apis.Condition{
-				Type:    apis.ConditionSucceeded,
-				Status:  corev1.ConditionFalse,
-				Reason:  ReasonFailedValidation,
-				Message: err.Error(),
-			})
-			// update pr completed time
-			return nil
-		}
-	}
-
 	// If the pipelinerun is cancelled, cancel tasks and update status
 	if pr.IsCancelled() {
 		before := pr.Status.GetCondition(apis.ConditionSucceeded)
-		err := cancelPipelineRun(pr, pipelineState, c.PipelineClientSet)
+		err := cancelPipelineRun(pr, rpr.state, c.PipelineClientSet)
 		after := pr.Status.GetCondition(apis.ConditionSucceeded)
 		reconciler.EmitEvent(c.Recorder, before, after, pr)
 		return err
 	}
 
-	candidateTasks, err := dag.GetSchedulable(d, pipelineState.SuccessfulPipelineTaskNames()...)
+	rprts, err := c.schedule(rpr)
 	if err != nil {
 		c.Logger.Errorf("Error getting potential next tasks for valid pipelinerun %s: %v", pr.Name, err)
 	}
-
-	rprts := pipelineState.GetNextTasks(candidateTasks)
-
-	var as artifacts.ArtifactStorageInterface
-	if as, err = artifacts.InitializeArtifactStorage(c.Images, pr, c.KubeClientSet, c.Logger); err != nil {
-		c.Logger.Infof("PipelineRun failed to initialize artifact storage %s", pr.Name)
+	if err := c.createRuns(pr, rprts); err != nil {
 		return err
 	}
-
-	for _, rprt := range rprts {
-		if rprt == nil {
-			continue
-		}
-		if rprt.ResolvedConditionChecks == nil || rprt.ResolvedConditionChecks.IsSuccess() {
-			rprt.TaskRun, err = c.createTaskRun(rprt, pr, as.StorageBasePath(pr))
-			if err != nil {
-				c.Recorder.Eventf(pr, corev1.EventTypeWarning, "TaskRunCreationFailed", "Failed to create TaskRun %q: %v", rprt.TaskRunName, err)
-				return xerrors.Errorf("error creating TaskRun called %s for PipelineTask %s from PipelineRun %s: %w", rprt.TaskRunName, rprt.PipelineTask.Name, pr.Name, err)
-			}
-		} else if !rprt.ResolvedConditionChecks.HasStarted() {
-			for _, rcc := range rprt.ResolvedConditionChecks {
-				rcc.ConditionCheck, err = c.makeConditionCheckContainer(rprt, rcc, pr)
-				if err != nil {
-					c.Recorder.Eventf(pr, corev1.EventTypeWarning, "ConditionCheckCreationFailed", "Failed to create TaskRun %q: %v", rcc.ConditionCheckName, err)
-					return xerrors.Errorf("error creating ConditionCheck container called %s for PipelineTask %s from PipelineRun %s: %w", rcc.ConditionCheckName, rprt.PipelineTask.Name, pr.Name, err)
-				}
-			}
-		}
-	}
-	before := pr.Status.GetCondition(apis.ConditionSucceeded)
-	after := resources.GetPipelineConditionStatus(pr, pipelineState, c.Logger, d)
-	pr.Status.SetCondition(after)
-	reconciler.EmitEvent(c.Recorder, before, after, pr)
-
-	pr.Status.TaskRuns = getTaskRunsStatus(pr, pipelineState)
+	c.aggregateStatus(pr, rpr)
 
 	c.Logger.Infof("PipelineRun %s status is being set to %s", pr.Name, pr.Status.GetCondition(apis.ConditionSucceeded))
 	return nil
 }
 
 func getTaskRunsStatus(pr *v1alpha1.PipelineRun, state []*resources.ResolvedPipelineRunTask) map[string]*v1alpha1.PipelineRunTaskRunStatus {
-	status := make(map[string]*v1alpha1.PipelineRunTaskRunStatus)
+	taskRunsStatus := make(map[string]*v1alpha1.PipelineRunTaskRunStatus)
 	for _, rprt := range state {
 		if rprt.TaskRun == nil && rprt.ResolvedConditionChecks == nil {
 			continue
@@ -488,17 +365,12 @@ func getTaskRunsStatus(pr *v1alpha1.PipelineRun, state []*resources.ResolvedPipe
 				if prtrs.Status == nil {
 					prtrs.Status = &v1alpha1.TaskRunStatus{}
 				}
-				prtrs.Status.SetCondition(&apis.Condition{
-					Type:    apis.ConditionSucceeded,
-					Status:  corev1.ConditionFalse,
-					Reason:  resources.ReasonConditionCheckFailed,
-					Message: fmt.Sprintf("ConditionChecks failed for Task %s in PipelineRun %s", rprt.TaskRunName, pr.Name),
-				})
+				status.MarkFailed(prtrs.Status, resources.ReasonConditionCheckFailed, "ConditionChecks failed for Task %s in PipelineRun %s", rprt.TaskRunName, pr.Name)
 			}
 		}
-		status[rprt.TaskRunName] = prtrs
+		taskRunsStatus[rprt.TaskRunName] = prtrs
 	}
-	return status
+	return taskRunsStatus
 }
 
 func (c *Reconciler) updateTaskRunsStatusDirectly(pr *v1alpha1.PipelineRun) error {
@@ -522,12 +394,25 @@ func (c *Reconciler) createTaskRun(rprt *resources.ResolvedPipelineRunTask, pr *
 	tr, _ := c.taskRunLister.TaskRuns(pr.Namespace).Get(rprt.TaskRunName)
 	if tr != nil {
 		//is a retry
+		tr = tr.DeepCopy()
 		addRetryHistory(tr)
 		clearStatus(tr)
 		tr.Status.SetCondition(&apis.Condition{
 			Type:   apis.ConditionSucceeded,
 			Status: corev1.ConditionUnknown,
 		})
+		if pt, ok := retryPodTemplate(rprt.PipelineTask, len(tr.Status.RetriesStatus)); ok {
+			// The spec is updated before the status, for the pod of the
+			// retry to be created with the pod template of the retry.
+			status := tr.Status
+			tr.Spec.PodTemplate = pt
+			updated, err := c.PipelineClientSet.TektonV1alpha1().TaskRuns(pr.Namespace).Update(tr)
+			if err != nil {
+				return nil, err
+			}
+			tr = updated
+			tr.Status = status
+		}
 		return c.PipelineClientSet.TektonV1alpha1().TaskRuns(pr.Namespace).UpdateStatus(tr)
 	}
 
@@ -541,15 +426,21 @@ func (c *Reconciler) createTaskRun(rprt *resources.ResolvedPipelineRunTask, pr *
 		},
 		Spec: v1alpha1.TaskRunSpec{
 			TaskRef: &v1alpha1.TaskRef{
-				Name: rprt.ResolvedTaskResources.TaskName,
-				Kind: rprt.ResolvedTaskResources.Kind,
+				Name:     rprt.ResolvedTaskResources.TaskName,
+				Kind:     rprt.ResolvedTaskResources.Kind,
+				Checksum: rprt.PipelineTask.TaskRef.Checksum,
 			},
 			Inputs: v1alpha1.TaskRunInputs{
 				Params: rprt.PipelineTask.Params,
 			},
+			Checkout:           rprt.PipelineTask.Checkout,
 			ServiceAccountName: pr.GetServiceAccountName(rprt.PipelineTask.Name),
 			Timeout:            getTaskRunTimeout(pr),
 			PodTemplate:        pr.Spec.PodTemplate,
+			Files:              pr.Spec.Files,
+			// The TaskRun expiration controller holds the TaskRuns of a
+			// PipelineRun until it is deleted or expired.
+			ExpirationSecondsTTL: pr.Spec.TaskRunTTL,
 		}}
 
 	resources.WrapSteps(&tr.Spec, rprt.PipelineTask, rprt.ResolvedTaskResources.Inputs, rprt.ResolvedTaskResources.Outputs, storageBasePath)
@@ -560,9 +451,22 @@ func (c *Reconciler) createTaskRun(rprt *resources.ResolvedPipelineRunTask, pr *
 func addRetryHistory(tr *v1alpha1.TaskRun) {
 	newStatus := *tr.Status.DeepCopy()
 	newStatus.RetriesStatus = nil
+	newStatus.PodTemplate = tr.Spec.PodTemplate.DeepCopy()
 	tr.Status.RetriesStatus = append(tr.Status.RetriesStatus, newStatus)
 }
 
+// retryPodTemplate returns the pod template of the retry-th retry of pt, if
+// it has pod templates for its retries.
+func retryPodTemplate(pt *v1alpha1.PipelineTask, retry int) (v1alpha1.PodTemplate, bool) {
+	if len(pt.RetryPodTemplates) == 0 || retry < 1 {
+		return v1alpha1.PodTemplate{}, false
+	}
+	if retry > len(pt.RetryPodTemplates) {
+		retry = len(pt.RetryPodTemplates)
+	}
+	return pt.RetryPodTemplates[retry-1], true
+}
+
 func clearStatus(tr *v1alpha1.TaskRun) {
 	tr.Status.StartTime = nil
 	tr.Status.CompletionTime = nil
@@ -618,6 +522,20 @@ func getTaskRunTimeout(pr *v1alpha1.PipelineRun) *metav1.Duration {
 	return taskRunTimeout
 }
 
+// waitForQuotas returns how long pr must wait for the PipelineQuotas of its
+// namespace to let it start, and marks it accordingly.
+func (c *Reconciler) waitForQuotas(pr *v1alpha1.PipelineRun) (time.Duration, error) {
+	quotas, err := c.quotaLister.PipelineQuotas(pr.Namespace).List(labels.Everything())
+	if err != nil || len(quotas) == 0 {
+		return 0, err
+	}
+	runs, err := reconciler.QuotaRuns(pr.Namespace, c.pipelineRunLister, c.taskRunLister)
+	if err != nil {
+		return 0, err
+	}
+	return reconciler.WaitForQuotas(&pr.Status, quotas, reconciler.PipelineRunQuotaRun(pr), runs, c.clock.Now()), nil
+}
+
 func (c *Reconciler) updateStatus(pr *v1alpha1.PipelineRun) (*v1alpha1.PipelineRun, error) {
 	newPr, err := c.pipelineRunLister.PipelineRuns(pr.Namespace).Get(pr.Name)
 	if err != nil {
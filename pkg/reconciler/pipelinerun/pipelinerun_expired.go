@@ -0,0 +1,493 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apisconfig "github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	cleanuppolicyinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/cleanuppolicy"
+	pipelineruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelinerun"
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+	namespaceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/namespace"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// expirationAgentName defines logging agent name for the PipelineRun expiration controller
+	expirationAgentName = "pipelinerun-expiration-controller"
+	// expirationControllerName defines name for the PipelineRun expiration controller
+	expirationControllerName = "PipelineRunExpiration"
+)
+
+// ExpirationReconciler deletes finished PipelineRuns, along with their
+// TaskRuns, once their ExpirationSecondsTTL has elapsed, or once they are
+// beyond the history limit of their CleanupPolicy.
+type ExpirationReconciler struct {
+	*reconciler.Base
+
+	pipelineRunLister   listers.PipelineRunLister
+	cleanupPolicyLister listers.CleanupPolicyLister
+	namespaceLister     corev1listers.NamespaceLister
+	scope               taskrun.ExpirationScope
+	clock               clock.Clock
+	filter              func(obj interface{}) bool
+	configStore         reconciler.ConfigStore
+	throttle            *taskrun.DeletionThrottle
+	skewCheck           *taskrun.ClockSkewCheck
+	metrics             *taskrun.CleanupMetrics
+	histories           *taskrun.PrunedHistories
+	// elector, if set, restricts the PipelineRuns cleaned up to the namespaces
+	// of the buckets this replica leads.
+	elector *reconciler.BucketElector
+
+	// enqueue and enqueueAfter are the ones of the controller.Impl the
+	// reconciler was created with.
+	enqueue      func(obj interface{})
+	enqueueAfter func(obj interface{}, after time.Duration)
+}
+
+// Check that our ExpirationReconciler implements controller.Reconciler
+var _ controller.Reconciler = (*ExpirationReconciler)(nil)
+
+// NewExpirationController returns a constructor for the PipelineRun
+// expiration controller, which only cleans up the PipelineRuns in scope,
+// with the dependencies replaced by opts.
+func NewExpirationController(images pipeline.Images, scope taskrun.ExpirationScope, opts ...reconciler.ControllerOption) func(context.Context, configmap.Watcher) *controller.Impl {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		logger := logging.FromContext(ctx)
+		o := reconciler.NewControllerOptions(ctx, opts...)
+		pipelineRunInformer := pipelineruninformer.Get(ctx)
+		cleanupPolicyInformer := cleanuppolicyinformer.Get(ctx)
+
+		opt := reconciler.Options{
+			KubeClientSet:     o.KubeClientSet,
+			PipelineClientSet: o.PipelineClientSet,
+			ConfigMapWatcher:  cmw,
+			ResyncPeriod:      resyncPeriod,
+			Logger:            logger,
+		}
+
+		c := &ExpirationReconciler{
+			Base:                reconciler.NewBase(opt, expirationAgentName, images),
+			pipelineRunLister:   pipelineRunInformer.Lister(),
+			cleanupPolicyLister: cleanupPolicyInformer.Lister(),
+			namespaceLister:     namespaceinformer.Get(ctx).Lister(),
+			scope:               scope,
+			clock:               o.Clock,
+			filter:              o.FilterFunc(),
+			throttle:            taskrun.NewDeletionThrottle("PipelineRun", logger),
+			skewCheck:           taskrun.NewClockSkewCheck("PipelineRun", logger),
+			metrics:             taskrun.NewCleanupMetrics("PipelineRun", logger),
+			histories:           taskrun.NewPrunedHistories(),
+			elector:             o.BucketElector,
+		}
+		impl := controller.NewImpl(c, c.Logger, expirationControllerName)
+		if err := reconciler.TrackQueueLatency(impl, expirationControllerName, "PipelineRun", o.Clock); err != nil {
+			logger.Errorf("Failed to track the queue latency of %s: %v", expirationControllerName, err)
+		}
+		c.enqueue = impl.Enqueue
+		c.enqueueAfter = impl.EnqueueAfter
+
+		c.configStore = o.ConfigStore(expirationControllerName)
+		if c.configStore == nil {
+			c.configStore = apisconfig.NewStore(c.Logger.Named("config-store"))
+		}
+		c.configStore.WatchConfigs(opt.ConfigMapWatcher)
+
+		c.Logger.Info("Setting up event handlers")
+		pipelineRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: o.FilterFunc(),
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc:    c.AddPipelineRun,
+				UpdateFunc: c.UpdatePipelineRun,
+			},
+		})
+		cleanupPolicyInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.AddCleanupPolicy,
+			UpdateFunc: controller.PassNew(c.AddCleanupPolicy),
+		})
+		if c.elector != nil {
+			c.elector.OnPromote(c.addBucket)
+			c.elector.Start(ctx, o.KubeClientSet)
+		}
+		go taskrun.RunSweeps(ctx.Done(), o.Clock, c.configStore, func() { c.SweepExpired() })
+
+		return impl
+	}
+}
+
+// SweepExpired enqueues the PipelineRuns in scope whose TTL elapsed, and
+// returns how many.
+func (c *ExpirationReconciler) SweepExpired() int {
+	prs, err := c.pipelineRunLister.List(labels.Everything())
+	if err != nil {
+		c.Logger.Errorf("Failed to list the PipelineRuns to sweep: %v", err)
+		return 0
+	}
+	var runs []taskrun.Expirable
+	for _, pr := range prs {
+		if c.filter(pr) && c.scope.Matches(pr) && c.elector.Has(pr.Namespace) {
+			runs = append(runs, withCleanupPolicy(pr, c.cleanupPolicy(pr)))
+		}
+	}
+	n := taskrun.EnqueueExpired(runs, c.clock.Now(), c.enqueue)
+	if n > 0 {
+		c.Logger.Infof("Sweep found %d PipelineRuns whose TTL elapsed", n)
+	}
+	return n
+}
+
+// AddCleanupPolicy enqueues the PipelineRuns of the namespace of a newly
+// seen or updated CleanupPolicy which need to be cleaned up.
+func (c *ExpirationReconciler) AddCleanupPolicy(obj interface{}) {
+	cp, ok := obj.(*v1alpha1.CleanupPolicy)
+	if !ok {
+		return
+	}
+	prs, err := c.pipelineRunLister.PipelineRuns(cp.Namespace).List(labels.Everything())
+	if err != nil {
+		c.Logger.Errorf("Failed to list the PipelineRuns of CleanupPolicy %s/%s: %v", cp.Namespace, cp.Name, err)
+		return
+	}
+	for _, pr := range prs {
+		if c.filter(pr) {
+			c.AddPipelineRun(pr)
+		}
+	}
+}
+
+// addBucket enqueues the PipelineRuns of the namespaces of a bucket this replica
+// starts leading which need to be cleaned up, since the replica which led it
+// before may have left them.
+func (c *ExpirationReconciler) addBucket(bucket int) {
+	prs, err := c.pipelineRunLister.List(labels.Everything())
+	if err != nil {
+		c.Logger.Errorf("Failed to list the PipelineRuns of bucket %d: %v", bucket, err)
+		return
+	}
+	for _, pr := range prs {
+		if c.filter(pr) && c.elector.Bucket(pr.Namespace) == bucket {
+			c.AddPipelineRun(pr)
+		}
+	}
+}
+
+// AddPipelineRun enqueues a newly seen PipelineRun if it needs to be cleaned up.
+func (c *ExpirationReconciler) AddPipelineRun(obj interface{}) {
+	pr, ok := obj.(*v1alpha1.PipelineRun)
+	if !ok || !c.needsCleanup(pr) {
+		return
+	}
+	c.Logger.Debugf("Adding PipelineRun %s/%s to the expiration queue", pr.Namespace, pr.Name)
+	c.enqueue(pr)
+}
+
+// UpdatePipelineRun enqueues an updated PipelineRun if it needs to be cleaned up.
+func (c *ExpirationReconciler) UpdatePipelineRun(old, cur interface{}) {
+	c.AddPipelineRun(cur)
+}
+
+// Reconcile deletes the PipelineRun identified by key if its TTL has
+// elapsed, or checks it again once it will have. It also deletes the
+// PipelineRuns beyond the history limit of its CleanupPolicy.
+func (c *ExpirationReconciler) Reconcile(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		c.Logger.Errorf("invalid resource key: %s", key)
+		return nil
+	}
+	if !c.elector.Has(namespace) {
+		// Another replica leads the bucket of the namespace.
+		return nil
+	}
+	return c.processPipelineRunExpired(c.configStore.ToContext(ctx), namespace, name)
+}
+
+// processPipelineRunExpired deletes the PipelineRun namespace/name if it is
+// expired. Its TaskRuns are owned by it, and deleted first by the garbage
+// collector.
+func (c *ExpirationReconciler) processPipelineRunExpired(ctx context.Context, namespace, name string) error {
+	pr, err := c.pipelineRunLister.PipelineRuns(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		c.metrics.SetPending(namespace+"/"+name, false)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if deleted, err := c.enforceHistoryLimit(ctx, pr); err != nil || deleted {
+		return err
+	}
+	if expiredAt, err := c.processPrTTL(ctx, pr); err != nil || expiredAt == nil {
+		return err
+	}
+	// The throttle is acquired before any call to the API server, which it
+	// protects.
+	release := c.acquireDeletion(ctx, pr)
+	if release == nil {
+		return nil
+	}
+	defer release()
+
+	// The PipelineRun in the lister may be stale, check again against the
+	// latest version before deleting it.
+	fresh, err := c.PipelineClientSet.TektonV1alpha1().PipelineRuns(namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	expiredAt, err := c.processPrTTL(ctx, fresh)
+	if err != nil || expiredAt == nil {
+		return err
+	}
+
+	return c.deletePipelineRun(ctx, fresh, taskrun.ReasonTTLExpired, "its TTL elapsed", *expiredAt)
+}
+
+// deletePipelineRun deletes pr, unless it has been replaced by another
+// PipelineRun of the same name. In dry run mode, it only logs and records an
+// event saying pr would be deleted, and why. pr is enqueued again later if
+// it finished less than the minimum retention ago. The deletion throttle must
+// be held by the caller. A HeldError is returned, for pr to be checked again with a
+// backoff, while blocking finalizers are set on it. reason and the time pr
+// expired at, zero if it is deleted
+// for another reason than its TTL, are recorded in the cleanup metrics.
+func (c *ExpirationReconciler) deletePipelineRun(ctx context.Context, pr *v1alpha1.PipelineRun, reason, why string, expiredAt time.Time) error {
+	cfg := apisconfig.FromContextOrDefaults(ctx).Cleanup
+	if cfg.ExcludesNamespace(pr.Namespace, c.namespaceLister.Get) {
+		c.Logger.Debugf("Not cleaning up PipelineRun %s/%s, its namespace is excluded from the cleanup", pr.Namespace, pr.Name)
+		return nil
+	}
+	finishedAt, err := taskrun.FinishTime(pr)
+	if err != nil {
+		return err
+	}
+	if left := taskrun.RetentionLeft(cfg, finishedAt, c.clock.Now()); left > 0 {
+		c.Logger.Debugf("Keeping PipelineRun %s/%s for the minimum retention, %s more", pr.Namespace, pr.Name, left)
+		c.enqueueAfter(pr, left)
+		return nil
+	}
+	if err := taskrun.CheckFinalizers(cfg, pr); err != nil {
+		c.Logger.Infof("Holding off the cleanup of PipelineRun %s/%s: %v", pr.Namespace, pr.Name, err)
+		return err
+	}
+	if c.scope.DryRun || cfg.DryRun {
+		c.Logger.Infof("Dry run: would clean up PipelineRun %s/%s, as %s", pr.Namespace, pr.Name, why)
+		c.Recorder.Eventf(pr, corev1.EventTypeNormal, taskrun.ReasonCleanupDryRun, "PipelineRun would be deleted, as %s", why)
+		return nil
+	}
+	c.Logger.Infof("Cleaning up PipelineRun %s/%s, as %s", pr.Namespace, pr.Name, why)
+	policy := metav1.DeletePropagationForeground
+	if err := c.PipelineClientSet.TektonV1alpha1().PipelineRuns(pr.Namespace).Delete(pr.Name, &metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+		Preconditions:     &metav1.Preconditions{UID: &pr.UID},
+	}); err != nil {
+		if !errors.IsNotFound(err) {
+			c.metrics.DeletionFailed()
+		}
+		return err
+	}
+	var lag time.Duration
+	if !expiredAt.IsZero() {
+		lag = c.clock.Since(expiredAt)
+	}
+	c.metrics.Cleaned(reason, lag)
+	c.Recorder.Eventf(taskrun.NamespaceRef(pr.Namespace), corev1.EventTypeNormal, reason, "PipelineRun %s deleted, as %s", pr.Name, why)
+	return nil
+}
+
+// acquireDeletion returns a func to call once pr is deleted if the deletion
+// throttle lets it be now. Otherwise, it enqueues pr again for when it may
+// be, and returns nil.
+func (c *ExpirationReconciler) acquireDeletion(ctx context.Context, pr *v1alpha1.PipelineRun) func() {
+	release, retryAfter := c.throttle.Acquire(pr.Namespace+"/"+pr.Name, apisconfig.FromContextOrDefaults(ctx).Cleanup, c.clock.Now())
+	if release == nil {
+		c.Logger.Debugf("Throttled the cleanup of PipelineRun %s/%s, retrying in %s", pr.Namespace, pr.Name, retryAfter)
+		c.enqueueAfter(pr, retryAfter)
+	}
+	return release
+}
+
+// throttledDeletePipelineRun deletes pr as deletePipelineRun does once the
+// deletion throttle lets it, enqueuing pr again for later otherwise.
+func (c *ExpirationReconciler) throttledDeletePipelineRun(ctx context.Context, pr *v1alpha1.PipelineRun, reason, why string, expiredAt time.Time) error {
+	release := c.acquireDeletion(ctx, pr)
+	if release == nil {
+		return nil
+	}
+	defer release()
+	return c.deletePipelineRun(ctx, pr, reason, why, expiredAt)
+}
+
+// enforceHistoryLimit deletes the PipelineRuns beyond the history limit of
+// the CleanupPolicy of pr, among the ones it applies to which finished with
+// the same outcome as pr. It returns whether pr itself was deleted. The
+// history is only listed again if pr wasn't in it the last time it was
+// pruned.
+func (c *ExpirationReconciler) enforceHistoryLimit(ctx context.Context, pr *v1alpha1.PipelineRun) (bool, error) {
+	policies, err := c.cleanupPolicyLister.CleanupPolicies(pr.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	cp := taskrun.CleanupPolicyFor(policies, pr)
+	if !c.limitedBy(pr, cp) {
+		return false, nil
+	}
+	succeeded := pr.Status.GetCondition(apis.ConditionSucceeded)
+	limit := cp.Spec.HistoryLimit(succeeded)
+	key := taskrun.HistoryKey(cp, succeeded)
+	why := fmt.Sprintf("it is beyond the history limit of CleanupPolicy %s", cp.Name)
+	if kept, over := c.histories.Lookup(key, *limit, pr); kept {
+		return false, nil
+	} else if over {
+		if err := c.throttledDeletePipelineRun(ctx, pr, taskrun.ReasonHistoryLimitExceeded, why, time.Time{}); err != nil && !errors.IsNotFound(err) && !taskrun.IsHeld(err) {
+			return false, err
+		}
+		return true, nil
+	}
+
+	prs, err := c.pipelineRunLister.PipelineRuns(pr.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	var runs []taskrun.FinishedRun
+	for _, other := range prs {
+		otherCp := taskrun.CleanupPolicyFor(policies, other)
+		if !c.limitedBy(other, otherCp) || other.Status.GetCondition(apis.ConditionSucceeded).Status != succeeded.Status {
+			continue
+		}
+		if otherCp.Name != cp.Name {
+			continue
+		}
+		finishedAt, err := taskrun.FinishTime(other)
+		if err != nil {
+			continue
+		}
+		runs = append(runs, taskrun.FinishedRun{Object: other, FinishedAt: finishedAt})
+	}
+	over := taskrun.OverHistoryLimit(runs, *limit)
+	c.histories.Set(key, *limit, runs, over)
+	var deleted bool
+	for _, run := range over {
+		if err := c.throttledDeletePipelineRun(ctx, run.Object.(*v1alpha1.PipelineRun), taskrun.ReasonHistoryLimitExceeded, why, time.Time{}); err != nil && !errors.IsNotFound(err) && !taskrun.IsHeld(err) {
+			return deleted, err
+		}
+		deleted = deleted || run.Object.GetName() == pr.Name
+	}
+	return deleted, nil
+}
+
+// historyLimited returns whether pr is in scope, and a finished PipelineRun
+// counted against the history limit of its CleanupPolicy.
+func (c *ExpirationReconciler) historyLimited(pr *v1alpha1.PipelineRun) bool {
+	return c.limitedBy(pr, c.cleanupPolicy(pr))
+}
+
+// limitedBy returns whether pr is in scope, and a finished PipelineRun
+// counted against the history limit of cp, its CleanupPolicy.
+func (c *ExpirationReconciler) limitedBy(pr *v1alpha1.PipelineRun, cp *v1alpha1.CleanupPolicy) bool {
+	if !c.scope.Matches(pr) || !pr.IsDone() || taskrun.IsKept(pr) {
+		return false
+	}
+	return cp != nil && cp.Spec.HistoryLimit(pr.Status.GetCondition(apis.ConditionSucceeded)) != nil
+}
+
+// cleanupPolicy returns the CleanupPolicy which applies to pr, or nil if
+// none does.
+func (c *ExpirationReconciler) cleanupPolicy(pr *v1alpha1.PipelineRun) *v1alpha1.CleanupPolicy {
+	policies, err := c.cleanupPolicyLister.CleanupPolicies(pr.Namespace).List(labels.Everything())
+	if err != nil {
+		c.Logger.Errorf("Failed to list the CleanupPolicies of namespace %s: %v", pr.Namespace, err)
+		return nil
+	}
+	return taskrun.CleanupPolicyFor(policies, pr)
+}
+
+// withCleanupPolicy returns pr with the TTL of cp for its outcome, if cp
+// applies to it and pr has no TTL of its own.
+func withCleanupPolicy(pr *v1alpha1.PipelineRun, cp *v1alpha1.CleanupPolicy) *v1alpha1.PipelineRun {
+	if cp == nil || pr.Spec.ExpirationSecondsTTL != nil {
+		return pr
+	}
+	pr = pr.DeepCopy()
+	pr.Spec.ExpirationSecondsTTL = cp.Spec.TTL(pr.Status.GetCondition(apis.ConditionSucceeded))
+	return pr
+}
+
+// processPrTTL returns the time at which pr expired, or nil if it hasn't
+// expired yet, in which case it is enqueued again for when it will. The TTL
+// of its CleanupPolicy applies if it has none of its own. It returns an
+// error, for pr to be checked again with a backoff, if pr finished further
+// in the future than the allowed clock skew.
+func (c *ExpirationReconciler) processPrTTL(ctx context.Context, pr *v1alpha1.PipelineRun) (*time.Time, error) {
+	key := pr.Namespace + "/" + pr.Name
+	if !c.scope.Matches(pr) {
+		c.metrics.SetPending(key, false)
+		return nil, nil
+	}
+	if apisconfig.FromContextOrDefaults(ctx).Cleanup.ExcludesNamespace(pr.Namespace, c.namespaceLister.Get) {
+		c.metrics.SetPending(key, false)
+		return nil, nil
+	}
+	pr = withCleanupPolicy(pr, c.cleanupPolicy(pr))
+	if !taskrun.Expires(pr) {
+		c.metrics.SetPending(key, false)
+		return nil, nil
+	}
+	now := c.clock.Now()
+	if err := c.skewCheck.Check(apisconfig.FromContextOrDefaults(ctx).Cleanup, pr, now); err != nil {
+		return nil, err
+	}
+	remaining, err := taskrun.TimeLeft(pr, &now)
+	if err != nil {
+		return nil, err
+	}
+	if *remaining <= 0 {
+		c.metrics.SetPending(key, false)
+		expiredAt := now.Add(*remaining)
+		return &expiredAt, nil
+	}
+	c.metrics.SetPending(key, true)
+	c.enqueueAfter(pr, *remaining)
+	return nil, nil
+}
+
+// needsCleanup returns whether pr is in scope and needs to be cleaned up,
+// once its TTL elapses or beyond the history limit of its CleanupPolicy.
+func (c *ExpirationReconciler) needsCleanup(pr *v1alpha1.PipelineRun) bool {
+	if !c.scope.Matches(pr) {
+		return false
+	}
+	return taskrun.Expires(withCleanupPolicy(pr, c.cleanupPolicy(pr))) || c.historyLimited(pr)
+}
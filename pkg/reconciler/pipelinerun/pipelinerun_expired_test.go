@@ -0,0 +1,223 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
+	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	"github.com/tektoncd/pipeline/pkg/system"
+	"github.com/tektoncd/pipeline/test"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/configmap"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+// testNow is the time the fake clocks of the expiration tests start at.
+var testNow = time.Date(2019, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+func finishedPipelineRun(finishedAgo time.Duration, ops ...tb.PipelineRunOp) *v1alpha1.PipelineRun {
+	ops = append([]tb.PipelineRunOp{
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunExpirationSecondsTTL(time.Hour)),
+		tb.PipelineRunStatus(
+			tb.PipelineRunStatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse}),
+			tb.PipelineRunCompletionTime(testNow.Add(-finishedAgo)),
+		),
+	}, ops...)
+	return tb.PipelineRun("test-pipeline-run", "foo", ops...)
+}
+
+func TestReconcileExpiredPipelineRun(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		pr          *v1alpha1.PipelineRun
+		policies    []*v1alpha1.CleanupPolicy
+		wantDeleted bool
+		// wantEnqueue is the delay after which the PipelineRun is expected to
+		// be enqueued again.
+		wantEnqueue time.Duration
+	}{{
+		name:        "expired",
+		pr:          finishedPipelineRun(2 * time.Hour),
+		wantDeleted: true,
+	}, {
+		name:        "not expired yet",
+		pr:          finishedPipelineRun(time.Minute),
+		wantEnqueue: 59 * time.Minute,
+	}, {
+		name: "expired but kept",
+		pr:   finishedPipelineRun(2*time.Hour, tb.PipelineRunAnnotation(taskrun.KeepAnnotationKey, "true")),
+	}, {
+		name: "expired but kept by label",
+		pr:   finishedPipelineRun(2*time.Hour, tb.PipelineRunLabel(taskrun.KeepAnnotationKey, "true")),
+	}, {
+		name: "expired by the cleanup policy",
+		pr:   finishedPipelineRun(2*time.Hour, func(pr *v1alpha1.PipelineRun) { pr.Spec.ExpirationSecondsTTL = nil }),
+		policies: []*v1alpha1.CleanupPolicy{tb.CleanupPolicy("everything", "foo",
+			tb.CleanupPolicyExpirationSecondsTTL(24*time.Hour),
+			tb.CleanupPolicyTTLSecondsAfterFailed(time.Hour),
+		)},
+		wantDeleted: true,
+	}, {
+		name: "history limit of the cleanup policy",
+		pr:   finishedPipelineRun(time.Minute),
+		policies: []*v1alpha1.CleanupPolicy{tb.CleanupPolicy("everything", "foo",
+			tb.CleanupPolicyHistoryLimits(1, 0),
+		)},
+		wantDeleted: true,
+	}, {
+		name: "running",
+		pr: tb.PipelineRun("test-pipeline-run", "foo",
+			tb.PipelineRunSpec("test-pipeline", tb.PipelineRunExpirationSecondsTTL(0)),
+			tb.PipelineRunStatus(tb.PipelineRunStatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionUnknown})),
+		),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			c, _ := test.SeedTestData(t, ctx, test.Data{PipelineRuns: []*v1alpha1.PipelineRun{tc.pr}, CleanupPolicies: tc.policies})
+			q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+			impl := NewExpirationController(images, taskrun.ExpirationScope{}, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+			r := impl.Reconciler.(*ExpirationReconciler)
+			r.enqueueAfter = q.EnqueueAfter
+			recorder := ttesting.NewObjectRecorder(10)
+			r.Recorder = recorder
+
+			if err := r.Reconcile(ctx, "foo/test-pipeline-run"); err != nil {
+				t.Fatalf("Unexpected error reconciling pipelinerun: %v", err)
+			}
+
+			var deleted bool
+			for _, a := range c.Pipeline.Actions() {
+				if a.GetVerb() == "delete" {
+					deleted = true
+				}
+			}
+			if deleted != tc.wantDeleted {
+				t.Errorf("Expected the PipelineRun to be deleted: %t, got %t", tc.wantDeleted, deleted)
+			}
+			if tc.wantDeleted {
+				// The event outlives the PipelineRun on its namespace.
+				if d := cmp.Diff([]runtime.Object{taskrun.NamespaceRef("foo")}, recorder.Objects); d != "" {
+					t.Errorf("event objects diff -want, +got: %v", d)
+				}
+				if e := <-recorder.Events; !strings.Contains(e, "PipelineRun test-pipeline-run deleted") {
+					t.Errorf("Expected the event to name the deleted PipelineRun, got %q", e)
+				}
+			}
+			var enqueued time.Duration
+			if at, ok := q.NextAt(); ok {
+				enqueued = at.Sub(testNow)
+			}
+			if enqueued != tc.wantEnqueue {
+				t.Errorf("Expected the PipelineRun to be enqueued again after %s, got %s", tc.wantEnqueue, enqueued)
+			}
+		})
+	}
+}
+
+func TestReconcilePipelineRunDryRun(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, _ := test.SeedTestData(t, ctx, test.Data{PipelineRuns: []*v1alpha1.PipelineRun{finishedPipelineRun(2 * time.Hour)}})
+	store := config.NewStore(logtesting.TestLogger(t))
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.CleanupConfigName, Namespace: system.GetNamespace()},
+		Data:       map[string]string{"dry-run": "true"},
+	})
+	opts := []reconciler.ControllerOption{reconciler.WithClock(clock.NewFakeClock(testNow)), reconciler.WithConfigStore(store)}
+	impl := NewExpirationController(images, taskrun.ExpirationScope{}, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	recorder := record.NewFakeRecorder(10)
+	r.Recorder = recorder
+
+	if err := r.Reconcile(ctx, "foo/test-pipeline-run"); err != nil {
+		t.Fatalf("Unexpected error reconciling pipelinerun: %v", err)
+	}
+	for _, a := range c.Pipeline.Actions() {
+		if a.GetVerb() == "delete" {
+			t.Errorf("Expected the PipelineRun not to be deleted in dry run mode, got action %v", a)
+		}
+	}
+	select {
+	case e := <-recorder.Events:
+		if !strings.Contains(e, taskrun.ReasonCleanupDryRun) {
+			t.Errorf("Expected a %s event, got %q", taskrun.ReasonCleanupDryRun, e)
+		}
+	default:
+		t.Errorf("Expected a %s event", taskrun.ReasonCleanupDryRun)
+	}
+}
+
+func TestPipelineRunTimeLeft(t *testing.T) {
+	now := testNow
+	pr := finishedPipelineRun(0)
+	pr.Status.CompletionTime = nil
+	pr.Status.Conditions[0].LastTransitionTime = apis.VolatileTime{Inner: metav1.NewTime(now.Add(-20 * time.Minute))}
+	got, err := taskrun.TimeLeft(pr, &now)
+	if err != nil {
+		t.Fatalf("TimeLeft: %v", err)
+	}
+	if *got != 40*time.Minute {
+		t.Errorf("Expected 40m left, got %s", got)
+	}
+}
+
+func TestSweepExpiredPipelineRuns(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	named := func(name string) tb.PipelineRunOp {
+		return func(pr *v1alpha1.PipelineRun) {
+			pr.Name = name
+		}
+	}
+	c, _ := test.SeedTestData(t, ctx, test.Data{
+		PipelineRuns: []*v1alpha1.PipelineRun{
+			finishedPipelineRun(2*time.Hour, named("expired")),
+			finishedPipelineRun(30*time.Minute, named("not-expired")),
+			tb.PipelineRun("running", "foo", tb.PipelineRunSpec("test-pipeline", tb.PipelineRunExpirationSecondsTTL(time.Minute))),
+		},
+	})
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+	impl := NewExpirationController(images, taskrun.ExpirationScope{}, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	var enqueued []string
+	r.enqueue = func(obj interface{}) {
+		enqueued = append(enqueued, obj.(*v1alpha1.PipelineRun).Name)
+	}
+
+	if n := r.SweepExpired(); n != 1 || len(enqueued) != 1 || enqueued[0] != "expired" {
+		t.Errorf("Expected the sweep to only enqueue the expired PipelineRun, got %d: %v", n, enqueued)
+	}
+}
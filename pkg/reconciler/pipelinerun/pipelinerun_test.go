@@ -27,6 +27,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
 	taskrunresources "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
@@ -36,6 +37,7 @@ import (
 	"github.com/tektoncd/pipeline/test/names"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	ktesting "k8s.io/client-go/testing"
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
@@ -83,6 +85,9 @@ func conditionCheckFromTaskRun(tr *v1alpha1.TaskRun) *v1alpha1.ConditionCheck {
 
 func TestReconcile(t *testing.T) {
 	names.TestingSeed()
+	// The checksum of the Task isn't verified by the PipelineRun, but passed
+	// on to its TaskRuns.
+	unitTestTaskChecksum := "sha256:" + strings.Repeat("a", 64)
 
 	prs := []*v1alpha1.PipelineRun{
 		tb.PipelineRun("test-pipeline-run-success", "foo",
@@ -122,6 +127,7 @@ func TestReconcile(t *testing.T) {
 				),
 				// unit-test-1 can run right away because it has no dependencies
 				tb.PipelineTask("unit-test-1", "unit-test-task",
+					tb.PipelineTaskRefChecksum(unitTestTaskChecksum),
 					funParam, moreFunParam, templatedParam,
 					tb.PipelineTaskInputResource("workspace", "git-repo"),
 					tb.PipelineTaskOutputResource("image-to-use", "best-image"),
@@ -224,7 +230,7 @@ func TestReconcile(t *testing.T) {
 		tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-success"),
 		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "unit-test-1"),
 		tb.TaskRunSpec(
-			tb.TaskRunTaskRef("unit-test-task"),
+			tb.TaskRunTaskRef("unit-test-task", tb.TaskRefChecksum(unitTestTaskChecksum)),
 			tb.TaskRunServiceAccountName("test-sa"),
 			tb.TaskRunInputs(
 				tb.TaskRunInputsParam("foo", "somethingfun"),
@@ -305,6 +311,9 @@ func TestReconcile_InvalidPipelineRuns(t *testing.T) {
 			tb.PipelineParamSpec("some-param", v1alpha1.ParamTypeArray),
 			tb.PipelineTask("some-task", "a-task-that-needs-array-params"))),
 		tb.Pipeline("a-pipeline-with-missing-conditions", "foo", tb.PipelineSpec(tb.PipelineTask("some-task", "a-task-that-exists", tb.PipelineTaskCondition("condition-does-not-exist")))),
+		tb.Pipeline("a-pipeline-with-enum-params", "foo", tb.PipelineSpec(
+			tb.PipelineParamSpec("some-param", v1alpha1.ParamTypeString, tb.ParamSpecEnum("dev", "prod")),
+			tb.PipelineTask("some-task", "a-task-that-exists"))),
 	}
 	prs := []*v1alpha1.PipelineRun{
 		tb.PipelineRun("invalid-pipeline", "foo", tb.PipelineRunSpec("pipeline-not-exist")),
@@ -316,6 +325,11 @@ func TestReconcile_InvalidPipelineRuns(t *testing.T) {
 		tb.PipelineRun("pipeline-resources-not-declared", "foo", tb.PipelineRunSpec("a-pipeline-that-should-be-caught-by-admission-control")),
 		tb.PipelineRun("pipeline-mismatching-param-type", "foo", tb.PipelineRunSpec("a-pipeline-with-array-params", tb.PipelineRunParam("some-param", "stringval"))),
 		tb.PipelineRun("pipeline-conditions-missing", "foo", tb.PipelineRunSpec("a-pipeline-with-missing-conditions")),
+		tb.PipelineRun("pipeline-param-reference-cycle", "foo", tb.PipelineRunSpec("a-pipeline-without-params",
+			tb.PipelineRunParam("some-param", "$(params.other-param)"),
+			tb.PipelineRunParam("other-param", "$(params.some-param)"))),
+		tb.PipelineRun("pipeline-param-not-in-enum", "foo", tb.PipelineRunSpec("a-pipeline-with-enum-params",
+			tb.PipelineRunParam("some-param", "staging"))),
 	}
 	d := test.Data{
 		Tasks:        ts,
@@ -361,6 +375,14 @@ func TestReconcile_InvalidPipelineRuns(t *testing.T) {
 			name:        "invalid-pipeline-missing-conditions-shd-stop-reconciling",
 			pipelineRun: prs[7],
 			reason:      ReasonCouldntGetCondition,
+		}, {
+			name:        "invalid-pipeline-param-reference-cycle-shd-stop-reconciling",
+			pipelineRun: prs[8],
+			reason:      ReasonInvalidParamReference,
+		}, {
+			name:        "invalid-pipeline-param-not-in-enum-shd-stop-reconciling",
+			pipelineRun: prs[9],
+			reason:      ReasonParameterConstraintViolated,
 		},
 	}
 
@@ -1279,6 +1301,88 @@ func TestReconcileWithTimeoutAndRetry(t *testing.T) {
 	}
 }
 
+func TestReconcileRetryWithPodTemplates(t *testing.T) {
+	spot := v1alpha1.PodTemplate{NodeSelector: map[string]string{"lifecycle": "spot"}}
+	onDemand := v1alpha1.PodTemplate{NodeSelector: map[string]string{"lifecycle": "on-demand"}}
+	ps := []*v1alpha1.Pipeline{tb.Pipeline("test-pipeline-retry", "foo", tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world", tb.Retries(3), tb.RetryPodTemplates(spot, onDemand)),
+	))}
+	prs := []*v1alpha1.PipelineRun{tb.PipelineRun("test-pipeline-retry-run", "foo",
+		tb.PipelineRunSpec("test-pipeline-retry",
+			tb.PipelineRunServiceAccountName("test-sa"),
+			tb.PipelineRunNodeSelector(spot.NodeSelector),
+		),
+		tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now())),
+	)}
+	ts := []*v1alpha1.Task{tb.Task("hello-world", "foo")}
+	// The TaskRun failed on the first retry, which ran on spot nodes too.
+	trs := []*v1alpha1.TaskRun{
+		tb.TaskRun("hello-world-1", "foo",
+			tb.TaskRunSpec(
+				tb.TaskRunTaskRef("hello-world"),
+				tb.TaskRunNodeSelector(spot.NodeSelector),
+			),
+			tb.TaskRunStatus(
+				tb.PodName("my-pod-name"),
+				tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionFalse,
+				}),
+				tb.Retry(v1alpha1.TaskRunStatus{
+					Status: duckv1beta1.Status{
+						Conditions: []apis.Condition{{
+							Type:   apis.ConditionSucceeded,
+							Status: corev1.ConditionFalse,
+						}},
+					},
+					PodTemplate: spot.DeepCopy(),
+				}),
+			)),
+	}
+	prs[0].Status.TaskRuns = map[string]*v1alpha1.PipelineRunTaskRunStatus{
+		"hello-world-1": {
+			PipelineTaskName: "hello-world-1",
+			Status:           &trs[0].Status,
+		},
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     trs,
+	}
+
+	testAssets, cancel := getPipelineRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if err := c.Reconciler.Reconcile(context.Background(), "foo/test-pipeline-retry-run"); err != nil {
+		t.Fatalf("Did not expect to see error when reconciling PipelineRun but saw %s", err)
+	}
+
+	tr, err := clients.Pipeline.TektonV1alpha1().TaskRuns("foo").Get("hello-world-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting the retried TaskRun: %s", err)
+	}
+	// The second retry runs with the second pod template.
+	if d := cmp.Diff(onDemand, tr.Spec.PodTemplate); d != "" {
+		t.Errorf("Pod template of the retry (-want, +got): %s", d)
+	}
+	if len(tr.Status.RetriesStatus) != 2 {
+		t.Fatalf("Expected 2 retries in the status, got %d", len(tr.Status.RetriesStatus))
+	}
+	for i, s := range tr.Status.RetriesStatus {
+		if d := cmp.Diff(&spot, s.PodTemplate); d != "" {
+			t.Errorf("Pod template of the attempt %d (-want, +got): %s", i, d)
+		}
+	}
+	if status := tr.Status.GetCondition(apis.ConditionSucceeded).Status; status != corev1.ConditionUnknown {
+		t.Errorf("Expected the retry to be running, but its condition is %s", status)
+	}
+}
+
 func TestReconcilePropagateAnnotations(t *testing.T) {
 	names.TestingSeed()
 
@@ -1340,6 +1444,327 @@ func TestReconcilePropagateAnnotations(t *testing.T) {
 	}
 }
 
+func TestReconcilePropagateTaskRunTTL(t *testing.T) {
+	names.TestingSeed()
+
+	ps := []*v1alpha1.Pipeline{tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	prs := []*v1alpha1.PipelineRun{tb.PipelineRun("test-pipeline-run-with-taskrun-ttl", "foo",
+		tb.PipelineRunSpec("test-pipeline",
+			tb.PipelineRunServiceAccountName("test-sa"),
+			tb.PipelineRunTaskRunTTL(24*time.Hour),
+		),
+	)}
+	ts := []*v1alpha1.Task{tb.Task("hello-world", "foo")}
+
+	testAssets, cancel := getPipelineRunController(t, test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	})
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if err := c.Reconciler.Reconcile(context.Background(), "foo/test-pipeline-run-with-taskrun-ttl"); err != nil {
+		t.Fatalf("Error reconciling PipelineRun: %s", err)
+	}
+
+	actual := clients.Pipeline.Actions()[0].(ktesting.CreateAction).GetObject().(*v1alpha1.TaskRun)
+	expectedTaskRun := tb.TaskRun("test-pipeline-run-with-taskrun-ttl-hello-world-1-9l9zj", "foo",
+		tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-with-taskrun-ttl",
+			tb.OwnerReferenceAPIVersion("tekton.dev/v1alpha1"),
+			tb.Controller, tb.BlockOwnerDeletion,
+		),
+		tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
+		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
+		tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-with-taskrun-ttl"),
+		tb.TaskRunSpec(
+			tb.TaskRunTaskRef("hello-world"),
+			tb.TaskRunServiceAccountName("test-sa"),
+			tb.TaskRunExpirationSecondsTTL(24*time.Hour),
+		),
+	)
+	if d := cmp.Diff(expectedTaskRun, actual); d != "" {
+		t.Errorf("expected to see TaskRun %v created. Diff %s", expectedTaskRun, d)
+	}
+}
+
+func TestReconcilePinsPipelineSpec(t *testing.T) {
+	names.TestingSeed()
+
+	ps := []*v1alpha1.Pipeline{tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	prs := []*v1alpha1.PipelineRun{tb.PipelineRun("test-pipeline-run-pinned", "foo",
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+	)}
+	ts := []*v1alpha1.Task{tb.Task("hello-world", "foo"), tb.Task("goodbye-world", "foo")}
+
+	testAssets, cancel := getPipelineRunController(t, test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	})
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if err := c.Reconciler.Reconcile(context.Background(), "foo/test-pipeline-run-pinned"); err != nil {
+		t.Fatalf("Error reconciling PipelineRun: %s", err)
+	}
+	reconciledRun, err := clients.Pipeline.TektonV1alpha1().PipelineRuns("foo").Get("test-pipeline-run-pinned", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting the reconciled PipelineRun: %s", err)
+	}
+	if d := cmp.Diff(&ps[0].Spec, reconciledRun.Status.PipelineSpec); d != "" {
+		t.Errorf("Expected the spec of the Pipeline to be pinned in the status of the PipelineRun. Diff %s", d)
+	}
+}
+
+func TestReconcileLinksRerun(t *testing.T) {
+	names.TestingSeed()
+
+	ps := []*v1alpha1.Pipeline{tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	prs := []*v1alpha1.PipelineRun{tb.PipelineRun("test-pipeline-run-rerun", "foo",
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+		tb.PipelineRunAnnotation(v1alpha1.RerunOfAnnotationKey, "test-pipeline-run-prior"),
+	)}
+	ts := []*v1alpha1.Task{tb.Task("hello-world", "foo")}
+
+	testAssets, cancel := getPipelineRunController(t, test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	})
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if err := c.Reconciler.Reconcile(context.Background(), "foo/test-pipeline-run-rerun"); err != nil {
+		t.Fatalf("Error reconciling PipelineRun: %s", err)
+	}
+	reconciledRun, err := clients.Pipeline.TektonV1alpha1().PipelineRuns("foo").Get("test-pipeline-run-rerun", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting the reconciled PipelineRun: %s", err)
+	}
+	if reconciledRun.Status.RerunOf != "test-pipeline-run-prior" {
+		t.Errorf("Expected the PipelineRun to be linked to the run it re-runs, got %q", reconciledRun.Status.RerunOf)
+	}
+}
+
+func TestReconcileRunsPinnedPipelineSpec(t *testing.T) {
+	names.TestingSeed()
+
+	// The Pipeline was edited since the PipelineRun started with its first
+	// task: the PipelineRun keeps running the spec it started with.
+	ps := []*v1alpha1.Pipeline{tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "goodbye-world"),
+		tb.PipelineTask("hello-world-3", "goodbye-world"),
+	))}
+	prs := []*v1alpha1.PipelineRun{tb.PipelineRun("test-pipeline-run-pinned", "foo",
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+		tb.PipelineRunStatus(tb.PipelineRunStatusPipelineSpec(
+			tb.PipelineTask("hello-world-1", "hello-world"),
+			tb.PipelineTask("hello-world-2", "hello-world", tb.RunAfter("hello-world-1")),
+		)),
+	)}
+	ts := []*v1alpha1.Task{tb.Task("hello-world", "foo"), tb.Task("goodbye-world", "foo")}
+	trs := []*v1alpha1.TaskRun{tb.TaskRun("test-pipeline-run-pinned-hello-world-1", "foo",
+		tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-pinned",
+			tb.OwnerReferenceAPIVersion("tekton.dev/v1alpha1"),
+			tb.Controller, tb.BlockOwnerDeletion,
+		),
+		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+		tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionTrue,
+		})),
+	)}
+	prs[0].Status.TaskRuns = map[string]*v1alpha1.PipelineRunTaskRunStatus{
+		trs[0].Name: {PipelineTaskName: "hello-world-1", Status: &trs[0].Status},
+	}
+
+	testAssets, cancel := getPipelineRunController(t, test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     trs,
+	})
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if err := c.Reconciler.Reconcile(context.Background(), "foo/test-pipeline-run-pinned"); err != nil {
+		t.Fatalf("Error reconciling PipelineRun: %s", err)
+	}
+	var created []string
+	for _, a := range clients.Pipeline.Actions() {
+		if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+			tr := a.(ktesting.CreateAction).GetObject().(*v1alpha1.TaskRun)
+			created = append(created, tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey]+"/"+tr.Spec.TaskRef.Name)
+		}
+	}
+	if d := cmp.Diff([]string{"hello-world-2/hello-world"}, created); d != "" {
+		t.Errorf("Expected only the TaskRuns of the pinned spec to be created. Diff %s", d)
+	}
+	reconciledRun, err := clients.Pipeline.TektonV1alpha1().PipelineRuns("foo").Get("test-pipeline-run-pinned", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting the reconciled PipelineRun: %s", err)
+	}
+	if d := cmp.Diff(prs[0].Status.PipelineSpec, reconciledRun.Status.PipelineSpec); d != "" {
+		t.Errorf("Expected the pinned spec to be kept. Diff %s", d)
+	}
+}
+
+func TestReconcileWithParamsFrom(t *testing.T) {
+	ps := []*v1alpha1.Pipeline{tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(
+		tb.PipelineParamSpec("version", v1alpha1.ParamTypeString),
+		tb.PipelineTask("hello-world-1", "hello-world", tb.PipelineTaskParam("image", "gcr.io/hello:$(params.version)")),
+	))}
+	ts := []*v1alpha1.Task{tb.Task("hello-world", "foo", tb.TaskSpec(
+		tb.TaskInputs(tb.InputsParamSpec("image", v1alpha1.ParamTypeString)),
+	))}
+	for _, tc := range []struct {
+		name       string
+		configMap  string
+		wantReason string
+	}{{
+		name:      "existing configmap",
+		configMap: "release",
+	}, {
+		name:       "missing configmap",
+		configMap:  "missing",
+		wantReason: ReasonCouldntGetParamsFrom,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			prs := []*v1alpha1.PipelineRun{tb.PipelineRun("test-pipeline-run-params-from", "foo", tb.PipelineRunSpec("test-pipeline",
+				tb.PipelineRunParamsFrom(v1alpha1.ParamsFromSource{
+					ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: tc.configMap}},
+				}),
+			))}
+			cms := []*corev1.ConfigMap{{
+				ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "foo"},
+				Data:       map[string]string{"version": "v0.9.0"},
+			}}
+			testAssets, cancel := getPipelineRunController(t, test.Data{PipelineRuns: prs, Pipelines: ps, Tasks: ts, ConfigMaps: cms})
+			defer cancel()
+			c := testAssets.Controller
+			clients := testAssets.Clients
+
+			if err := c.Reconciler.Reconcile(context.Background(), "foo/test-pipeline-run-params-from"); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().PipelineRuns("foo").Get("test-pipeline-run-params-from", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Somehow had error getting reconciled run out of fake client: %s", err)
+			}
+			if len(reconciledRun.Spec.Params) != 0 {
+				t.Errorf("Expected the params read from the ConfigMap not to be written to the PipelineRun, got %v", reconciledRun.Spec.Params)
+			}
+			if tc.wantReason != "" {
+				if condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded); condition == nil || condition.Reason != tc.wantReason {
+					t.Errorf("Expected PipelineRun to fail with reason %s, got %v", tc.wantReason, condition)
+				}
+				return
+			}
+
+			var actual *v1alpha1.TaskRun
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" {
+					actual, _ = a.(ktesting.CreateAction).GetObject().(*v1alpha1.TaskRun)
+				}
+			}
+			if actual == nil {
+				t.Fatalf("Expected a TaskRun to be created, got actions %v", clients.Pipeline.Actions())
+			}
+			want := []v1alpha1.Param{{Name: "image", Value: *tb.ArrayOrString("gcr.io/hello:v0.9.0")}}
+			if d := cmp.Diff(want, actual.Spec.Inputs.Params); d != "" {
+				t.Errorf("TaskRun params diff -want, +got: %s", d)
+			}
+		})
+	}
+}
+
+func TestReconcileWithParamsFromSecret(t *testing.T) {
+	ts := []*v1alpha1.Task{tb.Task("hello-world", "foo", tb.TaskSpec(
+		tb.TaskInputs(tb.InputsParamSpec("token", v1alpha1.ParamTypeString)),
+	))}
+	secrets := []*corev1.Secret{{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "foo"},
+		Data:       map[string][]byte{"token": []byte("hunter2")},
+	}}
+	for _, tc := range []struct {
+		name       string
+		value      string
+		wantReason string
+	}{{
+		name:  "passed whole",
+		value: "$(params.token)",
+	}, {
+		name:       "substituted",
+		value:      "Bearer $(params.token)",
+		wantReason: ReasonInvalidParamReference,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := []*v1alpha1.Pipeline{tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(
+				tb.PipelineParamSpec("token", v1alpha1.ParamTypeString),
+				tb.PipelineTask("hello-world-1", "hello-world", tb.PipelineTaskParam("token", tc.value)),
+			))}
+			prs := []*v1alpha1.PipelineRun{tb.PipelineRun("test-pipeline-run-params-from", "foo", tb.PipelineRunSpec("test-pipeline",
+				tb.PipelineRunParamsFrom(v1alpha1.ParamsFromSource{
+					SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "registry"}},
+				}),
+			))}
+			testAssets, cancel := getPipelineRunController(t, test.Data{PipelineRuns: prs, Pipelines: ps, Tasks: ts, Secrets: secrets})
+			defer cancel()
+			c := testAssets.Controller
+			clients := testAssets.Clients
+
+			if err := c.Reconciler.Reconcile(context.Background(), "foo/test-pipeline-run-params-from"); err != nil {
+				t.Fatalf("Error reconciling: %s", err)
+			}
+
+			reconciledRun, err := clients.Pipeline.TektonV1alpha1().PipelineRuns("foo").Get("test-pipeline-run-params-from", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Somehow had error getting reconciled run out of fake client: %s", err)
+			}
+			if tc.wantReason != "" {
+				if condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded); condition == nil || condition.Reason != tc.wantReason {
+					t.Errorf("Expected PipelineRun to fail with reason %s, got %v", tc.wantReason, condition)
+				}
+				return
+			}
+
+			var actual *v1alpha1.TaskRun
+			for _, a := range clients.Pipeline.Actions() {
+				if a.GetVerb() == "create" {
+					actual, _ = a.(ktesting.CreateAction).GetObject().(*v1alpha1.TaskRun)
+				}
+			}
+			if actual == nil {
+				t.Fatalf("Expected a TaskRun to be created, got actions %v", clients.Pipeline.Actions())
+			}
+			want := []v1alpha1.Param{{
+				Name:  "token",
+				Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString},
+				ValueFrom: &v1alpha1.ParamValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "registry"},
+					Key:                  "token",
+				}},
+			}}
+			if d := cmp.Diff(want, actual.Spec.Inputs.Params); d != "" {
+				t.Errorf("TaskRun params diff -want, +got: %s", d)
+			}
+		})
+	}
+}
+
 func TestGetTaskRunTimeout(t *testing.T) {
 	prName := "pipelinerun-timeouts"
 	ns := "foo"
@@ -1588,3 +2013,128 @@ func makeExpectedTr(condName, ccName string) *v1alpha1.TaskRun {
 		),
 	)
 }
+
+func TestReconcileExecutionWindow(t *testing.T) {
+	names.TestingSeed()
+	saturday := time.Date(2019, time.November, 16, 10, 30, 0, 0, time.UTC)
+	monday := time.Date(2019, time.November, 18, 9, 0, 0, 0, time.UTC)
+	pr := tb.PipelineRun("test-pipeline-run-window", "foo", tb.PipelineRunSpec("test-pipeline",
+		tb.PipelineRunExecutionWindow("UTC", "* 9-17 * * mon-fri"),
+	))
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, i := test.SeedTestData(t, ctx, test.Data{
+		PipelineRuns: []*v1alpha1.PipelineRun{pr},
+		Pipelines:    []*v1alpha1.Pipeline{tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(tb.PipelineTask("hello-world-1", "hello-world")))},
+		Tasks:        []*v1alpha1.Task{tb.Task("hello-world", "foo")},
+	})
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(saturday))
+	impl := NewController(images, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*Reconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	createdTaskRuns := func() int {
+		n := 0
+		for _, a := range c.Pipeline.Actions() {
+			if a.GetVerb() == "create" && a.GetResource().Resource == "taskruns" {
+				n++
+			}
+		}
+		return n
+	}
+
+	if err := r.Reconcile(context.Background(), getRunName(pr)); err != nil {
+		t.Fatalf("Error reconciling PipelineRun: %s", err)
+	}
+	got, err := c.Pipeline.TektonV1alpha1().PipelineRuns("foo").Get(pr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting the reconciled PipelineRun: %s", err)
+	}
+	if cond := got.Status.GetCondition(apis.ConditionSucceeded); cond == nil || !cond.IsUnknown() || cond.Reason != reconciler.ReasonWaitingForExecutionWindow {
+		t.Errorf("Expected the PipelineRun to wait for its execution window, got condition %v", cond)
+	}
+	if got.Status.StartTime != nil || createdTaskRuns() != 0 {
+		t.Errorf("Expected the PipelineRun not to start before its execution window opens, got start time %v and %d TaskRuns", got.Status.StartTime, createdTaskRuns())
+	}
+	if next, ok := q.NextAt(); !ok || !next.Equal(monday) {
+		t.Fatalf("Expected the PipelineRun to be enqueued again at %s, got %s", monday, next)
+	}
+
+	q.TravelToNext()
+	if err := i.PipelineRun.Informer().GetIndexer().Update(got); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reconcile(context.Background(), getRunName(pr)); err != nil {
+		t.Fatalf("Error reconciling PipelineRun: %s", err)
+	}
+	got, err = c.Pipeline.TektonV1alpha1().PipelineRuns("foo").Get(pr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting the reconciled PipelineRun: %s", err)
+	}
+	if got.Status.StartTime == nil || createdTaskRuns() != 1 {
+		t.Errorf("Expected the PipelineRun to start once its execution window opens, got start time %v and %d TaskRuns", got.Status.StartTime, createdTaskRuns())
+	}
+}
+
+func TestReconcileQuota(t *testing.T) {
+	names.TestingSeed()
+	now := time.Date(2019, time.November, 16, 10, 30, 0, 0, time.UTC)
+	earlier := tb.PipelineRun("test-pipeline-run-earlier", "foo",
+		tb.PipelineRunLabel("team", "a"),
+		tb.PipelineRunSpec("test-pipeline"),
+		tb.PipelineRunStatus(tb.PipelineRunStartTime(now.Add(-40*time.Minute)), tb.PipelineRunStatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionTrue,
+		})),
+	)
+	pr := tb.PipelineRun("test-pipeline-run-quota", "foo", tb.PipelineRunLabel("team", "a"), tb.PipelineRunSpec("test-pipeline"))
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, i := test.SeedTestData(t, ctx, test.Data{
+		PipelineRuns: []*v1alpha1.PipelineRun{earlier, pr},
+		Pipelines:    []*v1alpha1.Pipeline{tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(tb.PipelineTask("hello-world-1", "hello-world")))},
+		Tasks:        []*v1alpha1.Task{tb.Task("hello-world", "foo")},
+		PipelineQuotas: []*v1alpha1.PipelineQuota{tb.PipelineQuota("quota", "foo",
+			tb.PipelineQuotaSelector(map[string]string{"team": "a"}),
+			tb.PipelineQuotaMaxRunsPerHour(1),
+		)},
+	})
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(now))
+	impl := NewController(images, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*Reconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	if err := r.Reconcile(context.Background(), getRunName(pr)); err != nil {
+		t.Fatalf("Error reconciling PipelineRun: %s", err)
+	}
+	got, err := c.Pipeline.TektonV1alpha1().PipelineRuns("foo").Get(pr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting the reconciled PipelineRun: %s", err)
+	}
+	if cond := got.Status.GetCondition(apis.ConditionSucceeded); cond == nil || !cond.IsUnknown() || cond.Reason != reconciler.ReasonQuotaExceeded {
+		t.Errorf("Expected the PipelineRun to wait for its quota, got condition %v", cond)
+	}
+	if got.Status.StartTime != nil {
+		t.Errorf("Expected the PipelineRun not to start over its quota, got start time %v", got.Status.StartTime)
+	}
+	if next, ok := q.NextAt(); !ok || !next.Equal(now.Add(20*time.Minute)) {
+		t.Fatalf("Expected the PipelineRun to be enqueued again at %s, got %s", now.Add(20*time.Minute), next)
+	}
+
+	q.TravelToNext()
+	if err := i.PipelineRun.Informer().GetIndexer().Update(got); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reconcile(context.Background(), getRunName(pr)); err != nil {
+		t.Fatalf("Error reconciling PipelineRun: %s", err)
+	}
+	got, err = c.Pipeline.TektonV1alpha1().PipelineRuns("foo").Get(pr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error getting the reconciled PipelineRun: %s", err)
+	}
+	if got.Status.StartTime == nil {
+		t.Errorf("Expected the PipelineRun to start once the earlier run is more than an hour old, got condition %v", got.Status.GetCondition(apis.ConditionSucceeded))
+	}
+}
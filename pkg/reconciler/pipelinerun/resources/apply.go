@@ -34,23 +34,51 @@ func ApplyParameters(p *v1alpha1.PipelineSpec, pr *v1alpha1.PipelineRun) *v1alph
 	// Set all the default stringReplacements
 	for _, p := range p.Params {
 		if p.Default != nil {
-			if p.Default.Type == v1alpha1.ParamTypeString {
+			switch p.Default.Type {
+			case v1alpha1.ParamTypeString:
 				stringReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Default.StringVal
-			} else {
+			case v1alpha1.ParamTypeObject:
+				for key, value := range p.Default.ObjectVal {
+					stringReplacements[fmt.Sprintf("params.%s.%s", p.Name, key)] = value
+				}
+			default:
 				arrayReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Default.ArrayVal
 			}
 		}
 	}
 	// Set and overwrite params with the ones from the PipelineRun
+	secrets := map[string]*v1alpha1.ParamValueSource{}
 	for _, p := range pr.Spec.Params {
-		if p.Value.Type == v1alpha1.ParamTypeString {
+		if p.ValueFrom != nil {
+			secrets[p.Name] = p.ValueFrom
+			delete(stringReplacements, fmt.Sprintf("params.%s", p.Name))
+			continue
+		}
+		switch p.Value.Type {
+		case v1alpha1.ParamTypeString:
 			stringReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Value.StringVal
-		} else {
+		case v1alpha1.ParamTypeObject:
+			// Object params are only referenced by key, e.g. $(params.foo.key).
+			for key, value := range p.Value.ObjectVal {
+				stringReplacements[fmt.Sprintf("params.%s.%s", p.Name, key)] = value
+			}
+		default:
 			arrayReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Value.ArrayVal
 		}
 	}
 
-	return ApplyReplacements(p, stringReplacements, arrayReplacements)
+	p = ApplyReplacements(p, stringReplacements, arrayReplacements)
+	// The params read from Secrets are passed whole to the params of the
+	// Tasks, see ValidateSecretParams, whose TaskRuns read them too.
+	for i := range p.Tasks {
+		for j, param := range p.Tasks[i].Params {
+			if name, ok := wholeParamReference(param.Value); ok && secrets[name] != nil {
+				p.Tasks[i].Params[j].Value.StringVal = ""
+				p.Tasks[i].Params[j].ValueFrom = secrets[name].DeepCopy()
+			}
+		}
+	}
+	return p
 }
 
 // ApplyReplacements replaces placeholders for declared parameters with the specified replacements.
@@ -61,6 +89,11 @@ func ApplyReplacements(p *v1alpha1.PipelineSpec, replacements map[string]string,
 
 	for i := range tasks {
 		tasks[i].Params = replaceParamValues(tasks[i].Params, replacements, arrayReplacements)
+		if c := tasks[i].Checkout; c != nil {
+			c.Repo = v1alpha1.ApplyReplacements(c.Repo, replacements)
+			c.Revision = v1alpha1.ApplyReplacements(c.Revision, replacements)
+			c.Workspace = v1alpha1.ApplyReplacements(c.Workspace, replacements)
+		}
 		for j := range tasks[i].Conditions {
 			c := tasks[i].Conditions[j]
 			c.Params = replaceParamValues(c.Params, replacements, arrayReplacements)
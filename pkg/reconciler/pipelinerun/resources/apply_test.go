@@ -54,6 +54,28 @@ func TestApplyParameters(t *testing.T) {
 					tb.PipelineTaskParam("first-task-second-param", "second-value"),
 					tb.PipelineTaskParam("first-task-third-param", "static value"),
 				))),
+	}, {
+		name: "object parameter keys",
+		original: tb.Pipeline("test-pipeline", "foo",
+			tb.PipelineSpec(
+				tb.PipelineParamSpec("repo", v1alpha1.ParamTypeObject, tb.ParamSpecProperties("url", "revision"),
+					tb.ParamSpecObjectDefault(map[string]string{"url": "https://github.com/tektoncd/pipeline", "revision": "master"})),
+				tb.PipelineTask("first-task-1", "first-task",
+					tb.PipelineTaskParam("url", "$(params.repo.url)"),
+					tb.PipelineTaskParam("revision", "$(params.repo.revision)"),
+				))),
+		run: &v1alpha1.PipelineRun{Spec: v1alpha1.PipelineRunSpec{Params: []v1alpha1.Param{{
+			Name:  "repo",
+			Value: *tb.ObjectValue(map[string]string{"url": "https://github.com/tektoncd/triggers", "revision": "v0.2.0"}),
+		}}}},
+		expected: tb.Pipeline("test-pipeline", "foo",
+			tb.PipelineSpec(
+				tb.PipelineParamSpec("repo", v1alpha1.ParamTypeObject, tb.ParamSpecProperties("url", "revision"),
+					tb.ParamSpecObjectDefault(map[string]string{"url": "https://github.com/tektoncd/pipeline", "revision": "master"})),
+				tb.PipelineTask("first-task-1", "first-task",
+					tb.PipelineTaskParam("url", "https://github.com/tektoncd/triggers"),
+					tb.PipelineTaskParam("revision", "v0.2.0"),
+				))),
 	}, {
 		name: "pipeline parameter nested inside task parameter",
 		original: tb.Pipeline("test-pipeline", "foo",
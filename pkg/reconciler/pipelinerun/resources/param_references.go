@@ -0,0 +1,136 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"golang.org/x/xerrors"
+)
+
+var paramReference = regexp.MustCompile(`\$\(params\.([_a-zA-Z][_a-zA-Z0-9.-]*)\)`)
+
+// ResolveParamReferences replaces the $(params.name) references in the
+// values of params, the params supplied by a PipelineRun, with the value of
+// the string param name, supplied or defaulted by p. References are resolved
+// once: values substituted in aren't scanned for references again, except for
+// the references of the referenced param itself. References to unknown,
+// array and object params are left as is, references forming a cycle are an
+// error.
+func ResolveParamReferences(p *v1alpha1.PipelineSpec, params []v1alpha1.Param) ([]v1alpha1.Param, error) {
+	values := map[string]string{}
+	for _, spec := range p.Params {
+		if spec.Default != nil && spec.Default.Type == v1alpha1.ParamTypeString {
+			values[spec.Name] = spec.Default.StringVal
+		}
+	}
+	for _, param := range params {
+		// The values read from Secrets are only known to the steps, the
+		// references to them are left as is.
+		if param.ValueFrom != nil {
+			delete(values, param.Name)
+		} else if param.Value.Type == v1alpha1.ParamTypeString {
+			values[param.Name] = param.Value.StringVal
+		} else {
+			delete(values, param.Name)
+		}
+	}
+
+	r := &paramResolver{values: values, resolved: map[string]string{}, visiting: map[string]bool{}}
+	resolved := make([]v1alpha1.Param, 0, len(params))
+	for _, param := range params {
+		param = *param.DeepCopy()
+		if param.ValueFrom != nil {
+			resolved = append(resolved, param)
+			continue
+		}
+		switch param.Value.Type {
+		case v1alpha1.ParamTypeString:
+			v, err := r.resolve(param.Name)
+			if err != nil {
+				return nil, err
+			}
+			param.Value.StringVal = v
+		case v1alpha1.ParamTypeObject:
+			for key, v := range param.Value.ObjectVal {
+				v, err := r.expand(v)
+				if err != nil {
+					return nil, err
+				}
+				param.Value.ObjectVal[key] = v
+			}
+		default:
+			for i, v := range param.Value.ArrayVal {
+				v, err := r.expand(v)
+				if err != nil {
+					return nil, err
+				}
+				param.Value.ArrayVal[i] = v
+			}
+		}
+		resolved = append(resolved, param)
+	}
+	return resolved, nil
+}
+
+// paramResolver resolves the references between string params, memoizing
+// the resolved values.
+type paramResolver struct {
+	values   map[string]string
+	resolved map[string]string
+	visiting map[string]bool
+	// path is the chain of params being resolved, to report cycles.
+	path []string
+}
+
+func (r *paramResolver) resolve(name string) (string, error) {
+	if v, ok := r.resolved[name]; ok {
+		return v, nil
+	}
+	if r.visiting[name] {
+		return "", xerrors.Errorf("params reference each other in a cycle: %s -> %s", strings.Join(r.path, " -> "), name)
+	}
+	r.visiting[name] = true
+	r.path = append(r.path, name)
+	v, err := r.expand(r.values[name])
+	r.path = r.path[:len(r.path)-1]
+	r.visiting[name] = false
+	if err != nil {
+		return "", err
+	}
+	r.resolved[name] = v
+	return v, nil
+}
+
+// expand replaces the references to string params in value.
+func (r *paramResolver) expand(value string) (string, error) {
+	var err error
+	expanded := paramReference.ReplaceAllStringFunc(value, func(ref string) string {
+		name := paramReference.FindStringSubmatch(ref)[1]
+		if _, ok := r.values[name]; !ok || err != nil {
+			return ref
+		}
+		var v string
+		if v, err = r.resolve(name); err != nil {
+			return ref
+		}
+		return v
+	})
+	return expanded, err
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+)
+
+func TestResolveParamReferences(t *testing.T) {
+	p := tb.Pipeline("p", namespace, tb.PipelineSpec(
+		tb.PipelineParamSpec("registry", v1alpha1.ParamTypeString, tb.ParamSpecDefault("gcr.io/foo")),
+		tb.PipelineParamSpec("version", v1alpha1.ParamTypeString),
+		tb.PipelineParamSpec("image", v1alpha1.ParamTypeString),
+		tb.PipelineParamSpec("tags", v1alpha1.ParamTypeArray),
+	)).Spec
+	param := func(name, value string, additionalValues ...string) v1alpha1.Param {
+		return v1alpha1.Param{Name: name, Value: *tb.ArrayOrString(value, additionalValues...)}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		params  []v1alpha1.Param
+		want    []v1alpha1.Param
+		wantErr bool
+	}{{
+		name:   "no references",
+		params: []v1alpha1.Param{param("version", "v1")},
+		want:   []v1alpha1.Param{param("version", "v1")},
+	}, {
+		name: "chained references and defaults",
+		params: []v1alpha1.Param{
+			param("image", "$(params.registry)/app:$(params.version)"),
+			param("version", "v1-$(params.suffix)"),
+			param("suffix", "rc1"),
+			param("tags", "$(params.version)", "latest"),
+		},
+		want: []v1alpha1.Param{
+			param("image", "gcr.io/foo/app:v1-rc1"),
+			param("version", "v1-rc1"),
+			param("suffix", "rc1"),
+			param("tags", "v1-rc1", "latest"),
+		},
+	}, {
+		name:   "unknown and array references left as is",
+		params: []v1alpha1.Param{param("image", "$(params.unknown):$(params.tags)"), param("tags", "a", "b")},
+		want:   []v1alpha1.Param{param("image", "$(params.unknown):$(params.tags)"), param("tags", "a", "b")},
+	}, {
+		name:   "substituted values are not scanned again",
+		params: []v1alpha1.Param{param("image", "$(params.open)params.registry)"), param("open", "$(")},
+		want:   []v1alpha1.Param{param("image", "$(params.registry)"), param("open", "$(")},
+	}, {
+		name:    "cycle",
+		params:  []v1alpha1.Param{param("image", "$(params.version)"), param("version", "$(params.image)")},
+		wantErr: true,
+	}, {
+		name:    "self reference",
+		params:  []v1alpha1.Param{param("version", "$(params.version)-1")},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ResolveParamReferences(&p, tc.params)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %t, got %v", tc.wantErr, err)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("params diff -want, +got: %v", d)
+			}
+		})
+	}
+}
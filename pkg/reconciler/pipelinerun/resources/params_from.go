@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"sort"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// GetConfigMap is a function used to retrieve ConfigMaps
+type GetConfigMap func(name string) (*corev1.ConfigMap, error)
+
+// GetSecret is a function used to retrieve Secrets
+type GetSecret func(name string) (*corev1.Secret, error)
+
+// ResolveParamsFrom returns the params of pr followed by the ones read from
+// the ConfigMaps and Secrets of its paramsFrom which pr doesn't set itself,
+// sorted by name. When a key exists in multiple sources, the value of the
+// last source is used. The params read from Secrets only reference their
+// key, the steps read their value.
+func ResolveParamsFrom(pr *v1alpha1.PipelineRun, getConfigMap GetConfigMap, getSecret GetSecret) ([]v1alpha1.Param, error) {
+	if len(pr.Spec.ParamsFrom) == 0 {
+		return pr.Spec.Params, nil
+	}
+
+	values := map[string]v1alpha1.Param{}
+	for _, pf := range pr.Spec.ParamsFrom {
+		params, err := paramsFromSource(pf, getConfigMap, getSecret)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range params {
+			p.Name = pf.Prefix + p.Name
+			values[p.Name] = p
+		}
+	}
+	for _, p := range pr.Spec.Params {
+		delete(values, p.Name)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := append([]v1alpha1.Param{}, pr.Spec.Params...)
+	for _, name := range names {
+		params = append(params, values[name])
+	}
+	return params, nil
+}
+
+// paramsFromSource returns a param for each key of the ConfigMap or Secret of
+// pf, or nothing if it doesn't exist but is optional.
+func paramsFromSource(pf v1alpha1.ParamsFromSource, getConfigMap GetConfigMap, getSecret GetSecret) ([]v1alpha1.Param, error) {
+	var params []v1alpha1.Param
+	switch {
+	case pf.ConfigMapRef != nil:
+		cm, err := getConfigMap(pf.ConfigMapRef.Name)
+		if errors.IsNotFound(err) && isOptional(pf.ConfigMapRef.Optional) {
+			return nil, nil
+		} else if err != nil {
+			return nil, xerrors.Errorf("couldn't get ConfigMap %s for paramsFrom: %w", pf.ConfigMapRef.Name, err)
+		}
+		for key, value := range cm.Data {
+			params = append(params, v1alpha1.Param{
+				Name:  key,
+				Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString, StringVal: value},
+			})
+		}
+	case pf.SecretRef != nil:
+		s, err := getSecret(pf.SecretRef.Name)
+		if errors.IsNotFound(err) && isOptional(pf.SecretRef.Optional) {
+			return nil, nil
+		} else if err != nil {
+			return nil, xerrors.Errorf("couldn't get Secret %s for paramsFrom: %w", pf.SecretRef.Name, err)
+		}
+		for key := range s.Data {
+			params = append(params, v1alpha1.Param{
+				Name:  key,
+				Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString},
+				ValueFrom: &v1alpha1.ParamValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: s.Name},
+					Key:                  key,
+				}},
+			})
+		}
+	}
+	return params, nil
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResolveParamsFrom(t *testing.T) {
+	getConfigMap := func(name string) (*corev1.ConfigMap, error) {
+		switch name {
+		case "staging":
+			return &corev1.ConfigMap{Data: map[string]string{"cluster": "staging", "replicas": "1"}}, nil
+		case "overrides":
+			return &corev1.ConfigMap{Data: map[string]string{"replicas": "3"}}, nil
+		}
+		return nil, errors.NewNotFound(corev1.Resource("configmap"), name)
+	}
+	getSecret := func(name string) (*corev1.Secret, error) {
+		if name == "registry" {
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}, Data: map[string][]byte{"user": []byte("robot")}}, nil
+		}
+		return nil, errors.NewNotFound(corev1.Resource("secret"), name)
+	}
+	configMap := func(name string, optional bool) v1alpha1.ParamsFromSource {
+		return v1alpha1.ParamsFromSource{ConfigMapRef: &corev1.ConfigMapEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+			Optional:             &optional,
+		}}
+	}
+	stringParam := func(name, value string) v1alpha1.Param {
+		return v1alpha1.Param{Name: name, Value: *tb.ArrayOrString(value)}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		pr      *v1alpha1.PipelineRun
+		want    []v1alpha1.Param
+		wantErr bool
+	}{{
+		name: "no paramsFrom",
+		pr:   tb.PipelineRun("pr", namespace, tb.PipelineRunSpec("p", tb.PipelineRunParam("cluster", "prod"))),
+		want: []v1alpha1.Param{stringParam("cluster", "prod")},
+	}, {
+		name: "later sources and params take precedence",
+		pr: tb.PipelineRun("pr", namespace, tb.PipelineRunSpec("p",
+			tb.PipelineRunParam("cluster", "prod"),
+			tb.PipelineRunParamsFrom(configMap("staging", false)),
+			tb.PipelineRunParamsFrom(configMap("overrides", false)),
+			tb.PipelineRunParamsFrom(v1alpha1.ParamsFromSource{
+				Prefix:    "registry-",
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "registry"}},
+			}),
+		)),
+		want: []v1alpha1.Param{
+			stringParam("cluster", "prod"),
+			{
+				Name:  "registry-user",
+				Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString},
+				ValueFrom: &v1alpha1.ParamValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "registry"},
+					Key:                  "user",
+				}},
+			},
+			stringParam("replicas", "3"),
+		},
+	}, {
+		name: "missing optional source",
+		pr:   tb.PipelineRun("pr", namespace, tb.PipelineRunSpec("p", tb.PipelineRunParamsFrom(configMap("missing", true)))),
+		want: []v1alpha1.Param{},
+	}, {
+		name:    "missing source",
+		pr:      tb.PipelineRun("pr", namespace, tb.PipelineRunSpec("p", tb.PipelineRunParamsFrom(configMap("missing", false)))),
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ResolveParamsFrom(tc.pr, getConfigMap, getSecret)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error %t, got %v", tc.wantErr, err)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("params diff -want, +got: %v", d)
+			}
+		})
+	}
+}
@@ -27,11 +27,20 @@ type GetPipeline func(string) (v1alpha1.PipelineInterface, error)
 
 // GetPipelineData will retrieve the Pipeline metadata and Spec associated with the
 // provided PipelineRun. This can come from a reference Pipeline or from the PipelineRun's
-// metadata and embedded PipelineSpec.
+// metadata and embedded PipelineSpec. Once the PipelineRun started, the spec
+// is the one pinned in its status, and the Pipeline isn't retrieved anymore.
 func GetPipelineData(pipelineRun *v1alpha1.PipelineRun, getPipeline GetPipeline) (*metav1.ObjectMeta, *v1alpha1.PipelineSpec, error) {
 	pipelineMeta := metav1.ObjectMeta{}
 	pipelineSpec := v1alpha1.PipelineSpec{}
 	switch {
+	case pipelineRun.Status.PipelineSpec != nil:
+		// The labels and annotations of the Pipeline were propagated to the
+		// PipelineRun already when its spec was pinned.
+		pipelineMeta = metav1.ObjectMeta{Name: pipelineRun.Spec.PipelineRef.Name, Namespace: pipelineRun.Namespace}
+		if pipelineMeta.Name == "" {
+			pipelineMeta = pipelineRun.ObjectMeta
+		}
+		pipelineSpec = *pipelineRun.Status.PipelineSpec.DeepCopy()
 	case pipelineRun.Spec.PipelineRef.Name != "":
 		// Get related pipeline for pipelinerun
 		t, err := getPipeline(pipelineRun.Spec.PipelineRef.Name)
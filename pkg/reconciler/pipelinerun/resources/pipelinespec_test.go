@@ -64,6 +64,51 @@ func TestGetPipelineSpec_Ref(t *testing.T) {
 	}
 }
 
+func TestGetPipelineSpec_Pinned(t *testing.T) {
+	pr := &v1alpha1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mypipelinerun",
+			Namespace: "foo",
+		},
+		Spec: v1alpha1.PipelineRunSpec{
+			PipelineRef: v1alpha1.PipelineRef{
+				Name: "orchestrate",
+			},
+		},
+		Status: v1alpha1.PipelineRunStatus{
+			PipelineSpec: &v1alpha1.PipelineSpec{
+				Tasks: []v1alpha1.PipelineTask{{
+					Name: "pinned-task",
+					TaskRef: v1alpha1.TaskRef{
+						Name: "mytask",
+					},
+				}},
+			},
+		},
+	}
+	gt := func(n string) (v1alpha1.PipelineInterface, error) {
+		t.Fatalf("Did not expect the Pipeline %s of a PipelineRun with a pinned spec to be retrieved", n)
+		return nil, nil
+	}
+	pipelineMeta, pipelineSpec, err := GetPipelineData(pr, gt)
+
+	if err != nil {
+		t.Fatalf("Did not expect error getting pipeline spec but got: %s", err)
+	}
+
+	if pipelineMeta.Name != "orchestrate" || pipelineMeta.Namespace != "foo" {
+		t.Errorf("Expected pipeline to be `foo/orchestrate` but was %s/%s", pipelineMeta.Namespace, pipelineMeta.Name)
+	}
+
+	if len(pipelineSpec.Tasks) != 1 || pipelineSpec.Tasks[0].Name != "pinned-task" {
+		t.Errorf("Pipeline Spec not resolved as expected, expected pinned Pipeline spec but got: %v", pipelineSpec)
+	}
+	pipelineSpec.Tasks[0].Name = "changed"
+	if pr.Status.PipelineSpec.Tasks[0].Name != "pinned-task" {
+		t.Error("Expected the pinned Pipeline spec not to be changed along with the returned one")
+	}
+}
+
 func TestGetPipelineSpec_Embedded(t *testing.T) {
 	pr := &v1alpha1.PipelineRun{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+// ReasonPending indicates that none of the PipelineTasks of a stage has
+// started yet.
+const ReasonPending = "Pending"
+
+// GetPipelineStagesStatus aggregates the status of the PipelineTasks of each
+// stage they are grouped in, in the order the stages first appear in the
+// state. PipelineTasks without a stage aren't part of any.
+func GetPipelineStagesStatus(state PipelineRunState, dag *v1alpha1.DAG) []v1alpha1.PipelineRunStageStatus {
+	var stages []v1alpha1.PipelineRunStageStatus
+	index := map[string]int{}
+	started := map[string]bool{}
+	stateMap := state.toMap()
+	for _, rprt := range state {
+		name := rprt.PipelineTask.Stage
+		if name == "" {
+			continue
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(stages)
+			index[name] = i
+			stages = append(stages, v1alpha1.PipelineRunStageStatus{Name: name})
+		}
+		s := &stages[i]
+		s.Tasks++
+		switch {
+		case rprt.IsFailure():
+			s.Failed++
+		case rprt.IsSuccessful() || isSkipped(rprt, stateMap, dag):
+			s.Completed++
+		}
+		if rprt.TaskRun != nil || len(rprt.ResolvedConditionChecks) > 0 && rprt.ResolvedConditionChecks.HasStarted() {
+			started[name] = true
+		}
+	}
+
+	for i := range stages {
+		s := &stages[i]
+		switch {
+		case s.Failed > 0:
+			s.Reason = ReasonFailed
+		case s.Completed == s.Tasks:
+			s.Reason = ReasonSucceeded
+		case started[s.Name] || s.Completed > 0:
+			s.Reason = ReasonRunning
+		default:
+			s.Reason = ReasonPending
+		}
+	}
+	return stages
+}
+
+// StagesSummary sums up the status of the stages on one line, e.g.
+// "build: Succeeded, test: Running 3/5".
+func StagesSummary(stages []v1alpha1.PipelineRunStageStatus) string {
+	summaries := make([]string, 0, len(stages))
+	for _, s := range stages {
+		summaries = append(summaries, s.String())
+	}
+	return strings.Join(summaries, ", ")
+}
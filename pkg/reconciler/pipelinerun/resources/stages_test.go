@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+func stagedTask(name, stage string, runAfter ...string) *v1alpha1.PipelineTask {
+	return &v1alpha1.PipelineTask{
+		Name:     name,
+		TaskRef:  v1alpha1.TaskRef{Name: "task"},
+		Stage:    stage,
+		RunAfter: runAfter,
+	}
+}
+
+func TestGetPipelineStagesStatus(t *testing.T) {
+	// The conditional task is skipped, which completes it.
+	conditional := stagedTask("conditional", "release")
+	conditional.Conditions = pts[5].Conditions
+	state := PipelineRunState{{
+		PipelineTask: stagedTask("compile", "build"),
+		TaskRun:      makeSucceeded(trs[0]),
+	}, {
+		PipelineTask: stagedTask("unit", "test", "compile"),
+		TaskRun:      makeSucceeded(trs[0]),
+	}, {
+		PipelineTask: stagedTask("integration", "test", "compile"),
+		TaskRun:      makeStarted(trs[0]),
+	}, {
+		PipelineTask: stagedTask("e2e", "test", "compile"),
+	}, {
+		PipelineTask: stagedTask("lint", "check"),
+		TaskRun:      makeFailed(trs[0]),
+	}, {
+		PipelineTask: stagedTask("vet", "check"),
+		TaskRun:      makeSucceeded(trs[0]),
+	}, {
+		PipelineTask: stagedTask("deploy", "release", "unit", "integration", "e2e"),
+	}, {
+		PipelineTask: &v1alpha1.PipelineTask{Name: "notify", TaskRef: v1alpha1.TaskRef{Name: "task"}},
+	}, {
+		PipelineTask:            conditional,
+		ResolvedConditionChecks: failedTaskConditionCheckState,
+	}}
+
+	dag, err := DagFromState(state)
+	if err != nil {
+		t.Fatalf("Unexpected error while building DAG for state %v: %v", state, err)
+	}
+	got := GetPipelineStagesStatus(state, dag)
+	want := []v1alpha1.PipelineRunStageStatus{{
+		Name:      "build",
+		Reason:    ReasonSucceeded,
+		Tasks:     1,
+		Completed: 1,
+	}, {
+		Name:      "test",
+		Reason:    ReasonRunning,
+		Tasks:     3,
+		Completed: 1,
+	}, {
+		Name:      "check",
+		Reason:    ReasonFailed,
+		Tasks:     2,
+		Completed: 1,
+		Failed:    1,
+	}, {
+		Name:      "release",
+		Reason:    ReasonRunning,
+		Tasks:     2,
+		Completed: 1,
+	}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Unexpected stages status: %s", d)
+	}
+
+	wantSummary := "build: Succeeded, test: Running 1/3, check: Failed, release: Running 1/2"
+	if summary := StagesSummary(got); summary != wantSummary {
+		t.Errorf("Expected summary %q but got %q", wantSummary, summary)
+	}
+}
+
+func TestGetPipelineStagesStatus_Pending(t *testing.T) {
+	state := PipelineRunState{{
+		PipelineTask: stagedTask("compile", "build"),
+	}, {
+		PipelineTask: stagedTask("unit", "test", "compile"),
+	}}
+	dag, err := DagFromState(state)
+	if err != nil {
+		t.Fatalf("Unexpected error while building DAG for state %v: %v", state, err)
+	}
+	got := GetPipelineStagesStatus(state, dag)
+	for _, s := range got {
+		if s.Reason != ReasonPending {
+			t.Errorf("Expected stage %s to be %s but it was %s", s.Name, ReasonPending, s.Reason)
+		}
+	}
+	if len(got) != 2 {
+		t.Errorf("Expected 2 stages but got %d", len(got))
+	}
+}
@@ -17,23 +17,30 @@ limitations under the License.
 package resources
 
 import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"golang.org/x/xerrors"
 )
 
 // Validate that parameters in PipelineRun override corresponding parameters in Pipeline of the same type.
 func ValidateParamTypesMatching(p *v1alpha1.PipelineSpec, pr *v1alpha1.PipelineRun) error {
-	// Build a map of parameter names/types declared in p.
-	paramTypes := make(map[string]v1alpha1.ParamType)
+	// Build a map of parameters declared in p by name.
+	paramSpecs := make(map[string]v1alpha1.ParamSpec)
 	for _, param := range p.Params {
-		paramTypes[param.Name] = param.Type
+		paramSpecs[param.Name] = param
 	}
 
 	// Build a list of parameter names from pr that have mismatching types with the map created above.
+	// Object parameters missing keys declared in p don't match either.
 	var wrongTypeParamNames []string
 	for _, param := range pr.Spec.Params {
-		if paramType, ok := paramTypes[param.Name]; ok {
-			if param.Value.Type != paramType {
+		if spec, ok := paramSpecs[param.Name]; ok {
+			if param.Value.Type != spec.Type {
+				wrongTypeParamNames = append(wrongTypeParamNames, param.Name)
+			} else if spec.Type == v1alpha1.ParamTypeObject && len(v1alpha1.MissingObjectKeys(spec, param.Value.ObjectVal)) != 0 {
 				wrongTypeParamNames = append(wrongTypeParamNames, param.Name)
 			}
 		}
@@ -45,3 +52,71 @@ func ValidateParamTypesMatching(p *v1alpha1.PipelineSpec, pr *v1alpha1.PipelineR
 	}
 	return nil
 }
+
+// ValidateParamConstraints validates that the parameters in the PipelineRun
+// satisfy the enum and the pattern of the parameters of the same name in p.
+func ValidateParamConstraints(p *v1alpha1.PipelineSpec, pr *v1alpha1.PipelineRun) error {
+	if err := v1alpha1.ValidateParamValues(p.Params, pr.Spec.Params, "spec.params"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateSecretParams checks that the params of the PipelineRun read from
+// Secrets are only passed whole to the params of the Tasks, e.g.
+// value: $(params.token), so that the TaskRuns read them from the Secrets
+// too rather than holding their values.
+func ValidateSecretParams(p *v1alpha1.PipelineSpec, pr *v1alpha1.PipelineRun) error {
+	secrets := map[string]bool{}
+	var params []v1alpha1.Param
+	for _, param := range pr.Spec.Params {
+		if param.ValueFrom != nil {
+			secrets[param.Name] = true
+		} else {
+			params = append(params, param)
+		}
+	}
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	rest := p.DeepCopy()
+	for i := range rest.Tasks {
+		for j, param := range rest.Tasks[i].Params {
+			if name, ok := wholeParamReference(param.Value); ok && secrets[name] {
+				rest.Tasks[i].Params[j].Value.StringVal = ""
+			}
+		}
+	}
+	b, err := json.Marshal(struct {
+		Spec   *v1alpha1.PipelineSpec
+		Params []v1alpha1.Param
+	}{rest, params})
+	if err != nil {
+		return err
+	}
+	var misused []string
+	for name := range secrets {
+		if bytes.Contains(b, []byte("$(params."+name+")")) {
+			misused = append(misused, name)
+		}
+	}
+	if len(misused) != 0 {
+		sort.Strings(misused)
+		return xerrors.Errorf("params read from Secrets can only be passed whole to the params of the Tasks: %s", misused)
+	}
+	return nil
+}
+
+// wholeParamReference returns the name of the param value only references,
+// e.g. token for $(params.token).
+func wholeParamReference(value v1alpha1.ArrayOrString) (string, bool) {
+	if value.Type != v1alpha1.ParamTypeString {
+		return "", false
+	}
+	m := paramReference.FindStringSubmatch(value.StringVal)
+	if m == nil || m[0] != value.StringVal {
+		return "", false
+	}
+	return m[1], true
+}
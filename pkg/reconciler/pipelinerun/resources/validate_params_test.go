@@ -17,6 +17,7 @@ limitations under the License.
 package resources
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
@@ -112,6 +113,14 @@ func TestValidateParamTypesMatching_Invalid(t *testing.T) {
 				tb.PipelineRunParam("correct-type-1", "somestring"),
 				tb.PipelineRunParam("mismatching-type", "astring"),
 				tb.PipelineRunParam("correct-type-2", "another", "array"))),
+	}, {
+		name: "object missing keys",
+		p: tb.Pipeline("a-pipeline", namespace, tb.PipelineSpec(
+			tb.PipelineParamSpec("repo", v1alpha1.ParamTypeObject, tb.ParamSpecProperties("url", "revision")))),
+		pr: &v1alpha1.PipelineRun{Spec: v1alpha1.PipelineRunSpec{Params: []v1alpha1.Param{{
+			Name:  "repo",
+			Value: *tb.ObjectValue(map[string]string{"url": "https://github.com/tektoncd/pipeline"}),
+		}}}},
 	}}
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
@@ -121,3 +130,26 @@ func TestValidateParamTypesMatching_Invalid(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateParamConstraints(t *testing.T) {
+	p := tb.Pipeline("a-pipeline", namespace, tb.PipelineSpec(
+		tb.PipelineParamSpec("env", v1alpha1.ParamTypeString, tb.ParamSpecEnum("dev", "prod")),
+		tb.PipelineParamSpec("tag", v1alpha1.ParamTypeString, tb.ParamSpecPattern("^v[0-9]+$"))))
+	valid := tb.PipelineRun("a-pipelinerun", namespace, tb.PipelineRunSpec("a-pipeline",
+		tb.PipelineRunParam("env", "prod"),
+		tb.PipelineRunParam("tag", "v2")))
+	if err := ValidateParamConstraints(&p.Spec, valid); err != nil {
+		t.Errorf("Didn't expect to see error when validating valid PipelineRun params but saw: %v", err)
+	}
+
+	invalid := tb.PipelineRun("a-pipelinerun", namespace, tb.PipelineRunSpec("a-pipeline",
+		tb.PipelineRunParam("env", "prod"),
+		tb.PipelineRunParam("tag", "latest")))
+	err := ValidateParamConstraints(&p.Spec, invalid)
+	if err == nil {
+		t.Fatal("Expected to see error when validating PipelineRun params violating constraints but saw none")
+	}
+	if want := "spec.params[1].value"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected the error to point at %s, got %v", want, err)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prepull
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
+	clustertaskinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/clustertask"
+	pipelineinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipeline"
+	taskinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/task"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	daemonsetinformer "knative.dev/pkg/client/injection/kube/informers/apps/v1/daemonset"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	resyncPeriod = 10 * time.Hour
+)
+
+// NewController returns a constructor for the image pre-pull controller. The
+// DaemonSets it creates only run on nodes matching nodeSelector.
+func NewController(images pipeline.Images, nodeSelector map[string]string) func(context.Context, configmap.Watcher) *controller.Impl {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		logger := logging.FromContext(ctx)
+		kubeclientset := kubeclient.Get(ctx)
+		pipelineclientset := pipelineclient.Get(ctx)
+		pipelineInformer := pipelineinformer.Get(ctx)
+		taskInformer := taskinformer.Get(ctx)
+		clusterTaskInformer := clustertaskinformer.Get(ctx)
+		daemonSetInformer := daemonsetinformer.Get(ctx)
+
+		opt := reconciler.Options{
+			KubeClientSet:     kubeclientset,
+			PipelineClientSet: pipelineclientset,
+			ConfigMapWatcher:  cmw,
+			ResyncPeriod:      resyncPeriod,
+			Logger:            logger,
+		}
+
+		c := &Reconciler{
+			Base:              reconciler.NewBase(opt, prePullAgentName, images),
+			pipelineLister:    pipelineInformer.Lister(),
+			taskLister:        taskInformer.Lister(),
+			clusterTaskLister: clusterTaskInformer.Lister(),
+			daemonSetLister:   daemonSetInformer.Lister(),
+			nodeSelector:      nodeSelector,
+		}
+		impl := controller.NewImpl(c, c.Logger, prePullControllerName)
+		if err := reconciler.TrackQueueLatency(impl, prePullControllerName, "Pipeline", clock.RealClock{}); err != nil {
+			logger.Errorf("Failed to track the queue latency of %s: %v", prePullControllerName, err)
+		}
+
+		c.enqueue = impl.Enqueue
+
+		c.Logger.Info("Setting up event handlers")
+		pipelineInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    impl.Enqueue,
+			UpdateFunc: controller.PassNew(impl.Enqueue),
+		})
+
+		// The images of the Pipelines change with the ones of their Tasks.
+		taskInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.AddTask,
+			UpdateFunc: controller.PassNew(c.AddTask),
+		})
+		clusterTaskInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.AddClusterTask,
+			UpdateFunc: controller.PassNew(c.AddClusterTask),
+		})
+
+		daemonSetInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: controller.Filter(pipelineGroupVersionKind),
+			Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+		})
+
+		return impl
+	}
+}
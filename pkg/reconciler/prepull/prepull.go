@@ -0,0 +1,311 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prepull
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/names"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"golang.org/x/xerrors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+)
+
+const (
+	// prePullAgentName defines logging agent name for the image pre-pull controller
+	prePullAgentName = "prepull-controller"
+	// prePullControllerName defines name for the image pre-pull controller
+	prePullControllerName = "ImagePrePull"
+
+	// daemonSetSuffix is appended to the Pipeline name to build the name of
+	// the DaemonSet pre-pulling its images.
+	daemonSetSuffix = "prepull"
+
+	toolsVolumeName = "prepull-tools"
+	toolsMountPoint = "/prepull"
+	entrypointBin   = toolsMountPoint + "/entrypoint"
+	// neverFile is a file which is never written, waiting on it keeps the
+	// DaemonSet pod alive without requiring a shell in any of the images.
+	neverFile = toolsMountPoint + "/never"
+
+	// ManagedByLabelKey and ManagedByLabelValue mark the DaemonSets created by
+	// this controller.
+	ManagedByLabelKey   = "app.kubernetes.io/managed-by"
+	ManagedByLabelValue = "tekton-pipelines"
+
+	// ReasonDaemonSetConflict is the reason of the events of the Pipelines
+	// whose pre-pull DaemonSet name is taken by one they don't own.
+	ReasonDaemonSetConflict = "PrePullDaemonSetConflict"
+)
+
+var pipelineGroupVersionKind = v1alpha1.SchemeGroupVersion.WithKind("Pipeline")
+
+// Reconciler keeps one DaemonSet per Pipeline which pulls all the step and
+// sidecar images of the Pipeline's Tasks onto the selected nodes, so that the
+// first run after an image bump doesn't pay the pull latency.
+type Reconciler struct {
+	*reconciler.Base
+
+	pipelineLister    listers.PipelineLister
+	taskLister        listers.TaskLister
+	clusterTaskLister listers.ClusterTaskLister
+	daemonSetLister   appslisters.DaemonSetLister
+
+	// nodeSelector restricts the DaemonSet pods to the labeled CI nodes.
+	nodeSelector map[string]string
+
+	// enqueue adds a Pipeline to the work queue of the controller.
+	enqueue func(obj interface{})
+}
+
+// Check that our Reconciler implements controller.Reconciler
+var _ controller.Reconciler = (*Reconciler)(nil)
+
+// Reconcile makes sure the pre-pull DaemonSet of the Pipeline identified by
+// key matches the images the Pipeline currently uses.
+func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		c.Logger.Errorf("invalid resource key: %s", key)
+		return nil
+	}
+
+	p, err := c.pipelineLister.Pipelines(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		// The DaemonSet is owned by the Pipeline and is garbage collected with it.
+		c.Logger.Infof("pipeline %q in work queue no longer exists", key)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	images, err := c.pipelineImages(p)
+	if err != nil {
+		// A missing Task may show up later, the Pipeline is then enqueued
+		// again, and meanwhile retried with a backoff.
+		return xerrors.Errorf("failed to resolve images for pipeline %q: %w", key, err)
+	}
+
+	dsName := DaemonSetName(p)
+	existing, err := c.daemonSetLister.DaemonSets(namespace).Get(dsName)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	// A DaemonSet of the same name the Pipeline doesn't own, e.g. one of
+	// the cluster operator, is neither updated nor deleted.
+	if existing != nil && !metav1.IsControlledBy(existing, p) {
+		c.Recorder.Eventf(p, corev1.EventTypeWarning, ReasonDaemonSetConflict,
+			"DaemonSet %s isn't owned by the pipeline, its images aren't pre-pulled", dsName)
+		return controller.NewPermanentError(fmt.Errorf("DaemonSet %s/%s isn't owned by pipeline %q", namespace, dsName, key))
+	}
+
+	if len(images) == 0 {
+		if existing != nil {
+			c.Logger.Infof("Pipeline %q has no images to pre-pull anymore, deleting DaemonSet %s", key, dsName)
+			return c.KubeClientSet.AppsV1().DaemonSets(namespace).Delete(dsName, &metav1.DeleteOptions{})
+		}
+		return nil
+	}
+
+	desired := MakeDaemonSet(p, images, c.Images.EntryPointImage, c.nodeSelector)
+	if existing == nil {
+		c.Logger.Infof("Creating pre-pull DaemonSet %s for pipeline %q", dsName, key)
+		_, err := c.KubeClientSet.AppsV1().DaemonSets(namespace).Create(desired)
+		return err
+	}
+	if equality.Semantic.DeepEqual(existing.Spec.Template, desired.Spec.Template) {
+		return nil
+	}
+	updated := existing.DeepCopy()
+	updated.Spec.Template = desired.Spec.Template
+	c.Logger.Infof("Updating pre-pull DaemonSet %s for pipeline %q", dsName, key)
+	_, err = c.KubeClientSet.AppsV1().DaemonSets(namespace).Update(updated)
+	return err
+}
+
+// AddTask enqueues the Pipelines of the namespace of a newly seen or updated
+// Task which reference it, since its images may have changed.
+func (c *Reconciler) AddTask(obj interface{}) {
+	t, ok := obj.(*v1alpha1.Task)
+	if !ok {
+		return
+	}
+	c.enqueueReferencing(t.Namespace, t.Name, v1alpha1.NamespacedTaskKind)
+}
+
+// AddClusterTask enqueues the Pipelines of all the namespaces which reference
+// a newly seen or updated ClusterTask, since its images may have changed.
+func (c *Reconciler) AddClusterTask(obj interface{}) {
+	ct, ok := obj.(*v1alpha1.ClusterTask)
+	if !ok {
+		return
+	}
+	c.enqueueReferencing(metav1.NamespaceAll, ct.Name, v1alpha1.ClusterTaskKind)
+}
+
+// enqueueReferencing enqueues the Pipelines of namespace which reference the
+// Task of the given kind and name.
+func (c *Reconciler) enqueueReferencing(namespace, name string, kind v1alpha1.TaskKind) {
+	ps, err := c.pipelineLister.Pipelines(namespace).List(labels.Everything())
+	if err != nil {
+		c.Logger.Errorf("Failed to list the pipelines referencing %s %q: %v", kind, name, err)
+		return
+	}
+	for _, p := range ps {
+		for _, pt := range p.Spec.Tasks {
+			if pt.TaskRef.Name == name && taskRefKind(pt.TaskRef) == kind {
+				c.enqueue(p)
+				break
+			}
+		}
+	}
+}
+
+// taskRefKind returns the kind of the Task ref references, which defaults to
+// a namespaced Task.
+func taskRefKind(ref v1alpha1.TaskRef) v1alpha1.TaskKind {
+	if ref.Kind == v1alpha1.ClusterTaskKind {
+		return v1alpha1.ClusterTaskKind
+	}
+	return v1alpha1.NamespacedTaskKind
+}
+
+// pipelineImages returns the sorted, de-duplicated list of images used by
+// the steps and sidecars of the Tasks referenced by p.
+func (c *Reconciler) pipelineImages(p *v1alpha1.Pipeline) ([]string, error) {
+	seen := map[string]struct{}{}
+	for _, pt := range p.Spec.Tasks {
+		var t v1alpha1.TaskInterface
+		var err error
+		if taskRefKind(pt.TaskRef) == v1alpha1.ClusterTaskKind {
+			t, err = c.clusterTaskLister.Get(pt.TaskRef.Name)
+		} else {
+			t, err = c.taskLister.Tasks(p.Namespace).Get(pt.TaskRef.Name)
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't get task %q for pipeline task %q: %w", pt.TaskRef.Name, pt.Name, err)
+		}
+		for _, img := range TaskImages(t.TaskSpec()) {
+			seen[img] = struct{}{}
+		}
+	}
+	images := make([]string, 0, len(seen))
+	for img := range seen {
+		images = append(images, img)
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+// TaskImages returns the images of the init steps, steps and sidecars of ts, skipping
+// images which still contain variables to be substituted at run time.
+func TaskImages(ts v1alpha1.TaskSpec) []string {
+	var images []string
+	for _, s := range ts.InitSteps {
+		images = append(images, s.Image)
+	}
+	for _, s := range ts.Steps {
+		images = append(images, s.Image)
+	}
+	for _, s := range ts.Sidecars {
+		images = append(images, s.Image)
+	}
+	var concrete []string
+	for _, img := range images {
+		if img == "" || strings.Contains(img, "$(") {
+			continue
+		}
+		concrete = append(concrete, img)
+	}
+	return concrete
+}
+
+// DaemonSetName returns the name of the pre-pull DaemonSet of p.
+func DaemonSetName(p *v1alpha1.Pipeline) string {
+	return names.SimpleNameGenerator.RestrictLength(fmt.Sprintf("%s-%s", p.Name, daemonSetSuffix))
+}
+
+// MakeDaemonSet builds the DaemonSet pulling images onto the nodes matching
+// nodeSelector. Each image is pulled by an init container running the
+// entrypoint binary copied from entrypointImage, which exits immediately, so
+// images don't need to ship a shell. The long running container then waits
+// forever on a file that is never written.
+func MakeDaemonSet(p *v1alpha1.Pipeline, images []string, entrypointImage string, nodeSelector map[string]string) *appsv1.DaemonSet {
+	labels := map[string]string{
+		ManagedByLabelKey: ManagedByLabelValue,
+		pipeline.GroupName + pipeline.PipelineLabelKey: p.Name,
+	}
+	toolsMount := corev1.VolumeMount{Name: toolsVolumeName, MountPath: toolsMountPoint}
+
+	initContainers := []corev1.Container{{
+		Name:         "place-tools",
+		Image:        entrypointImage,
+		Command:      []string{"cp", "/ko-app/entrypoint", entrypointBin},
+		VolumeMounts: []corev1.VolumeMount{toolsMount},
+	}}
+	for i, img := range images {
+		initContainers = append(initContainers, corev1.Container{
+			Name:         fmt.Sprintf("prepull-%d", i),
+			Image:        img,
+			Command:      []string{entrypointBin},
+			VolumeMounts: []corev1.VolumeMount{toolsMount},
+		})
+	}
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            DaemonSetName(p),
+			Namespace:       p.Namespace,
+			Labels:          labels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(p, pipelineGroupVersionKind)},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					NodeSelector:   nodeSelector,
+					InitContainers: initContainers,
+					Containers: []corev1.Container{{
+						Name:         "wait",
+						Image:        entrypointImage,
+						Command:      []string{entrypointBin, "-wait_file", neverFile},
+						VolumeMounts: []corev1.VolumeMount{toolsMount},
+					}},
+					Volumes: []corev1.Volume{{
+						Name:         toolsVolumeName,
+						VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+					}},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prepull
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	"github.com/tektoncd/pipeline/pkg/system"
+	"github.com/tektoncd/pipeline/test"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakedaemonsetinformer "knative.dev/pkg/client/injection/kube/informers/apps/v1/daemonset/fake"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+)
+
+var (
+	images = pipeline.Images{
+		EntryPointImage: "override-with-entrypoint:latest",
+	}
+	nodeSelector = map[string]string{"ci": "true"}
+)
+
+func TestTaskImages(t *testing.T) {
+	ts := tb.Task("build", "foo", tb.TaskSpec(
+		tb.Step("compile", "golang:1.12"),
+		tb.Step("templated", "$(inputs.params.image)"),
+		tb.Sidecar("docker", "docker:dind"),
+		tb.InitStep("restore-cache", "gsutil"),
+	)).Spec
+
+	got := TaskImages(ts)
+	want := []string{"gsutil", "golang:1.12", "docker:dind"}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("TaskImages() diff -want, +got: %v", d)
+	}
+}
+
+func TestMakeDaemonSet(t *testing.T) {
+	p := tb.Pipeline("test-pipeline", "foo")
+	ds := MakeDaemonSet(p, []string{"docker:dind", "golang:1.12"}, images.EntryPointImage, nodeSelector)
+
+	if ds.Name != "test-pipeline-prepull" {
+		t.Errorf("expected DaemonSet name test-pipeline-prepull, got %s", ds.Name)
+	}
+	if len(ds.OwnerReferences) != 1 || ds.OwnerReferences[0].Kind != "Pipeline" || ds.OwnerReferences[0].Name != p.Name {
+		t.Errorf("expected DaemonSet to be owned by the Pipeline, got %v", ds.OwnerReferences)
+	}
+	podSpec := ds.Spec.Template.Spec
+	if d := cmp.Diff(nodeSelector, podSpec.NodeSelector); d != "" {
+		t.Errorf("node selector diff -want, +got: %v", d)
+	}
+	var pulled []string
+	for _, c := range podSpec.InitContainers[1:] {
+		pulled = append(pulled, c.Image)
+		if d := cmp.Diff([]string{entrypointBin}, c.Command); d != "" {
+			t.Errorf("init container %s should run the copied entrypoint, diff -want, +got: %v", c.Name, d)
+		}
+	}
+	if d := cmp.Diff([]string{"docker:dind", "golang:1.12"}, pulled); d != "" {
+		t.Errorf("pulled images diff -want, +got: %v", d)
+	}
+	if podSpec.InitContainers[0].Image != images.EntryPointImage {
+		t.Errorf("expected the first init container to place the entrypoint binary, got %v", podSpec.InitContainers[0])
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		pipeline *v1alpha1.Pipeline
+		tasks    []*v1alpha1.Task
+		cts      []*v1alpha1.ClusterTask
+		want     []string
+	}{{
+		name: "namespaced and cluster tasks",
+		pipeline: tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(
+			tb.PipelineTask("build", "build-task"),
+			tb.PipelineTask("push", "push-task", tb.PipelineTaskRefKind(v1alpha1.ClusterTaskKind)),
+			tb.PipelineTask("build-again", "build-task"),
+		)),
+		tasks: []*v1alpha1.Task{tb.Task("build-task", "foo", tb.TaskSpec(tb.Step("compile", "golang:1.12")))},
+		cts:   []*v1alpha1.ClusterTask{tb.ClusterTask("push-task", tb.ClusterTaskSpec(tb.Step("push", "gcr.io/kaniko-project/executor")))},
+		want:  []string{"gcr.io/kaniko-project/executor", "golang:1.12"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			c, _ := test.SeedTestData(t, ctx, test.Data{
+				Pipelines:    []*v1alpha1.Pipeline{tc.pipeline},
+				Tasks:        tc.tasks,
+				ClusterTasks: tc.cts,
+			})
+			impl := NewController(images, nodeSelector)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+
+			if err := impl.Reconciler.Reconcile(ctx, "foo/test-pipeline"); err != nil {
+				t.Fatalf("Unexpected error reconciling pipeline: %v", err)
+			}
+			ds, err := c.Kube.AppsV1().DaemonSets("foo").Get("test-pipeline-prepull", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected the pre-pull DaemonSet to be created: %v", err)
+			}
+			var got []string
+			for _, c := range ds.Spec.Template.Spec.InitContainers[1:] {
+				got = append(got, c.Image)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("pre-pulled images diff -want, +got: %v", d)
+			}
+
+			// Once the DaemonSet is in the lister, it is only updated when it is stale.
+			if err := fakedaemonsetinformer.Get(ctx).Informer().GetIndexer().Add(ds); err != nil {
+				t.Fatal(err)
+			}
+			if err := impl.Reconciler.Reconcile(ctx, "foo/test-pipeline"); err != nil {
+				t.Fatalf("Unexpected error reconciling pipeline: %v", err)
+			}
+			for _, a := range c.Kube.Actions() {
+				if a.GetVerb() == "update" {
+					t.Errorf("Expected no update of an up to date DaemonSet, got %v", a)
+				}
+			}
+			ds.Spec.Template.Spec.InitContainers[1].Image = "golang:1.11"
+			ds.Spec.Template.Spec.InitContainers = append(ds.Spec.Template.Spec.InitContainers, corev1.Container{Name: "stale"})
+			if err := fakedaemonsetinformer.Get(ctx).Informer().GetIndexer().Update(ds); err != nil {
+				t.Fatal(err)
+			}
+			if err := impl.Reconciler.Reconcile(ctx, "foo/test-pipeline"); err != nil {
+				t.Fatalf("Unexpected error reconciling pipeline: %v", err)
+			}
+			updated, err := c.Kube.AppsV1().DaemonSets("foo").Get("test-pipeline-prepull", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(updated.Spec.Template.Spec.InitContainers) != len(tc.want)+1 {
+				t.Errorf("Expected the stale DaemonSet to be updated, got %v", updated.Spec.Template.Spec.InitContainers)
+			}
+		})
+	}
+}
+
+func TestReconcile_NotOwnedDaemonSet(t *testing.T) {
+	p := tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(tb.PipelineTask("build", "build-task")))
+	task := tb.Task("build-task", "foo", tb.TaskSpec(tb.Step("compile", "golang:1.12")))
+	// The DaemonSet of the same name was created by someone else.
+	ds := MakeDaemonSet(p, []string{"busybox"}, images.EntryPointImage, nodeSelector)
+	ds.OwnerReferences = nil
+
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, _ := test.SeedTestData(t, ctx, test.Data{
+		Pipelines: []*v1alpha1.Pipeline{p},
+		Tasks:     []*v1alpha1.Task{task},
+	})
+	if err := fakedaemonsetinformer.Get(ctx).Informer().GetIndexer().Add(ds); err != nil {
+		t.Fatal(err)
+	}
+	impl := NewController(images, nodeSelector)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+
+	err := impl.Reconciler.Reconcile(ctx, "foo/test-pipeline")
+	if !controller.IsPermanentError(err) {
+		t.Errorf("Expected a permanent error reconciling a pipeline whose DaemonSet it doesn't own, got %v", err)
+	}
+	for _, a := range c.Kube.Actions() {
+		if a.GetResource().Resource == "daemonsets" {
+			t.Errorf("Expected the DaemonSet not to be touched, got %v", a)
+		}
+	}
+}
+
+func TestReconcile_MissingTask(t *testing.T) {
+	p := tb.Pipeline("test-pipeline", "foo", tb.PipelineSpec(tb.PipelineTask("build", "build-task")))
+
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, _ := test.SeedTestData(t, ctx, test.Data{
+		Pipelines: []*v1alpha1.Pipeline{p},
+	})
+	impl := NewController(images, nodeSelector)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+
+	// The pipeline is retried until its Task shows up.
+	err := impl.Reconciler.Reconcile(ctx, "foo/test-pipeline")
+	if err == nil || controller.IsPermanentError(err) {
+		t.Errorf("Expected a transient error reconciling a pipeline whose Task is missing, got %v", err)
+	}
+	for _, a := range c.Kube.Actions() {
+		if a.GetResource().Resource == "daemonsets" {
+			t.Errorf("Expected no DaemonSet to be created, got %v", a)
+		}
+	}
+}
+
+func TestAddTask(t *testing.T) {
+	task := tb.Task("build-task", "foo")
+	ct := tb.ClusterTask("build-task")
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, _ := test.SeedTestData(t, ctx, test.Data{
+		Pipelines: []*v1alpha1.Pipeline{
+			tb.Pipeline("uses-task", "foo", tb.PipelineSpec(
+				tb.PipelineTask("lint", "lint-task"),
+				tb.PipelineTask("build", "build-task"),
+			)),
+			tb.Pipeline("uses-cluster-task", "foo", tb.PipelineSpec(
+				tb.PipelineTask("build", "build-task", tb.PipelineTaskRefKind(v1alpha1.ClusterTaskKind)),
+			)),
+			tb.Pipeline("uses-other-task", "foo", tb.PipelineSpec(tb.PipelineTask("lint", "lint-task"))),
+			tb.Pipeline("uses-task", "bar", tb.PipelineSpec(tb.PipelineTask("build", "build-task"))),
+		},
+	})
+	impl := NewController(images, nodeSelector)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*Reconciler)
+
+	var got []string
+	r.enqueue = func(obj interface{}) {
+		p := obj.(*v1alpha1.Pipeline)
+		got = append(got, p.Namespace+"/"+p.Name)
+	}
+
+	r.AddTask(task)
+	if d := cmp.Diff([]string{"foo/uses-task"}, got); d != "" {
+		t.Errorf("pipelines enqueued for the Task diff -want, +got: %v", d)
+	}
+
+	got = nil
+	r.AddClusterTask(ct)
+	if d := cmp.Diff([]string{"foo/uses-cluster-task"}, got); d != "" {
+		t.Errorf("pipelines enqueued for the ClusterTask diff -want, +got: %v", d)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/util/workqueue"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/metrics"
+)
+
+var (
+	queueLatency = stats.Float64(
+		"reconcile_queue_latency_seconds",
+		"Time between a resource being due for reconciliation and its reconciliation starting, in seconds",
+		stats.UnitDimensionless)
+	queueLatencyDistribution = view.Distribution(0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 600)
+
+	reconcilerTagKey = mustNewTagKey("reconciler")
+	kindTagKey       = mustNewTagKey("kind")
+)
+
+func mustNewTagKey(s string) tag.Key {
+	k, err := tag.NewKey(s)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// TrackQueueLatency replaces the work queue of impl, named name, with one
+// which records, per reconciler and resource kind, how long the keys wait
+// between being due, i.e. when they are added or once their delay elapsed,
+// and being handed to the reconciler. A growing latency means the
+// reconciler can't keep up with its queue.
+func TrackQueueLatency(impl *controller.Impl, name, kind string, c clock.Clock) error {
+	// Registering the same view again, e.g. for another controller, is a
+	// no-op.
+	err := view.Register(&view.View{
+		Description: queueLatency.Description(),
+		Measure:     queueLatency,
+		Aggregation: queueLatencyDistribution,
+		TagKeys:     []tag.Key{reconcilerTagKey, kindTagKey},
+	})
+	if err != nil {
+		return err
+	}
+	ctx, err := tag.New(context.Background(), tag.Insert(reconcilerTagKey, name), tag.Insert(kindTagKey, kind))
+	if err != nil {
+		return err
+	}
+
+	q := &latencyQueue{clock: c, ctx: ctx, due: map[interface{}][]time.Time{}}
+	q.RateLimitingInterface = workqueue.NewNamedRateLimitingQueue(&latencyRateLimiter{
+		RateLimiter: workqueue.DefaultControllerRateLimiter(),
+		queue:       q,
+	}, name)
+	impl.WorkQueue.ShutDown()
+	impl.WorkQueue = q
+	return nil
+}
+
+// latencyQueue is a work queue which records the latency of its keys.
+type latencyQueue struct {
+	workqueue.RateLimitingInterface
+
+	clock clock.Clock
+	ctx   context.Context
+
+	mu sync.Mutex
+	// due holds the times the keys in the queue, or waiting to be added to
+	// it, are due at.
+	due map[interface{}][]time.Time
+}
+
+// latencyRateLimiter lets the latencyQueue know when the keys it delays are
+// due.
+type latencyRateLimiter struct {
+	workqueue.RateLimiter
+	queue *latencyQueue
+}
+
+func (r *latencyRateLimiter) When(item interface{}) time.Duration {
+	d := r.RateLimiter.When(item)
+	r.queue.track(item, d)
+	return d
+}
+
+func (q *latencyQueue) Add(item interface{}) {
+	q.track(item, 0)
+	q.RateLimitingInterface.Add(item)
+}
+
+func (q *latencyQueue) AddAfter(item interface{}, d time.Duration) {
+	q.track(item, d)
+	q.RateLimitingInterface.AddAfter(item, d)
+}
+
+func (q *latencyQueue) Get() (interface{}, bool) {
+	item, shutdown := q.RateLimitingInterface.Get()
+	if !shutdown {
+		q.observe(item)
+	}
+	return item, shutdown
+}
+
+// track notes that item is due after d.
+func (q *latencyQueue) track(item interface{}, d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	now := q.clock.Now()
+	at := now.Add(d)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, t := range q.due[item] {
+		// The queue holds each key once, so a key already due is only
+		// handed to the reconciler once, however many times it is added.
+		if !t.After(now) {
+			return
+		}
+	}
+	q.due[item] = append(q.due[item], at)
+}
+
+// observe records the latency of item, handed to the reconciler now, since
+// the earliest time it was due at.
+func (q *latencyQueue) observe(item interface{}) {
+	now := q.clock.Now()
+	q.mu.Lock()
+	var earliest time.Time
+	var pending []time.Time
+	for _, t := range q.due[item] {
+		if t.After(now) {
+			pending = append(pending, t)
+		} else if earliest.IsZero() || t.Before(earliest) {
+			earliest = t
+		}
+	}
+	if len(pending) == 0 {
+		delete(q.due, item)
+	} else {
+		q.due[item] = pending
+	}
+	q.mu.Unlock()
+
+	if !earliest.IsZero() {
+		metrics.Record(q.ctx, queueLatency.M(now.Sub(earliest).Seconds()))
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+type nopReconciler struct{}
+
+func (nopReconciler) Reconcile(context.Context, string) error { return nil }
+
+func TestTrackQueueLatency(t *testing.T) {
+	defer metricstest.Unregister("reconcile_queue_latency_seconds")
+	c := clock.NewFakeClock(time.Date(2019, time.October, 1, 12, 0, 0, 0, time.UTC))
+	impl := controller.NewImpl(nopReconciler{}, logtesting.TestLogger(t), "TaskRunExpiration")
+	if err := TrackQueueLatency(impl, "TaskRunExpiration", "TaskRun", c); err != nil {
+		t.Fatalf("TrackQueueLatency() = %v", err)
+	}
+	q := impl.WorkQueue
+	defer q.ShutDown()
+
+	get := func(want string) {
+		t.Helper()
+		item, shutdown := q.Get()
+		if shutdown || item != want {
+			t.Fatalf("Get() = %v, %t, want %s", item, shutdown, want)
+		}
+		q.Done(item)
+	}
+
+	// Added twice before being handed to the reconciler, its latency is
+	// the one since the first time.
+	impl.EnqueueKey("foo/added")
+	c.Step(time.Second)
+	impl.EnqueueKey("foo/added")
+	c.Step(time.Second)
+	get("foo/added")
+
+	// Delayed by a minute, its latency only starts once the minute elapsed.
+	q.(*latencyQueue).track("foo/delayed", time.Minute)
+	c.Step(90 * time.Second)
+	impl.EnqueueKey("foo/delayed")
+	get("foo/delayed")
+
+	metricstest.CheckDistributionData(t, "reconcile_queue_latency_seconds", map[string]string{
+		"reconciler": "TaskRunExpiration",
+		"kind":       "TaskRun",
+	}, 2, 2, 30)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// ReasonQuotaExceeded indicates that the run waits for the PipelineQuotas
+	// of its namespace to free up before starting
+	ReasonQuotaExceeded = "QuotaExceeded"
+
+	// QuotaRecheckInterval is how often a run over the concurrency limit of
+	// a PipelineQuota checks whether it fits in again.
+	QuotaRecheckInterval = 30 * time.Second
+)
+
+// QuotaRun is a run counted against the PipelineQuotas of its namespace.
+type QuotaRun struct {
+	Kind              string
+	Name              string
+	CreationTimestamp metav1.Time
+	Labels            map[string]string
+	StartTime         *metav1.Time
+	Done              bool
+	// Queued is whether the run waits for the quotas to start, rather than
+	// e.g. for its execution window.
+	Queued bool
+}
+
+// PipelineRunQuotaRun returns pr as a run counted against the quotas.
+func PipelineRunQuotaRun(pr *v1alpha1.PipelineRun) QuotaRun {
+	return newQuotaRun("PipelineRun", pr.ObjectMeta, pr.Status.StartTime, pr.IsDone(), pr.IsCancelled(), pr.Status.GetCondition(apis.ConditionSucceeded))
+}
+
+// TaskRunQuotaRun returns tr as a run counted against the quotas.
+func TaskRunQuotaRun(tr *v1alpha1.TaskRun) QuotaRun {
+	return newQuotaRun("TaskRun", tr.ObjectMeta, tr.Status.StartTime, tr.IsDone(), tr.IsCancelled(), tr.Status.GetCondition(apis.ConditionSucceeded))
+}
+
+func newQuotaRun(kind string, meta metav1.ObjectMeta, startTime *metav1.Time, done, cancelled bool, succeeded *apis.Condition) QuotaRun {
+	waitingForWindow := succeeded != nil && succeeded.Reason == ReasonWaitingForExecutionWindow
+	return QuotaRun{
+		Kind:              kind,
+		Name:              meta.Name,
+		CreationTimestamp: meta.CreationTimestamp,
+		Labels:            meta.Labels,
+		StartTime:         startTime,
+		Done:              done,
+		Queued:            startTime == nil && !done && !cancelled && !waitingForWindow,
+	}
+}
+
+// before returns whether r was created before other, by name for the runs
+// created the same second.
+func (r QuotaRun) before(other QuotaRun) bool {
+	if !r.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return r.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+	if r.Kind != other.Kind {
+		return r.Kind < other.Kind
+	}
+	return r.Name < other.Name
+}
+
+// QuotaRuns returns the PipelineRuns of namespace, and its TaskRuns which
+// don't belong to a PipelineRun, which are counted against its
+// PipelineQuotas.
+func QuotaRuns(namespace string, pipelineRuns listers.PipelineRunLister, taskRuns listers.TaskRunLister) ([]QuotaRun, error) {
+	prs, err := pipelineRuns.PipelineRuns(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	trs, err := taskRuns.TaskRuns(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]QuotaRun, 0, len(prs)+len(trs))
+	for _, pr := range prs {
+		runs = append(runs, PipelineRunQuotaRun(pr))
+	}
+	for _, tr := range trs {
+		if owner := metav1.GetControllerOf(tr); owner != nil && owner.Kind == "PipelineRun" {
+			continue
+		}
+		runs = append(runs, TaskRunQuotaRun(tr))
+	}
+	return runs, nil
+}
+
+// WaitForQuotas returns how long run, which hasn't started yet, must wait at
+// now for the quotas selecting it to free up, and marks s accordingly. It
+// returns 0 if the run fits in all of them. The queued runs start in the
+// order they were created: the ones created before run take up the quotas
+// first, whether or not the cache shows them started yet, so that a burst of
+// runs created together doesn't exceed the quotas.
+func WaitForQuotas(s status.ConditionAccessor, quotas []*v1alpha1.PipelineQuota, run QuotaRun, runs []QuotaRun, now time.Time) time.Duration {
+	sorted := make([]*v1alpha1.PipelineQuota, len(quotas))
+	copy(sorted, quotas)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var wait time.Duration
+	var message string
+	for _, pq := range sorted {
+		selector := labels.Everything()
+		if pq.Spec.Selector != nil {
+			var err error
+			// Invalid selectors are rejected by the webhook, and select
+			// nothing.
+			if selector, err = metav1.LabelSelectorAsSelector(pq.Spec.Selector); err != nil {
+				continue
+			}
+		}
+		if !selector.Matches(labels.Set(run.Labels)) {
+			continue
+		}
+		qWait, qMessage := waitForQuota(pq, selector, run, runs, now)
+		if qWait > wait {
+			wait, message = qWait, qMessage
+		}
+	}
+	if wait > 0 {
+		status.MarkRunning(s, ReasonQuotaExceeded, "Waiting for quota: %s", message)
+	}
+	return wait
+}
+
+// waitForQuota returns how long run, selected by pq, must wait at now for it
+// to free up, and why.
+func waitForQuota(pq *v1alpha1.PipelineQuota, selector labels.Selector, run QuotaRun, runs []QuotaRun, now time.Time) (time.Duration, string) {
+	running, queuedAhead := 0, 0
+	var startedLastHour []time.Time
+	for _, r := range runs {
+		if !selector.Matches(labels.Set(r.Labels)) {
+			continue
+		}
+		if r.Queued && r.before(run) {
+			queuedAhead++
+			continue
+		}
+		if r.StartTime == nil {
+			continue
+		}
+		if !r.Done {
+			running++
+		}
+		if start := r.StartTime.Time; now.Sub(start) < time.Hour {
+			startedLastHour = append(startedLastHour, start)
+		}
+	}
+	var ahead string
+	if queuedAhead > 0 {
+		ahead = fmt.Sprintf(", %d created before are waiting", queuedAhead)
+	}
+	if max := pq.Spec.MaxConcurrentRuns; max != nil && int32(running+queuedAhead) >= *max {
+		return QuotaRecheckInterval, fmt.Sprintf("PipelineQuota %q allows %d concurrent runs, %d are running%s", pq.Name, *max, running, ahead)
+	}
+	if max := pq.Spec.MaxRunsPerHour; max != nil && int32(len(startedLastHour)+queuedAhead) >= *max {
+		// The run fits in once enough of the runs which started in the
+		// last hour started more than an hour ago, for it and the runs
+		// queued ahead of it to start.
+		wait := QuotaRecheckInterval
+		sort.Slice(startedLastHour, func(i, j int) bool { return startedLastHour[i].Before(startedLastHour[j]) })
+		if i := len(startedLastHour) + queuedAhead - int(*max); *max > 0 && i < len(startedLastHour) {
+			if w := startedLastHour[i].Add(time.Hour).Sub(now); w > 0 {
+				wait = w
+			}
+		}
+		return wait, fmt.Sprintf("PipelineQuota %q allows %d runs per hour, %d started in the last hour%s", pq.Name, *max, len(startedLastHour), ahead)
+	}
+	return 0, ""
+}
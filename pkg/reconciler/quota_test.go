@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestWaitForQuotas(t *testing.T) {
+	now := time.Date(2019, time.November, 16, 10, 30, 0, 0, time.UTC)
+	started := func(ago time.Duration, done bool, labels map[string]string) QuotaRun {
+		return QuotaRun{Labels: labels, StartTime: &metav1.Time{Time: now.Add(-ago)}, Done: done}
+	}
+	quota := func(name string, selector map[string]string, maxConcurrent, maxPerHour int32) *v1alpha1.PipelineQuota {
+		pq := &v1alpha1.PipelineQuota{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if selector != nil {
+			pq.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+		}
+		if maxConcurrent >= 0 {
+			pq.Spec.MaxConcurrentRuns = &maxConcurrent
+		}
+		if maxPerHour >= 0 {
+			pq.Spec.MaxRunsPerHour = &maxPerHour
+		}
+		return pq
+	}
+	team := map[string]string{"team": "a"}
+	queued := func(name string, ago time.Duration, labels map[string]string) QuotaRun {
+		return QuotaRun{Kind: "PipelineRun", Name: name, CreationTimestamp: metav1.NewTime(now.Add(-ago)), Labels: labels, Queued: true}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		quotas      []*v1alpha1.PipelineQuota
+		labels      map[string]string
+		runs        []QuotaRun
+		wantWait    time.Duration
+		wantMessage string
+	}{{
+		name:   "no quotas",
+		runs:   []QuotaRun{started(time.Minute, false, nil)},
+		labels: team,
+	}, {
+		name:   "under concurrency limit",
+		quotas: []*v1alpha1.PipelineQuota{quota("q", nil, 2, -1)},
+		runs:   []QuotaRun{started(time.Minute, false, nil), started(time.Minute, true, nil), {}},
+	}, {
+		name:        "at concurrency limit",
+		quotas:      []*v1alpha1.PipelineQuota{quota("q", nil, 2, -1)},
+		runs:        []QuotaRun{started(time.Minute, false, nil), started(2*time.Minute, false, nil)},
+		wantWait:    QuotaRecheckInterval,
+		wantMessage: `Waiting for quota: PipelineQuota "q" allows 2 concurrent runs, 2 are running`,
+	}, {
+		name:   "selector doesn't match the run",
+		quotas: []*v1alpha1.PipelineQuota{quota("q", team, 1, -1)},
+		runs:   []QuotaRun{started(time.Minute, false, team)},
+	}, {
+		name:   "selector doesn't match the running runs",
+		quotas: []*v1alpha1.PipelineQuota{quota("q", team, 1, -1)},
+		labels: team,
+		runs:   []QuotaRun{started(time.Minute, false, nil)},
+	}, {
+		name:   "under hourly limit",
+		quotas: []*v1alpha1.PipelineQuota{quota("q", nil, -1, 2)},
+		runs:   []QuotaRun{started(10*time.Minute, true, nil), started(2*time.Hour, true, nil)},
+	}, {
+		name:        "at hourly limit",
+		quotas:      []*v1alpha1.PipelineQuota{quota("q", nil, -1, 2)},
+		runs:        []QuotaRun{started(10*time.Minute, true, nil), started(40*time.Minute, true, nil), started(50*time.Minute, true, nil)},
+		wantWait:    20 * time.Minute,
+		wantMessage: `Waiting for quota: PipelineQuota "q" allows 2 runs per hour, 3 started in the last hour`,
+	}, {
+		name:        "no runs per hour",
+		quotas:      []*v1alpha1.PipelineQuota{quota("q", nil, -1, 0)},
+		wantWait:    QuotaRecheckInterval,
+		wantMessage: `Waiting for quota: PipelineQuota "q" allows 0 runs per hour, 0 started in the last hour`,
+	}, {
+		name:   "queued after the run",
+		quotas: []*v1alpha1.PipelineQuota{quota("q", nil, 1, -1)},
+		runs:   []QuotaRun{queued("later", -time.Second, nil), queued("run", 0, nil), queued("same-second", 0, nil)},
+	}, {
+		name:        "queued before the run",
+		quotas:      []*v1alpha1.PipelineQuota{quota("q", nil, 2, -1)},
+		runs:        []QuotaRun{started(time.Minute, false, nil), queued("earlier", time.Second, nil), queued("run", 0, nil)},
+		wantWait:    QuotaRecheckInterval,
+		wantMessage: `Waiting for quota: PipelineQuota "q" allows 2 concurrent runs, 1 are running, 1 created before are waiting`,
+	}, {
+		name:        "queued the same second before the run",
+		quotas:      []*v1alpha1.PipelineQuota{quota("q", nil, 1, -1)},
+		runs:        []QuotaRun{queued("a-run", 0, nil), queued("run", 0, nil)},
+		wantWait:    QuotaRecheckInterval,
+		wantMessage: `Waiting for quota: PipelineQuota "q" allows 1 concurrent runs, 0 are running, 1 created before are waiting`,
+	}, {
+		name:   "queued before the run not selected",
+		quotas: []*v1alpha1.PipelineQuota{quota("q", team, 1, -1)},
+		labels: team,
+		runs:   []QuotaRun{queued("earlier", time.Second, nil)},
+	}, {
+		name:        "queued before the run per hour",
+		quotas:      []*v1alpha1.PipelineQuota{quota("q", nil, -1, 2)},
+		runs:        []QuotaRun{started(20*time.Minute, true, nil), started(50*time.Minute, true, nil), queued("earlier", time.Second, nil)},
+		wantWait:    40 * time.Minute,
+		wantMessage: `Waiting for quota: PipelineQuota "q" allows 2 runs per hour, 2 started in the last hour, 1 created before are waiting`,
+	}, {
+		name:        "longest wait of the quotas",
+		quotas:      []*v1alpha1.PipelineQuota{quota("b", nil, -1, 1), quota("a", nil, 1, -1)},
+		runs:        []QuotaRun{started(10*time.Minute, false, nil)},
+		wantWait:    50 * time.Minute,
+		wantMessage: `Waiting for quota: PipelineQuota "b" allows 1 runs per hour, 1 started in the last hour`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &v1alpha1.PipelineRunStatus{}
+			run := QuotaRun{Kind: "PipelineRun", Name: "run", CreationTimestamp: metav1.NewTime(now), Labels: tc.labels, Queued: true}
+			if wait := WaitForQuotas(s, tc.quotas, run, tc.runs, now); wait != tc.wantWait {
+				t.Errorf("Expected to wait %s, got %s", tc.wantWait, wait)
+			}
+			cond := s.GetCondition(apis.ConditionSucceeded)
+			if tc.wantMessage == "" {
+				if cond != nil {
+					t.Errorf("Expected no condition, got %v", cond)
+				}
+				return
+			}
+			if cond == nil || cond.Status != corev1.ConditionUnknown || cond.Reason != ReasonQuotaExceeded || cond.Message != tc.wantMessage {
+				t.Errorf("Expected condition Unknown %s %q, got %v", ReasonQuotaExceeded, tc.wantMessage, cond)
+			}
+		})
+	}
+}
+
+// TestWaitForQuotasBurst admits a burst of runs created together, none of
+// which the cache shows started yet, in the order they were created.
+func TestWaitForQuotasBurst(t *testing.T) {
+	now := time.Date(2019, time.November, 16, 10, 30, 0, 0, time.UTC)
+	max := int32(2)
+	quotas := []*v1alpha1.PipelineQuota{{
+		ObjectMeta: metav1.ObjectMeta{Name: "q"},
+		Spec:       v1alpha1.PipelineQuotaSpec{MaxConcurrentRuns: &max},
+	}}
+	var runs []QuotaRun
+	for _, name := range []string{"e", "d", "c", "b", "a"} {
+		runs = append(runs, QuotaRun{Kind: "TaskRun", Name: name, CreationTimestamp: metav1.NewTime(now), Queued: true})
+	}
+	var admitted []string
+	for _, run := range runs {
+		if WaitForQuotas(&v1alpha1.TaskRunStatus{}, quotas, run, runs, now) == 0 {
+			admitted = append(admitted, run.Name)
+		}
+	}
+	if d := cmp.Diff([]string{"b", "a"}, admitted); d != "" {
+		t.Errorf("Admitted runs -want, +got: %s", d)
+	}
+}
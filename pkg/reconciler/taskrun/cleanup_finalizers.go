@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// HeldError is returned for the runs whose cleanup is held off by some of
+// the blocking finalizers of the cleanup config. The expiration controllers
+// check such runs again with a backoff, and as soon as they are updated.
+type HeldError struct {
+	Namespace  string
+	Name       string
+	Finalizers []string
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("cleanup of %s/%s held off by finalizers %s", e.Namespace, e.Name, strings.Join(e.Finalizers, ", "))
+}
+
+// IsHeld returns whether err is a HeldError.
+func IsHeld(err error) bool {
+	_, ok := err.(*HeldError)
+	return ok
+}
+
+// CheckFinalizers returns a HeldError if run has some of the blocking
+// finalizers of cfg, nil otherwise.
+func CheckFinalizers(cfg *config.Cleanup, run metav1.Object) error {
+	if len(cfg.BlockingFinalizers) == 0 {
+		return nil
+	}
+	blocking := sets.NewString(cfg.BlockingFinalizers...)
+	var held []string
+	for _, f := range run.GetFinalizers() {
+		if blocking.Has(f) {
+			held = append(held, f)
+		}
+	}
+	if len(held) == 0 {
+		return nil
+	}
+	return &HeldError{Namespace: run.GetNamespace(), Name: run.GetName(), Finalizers: held}
+}
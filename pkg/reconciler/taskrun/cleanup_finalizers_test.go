@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckFinalizers(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		blocking   []string
+		finalizers []string
+		want       []string
+	}{{
+		name:       "no blocking finalizers",
+		finalizers: []string{"chains.tekton.dev"},
+	}, {
+		name:     "no finalizers",
+		blocking: []string{"chains.tekton.dev"},
+	}, {
+		name:       "other finalizers",
+		blocking:   []string{"chains.tekton.dev"},
+		finalizers: []string{"example.com/audit"},
+	}, {
+		name:       "held",
+		blocking:   []string{"results.tekton.dev/archive", "chains.tekton.dev"},
+		finalizers: []string{"example.com/audit", "chains.tekton.dev"},
+		want:       []string{"chains.tekton.dev"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			run := &metav1.ObjectMeta{Namespace: "foo", Name: "test-run", Finalizers: tc.finalizers}
+			err := CheckFinalizers(&config.Cleanup{BlockingFinalizers: tc.blocking}, run)
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if !IsHeld(err) {
+				t.Fatalf("Expected a HeldError, got %v", err)
+			}
+			if d := cmp.Diff(tc.want, err.(*HeldError).Finalizers); d != "" {
+				t.Errorf("Unexpected held finalizers: %s", d)
+			}
+		})
+	}
+}
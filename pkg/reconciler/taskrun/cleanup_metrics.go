@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+)
+
+var (
+	deletedRuns = stats.Float64(
+		"cleanup_deleted_runs_count",
+		"Number of finished runs cleaned up by the expiration controllers",
+		stats.UnitDimensionless)
+
+	deletionErrors = stats.Float64(
+		"cleanup_deletion_errors_count",
+		"Number of failed deletions of finished runs by the expiration controllers",
+		stats.UnitDimensionless)
+
+	pendingExpirations = stats.Int64(
+		"cleanup_pending_expirations",
+		"Number of finished runs waiting for their TTL to elapse",
+		stats.UnitDimensionless)
+
+	cleanupLag = stats.Float64(
+		"cleanup_lag_seconds",
+		"Time between the expiry of the runs and their cleanup, in seconds",
+		stats.UnitDimensionless)
+	cleanupLagDistribution = view.Distribution(1, 5, 10, 30, 60, 300, 900, 1800, 3600, 21600, 86400)
+
+	cleanupReasonKey = tag.MustNewKey("reason")
+)
+
+// CleanupMetrics records the metrics of an expiration controller, so that
+// operators can alert on runs piling up, or being cleaned up late.
+type CleanupMetrics struct {
+	ctx    context.Context
+	logger *zap.SugaredLogger
+
+	mu sync.Mutex
+	// pending are the keys of the runs waiting for their TTL to elapse.
+	pending map[string]struct{}
+}
+
+// NewCleanupMetrics returns the CleanupMetrics of the expiration controller
+// of the runs of the given kind.
+func NewCleanupMetrics(kind string, logger *zap.SugaredLogger) *CleanupMetrics {
+	// Registering the same views again, for the other controller, is a
+	// no-op.
+	if err := view.Register(&view.View{
+		Description: deletedRuns.Description(),
+		Measure:     deletedRuns,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{runKindKey, cleanupReasonKey},
+	}, &view.View{
+		Description: deletionErrors.Description(),
+		Measure:     deletionErrors,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{runKindKey},
+	}, &view.View{
+		Description: pendingExpirations.Description(),
+		Measure:     pendingExpirations,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{runKindKey},
+	}, &view.View{
+		Description: cleanupLag.Description(),
+		Measure:     cleanupLag,
+		Aggregation: cleanupLagDistribution,
+		TagKeys:     []tag.Key{runKindKey, cleanupReasonKey},
+	}); err != nil {
+		logger.Errorf("Failed to register the cleanup views: %v", err)
+	}
+	ctx, err := tag.New(context.Background(), tag.Insert(runKindKey, kind))
+	if err != nil {
+		logger.Errorf("Failed to tag the cleanup measures: %v", err)
+		ctx = context.Background()
+	}
+	return &CleanupMetrics{ctx: ctx, logger: logger, pending: map[string]struct{}{}}
+}
+
+// Cleaned records that a run was cleaned up for reason, lag after it
+// expired. A zero lag isn't recorded, e.g. for the runs beyond the history
+// limit of their CleanupPolicy, which never expired.
+func (m *CleanupMetrics) Cleaned(reason string, lag time.Duration) {
+	ctx, err := tag.New(m.ctx, tag.Insert(cleanupReasonKey, reason))
+	if err != nil {
+		m.logger.Errorf("Failed to tag the cleanup measures: %v", err)
+		return
+	}
+	stats.Record(ctx, deletedRuns.M(1))
+	if lag > 0 {
+		stats.Record(ctx, cleanupLag.M(lag.Seconds()))
+	}
+}
+
+// DeletionFailed records that a run couldn't be deleted.
+func (m *CleanupMetrics) DeletionFailed() {
+	stats.Record(m.ctx, deletionErrors.M(1))
+}
+
+// SetPending records whether the run with the given key is waiting for its
+// TTL to elapse.
+func (m *CleanupMetrics) SetPending(key string, pending bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, was := m.pending[key]
+	if was == pending {
+		return
+	}
+	if pending {
+		m.pending[key] = struct{}{}
+	} else {
+		delete(m.pending, key)
+	}
+	stats.Record(m.ctx, pendingExpirations.M(int64(len(m.pending))))
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"testing"
+	"time"
+
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+var cleanupViews = []string{
+	"cleanup_deleted_runs_count",
+	"cleanup_deletion_errors_count",
+	"cleanup_pending_expirations",
+	"cleanup_lag_seconds",
+}
+
+func TestCleanupMetrics(t *testing.T) {
+	// The expiration controllers of the other tests may have recorded some.
+	metricstest.Unregister(cleanupViews...)
+	defer metricstest.Unregister(cleanupViews...)
+	m := NewCleanupMetrics("TaskRun", logtesting.TestLogger(t))
+
+	m.SetPending("foo/a", true)
+	m.SetPending("foo/b", true)
+	// Setting the same run pending again doesn't count it twice.
+	m.SetPending("foo/a", true)
+	metricstest.CheckLastValueData(t, "cleanup_pending_expirations", map[string]string{"kind": "TaskRun"}, 2)
+	m.SetPending("foo/a", false)
+	m.SetPending("foo/c", false)
+	metricstest.CheckLastValueData(t, "cleanup_pending_expirations", map[string]string{"kind": "TaskRun"}, 1)
+
+	m.Cleaned(ReasonTTLExpired, 30*time.Second)
+	m.Cleaned(ReasonTTLExpired, 2*time.Minute)
+	metricstest.CheckCountData(t, "cleanup_deleted_runs_count", map[string]string{"kind": "TaskRun", "reason": ReasonTTLExpired}, 2)
+	metricstest.CheckDistributionData(t, "cleanup_lag_seconds", map[string]string{"kind": "TaskRun", "reason": ReasonTTLExpired}, 2, 30, 120)
+
+	m.DeletionFailed()
+	metricstest.CheckCountData(t, "cleanup_deletion_errors_count", map[string]string{"kind": "TaskRun"}, 1)
+}
+
+func TestCleanupMetricsHistoryLimit(t *testing.T) {
+	metricstest.Unregister(cleanupViews...)
+	defer metricstest.Unregister(cleanupViews...)
+	m := NewCleanupMetrics("PipelineRun", logtesting.TestLogger(t))
+
+	// The runs beyond the history limit never expired, so they have no lag.
+	m.Cleaned(ReasonHistoryLimitExceeded, 0)
+	metricstest.CheckCountData(t, "cleanup_deleted_runs_count", map[string]string{"kind": "PipelineRun", "reason": ReasonHistoryLimitExceeded}, 1)
+	metricstest.CheckStatsNotReported(t, "cleanup_lag_seconds")
+}
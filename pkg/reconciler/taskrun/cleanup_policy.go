@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+)
+
+// CleanupPolicyFor returns the one of policies which applies to obj: the
+// first, by name, of the ones of its namespace selecting it, or nil if none
+// does.
+func CleanupPolicyFor(policies []*v1alpha1.CleanupPolicy, obj metav1.Object) *v1alpha1.CleanupPolicy {
+	sorted := make([]*v1alpha1.CleanupPolicy, len(policies))
+	copy(sorted, policies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, cp := range sorted {
+		if cp.Namespace != obj.GetNamespace() {
+			continue
+		}
+		if cp.Spec.Selector == nil {
+			return cp
+		}
+		// Invalid selectors are rejected by the webhook, and select nothing.
+		selector, err := metav1.LabelSelectorAsSelector(cp.Spec.Selector)
+		if err == nil && selector.Matches(labels.Set(obj.GetLabels())) {
+			return cp
+		}
+	}
+	return nil
+}
+
+// FinishedRun is a finished run counted against the history limit of a
+// CleanupPolicy.
+type FinishedRun struct {
+	Object     metav1.Object
+	FinishedAt time.Time
+}
+
+// OverHistoryLimit returns the runs beyond the limit, which are the ones
+// which finished first.
+func OverHistoryLimit(runs []FinishedRun, limit int32) []FinishedRun {
+	if len(runs) <= int(limit) {
+		return nil
+	}
+	sorted := make([]FinishedRun, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].FinishedAt.After(sorted[j].FinishedAt) })
+	return sorted[limit:]
+}
+
+// HistoryKey identifies the history of the runs cp limits which finished
+// with the status of succeeded.
+func HistoryKey(cp *v1alpha1.CleanupPolicy, succeeded *apis.Condition) string {
+	return fmt.Sprintf("%s/%s/%s/%s", cp.Namespace, cp.Name, cp.UID, succeeded.Status)
+}
+
+// PrunedHistories remembers the runs kept, and the ones beyond the limit,
+// the last time each history was pruned, for the runs of a history not to
+// list all the others again each, e.g. when they are all resynced.
+type PrunedHistories struct {
+	mu        sync.Mutex
+	histories map[string]prunedHistory
+}
+
+type prunedHistory struct {
+	limit int32
+	kept  sets.String
+	over  sets.String
+}
+
+// NewPrunedHistories returns a PrunedHistories which remembers no history.
+func NewPrunedHistories() *PrunedHistories {
+	return &PrunedHistories{histories: map[string]prunedHistory{}}
+}
+
+// Lookup returns whether run was kept, or was beyond the limit, the last
+// time the history key was pruned. Neither is true if run finished since,
+// or if the limit is another one now. A run which was kept stays so until a
+// run which finished after it prunes the history again.
+func (h *PrunedHistories) Lookup(key string, limit int32, run metav1.Object) (kept, over bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	history, ok := h.histories[key]
+	if !ok || history.limit != limit {
+		return false, false
+	}
+	return history.kept.Has(runKey(run)), history.over.Has(runKey(run))
+}
+
+// Set records the runs of the history key, and the ones of them beyond
+// limit, as it is pruned.
+func (h *PrunedHistories) Set(key string, limit int32, runs, over []FinishedRun) {
+	history := prunedHistory{limit: limit, kept: sets.NewString(), over: sets.NewString()}
+	for _, run := range over {
+		history.over.Insert(runKey(run.Object))
+	}
+	for _, run := range runs {
+		if k := runKey(run.Object); !history.over.Has(k) {
+			history.kept.Insert(k)
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.histories[key] = history
+}
+
+// runKey tells apart the runs recreated with the same name.
+func runKey(run metav1.Object) string {
+	return run.GetName() + "/" + string(run.GetUID())
+}
+
+// withCleanupPolicy returns tr with the TTLs of cp, if cp applies to it and
+// tr has no TTL of its own but the default one of the cluster.
+func withCleanupPolicy(tr *v1alpha1.TaskRun, cp *v1alpha1.CleanupPolicy) *v1alpha1.TaskRun {
+	if cp == nil || tr.Spec.TTLSecondsAfterSucceeded != nil || tr.Spec.TTLSecondsAfterFailed != nil {
+		return tr
+	}
+	if tr.Spec.ExpirationSecondsTTL != nil && tr.Annotations[v1alpha1.TTLDefaultedAnnotationKey] != "true" {
+		return tr
+	}
+	tr = tr.DeepCopy()
+	tr.Spec.ExpirationSecondsTTL = cp.Spec.ExpirationSecondsTTL
+	tr.Spec.TTLSecondsAfterSucceeded = cp.Spec.TTLSecondsAfterSucceeded
+	tr.Spec.TTLSecondsAfterFailed = cp.Spec.TTLSecondsAfterFailed
+	return tr
+}
+
+// ownedByPipelineRun returns whether tr is one of the TaskRuns of a
+// PipelineRun, which are deleted along with it.
+func ownedByPipelineRun(tr *v1alpha1.TaskRun) bool {
+	owner := metav1.GetControllerOf(tr)
+	return owner != nil && owner.Kind == "PipelineRun"
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCleanupPolicyFor(t *testing.T) {
+	nightly := tb.CleanupPolicy("a-nightly", "foo", tb.CleanupPolicySelector(map[string]string{"trigger": "nightly"}))
+	everything := tb.CleanupPolicy("b-everything", "foo")
+	elsewhere := tb.CleanupPolicy("0-elsewhere", "bar")
+	policies := []*v1alpha1.CleanupPolicy{everything, elsewhere, nightly}
+
+	for _, tc := range []struct {
+		name string
+		tr   *v1alpha1.TaskRun
+		want *v1alpha1.CleanupPolicy
+	}{{
+		name: "first policy by name",
+		tr:   tb.TaskRun("test-taskrun", "foo", tb.TaskRunLabel("trigger", "nightly")),
+		want: nightly,
+	}, {
+		name: "not selected",
+		tr:   tb.TaskRun("test-taskrun", "foo", tb.TaskRunLabel("trigger", "push")),
+		want: everything,
+	}, {
+		name: "other namespace",
+		tr:   tb.TaskRun("test-taskrun", "baz"),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := cmp.Diff(tc.want, CleanupPolicyFor(policies, tc.tr)); d != "" {
+				t.Errorf("CleanupPolicyFor() diff -want, +got: %v", d)
+			}
+		})
+	}
+}
+
+func TestOverHistoryLimit(t *testing.T) {
+	run := func(name string, finishedAgo time.Duration) FinishedRun {
+		return FinishedRun{
+			Object:     &metav1.ObjectMeta{Name: name},
+			FinishedAt: testNow.Add(-finishedAgo),
+		}
+	}
+	runs := []FinishedRun{run("b", 2*time.Minute), run("a", 3*time.Minute), run("c", time.Minute)}
+
+	var got []string
+	for _, r := range OverHistoryLimit(runs, 1) {
+		got = append(got, r.Object.GetName())
+	}
+	if d := cmp.Diff([]string{"b", "a"}, got); d != "" {
+		t.Errorf("OverHistoryLimit() diff -want, +got: %v", d)
+	}
+	if over := OverHistoryLimit(runs, 3); len(over) != 0 {
+		t.Errorf("Expected no run over a limit of 3, got %v", over)
+	}
+}
+
+func TestPrunedHistories(t *testing.T) {
+	run := func(name, uid string) FinishedRun {
+		return FinishedRun{Object: &metav1.ObjectMeta{Name: name, UID: types.UID(uid)}, FinishedAt: testNow}
+	}
+	kept, over, recreated := run("kept", "1"), run("over", "2"), run("kept", "3")
+	h := NewPrunedHistories()
+	h.Set("foo/everything", 1, []FinishedRun{kept, over}, []FinishedRun{over})
+
+	for _, tc := range []struct {
+		name     string
+		limit    int32
+		run      FinishedRun
+		wantKept bool
+		wantOver bool
+	}{{
+		name:     "kept",
+		limit:    1,
+		run:      kept,
+		wantKept: true,
+	}, {
+		name:     "beyond the limit",
+		limit:    1,
+		run:      over,
+		wantOver: true,
+	}, {
+		name:  "finished since",
+		limit: 1,
+		run:   run("new", "4"),
+	}, {
+		name:  "recreated with the same name",
+		limit: 1,
+		run:   recreated,
+	}, {
+		name:  "other limit",
+		limit: 2,
+		run:   over,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			gotKept, gotOver := h.Lookup("foo/everything", tc.limit, tc.run.Object)
+			if gotKept != tc.wantKept || gotOver != tc.wantOver {
+				t.Errorf("Lookup() = %t, %t, want %t, %t", gotKept, gotOver, tc.wantKept, tc.wantOver)
+			}
+		})
+	}
+}
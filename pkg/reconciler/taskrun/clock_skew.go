@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+var skewedRuns = stats.Float64(
+	"cleanup_clock_skew_count",
+	"Number of times the TTL of a run wasn't computed because it finished further in the future than the allowed-clock-skew",
+	stats.UnitDimensionless)
+
+// ClockSkewCheck rejects the finish times of runs too far in the future to
+// compute their TTL from, which happen when the clock of the node which
+// reported them is ahead of the one of the controller.
+type ClockSkewCheck struct {
+	ctx  context.Context
+	kind string
+}
+
+// NewClockSkewCheck returns a ClockSkewCheck counting the rejected finish
+// times of the runs of the given kind.
+func NewClockSkewCheck(kind string, logger *zap.SugaredLogger) *ClockSkewCheck {
+	// Registering the same view again, for the other controller, is a no-op.
+	if err := view.Register(&view.View{
+		Description: skewedRuns.Description(),
+		Measure:     skewedRuns,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{runKindKey},
+	}); err != nil {
+		logger.Errorf("Failed to register the %s view: %v", skewedRuns.Name(), err)
+	}
+	ctx, err := tag.New(context.Background(), tag.Insert(runKindKey, kind))
+	if err != nil {
+		logger.Errorf("Failed to tag the %s measure: %v", skewedRuns.Name(), err)
+		ctx = context.Background()
+	}
+	return &ClockSkewCheck{ctx: ctx, kind: kind}
+}
+
+// Check returns an error, and counts it, if run finished later than the
+// allowed clock skew of cfg after now. Since its TTL would be computed from
+// a finish time which may never come, the run should be checked again with
+// a backoff instead, until the clocks agree.
+func (s *ClockSkewCheck) Check(cfg *config.Cleanup, run Expirable, now time.Time) error {
+	finishedAt, err := FinishTime(run)
+	if err != nil {
+		return err
+	}
+	if skew := finishedAt.Sub(now); skew > cfg.AllowedClockSkew {
+		stats.Record(s.ctx, skewedRuns.M(1))
+		return xerrors.Errorf("%s %s/%s finished %s in the future, more than the allowed clock skew of %s",
+			s.kind, run.GetNamespace(), run.GetName(), skew, cfg.AllowedClockSkew)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/system"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ClusterTaskAccessVerb is the verb the creators of the TaskRuns, or of
+// their PipelineRuns, must be allowed on the ClusterTasks those reference
+// when ClusterTask access reviews are enabled.
+const ClusterTaskAccessVerb = "use"
+
+// reviewClusterTaskAccess returns why the user on behalf of whom tr runs
+// isn't allowed to use ClusterTask name, or an empty string if they are.
+func (c *Reconciler) reviewClusterTaskAccess(tr *v1alpha1.TaskRun, name string) (string, error) {
+	user, groups, err := c.runCreator(tr)
+	if err != nil {
+		return "", err
+	}
+	if user == "" {
+		return fmt.Sprintf("the user who created TaskRun %s isn't known, so its access to ClusterTask %s can't be reviewed", tr.Name, name), nil
+	}
+	review, err := c.KubeClientSet.AuthorizationV1().SubjectAccessReviews().Create(&authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user,
+			Groups: groups,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:     ClusterTaskAccessVerb,
+				Group:    pipeline.GroupName,
+				Resource: "clustertasks",
+				Name:     name,
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if review.Status.Allowed {
+		return "", nil
+	}
+	msg := fmt.Sprintf("%s isn't allowed to %s ClusterTask %s", user, ClusterTaskAccessVerb, name)
+	if review.Status.Reason != "" {
+		msg = fmt.Sprintf("%s: %s", msg, review.Status.Reason)
+	}
+	return msg, nil
+}
+
+// runCreator returns the user on behalf of whom tr runs, and their groups:
+// the creator of its PipelineRun if the controller created tr for it, the
+// creator of tr otherwise.
+func (c *Reconciler) runCreator(tr *v1alpha1.TaskRun) (string, []string, error) {
+	user, groups := v1alpha1.Creator(tr)
+	// Only the TaskRuns created by the service accounts of the controller
+	// act on behalf of their PipelineRun, otherwise anyone could borrow the
+	// access of the creator of a PipelineRun by setting it as owner.
+	if !strings.HasPrefix(user, "system:serviceaccount:"+system.GetNamespace()+":") {
+		return user, groups, nil
+	}
+	for _, ref := range tr.OwnerReferences {
+		if ref.Kind != "PipelineRun" {
+			continue
+		}
+		pr, err := c.pipelineRunLister.PipelineRuns(tr.Namespace).Get(ref.Name)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return "", nil, err
+		}
+		if pr.UID == ref.UID {
+			user, groups := v1alpha1.Creator(pr)
+			return user, groups, nil
+		}
+	}
+	return user, groups, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/entrypoint"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources/cloudevent"
+	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	"github.com/tektoncd/pipeline/pkg/status"
+	"github.com/tektoncd/pipeline/pkg/system"
+	test "github.com/tektoncd/pipeline/test"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ktesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/configmap"
+)
+
+func TestReconcileClusterTaskAccessReview(t *testing.T) {
+	controllerSA := "system:serviceaccount:" + system.GetNamespace() + ":tekton-pipelines-controller"
+	pr := tb.PipelineRun("test-pipelinerun", "foo",
+		tb.PipelineRunAnnotation(v1alpha1.CreatorAnnotationKey, "alice"),
+		tb.PipelineRunAnnotation(v1alpha1.CreatorGroupsAnnotationKey, "dev,system:authenticated"),
+	)
+	pr.UID = types.UID("pipelinerun-uid")
+	ownedBy := func(uid types.UID) tb.TaskRunOp {
+		return tb.TaskRunOwnerReference("PipelineRun", pr.Name, tb.OwnerReferenceUID(uid))
+	}
+	for _, tc := range []struct {
+		name string
+		ops  []tb.TaskRunOp
+		// allowed are the users allowed to use the ClusterTask.
+		allowed    []string
+		wantUser   string
+		wantGroups []string
+		wantDenied bool
+	}{{
+		name:     "creator allowed",
+		ops:      []tb.TaskRunOp{tb.TaskRunAnnotation(v1alpha1.CreatorAnnotationKey, "bob")},
+		allowed:  []string{"bob"},
+		wantUser: "bob",
+	}, {
+		name:       "creator denied",
+		ops:        []tb.TaskRunOp{tb.TaskRunAnnotation(v1alpha1.CreatorAnnotationKey, "bob")},
+		wantUser:   "bob",
+		wantDenied: true,
+	}, {
+		name:       "unknown creator",
+		wantDenied: true,
+	}, {
+		name: "created by the controller for a pipelinerun",
+		ops: []tb.TaskRunOp{
+			tb.TaskRunAnnotation(v1alpha1.CreatorAnnotationKey, controllerSA),
+			ownedBy(pr.UID),
+		},
+		allowed:    []string{"alice"},
+		wantUser:   "alice",
+		wantGroups: []string{"dev", "system:authenticated"},
+	}, {
+		name: "created by someone else for a pipelinerun",
+		ops: []tb.TaskRunOp{
+			tb.TaskRunAnnotation(v1alpha1.CreatorAnnotationKey, "bob"),
+			ownedBy(pr.UID),
+		},
+		allowed:    []string{"alice"},
+		wantUser:   "bob",
+		wantDenied: true,
+	}, {
+		name: "owned by a former pipelinerun",
+		ops: []tb.TaskRunOp{
+			tb.TaskRunAnnotation(v1alpha1.CreatorAnnotationKey, controllerSA),
+			ownedBy("former-uid"),
+		},
+		allowed:    []string{"alice"},
+		wantUser:   controllerSA,
+		wantDenied: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer unregisterMetrics()
+			ops := append([]tb.TaskRunOp{tb.TaskRunSpec(tb.TaskRunTaskRef(clustertask.Name, tb.TaskRefKind(v1alpha1.ClusterTaskKind)))}, tc.ops...)
+			tr := tb.TaskRun("test-taskrun", "foo", ops...)
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			entrypointCache, _ = entrypoint.NewCache()
+			c, _ := test.SeedTestData(t, ctx, test.Data{
+				TaskRuns:     []*v1alpha1.TaskRun{tr},
+				ClusterTasks: []*v1alpha1.ClusterTask{clustertask},
+				PipelineRuns: []*v1alpha1.PipelineRun{pr},
+			})
+			var reviews []authorizationv1.SubjectAccessReviewSpec
+			c.Kube.PrependReactor("create", "subjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+				review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				reviews = append(reviews, review.Spec)
+				for _, u := range tc.allowed {
+					review.Status.Allowed = review.Status.Allowed || u == review.Spec.User
+				}
+				return true, review, nil
+			})
+			impl := NewController(images, reconciler.WithClusterTaskAccessReview())(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+
+			if err := impl.Reconciler.Reconcile(context.Background(), "foo/test-taskrun"); err != nil {
+				t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+			}
+			got, err := c.Pipeline.TektonV1alpha1().TaskRuns("foo").Get("test-taskrun", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Couldn't get the TaskRun: %v", err)
+			}
+			cond := got.Status.GetCondition(apis.ConditionSucceeded)
+			if denied := cond.IsFalse() && cond.Reason == status.ReasonClusterTaskAccessDenied; denied != tc.wantDenied {
+				t.Errorf("Expected the TaskRun to be denied: %t, got condition %v", tc.wantDenied, cond)
+			}
+			if tc.wantUser == "" {
+				if len(reviews) != 0 {
+					t.Errorf("Expected no access review, got %v", reviews)
+				}
+				return
+			}
+			if len(reviews) != 1 {
+				t.Fatalf("Expected one access review, got %v", reviews)
+			}
+			r := reviews[0]
+			if r.User != tc.wantUser || strings.Join(r.Groups, ",") != strings.Join(tc.wantGroups, ",") {
+				t.Errorf("Expected the access of %s %v to be reviewed, got %s %v", tc.wantUser, tc.wantGroups, r.User, r.Groups)
+			}
+			if a := r.ResourceAttributes; a.Verb != ClusterTaskAccessVerb || a.Resource != "clustertasks" || a.Name != clustertask.Name {
+				t.Errorf("Expected the access to ClusterTask %s to be reviewed, got %v", clustertask.Name, a)
+			}
+		})
+	}
+}
@@ -20,18 +20,21 @@ import (
 	"context"
 	"time"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
-	pipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client"
 	clustertaskinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/clustertask"
+	pipelinequotainformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelinequota"
 	resourceinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelineresource"
+	pipelineruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelinerun"
+	stepactioninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/stepaction"
 	taskinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/task"
 	taskruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/taskrun"
 	"github.com/tektoncd/pipeline/pkg/reconciler"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/entrypoint"
 	cloudeventclient "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources/cloudevent"
 	"k8s.io/client-go/tools/cache"
-	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	nodeinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/node"
 	podinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -43,16 +46,20 @@ const (
 	resyncPeriod = 10 * time.Hour
 )
 
-func NewController(images pipeline.Images) func(context.Context, configmap.Watcher) *controller.Impl {
+// NewController returns a constructor for the TaskRun controller, with the
+// dependencies replaced by opts.
+func NewController(images pipeline.Images, opts ...reconciler.ControllerOption) func(context.Context, configmap.Watcher) *controller.Impl {
 	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
 		logger := logging.FromContext(ctx)
-		kubeclientset := kubeclient.Get(ctx)
-		pipelineclientset := pipelineclient.Get(ctx)
+		o := reconciler.NewControllerOptions(ctx, opts...)
+		kubeclientset := o.KubeClientSet
+		pipelineclientset := o.PipelineClientSet
 		taskRunInformer := taskruninformer.Get(ctx)
 		taskInformer := taskinformer.Get(ctx)
 		clusterTaskInformer := clustertaskinformer.Get(ctx)
 		podInformer := podinformer.Get(ctx)
 		resourceInformer := resourceinformer.Get(ctx)
+		stepActionInformer := stepactioninformer.Get(ctx)
 		timeoutHandler := reconciler.NewTimeoutHandler(ctx.Done(), logger)
 		metrics, err := NewRecorder()
 		if err != nil {
@@ -72,33 +79,62 @@ func NewController(images pipeline.Images) func(context.Context, configmap.Watch
 			taskRunLister:     taskRunInformer.Lister(),
 			taskLister:        taskInformer.Lister(),
 			clusterTaskLister: clusterTaskInformer.Lister(),
+			pipelineRunLister: pipelineruninformer.Get(ctx).Lister(),
 			resourceLister:    resourceInformer.Lister(),
+			stepActionLister:  stepActionInformer.Lister(),
+			quotaLister:       pipelinequotainformer.Get(ctx).Lister(),
+			nodeLister:        nodeinformer.Get(ctx).Lister(),
 			timeoutHandler:    timeoutHandler,
 			cloudEventClient:  cloudeventclient.Get(ctx),
 			metrics:           metrics,
+			clock:             o.Clock,
+
+			clusterTaskAccessReview: o.ClusterTaskAccessReview,
 		}
 		impl := controller.NewImpl(c, c.Logger, taskRunControllerName)
+		c.enqueueAfter = impl.EnqueueAfter
+		if err := reconciler.TrackQueueLatency(impl, taskRunControllerName, "TaskRun", o.Clock); err != nil {
+			logger.Errorf("Failed to track the queue latency of %s: %v", taskRunControllerName, err)
+		}
 
-		timeoutHandler.SetTaskRunCallbackFunc(impl.Enqueue)
+		c.configStore = o.ConfigStore(taskRunControllerName)
+		if c.configStore == nil {
+			c.configStore = config.NewStore(c.Logger.Named("config-store"))
+		}
+		c.configStore.WatchConfigs(opt.ConfigMapWatcher)
+
+		filter := o.FilterFunc()
+		timeoutHandler.SetTaskRunCallbackFunc(func(obj interface{}) {
+			if filter(obj) {
+				impl.Enqueue(obj)
+			}
+		})
 		timeoutHandler.CheckTimeouts(kubeclientset, pipelineclientset)
 
 		c.Logger.Info("Setting up event handlers")
-		taskRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-			AddFunc:    impl.Enqueue,
-			UpdateFunc: controller.PassNew(impl.Enqueue),
+		taskRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: filter,
+			Handler: reconciler.SkipCompletedRuns(cache.ResourceEventHandlerFuncs{
+				AddFunc:    impl.Enqueue,
+				UpdateFunc: controller.PassNew(impl.Enqueue),
+			}),
 		})
 
 		c.tracker = tracker.New(impl.EnqueueKey, controller.GetTrackerLease(ctx))
 
+		// The pods of the TaskRuns may get many status updates per second,
+		// e.g. while their steps log.
+		debouncer := reconciler.NewDebouncer(impl.EnqueueKeyAfter, o.DebounceInterval, o.Clock)
 		podInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
-			FilterFunc: controller.Filter(v1alpha1.SchemeGroupVersion.WithKind("TaskRun")),
-			Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+			FilterFunc: o.OwnerFilterFunc(v1alpha1.SchemeGroupVersion.WithKind("TaskRun"), func(namespace, name string) (interface{}, error) {
+				return taskRunInformer.Lister().TaskRuns(namespace).Get(name)
+			}),
+			Handler: controller.HandleAll(debouncer.EnqueueControllerOf),
 		})
 
-		// FIXME(vdemeester) it was never set
-		//entrypoint cache will be initialized by controller if not provided
+		// The entrypoint cache is initialized by the controller if not provided.
 		c.Logger.Info("Setting up Entrypoint cache")
-		c.cache = nil
+		c.cache = o.EntrypointCache
 		if c.cache == nil {
 			c.cache, _ = entrypoint.NewCache()
 		}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+var (
+	throttledDeletions = stats.Float64(
+		"cleanup_throttled_deletions_count",
+		"Number of deletions of expired runs postponed by the deletes-per-second or max-concurrent-deletions limits",
+		stats.UnitDimensionless)
+
+	runKindKey = tag.MustNewKey("kind")
+)
+
+const (
+	// concurrencyRetryDelay is how long a deletion postponed because too
+	// many were in flight waits before being tried again.
+	concurrencyRetryDelay = time.Second
+
+	// staleReservation is how long after its time a reservation is dropped,
+	// e.g. if its run was deleted meanwhile.
+	staleReservation = time.Minute
+)
+
+// DeletionThrottle limits the rate, and the concurrency, of the deletions of
+// an expiration controller, as set in the cleanup config, so that a backlog
+// of expired runs doesn't overload the API server. A throttled run reserves
+// the next free slot of the rate limit, so that a backlog is spread over
+// successive slots rather than retried all at once.
+type DeletionThrottle struct {
+	ctx context.Context
+
+	mu               sync.Mutex
+	deletesPerSecond float64
+	limiter          *rate.Limiter
+	// reserved holds the time of the slot reserved by each throttled run,
+	// by key.
+	reserved map[string]time.Time
+	inFlight int
+}
+
+// NewDeletionThrottle returns a DeletionThrottle counting the postponed
+// deletions of the runs of the given kind.
+func NewDeletionThrottle(kind string, logger *zap.SugaredLogger) *DeletionThrottle {
+	// Registering the same view again, for the other controller, is a no-op.
+	if err := view.Register(&view.View{
+		Description: throttledDeletions.Description(),
+		Measure:     throttledDeletions,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{runKindKey},
+	}); err != nil {
+		logger.Errorf("Failed to register the %s view: %v", throttledDeletions.Name(), err)
+	}
+	ctx, err := tag.New(context.Background(), tag.Insert(runKindKey, kind))
+	if err != nil {
+		logger.Errorf("Failed to tag the %s measure: %v", throttledDeletions.Name(), err)
+		ctx = context.Background()
+	}
+	return &DeletionThrottle{ctx: ctx, reserved: map[string]time.Time{}}
+}
+
+// Acquire returns a func to call once the deletion is done if the run with
+// the given key may be deleted at now according to cfg. Otherwise, it
+// counts the deletion as throttled and returns how long to wait before
+// trying again, until the slot reserved for the run if the rate is limited.
+func (t *DeletionThrottle) Acquire(key string, cfg *config.Cleanup, now time.Time) (func(), time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cfg.DeletesPerSecond != t.deletesPerSecond {
+		t.deletesPerSecond = cfg.DeletesPerSecond
+		t.limiter = nil
+		t.reserved = map[string]time.Time{}
+		if t.deletesPerSecond > 0 {
+			t.limiter = rate.NewLimiter(rate.Limit(t.deletesPerSecond), 1)
+		}
+	}
+	for k, at := range t.reserved {
+		if now.Sub(at) > staleReservation {
+			delete(t.reserved, k)
+		}
+	}
+
+	if cfg.MaxConcurrentDeletions > 0 && t.inFlight >= cfg.MaxConcurrentDeletions {
+		stats.Record(t.ctx, throttledDeletions.M(1))
+		return nil, concurrencyRetryDelay
+	}
+	if at, ok := t.reserved[key]; ok {
+		if at.After(now) {
+			stats.Record(t.ctx, throttledDeletions.M(1))
+			return nil, at.Sub(now)
+		}
+		delete(t.reserved, key)
+	} else if t.limiter != nil {
+		if delay := t.limiter.ReserveN(now, 1).DelayFrom(now); delay > 0 {
+			t.reserved[key] = now.Add(delay)
+			stats.Record(t.ctx, throttledDeletions.M(1))
+			return nil, delay
+		}
+	}
+	t.inFlight++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.inFlight--
+		})
+	}, 0
+}
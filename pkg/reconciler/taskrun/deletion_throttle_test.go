@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestDeletionThrottleConcurrency(t *testing.T) {
+	defer metricstest.Unregister("cleanup_throttled_deletions_count")
+	throttle := NewDeletionThrottle("TaskRun", logtesting.TestLogger(t))
+	cfg := &config.Cleanup{MaxConcurrentDeletions: 2}
+	now := time.Now()
+
+	first, _ := throttle.Acquire("foo/first", cfg, now)
+	second, _ := throttle.Acquire("foo/second", cfg, now)
+	if first == nil || second == nil {
+		t.Fatal("Expected the first two deletions not to be throttled")
+	}
+	if release, retryAfter := throttle.Acquire("foo/third", cfg, now); release != nil || retryAfter != concurrencyRetryDelay {
+		t.Errorf("Expected the third deletion to be retried after %s, got %s", concurrencyRetryDelay, retryAfter)
+	}
+	first()
+	// Releasing twice doesn't free another slot.
+	first()
+	if release, _ := throttle.Acquire("foo/third", cfg, now); release == nil {
+		t.Error("Expected a deletion not to be throttled once another one is done")
+	}
+	if release, _ := throttle.Acquire("foo/fourth", cfg, now); release != nil {
+		t.Error("Expected a deletion to be throttled while two are in flight")
+	}
+	// Lifting the limit applies to the deletions which follow.
+	if release, _ := throttle.Acquire("foo/fourth", &config.Cleanup{}, now); release == nil {
+		t.Error("Expected a deletion not to be throttled without limits")
+	}
+	metricstest.CheckCountData(t, "cleanup_throttled_deletions_count", map[string]string{"kind": "TaskRun"}, 2)
+}
+
+func TestDeletionThrottleRate(t *testing.T) {
+	defer metricstest.Unregister("cleanup_throttled_deletions_count")
+	throttle := NewDeletionThrottle("PipelineRun", logtesting.TestLogger(t))
+	cfg := &config.Cleanup{DeletesPerSecond: 0.1}
+	now := time.Now()
+
+	release, _ := throttle.Acquire("foo/first", cfg, now)
+	if release == nil {
+		t.Fatal("Expected the first deletion not to be throttled")
+	}
+	release()
+	// Each throttled run reserves its own slot, so their retries are spread.
+	for _, tc := range []struct {
+		key        string
+		retryAfter time.Duration
+	}{
+		{"foo/second", 10 * time.Second},
+		{"foo/third", 20 * time.Second},
+		// Trying again before its slot keeps the reservation.
+		{"foo/second", 10 * time.Second},
+	} {
+		if release, retryAfter := throttle.Acquire(tc.key, cfg, now); release != nil || retryAfter != tc.retryAfter {
+			t.Errorf("Expected the deletion of %s to be retried after %s, got %s", tc.key, tc.retryAfter, retryAfter)
+		}
+	}
+	if release, retryAfter := throttle.Acquire("foo/second", cfg, now.Add(5*time.Second)); release != nil || retryAfter != 5*time.Second {
+		t.Errorf("Expected the deletion of foo/second to be retried after 5s, got %s", retryAfter)
+	}
+	if release, _ := throttle.Acquire("foo/second", cfg, now.Add(10*time.Second)); release == nil {
+		t.Error("Expected the deletion of foo/second not to be throttled in its slot")
+	}
+	if release, retryAfter := throttle.Acquire("foo/fourth", cfg, now.Add(10*time.Second)); release != nil || retryAfter != 20*time.Second {
+		t.Errorf("Expected the deletion of foo/fourth to be retried after 20s, got %s", retryAfter)
+	}
+	metricstest.CheckCountData(t, "cleanup_throttled_deletions_count", map[string]string{"kind": "PipelineRun"}, 5)
+
+	// A new rate replaces the limiter, its tokens and its reservations.
+	if release, _ := throttle.Acquire("foo/third", &config.Cleanup{DeletesPerSecond: 1}, now.Add(10*time.Second)); release == nil {
+		t.Error("Expected a deletion not to be throttled once the rate changed")
+	}
+}
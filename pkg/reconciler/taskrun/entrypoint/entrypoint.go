@@ -19,6 +19,8 @@ package entrypoint
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/authn/k8schain"
@@ -42,8 +44,18 @@ const (
 	DownwardMountPoint     = "/builder/downward"
 	DownwardMountReadyFile = "ready"
 	binaryLocation         = mountPoint + "/entrypoint"
+	stepsDir               = mountPoint + "/steps"
 	InitContainerName      = "place-tools"
 	cacheSize              = 1024
+
+	windowsOS = "windows"
+
+	// InternalMountName is the name of the volume of the /tekton directory
+	// the steps write their progress and the results of their Task to.
+	InternalMountName = "tekton-internal"
+	// ProgressFile is the file the steps write their progress to.
+	ProgressFile       = internalMountPoint + "/progress"
+	internalMountPoint = "/tekton"
 )
 
 var (
@@ -55,20 +67,51 @@ var (
 		Name:      DownwardMountName,
 		MountPath: DownwardMountPoint,
 	}
+	internalMount = corev1.VolumeMount{
+		Name:      InternalMountName,
+		MountPath: internalMountPoint,
+	}
 )
 
+// UnresolvableEntrypointError is returned when a step doesn't specify a
+// command and none can be derived from the configuration of its image.
+type UnresolvableEntrypointError struct {
+	Image  string
+	Reason string
+}
+
+var _ error = (*UnresolvableEntrypointError)(nil)
+
+// Error implements error
+func (e *UnresolvableEntrypointError) Error() string {
+	return fmt.Sprintf("couldn't resolve the command to run for image %q: %s, the step must specify a command", e.Image, e.Reason)
+}
+
 // Cache is a simple caching mechanism allowing for caching the results of
-// getting the Entrypoint of a container image from a remote registry. The
-// internal lru cache is thread-safe.
+// getting the Entrypoint of a container image from a remote registry. It
+// also holds the digests tags were prefetched to, until they expire. The
+// internal lru caches are thread-safe.
 type Cache struct {
-	lru *lru.Cache
+	lru     *lru.Cache
+	digests *lru.Cache
+}
+
+// prefetchedDigest is the digest a tag was prefetched to, which is used
+// instead of resolving the tag again until it expires.
+type prefetchedDigest struct {
+	digest string
+	until  time.Time
 }
 
 // NewCache is a simple helper function that returns a pointer to a Cache that
-// has had the internal fixed-sized lru cache initialized.
+// has had the internal fixed-sized lru caches initialized.
 func NewCache() (*Cache, error) {
-	lru, err := lru.New(cacheSize)
-	return &Cache{lru}, err
+	c, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, err
+	}
+	digests, err := lru.New(cacheSize)
+	return &Cache{lru: c, digests: digests}, err
 }
 
 func (c *Cache) get(sha string) ([]string, bool) {
@@ -82,6 +125,20 @@ func (c *Cache) set(sha string, ep []string) {
 	c.lru.Add(sha, ep)
 }
 
+// getDigest returns the digest the tag ref was prefetched to for namespace,
+// unless it expired at now. Tags are prefetched per namespace so that a
+// namespace can't pin the tags the TaskRuns of others run.
+func (c *Cache) getDigest(namespace string, ref name.Reference, now time.Time) (string, bool) {
+	if d, ok := c.digests.Get(namespace + "/" + ref.Name()); ok && now.Before(d.(prefetchedDigest).until) {
+		return d.(prefetchedDigest).digest, true
+	}
+	return "", false
+}
+
+func (c *Cache) setDigest(namespace string, ref name.Reference, digest string, until time.Time) {
+	c.digests.Add(namespace+"/"+ref.Name(), prefetchedDigest{digest: digest, until: until})
+}
+
 // AddToEntrypointCache adds an image digest and its entrypoint
 // to the cache
 func AddToEntrypointCache(c *Cache, sha string, ep []string) {
@@ -105,34 +162,194 @@ func AddCopyStep(entrypointImage string, spec *v1alpha1.TaskSpec) {
 // RedirectSteps will modify each of the steps/containers such that
 // the binary being run is no longer the one specified by the Command
 // and the Args, but is instead the entrypoint binary, which will
-// itself invoke the Command and Args, but also capture logs.
-func RedirectSteps(cache *Cache, steps []v1alpha1.Step, kubeclient kubernetes.Interface, taskRun *v1alpha1.TaskRun, logger *zap.SugaredLogger) error {
+// itself invoke the Command and Args, but also capture logs. The images of
+// the steps without a Command are looked up in the mirror of their
+// registry in mirrors if they can't be in their own. The entrypoint of the
+// steps using $(steps.<name>.<field>) variables, and of the steps they
+// reference, shares their exit codes and results through the tools volume.
+func RedirectSteps(cache *Cache, steps []v1alpha1.Step, kubeclient kubernetes.Interface, taskRun *v1alpha1.TaskRun, mirrors map[string]string, logger *zap.SugaredLogger) error {
+	referencing := make([]bool, len(steps))
+	referenced := map[string]bool{}
+	for i, step := range steps {
+		for _, v := range stepVariables(step) {
+			referencing[i] = true
+			referenced[v.Step] = true
+		}
+	}
 	for i := range steps {
 		step := &steps[i]
-		if err := RedirectStep(cache, i, step, kubeclient, taskRun, logger); err != nil {
+		if err := RedirectStep(cache, i, step, kubeclient, taskRun, mirrors, logger); err != nil {
 			return err
 		}
+		var args []string
+		if referencing[i] || referenced[step.Name] {
+			args = append(args, "-steps_dir", stepsDir)
+		}
+		if step.Name != "" && referenced[step.Name] {
+			args = append(args, "-step_name", step.Name)
+		}
+		step.Args = append(args, step.Args...)
 	}
 
 	return nil
 }
 
+// SetWaitTimeouts makes the entrypoint of the first of the redirected steps
+// fail if the pod isn't ready after readyTimeout, and the one of the other
+// steps fail if the previous step didn't finish waitFileTimeout after it
+// started. Zero timeouts let them wait until the TaskRun times out.
+func SetWaitTimeouts(steps []v1alpha1.Step, readyTimeout, waitFileTimeout time.Duration) {
+	if readyTimeout > 0 && len(steps) > 0 {
+		steps[0].Args = append([]string{"-wait_file_timeout", readyTimeout.String()}, steps[0].Args...)
+	}
+	if waitFileTimeout <= 0 {
+		return
+	}
+	for i := 1; i < len(steps); i++ {
+		// All the containers start with the pod, so the timeout counts
+		// from the start file the previous step writes once it runs.
+		steps[i-1].Args = append([]string{"-start_file", getStartFile(i - 1)}, steps[i-1].Args...)
+		steps[i].Args = append([]string{"-wait_file_timeout", waitFileTimeout.String(), "-wait_start_file", getStartFile(i - 1)}, steps[i].Args...)
+	}
+}
+
+// SetProgress makes the entrypoint of the redirected steps of spec report
+// the progress they write to ProgressFile every interval, if it changed, in
+// their termination message. A zero interval doesn't report it.
+func SetProgress(spec *v1alpha1.TaskSpec, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	for i := range spec.Steps {
+		step := &spec.Steps[i]
+		step.Args = append([]string{"-progress_file", ProgressFile, "-progress_interval", interval.String(), "-progress_step", step.Name}, step.Args...)
+	}
+	mountInternal(spec)
+}
+
+// SetLogPrefixes makes the entrypoint of the redirected steps prefix each
+// line of their output with the time it was written at, if timestamps is
+// set, and with the name of the step, if stepNames is set. The unnamed steps
+// are named after their index, like their containers.
+func SetLogPrefixes(steps []v1alpha1.Step, timestamps, stepNames bool) {
+	for i := range steps {
+		var args []string
+		if timestamps {
+			args = append(args, "-log_timestamps")
+		}
+		if stepNames {
+			name := steps[i].Name
+			if name == "" {
+				name = fmt.Sprintf("unnamed-%d", i)
+			}
+			args = append(args, "-log_step_name", name)
+		}
+		steps[i].Args = append(args, steps[i].Args...)
+	}
+}
+
+// SetResults makes the entrypoint of the redirected steps of spec report
+// the results of the Task they write to v1alpha1.ResultsDir.
+func SetResults(spec *v1alpha1.TaskSpec) {
+	if len(spec.Results) == 0 {
+		return
+	}
+	names := make([]string, len(spec.Results))
+	for i, r := range spec.Results {
+		names[i] = r.Name
+	}
+	for i := range spec.Steps {
+		spec.Steps[i].Args = append([]string{"-results", strings.Join(names, ",")}, spec.Steps[i].Args...)
+	}
+	mountInternal(spec)
+}
+
+// SetWorkspaceChecksums makes the entrypoint of the first of the redirected
+// steps of spec report the checksums of the workspaces with Checksum set
+// before it runs, and the one of the last step once it ran, as their
+// checksum results.
+func SetWorkspaceChecksums(spec *v1alpha1.TaskSpec) {
+	var start, end []string
+	for _, w := range spec.Workspaces {
+		if !w.Checksum {
+			continue
+		}
+		startResult, endResult := w.ChecksumResults()
+		start = append(start, startResult+"="+w.GetMountPath())
+		end = append(end, endResult+"="+w.GetMountPath())
+	}
+	if len(start) == 0 || len(spec.Steps) == 0 {
+		return
+	}
+	first, last := &spec.Steps[0], &spec.Steps[len(spec.Steps)-1]
+	first.Args = append([]string{"-start_checksums", strings.Join(start, ",")}, first.Args...)
+	last.Args = append([]string{"-end_checksums", strings.Join(end, ",")}, last.Args...)
+}
+
+// mountInternal mounts the /tekton directory in the steps of spec, unless
+// it is already.
+func mountInternal(spec *v1alpha1.TaskSpec) {
+	for _, v := range spec.Volumes {
+		if v.Name == InternalMountName {
+			return
+		}
+	}
+	for i := range spec.Steps {
+		spec.Steps[i].VolumeMounts = append(spec.Steps[i].VolumeMounts, internalMount)
+	}
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name:         InternalMountName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+}
+
+// stepVariables returns the $(steps.<name>.<field>) variables in the
+// command, the args and the env of step, which the entrypoint replaces.
+func stepVariables(step v1alpha1.Step) []v1alpha1.StepVariable {
+	var vs []v1alpha1.StepVariable
+	for _, s := range append(append([]string{}, step.Command...), step.Args...) {
+		vs = append(vs, v1alpha1.StepVariables(s)...)
+	}
+	for _, env := range step.Env {
+		vs = append(vs, v1alpha1.StepVariables(env.Value)...)
+	}
+	return vs
+}
+
 // RedirectStep will modify a step/container such that
 // the binary being run is no longer the one specified by the Command
 // and the Args, but is instead the entrypoint binary, which will
 // itself invoke the Command and Args, but also capture logs.
-func RedirectStep(cache *Cache, stepNum int, step *v1alpha1.Step, kubeclient kubernetes.Interface, taskRun *v1alpha1.TaskRun, logger *zap.SugaredLogger) error {
+func RedirectStep(cache *Cache, stepNum int, step *v1alpha1.Step, kubeclient kubernetes.Interface, taskRun *v1alpha1.TaskRun, mirrors map[string]string, logger *zap.SugaredLogger) error {
 	if len(step.Command) == 0 {
 		logger.Infof("Getting Cmd from remote entrypoint for step: %s", step.Name)
-		var err error
-		step.Command, err = GetRemoteEntrypoint(cache, step.Image, kubeclient, taskRun)
+		command, pinned, err := getRemoteEntrypoint(cache, step.Image, kubeclient, taskRun, mirrors)
 		if err != nil {
 			logger.Errorf("Error getting entry point image", err.Error())
 			return err
 		}
+		step.Command = command
+		if pinned != "" {
+			// The tag may have moved since it was prefetched: run the
+			// digest whose entrypoint we found.
+			step.Image = pinned
+		}
 	}
 
 	step.Args = GetArgs(stepNum, step.Command, step.Args)
+	if len(step.SkipExitCodes) > 0 {
+		codes := make([]string, len(step.SkipExitCodes))
+		for i, code := range step.SkipExitCodes {
+			codes[i] = strconv.Itoa(int(code))
+		}
+		step.Args = append([]string{"-skip_exit_codes", strings.Join(codes, ",")}, step.Args...)
+	}
+	if step.OnError == v1alpha1.Continue {
+		step.Args = append([]string{"-on_error", string(step.OnError)}, step.Args...)
+	}
+	if step.Timeout != nil && step.Timeout.Duration > 0 {
+		step.Args = append([]string{"-timeout", step.Timeout.Duration.String()}, step.Args...)
+	}
 	step.Command = []string{binaryLocation}
 	step.VolumeMounts = append(step.VolumeMounts, toolsMount)
 	// The first step in a Task waits for the existence of a file projected into the Pod
@@ -177,53 +394,189 @@ func getWaitFile(stepNum int) string {
 	return fmt.Sprintf("%s/%s", mountPoint, strconv.Itoa(stepNum-1))
 }
 
+// getStartFile returns the file the step writes when it starts running.
+func getStartFile(stepNum int) string {
+	return fmt.Sprintf("%s/%d.start", mountPoint, stepNum)
+}
+
 // GetRemoteEntrypoint accepts a cache of digest lookups, as well as the digest
 // to look for. If the cache does not contain the digest, it will lookup the
-// metadata from the images registry, and then commit that to the cache
-func GetRemoteEntrypoint(cache *Cache, image string, kubeclient kubernetes.Interface, taskRun *v1alpha1.TaskRun) ([]string, error) {
+// metadata from the images registry, or from its mirror in mirrors, and then
+// commit that to the cache. How the metadata was fetched is recorded in the
+// status of taskRun.
+func GetRemoteEntrypoint(cache *Cache, image string, kubeclient kubernetes.Interface, taskRun *v1alpha1.TaskRun, mirrors map[string]string) ([]string, error) {
+	command, _, err := getRemoteEntrypoint(cache, image, kubeclient, taskRun, mirrors)
+	return command, err
+}
+
+// getRemoteEntrypoint is GetRemoteEntrypoint, which also returns the image
+// pinned to the digest a tag was prefetched to, if it was, so that the step
+// runs the image whose entrypoint was found rather than wherever the tag
+// moved since.
+func getRemoteEntrypoint(cache *Cache, image string, kubeclient kubernetes.Interface, taskRun *v1alpha1.TaskRun, mirrors map[string]string) ([]string, string, error) {
 	ref, err := name.ParseReference(image, name.WeakValidation)
 	if err != nil {
-		return nil, xerrors.Errorf("Failed to parse image %s: %w", image, err)
+		return nil, "", xerrors.Errorf("Failed to parse image %s: %w", image, err)
 	}
 
-	var digest string
+	var digest, pinned string
 	// If the image is specified as a digest, we can just take the digest from the name and use that in our cache.
-	// Otherwise we first have to resolve the tag to a digest.
+	// Otherwise we first have to resolve the tag to a digest, unless it was prefetched.
 	if d, ok := ref.(name.Digest); ok {
 		digest = d.String()
+	} else if d, ok := cache.getDigest(taskRun.Namespace, ref, time.Now()); ok {
+		digest = d
+		pinned = ref.Context().Name() + "@" + d
 	} else {
-		img, err := getRemoteImage(image, kubeclient, taskRun)
+		img, err := getRemoteImage(image, kubeclient, taskRun, mirrors)
 		if err != nil {
-			return nil, xerrors.Errorf("Failed to fetch remote image %s: %w", digest, err)
+			return nil, "", xerrors.Errorf("Failed to fetch remote image %s: %w", image, err)
 		}
 		d, err := img.Digest()
 		if err != nil {
-			return nil, xerrors.Errorf("Failed to get digest for image %s: %w", image, err)
+			return nil, "", xerrors.Errorf("Failed to get digest for image %s: %w", image, err)
 		}
 		digest = d.String()
 	}
 
 	if ep, ok := cache.get(digest); ok {
-		return ep, nil
+		return ep, pinned, nil
 	}
 
-	img, err := getRemoteImage(image, kubeclient, taskRun)
+	lookup := image
+	if pinned != "" {
+		lookup = pinned
+	}
+	img, err := getRemoteImage(lookup, kubeclient, taskRun, mirrors)
 	if err != nil {
-		return nil, xerrors.Errorf("Failed to fetch remote image %s: %w", digest, err)
+		return nil, "", xerrors.Errorf("Failed to fetch remote image %s: %w", digest, err)
 	}
 	cfg, err := img.ConfigFile()
 	if err != nil {
-		return nil, xerrors.Errorf("Failed to get config for image %s: %w", digest, err)
+		return nil, "", xerrors.Errorf("Failed to get config for image %s: %w", digest, err)
+	}
+	command, err := commandFromConfig(image, cfg)
+	if err != nil {
+		return nil, "", err
 	}
+	cache.set(digest, command)
+	return command, pinned, nil
+}
+
+// Prefetch resolves image to its digest and caches the entrypoint of that
+// digest, like GetRemoteEntrypoint, with the pull secrets of the service
+// account serviceAccount of namespace. If image is a tag, GetRemoteEntrypoint
+// then uses the digest it was resolved to for the TaskRuns of namespace
+// instead of resolving it again, until the time until. It returns the digest.
+func Prefetch(cache *Cache, image string, kubeclient kubernetes.Interface, namespace, serviceAccount string, mirrors map[string]string, until time.Time) (string, error) {
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return "", xerrors.Errorf("Failed to parse image %s: %w", image, err)
+	}
+	// Only the namespace and service account of the TaskRun are used to
+	// fetch the image, and its lookups aren't recorded anywhere.
+	taskRun := &v1alpha1.TaskRun{}
+	taskRun.Namespace = namespace
+	taskRun.Spec.ServiceAccountName = serviceAccount
+	img, err := getRemoteImage(image, kubeclient, taskRun, mirrors)
+	if err != nil {
+		return "", xerrors.Errorf("Failed to fetch remote image %s: %w", image, err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		return "", xerrors.Errorf("Failed to get digest for image %s: %w", image, err)
+	}
+	// Key the entrypoint like GetRemoteEntrypoint does.
+	digest, key := d.String(), d.String()
+	if r, ok := ref.(name.Digest); ok {
+		key = r.String()
+	}
+	if _, ok := cache.get(key); !ok {
+		cfg, err := img.ConfigFile()
+		if err != nil {
+			return "", xerrors.Errorf("Failed to get config for image %s: %w", digest, err)
+		}
+		command, err := commandFromConfig(image, cfg)
+		if err != nil {
+			return "", err
+		}
+		cache.set(key, command)
+	}
+	if _, ok := ref.(name.Tag); ok {
+		cache.setDigest(namespace, ref, digest, until)
+	}
+	return digest, nil
+}
+
+// Prefetched returns whether the tag image was prefetched for namespace to a
+// digest which doesn't expire before now, or the digest image has its
+// entrypoint cached.
+func Prefetched(cache *Cache, namespace, image string, now time.Time) bool {
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return false
+	}
+	if d, ok := ref.(name.Digest); ok {
+		_, ok := cache.get(d.String())
+		return ok
+	}
+	digest, ok := cache.getDigest(namespace, ref, now)
+	if !ok {
+		return false
+	}
+	_, ok = cache.get(digest)
+	return ok
+}
+
+// commandFromConfig returns the command the container runtime would run for
+// an image with the config cfg: its entrypoint or, if there is none, its cmd.
+func commandFromConfig(image string, cfg *v1.ConfigFile) ([]string, error) {
 	command := cfg.Config.Entrypoint
 	if len(command) == 0 {
 		command = cfg.Config.Cmd
 	}
-	cache.set(digest, command)
+	if len(command) == 0 {
+		if len(cfg.Config.OnBuild) > 0 {
+			return nil, &UnresolvableEntrypointError{Image: image, Reason: "it only has ONBUILD triggers, which are meant to be used by images built from it"}
+		}
+		return nil, &UnresolvableEntrypointError{Image: image, Reason: "its config has neither an entrypoint nor a cmd"}
+	}
+	if cfg.OS == windowsOS && len(command) == 1 {
+		// Windows images can record the whole command line as a single string.
+		command = splitWindowsCommandLine(command[0])
+	}
 	return command, nil
 }
 
-func getRemoteImage(image string, kubeclient kubernetes.Interface, taskRun *v1alpha1.TaskRun) (v1.Image, error) {
+// splitWindowsCommandLine splits a Windows command line into its arguments.
+// Arguments are separated by spaces or tabs, unless the separator is enclosed
+// in double quotes, which are removed.
+func splitWindowsCommandLine(cmdLine string) []string {
+	var args []string
+	var arg strings.Builder
+	inArg, inQuotes := false, false
+	for _, r := range cmdLine {
+		switch {
+		case r == '"':
+			inArg, inQuotes = true, !inQuotes
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			inArg = true
+			arg.WriteRune(r)
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+func getRemoteImage(image string, kubeclient kubernetes.Interface, taskRun *v1alpha1.TaskRun, mirrors map[string]string) (v1.Image, error) {
 	// verify the image name, then download the remote config file
 	ref, err := name.ParseReference(image, name.WeakValidation)
 	if err != nil {
@@ -238,14 +591,96 @@ func getRemoteImage(image string, kubeclient kubernetes.Interface, taskRun *v1al
 		return nil, xerrors.Errorf("Failed to create k8schain: %w", err)
 	}
 
-	// this will first try to authenticate using the k8schain,
-	// then fall back to the google keychain,
-	// then fall back to anonymous
-	mkc := authn.NewMultiKeychain(kc)
-	img, err := remote.Image(ref, remote.WithAuthFromKeychain(mkc))
+	refs := []name.Reference{ref}
+	mirror, err := mirrorOf(ref, mirrors)
+	if err != nil {
+		return nil, err
+	}
+	if mirror != nil {
+		refs = append(refs, mirror)
+	}
+	var errs []string
+	for _, ref := range refs {
+		img, auth, err := fetchImage(ref, kc)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		recordImageLookup(taskRun, v1alpha1.ImageLookup{
+			Image:    image,
+			Registry: ref.Context().RegistryStr(),
+			Auth:     auth,
+		})
+		return img, nil
+	}
+	return nil, xerrors.Errorf("Failed to get container image info from registry %s: %s", image, strings.Join(errs, "; "))
+}
+
+// fetchImage fetches ref, trying the credentials found for its registry in
+// turn: first the pull secrets of the TaskRun's service account in kc, then
+// the docker config of the controller, and finally anonymous access. It
+// returns which one succeeded.
+func fetchImage(ref name.Reference, kc authn.Keychain) (v1.Image, v1alpha1.ImageLookupAuth, error) {
+	var errs []string
+	for _, k := range []struct {
+		keychain authn.Keychain
+		auth     v1alpha1.ImageLookupAuth
+	}{
+		{kc, v1alpha1.ImageLookupAuthServiceAccount},
+		{authn.DefaultKeychain, v1alpha1.ImageLookupAuthController},
+	} {
+		auth, err := k.keychain.Resolve(ref.Context().Registry)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if auth == authn.Anonymous {
+			continue
+		}
+		img, err := remote.Image(ref, remote.WithAuth(auth))
+		if err == nil {
+			return img, k.auth, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	img, err := remote.Image(ref, remote.WithAuth(authn.Anonymous))
 	if err != nil {
-		return nil, xerrors.Errorf("Failed to get container image info from registry %s: %w", image, err)
+		errs = append(errs, err.Error())
+		return nil, "", xerrors.New(strings.Join(errs, "; "))
+	}
+	return img, v1alpha1.ImageLookupAuthAnonymous, nil
+}
+
+// mirrorOf returns the reference to the image ref in the mirror of its
+// registry in mirrors, or nil if it has none. The registries in mirrors are
+// normalized, e.g. docker.io is the same as index.docker.io.
+func mirrorOf(ref name.Reference, mirrors map[string]string) (name.Reference, error) {
+	for registry, mirror := range mirrors {
+		r, err := name.NewRegistry(registry, name.WeakValidation)
+		if err != nil || r.RegistryStr() != ref.Context().RegistryStr() {
+			continue
+		}
+		separator := ":"
+		if _, ok := ref.(name.Digest); ok {
+			separator = "@"
+		}
+		m, err := name.ParseReference(mirror+"/"+ref.Context().RepositoryStr()+separator+ref.Identifier(), name.WeakValidation)
+		if err != nil {
+			return nil, xerrors.Errorf("Failed to parse the mirror %s of registry %s: %w", mirror, registry, err)
+		}
+		return m, nil
 	}
+	return nil, nil
+}
 
-	return img, nil
+// recordImageLookup records lookup in the status of taskRun, replacing the
+// one of the same image if any.
+func recordImageLookup(taskRun *v1alpha1.TaskRun, lookup v1alpha1.ImageLookup) {
+	for i, l := range taskRun.Status.ImageLookups {
+		if l.Image == lookup.Image {
+			taskRun.Status.ImageLookups[i] = lookup
+			return
+		}
+	}
+	taskRun.Status.ImageLookups = append(taskRun.Status.ImageLookups, lookup)
 }
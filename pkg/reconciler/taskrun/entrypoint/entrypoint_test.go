@@ -17,13 +17,18 @@ limitations under the License.
 package entrypoint
 
 import (
+	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -69,7 +74,7 @@ func TestRewriteSteps(t *testing.T) {
 	observer, _ := observer.New(zap.InfoLevel)
 	entrypointCache, _ := NewCache()
 	c := fakekubeclientset.NewSimpleClientset()
-	err := RedirectSteps(entrypointCache, inputs, c, taskRun, zap.New(observer).Sugar())
+	err := RedirectSteps(entrypointCache, inputs, c, taskRun, nil, zap.New(observer).Sugar())
 	if err != nil {
 		t.Errorf("failed to get resources: %v", err)
 	}
@@ -90,6 +95,270 @@ func TestRewriteSteps(t *testing.T) {
 	}
 }
 
+func TestRedirectStepSkipExitCodes(t *testing.T) {
+	step := v1alpha1.Step{
+		Container: corev1.Container{
+			Image:   "image",
+			Command: []string{"lint"},
+		},
+		SkipExitCodes: []int32{78, 79},
+	}
+	observer, _ := observer.New(zap.InfoLevel)
+	entrypointCache, _ := NewCache()
+	if err := RedirectStep(entrypointCache, 1, &step, fakekubeclientset.NewSimpleClientset(), &v1alpha1.TaskRun{}, nil, zap.New(observer).Sugar()); err != nil {
+		t.Fatalf("failed to redirect step: %v", err)
+	}
+	expectedArgs := []string{
+		"-skip_exit_codes", "78,79",
+		"-wait_file", "/builder/tools/0",
+		"-post_file", "/builder/tools/1",
+		"-entrypoint", "lint", "--",
+	}
+	if d := cmp.Diff(expectedArgs, step.Args); d != "" {
+		t.Errorf("Didn't get expected arguments, difference: %s", d)
+	}
+}
+
+func TestRedirectStepOnError(t *testing.T) {
+	step := v1alpha1.Step{
+		Container: corev1.Container{
+			Image:   "image",
+			Command: []string{"lint"},
+		},
+		OnError: v1alpha1.Continue,
+	}
+	observer, _ := observer.New(zap.InfoLevel)
+	entrypointCache, _ := NewCache()
+	if err := RedirectStep(entrypointCache, 1, &step, fakekubeclientset.NewSimpleClientset(), &v1alpha1.TaskRun{}, nil, zap.New(observer).Sugar()); err != nil {
+		t.Fatalf("failed to redirect step: %v", err)
+	}
+	expectedArgs := []string{
+		"-on_error", "continue",
+		"-wait_file", "/builder/tools/0",
+		"-post_file", "/builder/tools/1",
+		"-entrypoint", "lint", "--",
+	}
+	if d := cmp.Diff(expectedArgs, step.Args); d != "" {
+		t.Errorf("Didn't get expected arguments, difference: %s", d)
+	}
+}
+
+func TestRedirectStepTimeout(t *testing.T) {
+	step := v1alpha1.Step{
+		Container: corev1.Container{
+			Image:   "image",
+			Command: []string{"test"},
+		},
+		Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+	}
+	observer, _ := observer.New(zap.InfoLevel)
+	entrypointCache, _ := NewCache()
+	if err := RedirectStep(entrypointCache, 1, &step, fakekubeclientset.NewSimpleClientset(), &v1alpha1.TaskRun{}, nil, zap.New(observer).Sugar()); err != nil {
+		t.Fatalf("failed to redirect step: %v", err)
+	}
+	expectedArgs := []string{
+		"-timeout", "10m0s",
+		"-wait_file", "/builder/tools/0",
+		"-post_file", "/builder/tools/1",
+		"-entrypoint", "test", "--",
+	}
+	if d := cmp.Diff(expectedArgs, step.Args); d != "" {
+		t.Errorf("Didn't get expected arguments, difference: %s", d)
+	}
+}
+
+func TestRedirectStepsStepVariables(t *testing.T) {
+	steps := []v1alpha1.Step{{Container: corev1.Container{
+		Name:    "lint",
+		Image:   "image",
+		Command: []string{"lint"},
+	}}, {Container: corev1.Container{
+		Name:    "build",
+		Image:   "image",
+		Command: []string{"build"},
+		Env:     []corev1.EnvVar{{Name: "LINTED", Value: "$(steps.lint.exitCode)"}},
+	}}, {Container: corev1.Container{
+		Name:    "unrelated",
+		Image:   "image",
+		Command: []string{"echo"},
+	}}}
+	observer, _ := observer.New(zap.InfoLevel)
+	entrypointCache, _ := NewCache()
+	if err := RedirectSteps(entrypointCache, steps, fakekubeclientset.NewSimpleClientset(), &v1alpha1.TaskRun{}, nil, zap.New(observer).Sugar()); err != nil {
+		t.Fatalf("failed to redirect steps: %v", err)
+	}
+	for i, want := range [][]string{{
+		"-steps_dir", "/builder/tools/steps",
+		"-step_name", "lint",
+		"-wait_file", "/builder/downward/ready",
+		"-post_file", "/builder/tools/0",
+		"-wait_file_content",
+		"-entrypoint", "lint", "--",
+	}, {
+		"-steps_dir", "/builder/tools/steps",
+		"-wait_file", "/builder/tools/0",
+		"-post_file", "/builder/tools/1",
+		"-entrypoint", "build", "--",
+	}, {
+		"-wait_file", "/builder/tools/1",
+		"-post_file", "/builder/tools/2",
+		"-entrypoint", "echo", "--",
+	}} {
+		if d := cmp.Diff(want, steps[i].Args); d != "" {
+			t.Errorf("Didn't get expected arguments for step %d, difference: %s", i, d)
+		}
+	}
+}
+
+func TestSetWaitTimeouts(t *testing.T) {
+	steps := []v1alpha1.Step{
+		{Container: corev1.Container{Args: []string{"-wait_file", "/builder/downward/ready"}}},
+		{Container: corev1.Container{Args: []string{"-wait_file", "/builder/tools/0"}}},
+	}
+	SetWaitTimeouts(steps, 5*time.Minute, 2*time.Hour)
+	for i, want := range [][]string{
+		{"-start_file", "/builder/tools/0.start", "-wait_file_timeout", "5m0s", "-wait_file", "/builder/downward/ready"},
+		{"-wait_file_timeout", "2h0m0s", "-wait_start_file", "/builder/tools/0.start", "-wait_file", "/builder/tools/0"},
+	} {
+		if d := cmp.Diff(want, steps[i].Args); d != "" {
+			t.Errorf("Didn't get expected arguments for step %d, difference: %s", i, d)
+		}
+	}
+
+	steps = []v1alpha1.Step{{Container: corev1.Container{Args: []string{"-wait_file", "/builder/downward/ready"}}}}
+	SetWaitTimeouts(steps, 0, time.Hour)
+	if d := cmp.Diff([]string{"-wait_file", "/builder/downward/ready"}, steps[0].Args); d != "" {
+		t.Errorf("Expected no timeout for the first step, difference: %s", d)
+	}
+}
+
+func TestSetProgress(t *testing.T) {
+	spec := &v1alpha1.TaskSpec{Steps: []v1alpha1.Step{
+		{Container: corev1.Container{Name: "build", Args: []string{"-wait_file", "/builder/downward/ready"}}},
+	}}
+	SetProgress(spec, 10*time.Second)
+	step := spec.Steps[0]
+	if d := cmp.Diff([]string{"-progress_file", "/tekton/progress", "-progress_interval", "10s", "-progress_step", "build", "-wait_file", "/builder/downward/ready"}, step.Args); d != "" {
+		t.Errorf("Didn't get expected arguments, difference: %s", d)
+	}
+	if d := cmp.Diff([]corev1.VolumeMount{{Name: "tekton-internal", MountPath: "/tekton"}}, step.VolumeMounts); d != "" {
+		t.Errorf("Didn't get expected volume mounts, difference: %s", d)
+	}
+	if len(step.Env) != 0 {
+		t.Errorf("Expected no environment, got %v", step.Env)
+	}
+	if len(spec.Volumes) != 1 || spec.Volumes[0].Name != "tekton-internal" || spec.Volumes[0].EmptyDir == nil {
+		t.Errorf("Expected the tekton-internal emptyDir volume, got %v", spec.Volumes)
+	}
+
+	spec = &v1alpha1.TaskSpec{Steps: []v1alpha1.Step{{Container: corev1.Container{Args: []string{"-wait_file", "/builder/downward/ready"}}}}}
+	SetProgress(spec, 0)
+	if d := cmp.Diff([]string{"-wait_file", "/builder/downward/ready"}, spec.Steps[0].Args); d != "" || len(spec.Volumes) != 0 {
+		t.Errorf("Expected no progress reporting, difference: %s", d)
+	}
+}
+
+func TestSetLogPrefixes(t *testing.T) {
+	for _, c := range []struct {
+		desc                  string
+		timestamps, stepNames bool
+		want                  [][]string
+	}{{
+		desc: "no prefixes",
+		want: [][]string{{"-wait_file", "/builder/downward/ready"}, {"-wait_file", "/builder/tools/0"}},
+	}, {
+		desc:       "timestamps",
+		timestamps: true,
+		want:       [][]string{{"-log_timestamps", "-wait_file", "/builder/downward/ready"}, {"-log_timestamps", "-wait_file", "/builder/tools/0"}},
+	}, {
+		desc:       "timestamps and step names",
+		timestamps: true,
+		stepNames:  true,
+		want: [][]string{
+			{"-log_timestamps", "-log_step_name", "build", "-wait_file", "/builder/downward/ready"},
+			{"-log_timestamps", "-log_step_name", "unnamed-1", "-wait_file", "/builder/tools/0"},
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			steps := []v1alpha1.Step{
+				{Container: corev1.Container{Name: "build", Args: []string{"-wait_file", "/builder/downward/ready"}}},
+				{Container: corev1.Container{Args: []string{"-wait_file", "/builder/tools/0"}}},
+			}
+			SetLogPrefixes(steps, c.timestamps, c.stepNames)
+			for i, step := range steps {
+				if d := cmp.Diff(c.want[i], step.Args); d != "" {
+					t.Errorf("Didn't get expected arguments for step %d, difference: %s", i, d)
+				}
+			}
+		})
+	}
+}
+
+func TestSetResults(t *testing.T) {
+	spec := &v1alpha1.TaskSpec{
+		Results: []v1alpha1.TaskResult{{Name: "digest"}, {Name: "commit"}},
+		Steps: []v1alpha1.Step{
+			{Container: corev1.Container{Args: []string{"-wait_file", "/builder/downward/ready"}}},
+			{Container: corev1.Container{Args: []string{"-wait_file", "/builder/tools/0"}}},
+		},
+	}
+	SetProgress(spec, time.Second)
+	SetResults(spec)
+	for i, step := range spec.Steps {
+		if d := cmp.Diff([]string{"-results", "digest,commit", "-progress_file"}, step.Args[:3]); d != "" {
+			t.Errorf("Didn't get expected arguments for step %d, difference: %s", i, d)
+		}
+		// The /tekton directory is only mounted once.
+		if d := cmp.Diff([]corev1.VolumeMount{{Name: "tekton-internal", MountPath: "/tekton"}}, step.VolumeMounts); d != "" {
+			t.Errorf("Didn't get expected volume mounts for step %d, difference: %s", i, d)
+		}
+	}
+	if len(spec.Volumes) != 1 {
+		t.Errorf("Expected a single tekton-internal volume, got %v", spec.Volumes)
+	}
+
+	spec = &v1alpha1.TaskSpec{Steps: []v1alpha1.Step{{Container: corev1.Container{Args: []string{"-wait_file", "/builder/downward/ready"}}}}}
+	SetResults(spec)
+	if d := cmp.Diff([]string{"-wait_file", "/builder/downward/ready"}, spec.Steps[0].Args); d != "" || len(spec.Volumes) != 0 {
+		t.Errorf("Expected no results, difference: %s", d)
+	}
+}
+
+func TestSetWorkspaceChecksums(t *testing.T) {
+	spec := &v1alpha1.TaskSpec{
+		Workspaces: []v1alpha1.WorkspaceDeclaration{
+			{Name: "source", Checksum: true},
+			{Name: "cache", MountPath: "/cache"},
+			{Name: "output", MountPath: "/output", Checksum: true},
+		},
+		Steps: []v1alpha1.Step{
+			{Container: corev1.Container{Args: []string{"-wait_file", "/builder/downward/ready"}}},
+			{Container: corev1.Container{Args: []string{"-wait_file", "/builder/tools/0"}}},
+			{Container: corev1.Container{Args: []string{"-wait_file", "/builder/tools/1"}}},
+		},
+	}
+	SetWorkspaceChecksums(spec)
+	expected := [][]string{
+		{"-start_checksums", "source-checksum-start=/workspace/source,output-checksum-start=/output", "-wait_file", "/builder/downward/ready"},
+		{"-wait_file", "/builder/tools/0"},
+		{"-end_checksums", "source-checksum-end=/workspace/source,output-checksum-end=/output", "-wait_file", "/builder/tools/1"},
+	}
+	for i, step := range spec.Steps {
+		if d := cmp.Diff(expected[i], step.Args); d != "" {
+			t.Errorf("Didn't get expected arguments for step %d, difference: %s", i, d)
+		}
+	}
+
+	spec = &v1alpha1.TaskSpec{
+		Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source"}},
+		Steps:      []v1alpha1.Step{{Container: corev1.Container{Args: []string{"-wait_file", "/builder/downward/ready"}}}},
+	}
+	SetWorkspaceChecksums(spec)
+	if d := cmp.Diff([]string{"-wait_file", "/builder/downward/ready"}, spec.Steps[0].Args); d != "" {
+		t.Errorf("Expected no checksums, difference: %s", d)
+	}
+}
+
 func TestGetArgs(t *testing.T) {
 	// first step
 	// multiple commands
@@ -282,7 +551,7 @@ func TestGetRemoteEntrypoint(t *testing.T) {
 			Namespace: "foo",
 		},
 	})
-	ep, err := GetRemoteEntrypoint(entrypointCache, finalDigest, c, taskRun)
+	ep, err := GetRemoteEntrypoint(entrypointCache, finalDigest, c, taskRun, nil)
 	if err != nil {
 		t.Errorf("couldn't get entrypoint remote: %v", err)
 	}
@@ -323,7 +592,7 @@ func TestGetRemoteEntrypointStale(t *testing.T) {
 			Namespace: "foo",
 		},
 	})
-	ep1, err := GetRemoteEntrypoint(entrypointCache, image, c, taskRun)
+	ep1, err := GetRemoteEntrypoint(entrypointCache, image, c, taskRun, nil)
 	if err != nil {
 		t.Errorf("couldn't get entrypoint remote: %v", err)
 	}
@@ -339,7 +608,7 @@ func TestGetRemoteEntrypointStale(t *testing.T) {
 	server2 := getServer(t, img)
 	image = path.Join(strings.TrimPrefix(server2.URL, "http://"), expectedRepo) + ":latest"
 	defer server2.Close()
-	ep2, err := GetRemoteEntrypoint(entrypointCache, image, c, taskRun)
+	ep2, err := GetRemoteEntrypoint(entrypointCache, image, c, taskRun, nil)
 	if err != nil {
 		t.Fatalf("couldn't get entrypoint remote: %v", err)
 	}
@@ -428,7 +697,7 @@ func TestGetRemoteEntrypointWithNonDefaultSA(t *testing.T) {
 			t.Fatalf("couldn't create new entrypoint cache: %v", err)
 		}
 
-		ep, err := GetRemoteEntrypoint(entrypointCache, finalDigest, c, tt(taskRun))
+		ep, err := GetRemoteEntrypoint(entrypointCache, finalDigest, c, tt(taskRun), nil)
 		if err != nil {
 			t.Errorf("couldn't get entrypoint remote: %v", err)
 		}
@@ -438,6 +707,249 @@ func TestGetRemoteEntrypointWithNonDefaultSA(t *testing.T) {
 	}
 }
 
+func TestCommandFromConfig(t *testing.T) {
+	for _, c := range []struct {
+		desc    string
+		cfg     v1.ConfigFile
+		want    []string
+		wantErr bool
+	}{{
+		desc: "entrypoint",
+		cfg:  v1.ConfigFile{Config: v1.Config{Entrypoint: []string{"/bin/app"}, Cmd: []string{"--help"}}},
+		want: []string{"/bin/app"},
+	}, {
+		desc: "cmd only",
+		cfg:  v1.ConfigFile{Config: v1.Config{Cmd: []string{"/bin/sh", "-c", "echo hello"}}},
+		want: []string{"/bin/sh", "-c", "echo hello"},
+	}, {
+		desc: "windows command line",
+		cfg:  v1.ConfigFile{OS: "windows", Config: v1.Config{Entrypoint: []string{`"C:\Program Files\app.exe" -v  --name "a b"`}}},
+		want: []string{`C:\Program Files\app.exe`, "-v", "--name", "a b"},
+	}, {
+		desc: "windows exec form",
+		cfg:  v1.ConfigFile{OS: "windows", Config: v1.Config{Cmd: []string{"cmd", "/S", "/C", "echo hello"}}},
+		want: []string{"cmd", "/S", "/C", "echo hello"},
+	}, {
+		desc:    "onbuild only",
+		cfg:     v1.ConfigFile{Config: v1.Config{OnBuild: []string{"COPY . /app"}}},
+		wantErr: true,
+	}, {
+		desc:    "no command",
+		cfg:     v1.ConfigFile{},
+		wantErr: true,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := commandFromConfig("my.registry.svc/image:tag", &c.cfg)
+			if c.wantErr {
+				var uerr *UnresolvableEntrypointError
+				if !xerrors.As(err, &uerr) {
+					t.Fatalf("expected an UnresolvableEntrypointError, got %v", err)
+				}
+				if !strings.Contains(err.Error(), "my.registry.svc/image:tag") {
+					t.Errorf("expected the error to name the image, got %q", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("command diff -want, +got: %s", d)
+			}
+		})
+	}
+}
+
+func TestGetRemoteEntrypointCredentialChain(t *testing.T) {
+	expectedEntrypoint := []string{"/bin/expected", "entrypoint"}
+	img := getImage(t, &v1.ConfigFile{
+		Config: v1.Config{
+			Entrypoint: expectedEntrypoint,
+		},
+	})
+	registry := getServer(t, img)
+	defer registry.Close()
+	goodAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:good"))
+	// Only the controller's credentials are accepted by the registry.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != goodAuth {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		registry.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	image := path.Join(host, "image") + "@" + getDigestAsString(img)
+
+	dir, err := ioutil.TempDir("", "docker-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dockerConfig := fmt.Sprintf(`{"auths": {%q: {"username": "user", "password": "good"}}}`, host)
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(dockerConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("DOCKER_CONFIG", os.Getenv("DOCKER_CONFIG"))
+	os.Setenv("DOCKER_CONFIG", dir)
+
+	c := fakekubeclientset.NewSimpleClientset(&corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "regcred"}},
+	}, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "foo"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(fmt.Sprintf(`{"auths": {%q: {"username": "user", "password": "bad"}}}`, host)),
+		},
+	})
+	taskRun := &v1alpha1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "taskRun"},
+		Spec:       v1alpha1.TaskRunSpec{ServiceAccountName: "default"},
+	}
+	entrypointCache, err := NewCache()
+	if err != nil {
+		t.Fatalf("couldn't create new entrypoint cache: %v", err)
+	}
+
+	ep, err := GetRemoteEntrypoint(entrypointCache, image, c, taskRun, nil)
+	if err != nil {
+		t.Fatalf("expected to fall back to the controller credentials, got: %v", err)
+	}
+	if d := cmp.Diff(expectedEntrypoint, ep); d != "" {
+		t.Errorf("entrypoint diff -want, +got: %s", d)
+	}
+	wantLookups := []v1alpha1.ImageLookup{{Image: image, Registry: host, Auth: v1alpha1.ImageLookupAuthController}}
+	if d := cmp.Diff(wantLookups, taskRun.Status.ImageLookups); d != "" {
+		t.Errorf("image lookups diff -want, +got: %s", d)
+	}
+}
+
+func TestGetRemoteEntrypointMirror(t *testing.T) {
+	expectedEntrypoint := []string{"/bin/expected", "entrypoint"}
+	img := getImage(t, &v1.ConfigFile{
+		Config: v1.Config{
+			Entrypoint: expectedEntrypoint,
+		},
+	})
+	mirror := getServer(t, img)
+	defer mirror.Close()
+	mirrorHost := strings.TrimPrefix(mirror.URL, "http://")
+	// The registry of the image rejects every request.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	for _, image := range []string{
+		path.Join(host, "image") + ":latest",
+		path.Join(host, "image") + "@" + getDigestAsString(img),
+	} {
+		t.Run(image, func(t *testing.T) {
+			c := fakekubeclientset.NewSimpleClientset(&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+			})
+			taskRun := &v1alpha1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "taskRun"},
+				Spec:       v1alpha1.TaskRunSpec{ServiceAccountName: "default"},
+			}
+			entrypointCache, err := NewCache()
+			if err != nil {
+				t.Fatalf("couldn't create new entrypoint cache: %v", err)
+			}
+
+			if _, err := GetRemoteEntrypoint(entrypointCache, image, c, taskRun, nil); err == nil {
+				t.Fatal("expected an error without mirror")
+			}
+			ep, err := GetRemoteEntrypoint(entrypointCache, image, c, taskRun, map[string]string{host: mirrorHost})
+			if err != nil {
+				t.Fatalf("expected to fall back to the mirror, got: %v", err)
+			}
+			if d := cmp.Diff(expectedEntrypoint, ep); d != "" {
+				t.Errorf("entrypoint diff -want, +got: %s", d)
+			}
+			wantLookups := []v1alpha1.ImageLookup{{Image: image, Registry: mirrorHost, Auth: v1alpha1.ImageLookupAuthAnonymous}}
+			if d := cmp.Diff(wantLookups, taskRun.Status.ImageLookups); d != "" {
+				t.Errorf("image lookups diff -want, +got: %s", d)
+			}
+		})
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	expectedEntrypoint := []string{"/bin/expected", "entrypoint"}
+	img := getImage(t, &v1.ConfigFile{
+		Config: v1.Config{
+			Entrypoint: expectedEntrypoint,
+		},
+	})
+	server := getServer(t, img)
+	defer server.Close()
+	image := path.Join(strings.TrimPrefix(server.URL, "http://"), "image") + ":latest"
+	c := fakekubeclientset.NewSimpleClientset(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	})
+	entrypointCache, err := NewCache()
+	if err != nil {
+		t.Fatalf("couldn't create new entrypoint cache: %v", err)
+	}
+	now := time.Now()
+	until := now.Add(time.Hour)
+
+	if Prefetched(entrypointCache, "foo", image, now) {
+		t.Errorf("expected %s not to be prefetched yet", image)
+	}
+	digest, err := Prefetch(entrypointCache, image, c, "foo", "default", nil, until)
+	if err != nil {
+		t.Fatalf("couldn't prefetch %s: %v", image, err)
+	}
+	if digest != getDigestAsString(img) {
+		t.Errorf("expected %s to be resolved to %s, got %s", image, getDigestAsString(img), digest)
+	}
+	if !Prefetched(entrypointCache, "foo", image, now) {
+		t.Errorf("expected %s to be prefetched", image)
+	}
+	if Prefetched(entrypointCache, "foo", image, until) {
+		t.Errorf("expected %s to be prefetched only until %s", image, until)
+	}
+	if Prefetched(entrypointCache, "bar", image, now) {
+		t.Errorf("expected %s to be prefetched only for namespace foo", image)
+	}
+
+	// The registry isn't needed anymore.
+	server.Close()
+	taskRun := &v1alpha1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "taskRun"},
+		Spec:       v1alpha1.TaskRunSpec{ServiceAccountName: "default"},
+	}
+	ep, err := GetRemoteEntrypoint(entrypointCache, image, c, taskRun, nil)
+	if err != nil {
+		t.Fatalf("expected the prefetched entrypoint of %s, got: %v", image, err)
+	}
+	if d := cmp.Diff(expectedEntrypoint, ep); d != "" {
+		t.Errorf("entrypoint diff -want, +got: %s", d)
+	}
+	if len(taskRun.Status.ImageLookups) != 0 {
+		t.Errorf("expected no image lookup, got %v", taskRun.Status.ImageLookups)
+	}
+
+	// The step runs the digest the tag was prefetched to, wherever the tag
+	// moved since.
+	step := v1alpha1.Step{Container: corev1.Container{Image: image}}
+	observer, _ := observer.New(zap.InfoLevel)
+	if err := RedirectStep(entrypointCache, 0, &step, c, taskRun, nil, zap.New(observer).Sugar()); err != nil {
+		t.Fatalf("couldn't redirect the step: %v", err)
+	}
+	wantImage := strings.TrimSuffix(image, ":latest") + "@" + digest
+	if step.Image != wantImage {
+		t.Errorf("expected the step to run %s, got %s", wantImage, step.Image)
+	}
+}
+
 func TestEntrypointCacheLRU(t *testing.T) {
 	entrypoint := []string{"/bin/expected", "entrypoint"}
 	entrypointCache, err := NewCache()
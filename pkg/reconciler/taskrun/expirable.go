@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"golang.org/x/xerrors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Expirable is a run which can be deleted once it has been finished for its
+// TTL, e.g. a TaskRun, a PipelineRun or a ConditionCheck.
+type Expirable interface {
+	metav1.Object
+	// HasCompleted returns whether the run finished, whatever its outcome.
+	HasCompleted() bool
+	// FinishTime returns the time at which the run finished, or nil if it
+	// can't be told.
+	FinishTime() *metav1.Time
+	// TTL returns the time the finished run is kept for, or nil if it is
+	// never deleted.
+	TTL() *metav1.Duration
+}
+
+var (
+	_ Expirable = (*v1alpha1.TaskRun)(nil)
+	_ Expirable = (*v1alpha1.PipelineRun)(nil)
+	_ Expirable = (*v1alpha1.ConditionCheck)(nil)
+)
+
+// Expires returns whether run is a finished run with a TTL, which isn't
+// exempted from being deleted by the keep annotation or label, and wasn't
+// compacted already.
+func Expires(run Expirable) bool {
+	return run.HasCompleted() && run.TTL() != nil && !IsKept(run) && !IsCompacted(run)
+}
+
+// TimeLeft returns the time left until run expires, as seen at since. It is
+// negative if run has already expired.
+func TimeLeft(run Expirable, since *time.Time) (*time.Duration, error) {
+	finishAt, err := FinishTime(run)
+	if err != nil {
+		return nil, err
+	}
+	remaining := finishAt.Add(run.TTL().Duration).Sub(*since)
+	return &remaining, nil
+}
+
+// FinishTime returns the time at which run finished.
+func FinishTime(run Expirable) (time.Time, error) {
+	if t := run.FinishTime(); t != nil {
+		return t.Time, nil
+	}
+	return time.Time{}, xerrors.Errorf("unable to find the time when %s/%s finished", run.GetNamespace(), run.GetName())
+}
@@ -30,6 +30,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/apis"
 	"knative.dev/pkg/metrics"
 )
 
@@ -179,19 +180,20 @@ func (r *Recorder) DurationAndCount(tr *v1alpha1.TaskRun) error {
 	}
 
 	status := "success"
-	if tr.Status.Conditions[0].Status == corev1.ConditionFalse {
+	if tr.Status.GetCondition(apis.ConditionSucceeded).IsFalse() {
 		status = "failed"
 	}
 
-	if ok, pipeline, pipelinerun := tr.IsPartOfPipeline(); ok {
+	id := tr.RunIdentity("")
+	if id.Pipeline != "" {
 		ctx, err := tag.New(
 			context.Background(),
 			tag.Insert(r.task, taskName),
-			tag.Insert(r.taskRun, tr.Name),
-			tag.Insert(r.namespace, tr.Namespace),
+			tag.Insert(r.taskRun, id.Run),
+			tag.Insert(r.namespace, id.Namespace),
 			tag.Insert(r.status, status),
-			tag.Insert(r.pipeline, pipeline),
-			tag.Insert(r.pipelineRun, pipelinerun),
+			tag.Insert(r.pipeline, id.Pipeline),
+			tag.Insert(r.pipelineRun, id.PipelineRun),
 		)
 
 		if err != nil {
@@ -206,8 +208,8 @@ func (r *Recorder) DurationAndCount(tr *v1alpha1.TaskRun) error {
 	ctx, err := tag.New(
 		context.Background(),
 		tag.Insert(r.task, taskName),
-		tag.Insert(r.taskRun, tr.Name),
-		tag.Insert(r.namespace, tr.Namespace),
+		tag.Insert(r.taskRun, id.Run),
+		tag.Insert(r.namespace, id.Namespace),
 		tag.Insert(r.status, status),
 	)
 	if err != nil {
@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// sweepConfigPollInterval is how often RunSweeps checks whether the cleanup
+// config enabled the sweeps while they are disabled.
+const sweepConfigPollInterval = time.Minute
+
+// RunSweeps calls sweep every sweep-interval of the cleanup config watched by
+// configStore, as told by c, until stopCh is closed. The expiration
+// controllers clean up each run once its TTL elapses, with a timer which is
+// lost if the controller restarts, and which no event sets again until the
+// run is updated; the sweeps catch those runs.
+func RunSweeps(stopCh <-chan struct{}, c clock.Clock, configStore reconciler.ConfigStore, sweep func()) {
+	interval := func() time.Duration {
+		return config.FromContextOrDefaults(configStore.ToContext(context.Background())).Cleanup.SweepInterval
+	}
+	for {
+		wait := interval()
+		if wait <= 0 {
+			wait = sweepConfigPollInterval
+		}
+		select {
+		case <-stopCh:
+			return
+		case <-c.After(wait):
+		}
+		if interval() > 0 {
+			sweep()
+		}
+	}
+}
+
+// EnqueueExpired enqueues the runs whose TTL elapsed at now, and returns how
+// many.
+func EnqueueExpired(runs []Expirable, now time.Time, enqueue func(obj interface{})) int {
+	n := 0
+	for _, run := range runs {
+		if !Expires(run) {
+			continue
+		}
+		if left, err := TimeLeft(run, &now); err != nil || *left > 0 {
+			continue
+		}
+		enqueue(run)
+		n++
+	}
+	return n
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/configmap"
+)
+
+// sweepConfigStore holds a cleanup config with the sweep interval it is set.
+type sweepConfigStore struct {
+	mu       sync.Mutex
+	interval time.Duration
+}
+
+func (s *sweepConfigStore) set(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interval = interval
+}
+
+func (s *sweepConfigStore) ToContext(ctx context.Context) context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return config.ToContext(ctx, &config.Config{Cleanup: &config.Cleanup{SweepInterval: s.interval}})
+}
+
+func (s *sweepConfigStore) WatchConfigs(configmap.Watcher) {}
+
+func TestRunSweeps(t *testing.T) {
+	c := clock.NewFakeClock(testNow)
+	store := &sweepConfigStore{interval: 10 * time.Minute}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	swept := make(chan time.Time, 10)
+	go RunSweeps(stopCh, c, store, func() { swept <- c.Now() })
+
+	// step moves the clock forward by d once RunSweeps waits for it.
+	step := func(d time.Duration) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for !c.HasWaiters() {
+			if time.Now().After(deadline) {
+				t.Fatal("Timed out waiting for RunSweeps to wait")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		c.Step(d)
+	}
+	expectSweep := func(want bool) {
+		t.Helper()
+		select {
+		case <-swept:
+			if !want {
+				t.Errorf("Unexpected sweep at %s", c.Now())
+			}
+		case <-time.After(100 * time.Millisecond):
+			if want {
+				t.Errorf("Expected a sweep at %s", c.Now())
+			}
+		}
+	}
+
+	step(10 * time.Minute)
+	expectSweep(true)
+
+	store.set(0)
+	step(10 * time.Minute)
+	expectSweep(false)
+	step(sweepConfigPollInterval)
+	expectSweep(false)
+
+	// Enabling the sweeps again takes effect when the config is polled.
+	store.set(time.Hour)
+	step(sweepConfigPollInterval)
+	expectSweep(true)
+	step(time.Hour)
+	expectSweep(true)
+}
+
+func TestEnqueueExpired(t *testing.T) {
+	running := tb.TaskRun("running", "foo", tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(time.Hour)))
+	var enqueued []string
+	n := EnqueueExpired([]Expirable{
+		finishedTaskRun("expired", 2*time.Hour),
+		finishedTaskRun("not-expired", 30*time.Minute),
+		running,
+		finishedTaskRun("kept", 2*time.Hour, tb.TaskRunAnnotation(KeepAnnotationKey, "true")),
+	}, testNow, func(obj interface{}) {
+		enqueued = append(enqueued, obj.(*v1alpha1.TaskRun).Name)
+	})
+	if n != 1 || len(enqueued) != 1 || enqueued[0] != "expired" {
+		t.Errorf("Expected only the expired TaskRun to be enqueued, got %d: %v", n, enqueued)
+	}
+}
@@ -18,8 +18,10 @@ package resources
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // ApplyParameters applies the params from a TaskRun.Input.Parameters to a TaskSpec
@@ -34,23 +36,88 @@ func ApplyParameters(spec *v1alpha1.TaskSpec, tr *v1alpha1.TaskRun, defaults ...
 	// Set all the default stringReplacements
 	for _, p := range defaults {
 		if p.Default != nil {
-			if p.Default.Type == v1alpha1.ParamTypeString {
+			switch p.Default.Type {
+			case v1alpha1.ParamTypeString:
 				stringReplacements[fmt.Sprintf("inputs.params.%s", p.Name)] = p.Default.StringVal
-			} else {
+			case v1alpha1.ParamTypeObject:
+				for key, value := range p.Default.ObjectVal {
+					stringReplacements[fmt.Sprintf("inputs.params.%s.%s", p.Name, key)] = value
+				}
+			default:
 				arrayReplacements[fmt.Sprintf("inputs.params.%s", p.Name)] = p.Default.ArrayVal
 			}
 		}
 	}
 	// Set and overwrite params with the ones from the TaskRun
+	var secretParams []v1alpha1.Param
 	for _, p := range tr.Spec.Inputs.Params {
-		if p.Value.Type == v1alpha1.ParamTypeString {
+		// The values read from Secrets are only expanded by the kubelet,
+		// from the environment of the steps using them.
+		if p.ValueFrom != nil && p.ValueFrom.SecretKeyRef != nil {
+			stringReplacements[fmt.Sprintf("inputs.params.%s", p.Name)] = fmt.Sprintf("$(%s)", SecretParamEnvName(p.Name))
+			secretParams = append(secretParams, p)
+			continue
+		}
+		switch p.Value.Type {
+		case v1alpha1.ParamTypeString:
 			stringReplacements[fmt.Sprintf("inputs.params.%s", p.Name)] = p.Value.StringVal
-		} else {
+		case v1alpha1.ParamTypeObject:
+			// Object params are only referenced by key, e.g. $(inputs.params.foo.key).
+			for key, value := range p.Value.ObjectVal {
+				stringReplacements[fmt.Sprintf("inputs.params.%s.%s", p.Name, key)] = value
+			}
+		default:
 			arrayReplacements[fmt.Sprintf("inputs.params.%s", p.Name)] = p.Value.ArrayVal
 		}
 	}
 
-	return ApplyReplacements(spec, stringReplacements, arrayReplacements)
+	spec = ApplyReplacements(spec, stringReplacements, arrayReplacements)
+	for i := range spec.Steps {
+		addSecretParamsEnv(&spec.Steps[i], secretParams)
+	}
+	return spec
+}
+
+// SecretParamEnvName is the environment variable of the steps holding the
+// value of the param read from a Secret.
+func SecretParamEnvName(name string) string {
+	return "TEKTON_PARAM_" + name
+}
+
+// addSecretParamsEnv adds the environment variables of the params read from
+// Secrets that step uses before its own, so that they're expanded in its
+// environment too.
+func addSecretParamsEnv(step *v1alpha1.Step, params []v1alpha1.Param) {
+	var env []corev1.EnvVar
+	for _, p := range params {
+		ref := fmt.Sprintf("$(%s)", SecretParamEnvName(p.Name))
+		if !usesVariable(step, ref) {
+			continue
+		}
+		env = append(env, corev1.EnvVar{
+			Name:      SecretParamEnvName(p.Name),
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: p.ValueFrom.SecretKeyRef.DeepCopy()},
+		})
+	}
+	if len(env) > 0 {
+		step.Env = append(env, step.Env...)
+	}
+}
+
+func usesVariable(step *v1alpha1.Step, ref string) bool {
+	for _, values := range [][]string{step.Command, step.Args} {
+		for _, v := range values {
+			if strings.Contains(v, ref) {
+				return true
+			}
+		}
+	}
+	for _, e := range step.Env {
+		if strings.Contains(e.Value, ref) {
+			return true
+		}
+	}
+	return false
 }
 
 // ApplyResources applies the substitution from values in resources which are referenced in spec as subitems
@@ -78,6 +145,42 @@ func ApplyResources(spec *v1alpha1.TaskSpec, resolvedResources map[string]v1alph
 	return ApplyReplacements(spec, replacements, map[string][]string{})
 }
 
+// ApplyTaskResults replaces the $(results.<name>.path) variables of spec
+// with the files the steps write the results to. They are also replaced in
+// the scripts of the steps, which write most results.
+func ApplyTaskResults(spec *v1alpha1.TaskSpec) *v1alpha1.TaskSpec {
+	if len(spec.Results) == 0 {
+		return spec
+	}
+	replacements := map[string]string{}
+	for _, r := range spec.Results {
+		replacements[fmt.Sprintf("results.%s.path", r.Name)] = v1alpha1.ResultPath(r.Name)
+	}
+	spec = ApplyReplacements(spec, replacements, map[string][]string{})
+	for i := range spec.Steps {
+		spec.Steps[i].Script = v1alpha1.ApplyReplacements(spec.Steps[i].Script, replacements)
+	}
+	return spec
+}
+
+// ApplyWorkspaces replaces the $(workspaces.<name>.path) variables of spec
+// with where the workspaces are mounted in the steps, including in their
+// scripts.
+func ApplyWorkspaces(spec *v1alpha1.TaskSpec) *v1alpha1.TaskSpec {
+	if len(spec.Workspaces) == 0 {
+		return spec
+	}
+	replacements := map[string]string{}
+	for _, w := range spec.Workspaces {
+		replacements[fmt.Sprintf("workspaces.%s.path", w.Name)] = w.GetMountPath()
+	}
+	spec = ApplyReplacements(spec, replacements, map[string][]string{})
+	for i := range spec.Steps {
+		spec.Steps[i].Script = v1alpha1.ApplyReplacements(spec.Steps[i].Script, replacements)
+	}
+	return spec
+}
+
 // ApplyReplacements replaces placeholders for declared parameters with the specified replacements.
 func ApplyReplacements(spec *v1alpha1.TaskSpec, stringReplacements map[string]string, arrayReplacements map[string][]string) *v1alpha1.TaskSpec {
 	spec = spec.DeepCopy()
@@ -88,11 +191,32 @@ func ApplyReplacements(spec *v1alpha1.TaskSpec, stringReplacements map[string]st
 		v1alpha1.ApplyStepReplacements(&steps[i], stringReplacements, arrayReplacements)
 	}
 
+	// Apply variable expansion to init steps fields.
+	for i, c := range spec.InitSteps {
+		s := v1alpha1.Step{Container: c}
+		v1alpha1.ApplyStepReplacements(&s, stringReplacements, arrayReplacements)
+		spec.InitSteps[i] = s.Container
+	}
+
+	// Apply variable expansion to sidecars fields.
+	for i, c := range spec.Sidecars {
+		s := v1alpha1.Step{Container: c}
+		v1alpha1.ApplyStepReplacements(&s, stringReplacements, arrayReplacements)
+		spec.Sidecars[i] = s.Container
+	}
+
 	// Apply variable expansion to stepTemplate fields.
 	if spec.StepTemplate != nil {
 		v1alpha1.ApplyStepReplacements(&v1alpha1.Step{Container: *spec.StepTemplate}, stringReplacements, arrayReplacements)
 	}
 
+	// Apply variable expansion to the repository checked out.
+	if c := spec.Checkout; c != nil {
+		c.Repo = v1alpha1.ApplyReplacements(c.Repo, stringReplacements)
+		c.Revision = v1alpha1.ApplyReplacements(c.Revision, stringReplacements)
+		c.Workspace = v1alpha1.ApplyReplacements(c.Workspace, stringReplacements)
+	}
+
 	// Apply variable expansion to the build's volumes
 	for i, v := range spec.Volumes {
 		spec.Volumes[i].Name = v1alpha1.ApplyReplacements(v.Name, stringReplacements)
@@ -40,6 +40,7 @@ var (
 		BuildGCSFetcherImage:     "gcr.io/cloud-builders/gcs-fetcher:latest",
 		PRImage:                  "override-with-pr:latest",
 		ImageDigestExporterImage: "override-with-imagedigest-exporter-image:latest",
+		WindowsShellImage:        "override-with-windows-shell:latest",
 	}
 
 	simpleTaskSpec = &v1alpha1.TaskSpec{
@@ -390,6 +391,84 @@ func TestApplyParameters(t *testing.T) {
 			spec.Steps[0].Image = "bar"
 			spec.Steps[3].Image = "bar"
 		}),
+	}, {
+		name: "parameter in init step",
+		args: args{
+			ts: &v1alpha1.TaskSpec{
+				InitSteps: []corev1.Container{{
+					Name:  "setup",
+					Image: "$(inputs.params.myimage)",
+					Args:  []string{"--image", "$(inputs.params.myimage)"},
+				}},
+			},
+			tr: paramTaskRun,
+		},
+		want: &v1alpha1.TaskSpec{
+			InitSteps: []corev1.Container{{
+				Name:  "setup",
+				Image: "bar",
+				Args:  []string{"--image", "bar"},
+			}},
+		},
+	}, {
+		name: "parameter in sidecar",
+		args: args{
+			ts: &v1alpha1.TaskSpec{
+				Sidecars: []corev1.Container{{
+					Name:  "server",
+					Image: "$(inputs.params.myimage)",
+					Env:   []corev1.EnvVar{{Name: "IMAGE", Value: "$(inputs.params.myimage)"}},
+				}},
+			},
+			tr: paramTaskRun,
+		},
+		want: &v1alpha1.TaskSpec{
+			Sidecars: []corev1.Container{{
+				Name:  "server",
+				Image: "bar",
+				Env:   []corev1.EnvVar{{Name: "IMAGE", Value: "bar"}},
+			}},
+		},
+	}, {
+		name: "parameter read from a secret",
+		args: args{
+			ts: &v1alpha1.TaskSpec{
+				Steps: []v1alpha1.Step{{Container: corev1.Container{
+					Name:  "login",
+					Image: "registry",
+					Args:  []string{"--token", "$(inputs.params.token)"},
+					Env:   []corev1.EnvVar{{Name: "HEADER", Value: "Bearer $(inputs.params.token)"}},
+				}}, {Container: corev1.Container{
+					Name:  "push",
+					Image: "registry",
+				}}},
+			},
+			tr: &v1alpha1.TaskRun{Spec: v1alpha1.TaskRunSpec{Inputs: v1alpha1.TaskRunInputs{Params: []v1alpha1.Param{{
+				Name:  "token",
+				Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString},
+				ValueFrom: &v1alpha1.ParamValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "registry"},
+					Key:                  "token",
+				}},
+			}}}}},
+		},
+		want: &v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "login",
+				Image: "registry",
+				Args:  []string{"--token", "$(TEKTON_PARAM_token)"},
+				Env: []corev1.EnvVar{{
+					Name: "TEKTON_PARAM_token",
+					ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "registry"},
+						Key:                  "token",
+					}},
+				}, {Name: "HEADER", Value: "Bearer $(TEKTON_PARAM_token)"}},
+			}}, {Container: corev1.Container{
+				Name:  "push",
+				Image: "registry",
+			}}},
+		},
 	}, {
 		name: "array parameter with 0 elements",
 		args: args{
@@ -567,6 +646,63 @@ func TestApplyParameters(t *testing.T) {
 			spec.Steps[0].Image = ""
 			spec.Steps[3].Image = ""
 		}),
+	}, {
+		name: "object parameter keys",
+		args: args{
+			ts: &v1alpha1.TaskSpec{
+				Steps: []v1alpha1.Step{{Container: corev1.Container{
+					Name:  "clone",
+					Image: "alpine/git",
+					Args:  []string{"clone", "$(inputs.params.repo.url)", "--branch=$(inputs.params.repo.revision)"},
+				}}},
+			},
+			tr: &v1alpha1.TaskRun{
+				Spec: v1alpha1.TaskRunSpec{
+					Inputs: v1alpha1.TaskRunInputs{
+						Params: []v1alpha1.Param{{
+							Name:  "repo",
+							Value: *builder.ObjectValue(map[string]string{"url": "https://github.com/tektoncd/pipeline", "revision": "v0.10.0"}),
+						}},
+					},
+				},
+			},
+			dp: []v1alpha1.ParamSpec{{
+				Name:    "repo",
+				Type:    v1alpha1.ParamTypeObject,
+				Default: builder.ObjectValue(map[string]string{"url": "https://github.com/tektoncd/triggers", "revision": "master"}),
+			}},
+		},
+		want: &v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "clone",
+				Image: "alpine/git",
+				Args:  []string{"clone", "https://github.com/tektoncd/pipeline", "--branch=v0.10.0"},
+			}}},
+		},
+	}, {
+		name: "default object parameter keys",
+		args: args{
+			ts: &v1alpha1.TaskSpec{
+				Steps: []v1alpha1.Step{{Container: corev1.Container{
+					Name:  "clone",
+					Image: "alpine/git",
+					Env:   []corev1.EnvVar{{Name: "REPO", Value: "$(inputs.params.repo.url)@$(inputs.params.repo.revision)"}},
+				}}},
+			},
+			tr: &v1alpha1.TaskRun{},
+			dp: []v1alpha1.ParamSpec{{
+				Name:    "repo",
+				Type:    v1alpha1.ParamTypeObject,
+				Default: builder.ObjectValue(map[string]string{"url": "https://github.com/tektoncd/triggers", "revision": "master"}),
+			}},
+		},
+		want: &v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "clone",
+				Image: "alpine/git",
+				Env:   []corev1.EnvVar{{Name: "REPO", Value: "https://github.com/tektoncd/triggers@master"}},
+			}}},
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -646,6 +782,55 @@ func TestApplyResources(t *testing.T) {
 	}
 }
 
+func TestApplyTaskResults(t *testing.T) {
+	ts := &v1alpha1.TaskSpec{
+		Results: []v1alpha1.TaskResult{{Name: "digest"}},
+		Steps: []v1alpha1.Step{{
+			Container: corev1.Container{Name: "build", Image: "builder", Args: []string{"--digest-file", "$(results.digest.path)"}},
+		}, {
+			Container: corev1.Container{Name: "push", Image: "pusher"},
+			Script:    "#!/bin/sh\npush > $(results.digest.path)",
+		}},
+	}
+	want := &v1alpha1.TaskSpec{
+		Results: []v1alpha1.TaskResult{{Name: "digest"}},
+		Steps: []v1alpha1.Step{{
+			Container: corev1.Container{Name: "build", Image: "builder", Args: []string{"--digest-file", "/tekton/results/digest"}},
+		}, {
+			Container: corev1.Container{Name: "push", Image: "pusher"},
+			Script:    "#!/bin/sh\npush > /tekton/results/digest",
+		}},
+	}
+	if d := cmp.Diff(want, resources.ApplyTaskResults(ts)); d != "" {
+		t.Errorf("ApplyTaskResults() got diff %s", d)
+	}
+}
+
+func TestApplyWorkspaces(t *testing.T) {
+	workspaces := []v1alpha1.WorkspaceDeclaration{{Name: "source"}, {Name: "cache", MountPath: "/cache"}}
+	ts := &v1alpha1.TaskSpec{
+		Workspaces: workspaces,
+		Steps: []v1alpha1.Step{{
+			Container: corev1.Container{Name: "build", Image: "builder", WorkingDir: "$(workspaces.source.path)"},
+		}, {
+			Container: corev1.Container{Name: "push", Image: "pusher"},
+			Script:    "#!/bin/sh\npush --cache $(workspaces.cache.path)",
+		}},
+	}
+	want := &v1alpha1.TaskSpec{
+		Workspaces: workspaces,
+		Steps: []v1alpha1.Step{{
+			Container: corev1.Container{Name: "build", Image: "builder", WorkingDir: "/workspace/source"},
+		}, {
+			Container: corev1.Container{Name: "push", Image: "pusher"},
+			Script:    "#!/bin/sh\npush --cache /cache",
+		}},
+	}
+	if d := cmp.Diff(want, resources.ApplyWorkspaces(ts)); d != "" {
+		t.Errorf("ApplyWorkspaces() got diff %s", d)
+	}
+}
+
 func TestVolumeReplacement(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,152 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	buildCacheVolumeName = "tekton-build-cache"
+	// BuildCacheDir is where the build cache volume is mounted in the steps
+	// of a run with a build profile.
+	BuildCacheDir = "/builder/build-cache"
+
+	registryConfigVolumeName = "tekton-registry-config"
+	// RegistryConfigDir is where the registry config of a build profile is
+	// mounted, and what DOCKER_CONFIG points to.
+	RegistryConfigDir = "/builder/registry-config"
+
+	// rootlessUser is the unprivileged user the steps run as by default,
+	// matching the user of the rootless BuildKit image.
+	rootlessUser int64 = 1000
+
+	apparmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+	seccompAnnotationPrefix  = "container.seccomp.security.alpha.kubernetes.io/"
+)
+
+// buildProfileStepSettings returns the volumes a build profile adds to the
+// pod, and the volume mounts and environment variables it adds to the steps.
+func buildProfileStepSettings(bp *v1alpha1.BuildProfile) ([]corev1.Volume, []corev1.VolumeMount, []corev1.EnvVar) {
+	if bp == nil {
+		return nil, nil, nil
+	}
+	volumes := []corev1.Volume{{
+		Name: buildCacheVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: bp.CacheSizeLimit},
+		},
+	}}
+	mounts := []corev1.VolumeMount{{Name: buildCacheVolumeName, MountPath: BuildCacheDir}}
+	// Rootless BuildKit can't create the sandboxes of its build steps
+	// without privileges, the pod is the sandbox.
+	env := []corev1.EnvVar{{Name: "BUILDKITD_FLAGS", Value: "--oci-worker-no-process-sandbox"}}
+
+	if bp.RegistryConfigSecret != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: registryConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: bp.RegistryConfigSecret,
+					Items: []corev1.KeyToPath{{
+						Key:  corev1.DockerConfigJsonKey,
+						Path: "config.json",
+					}},
+				},
+			},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: registryConfigVolumeName, MountPath: RegistryConfigDir, ReadOnly: true})
+		env = append(env, corev1.EnvVar{Name: "DOCKER_CONFIG", Value: RegistryConfigDir})
+	}
+	return volumes, mounts, env
+}
+
+// buildProfileSecurityContext returns the security context of a pod using a
+// build profile, running the steps as an unprivileged user unless the pod
+// template says otherwise.
+func buildProfileSecurityContext(bp *v1alpha1.BuildProfile, sc *corev1.PodSecurityContext) *corev1.PodSecurityContext {
+	if bp == nil {
+		return sc
+	}
+	if sc == nil {
+		sc = &corev1.PodSecurityContext{}
+	} else {
+		sc = sc.DeepCopy()
+	}
+	user := rootlessUser
+	if sc.RunAsUser == nil {
+		sc.RunAsUser = &user
+	}
+	if sc.RunAsGroup == nil {
+		sc.RunAsGroup = &user
+	}
+	// Makes the emptyDir volumes, like the cache, writable by the user.
+	if sc.FSGroup == nil {
+		sc.FSGroup = &user
+	}
+	return sc
+}
+
+// addBuildProfileAnnotations lifts the AppArmor and seccomp confinement of
+// the steps, which prevents the user namespaces rootless builders rely on.
+func addBuildProfileAnnotations(bp *v1alpha1.BuildProfile, annotations map[string]string, containers []corev1.Container) {
+	if bp == nil {
+		return
+	}
+	for _, c := range containers {
+		if !strings.HasPrefix(c.Name, containerPrefix) {
+			continue
+		}
+		for _, prefix := range []string{apparmorAnnotationPrefix, seccompAnnotationPrefix} {
+			if _, ok := annotations[prefix+c.Name]; !ok {
+				annotations[prefix+c.Name] = "unconfined"
+			}
+		}
+	}
+}
+
+// PrivilegedContainers returns the steps and sidecars of the Task which
+// request to run privileged, including through the step template.
+func PrivilegedContainers(taskSpec v1alpha1.TaskSpec) []string {
+	var privileged []string
+	isPrivileged := func(c corev1.Container) bool {
+		if c.SecurityContext != nil && c.SecurityContext.Privileged != nil {
+			return *c.SecurityContext.Privileged
+		}
+		t := taskSpec.StepTemplate
+		return t != nil && t.SecurityContext != nil && t.SecurityContext.Privileged != nil && *t.SecurityContext.Privileged
+	}
+	for i, s := range taskSpec.Steps {
+		if isPrivileged(s.Container) {
+			name := s.Name
+			if name == "" {
+				name = fmt.Sprintf("%d", i)
+			}
+			privileged = append(privileged, "step "+name)
+		}
+	}
+	for _, s := range taskSpec.Sidecars {
+		if s.SecurityContext != nil && s.SecurityContext.Privileged != nil && *s.SecurityContext.Privileged {
+			privileged = append(privileged, "sidecar "+s.Name)
+		}
+	}
+	return privileged
+}
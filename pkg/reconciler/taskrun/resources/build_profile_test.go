@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/test/names"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMakePodWithBuildProfile(t *testing.T) {
+	names.TestingSeed()
+	cacheSize := resource.MustParse("10Gi")
+	user := int64(0)
+	ts := v1alpha1.TaskSpec{
+		Steps: []v1alpha1.Step{{Container: corev1.Container{
+			Name:  "build",
+			Image: "moby/buildkit:rootless",
+			Env:   []corev1.EnvVar{{Name: "BUILDKITD_FLAGS", Value: "--debug"}},
+		}}},
+	}
+	tr := &v1alpha1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name"},
+		Spec: v1alpha1.TaskRunSpec{
+			PodTemplate: v1alpha1.PodTemplate{
+				SecurityContext: &corev1.PodSecurityContext{RunAsUser: &user},
+				BuildProfile: &v1alpha1.BuildProfile{
+					Mode:                 v1alpha1.BuildProfileRootless,
+					RegistryConfigSecret: "registry-creds",
+					CacheSizeLimit:       &cacheSize,
+				},
+			},
+		},
+	}
+	cs := fakek8s.NewSimpleClientset(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	got, err := MakePod(images, tr, ts, cs)
+	if err != nil {
+		t.Fatalf("MakePod: %v", err)
+	}
+
+	rootless := rootlessUser
+	wantSecurityContext := &corev1.PodSecurityContext{RunAsUser: &user, RunAsGroup: &rootless, FSGroup: &rootless}
+	if d := cmp.Diff(wantSecurityContext, got.Spec.SecurityContext); d != "" {
+		t.Errorf("Diff security context:\n%s", d)
+	}
+	if tr.Spec.PodTemplate.SecurityContext.FSGroup != nil {
+		t.Error("Expected the pod template of the TaskRun not to be modified")
+	}
+
+	wantVolumes := []corev1.Volume{{
+		Name: buildCacheVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &cacheSize},
+		},
+	}, {
+		Name: registryConfigVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: "registry-creds",
+				Items:      []corev1.KeyToPath{{Key: corev1.DockerConfigJsonKey, Path: "config.json"}},
+			},
+		},
+	}}
+	if d := cmp.Diff(wantVolumes, got.Spec.Volumes[len(implicitVolumes):len(implicitVolumes)+2], resourceQuantityCmp); d != "" {
+		t.Errorf("Diff build profile volumes:\n%s", d)
+	}
+
+	step := got.Spec.Containers[0]
+	// The environment of the step comes last, to override the profile's.
+	wantEnv := append([]corev1.EnvVar{
+		{Name: "BUILDKITD_FLAGS", Value: "--oci-worker-no-process-sandbox"},
+		{Name: "DOCKER_CONFIG", Value: RegistryConfigDir},
+	}, append(append([]corev1.EnvVar{}, implicitEnvVars...), corev1.EnvVar{Name: "BUILDKITD_FLAGS", Value: "--debug"})...)
+	if d := cmp.Diff(wantEnv, step.Env); d != "" {
+		t.Errorf("Diff step env:\n%s", d)
+	}
+	wantMounts := append(append([]corev1.VolumeMount{}, implicitVolumeMounts...),
+		corev1.VolumeMount{Name: buildCacheVolumeName, MountPath: BuildCacheDir},
+		corev1.VolumeMount{Name: registryConfigVolumeName, MountPath: RegistryConfigDir, ReadOnly: true},
+	)
+	if d := cmp.Diff(wantMounts, step.VolumeMounts); d != "" {
+		t.Errorf("Diff step volume mounts:\n%s", d)
+	}
+
+	for _, key := range []string{
+		"container.apparmor.security.beta.kubernetes.io/step-build",
+		"container.seccomp.security.alpha.kubernetes.io/step-build",
+	} {
+		if got.Annotations[key] != "unconfined" {
+			t.Errorf("Expected annotation %s to be unconfined, got annotations %v", key, got.Annotations)
+		}
+	}
+	for key := range got.Annotations {
+		if key == "container.apparmor.security.beta.kubernetes.io/"+got.Spec.InitContainers[0].Name {
+			t.Errorf("Expected init containers to stay confined, got annotation %s", key)
+		}
+	}
+}
+
+func TestPrivilegedContainers(t *testing.T) {
+	privileged, unprivileged := true, false
+	for _, tc := range []struct {
+		desc string
+		ts   v1alpha1.TaskSpec
+		want []string
+	}{{
+		desc: "no privileged containers",
+		ts: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{Name: "build"}}},
+		},
+	}, {
+		desc: "privileged steps and sidecars",
+		ts: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:            "build",
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+			}}, {Container: corev1.Container{
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+			}}},
+			Sidecars: []corev1.Container{{
+				Name:            "docker",
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+			}},
+		},
+		want: []string{"step build", "step 1", "sidecar docker"},
+	}, {
+		desc: "privileged through the step template",
+		ts: v1alpha1.TaskSpec{
+			StepTemplate: &corev1.Container{SecurityContext: &corev1.SecurityContext{Privileged: &privileged}},
+			Steps: []v1alpha1.Step{{Container: corev1.Container{Name: "build"}}, {Container: corev1.Container{
+				Name:            "test",
+				SecurityContext: &corev1.SecurityContext{Privileged: &unprivileged},
+			}}},
+		},
+		want: []string{"step build"},
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if d := cmp.Diff(tc.want, PrivilegedContainers(tc.ts)); d != "" {
+				t.Errorf("PrivilegedContainers() diff -want, +got: %v", d)
+			}
+		})
+	}
+}
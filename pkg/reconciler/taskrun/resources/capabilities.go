@@ -0,0 +1,166 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// CapabilitiesAnnotation is the annotation of the pods listing the
+// capabilities their Task requires.
+const CapabilitiesAnnotation = "tekton.dev/capabilities"
+
+// UnsatisfiableCapabilitiesError is returned when the pod of a TaskRun can't
+// run on nodes with the capabilities its Task requires.
+type UnsatisfiableCapabilitiesError struct {
+	Capabilities []string
+	Reason       string
+}
+
+func (e *UnsatisfiableCapabilitiesError) Error() string {
+	return fmt.Sprintf("can't satisfy %s: %s", strings.Join(e.Capabilities, ", "), e.Reason)
+}
+
+// ApplyCapabilities schedules pod on the nodes with the capabilities
+// required, as configured in caps, and lists them in its annotations.
+func ApplyCapabilities(pod *corev1.Pod, required []string, caps *config.Capabilities) error {
+	if len(required) == 0 {
+		return nil
+	}
+	if caps == nil {
+		caps = &config.Capabilities{}
+	}
+	// The node selector of the pod is the one of the pod template of the
+	// TaskRun.
+	nodeSelector := make(map[string]string, len(pod.Spec.NodeSelector))
+	for k, v := range pod.Spec.NodeSelector {
+		nodeSelector[k] = v
+	}
+	for _, name := range required {
+		capability, ok := caps.Capabilities[name]
+		if !ok {
+			return &UnsatisfiableCapabilitiesError{
+				Capabilities: []string{name},
+				Reason:       fmt.Sprintf("it isn't configured in %s", config.CapabilitiesConfigName),
+			}
+		}
+		keys := make([]string, 0, len(capability.NodeSelector))
+		for k := range capability.NodeSelector {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := capability.NodeSelector[k]
+			if existing, ok := nodeSelector[k]; ok && existing != v {
+				return &UnsatisfiableCapabilitiesError{
+					Capabilities: []string{name},
+					Reason:       fmt.Sprintf("its node selector %s=%s conflicts with %s=%s", k, v, k, existing),
+				}
+			}
+			nodeSelector[k] = v
+		}
+		for _, t := range capability.Tolerations {
+			if !hasToleration(pod.Spec.Tolerations, t) {
+				pod.Spec.Tolerations = append(pod.Spec.Tolerations, t)
+			}
+		}
+		if rc := capability.RuntimeClassName; rc != "" {
+			if existing := pod.Spec.RuntimeClassName; existing != nil && *existing != rc {
+				return &UnsatisfiableCapabilitiesError{
+					Capabilities: []string{name},
+					Reason:       fmt.Sprintf("its runtime class %s conflicts with %s", rc, *existing),
+				}
+			}
+			pod.Spec.RuntimeClassName = &rc
+		}
+	}
+	if len(nodeSelector) > 0 {
+		pod.Spec.NodeSelector = nodeSelector
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[CapabilitiesAnnotation] = strings.Join(required, ",")
+	return nil
+}
+
+func hasToleration(tolerations []corev1.Toleration, t corev1.Toleration) bool {
+	for _, existing := range tolerations {
+		if existing.MatchToleration(&t) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCapabilities checks a node pod can be scheduled on exists, once the
+// capabilities required were applied to it, when all of them are configured
+// in caps to check their nodes. Otherwise, the pod is left pending until a
+// node with them is added, e.g. by the cluster autoscaler. The errors
+// listing the nodes are returned as is, for the caller to leave the check to
+// the scheduler.
+func CheckCapabilities(pod *corev1.Pod, required []string, caps *config.Capabilities, nodeLister corev1listers.NodeLister) error {
+	if len(required) == 0 || caps == nil {
+		return nil
+	}
+	for _, name := range required {
+		if !caps.Capabilities[name].CheckNodes {
+			return nil
+		}
+	}
+	nodes, err := nodeLister.List(labels.SelectorFromSet(pod.Spec.NodeSelector))
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if !node.Spec.Unschedulable && toleratesTaints(pod.Spec.Tolerations, node.Spec.Taints) {
+			return nil
+		}
+	}
+	return &UnsatisfiableCapabilitiesError{
+		Capabilities: required,
+		Reason:       "no schedulable node has them",
+	}
+}
+
+// toleratesTaints reports whether the tolerations tolerate the taints which
+// keep pods off a node. The PreferNoSchedule taints don't.
+func toleratesTaints(tolerations []corev1.Toleration, taints []corev1.Taint) bool {
+	for i := range taints {
+		if taints[i].Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for _, t := range tolerations {
+			if t.ToleratesTaint(&taints[i]) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var testCapabilities = &config.Capabilities{Capabilities: map[string]config.Capability{
+	"gpu": {
+		NodeSelector: map[string]string{"accelerator": "nvidia-tesla-t4"},
+		Tolerations:  []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+	},
+	"dind":      {RuntimeClassName: "sysbox"},
+	"largedisk": {NodeSelector: map[string]string{"disk": "large"}},
+}}
+
+func TestApplyCapabilities(t *testing.T) {
+	templateSelector := map[string]string{"pool": "ci"}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		NodeSelector: templateSelector,
+		Tolerations:  []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+	}}
+	if err := ApplyCapabilities(pod, []string{"gpu", "dind", "largedisk"}, testCapabilities); err != nil {
+		t.Fatalf("ApplyCapabilities() = %v", err)
+	}
+	runtimeClassName := "sysbox"
+	want := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{CapabilitiesAnnotation: "gpu,dind,largedisk"}},
+		Spec: corev1.PodSpec{
+			NodeSelector:     map[string]string{"pool": "ci", "accelerator": "nvidia-tesla-t4", "disk": "large"},
+			Tolerations:      []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+			RuntimeClassName: &runtimeClassName,
+		},
+	}
+	if d := cmp.Diff(want, pod); d != "" {
+		t.Errorf("Diff -want, +got: %s", d)
+	}
+	if len(templateSelector) != 1 {
+		t.Errorf("Expected the node selector of the pod template not to change, got %v", templateSelector)
+	}
+
+	unchanged := &corev1.Pod{}
+	if err := ApplyCapabilities(unchanged, nil, nil); err != nil {
+		t.Fatalf("ApplyCapabilities() = %v", err)
+	}
+	if d := cmp.Diff(&corev1.Pod{}, unchanged); d != "" {
+		t.Errorf("Expected a pod without capabilities not to change, diff -want, +got: %s", d)
+	}
+}
+
+func TestApplyCapabilitiesErrors(t *testing.T) {
+	gvisor := "gvisor"
+	for _, tc := range []struct {
+		name     string
+		pod      *corev1.Pod
+		required []string
+		want     string
+	}{{
+		name:     "not configured",
+		pod:      &corev1.Pod{},
+		required: []string{"gpu", "fpga"},
+		want:     "can't satisfy fpga: it isn't configured in config-capabilities",
+	}, {
+		name:     "conflicting node selector",
+		pod:      &corev1.Pod{Spec: corev1.PodSpec{NodeSelector: map[string]string{"disk": "small"}}},
+		required: []string{"largedisk"},
+		want:     "can't satisfy largedisk: its node selector disk=large conflicts with disk=small",
+	}, {
+		name:     "conflicting runtime class",
+		pod:      &corev1.Pod{Spec: corev1.PodSpec{RuntimeClassName: &gvisor}},
+		required: []string{"dind"},
+		want:     "can't satisfy dind: its runtime class sysbox conflicts with gvisor",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ApplyCapabilities(tc.pod, tc.required, testCapabilities)
+			if !xerrors.As(err, new(*UnsatisfiableCapabilitiesError)) {
+				t.Fatalf("Expected an UnsatisfiableCapabilitiesError, got %v", err)
+			}
+			if d := cmp.Diff(tc.want, err.Error()); d != "" {
+				t.Errorf("Diff -want, +got: %s", d)
+			}
+		})
+	}
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		NodeSelector: map[string]string{"accelerator": "nvidia-tesla-t4"},
+		Tolerations:  []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+	}}
+	checked := &config.Capabilities{Capabilities: map[string]config.Capability{
+		"gpu":       {NodeSelector: pod.Spec.NodeSelector, CheckNodes: true},
+		"largedisk": {NodeSelector: map[string]string{"tekton.dev/disk": "large"}},
+	}}
+	gpuLabels := map[string]string{"accelerator": "nvidia-tesla-t4"}
+	gpuTaint := corev1.Taint{Key: "nvidia.com/gpu", Effect: corev1.TaintEffectNoSchedule}
+	for _, tc := range []struct {
+		name     string
+		required []string
+		nodes    []*corev1.Node
+		wantErr  bool
+	}{{
+		name:     "tolerated taints",
+		required: []string{"gpu"},
+		nodes: []*corev1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu", Labels: gpuLabels},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{gpuTaint, {Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule}}},
+		}},
+	}, {
+		name:     "no node",
+		required: []string{"gpu"},
+		nodes:    []*corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "cpu"}}},
+		wantErr:  true,
+	}, {
+		name:     "unschedulable",
+		required: []string{"gpu"},
+		nodes: []*corev1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu", Labels: gpuLabels},
+			Spec:       corev1.NodeSpec{Unschedulable: true},
+		}},
+		wantErr: true,
+	}, {
+		name:     "untolerated taint",
+		required: []string{"gpu"},
+		nodes: []*corev1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu", Labels: gpuLabels},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{gpuTaint, {Key: "dedicated", Value: "ml", Effect: corev1.TaintEffectNoExecute}}},
+		}},
+		wantErr: true,
+	}, {
+		name:     "not checking nodes",
+		required: []string{"gpu", "largedisk"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, n := range tc.nodes {
+				if err := indexer.Add(n); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := CheckCapabilities(pod, tc.required, checked, corev1listers.NewNodeLister(indexer))
+			if tc.wantErr != xerrors.As(err, new(*UnsatisfiableCapabilitiesError)) {
+				t.Errorf("CheckCapabilities() = %v, wanted an error: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
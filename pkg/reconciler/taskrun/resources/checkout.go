@@ -0,0 +1,63 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/names"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const checkoutContainerName = "checkout"
+
+// AddCheckoutStep prepends a step cloning the repository of the checkout of
+// the TaskRun, or else of the Task, to the steps of taskSpec, using the
+// git-init gitImage. The TaskRun's checkout replaces the Task's. The params
+// of tr, or else their defaults, are substituted in the checkout before it is
+// validated again, since the workspace could only be checked under
+// /workspace once they're known.
+func AddCheckoutStep(ctx context.Context, gitImage string, tr *v1alpha1.TaskRun, taskSpec *v1alpha1.TaskSpec, defaults ...v1alpha1.ParamSpec) error {
+	checkout := taskSpec.Checkout
+	if tr.Spec.Checkout != nil {
+		checkout = tr.Spec.Checkout
+	}
+	if checkout == nil {
+		return nil
+	}
+	checkout = ApplyParameters(&v1alpha1.TaskSpec{Checkout: checkout}, tr, defaults...).Checkout
+	if err := checkout.Validate(ctx); err != nil {
+		return xerrors.Errorf("invalid checkout: %w", err.ViaField("checkout"))
+	}
+
+	revision := checkout.Revision
+	if revision == "" {
+		revision = "master"
+	}
+	step := v1alpha1.Step{Container: corev1.Container{
+		Name:       names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(checkoutContainerName),
+		Image:      gitImage,
+		Command:    []string{"/ko-app/git-init"},
+		Args:       []string{"-url", checkout.Repo, "-revision", revision, "-path", filepath.Join(v1alpha1.WorkspaceDir, checkout.Workspace)},
+		WorkingDir: v1alpha1.WorkspaceDir,
+	}}
+	taskSpec.Steps = append([]v1alpha1.Step{step}, taskSpec.Steps...)
+	return nil
+}
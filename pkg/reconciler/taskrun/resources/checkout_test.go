@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/test/names"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddCheckoutStep(t *testing.T) {
+	taskCheckout := &v1alpha1.Checkout{Repo: "https://github.com/tektoncd/pipeline"}
+	runCheckout := &v1alpha1.Checkout{Repo: "https://github.com/tektoncd/cli", Revision: "v0.5.0", Workspace: "src/cli"}
+	checkoutStep := func(args ...string) v1alpha1.Step {
+		return v1alpha1.Step{Container: corev1.Container{
+			Name:       "checkout-9l9zj",
+			Image:      "override-with-git:latest",
+			Command:    []string{"/ko-app/git-init"},
+			Args:       args,
+			WorkingDir: "/workspace",
+		}}
+	}
+	userStep := v1alpha1.Step{Container: corev1.Container{Name: "build", Image: "golang"}}
+
+	for _, tc := range []struct {
+		name         string
+		taskCheckout *v1alpha1.Checkout
+		runCheckout  *v1alpha1.Checkout
+		params       []v1alpha1.Param
+		want         []v1alpha1.Step
+		wantErr      bool
+	}{{
+		name: "no checkout",
+		want: []v1alpha1.Step{userStep},
+	}, {
+		name:         "task checkout",
+		taskCheckout: taskCheckout,
+		want: []v1alpha1.Step{
+			checkoutStep("-url", "https://github.com/tektoncd/pipeline", "-revision", "master", "-path", "/workspace"),
+			userStep,
+		},
+	}, {
+		name:         "run checkout replaces the task's",
+		taskCheckout: taskCheckout,
+		runCheckout:  runCheckout,
+		want: []v1alpha1.Step{
+			checkoutStep("-url", "https://github.com/tektoncd/cli", "-revision", "v0.5.0", "-path", "/workspace/src/cli"),
+			userStep,
+		},
+	}, {
+		name:         "params substituted",
+		taskCheckout: &v1alpha1.Checkout{Repo: "$(inputs.params.repo)", Revision: "$(inputs.params.revision)", Workspace: "$(inputs.params.dir)"},
+		params: []v1alpha1.Param{
+			{Name: "repo", Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString, StringVal: "https://github.com/tektoncd/cli"}},
+			{Name: "dir", Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString, StringVal: "src/cli"}},
+		},
+		want: []v1alpha1.Step{
+			checkoutStep("-url", "https://github.com/tektoncd/cli", "-revision", "v0.5.0", "-path", "/workspace/src/cli"),
+			userStep,
+		},
+	}, {
+		name:         "params outside of the workspace",
+		taskCheckout: &v1alpha1.Checkout{Repo: "https://github.com/tektoncd/cli", Workspace: "$(inputs.params.dir)"},
+		params:       []v1alpha1.Param{{Name: "dir", Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString, StringVal: "../../etc"}}},
+		wantErr:      true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			names.TestingSeed()
+			ts := &v1alpha1.TaskSpec{Checkout: tc.taskCheckout, Steps: []v1alpha1.Step{userStep}}
+			tr := &v1alpha1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun", Namespace: "foo"},
+				Spec: v1alpha1.TaskRunSpec{
+					Checkout: tc.runCheckout,
+					Inputs:   v1alpha1.TaskRunInputs{Params: tc.params},
+				},
+			}
+			defaults := []v1alpha1.ParamSpec{{Name: "revision", Default: &v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString, StringVal: "v0.5.0"}}}
+
+			err := AddCheckoutStep(context.Background(), "override-with-git:latest", tr, ts, defaults...)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got the steps %v", ts.Steps)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tc.want, ts.Steps); d != "" {
+				t.Errorf("steps diff -want, +got: %v", d)
+			}
+		})
+	}
+}
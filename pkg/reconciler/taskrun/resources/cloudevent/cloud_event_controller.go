@@ -63,8 +63,8 @@ func cloudEventDeliveryFromTargets(targets []string) []v1alpha1.CloudEventDelive
 
 // SendCloudEvents is used by the TaskRun controller to send cloud events once
 // the TaskRun is complete. `tr` is used to obtain the list of targets but also
-// to construct the body of the
-func SendCloudEvents(tr *v1alpha1.TaskRun, ceclient CEClient, logger *zap.SugaredLogger) error {
+// to construct the body of the events, with its identity on cluster.
+func SendCloudEvents(tr *v1alpha1.TaskRun, cluster string, ceclient CEClient, logger *zap.SugaredLogger) error {
 	// Using multierror here so we can attempt to send all cloud events defined,
 	// regardless of whether they fail or not, and report all failed ones
 	var merr *multierror.Error
@@ -75,7 +75,7 @@ func SendCloudEvents(tr *v1alpha1.TaskRun, ceclient CEClient, logger *zap.Sugare
 		if eventStatus.Condition != v1alpha1.CloudEventConditionUnknown || eventStatus.RetryCount > 0 {
 			continue
 		}
-		_, err := SendTaskRunCloudEvent(cloudEventDelivery.Target, tr, logger, ceclient)
+		_, err := SendTaskRunCloudEvent(cloudEventDelivery.Target, tr, cluster, logger, ceclient)
 		eventStatus.SentAt = &metav1.Time{Time: time.Now()}
 		eventStatus.RetryCount++
 		if err != nil {
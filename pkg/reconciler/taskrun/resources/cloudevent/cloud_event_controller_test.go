@@ -113,7 +113,7 @@ func TestSendCloudEvents(t *testing.T) {
 			successfulBehaviour := FakeClientBehaviour{
 				SendSuccessfully: true,
 			}
-			err := SendCloudEvents(tc.taskRun, NewFakeClient(&successfulBehaviour), logger)
+			err := SendCloudEvents(tc.taskRun, "", NewFakeClient(&successfulBehaviour), logger)
 			if err != nil {
 				t.Fatalf("Unexpected error sending cloud events: %v", err)
 			}
@@ -159,7 +159,7 @@ func TestSendCloudEventsErrors(t *testing.T) {
 			unsuccessfulBehaviour := FakeClientBehaviour{
 				SendSuccessfully: false,
 			}
-			err := SendCloudEvents(tc.taskRun, NewFakeClient(&unsuccessfulBehaviour), logger)
+			err := SendCloudEvents(tc.taskRun, "", NewFakeClient(&unsuccessfulBehaviour), logger)
 			if err == nil {
 				t.Fatalf("Unexpected success sending cloud events: %v", err)
 			}
@@ -47,6 +47,9 @@ const (
 	TektonTaskRunSuccessfulV1 TektonEventType = "dev.tekton.event.task.successful.v1"
 	// TektonTaskRunFailedV1 is sent for TaskRuns with "ConditionSucceeded" "False"
 	TektonTaskRunFailedV1 TektonEventType = "dev.tekton.event.task.failed.v1"
+	// TektonTaskRunDeletedV1 is sent for TaskRuns deleted by the expiration
+	// controller
+	TektonTaskRunDeletedV1 TektonEventType = "dev.tekton.event.taskrun.deleted.v1"
 )
 
 // CEClient matches the `Client` interface from github.com/cloudevents/sdk-go/pkg/cloudevents
@@ -57,12 +60,19 @@ type CEClient client.Client
 // the possibility for the future to add more data to the payload
 type TektonCloudEventData struct {
 	TaskRun *v1alpha1.TaskRun `json:"taskRun"`
+	// Identity is the identity of the attempt of the TaskRun.
+	Identity v1alpha1.RunIdentity `json:"identity"`
+	// Reason is why the TaskRun was deleted, for TektonTaskRunDeletedV1
+	// events.
+	Reason string `json:"reason,omitempty"`
 }
 
 // NewTektonCloudEventData returns a new instance of NewTektonCloudEventData
-func NewTektonCloudEventData(taskRun *v1alpha1.TaskRun) TektonCloudEventData {
+// for taskRun running on cluster
+func NewTektonCloudEventData(taskRun *v1alpha1.TaskRun, cluster string) TektonCloudEventData {
 	return TektonCloudEventData{
-		TaskRun: taskRun,
+		TaskRun:  taskRun,
+		Identity: taskRun.RunIdentity(cluster),
 	}
 }
 
@@ -95,8 +105,8 @@ func SendCloudEvent(sinkURI, eventID, eventSourceURI string, data []byte, eventT
 	return event, nil
 }
 
-// SendTaskRunCloudEvent sends a cloud event for a TaskRun
-func SendTaskRunCloudEvent(sinkURI string, taskRun *v1alpha1.TaskRun, logger *zap.SugaredLogger, cloudEventClient CEClient) (cloudevents.Event, error) {
+// SendTaskRunCloudEvent sends a cloud event for a TaskRun running on cluster
+func SendTaskRunCloudEvent(sinkURI string, taskRun *v1alpha1.TaskRun, cluster string, logger *zap.SugaredLogger, cloudEventClient CEClient) (cloudevents.Event, error) {
 	var event cloudevents.Event
 	var err error
 	// Check if a client was provided, if not build one on the fly
@@ -125,11 +135,24 @@ func SendTaskRunCloudEvent(sinkURI string, taskRun *v1alpha1.TaskRun, logger *za
 		return event, fmt.Errorf("Unknown condition for in TaskRun.Status %s", taskRunStatus.Status)
 	}
 	eventSourceURI := taskRun.ObjectMeta.SelfLink
-	data, _ := json.Marshal(NewTektonCloudEventData(taskRun))
+	data, _ := json.Marshal(NewTektonCloudEventData(taskRun, cluster))
 	event, err = SendCloudEvent(sinkURI, eventID, eventSourceURI, data, eventType, logger, cloudEventClient)
 	return event, err
 }
 
+// SendTaskRunDeletedCloudEvent sends a cloud event for a TaskRun running on
+// cluster deleted by the expiration controller for reason
+func SendTaskRunDeletedCloudEvent(sinkURI string, taskRun *v1alpha1.TaskRun, cluster, reason string, logger *zap.SugaredLogger, cloudEventClient CEClient) (cloudevents.Event, error) {
+	data := NewTektonCloudEventData(taskRun, cluster)
+	data.Reason = reason
+	b, err := json.Marshal(data)
+	if err != nil {
+		return cloudevents.Event{}, err
+	}
+	// The name of a deleted TaskRun can be reused, its UID can't.
+	return SendCloudEvent(sinkURI, string(taskRun.UID), taskRun.ObjectMeta.SelfLink, b, TektonTaskRunDeletedV1, logger, cloudEventClient)
+}
+
 // GetCloudEventDeliveryCompareOptions returns compare options to sort
 // and compare a list of CloudEventDelivery
 func GetCloudEventDeliveryCompareOptions() []cmp.Option {
@@ -151,7 +151,7 @@ func TestSendTaskRunCloudEvent(t *testing.T) {
 		t.Run(c.desc, func(t *testing.T) {
 			logger, _ := logging.NewLogger("", "")
 			names.TestingSeed()
-			event, err := SendTaskRunCloudEvent(defaultSinkURI, c.taskRun, logger, NewFakeClient(&happyClientBehaviour))
+			event, err := SendTaskRunCloudEvent(defaultSinkURI, c.taskRun, "", logger, NewFakeClient(&happyClientBehaviour))
 			if err != nil {
 				t.Fatalf("I did not expect an error but I got %s", err)
 			} else {
@@ -163,7 +163,7 @@ func TestSendTaskRunCloudEvent(t *testing.T) {
 				if diff := cmp.Diff(string(c.wantEventType), gotEventType); diff != "" {
 					t.Errorf("Wrong Event Type (-want +got) = %s", diff)
 				}
-				wantData, _ := json.Marshal(NewTektonCloudEventData(c.taskRun))
+				wantData, _ := json.Marshal(NewTektonCloudEventData(c.taskRun, ""))
 				gotData, err := event.DataBytes()
 				if err != nil {
 					t.Fatalf("Could not get data from event %v: %v", event, err)
@@ -176,6 +176,29 @@ func TestSendTaskRunCloudEvent(t *testing.T) {
 	}
 }
 
+func TestSendTaskRunCloudEvent_Identity(t *testing.T) {
+	logger, _ := logging.NewLogger("", "")
+	tr := getTaskRunByCondition(corev1.ConditionTrue)
+	tr.UID = "5f3c1a2e"
+	event, err := SendTaskRunCloudEvent(defaultSinkURI, tr, "ci-east", logger, NewFakeClient(&happyClientBehaviour))
+	if err != nil {
+		t.Fatalf("I did not expect an error but I got %s", err)
+	}
+	var data TektonCloudEventData
+	if err := event.DataAs(&data); err != nil {
+		t.Fatalf("Could not get data from event %v: %v", event, err)
+	}
+	want := v1alpha1.RunIdentity{
+		Cluster:   "ci-east",
+		Namespace: tr.Namespace,
+		Run:       taskRunName,
+		UID:       "5f3c1a2e",
+	}
+	if diff := cmp.Diff(want, data.Identity); diff != "" {
+		t.Errorf("Wrong Event identity (-want +got) = %s", diff)
+	}
+}
+
 func TestSendTaskRunCloudEventErrors(t *testing.T) {
 	for _, c := range []struct {
 		desc          string
@@ -196,7 +219,7 @@ func TestSendTaskRunCloudEventErrors(t *testing.T) {
 		t.Run(c.desc, func(t *testing.T) {
 			logger, _ := logging.NewLogger("", "")
 			names.TestingSeed()
-			_, err := SendTaskRunCloudEvent(defaultSinkURI, c.taskRun, logger, NewFakeClient(&happyClientBehaviour))
+			_, err := SendTaskRunCloudEvent(defaultSinkURI, c.taskRun, "", logger, NewFakeClient(&happyClientBehaviour))
 			if err == nil {
 				t.Fatalf("I expected an error but I got nil")
 			} else {
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ApplyScriptStepStorageDefaults sets the ephemeral-storage request and limit
+// of the script steps of ts which neither they nor the step template of ts
+// set. Scripts typically write to the disk of their node, the kubelet evicts
+// the pods which exceed their limit instead of the node reaching disk
+// pressure. A default request greater than the limit of a step, or a default
+// limit less than its request, isn't set.
+func ApplyScriptStepStorageDefaults(ts *v1alpha1.TaskSpec, request, limit *resource.Quantity) {
+	if request == nil && limit == nil {
+		return
+	}
+	var template corev1.ResourceRequirements
+	if ts.StepTemplate != nil {
+		template = ts.StepTemplate.Resources
+	}
+	for i := range ts.Steps {
+		s := &ts.Steps[i]
+		if s.Script == "" {
+			continue
+		}
+		stepRequest, hasRequest := ephemeralStorage(s.Resources.Requests, template.Requests)
+		stepLimit, hasLimit := ephemeralStorage(s.Resources.Limits, template.Limits)
+		if request != nil && !hasRequest && (!hasLimit || request.Cmp(stepLimit) <= 0) {
+			if s.Resources.Requests == nil {
+				s.Resources.Requests = corev1.ResourceList{}
+			}
+			s.Resources.Requests[corev1.ResourceEphemeralStorage] = request.DeepCopy()
+		}
+		if limit != nil && !hasLimit && (!hasRequest || limit.Cmp(stepRequest) >= 0) {
+			if s.Resources.Limits == nil {
+				s.Resources.Limits = corev1.ResourceList{}
+			}
+			s.Resources.Limits[corev1.ResourceEphemeralStorage] = limit.DeepCopy()
+		}
+	}
+}
+
+// ephemeralStorage returns the ephemeral-storage of the resources of a step,
+// or else of the ones of the step template.
+func ephemeralStorage(step, template corev1.ResourceList) (resource.Quantity, bool) {
+	if q, ok := step[corev1.ResourceEphemeralStorage]; ok {
+		return q, true
+	}
+	q, ok := template[corev1.ResourceEphemeralStorage]
+	return q, ok
+}
+
+// SetWorkspaceSizeLimit sets the sizeLimit of the emptyDirs of the workspace
+// and home directories of the steps of pod.
+func SetWorkspaceSizeLimit(pod *corev1.Pod, limit *resource.Quantity) {
+	if limit == nil {
+		return
+	}
+	for i, v := range pod.Spec.Volumes {
+		if v.EmptyDir == nil || (v.Name != "workspace" && v.Name != "home") {
+			continue
+		}
+		// The emptyDir of the implicit volumes is shared by every pod.
+		emptyDir := v.EmptyDir.DeepCopy()
+		if emptyDir.SizeLimit == nil {
+			sizeLimit := limit.DeepCopy()
+			emptyDir.SizeLimit = &sizeLimit
+		}
+		pod.Spec.Volumes[i].EmptyDir = emptyDir
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestApplyScriptStepStorageDefaults(t *testing.T) {
+	request, limit := resource.MustParse("1Gi"), resource.MustParse("10Gi")
+	storage := func(q string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse(q)}
+	}
+	for _, c := range []struct {
+		desc     string
+		template *corev1.Container
+		step     v1alpha1.Step
+		want     corev1.ResourceRequirements
+	}{{
+		desc: "script step",
+		step: v1alpha1.Step{Script: "#!/bin/sh"},
+		want: corev1.ResourceRequirements{Requests: storage("1Gi"), Limits: storage("10Gi")},
+	}, {
+		desc: "container step",
+		step: v1alpha1.Step{Container: corev1.Container{Command: []string{"make"}}},
+	}, {
+		desc: "script step with its ephemeral storage",
+		step: v1alpha1.Step{
+			Container: corev1.Container{Resources: corev1.ResourceRequirements{Requests: storage("2Gi"), Limits: storage("4Gi")}},
+			Script:    "#!/bin/sh",
+		},
+		want: corev1.ResourceRequirements{Requests: storage("2Gi"), Limits: storage("4Gi")},
+	}, {
+		desc:     "step template with ephemeral storage",
+		template: &corev1.Container{Resources: corev1.ResourceRequirements{Limits: storage("4Gi")}},
+		step:     v1alpha1.Step{Script: "#!/bin/sh"},
+		want:     corev1.ResourceRequirements{Requests: storage("1Gi")},
+	}, {
+		desc: "script step with a limit less than the default request",
+		step: v1alpha1.Step{
+			Container: corev1.Container{Resources: corev1.ResourceRequirements{Limits: storage("512Mi")}},
+			Script:    "#!/bin/sh",
+		},
+		want: corev1.ResourceRequirements{Limits: storage("512Mi")},
+	}, {
+		desc: "script step with a request greater than the default limit",
+		step: v1alpha1.Step{
+			Container: corev1.Container{Resources: corev1.ResourceRequirements{Requests: storage("20Gi")}},
+			Script:    "#!/bin/sh",
+		},
+		want: corev1.ResourceRequirements{Requests: storage("20Gi")},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			ts := &v1alpha1.TaskSpec{StepTemplate: c.template, Steps: []v1alpha1.Step{c.step}}
+			ApplyScriptStepStorageDefaults(ts, &request, &limit)
+			if d := cmp.Diff(c.want, ts.Steps[0].Resources, resourceQuantityCmp); d != "" {
+				t.Errorf("Diff resources (-want, +got): %s", d)
+			}
+		})
+	}
+}
+
+func TestSetWorkspaceSizeLimit(t *testing.T) {
+	limit, cacheLimit := resource.MustParse("20Gi"), resource.MustParse("5Gi")
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Volumes: append(implicitVolumes, corev1.Volume{
+		Name:         buildCacheVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &cacheLimit}},
+	})}}
+	SetWorkspaceSizeLimit(pod, &limit)
+
+	want := []corev1.Volume{{
+		Name:         "workspace",
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &limit}},
+	}, {
+		Name:         "home",
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &limit}},
+	}, {
+		Name:         buildCacheVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &cacheLimit}},
+	}}
+	if d := cmp.Diff(want, pod.Spec.Volumes, resourceQuantityCmp); d != "" {
+		t.Errorf("Diff volumes (-want, +got): %s", d)
+	}
+	if emptyVolumeSource.EmptyDir.SizeLimit != nil {
+		t.Errorf("Expected the emptyDir of the implicit volumes not to be modified, got the sizeLimit %s", emptyVolumeSource.EmptyDir.SizeLimit)
+	}
+}
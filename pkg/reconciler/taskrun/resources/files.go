@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmeta"
+)
+
+const filesVolumeName = "tekton-internal-files"
+
+// FilesConfigMapConflictError is returned when the ConfigMap holding the
+// inline files of a TaskRun exists but isn't owned by it, so its pod would
+// mount files it didn't specify.
+type FilesConfigMapConflictError struct {
+	Name string
+}
+
+func (e *FilesConfigMapConflictError) Error() string {
+	return fmt.Sprintf("the ConfigMap %s of the files exists and isn't owned by the TaskRun", e.Name)
+}
+
+// FilesConfigMapName returns the name of the ConfigMap holding the inline
+// files of the TaskRun tr.
+func FilesConfigMapName(tr *v1alpha1.TaskRun) string {
+	return kmeta.ChildName(tr.Name, "-files")
+}
+
+// MakeFilesConfigMap returns the ConfigMap holding the inline files of tr,
+// which is deleted along with it, or nil if tr has none.
+func MakeFilesConfigMap(tr *v1alpha1.TaskRun) *corev1.ConfigMap {
+	if len(tr.Spec.Files) == 0 {
+		return nil
+	}
+	data := map[string]string{}
+	for _, f := range tr.Spec.Files {
+		data[f.Name] = f.Content
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: tr.Namespace,
+			Name:      FilesConfigMapName(tr),
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(tr, groupVersionKind),
+			},
+			Labels: makeLabels(tr),
+		},
+		Data: data,
+	}
+}
+
+// makeFilesVolume returns the volume of the ConfigMap holding the inline
+// files of tr, and its mount in the steps, or nil if tr has none.
+func makeFilesVolume(tr *v1alpha1.TaskRun) (*corev1.Volume, corev1.VolumeMount) {
+	if len(tr.Spec.Files) == 0 {
+		return nil, corev1.VolumeMount{}
+	}
+	return &corev1.Volume{
+		Name: filesVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: FilesConfigMapName(tr)},
+			},
+		},
+	}, corev1.VolumeMount{
+		Name:      filesVolumeName,
+		MountPath: v1alpha1.FilesDir,
+		ReadOnly:  true,
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMakeFilesConfigMap(t *testing.T) {
+	tr := &v1alpha1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "taskrun-name"},
+		Spec: v1alpha1.TaskRunSpec{
+			Files: []v1alpha1.InlineFile{
+				{Name: "settings.xml", Content: "<settings/>"},
+				{Name: ".npmrc", Content: "registry=https://npm.example.com"},
+			},
+		},
+	}
+	controller, blockOwnerDeletion := true, true
+	want := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "taskrun-name-files",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "tekton.dev/v1alpha1",
+				Kind:               "TaskRun",
+				Name:               "taskrun-name",
+				Controller:         &controller,
+				BlockOwnerDeletion: &blockOwnerDeletion,
+			}},
+			Labels: map[string]string{
+				taskRunLabelKey:   "taskrun-name",
+				ManagedByLabelKey: ManagedByLabelValue,
+			},
+		},
+		Data: map[string]string{
+			"settings.xml": "<settings/>",
+			".npmrc":       "registry=https://npm.example.com",
+		},
+	}
+	if d := cmp.Diff(want, MakeFilesConfigMap(tr)); d != "" {
+		t.Errorf("MakeFilesConfigMap() (-want, +got): %s", d)
+	}
+
+	tr.Spec.Files = nil
+	if cm := MakeFilesConfigMap(tr); cm != nil {
+		t.Errorf("MakeFilesConfigMap() = %v, want nil without files", cm)
+	}
+}
+
+func TestMakePodWithFiles(t *testing.T) {
+	ts := v1alpha1.TaskSpec{
+		Steps: []v1alpha1.Step{{
+			Container: corev1.Container{Name: "build", Image: "image"},
+		}},
+	}
+	cs := fakek8s.NewSimpleClientset(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	tr := &v1alpha1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name"},
+		Spec: v1alpha1.TaskRunSpec{
+			Files: []v1alpha1.InlineFile{{Name: "settings.xml", Content: "<settings/>"}},
+		},
+	}
+	got, err := MakePod(images, tr, ts, cs)
+	if err != nil {
+		t.Fatalf("MakePod: %v", err)
+	}
+
+	wantVolume := corev1.Volume{
+		Name: "tekton-internal-files",
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "taskrun-name-files"},
+		}},
+	}
+	var volume *corev1.Volume
+	for i, v := range got.Spec.Volumes {
+		if v.Name == wantVolume.Name {
+			volume = &got.Spec.Volumes[i]
+		}
+	}
+	if d := cmp.Diff(&wantVolume, volume); d != "" {
+		t.Errorf("Diff files volume:\n%s", d)
+	}
+	wantMount := corev1.VolumeMount{
+		Name:      "tekton-internal-files",
+		MountPath: "/tekton/files",
+		ReadOnly:  true,
+	}
+	var mount *corev1.VolumeMount
+	for i, m := range got.Spec.Containers[0].VolumeMounts {
+		if m.Name == wantMount.Name {
+			mount = &got.Spec.Containers[0].VolumeMounts[i]
+		}
+	}
+	if d := cmp.Diff(&wantMount, mount); d != "" {
+		t.Errorf("Diff files volume mount:\n%s", d)
+	}
+}
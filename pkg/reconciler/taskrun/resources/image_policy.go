@@ -0,0 +1,50 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"knative.dev/pkg/apis"
+)
+
+// ImagePolicyError is returned when the images of a TaskRun, once the
+// variables in them are replaced and its StepActions resolved, break the
+// image policy of the cluster.
+type ImagePolicyError struct {
+	Err *apis.FieldError
+}
+
+func (e *ImagePolicyError) Error() string {
+	return fmt.Sprintf("the images break the image policy of the cluster: %v", e.Err)
+}
+
+// Unwrap returns the validation error of the images.
+func (e *ImagePolicyError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateImagePolicy checks the images of ts against the image policy of
+// the cluster, returning an ImagePolicyError if they break it.
+func ValidateImagePolicy(ctx context.Context, ts *v1alpha1.TaskSpec) error {
+	if err := ts.ValidateImagePolicy(ctx); err != nil {
+		return &ImagePolicyError{Err: err}
+	}
+	return nil
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/credentials/gitcreds"
 	"github.com/tektoncd/pipeline/pkg/names"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/entrypoint"
+	"github.com/tektoncd/pipeline/pkg/secrets"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -52,6 +53,8 @@ const (
 	ManagedByLabelValue = "tekton-pipelines"
 
 	scriptsDir = "/builder/scripts"
+
+	serviceAccountTokenVolumeName = "tekton-serviceaccount-token"
 )
 
 // These are effectively const, but Go doesn't have such an annotation.
@@ -106,6 +109,8 @@ const (
 	// Prefixes to add to the name of the init containers.
 	containerPrefix            = "step-"
 	unnamedInitContainerPrefix = "step-unnamed-"
+	// Prefix to add to the name of the init steps of the Task.
+	initStepPrefix = "init-step-"
 	// Name of the credential initialization container.
 	credsInit = "credential-initializer"
 	// Name of the working dir initialization container.
@@ -185,7 +190,7 @@ func makeWorkingDirScript(workingDirs map[string]bool) string {
 
 	for _, wd := range orderedDirs {
 		p := filepath.Clean(wd)
-		if rel, err := filepath.Rel(workspaceDir, p); err == nil && !strings.HasPrefix(rel, ".") {
+		if inWorkspaceDir(p) {
 			if script == "" {
 				script = fmt.Sprintf("mkdir -p %s", p)
 			} else {
@@ -197,21 +202,107 @@ func makeWorkingDirScript(workingDirs map[string]bool) string {
 	return script
 }
 
-func makeWorkingDirInitializer(bashNoopImage string, steps []v1alpha1.Step) *v1alpha1.Step {
+// inWorkspaceDir returns whether the clean path p is a dir below the
+// workspace dir, hidden ones included.
+func inWorkspaceDir(p string) bool {
+	rel, err := filepath.Rel(workspaceDir, p)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// makeWorkingDirOwnershipScript chowns each of the working dirs created by
+// makeWorkingDirScript to the user its step runs as, so that steps running as
+// a non-root user can write to them.
+func makeWorkingDirOwnershipScript(owners map[string]int64) string {
+	var orderedDirs []string
+	for wd := range owners {
+		orderedDirs = append(orderedDirs, wd)
+	}
+	sort.Strings(orderedDirs)
+
+	var chowns []string
+	for _, wd := range orderedDirs {
+		chowns = append(chowns, fmt.Sprintf("chown %d %s", owners[wd], wd))
+	}
+	return strings.Join(chowns, " && ")
+}
+
+// stepRunAsUser returns the user the step runs as, if it isn't root.
+func stepRunAsUser(step v1alpha1.Step, podSecurityContext *corev1.PodSecurityContext) (int64, bool) {
+	var uid *int64
+	if podSecurityContext != nil {
+		uid = podSecurityContext.RunAsUser
+	}
+	if step.SecurityContext != nil && step.SecurityContext.RunAsUser != nil {
+		uid = step.SecurityContext.RunAsUser
+	}
+	if uid == nil || *uid == 0 {
+		return 0, false
+	}
+	return *uid, true
+}
+
+// runsAsUser returns whether the pod runs its containers as uid by default.
+func runsAsUser(podSecurityContext *corev1.PodSecurityContext, uid int64) bool {
+	return podSecurityContext != nil && podSecurityContext.RunAsUser != nil && *podSecurityContext.RunAsUser == uid
+}
+
+// RemoveWorkingDirInitializer removes the init container creating the
+// workingDirs of the steps from pod, if any.
+func RemoveWorkingDirInitializer(pod *corev1.Pod) {
+	initContainers := pod.Spec.InitContainers[:0]
+	for _, c := range pod.Spec.InitContainers {
+		if !strings.HasPrefix(c.Name, containerPrefix+workingDirInit) {
+			initContainers = append(initContainers, c)
+		}
+	}
+	pod.Spec.InitContainers = initContainers
+}
+
+func makeWorkingDirInitializer(bashNoopImage string, steps []v1alpha1.Step, podSecurityContext *corev1.PodSecurityContext) *v1alpha1.Step {
 	workingDirs := make(map[string]bool)
+	owners := make(map[string]int64)
+	seen := make(map[string]bool)
 	for _, step := range steps {
 		workingDirs[step.WorkingDir] = true
+		if step.WorkingDir == "" {
+			continue
+		}
+		p := filepath.Clean(step.WorkingDir)
+		if !inWorkspaceDir(p) {
+			continue
+		}
+		// The first step using the dir owns it.
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		// The init container runs as the user of the pod, whose dirs need
+		// no chown.
+		if uid, ok := stepRunAsUser(step, podSecurityContext); ok && !runsAsUser(podSecurityContext, uid) {
+			owners[p] = uid
+		}
 	}
 
 	if script := makeWorkingDirScript(workingDirs); script != "" {
+		var securityContext *corev1.SecurityContext
+		if chowns := makeWorkingDirOwnershipScript(owners); chowns != "" {
+			script = fmt.Sprintf("%s && %s", script, chowns)
+			// Only root can hand the dirs over to another user.
+			root, runAsNonRoot := int64(0), false
+			securityContext = &corev1.SecurityContext{
+				RunAsUser:    &root,
+				RunAsNonRoot: &runAsNonRoot,
+			}
+		}
 		return &v1alpha1.Step{Container: corev1.Container{
-			Name:         names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(containerPrefix + workingDirInit),
-			Image:        bashNoopImage,
-			Command:      []string{"/ko-app/bash"},
-			Args:         []string{"-args", script},
-			VolumeMounts: implicitVolumeMounts,
-			Env:          implicitEnvVars,
-			WorkingDir:   workspaceDir,
+			Name:            names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(containerPrefix + workingDirInit),
+			Image:           bashNoopImage,
+			Command:         []string{"/ko-app/bash"},
+			Args:            []string{"-args", script},
+			VolumeMounts:    implicitVolumeMounts,
+			Env:             implicitEnvVars,
+			WorkingDir:      workspaceDir,
+			SecurityContext: securityContext,
 		}}
 	}
 	return nil
@@ -257,14 +348,16 @@ func TryGetPod(taskRunStatus v1alpha1.TaskRunStatus, gp GetPod) (*corev1.Pod, er
 // MakePod converts TaskRun and TaskSpec objects to a Pod which implements the taskrun specified
 // by the supplied CRD.
 func MakePod(images pipeline.Images, taskRun *v1alpha1.TaskRun, taskSpec v1alpha1.TaskSpec, kubeclient kubernetes.Interface) (*corev1.Pod, error) {
-	cred, secrets, err := makeCredentialInitializer(images.CredsImage, taskRun.GetServiceAccountName(), taskRun.Namespace, kubeclient)
+	cred, credVolumes, err := makeCredentialInitializer(images.CredsImage, taskRun.GetServiceAccountName(), taskRun.Namespace, kubeclient)
 	if err != nil {
 		return nil, err
 	}
 	initSteps := []v1alpha1.Step{*cred}
 	var podSteps []v1alpha1.Step
 
-	if workingDir := makeWorkingDirInitializer(images.BashNoopImage, taskSpec.Steps); workingDir != nil {
+	buildProfile := taskRun.Spec.PodTemplate.BuildProfile
+	podSecurityContext := buildProfileSecurityContext(buildProfile, taskRun.Spec.PodTemplate.SecurityContext)
+	if workingDir := makeWorkingDirInitializer(images.BashNoopImage, taskSpec.Steps, podSecurityContext); workingDir != nil {
 		initSteps = append(initSteps, *workingDir)
 	}
 
@@ -272,6 +365,11 @@ func MakePod(images pipeline.Images, taskRun *v1alpha1.TaskRun, taskSpec v1alpha
 
 	maxIndicesByResource := findMaxResourceRequest(taskSpec.Steps, corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage)
 
+	tokenVolume, tokenVolumeMount := makeServiceAccountTokenVolume(taskRun.Spec.PodTemplate.ServiceAccountToken)
+	filesVolume, filesVolumeMount := makeFilesVolume(taskRun)
+	buildVolumes, buildVolumeMounts, buildEnv := buildProfileStepSettings(buildProfile)
+	workspaceVolumes, workspaceVolumeMounts := workspaceStepSettings(taskSpec.Workspaces, taskRun.Spec.Workspaces)
+
 	placeScripts := false
 	placeScriptsStep := v1alpha1.Step{Container: corev1.Container{
 		Name:         names.SimpleNameGenerator.RestrictLengthWithRandomSuffix("place-scripts"),
@@ -281,6 +379,12 @@ func MakePod(images pipeline.Images, taskRun *v1alpha1.TaskRun, taskSpec v1alpha
 		Args:         []string{"-args", ""},
 		VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
 	}}
+	// The steps of Windows pods don't have a POSIX shell to run their
+	// scripts with, nor is bash available to place them.
+	windows := taskRun.Spec.PodTemplate.IsWindows()
+	if windows {
+		makeWindowsPlaceScriptsStep(&placeScriptsStep, images.WindowsShellImage)
+	}
 
 	for i, s := range taskSpec.Steps {
 		s.Env = append(implicitEnvVars, s.Env...)
@@ -305,40 +409,55 @@ func MakePod(images pipeline.Images, taskRun *v1alpha1.TaskRun, taskSpec v1alpha
 			// Append to the place-scripts script to place the
 			// script file in a known location in the scripts volume.
 			tmpFile := filepath.Join(scriptsDir, names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("script-%d", i)))
-			// heredoc is the "here document" placeholder string
-			// used to cat script contents into the file. Typically
-			// this is the string "EOF" but if this value were
-			// "EOF" it would prevent users from including the
-			// string "EOF" in their own scripts. Instead we
-			// randomly generate a string to (hopefully) prevent
-			// collisions.
-			heredoc := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix("script-heredoc-randomly-generated")
-			// NOTE: quotes around the heredoc string are
-			// important. Without them, ${}s in the file are
-			// interpreted as env vars and likely end up replaced
-			// with empty strings. See
-			// https://stackoverflow.com/a/27921346
-			placeScriptsStep.Args[1] += fmt.Sprintf(`tmpfile="%s"
+			if windows {
+				command, err := placeWindowsScript(&placeScriptsStep, s, tmpFile)
+				if err != nil {
+					return nil, err
+				}
+				for i := 0; i < len(s.Args); i++ {
+					if s.Args[i] == "-entrypoint" {
+						s.Args = append(s.Args[:i+1], command...)
+					}
+				}
+			} else {
+				if isWindowsScript(s.Script) {
+					return nil, fmt.Errorf("the script of step %q starts with %q but the pod doesn't run on Windows: set the kubernetes.io/os node selector of its pod template to windows", s.Name, windowsShebang)
+				}
+				// heredoc is the "here document" placeholder string
+				// used to cat script contents into the file. Typically
+				// this is the string "EOF" but if this value were
+				// "EOF" it would prevent users from including the
+				// string "EOF" in their own scripts. Instead we
+				// randomly generate a string to (hopefully) prevent
+				// collisions.
+				heredoc := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix("script-heredoc-randomly-generated")
+				// NOTE: quotes around the heredoc string are
+				// important. Without them, ${}s in the file are
+				// interpreted as env vars and likely end up replaced
+				// with empty strings. See
+				// https://stackoverflow.com/a/27921346
+				placeScriptsStep.Args[1] += fmt.Sprintf(`tmpfile="%s"
 touch ${tmpfile} && chmod +x ${tmpfile}
 cat > ${tmpfile} << '%s'
 %s
 %s
 `, tmpFile, heredoc, s.Script, heredoc)
-			// The entrypoint redirecter has already run on this
-			// step, so we just need to replace the image's
-			// entrypoint (if any) with the script to run.
-			// Validation prevents step args from being passed, but
-			// just to be careful we'll replace any that survived
-			// entrypoint redirection here.
-
-			// TODO(jasonhall): It's confusing that entrypoint
-			// redirection isn't done as part of MakePod, and the
-			// interaction of these two modifications to container
-			// args might be confusing to debug in the future.
-			s.Args = append(s.Args, tmpFile)
-			for i := 0; i < len(s.Args); i++ {
-				if s.Args[i] == "-entrypoint" {
-					s.Args = append(s.Args[:i+1], tmpFile)
+				// The entrypoint redirecter has already run on this
+				// step, so we just need to replace the image's
+				// entrypoint (if any) with the script to run.
+				// Validation prevents step args from being passed, but
+				// just to be careful we'll replace any that survived
+				// entrypoint redirection here.
+
+				// TODO(jasonhall): It's confusing that entrypoint
+				// redirection isn't done as part of MakePod, and the
+				// interaction of these two modifications to container
+				// args might be confusing to debug in the future.
+				s.Args = append(s.Args, tmpFile)
+				for i := 0; i < len(s.Args); i++ {
+					if s.Args[i] == "-entrypoint" {
+						s.Args = append(s.Args[:i+1], tmpFile)
+					}
 				}
 			}
 			s.VolumeMounts = append(s.VolumeMounts, scriptsVolumeMount)
@@ -358,16 +477,45 @@ cat > ${tmpfile} << '%s'
 			initSteps = append(initSteps, s)
 		} else {
 			zeroNonMaxResourceRequests(&s, i, maxIndicesByResource)
+			if tokenVolume != nil {
+				s.VolumeMounts = append(s.VolumeMounts, tokenVolumeMount)
+			}
+			if filesVolume != nil {
+				s.VolumeMounts = append(s.VolumeMounts, filesVolumeMount)
+			}
+			if len(s.SecretRefs) > 0 {
+				// Only the init containers of the providers write the secrets.
+				secretsMount := secrets.VolumeMount()
+				secretsMount.ReadOnly = true
+				s.VolumeMounts = append(s.VolumeMounts, secretsMount)
+			}
+			s.VolumeMounts = append(s.VolumeMounts, buildVolumeMounts...)
+			s.VolumeMounts = append(s.VolumeMounts, workspaceVolumeMounts...)
+			if len(buildEnv) > 0 {
+				// Steps can still override the environment of the build profile.
+				s.Env = append(append([]corev1.EnvVar{}, buildEnv...), s.Env...)
+			}
 			podSteps = append(podSteps, s)
 		}
 	}
 
 	// Add podTemplate Volumes to the explicitly declared use volumes
-	volumes := append(taskSpec.Volumes, taskRun.Spec.PodTemplate.Volumes...)
+	volumes := withPodTemplateVolumes(taskSpec.Volumes, taskRun.Spec.PodTemplate.Volumes)
 	// Add our implicit volumes and any volumes needed for secrets to the explicitly
 	// declared user volumes.
 	volumes = append(volumes, implicitVolumes...)
-	volumes = append(volumes, secrets...)
+	volumes = append(volumes, credVolumes...)
+	// Projected tokens replace the token secret of the service account.
+	var automountServiceAccountToken *bool
+	if tokenVolume != nil {
+		volumes = append(volumes, *tokenVolume)
+		automountServiceAccountToken = new(bool)
+	}
+	if filesVolume != nil {
+		volumes = append(volumes, *filesVolume)
+	}
+	volumes = append(volumes, buildVolumes...)
+	volumes = append(volumes, workspaceVolumes...)
 
 	// Add the volume shared to place a script file, if any step specified
 	// a script.
@@ -376,6 +524,26 @@ cat > ${tmpfile} << '%s'
 		initSteps = append(initSteps, placeScriptsStep)
 	}
 
+	// Fetch the secrets referenced by the steps into an in-memory volume
+	// before the steps start.
+	secretsInitContainers, secretsSidecars, secretsVolumes, err := secrets.ContainersForSteps(taskSpec.Steps)
+	if err != nil {
+		return nil, err
+	}
+	if len(secretsInitContainers) > 0 {
+		volumes = append(volumes, secrets.Volume())
+		volumes = append(volumes, secretsVolumes...)
+		for _, c := range secretsInitContainers {
+			initSteps = append(initSteps, v1alpha1.Step{Container: c})
+		}
+	}
+
+	// The init steps of the Task run last, once the credentials and the
+	// working dirs are ready.
+	for _, c := range taskSpec.InitSteps {
+		initSteps = append(initSteps, makeInitStep(c))
+	}
+
 	if err := v1alpha1.ValidateVolumes(volumes); err != nil {
 		return nil, err
 	}
@@ -406,6 +574,10 @@ cat > ${tmpfile} << '%s'
 	if len(taskSpec.Sidecars) > 0 {
 		mergedPodContainers = append(mergedPodContainers, taskSpec.Sidecars...)
 	}
+	mergedPodContainers = append(mergedPodContainers, secretsSidecars...)
+
+	annotations := makeAnnotations(taskRun)
+	addBuildProfileAnnotations(buildProfile, annotations, mergedPodContainers)
 
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -421,24 +593,94 @@ cat > ${tmpfile} << '%s'
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(taskRun, groupVersionKind),
 			},
-			Annotations: makeAnnotations(taskRun),
+			Annotations: annotations,
 			Labels:      makeLabels(taskRun),
 		},
 		Spec: corev1.PodSpec{
-			RestartPolicy:      corev1.RestartPolicyNever,
-			InitContainers:     mergedInitContainers,
-			Containers:         mergedPodContainers,
-			ServiceAccountName: taskRun.GetServiceAccountName(),
-			Volumes:            volumes,
-			NodeSelector:       taskRun.Spec.PodTemplate.NodeSelector,
-			Tolerations:        taskRun.Spec.PodTemplate.Tolerations,
-			Affinity:           taskRun.Spec.PodTemplate.Affinity,
-			SecurityContext:    taskRun.Spec.PodTemplate.SecurityContext,
-			RuntimeClassName:   taskRun.Spec.PodTemplate.RuntimeClassName,
+			RestartPolicy:                corev1.RestartPolicyNever,
+			InitContainers:               mergedInitContainers,
+			Containers:                   mergedPodContainers,
+			ServiceAccountName:           taskRun.GetServiceAccountName(),
+			AutomountServiceAccountToken: automountServiceAccountToken,
+			Volumes:                      volumes,
+			NodeSelector:                 taskRun.Spec.PodTemplate.NodeSelector,
+			Tolerations:                  taskRun.Spec.PodTemplate.Tolerations,
+			Affinity:                     taskRun.Spec.PodTemplate.Affinity,
+			SecurityContext:              podSecurityContext,
+			RuntimeClassName:             taskRun.Spec.PodTemplate.RuntimeClassName,
 		},
 	}, nil
 }
 
+// makeInitStep returns the init container running an init step of the Task,
+// which gets the same implicit environment and volumes as the steps.
+func makeInitStep(c corev1.Container) v1alpha1.Step {
+	c.Name = names.SimpleNameGenerator.RestrictLength(initStepPrefix + c.Name)
+	c.Env = append(append([]corev1.EnvVar{}, implicitEnvVars...), c.Env...)
+	requestedVolumeMounts := map[string]bool{}
+	for _, vm := range c.VolumeMounts {
+		requestedVolumeMounts[filepath.Clean(vm.MountPath)] = true
+	}
+	for _, imp := range implicitVolumeMounts {
+		if !requestedVolumeMounts[filepath.Clean(imp.MountPath)] {
+			c.VolumeMounts = append(c.VolumeMounts, imp)
+		}
+	}
+	if c.WorkingDir == "" {
+		c.WorkingDir = workspaceDir
+	}
+	return v1alpha1.Step{Container: c}
+}
+
+// withPodTemplateVolumes returns the volumes of a Task and of the pod template
+// of its TaskRun. A volume of the pod template replaces the volume of the Task
+// with the same name, which the Task declares for its steps to mount it.
+func withPodTemplateVolumes(taskVolumes, podTemplateVolumes []corev1.Volume) []corev1.Volume {
+	replaced := map[string]struct{}{}
+	for _, v := range podTemplateVolumes {
+		replaced[v.Name] = struct{}{}
+	}
+	var volumes []corev1.Volume
+	for _, v := range taskVolumes {
+		if _, ok := replaced[v.Name]; !ok {
+			volumes = append(volumes, v)
+		}
+	}
+	return append(volumes, podTemplateVolumes...)
+}
+
+// makeServiceAccountTokenVolume returns the projected volume holding the
+// service account tokens requested by sat, and its mount in the steps.
+func makeServiceAccountTokenVolume(sat *v1alpha1.ServiceAccountTokenProjection) (*corev1.Volume, corev1.VolumeMount) {
+	if sat == nil {
+		return nil, corev1.VolumeMount{}
+	}
+	mountPath := sat.MountPath
+	if mountPath == "" {
+		mountPath = v1alpha1.DefaultServiceAccountTokenMountPath
+	}
+	var sources []corev1.VolumeProjection
+	for _, t := range sat.Tokens {
+		sources = append(sources, corev1.VolumeProjection{
+			ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+				Audience:          t.Audience,
+				ExpirationSeconds: t.ExpirationSeconds,
+				Path:              t.Path,
+			},
+		})
+	}
+	return &corev1.Volume{
+		Name: serviceAccountTokenVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		},
+	}, corev1.VolumeMount{
+		Name:      serviceAccountTokenVolumeName,
+		MountPath: mountPath,
+		ReadOnly:  true,
+	}
+}
+
 type UpdatePod func(*corev1.Pod) (*corev1.Pod, error)
 
 // AddReadyAnnotation adds the ready annotation if it is not present.
@@ -461,6 +703,12 @@ func IsContainerStep(name string) bool {
 	return strings.HasPrefix(name, containerPrefix)
 }
 
+// IsContainerInitStep returns true if the init container runs an init step
+// of the Task.
+func IsContainerInitStep(name string) bool {
+	return strings.HasPrefix(name, initStepPrefix)
+}
+
 // makeLabels constructs the labels we will propagate from TaskRuns to Pods.
 func makeLabels(s *v1alpha1.TaskRun) map[string]string {
 	labels := make(map[string]string, len(s.ObjectMeta.Labels)+1)
@@ -534,3 +782,9 @@ func findMaxResourceRequest(steps []v1alpha1.Step, resourceNames ...corev1.Resou
 func TrimContainerNamePrefix(containerName string) string {
 	return strings.TrimPrefix(containerName, containerPrefix)
 }
+
+// TrimInitStepNamePrefix returns the name of the init step run by the init
+// container.
+func TrimInitStepNamePrefix(containerName string) string {
+	return strings.TrimPrefix(containerName, initStepPrefix)
+}
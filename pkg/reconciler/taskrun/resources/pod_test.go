@@ -25,6 +25,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/secrets"
 	"github.com/tektoncd/pipeline/test/names"
 	"golang.org/x/xerrors"
 	corev1 "k8s.io/api/core/v1"
@@ -118,6 +119,8 @@ func TestMakePod(t *testing.T) {
 	})
 
 	runtimeClassName := "gvisor"
+	tokenExpirationSeconds := int64(3600)
+	podUser, stepUser, rootUser, runAsNonRoot := int64(1000), int64(2000), int64(0), false
 
 	randReader = strings.NewReader(strings.Repeat("a", 10000))
 	defer func() { randReader = rand.Reader }()
@@ -306,6 +309,125 @@ func TestMakePod(t *testing.T) {
 			},
 			RuntimeClassName: &runtimeClassName,
 		},
+	}, {
+		desc: "with-pod-template-volume-replacing-task-volume",
+		ts: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:         "name",
+				Image:        "image",
+				VolumeMounts: []corev1.VolumeMount{{Name: "cache", MountPath: "/cache"}},
+			}}},
+			Volumes: []corev1.Volume{{
+				Name:         "cache",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}},
+		},
+		trs: v1alpha1.TaskRunSpec{
+			PodTemplate: v1alpha1.PodTemplate{
+				Volumes: []corev1.Volume{{
+					Name: "cache",
+					VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: "cache",
+					}},
+				}},
+			},
+		},
+		want: &corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{{
+				Name:         containerPrefix + credsInit + "-9l9zj",
+				Image:        credsImage,
+				Command:      []string{"/ko-app/creds-init"},
+				Args:         []string{},
+				Env:          implicitEnvVars,
+				VolumeMounts: implicitVolumeMounts,
+				WorkingDir:   workspaceDir,
+			}},
+			Containers: []corev1.Container{{
+				Name:         "step-name",
+				Image:        "image",
+				Env:          implicitEnvVars,
+				VolumeMounts: append([]corev1.VolumeMount{{Name: "cache", MountPath: "/cache"}}, implicitVolumeMounts...),
+				WorkingDir:   workspaceDir,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:              resource.MustParse("0"),
+						corev1.ResourceMemory:           resource.MustParse("0"),
+						corev1.ResourceEphemeralStorage: resource.MustParse("0"),
+					},
+				},
+			}},
+			Volumes: append([]corev1.Volume{{
+				Name: "cache",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "cache",
+				}},
+			}}, implicitVolumes...),
+		},
+	}, {
+		desc: "with-service-account-token",
+		ts: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:  "name",
+				Image: "image",
+			}}},
+		},
+		trs: v1alpha1.TaskRunSpec{
+			PodTemplate: v1alpha1.PodTemplate{
+				ServiceAccountToken: &v1alpha1.ServiceAccountTokenProjection{
+					Tokens: []v1alpha1.ProjectedServiceAccountToken{{
+						Path:              "sts",
+						Audience:          "sts.amazonaws.com",
+						ExpirationSeconds: &tokenExpirationSeconds,
+					}},
+				},
+			},
+		},
+		want: &corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{{
+				Name:         containerPrefix + credsInit + "-9l9zj",
+				Image:        credsImage,
+				Command:      []string{"/ko-app/creds-init"},
+				Args:         []string{},
+				Env:          implicitEnvVars,
+				VolumeMounts: implicitVolumeMounts,
+				WorkingDir:   workspaceDir,
+			}},
+			Containers: []corev1.Container{{
+				Name:  "step-name",
+				Image: "image",
+				Env:   implicitEnvVars,
+				VolumeMounts: append(implicitVolumeMounts, corev1.VolumeMount{
+					Name:      serviceAccountTokenVolumeName,
+					MountPath: v1alpha1.DefaultServiceAccountTokenMountPath,
+					ReadOnly:  true,
+				}),
+				WorkingDir: workspaceDir,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:              resource.MustParse("0"),
+						corev1.ResourceMemory:           resource.MustParse("0"),
+						corev1.ResourceEphemeralStorage: resource.MustParse("0"),
+					},
+				},
+			}},
+			AutomountServiceAccountToken: new(bool),
+			Volumes: append(implicitVolumes, corev1.Volume{
+				Name: serviceAccountTokenVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					Projected: &corev1.ProjectedVolumeSource{
+						Sources: []corev1.VolumeProjection{{
+							ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+								Audience:          "sts.amazonaws.com",
+								ExpirationSeconds: &tokenExpirationSeconds,
+								Path:              "sts",
+							},
+						}},
+					},
+				},
+			}),
+		},
 	}, {
 		desc: "very-long-step-name",
 		ts: v1alpha1.TaskSpec{
@@ -426,6 +548,77 @@ func TestMakePod(t *testing.T) {
 			}},
 			Volumes: implicitVolumes,
 		},
+	}, {
+		desc: "working-dir-owned-by-step-user",
+		trs: v1alpha1.TaskRunSpec{
+			PodTemplate: v1alpha1.PodTemplate{
+				SecurityContext: &corev1.PodSecurityContext{RunAsUser: &podUser},
+			},
+		},
+		ts: v1alpha1.TaskSpec{
+			Steps: []v1alpha1.Step{{Container: corev1.Container{
+				Name:       "pod-user",
+				Image:      "image",
+				WorkingDir: filepath.Join(workspaceDir, "pod"),
+			}}, {Container: corev1.Container{
+				Name:            "step-user",
+				Image:           "image",
+				WorkingDir:      filepath.Join(workspaceDir, "step"),
+				SecurityContext: &corev1.SecurityContext{RunAsUser: &stepUser},
+			}}},
+		},
+		want: &corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			InitContainers: []corev1.Container{{
+				Name:         containerPrefix + credsInit + "-9l9zj",
+				Image:        credsImage,
+				Command:      []string{"/ko-app/creds-init"},
+				Args:         []string{},
+				Env:          implicitEnvVars,
+				VolumeMounts: implicitVolumeMounts,
+				WorkingDir:   workspaceDir,
+			}, {
+				Name:    containerPrefix + workingDirInit + "-mz4c7",
+				Image:   bashNoopImage,
+				Command: []string{"/ko-app/bash"},
+				Args: []string{"-args", fmt.Sprintf("mkdir -p %[1]s %[2]s && chown 2000 %[2]s",
+					filepath.Join(workspaceDir, "pod"), filepath.Join(workspaceDir, "step"))},
+				Env:             implicitEnvVars,
+				VolumeMounts:    implicitVolumeMounts,
+				WorkingDir:      workspaceDir,
+				SecurityContext: &corev1.SecurityContext{RunAsUser: &rootUser, RunAsNonRoot: &runAsNonRoot},
+			}},
+			Containers: []corev1.Container{{
+				Name:         "step-pod-user",
+				Image:        "image",
+				Env:          implicitEnvVars,
+				VolumeMounts: implicitVolumeMounts,
+				WorkingDir:   filepath.Join(workspaceDir, "pod"),
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:              resource.MustParse("0"),
+						corev1.ResourceMemory:           resource.MustParse("0"),
+						corev1.ResourceEphemeralStorage: resource.MustParse("0"),
+					},
+				},
+			}, {
+				Name:            "step-step-user",
+				Image:           "image",
+				Env:             implicitEnvVars,
+				VolumeMounts:    implicitVolumeMounts,
+				WorkingDir:      filepath.Join(workspaceDir, "step"),
+				SecurityContext: &corev1.SecurityContext{RunAsUser: &stepUser},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:              resource.MustParse("0"),
+						corev1.ResourceMemory:           resource.MustParse("0"),
+						corev1.ResourceEphemeralStorage: resource.MustParse("0"),
+					},
+				},
+			}},
+			SecurityContext: &corev1.PodSecurityContext{RunAsUser: &podUser},
+			Volumes:         implicitVolumes,
+		},
 	}, {
 		desc: "additional-sidecar-container",
 		ts: v1alpha1.TaskSpec{
@@ -617,6 +810,252 @@ script-heredoc-randomly-generated-j2tds
 	}
 }
 
+type fakeSecretsProvider struct{}
+
+func (fakeSecretsProvider) Containers(refs []v1alpha1.SecretRef, mount corev1.VolumeMount) (corev1.Container, *corev1.Container, error) {
+	var args []string
+	for _, ref := range refs {
+		args = append(args, ref.Path+"="+ref.Key)
+	}
+	return corev1.Container{Name: "fetch-secrets", Image: "secrets", Args: args, VolumeMounts: []corev1.VolumeMount{mount}},
+		&corev1.Container{Name: "renew-secrets", Image: "secrets", VolumeMounts: []corev1.VolumeMount{{Name: "leases", MountPath: "/leases"}}}, nil
+}
+
+func (fakeSecretsProvider) Volumes() []corev1.Volume {
+	return []corev1.Volume{{Name: "leases", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+}
+
+func TestMakePodWithSecretRefs(t *testing.T) {
+	names.TestingSeed()
+	secrets.Register("fake", fakeSecretsProvider{})
+	defer secrets.Unregister("fake")
+
+	ts := v1alpha1.TaskSpec{
+		Steps: []v1alpha1.Step{{
+			Container:  corev1.Container{Name: "uses-secrets", Image: "image"},
+			SecretRefs: []v1alpha1.SecretRef{{Provider: "fake", Key: "ci/token", Path: "token"}},
+		}, {
+			Container: corev1.Container{Name: "no-secrets", Image: "image"},
+		}},
+	}
+	cs := fakek8s.NewSimpleClientset(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	tr := &v1alpha1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name"}}
+	got, err := MakePod(images, tr, ts, cs)
+	if err != nil {
+		t.Fatalf("MakePod: %v", err)
+	}
+
+	wantInit := corev1.Container{
+		Name:         "fetch-secrets",
+		Image:        "secrets",
+		Args:         []string{"token=ci/token"},
+		VolumeMounts: []corev1.VolumeMount{secrets.VolumeMount()},
+	}
+	if d := cmp.Diff(wantInit, got.Spec.InitContainers[len(got.Spec.InitContainers)-1]); d != "" {
+		t.Errorf("Diff secrets init container:\n%s", d)
+	}
+	if d := cmp.Diff("renew-secrets", got.Spec.Containers[len(got.Spec.Containers)-1].Name); d != "" {
+		t.Errorf("Diff secrets sidecar:\n%s", d)
+	}
+	wantVolumes := append([]corev1.Volume{secrets.Volume()}, fakeSecretsProvider{}.Volumes()...)
+	if d := cmp.Diff(wantVolumes, got.Spec.Volumes[len(got.Spec.Volumes)-2:]); d != "" {
+		t.Errorf("Diff secrets volumes:\n%s", d)
+	}
+	mount := func(c corev1.Container) *corev1.VolumeMount {
+		for _, vm := range c.VolumeMounts {
+			if vm.Name == secrets.VolumeName {
+				return &vm
+			}
+		}
+		return nil
+	}
+	if vm := mount(got.Spec.Containers[0]); vm == nil || !vm.ReadOnly {
+		t.Errorf("Expected step with secretRefs to mount the secrets volume read-only, got %v", got.Spec.Containers[0].VolumeMounts)
+	}
+	if mount(got.Spec.Containers[1]) != nil {
+		t.Errorf("Expected step without secretRefs not to mount the secrets volume, got %v", got.Spec.Containers[1].VolumeMounts)
+	}
+
+	ts.Steps[0].SecretRefs[0].Provider = "unknown"
+	if _, err := MakePod(images, tr, ts, cs); err == nil {
+		t.Error("Expected an error for a secrets provider which isn't configured")
+	}
+}
+
+func TestMakePodWithWorkspaces(t *testing.T) {
+	names.TestingSeed()
+
+	ts := v1alpha1.TaskSpec{
+		Workspaces: []v1alpha1.WorkspaceDeclaration{{Name: "source"}, {Name: "config", MountPath: "/config", ReadOnly: true}},
+		Steps: []v1alpha1.Step{{
+			Container: corev1.Container{Name: "build", Image: "image"},
+		}},
+	}
+	cs := fakek8s.NewSimpleClientset(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	tr := &v1alpha1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name"},
+		Spec: v1alpha1.TaskRunSpec{
+			Workspaces: []v1alpha1.WorkspaceBinding{{
+				Name:                  "source",
+				SubPath:               "runs/1",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "sources"},
+			}, {
+				Name:      "config",
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "build-config"}},
+			}},
+		},
+	}
+	got, err := MakePod(images, tr, ts, cs)
+	if err != nil {
+		t.Fatalf("MakePod: %v", err)
+	}
+
+	wantVolumes := []corev1.Volume{{
+		Name:         "ws-source",
+		VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "sources"}},
+	}, {
+		Name:         "ws-config",
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "build-config"}}},
+	}}
+	if d := cmp.Diff(wantVolumes, got.Spec.Volumes[len(got.Spec.Volumes)-2:]); d != "" {
+		t.Errorf("Diff workspace volumes:\n%s", d)
+	}
+	wantMounts := []corev1.VolumeMount{{
+		Name:      "ws-source",
+		MountPath: "/workspace/source",
+		SubPath:   "runs/1",
+	}, {
+		Name:      "ws-config",
+		MountPath: "/config",
+		ReadOnly:  true,
+	}}
+	mounts := got.Spec.Containers[0].VolumeMounts
+	if d := cmp.Diff(wantMounts, mounts[len(mounts)-2:]); d != "" {
+		t.Errorf("Diff workspace volume mounts:\n%s", d)
+	}
+}
+
+func TestMakePodWithInitSteps(t *testing.T) {
+	names.TestingSeed()
+	ts := v1alpha1.TaskSpec{
+		InitSteps: []corev1.Container{{
+			Name:  "fetch-cache",
+			Image: "cache",
+			Env:   []corev1.EnvVar{{Name: "HOME", Value: "/cache"}},
+		}, {
+			Name:       "warm-up",
+			Image:      "warmer",
+			WorkingDir: "/builder",
+		}},
+		Steps: []v1alpha1.Step{{Container: corev1.Container{Name: "build", Image: "image"}}},
+	}
+	cs := fakek8s.NewSimpleClientset(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	tr := &v1alpha1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name"}}
+	got, err := MakePod(images, tr, ts, cs)
+	if err != nil {
+		t.Fatalf("MakePod: %v", err)
+	}
+
+	want := []corev1.Container{{
+		Name:  "init-step-fetch-cache",
+		Image: "cache",
+		// The environment of the init step overrides the implicit one.
+		Env:          append(append([]corev1.EnvVar{}, implicitEnvVars...), corev1.EnvVar{Name: "HOME", Value: "/cache"}),
+		VolumeMounts: implicitVolumeMounts,
+		WorkingDir:   workspaceDir,
+	}, {
+		Name:         "init-step-warm-up",
+		Image:        "warmer",
+		Env:          implicitEnvVars,
+		VolumeMounts: implicitVolumeMounts,
+		WorkingDir:   "/builder",
+	}}
+	// The init steps run after the credentials are initialized.
+	if got.Spec.InitContainers[0].Name != "step-credential-initializer-9l9zj" {
+		t.Errorf("Expected the credential initializer to run first, got %s", got.Spec.InitContainers[0].Name)
+	}
+	initContainers := got.Spec.InitContainers[len(got.Spec.InitContainers)-2:]
+	if d := cmp.Diff(want, initContainers); d != "" {
+		t.Errorf("Diff init steps:\n%s", d)
+	}
+	for _, c := range got.Spec.Containers {
+		if IsContainerInitStep(c.Name) {
+			t.Errorf("Expected init steps to run as init containers only, got container %s", c.Name)
+		}
+	}
+}
+
+func TestMakePodWithWindowsScripts(t *testing.T) {
+	names.TestingSeed()
+	ts := v1alpha1.TaskSpec{
+		Steps: []v1alpha1.Step{{
+			Container: corev1.Container{
+				Name:    "one",
+				Image:   "image",
+				Command: []string{"entrypointer"},
+				Args:    []string{"wait-file", "out-file", "-entrypoint", "image-entrypoint", "--"},
+			},
+			Script: "Write-Output 'hello'",
+		}, {
+			Container: corev1.Container{
+				Name:    "two",
+				Image:   "image",
+				Command: []string{"entrypointer"},
+				Args:    []string{"wait-file", "out-file", "-entrypoint", "image-entrypoint", "--"},
+			},
+			Script: "#!win\necho hello",
+		}},
+	}
+	cs := fakek8s.NewSimpleClientset(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	tr := &v1alpha1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name"},
+		Spec: v1alpha1.TaskRunSpec{
+			PodTemplate: v1alpha1.PodTemplate{NodeSelector: map[string]string{"kubernetes.io/os": "windows"}},
+		},
+	}
+	got, err := MakePod(images, tr, ts, cs)
+	if err != nil {
+		t.Fatalf("MakePod: %v", err)
+	}
+
+	wantPlaceScripts := corev1.Container{
+		Name:    "place-scripts-mz4c7",
+		Image:   images.WindowsShellImage,
+		Command: []string{"pwsh", "-NoProfile", "-NonInteractive", "-Command"},
+		Args: []string{`[IO.File]::WriteAllBytes('/builder/scripts/script-0-mssqb.ps1', [Convert]::FromBase64String('V3JpdGUtT3V0cHV0ICdoZWxsbyc='))
+[IO.File]::WriteAllBytes('/builder/scripts/script-1-78c5n.cmd', [Convert]::FromBase64String('ZWNobyBoZWxsbw=='))
+`},
+		VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+	}
+	placeScripts := got.Spec.InitContainers[len(got.Spec.InitContainers)-1]
+	if d := cmp.Diff(wantPlaceScripts, placeScripts); d != "" {
+		t.Errorf("Diff place-scripts init container:\n%s", d)
+	}
+	wantArgs := [][]string{
+		{"wait-file", "out-file", "-entrypoint", "pwsh", "--", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", "/builder/scripts/script-0-mssqb.ps1"},
+		{"wait-file", "out-file", "-entrypoint", "cmd.exe", "--", "/c", "/builder/scripts/script-1-78c5n.cmd"},
+	}
+	for i, c := range got.Spec.Containers {
+		if d := cmp.Diff(wantArgs[i], c.Args); d != "" {
+			t.Errorf("Diff args of %s:\n%s", c.Name, d)
+		}
+	}
+}
+
+func TestMakePodWithWindowsScriptsOnLinux(t *testing.T) {
+	ts := v1alpha1.TaskSpec{
+		Steps: []v1alpha1.Step{{
+			Container: corev1.Container{Name: "one", Image: "image"},
+			Script:    "#!win\necho hello",
+		}},
+	}
+	cs := fakek8s.NewSimpleClientset(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	tr := &v1alpha1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name"}}
+	if _, err := MakePod(images, tr, ts, cs); err == nil {
+		t.Error("Expected an error running a Windows script on Linux")
+	}
+}
+
 func TestMakeLabels(t *testing.T) {
 	taskRunName := "task-run-name"
 	for _, c := range []struct {
@@ -846,6 +1285,98 @@ func TestInitOutputResourcesDefaultDir(t *testing.T) {
 	}
 }
 
+func TestRemoveWorkingDirInitializer(t *testing.T) {
+	names.TestingSeed()
+	ts := v1alpha1.TaskSpec{
+		Steps: []v1alpha1.Step{{Container: corev1.Container{
+			Name:       "name",
+			Image:      "image",
+			WorkingDir: filepath.Join(workspaceDir, "test"),
+		}}},
+	}
+	cs := fakek8s.NewSimpleClientset(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	tr := &v1alpha1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "taskrun-name"}}
+	got, err := MakePod(images, tr, ts, cs)
+	if err != nil {
+		t.Fatalf("MakePod: %v", err)
+	}
+
+	RemoveWorkingDirInitializer(got)
+	var initContainers []string
+	for _, c := range got.Spec.InitContainers {
+		initContainers = append(initContainers, c.Name)
+	}
+	if d := cmp.Diff([]string{containerPrefix + credsInit + "-9l9zj"}, initContainers); d != "" {
+		t.Errorf("Diff init containers -want, +got: %s", d)
+	}
+}
+
+func TestMakeWorkingDirInitializerOwnership(t *testing.T) {
+	podUser, stepUser := int64(1000), int64(2000)
+	podSecurityContext := &corev1.PodSecurityContext{RunAsUser: &podUser}
+	step := func(workingDir string, uid *int64) v1alpha1.Step {
+		s := v1alpha1.Step{Container: corev1.Container{WorkingDir: workingDir}}
+		if uid != nil {
+			s.SecurityContext = &corev1.SecurityContext{RunAsUser: uid}
+		}
+		return s
+	}
+	for _, c := range []struct {
+		desc     string
+		steps    []v1alpha1.Step
+		wantArgs string
+		wantRoot bool
+	}{{
+		desc:     "pod user",
+		steps:    []v1alpha1.Step{step("/workspace/src", nil), step("/workspace/out", &podUser)},
+		wantArgs: "mkdir -p /workspace/out /workspace/src",
+	}, {
+		desc:     "hidden dir",
+		steps:    []v1alpha1.Step{step("/workspace/.cache", &stepUser)},
+		wantArgs: "mkdir -p /workspace/.cache && chown 2000 /workspace/.cache",
+		wantRoot: true,
+	}, {
+		desc:     "outside of the workspace",
+		steps:    []v1alpha1.Step{step("/workspace/../tmp", &stepUser), step("/workspace/src", nil)},
+		wantArgs: "mkdir -p /workspace/src",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			s := makeWorkingDirInitializer("bash", c.steps, podSecurityContext)
+			if s == nil {
+				t.Fatal("Expected an init container creating the working dirs")
+			}
+			if d := cmp.Diff([]string{"-args", c.wantArgs}, s.Args); d != "" {
+				t.Errorf("Diff args -want, +got: %s", d)
+			}
+			if root := s.SecurityContext != nil; root != c.wantRoot {
+				t.Errorf("Expected running as root to be %t, got security context %v", c.wantRoot, s.SecurityContext)
+			}
+		})
+	}
+}
+
+func TestMakeWorkingDirOwnershipScript(t *testing.T) {
+	for _, c := range []struct {
+		desc   string
+		owners map[string]int64
+		want   string
+	}{{
+		desc:   "none",
+		owners: map[string]int64{},
+		want:   "",
+	}, {
+		desc:   "ordered",
+		owners: map[string]int64{"/workspace/foo": 1000, "/workspace/bar": 65532},
+		want:   "chown 65532 /workspace/bar && chown 1000 /workspace/foo",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			if script := makeWorkingDirOwnershipScript(c.owners); script != c.want {
+				t.Errorf("Expected `%v`, got `%v`", c.want, script)
+			}
+		})
+	}
+}
+
 func TestMakeWorkingDirScript(t *testing.T) {
 	for _, c := range []struct {
 		desc        string
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"golang.org/x/xerrors"
+)
+
+// GetStepAction is a function used to retrieve StepActions.
+type GetStepAction func(name string) (*v1alpha1.StepAction, error)
+
+// ResolveStepActions returns a copy of ts in which the steps referencing a
+// StepAction get its image, command, args, env and script, with the params
+// they bind substituted in. ts itself is returned if none of its steps
+// reference a StepAction.
+func ResolveStepActions(ts *v1alpha1.TaskSpec, getStepAction GetStepAction) (*v1alpha1.TaskSpec, error) {
+	var resolved *v1alpha1.TaskSpec
+	for i, step := range ts.Steps {
+		if step.Ref == nil {
+			continue
+		}
+		if resolved == nil {
+			resolved = ts.DeepCopy()
+		}
+		sa, err := getStepAction(step.Ref.Name)
+		if err != nil {
+			return nil, xerrors.Errorf("couldn't get StepAction %s for step %d: %w", step.Ref.Name, i, err)
+		}
+		if err := resolveStep(&resolved.Steps[i], sa); err != nil {
+			return nil, xerrors.Errorf("couldn't bind the params of StepAction %s for step %d: %w", sa.Name, i, err)
+		}
+	}
+	if resolved == nil {
+		return ts, nil
+	}
+	return resolved, nil
+}
+
+// resolveStep sets the fields step gets from sa, and clears its reference.
+func resolveStep(step *v1alpha1.Step, sa *v1alpha1.StepAction) error {
+	stringReplacements, arrayReplacements, err := stepActionReplacements(step.Params, sa.Spec.Params)
+	if err != nil {
+		return err
+	}
+
+	// The replacements are applied in place, on a copy of the StepAction.
+	action := v1alpha1.Step{}
+	action.Image = sa.Spec.Image
+	action.Command = sa.Spec.Command
+	action.Args = sa.Spec.Args
+	for _, env := range sa.Spec.Env {
+		action.Env = append(action.Env, *env.DeepCopy())
+	}
+	v1alpha1.ApplyStepReplacements(&action, stringReplacements, arrayReplacements)
+
+	step.Image = action.Image
+	step.Command = action.Command
+	step.Args = action.Args
+	// The env of the step comes last, so that it overrides the one of the
+	// StepAction.
+	step.Env = append(action.Env, step.Env...)
+	step.Script = v1alpha1.ApplyReplacements(sa.Spec.Script, stringReplacements)
+	step.Ref = nil
+	step.Params = nil
+	return nil
+}
+
+// stepActionReplacements returns the replacements of the params of a
+// StepAction, which must all be declared by specs, and supplied by params
+// unless they have a default.
+func stepActionReplacements(params []v1alpha1.Param, specs []v1alpha1.ParamSpec) (map[string]string, map[string][]string, error) {
+	values := map[string]v1alpha1.ArrayOrString{}
+	for _, p := range params {
+		values[p.Name] = p.Value
+	}
+
+	stringReplacements := map[string]string{}
+	arrayReplacements := map[string][]string{}
+	for _, spec := range specs {
+		value, ok := values[spec.Name]
+		delete(values, spec.Name)
+		if !ok {
+			if spec.Default == nil {
+				return nil, nil, xerrors.Errorf("missing value for param %q", spec.Name)
+			}
+			value = *spec.Default
+		}
+		if spec.Type != "" && value.Type != spec.Type {
+			return nil, nil, xerrors.Errorf("param %q is of type %q, got a value of type %q", spec.Name, spec.Type, value.Type)
+		}
+		key := fmt.Sprintf("params.%s", spec.Name)
+		switch value.Type {
+		case v1alpha1.ParamTypeArray:
+			arrayReplacements[key] = value.ArrayVal
+		case v1alpha1.ParamTypeObject:
+			for k, v := range value.ObjectVal {
+				stringReplacements[fmt.Sprintf("%s.%s", key, k)] = v
+			}
+		default:
+			stringReplacements[key] = value.StringVal
+		}
+	}
+	for _, p := range params {
+		if _, ok := values[p.Name]; ok {
+			return nil, nil, xerrors.Errorf("param %q isn't declared", p.Name)
+		}
+	}
+	return stringReplacements, arrayReplacements, nil
+}
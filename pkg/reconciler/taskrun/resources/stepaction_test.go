@@ -0,0 +1,178 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var goBuildStepAction = &v1alpha1.StepAction{
+	ObjectMeta: metav1.ObjectMeta{Name: "go-build", Namespace: "foo"},
+	Spec: v1alpha1.StepActionSpec{
+		Image:   "golang:$(params.version)",
+		Command: []string{"go", "build"},
+		Args:    []string{"$(params.flags)", "$(params.package)"},
+		Env:     []corev1.EnvVar{{Name: "CGO_ENABLED", Value: "0"}, {Name: "GOOS", Value: "linux"}},
+		Params: []v1alpha1.ParamSpec{{
+			Name: "package",
+			Type: v1alpha1.ParamTypeString,
+		}, {
+			Name:    "version",
+			Type:    v1alpha1.ParamTypeString,
+			Default: &v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString, StringVal: "1.13"},
+		}, {
+			Name:    "flags",
+			Type:    v1alpha1.ParamTypeArray,
+			Default: &v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeArray, ArrayVal: []string{"-v"}},
+		}},
+	},
+}
+
+var scriptStepAction = &v1alpha1.StepAction{
+	ObjectMeta: metav1.ObjectMeta{Name: "hello", Namespace: "foo"},
+	Spec: v1alpha1.StepActionSpec{
+		Image:  "ubuntu",
+		Script: "#!/bin/sh\necho hello $(params.who)",
+		Params: []v1alpha1.ParamSpec{{Name: "who", Type: v1alpha1.ParamTypeString}},
+	},
+}
+
+func getStepAction(name string) (*v1alpha1.StepAction, error) {
+	for _, sa := range []*v1alpha1.StepAction{goBuildStepAction, scriptStepAction} {
+		if sa.Name == name {
+			return sa, nil
+		}
+	}
+	return nil, errors.NewNotFound(schema.GroupResource{Resource: "stepactions"}, name)
+}
+
+func stringParam(name, value string) v1alpha1.Param {
+	return v1alpha1.Param{Name: name, Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString, StringVal: value}}
+}
+
+func TestResolveStepActions(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		steps []v1alpha1.Step
+		want  []v1alpha1.Step
+	}{{
+		name:  "no reference",
+		steps: []v1alpha1.Step{{Container: corev1.Container{Name: "build", Image: "golang"}}},
+		want:  []v1alpha1.Step{{Container: corev1.Container{Name: "build", Image: "golang"}}},
+	}, {
+		name: "params and defaults",
+		steps: []v1alpha1.Step{{
+			Container: corev1.Container{
+				Name:       "build",
+				WorkingDir: "/workspace/src",
+				Env:        []corev1.EnvVar{{Name: "GOOS", Value: "darwin"}},
+			},
+			Ref:    &v1alpha1.StepActionRef{Name: "go-build"},
+			Params: []v1alpha1.Param{stringParam("package", "$(inputs.params.package)")},
+		}},
+		want: []v1alpha1.Step{{Container: corev1.Container{
+			Name:       "build",
+			Image:      "golang:1.13",
+			Command:    []string{"go", "build"},
+			Args:       []string{"-v", "$(inputs.params.package)"},
+			WorkingDir: "/workspace/src",
+			Env:        []corev1.EnvVar{{Name: "CGO_ENABLED", Value: "0"}, {Name: "GOOS", Value: "linux"}, {Name: "GOOS", Value: "darwin"}},
+		}}},
+	}, {
+		name: "array param",
+		steps: []v1alpha1.Step{{
+			Ref: &v1alpha1.StepActionRef{Name: "go-build"},
+			Params: []v1alpha1.Param{
+				stringParam("package", "./cmd/..."),
+				stringParam("version", "1.12"),
+				{Name: "flags", Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeArray, ArrayVal: []string{"-o", "/workspace/bin"}}},
+			},
+		}},
+		want: []v1alpha1.Step{{Container: corev1.Container{
+			Image:   "golang:1.12",
+			Command: []string{"go", "build"},
+			Args:    []string{"-o", "/workspace/bin", "./cmd/..."},
+			Env:     []corev1.EnvVar{{Name: "CGO_ENABLED", Value: "0"}, {Name: "GOOS", Value: "linux"}},
+		}}},
+	}, {
+		name: "script",
+		steps: []v1alpha1.Step{
+			{Container: corev1.Container{Name: "build", Image: "golang"}},
+			{Ref: &v1alpha1.StepActionRef{Name: "hello"}, Params: []v1alpha1.Param{stringParam("who", "world")}},
+		},
+		want: []v1alpha1.Step{
+			{Container: corev1.Container{Name: "build", Image: "golang"}},
+			{Container: corev1.Container{Image: "ubuntu"}, Script: "#!/bin/sh\necho hello world"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := &v1alpha1.TaskSpec{Steps: tc.steps}
+			original := ts.DeepCopy()
+			got, err := ResolveStepActions(ts, getStepAction)
+			if err != nil {
+				t.Fatalf("ResolveStepActions: %v", err)
+			}
+			if d := cmp.Diff(tc.want, got.Steps); d != "" {
+				t.Errorf("resolved steps diff -want, +got: %v", d)
+			}
+			if d := cmp.Diff(original, ts); d != "" {
+				t.Errorf("ResolveStepActions modified the TaskSpec: %v", d)
+			}
+		})
+	}
+}
+
+func TestResolveStepActionsError(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		step v1alpha1.Step
+	}{{
+		name: "missing step action",
+		step: v1alpha1.Step{Ref: &v1alpha1.StepActionRef{Name: "missing"}},
+	}, {
+		name: "missing param",
+		step: v1alpha1.Step{Ref: &v1alpha1.StepActionRef{Name: "hello"}},
+	}, {
+		name: "undeclared param",
+		step: v1alpha1.Step{
+			Ref:    &v1alpha1.StepActionRef{Name: "hello"},
+			Params: []v1alpha1.Param{stringParam("who", "world"), stringParam("greeting", "hi")},
+		},
+	}, {
+		name: "param type mismatch",
+		step: v1alpha1.Step{
+			Ref: &v1alpha1.StepActionRef{Name: "hello"},
+			Params: []v1alpha1.Param{{
+				Name:  "who",
+				Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeArray, ArrayVal: []string{"world"}},
+			}},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ResolveStepActions(&v1alpha1.TaskSpec{Steps: []v1alpha1.Step{tc.step}}, getStepAction); err == nil {
+				t.Error("Expected an error resolving the StepActions")
+			}
+		})
+	}
+}
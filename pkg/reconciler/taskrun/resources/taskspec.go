@@ -17,6 +17,7 @@ limitations under the License.
 package resources
 
 import (
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"golang.org/x/xerrors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -52,3 +53,19 @@ func GetTaskData(taskRun *v1alpha1.TaskRun, getTask GetTask) (*metav1.ObjectMeta
 	}
 	return &taskMeta, &taskSpec, nil
 }
+
+// VerifyTaskSpec checks that taskSpec, the spec of the Task ref references,
+// matches the checksum of ref, if it has one.
+func VerifyTaskSpec(ref *v1alpha1.TaskRef, taskSpec *v1alpha1.TaskSpec) error {
+	if ref == nil || ref.Name == "" || ref.Checksum == "" {
+		return nil
+	}
+	checksum, err := config.Checksum(taskSpec)
+	if err != nil {
+		return xerrors.Errorf("couldn't compute the checksum of %s %s: %w", ref.Kind, ref.Name, err)
+	}
+	if checksum != ref.Checksum {
+		return xerrors.Errorf("%s %s doesn't match the expected checksum %s, its checksum is %s", ref.Kind, ref.Name, ref.Checksum, checksum)
+	}
+	return nil
+}
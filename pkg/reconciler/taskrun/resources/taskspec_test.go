@@ -19,6 +19,7 @@ package resources
 import (
 	"testing"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"golang.org/x/xerrors"
 	corev1 "k8s.io/api/core/v1"
@@ -121,3 +122,47 @@ func TestGetTaskSpec_Error(t *testing.T) {
 		t.Fatalf("Expected error when unable to find referenced Task but got none")
 	}
 }
+
+func TestVerifyTaskSpec(t *testing.T) {
+	spec := &v1alpha1.TaskSpec{
+		Steps: []v1alpha1.Step{{Container: corev1.Container{
+			Name:  "step1",
+			Image: "ubuntu",
+		}}},
+	}
+	checksum, err := config.Checksum(spec)
+	if err != nil {
+		t.Fatalf("Checksum() = %v", err)
+	}
+	edited := spec.DeepCopy()
+	edited.Steps[0].Image = "busybox"
+
+	for _, tc := range []struct {
+		name    string
+		ref     *v1alpha1.TaskRef
+		spec    *v1alpha1.TaskSpec
+		wantErr bool
+	}{{
+		name: "no reference",
+		spec: spec,
+	}, {
+		name: "no checksum",
+		ref:  &v1alpha1.TaskRef{Name: "orchestrate"},
+		spec: edited,
+	}, {
+		name: "matching checksum",
+		ref:  &v1alpha1.TaskRef{Name: "orchestrate", Checksum: checksum},
+		spec: spec,
+	}, {
+		name:    "edited task",
+		ref:     &v1alpha1.TaskRef{Name: "orchestrate", Checksum: checksum},
+		spec:    edited,
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := VerifyTaskSpec(tc.ref, tc.spec); (err != nil) != tc.wantErr {
+				t.Errorf("VerifyTaskSpec() = %v, want error: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
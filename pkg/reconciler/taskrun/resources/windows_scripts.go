@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+// windowsShebang starts the first line of the scripts naming the Windows
+// command running them, e.g. "#!win pwsh.exe -File". The scripts starting
+// with a line of it alone are run with cmd.exe.
+const windowsShebang = "#!win"
+
+var (
+	// powerShell runs the scripts of the steps of Windows pods without a
+	// shebang with PowerShell 7, the one the nanoserver images ship.
+	powerShell = []string{"pwsh", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File"}
+	cmd        = []string{"cmd.exe", "/c"}
+
+	// windowsPlaceScriptsCommand writes the scripts of the steps of Windows
+	// pods, decoding them from base64 so that they don't need escaping.
+	windowsPlaceScriptsCommand = []string{"pwsh", "-NoProfile", "-NonInteractive", "-Command"}
+
+	// windowsScriptExtensions are the extensions the script files need to
+	// be run by the Windows commands.
+	windowsScriptExtensions = map[string]string{
+		"pwsh":       ".ps1",
+		"powershell": ".ps1",
+		"cmd":        ".cmd",
+	}
+)
+
+// isWindowsScript returns whether script starts with the Windows shebang.
+func isWindowsScript(script string) bool {
+	firstLine := strings.SplitN(strings.TrimSpace(script), "\n", 2)[0]
+	return firstLine == windowsShebang || strings.HasPrefix(firstLine, windowsShebang+" ")
+}
+
+// windowsScript returns the command running script on Windows, the
+// extension its file needs and its content without the Windows shebang.
+func windowsScript(step string, script string) ([]string, string, string, error) {
+	trimmed := strings.TrimSpace(script)
+	if !isWindowsScript(trimmed) {
+		if strings.HasPrefix(trimmed, "#!") {
+			return nil, "", "", fmt.Errorf("the script of step %q starts with a shebang, which Windows doesn't run: use %q instead", step, windowsShebang+" <command>")
+		}
+		return powerShell, ".ps1", script, nil
+	}
+	lines := strings.SplitN(trimmed, "\n", 2)
+	var body string
+	if len(lines) > 1 {
+		body = lines[1]
+	}
+	command := strings.Fields(strings.TrimPrefix(lines[0], windowsShebang))
+	if len(command) == 0 {
+		return cmd, ".cmd", body, nil
+	}
+	name := command[0][strings.LastIndexAny(command[0], "/\\")+1:]
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	return command, windowsScriptExtensions[name], body, nil
+}
+
+// makeWindowsPlaceScriptsStep turns placeScriptsStep into the init
+// container placing the scripts of the steps of a Windows pod with
+// PowerShell in image.
+func makeWindowsPlaceScriptsStep(placeScriptsStep *v1alpha1.Step, image string) {
+	placeScriptsStep.Image = image
+	placeScriptsStep.TTY = false
+	placeScriptsStep.Command = windowsPlaceScriptsCommand
+	placeScriptsStep.Args = []string{""}
+}
+
+// placeWindowsScript adds the placement of the script of step s at tmpFile,
+// plus the extension its command needs, to the PowerShell command of
+// placeScriptsStep, and returns the command running it.
+func placeWindowsScript(placeScriptsStep *v1alpha1.Step, s v1alpha1.Step, tmpFile string) ([]string, error) {
+	command, ext, body, err := windowsScript(s.Name, s.Script)
+	if err != nil {
+		return nil, err
+	}
+	tmpFile += ext
+	placeScriptsStep.Args[0] += fmt.Sprintf("[IO.File]::WriteAllBytes('%s', [Convert]::FromBase64String('%s'))\n",
+		tmpFile, base64.StdEncoding.EncodeToString([]byte(body)))
+	// The entrypoint runs the first item of the command with the others and
+	// the script as its args.
+	return append(append([]string{command[0], "--"}, command[1:]...), tmpFile), nil
+}
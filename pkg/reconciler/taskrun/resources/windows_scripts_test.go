@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWindowsScript(t *testing.T) {
+	for _, c := range []struct {
+		desc        string
+		script      string
+		wantCommand []string
+		wantExt     string
+		wantBody    string
+	}{{
+		desc:        "no shebang",
+		script:      "Write-Output 'hello'",
+		wantCommand: powerShell,
+		wantExt:     ".ps1",
+		wantBody:    "Write-Output 'hello'",
+	}, {
+		desc:        "cmd",
+		script:      "#!win\necho hello",
+		wantCommand: cmd,
+		wantExt:     ".cmd",
+		wantBody:    "echo hello",
+	}, {
+		desc:        "pwsh",
+		script:      "#!win pwsh.exe -File\nWrite-Output 'hello'",
+		wantCommand: []string{"pwsh.exe", "-File"},
+		wantExt:     ".ps1",
+		wantBody:    "Write-Output 'hello'",
+	}, {
+		desc:        "path",
+		script:      "#!win C:\\Windows\\System32\\CMD.EXE /c\necho hello",
+		wantCommand: []string{"C:\\Windows\\System32\\CMD.EXE", "/c"},
+		wantExt:     ".cmd",
+		wantBody:    "echo hello",
+	}, {
+		desc:        "other command",
+		script:      "#!win python\nprint('hello')",
+		wantCommand: []string{"python"},
+		wantBody:    "print('hello')",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			command, ext, body, err := windowsScript("step", c.script)
+			if err != nil {
+				t.Fatalf("windowsScript: %v", err)
+			}
+			if d := cmp.Diff(c.wantCommand, command); d != "" {
+				t.Errorf("Diff command:\n%s", d)
+			}
+			if ext != c.wantExt {
+				t.Errorf("Extension got %q, want %q", ext, c.wantExt)
+			}
+			if body != c.wantBody {
+				t.Errorf("Body got %q, want %q", body, c.wantBody)
+			}
+		})
+	}
+}
+
+func TestWindowsScript_PosixShebang(t *testing.T) {
+	if _, _, _, err := windowsScript("step", "#!/bin/sh\necho hello"); err == nil {
+		t.Error("Expected an error for a POSIX shebang")
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/names"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const workspaceVolumePrefix = "ws-"
+
+// workspaceStepSettings returns the volumes the bindings of the workspaces
+// add to the pod, and the volume mounts they add to the steps. Workspaces
+// without a binding, which validation rejects, are left out.
+func workspaceStepSettings(declarations []v1alpha1.WorkspaceDeclaration, bindings []v1alpha1.WorkspaceBinding) ([]corev1.Volume, []corev1.VolumeMount) {
+	bound := map[string]v1alpha1.WorkspaceBinding{}
+	for _, b := range bindings {
+		bound[b.Name] = b
+	}
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for _, w := range declarations {
+		b, ok := bound[w.Name]
+		if !ok {
+			continue
+		}
+		name := names.SimpleNameGenerator.RestrictLength(workspaceVolumePrefix + w.Name)
+		volumes = append(volumes, corev1.Volume{
+			Name:         name,
+			VolumeSource: workspaceVolumeSource(b),
+		})
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: w.GetMountPath(),
+			SubPath:   b.SubPath,
+			ReadOnly:  w.ReadOnly,
+		})
+	}
+	return volumes, mounts
+}
+
+func workspaceVolumeSource(b v1alpha1.WorkspaceBinding) corev1.VolumeSource {
+	switch {
+	case b.PersistentVolumeClaim != nil:
+		return corev1.VolumeSource{PersistentVolumeClaim: b.PersistentVolumeClaim}
+	case b.ConfigMap != nil:
+		return corev1.VolumeSource{ConfigMap: b.ConfigMap}
+	case b.Secret != nil:
+		return corev1.VolumeSource{Secret: b.Secret}
+	default:
+		emptyDir := b.EmptyDir
+		if emptyDir == nil {
+			emptyDir = &corev1.EmptyDirVolumeSource{}
+		}
+		return corev1.VolumeSource{EmptyDir: emptyDir}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// SweepOptions configures a Sweep.
+type SweepOptions struct {
+	// Namespace is the namespace to sweep, all of them if empty.
+	Namespace string
+	// TTL is applied to the finished TaskRuns which don't have an
+	// TTL of their own. Zero leaves them alone.
+	TTL time.Duration
+	// Scope restricts which TaskRuns are deleted.
+	Scope ExpirationScope
+	// Limiter, if set, throttles the deletions.
+	Limiter flowcontrol.RateLimiter
+	// DryRun only logs the TaskRuns which would be deleted.
+	DryRun bool
+	// Clock tells the time the TaskRuns expire at. Nil is the real clock.
+	Clock clock.Clock
+}
+
+// Sweep deletes all the expired TaskRuns at once, including those created
+// before TTLs could be set on them, and returns their keys. It is meant to
+// clear the backlog of finished TaskRuns when enabling TTL cleanup, after
+// which the expiration controller keeps up with new ones. The TaskRuns to be
+// archived on expiration get their TaskRunArchive before being deleted.
+func Sweep(client versioned.Interface, opts SweepOptions, logger *zap.SugaredLogger) ([]string, error) {
+	trs, err := client.TektonV1alpha1().TaskRuns(opts.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("listing TaskRuns: %w", err)
+	}
+
+	var deleted []string
+	c := opts.Clock
+	if c == nil {
+		c = clock.RealClock{}
+	}
+	now := c.Now()
+	for i := range trs.Items {
+		tr := &trs.Items[i]
+		if !sweepable(tr, opts, now) {
+			continue
+		}
+		key := tr.Namespace + "/" + tr.Name
+		if opts.DryRun {
+			logger.Infof("Would delete expired TaskRun %s", key)
+			deleted = append(deleted, key)
+			continue
+		}
+		if opts.Limiter != nil {
+			opts.Limiter.Accept()
+		}
+		logger.Infof("Deleting expired TaskRun %s", key)
+		if err := archiveToTaskRunArchive(client, tr); err != nil {
+			return deleted, err
+		}
+		policy := metav1.DeletePropagationForeground
+		err := client.TektonV1alpha1().TaskRuns(tr.Namespace).Delete(tr.Name, &metav1.DeleteOptions{
+			PropagationPolicy: &policy,
+			Preconditions:     &metav1.Preconditions{UID: &tr.UID},
+		})
+		if err != nil && !errors.IsNotFound(err) {
+			return deleted, xerrors.Errorf("deleting TaskRun %s: %w", key, err)
+		}
+		deleted = append(deleted, key)
+	}
+	return deleted, nil
+}
+
+// sweepable returns whether tr is expired at now, applying opts.TTL if it
+// doesn't have a TTL of its own.
+func sweepable(tr *v1alpha1.TaskRun, opts SweepOptions, now time.Time) bool {
+	if tr.TTL() == nil {
+		if opts.TTL <= 0 {
+			return false
+		}
+		tr = tr.DeepCopy()
+		tr.Spec.ExpirationSecondsTTL = &metav1.Duration{Duration: opts.TTL}
+	}
+	if !opts.Scope.Matches(tr) || !Expires(tr) {
+		return false
+	}
+	remaining, err := TimeLeft(tr, &now)
+	return err == nil && *remaining <= 0
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	"github.com/tektoncd/pipeline/test"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/pkg/apis"
+)
+
+func TestSweep(t *testing.T) {
+	legacy := func(name string, finishedAgo time.Duration) *v1alpha1.TaskRun {
+		return tb.TaskRun(name, "foo", tb.TaskRunStatus(
+			tb.StatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse}),
+			tb.TaskRunCompletionTime(testNow.Add(-finishedAgo)),
+		))
+	}
+	trs := []*v1alpha1.TaskRun{
+		finishedTaskRun("expired", 2*time.Hour),
+		finishedTaskRun("not-expired", time.Minute),
+		finishedTaskRun("kept", 2*time.Hour, tb.TaskRunAnnotation(KeepAnnotationKey, "true")),
+		legacy("legacy-old", 48*time.Hour),
+		legacy("legacy-recent", time.Hour),
+		tb.TaskRun("running", "foo", tb.TaskRunStatus(tb.StatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionUnknown}))),
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts SweepOptions
+		want []string
+	}{{
+		name: "only runs with a ttl",
+		want: []string{"foo/expired"},
+	}, {
+		name: "default ttl",
+		opts: SweepOptions{TTL: 24 * time.Hour},
+		want: []string{"foo/expired", "foo/legacy-old"},
+	}, {
+		name: "dry run",
+		opts: SweepOptions{TTL: 24 * time.Hour, DryRun: true},
+		want: []string{"foo/expired", "foo/legacy-old"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: trs})
+
+			tc.opts.Clock = clock.NewFakeClock(testNow)
+			got, err := Sweep(c.Pipeline, tc.opts, zap.NewNop().Sugar())
+			if err != nil {
+				t.Fatalf("Sweep: %v", err)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("swept TaskRuns diff -want, +got: %v", d)
+			}
+
+			remaining, err := c.Pipeline.TektonV1alpha1().TaskRuns("foo").List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantRemaining := len(trs) - len(tc.want)
+			if tc.opts.DryRun {
+				wantRemaining = len(trs)
+			}
+			if len(remaining.Items) != wantRemaining {
+				t.Errorf("Expected %d TaskRuns left, got %d", wantRemaining, len(remaining.Items))
+			}
+		})
+	}
+}
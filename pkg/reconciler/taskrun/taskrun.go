@@ -29,6 +29,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
+	pkgentrypoint "github.com/tektoncd/pipeline/pkg/entrypoint"
 	"github.com/tektoncd/pipeline/pkg/reconciler"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/entrypoint"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
@@ -42,9 +43,13 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/apis"
+	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/tracker"
 )
@@ -59,6 +64,11 @@ const (
 	// built images digest
 )
 
+type configStore interface {
+	ToContext(ctx context.Context) context.Context
+	WatchConfigs(w configmap.Watcher)
+}
+
 // Reconciler implements controller.Reconciler for Configuration resources.
 type Reconciler struct {
 	*reconciler.Base
@@ -67,12 +77,25 @@ type Reconciler struct {
 	taskRunLister     listers.TaskRunLister
 	taskLister        listers.TaskLister
 	clusterTaskLister listers.ClusterTaskLister
+	pipelineRunLister listers.PipelineRunLister
 	resourceLister    listers.PipelineResourceLister
+	stepActionLister  listers.StepActionLister
+	quotaLister       listers.PipelineQuotaLister
+	nodeLister        corev1listers.NodeLister
 	cloudEventClient  cloudevent.CEClient
 	tracker           tracker.Interface
 	cache             *entrypoint.Cache
 	timeoutHandler    *reconciler.TimeoutSet
 	metrics           *Recorder
+	configStore       configStore
+	clock             clock.Clock
+	// enqueueAfter is the one of the controller.Impl the reconciler was
+	// created with.
+	enqueueAfter func(obj interface{}, after time.Duration)
+
+	// clusterTaskAccessReview makes the reconciler check that the creators
+	// of the TaskRuns may use the ClusterTasks they reference.
+	clusterTaskAccessReview bool
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -93,6 +116,8 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 		return nil
 	}
 
+	ctx = c.configStore.ToContext(ctx)
+
 	// Get the Task Run resource with this namespace/name
 	original, err := c.taskRunLister.TaskRuns(namespace).Get(name)
 	if errors.IsNotFound(err) {
@@ -107,6 +132,30 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 	// Don't modify the informer's copy.
 	tr := original.DeepCopy()
 
+	// A TaskRun created outside of its execution window waits for it to
+	// open before starting, so that its timeout doesn't count down.
+	if !tr.HasStarted() && !tr.IsCancelled() && tr.Spec.ExecutionWindow != nil {
+		if wait := reconciler.WaitForExecutionWindow(&tr.Status, tr.Spec.ExecutionWindow, c.clock.Now()); wait > 0 {
+			c.enqueueAfter(tr, wait)
+			return c.updateStatusLabelsAndAnnotations(tr, original)
+		}
+	}
+
+	// A TaskRun which doesn't belong to a PipelineRun is counted against
+	// the PipelineQuotas of its namespace, and waits for them to let it
+	// start.
+	if !tr.HasStarted() && !tr.IsCancelled() && !ownedByPipelineRun(tr) {
+		wait, err := c.waitForQuotas(tr)
+		if err != nil {
+			c.Logger.Errorf("Failed to check the quotas of TaskRun %q: %v", tr.Name, err)
+			return err
+		}
+		if wait > 0 {
+			c.enqueueAfter(tr, wait)
+			return c.updateStatusLabelsAndAnnotations(tr, original)
+		}
+	}
+
 	// If the TaskRun is just starting, this will also set the starttime,
 	// from which the timeout will immediately begin counting down.
 	tr.Status.InitializeConditions()
@@ -120,7 +169,7 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 		c.Logger.Infof("taskrun done : %s \n", tr.Name)
 		var merr *multierror.Error
 		// Try to send cloud events first
-		cloudEventErr := cloudevent.SendCloudEvents(tr, c.cloudEventClient, c.Logger)
+		cloudEventErr := cloudevent.SendCloudEvents(tr, config.FromContextOrDefaults(ctx).Defaults.ClusterName, c.cloudEventClient, c.Logger)
 		// Regardless of `err`, we must write back any status update that may have
 		// been generated by `sendCloudEvents`
 		updateErr := c.updateStatusLabelsAndAnnotations(tr, original)
@@ -243,12 +292,35 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1alpha1.TaskRun) error
 	taskMeta, taskSpec, err := resources.GetTaskData(tr, getTaskFunc)
 	if err != nil {
 		c.Logger.Errorf("Failed to determine Task spec to use for taskrun %s: %v", tr.Name, err)
-		tr.Status.SetCondition(&apis.Condition{
-			Type:    apis.ConditionSucceeded,
-			Status:  corev1.ConditionFalse,
-			Reason:  status.ReasonFailedResolution,
-			Message: err.Error(),
-		})
+		status.MarkFailed(&tr.Status, status.ReasonFailedResolution, "%v", err)
+		return nil
+	}
+	// The Task is only verified until the pod is created, the TaskRun isn't
+	// affected by the edits made to it later on.
+	if tr.Status.PodName == "" {
+		if err := resources.VerifyTaskSpec(tr.Spec.TaskRef, taskSpec); err != nil {
+			c.Logger.Errorf("Failed to verify the Task of taskrun %s: %v", tr.Name, err)
+			status.MarkFailed(&tr.Status, status.ReasonTaskVerificationFailed, "%v", err)
+			return nil
+		}
+	}
+	// The access is only reviewed until the pod is created, the TaskRun
+	// keeps running if it is revoked later on.
+	if kind == v1alpha1.ClusterTaskKind && c.clusterTaskAccessReview && tr.Status.PodName == "" {
+		denied, err := c.reviewClusterTaskAccess(tr, taskMeta.Name)
+		if err != nil {
+			return xerrors.Errorf("couldn't review the access of TaskRun %s to ClusterTask %s: %w", tr.Name, taskMeta.Name, err)
+		}
+		if denied != "" {
+			c.Logger.Warnf("Denied TaskRun %s/%s the use of ClusterTask %s: %s", tr.Namespace, tr.Name, taskMeta.Name, denied)
+			status.MarkFailed(&tr.Status, status.ReasonClusterTaskAccessDenied, "%s", denied)
+			return nil
+		}
+	}
+	taskSpec, err = resources.ResolveStepActions(taskSpec, c.stepActionLister.StepActions(tr.Namespace).Get)
+	if err != nil {
+		c.Logger.Errorf("Failed to resolve the StepActions of taskrun %s: %v", tr.Name, err)
+		status.MarkFailed(&tr.Status, status.ReasonFailedResolution, "%v", err)
 		return nil
 	}
 
@@ -268,6 +340,10 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1alpha1.TaskRun) error
 		tr.ObjectMeta.Annotations = make(map[string]string, len(taskMeta.Annotations))
 	}
 	for key, value := range taskMeta.Annotations {
+		// Who created the TaskRun is the webhook's to tell, not the Task's.
+		if key == v1alpha1.CreatorAnnotationKey || key == v1alpha1.CreatorGroupsAnnotationKey || key == pipeline.GroupName+apis.UpdaterAnnotationSuffix {
+			continue
+		}
 		tr.ObjectMeta.Annotations[key] = value
 	}
 
@@ -286,23 +362,19 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1alpha1.TaskRun) error
 	rtr, err := resources.ResolveTaskResources(taskSpec, taskMeta.Name, kind, tr.Spec.Inputs.Resources, tr.Spec.Outputs.Resources, c.resourceLister.PipelineResources(tr.Namespace).Get)
 	if err != nil {
 		c.Logger.Errorf("Failed to resolve references for taskrun %s: %v", tr.Name, err)
-		tr.Status.SetCondition(&apis.Condition{
-			Type:    apis.ConditionSucceeded,
-			Status:  corev1.ConditionFalse,
-			Reason:  status.ReasonFailedResolution,
-			Message: err.Error(),
-		})
+		status.MarkFailed(&tr.Status, status.ReasonFailedResolution, "%v", err)
 		return nil
 	}
 
 	if err := ValidateResolvedTaskResources(tr.Spec.Inputs.Params, rtr); err != nil {
 		c.Logger.Errorf("Failed to validate taskrun %q: %v", tr.Name, err)
-		tr.Status.SetCondition(&apis.Condition{
-			Type:    apis.ConditionSucceeded,
-			Status:  corev1.ConditionFalse,
-			Reason:  status.ReasonFailedValidation,
-			Message: err.Error(),
-		})
+		status.MarkFailed(&tr.Status, status.ReasonFailedValidation, "%v", err)
+		return nil
+	}
+
+	if err := v1alpha1.ValidateWorkspaceBindings(taskSpec.Workspaces, tr.Spec.Workspaces); err != nil {
+		c.Logger.Errorf("Failed to validate taskrun %q: %v", tr.Name, err)
+		status.MarkFailed(&tr.Status, status.ReasonFailedValidation, "%v", err)
 		return nil
 	}
 
@@ -324,7 +396,13 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1alpha1.TaskRun) error
 		return err
 	}
 	if pod == nil {
-		pod, err = c.createPod(tr, rtr)
+		if cfg := config.FromContextOrDefaults(ctx); cfg.Defaults.ForbidPrivilegedSteps {
+			if privileged := resources.PrivilegedContainers(*rtr.TaskSpec); len(privileged) > 0 {
+				c.Recorder.Eventf(tr, corev1.EventTypeWarning, status.ReasonPrivilegedStepForbidden,
+					"The cluster forbids privileged containers, but %s request privileged mode: consider the rootless build profile", strings.Join(privileged, ", "))
+			}
+		}
+		pod, err = c.createPod(ctx, tr, rtr)
 		if err != nil {
 			c.handlePodCreationError(tr, err)
 			return nil
@@ -345,8 +423,10 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1alpha1.TaskRun) error
 	addReady := status.UpdateStatusFromPod(tr, pod, c.resourceLister, c.KubeClientSet, c.Logger)
 
 	status.SortTaskRunStepOrder(tr.Status.Steps, taskSpec.Steps)
+	status.DescribeTaskRunSteps(tr, taskSpec)
 
 	updateTaskRunResourceResult(tr, pod, c.Logger)
+	updateTaskRunProgress(tr, pod)
 
 	after := tr.Status.GetCondition(apis.ConditionSucceeded)
 
@@ -364,17 +444,27 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1alpha1.TaskRun) error
 
 func (c *Reconciler) handlePodCreationError(tr *v1alpha1.TaskRun, err error) {
 	var reason, msg string
-	var succeededStatus corev1.ConditionStatus
+	// Running out of quota is transient, the TaskRun keeps running until
+	// its pod can be created or it times out.
+	mark := status.MarkFailed
 	if isExceededResourceQuotaError(err) {
-		succeededStatus = corev1.ConditionUnknown
+		mark = status.MarkRunning
 		reason = status.ReasonExceededResourceQuota
 		backoff, currentlyBackingOff := c.timeoutHandler.GetBackoff(tr)
 		if !currentlyBackingOff {
 			go c.timeoutHandler.SetTaskRunTimer(tr, time.Until(backoff.NextAttempt))
 		}
 		msg = fmt.Sprintf("%s, reattempted %d times", status.GetExceededResourcesMessage(tr), backoff.NumAttempts)
+	} else if xerrors.As(err, new(*entrypoint.UnresolvableEntrypointError)) || xerrors.As(err, new(*resources.ImagePolicyError)) {
+		reason = status.ReasonFailedValidation
+		msg = "Invalid step image"
+	} else if xerrors.As(err, new(*resources.UnsatisfiableCapabilitiesError)) {
+		reason = status.ReasonCapabilitiesUnsatisfiable
+		msg = "Unsatisfiable capabilities"
+	} else if xerrors.As(err, new(*resources.FilesConfigMapConflictError)) {
+		reason = status.ReasonFilesConfigMapConflict
+		msg = "Conflicting ConfigMap"
 	} else {
-		succeededStatus = corev1.ConditionFalse
 		reason = status.ReasonCouldntGetTask
 		if tr.Spec.TaskRef != nil {
 			msg = fmt.Sprintf("Missing or invalid Task %s/%s", tr.Namespace, tr.Spec.TaskRef.Name)
@@ -382,12 +472,7 @@ func (c *Reconciler) handlePodCreationError(tr *v1alpha1.TaskRun, err error) {
 			msg = fmt.Sprintf("Invalid TaskSpec")
 		}
 	}
-	tr.Status.SetCondition(&apis.Condition{
-		Type:    apis.ConditionSucceeded,
-		Status:  succeededStatus,
-		Reason:  reason,
-		Message: fmt.Sprintf("%s: %v", msg, err),
-	})
+	mark(&tr.Status, reason, "%s: %v", msg, err)
 	c.Recorder.Eventf(tr, corev1.EventTypeWarning, "BuildCreationFailed", "Failed to create build pod %q: %v", tr.Name, err)
 	c.Logger.Errorf("Failed to create build pod for task %q: %v", tr.Name, err)
 }
@@ -407,16 +492,86 @@ func updateTaskRunResourceResult(taskRun *v1alpha1.TaskRun, pod *corev1.Pod, log
 	}
 }
 
+// updateTaskRunProgress copies the progress reported in the termination
+// message of the last finished step of pod which reported one to the
+// annotation of taskRun.
+func updateTaskRunProgress(taskRun *v1alpha1.TaskRun, pod *corev1.Pod) {
+	var progress string
+	var finishedAt metav1.Time
+	for _, cs := range pod.Status.ContainerStatuses {
+		if !resources.IsContainerStep(cs.Name) || cs.State.Terminated == nil || cs.State.Terminated.Message == "" {
+			continue
+		}
+		var results []v1alpha1.PipelineResourceResult
+		if err := json.Unmarshal([]byte(cs.State.Terminated.Message), &results); err != nil {
+			continue
+		}
+		for _, r := range results {
+			if r.Key == pkgentrypoint.ProgressResultKey && !cs.State.Terminated.FinishedAt.Before(&finishedAt) {
+				progress, finishedAt = r.Value, cs.State.Terminated.FinishedAt
+			}
+		}
+	}
+	if progress == "" || taskRun.Annotations[v1alpha1.ProgressAnnotationKey] == progress {
+		return
+	}
+	if taskRun.Annotations == nil {
+		taskRun.Annotations = map[string]string{}
+	}
+	taskRun.Annotations[v1alpha1.ProgressAnnotationKey] = progress
+}
+
 // updateTaskRunStatusWithResourceResult if there is an update to the outout image resource, add to taskrun status result
 func updateTaskRunStatusWithResourceResult(taskRun *v1alpha1.TaskRun, logContent []byte) error {
 	results := []v1alpha1.PipelineResourceResult{}
 	if err := json.Unmarshal(logContent, &results); err != nil {
 		return xerrors.Errorf("Failed to unmarshal output image exporter JSON output: %w", err)
 	}
-	taskRun.Status.ResourcesResult = append(taskRun.Status.ResourcesResult, results...)
+	for _, r := range results {
+		if r.ResultType == v1alpha1.TaskRunResultType {
+			setTaskRunResult(taskRun, r.Key, r.Value)
+			continue
+		}
+		// Skipped steps, the exit codes of the steps which continued on
+		// error, and the steps which timed out, are reported in the step
+		// states and in the condition instead, and the progress of the
+		// steps in an annotation.
+		switch r.Key {
+		case pkgentrypoint.SkippedResultKey, pkgentrypoint.ExitCodeResultKey, pkgentrypoint.WaitTimedOutResultKey, pkgentrypoint.TimedOutResultKey, pkgentrypoint.ProgressResultKey:
+		default:
+			taskRun.Status.ResourcesResult = append(taskRun.Status.ResourcesResult, r)
+		}
+	}
 	return nil
 }
 
+// setTaskRunResult sets the result name of the Task of taskRun. Every step
+// reports the results written so far, so the ones reported by later steps
+// replace the ones of earlier steps.
+func setTaskRunResult(taskRun *v1alpha1.TaskRun, name, value string) {
+	for i, r := range taskRun.Status.TaskResults {
+		if r.Name == name {
+			taskRun.Status.TaskResults[i].Value = value
+			return
+		}
+	}
+	taskRun.Status.TaskResults = append(taskRun.Status.TaskResults, v1alpha1.TaskRunResult{Name: name, Value: value})
+}
+
+// waitForQuotas returns how long tr must wait for the PipelineQuotas of its
+// namespace to let it start, and marks it accordingly.
+func (c *Reconciler) waitForQuotas(tr *v1alpha1.TaskRun) (time.Duration, error) {
+	quotas, err := c.quotaLister.PipelineQuotas(tr.Namespace).List(labels.Everything())
+	if err != nil || len(quotas) == 0 {
+		return 0, err
+	}
+	runs, err := reconciler.QuotaRuns(tr.Namespace, c.pipelineRunLister, c.taskRunLister)
+	if err != nil {
+		return 0, err
+	}
+	return reconciler.WaitForQuotas(&tr.Status, quotas, reconciler.TaskRunQuotaRun(tr), runs, c.clock.Now()), nil
+}
+
 func (c *Reconciler) updateStatus(taskrun *v1alpha1.TaskRun) (*v1alpha1.TaskRun, error) {
 	newtaskrun, err := c.taskRunLister.TaskRuns(taskrun.Namespace).Get(taskrun.Name)
 	if err != nil {
@@ -461,8 +616,12 @@ func (c *Reconciler) updateReady(pod *corev1.Pod) error {
 
 // createPod creates a Pod based on the Task's configuration, with pvcName as a volumeMount
 // TODO(dibyom): Refactor resource setup/substitution logic to its own function in the resources package
-func (c *Reconciler) createPod(tr *v1alpha1.TaskRun, rtr *resources.ResolvedTaskResources) (*corev1.Pod, error) {
+func (c *Reconciler) createPod(ctx context.Context, tr *v1alpha1.TaskRun, rtr *resources.ResolvedTaskResources) (*corev1.Pod, error) {
 	ts := rtr.TaskSpec.DeepCopy()
+	// The Tasks created before the requirements were configured, and the
+	// ClusterTasks, don't have them yet. The steps added below for the
+	// resources and the entrypoint don't get them.
+	ts.SetDefaultResourceRequirements(config.FromContextOrDefaults(ctx).Defaults.ContainerResourceRequirements(tr.Namespace))
 	inputResources, err := resourceImplBinding(rtr.Inputs, c.Images)
 	if err != nil {
 		c.Logger.Errorf("Failed to initialize input resources: %v", err)
@@ -474,6 +633,20 @@ func (c *Reconciler) createPod(tr *v1alpha1.TaskRun, rtr *resources.ResolvedTask
 		return nil, err
 	}
 
+	var defaults []v1alpha1.ParamSpec
+	if ts.Inputs != nil {
+		defaults = append(defaults, ts.Inputs.Params...)
+	}
+	// The images set through variables, and the ones of the StepActions, are
+	// only known now. The steps added below for the resources and the
+	// entrypoint run the images of the controller.
+	resolved := resources.ApplyParameters(rtr.TaskSpec, tr, defaults...)
+	resolved = resources.ApplyResources(resolved, inputResources, "inputs")
+	resolved = resources.ApplyResources(resolved, outputResources, "outputs")
+	if err := resources.ValidateImagePolicy(ctx, resolved); err != nil {
+		return nil, err
+	}
+
 	// Get actual resource
 
 	err = resources.AddOutputImageDigestExporter(c.Images.ImageDigestExporterImage, tr, ts, c.resourceLister.PipelineResources(tr.Namespace).Get)
@@ -494,41 +667,87 @@ func (c *Reconciler) createPod(tr *v1alpha1.TaskRun, rtr *resources.ResolvedTask
 		return nil, err
 	}
 
-	ts, err = createRedirectedTaskSpec(c.KubeClientSet, c.Images.EntryPointImage, ts, tr, c.cache, c.Logger)
+	if err := resources.AddCheckoutStep(ctx, c.Images.GitImage, tr, ts, defaults...); err != nil {
+		return nil, err
+	}
+
+	cfg := config.FromContextOrDefaults(ctx).Defaults
+	ts, err = createRedirectedTaskSpec(c.KubeClientSet, c.Images.EntryPointImage, ts, tr, c.cache, cfg, c.Logger)
 	if err != nil {
 		return nil, xerrors.Errorf("couldn't create redirected TaskSpec: %w", err)
 	}
 
-	var defaults []v1alpha1.ParamSpec
-	if ts.Inputs != nil {
-		defaults = append(defaults, ts.Inputs.Params...)
-	}
 	// Apply parameter substitution from the taskrun.
 	ts = resources.ApplyParameters(ts, tr, defaults...)
 
 	// Apply bound resource substitution from the taskrun.
 	ts = resources.ApplyResources(ts, inputResources, "inputs")
 	ts = resources.ApplyResources(ts, outputResources, "outputs")
+	ts = resources.ApplyTaskResults(ts)
+	ts = resources.ApplyWorkspaces(ts)
+
+	resources.ApplyScriptStepStorageDefaults(ts, cfg.DefaultScriptEphemeralStorageRequest, cfg.DefaultScriptEphemeralStorageLimit)
 
 	pod, err := resources.MakePod(c.Images, tr, *ts, c.KubeClientSet)
 	if err != nil {
 		return nil, xerrors.Errorf("translating Build to Pod: %w", err)
 	}
+	caps := config.FromContextOrDefaults(ctx).Capabilities
+	if err := resources.ApplyCapabilities(pod, ts.Requires, caps); err != nil {
+		return nil, err
+	}
+	if err := resources.CheckCapabilities(pod, ts.Requires, caps, c.nodeLister); xerrors.As(err, new(*resources.UnsatisfiableCapabilitiesError)) {
+		return nil, err
+	} else if err != nil {
+		// The scheduler is left to find a node.
+		c.Logger.Warnf("Couldn't check the nodes can run the pod of taskrun %s: %v", tr.Name, err)
+	}
+	// The ConfigMap of the files is left from an attempt whose pod couldn't
+	// be created, or from a retry, unless it was created by someone else.
+	if cm := resources.MakeFilesConfigMap(tr); cm != nil {
+		_, err := c.KubeClientSet.CoreV1().ConfigMaps(tr.Namespace).Create(cm)
+		if errors.IsAlreadyExists(err) {
+			existing, getErr := c.KubeClientSet.CoreV1().ConfigMaps(tr.Namespace).Get(cm.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return nil, xerrors.Errorf("couldn't get the ConfigMap %s of the files: %w", cm.Name, getErr)
+			}
+			if !metav1.IsControlledBy(existing, tr) {
+				return nil, &resources.FilesConfigMapConflictError{Name: cm.Name}
+			}
+		} else if err != nil {
+			return nil, xerrors.Errorf("couldn't create the ConfigMap %s of the files: %w", cm.Name, err)
+		}
+	}
+	resources.SetWorkspaceSizeLimit(pod, cfg.DefaultWorkspaceSizeLimit)
+	if cfg.DisableWorkingDirInit {
+		resources.RemoveWorkingDirInitializer(pod)
+	}
+	// The pod is labeled with the identity of the run, to be joined with its
+	// CloudEvents and its metrics.
+	for k, v := range tr.RunIdentity(cfg.ClusterName).Labels() {
+		pod.Labels[k] = v
+	}
 
 	return c.KubeClientSet.CoreV1().Pods(tr.Namespace).Create(pod)
 }
 
 // CreateRedirectedTaskSpec takes a TaskSpec, a persistent volume claim name, a taskrun and
 // an entrypoint cache creates a build where all entrypoints are switched to
-// be the entrypoint redirector binary. This function assumes that it receives
-// its own copy of the TaskSpec and modifies it freely
-func createRedirectedTaskSpec(kubeclient kubernetes.Interface, entrypointImage string, ts *v1alpha1.TaskSpec, tr *v1alpha1.TaskRun, cache *entrypoint.Cache, logger *zap.SugaredLogger) (*v1alpha1.TaskSpec, error) {
+// be the entrypoint redirector binary, with the registry mirrors and the wait
+// timeouts of cfg. This function assumes that it receives its own copy of the
+// TaskSpec and modifies it freely
+func createRedirectedTaskSpec(kubeclient kubernetes.Interface, entrypointImage string, ts *v1alpha1.TaskSpec, tr *v1alpha1.TaskRun, cache *entrypoint.Cache, cfg *config.Defaults, logger *zap.SugaredLogger) (*v1alpha1.TaskSpec, error) {
 	// RedirectSteps the entrypoint in each container so that we can use our custom
 	// entrypoint which copies logs to the volume
-	err := entrypoint.RedirectSteps(cache, ts.Steps, kubeclient, tr, logger)
+	err := entrypoint.RedirectSteps(cache, ts.Steps, kubeclient, tr, cfg.RegistryMirrors, logger)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to add entrypoint to steps of TaskRun %s: %w", tr.Name, err)
 	}
+	entrypoint.SetWaitTimeouts(ts.Steps, cfg.EntrypointReadyTimeout, cfg.EntrypointWaitFileTimeout)
+	entrypoint.SetProgress(ts, cfg.StepProgressInterval)
+	entrypoint.SetLogPrefixes(ts.Steps, cfg.StepLogTimestamps, cfg.StepLogStepNames)
+	entrypoint.SetResults(ts)
+	entrypoint.SetWorkspaceChecksums(ts)
 	// Add the step which will copy the entrypoint into the volume
 	// we are going to be using, so that all of the steps will have
 	// access to it.
@@ -578,13 +797,7 @@ func (c *Reconciler) updateTaskRunStatusForTimeout(tr *v1alpha1.TaskRun, dp Dele
 	}
 
 	timeout := tr.Spec.Timeout.Duration
-	timeoutMsg := fmt.Sprintf("TaskRun %q failed to finish within %q", tr.Name, timeout.String())
-	tr.Status.SetCondition(&apis.Condition{
-		Type:    apis.ConditionSucceeded,
-		Status:  corev1.ConditionFalse,
-		Reason:  status.ReasonTimedOut,
-		Message: timeoutMsg,
-	})
+	status.MarkFailed(&tr.Status, status.ReasonTimedOut, "TaskRun %q failed to finish within %q", tr.Name, timeout.String())
 	// update tr completed time
 	tr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
 	return nil
@@ -0,0 +1,41 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"golang.org/x/xerrors"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// archiveToTaskRunArchive writes the TaskRunArchive of tr if it is to be
+// archived on expiration. A TaskRunArchive of tr written by a cleanup whose
+// deletion of tr failed is kept.
+func archiveToTaskRunArchive(client versioned.Interface, tr *v1alpha1.TaskRun) error {
+	if !tr.Spec.ArchiveOnExpire {
+		return nil
+	}
+	a, err := v1alpha1.NewTaskRunArchive(tr)
+	if err != nil {
+		return err
+	}
+	if _, err := client.TektonV1alpha1().TaskRunArchives(tr.Namespace).Create(a); err != nil && !errors.IsAlreadyExists(err) {
+		return xerrors.Errorf("couldn't write the TaskRunArchive of TaskRun %s/%s: %w", tr.Namespace, tr.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,913 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/archive"
+	cleanuppolicyinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/cleanuppolicy"
+	pipelineruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelinerun"
+	taskruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/taskrun"
+	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources/cloudevent"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+	namespaceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/namespace"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// expirationAgentName defines logging agent name for the TaskRun expiration controller
+	expirationAgentName = "taskrun-expiration-controller"
+	// expirationControllerName defines name for the TaskRun expiration controller
+	expirationControllerName = "TaskRunExpiration"
+
+	// ReasonCleanupDryRun is the reason of the events recorded on the runs
+	// the expiration controllers would have deleted in dry run mode.
+	ReasonCleanupDryRun = "CleanupDryRun"
+	// ReasonTTLExpired is the reason of the events recorded for the runs
+	// deleted because their TTL elapsed.
+	ReasonTTLExpired = "TTLExpired"
+	// ReasonHistoryLimitExceeded is the reason of the events recorded for
+	// the runs deleted because they were beyond the history limit of their
+	// CleanupPolicy.
+	ReasonHistoryLimitExceeded = "HistoryLimitExceeded"
+	// ReasonTTLPending is the reason of the Expiring condition of the
+	// finished TaskRuns waiting for their TTL to elapse.
+	ReasonTTLPending = "TTLPending"
+
+	// KeepAnnotationKey is the annotation which, set to "true", exempts a
+	// finished run from being deleted when its TTL elapses. It can also be
+	// set as a label, so that the kept runs can be listed with a selector.
+	KeepAnnotationKey = "pipeline.tekton.dev/keep"
+	// CompactedAnnotationKey is the annotation set to "true" on the TaskRuns
+	// compacted instead of deleted once their TTL elapsed, which aren't
+	// cleaned up anymore.
+	CompactedAnnotationKey = "pipeline.tekton.dev/compacted"
+)
+
+// IsKept returns whether the run with the given metadata is exempted from
+// being deleted by the keep annotation or label.
+func IsKept(obj metav1.Object) bool {
+	return obj.GetAnnotations()[KeepAnnotationKey] == "true" || obj.GetLabels()[KeepAnnotationKey] == "true"
+}
+
+// IsCompacted returns whether the run with the given metadata was compacted
+// instead of deleted.
+func IsCompacted(obj metav1.Object) bool {
+	return obj.GetAnnotations()[CompactedAnnotationKey] == "true"
+}
+
+// ExpirationScope restricts which runs are cleaned up when their TTL
+// elapses, so that cleanup can be rolled out gradually.
+type ExpirationScope struct {
+	// Selector selects the runs to clean up. Nil selects all of them.
+	Selector labels.Selector
+	// ExcludedNamespaces are the namespaces in which runs are never cleaned
+	// up.
+	ExcludedNamespaces sets.String
+	// DryRun makes the expiration controllers log, and record events for,
+	// the runs they would delete instead of deleting them.
+	DryRun bool
+}
+
+// ParseExpirationScope builds an ExpirationScope from a label selector and
+// a comma separated list of excluded namespaces, both of which may be empty.
+func ParseExpirationScope(selector, excludedNamespaces string) (ExpirationScope, error) {
+	scope := ExpirationScope{ExcludedNamespaces: sets.NewString()}
+	if selector != "" {
+		s, err := labels.Parse(selector)
+		if err != nil {
+			return scope, err
+		}
+		scope.Selector = s
+	}
+	for _, ns := range strings.Split(excludedNamespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			scope.ExcludedNamespaces.Insert(ns)
+		}
+	}
+	return scope, nil
+}
+
+// Matches returns whether the run with the given metadata is in scope.
+func (s ExpirationScope) Matches(obj metav1.Object) bool {
+	if s.ExcludedNamespaces.Has(obj.GetNamespace()) {
+		return false
+	}
+	return s.Selector == nil || s.Selector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// ExpirationReconciler deletes finished TaskRuns once their TTL has elapsed,
+// or once they are beyond the history limit of their CleanupPolicy.
+type ExpirationReconciler struct {
+	*reconciler.Base
+
+	taskRunLister       listers.TaskRunLister
+	pipelineRunLister   listers.PipelineRunLister
+	cleanupPolicyLister listers.CleanupPolicyLister
+	namespaceLister     corev1listers.NamespaceLister
+	scope               ExpirationScope
+	clock               clock.Clock
+	filter              func(obj interface{}) bool
+	configStore         reconciler.ConfigStore
+	cloudEventClient    cloudevent.CEClient
+	throttle            *DeletionThrottle
+	skewCheck           *ClockSkewCheck
+	metrics             *CleanupMetrics
+	histories           *PrunedHistories
+	// elector, if set, restricts the TaskRuns cleaned up to the namespaces
+	// of the buckets this replica leads.
+	elector *reconciler.BucketElector
+
+	// newArchiver returns the Archiver of the archive location of the
+	// cleanup config, which is cached in archiver until the location
+	// changes.
+	newArchiver      func(ctx context.Context, location string) (archive.Archiver, error)
+	archiverMu       sync.Mutex
+	archiver         archive.Archiver
+	archiverLocation string
+
+	// enqueue and enqueueAfter are the ones of the controller.Impl the
+	// reconciler was created with.
+	enqueue      func(obj interface{})
+	enqueueAfter func(obj interface{}, after time.Duration)
+}
+
+// Check that our ExpirationReconciler implements controller.Reconciler
+var _ controller.Reconciler = (*ExpirationReconciler)(nil)
+
+// NewExpirationController returns a constructor for the TaskRun expiration
+// controller, which only cleans up the TaskRuns in scope, with the
+// dependencies replaced by opts.
+func NewExpirationController(images pipeline.Images, scope ExpirationScope, opts ...reconciler.ControllerOption) func(context.Context, configmap.Watcher) *controller.Impl {
+	return func(ctx context.Context, cmw configmap.Watcher) *controller.Impl {
+		logger := logging.FromContext(ctx)
+		o := reconciler.NewControllerOptions(ctx, opts...)
+		taskRunInformer := taskruninformer.Get(ctx)
+		cleanupPolicyInformer := cleanuppolicyinformer.Get(ctx)
+		pipelineRunInformer := pipelineruninformer.Get(ctx)
+		if _, ok := taskRunInformer.Informer().GetIndexer().GetIndexers()[listers.TaskRunExpirationIndex]; !ok {
+			if err := taskRunInformer.Informer().AddIndexers(cache.Indexers{listers.TaskRunExpirationIndex: listers.TaskRunExpirationIndexFunc}); err != nil {
+				logger.Warnf("Failed to index the expirable TaskRuns, the sweeps go through all of them: %v", err)
+			}
+		}
+
+		opt := reconciler.Options{
+			KubeClientSet:     o.KubeClientSet,
+			PipelineClientSet: o.PipelineClientSet,
+			ConfigMapWatcher:  cmw,
+			ResyncPeriod:      resyncPeriod,
+			Logger:            logger,
+		}
+
+		c := &ExpirationReconciler{
+			Base:                reconciler.NewBase(opt, expirationAgentName, images),
+			taskRunLister:       taskRunInformer.Lister(),
+			pipelineRunLister:   pipelineRunInformer.Lister(),
+			cleanupPolicyLister: cleanupPolicyInformer.Lister(),
+			namespaceLister:     namespaceinformer.Get(ctx).Lister(),
+			scope:               scope,
+			clock:               o.Clock,
+			filter:              o.FilterFunc(),
+			newArchiver:         archive.New,
+			cloudEventClient:    cloudevent.Get(ctx),
+			throttle:            NewDeletionThrottle("TaskRun", logger),
+			skewCheck:           NewClockSkewCheck("TaskRun", logger),
+			metrics:             NewCleanupMetrics("TaskRun", logger),
+			histories:           NewPrunedHistories(),
+			elector:             o.BucketElector,
+		}
+		impl := controller.NewImpl(c, c.Logger, expirationControllerName)
+		if err := reconciler.TrackQueueLatency(impl, expirationControllerName, "TaskRun", o.Clock); err != nil {
+			logger.Errorf("Failed to track the queue latency of %s: %v", expirationControllerName, err)
+		}
+
+		c.configStore = o.ConfigStore(expirationControllerName)
+		if c.configStore == nil {
+			c.configStore = config.NewStore(c.Logger.Named("config-store"))
+		}
+		c.configStore.WatchConfigs(opt.ConfigMapWatcher)
+		c.enqueue = impl.Enqueue
+		c.enqueueAfter = impl.EnqueueAfter
+
+		c.Logger.Info("Setting up event handlers")
+		taskRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: o.FilterFunc(),
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc:    c.AddTaskRun,
+				UpdateFunc: c.UpdateTaskRun,
+			},
+		})
+		cleanupPolicyInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.AddCleanupPolicy,
+			UpdateFunc: controller.PassNew(c.AddCleanupPolicy),
+		})
+		pipelineRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: o.FilterFunc(),
+			Handler: cache.ResourceEventHandlerFuncs{
+				UpdateFunc: controller.PassNew(c.AddPipelineRun),
+				DeleteFunc: c.DeletePipelineRun,
+			},
+		})
+		if c.elector != nil {
+			c.elector.OnPromote(c.addBucket)
+			c.elector.Start(ctx, o.KubeClientSet)
+		}
+		go RunSweeps(ctx.Done(), o.Clock, c.configStore, func() { c.SweepExpired() })
+
+		return impl
+	}
+}
+
+// SweepExpired enqueues the TaskRuns in scope whose TTL elapsed, and returns
+// how many. Only the TaskRuns whose own TTL elapsed are checked, but in the
+// namespaces with CleanupPolicies, whose TTLs may replace theirs.
+func (c *ExpirationReconciler) SweepExpired() int {
+	now := c.clock.Now()
+	policies, err := c.cleanupPolicyLister.List(labels.Everything())
+	if err != nil {
+		c.Logger.Errorf("Failed to list the CleanupPolicies to sweep: %v", err)
+		return 0
+	}
+	withPolicies := sets.NewString()
+	for _, cp := range policies {
+		withPolicies.Insert(cp.Namespace)
+	}
+	var trs []*v1alpha1.TaskRun
+	for _, ns := range withPolicies.List() {
+		nsTrs, err := c.taskRunLister.TaskRuns(ns).List(labels.Everything())
+		if err != nil {
+			c.Logger.Errorf("Failed to list the TaskRuns of namespace %s to sweep: %v", ns, err)
+			return 0
+		}
+		trs = append(trs, nsTrs...)
+	}
+	expired, err := c.taskRunLister.ListExpired(now)
+	if err != nil {
+		c.Logger.Errorf("Failed to list the expired TaskRuns to sweep: %v", err)
+		return 0
+	}
+	for _, tr := range expired {
+		if !withPolicies.Has(tr.Namespace) {
+			trs = append(trs, tr)
+		}
+	}
+
+	var runs []Expirable
+	for _, tr := range trs {
+		if c.filter(tr) && c.scope.Matches(tr) && c.elector.Has(tr.Namespace) {
+			runs = append(runs, withCleanupPolicy(tr, c.cleanupPolicy(tr)))
+		}
+	}
+	n := EnqueueExpired(runs, now, c.enqueue)
+	if n > 0 {
+		c.Logger.Infof("Sweep found %d TaskRuns whose TTL elapsed", n)
+	}
+	return n
+}
+
+// AddCleanupPolicy enqueues the TaskRuns of the namespace of a newly seen or
+// updated CleanupPolicy which need to be cleaned up.
+func (c *ExpirationReconciler) AddCleanupPolicy(obj interface{}) {
+	cp, ok := obj.(*v1alpha1.CleanupPolicy)
+	if !ok {
+		return
+	}
+	trs, err := c.taskRunLister.TaskRuns(cp.Namespace).List(labels.Everything())
+	if err != nil {
+		c.Logger.Errorf("Failed to list the TaskRuns of CleanupPolicy %s/%s: %v", cp.Namespace, cp.Name, err)
+		return
+	}
+	for _, tr := range trs {
+		if c.filter(tr) {
+			c.AddTaskRun(tr)
+		}
+	}
+}
+
+// addBucket enqueues the TaskRuns of the namespaces of a bucket this replica
+// starts leading which need to be cleaned up, since the replica which led it
+// before may have left them.
+func (c *ExpirationReconciler) addBucket(bucket int) {
+	trs, err := c.taskRunLister.List(labels.Everything())
+	if err != nil {
+		c.Logger.Errorf("Failed to list the TaskRuns of bucket %d: %v", bucket, err)
+		return
+	}
+	for _, tr := range trs {
+		if c.filter(tr) && c.elector.Bucket(tr.Namespace) == bucket {
+			c.AddTaskRun(tr)
+		}
+	}
+}
+
+// AddTaskRun enqueues a newly seen TaskRun if it needs to be cleaned up, or
+// if its expiration time may have to be cleared.
+func (c *ExpirationReconciler) AddTaskRun(obj interface{}) {
+	tr, ok := obj.(*v1alpha1.TaskRun)
+	if !ok || !(c.needsCleanup(tr) || tr.Status.ExpirationTime != nil) {
+		return
+	}
+	c.Logger.Debugf("Adding TaskRun %s/%s to the expiration queue", tr.Namespace, tr.Name)
+	c.enqueue(tr)
+}
+
+// AddPipelineRun enqueues the TaskRuns of a finished PipelineRun, which are
+// held until it is deleted or expires, if they need to be cleaned up.
+func (c *ExpirationReconciler) AddPipelineRun(obj interface{}) {
+	if pr, ok := obj.(*v1alpha1.PipelineRun); ok && pr.IsDone() {
+		c.enqueuePipelineRunTaskRuns(pr)
+	}
+}
+
+// DeletePipelineRun enqueues the TaskRuns of a deleted PipelineRun which
+// weren't deleted along with it, if they need to be cleaned up.
+func (c *ExpirationReconciler) DeletePipelineRun(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if pr, ok := obj.(*v1alpha1.PipelineRun); ok {
+		c.enqueuePipelineRunTaskRuns(pr)
+	}
+}
+
+func (c *ExpirationReconciler) enqueuePipelineRunTaskRuns(pr *v1alpha1.PipelineRun) {
+	selector := labels.SelectorFromSet(labels.Set{pipeline.GroupName + pipeline.PipelineRunLabelKey: pr.Name})
+	trs, err := c.taskRunLister.TaskRuns(pr.Namespace).List(selector)
+	if err != nil {
+		c.Logger.Errorf("Failed to list the TaskRuns of PipelineRun %s/%s: %v", pr.Namespace, pr.Name, err)
+		return
+	}
+	for _, tr := range trs {
+		if owner := metav1.GetControllerOf(tr); owner != nil && owner.Kind == "PipelineRun" && owner.Name == pr.Name {
+			c.AddTaskRun(tr)
+		}
+	}
+}
+
+// UpdateTaskRun enqueues an updated TaskRun if it needs to be cleaned up.
+func (c *ExpirationReconciler) UpdateTaskRun(old, cur interface{}) {
+	c.AddTaskRun(cur)
+}
+
+// Reconcile deletes the TaskRun identified by key if its TTL has elapsed,
+// or checks it again once it will have. It also deletes the TaskRuns beyond
+// the history limit of its CleanupPolicy.
+func (c *ExpirationReconciler) Reconcile(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		c.Logger.Errorf("invalid resource key: %s", key)
+		return nil
+	}
+	if !c.elector.Has(namespace) {
+		// Another replica leads the bucket of the namespace.
+		return nil
+	}
+	return c.processTaskRunExpired(c.configStore.ToContext(ctx), namespace, name)
+}
+
+// processTaskRunExpired deletes the TaskRun namespace/name if it is expired.
+func (c *ExpirationReconciler) processTaskRunExpired(ctx context.Context, namespace, name string) error {
+	tr, err := c.taskRunLister.TaskRuns(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		c.metrics.SetPending(namespace+"/"+name, false)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if deleted, err := c.enforceHistoryLimit(ctx, tr); err != nil || deleted {
+		return err
+	}
+	if expiredAt, err := c.processTrTTL(ctx, tr); err != nil || expiredAt == nil {
+		return err
+	}
+	// The throttle is acquired before any call to the API server, which it
+	// protects.
+	release := c.acquireDeletion(ctx, tr)
+	if release == nil {
+		return nil
+	}
+	defer release()
+
+	// The TaskRun in the lister may be stale, e.g. its TTL or annotations may
+	// have changed since. Check again against the latest version before
+	// deleting it.
+	fresh, err := c.PipelineClientSet.TektonV1alpha1().TaskRuns(namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	expiredAt, err := c.processTrTTL(ctx, fresh)
+	if err != nil || expiredAt == nil {
+		return err
+	}
+
+	return c.deleteTaskRun(ctx, fresh, ReasonTTLExpired, "its TTL elapsed", *expiredAt)
+}
+
+// deleteTaskRun archives tr, if an archive location is configured, and then
+// deletes it, unless it has been replaced by another TaskRun of the same
+// name. A TaskRunArchive of tr is written before, if tr is to be archived on
+// expiration. tr isn't deleted if it couldn't be archived. Once deleted, an event
+// with reason, saying why tr was deleted, is recorded in its namespace, and
+// a CloudEvent is sent to the events sink, if one is configured. tr is
+// compacted instead if its cleanup mode is Compact. In dry run mode, it only
+// logs and records an event saying tr would be deleted, and why. tr is
+// enqueued again later if it finished less than the minimum retention ago.
+// The deletion throttle must be held by the caller. A HeldError is returned, for tr to be
+// checked again with a backoff, while blocking finalizers are set on it.
+// expiredAt is the time tr expired at, zero
+// if it is deleted for another reason than its TTL.
+func (c *ExpirationReconciler) deleteTaskRun(ctx context.Context, tr *v1alpha1.TaskRun, reason, why string, expiredAt time.Time) error {
+	cfg := config.FromContextOrDefaults(ctx).Cleanup
+	if cfg.ExcludesNamespace(tr.Namespace, c.namespaceLister.Get) {
+		c.Logger.Debugf("Not cleaning up TaskRun %s/%s, its namespace is excluded from the cleanup", tr.Namespace, tr.Name)
+		return nil
+	}
+	finishedAt, err := FinishTime(tr)
+	if err != nil {
+		return err
+	}
+	if left := RetentionLeft(cfg, finishedAt, c.clock.Now()); left > 0 {
+		c.Logger.Debugf("Keeping TaskRun %s/%s for the minimum retention, %s more", tr.Namespace, tr.Name, left)
+		c.enqueueAfter(tr, left)
+		return nil
+	}
+	if err := CheckFinalizers(cfg, tr); err != nil {
+		c.Logger.Infof("Holding off the cleanup of TaskRun %s/%s: %v", tr.Namespace, tr.Name, err)
+		return err
+	}
+	if c.scope.DryRun || cfg.DryRun {
+		c.Logger.Infof("Dry run: would clean up TaskRun %s/%s, as %s", tr.Namespace, tr.Name, why)
+		verb := "deleted"
+		if tr.Spec.CleanupMode == v1alpha1.TaskRunCleanupModeCompact {
+			verb = "compacted"
+		}
+		c.Recorder.Eventf(tr, corev1.EventTypeNormal, ReasonCleanupDryRun, "TaskRun would be %s, as %s", verb, why)
+		return nil
+	}
+	c.Logger.Infof("Cleaning up TaskRun %s/%s, as %s", tr.Namespace, tr.Name, why)
+	a, err := c.archiverFor(ctx)
+	if err != nil {
+		return err
+	}
+	if a != nil {
+		if err := archive.TaskRun(ctx, a, c.KubeClientSet, tr); err != nil {
+			return xerrors.Errorf("couldn't archive TaskRun %s/%s: %w", tr.Namespace, tr.Name, err)
+		}
+	}
+	if tr.Spec.CleanupMode == v1alpha1.TaskRunCleanupModeCompact {
+		if err := c.compactTaskRun(tr, reason, why); err != nil {
+			c.metrics.DeletionFailed()
+			return err
+		}
+		c.metrics.Cleaned(reason, c.lag(expiredAt))
+		return nil
+	}
+	if err := archiveToTaskRunArchive(c.PipelineClientSet, tr); err != nil {
+		return err
+	}
+	// The pod has to be fetched before deleting the TaskRun, which deletes
+	// the pods it owns.
+	pod, err := c.taskRunPod(tr, cfg.Resources)
+	if err != nil {
+		return err
+	}
+	policy := metav1.DeletePropagationForeground
+	if cfg.Resources == config.CleanupResourcesNone {
+		// The garbage collector still deletes the pods tr owns, but once
+		// tr is gone.
+		policy = metav1.DeletePropagationBackground
+	}
+	if err := c.PipelineClientSet.TektonV1alpha1().TaskRuns(tr.Namespace).Delete(tr.Name, &metav1.DeleteOptions{
+		PropagationPolicy: &policy,
+		Preconditions:     &metav1.Preconditions{UID: &tr.UID},
+	}); err != nil {
+		if !errors.IsNotFound(err) {
+			c.metrics.DeletionFailed()
+		}
+		return err
+	}
+	c.metrics.Cleaned(reason, c.lag(expiredAt))
+	// The TaskRun is gone, so failing to delete its resources can't be
+	// retried either.
+	if err := c.deleteTaskRunResources(tr, pod, cfg.Resources); err != nil {
+		c.Logger.Warnf("Failed to clean up the resources of deleted TaskRun %s/%s: %v", tr.Namespace, tr.Name, err)
+	}
+
+	c.Recorder.Eventf(NamespaceRef(tr.Namespace), corev1.EventTypeNormal, reason, "TaskRun %s deleted, as %s", tr.Name, why)
+	if cfg.EventsSink != "" {
+		// The TaskRun is gone, so failing to send its CloudEvent can't be
+		// retried.
+		if _, err := cloudevent.SendTaskRunDeletedCloudEvent(cfg.EventsSink, tr, config.FromContextOrDefaults(ctx).Defaults.ClusterName, reason, c.Logger, c.cloudEventClient); err != nil {
+			c.Logger.Warnf("Failed to send the CloudEvent of deleted TaskRun %s/%s: %v", tr.Namespace, tr.Name, err)
+		}
+	}
+	return nil
+}
+
+// NamespaceRef returns a reference to namespace, to record the events of the
+// runs deleted from it on, since the events of a run are garbage collected
+// along with it. The reference is in namespace itself, so the events are
+// listed along with the ones of the other objects of namespace.
+func NamespaceRef(namespace string) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		Kind:       "Namespace",
+		APIVersion: "v1",
+		Name:       namespace,
+		Namespace:  namespace,
+	}
+}
+
+// acquireDeletion returns a func to call once tr is cleaned up if the
+// deletion throttle lets it be now. Otherwise, it enqueues tr again for when
+// it may be, and returns nil.
+func (c *ExpirationReconciler) acquireDeletion(ctx context.Context, tr *v1alpha1.TaskRun) func() {
+	release, retryAfter := c.throttle.Acquire(tr.Namespace+"/"+tr.Name, config.FromContextOrDefaults(ctx).Cleanup, c.clock.Now())
+	if release == nil {
+		c.Logger.Debugf("Throttled the cleanup of TaskRun %s/%s, retrying in %s", tr.Namespace, tr.Name, retryAfter)
+		c.enqueueAfter(tr, retryAfter)
+	}
+	return release
+}
+
+// throttledDeleteTaskRun deletes tr as deleteTaskRun does once the deletion
+// throttle lets it, enqueuing tr again for later otherwise.
+func (c *ExpirationReconciler) throttledDeleteTaskRun(ctx context.Context, tr *v1alpha1.TaskRun, reason, why string, expiredAt time.Time) error {
+	release := c.acquireDeletion(ctx, tr)
+	if release == nil {
+		return nil
+	}
+	defer release()
+	return c.deleteTaskRun(ctx, tr, reason, why, expiredAt)
+}
+
+// compactTaskRun strips the state of the steps and the retries of tr from
+// its status and annotates it as compacted, keeping it as a record instead
+// of deleting it. An event with reason, saying why tr was compacted, is
+// recorded in its namespace.
+func (c *ExpirationReconciler) compactTaskRun(tr *v1alpha1.TaskRun, reason, why string) error {
+	taskRuns := c.PipelineClientSet.TektonV1alpha1().TaskRuns(tr.Namespace)
+	tr = tr.DeepCopy()
+	tr.Status.InitSteps = nil
+	tr.Status.Steps = nil
+	tr.Status.CloudEvents = nil
+	tr.Status.RetriesStatus = nil
+	tr.Status.ImageLookups = nil
+	tr, err := taskRuns.UpdateStatus(tr)
+	if err != nil {
+		return err
+	}
+	// The status is updated first, so that it is stripped again if
+	// annotating tr fails and is retried.
+	if tr.Annotations == nil {
+		tr.Annotations = map[string]string{}
+	}
+	tr.Annotations[CompactedAnnotationKey] = "true"
+	if _, err := taskRuns.Update(tr); err != nil {
+		return err
+	}
+	c.Recorder.Eventf(tr, corev1.EventTypeNormal, reason, "TaskRun compacted, as %s", why)
+	return nil
+}
+
+// lag returns how long ago expiredAt was, or zero if it is.
+func (c *ExpirationReconciler) lag(expiredAt time.Time) time.Duration {
+	if expiredAt.IsZero() {
+		return 0
+	}
+	return c.clock.Since(expiredAt)
+}
+
+// RetentionLeft returns how much longer a run which finished at finishedAt
+// has to be kept for the minimum retention of cfg, as of now.
+func RetentionLeft(cfg *config.Cleanup, finishedAt, now time.Time) time.Duration {
+	return finishedAt.Add(cfg.MinimumRetention).Sub(now)
+}
+
+// taskRunPod returns the pod of tr if its resources are cleaned up along
+// with it, nil otherwise or if it doesn't exist anymore.
+func (c *ExpirationReconciler) taskRunPod(tr *v1alpha1.TaskRun, resources config.CleanupResources) (*corev1.Pod, error) {
+	if resources == config.CleanupResourcesNone || tr.Status.PodName == "" {
+		return nil, nil
+	}
+	pod, err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Get(tr.Status.PodName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	return pod, err
+}
+
+// deleteTaskRunResources deletes the pod of the deleted TaskRun tr if it
+// doesn't have an owner anymore, since the garbage collector deletes the
+// other ones, and the PersistentVolumeClaims created for tr which it
+// mounted, depending on resources.
+func (c *ExpirationReconciler) deleteTaskRunResources(tr *v1alpha1.TaskRun, pod *corev1.Pod, resources config.CleanupResources) error {
+	if pod == nil {
+		return nil
+	}
+	if len(pod.OwnerReferences) == 0 {
+		c.Logger.Infof("Cleaning up orphaned pod %s/%s of TaskRun %s", pod.Namespace, pod.Name, tr.Name)
+		if err := c.KubeClientSet.CoreV1().Pods(pod.Namespace).Delete(pod.Name, &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &pod.UID},
+		}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	if resources != config.CleanupResourcesPodsAndPVCs {
+		return nil
+	}
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvcs := c.KubeClientSet.CoreV1().PersistentVolumeClaims(pod.Namespace)
+		pvc, err := pvcs.Get(v.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		// Only the claims created for tr are deleted, not the ones shared
+		// with other runs.
+		if pvc.Labels[pipeline.GroupName+pipeline.TaskRunLabelKey] != tr.Name {
+			continue
+		}
+		c.Logger.Infof("Cleaning up PersistentVolumeClaim %s/%s of TaskRun %s", pvc.Namespace, pvc.Name, tr.Name)
+		if err := pvcs.Delete(pvc.Name, &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &pvc.UID},
+		}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// enforceHistoryLimit deletes the TaskRuns beyond the history limit of the
+// CleanupPolicy of tr, among the ones it applies to which finished with the
+// same outcome as tr. It returns whether tr itself was deleted. The history
+// is only listed again if tr wasn't in it the last time it was pruned.
+func (c *ExpirationReconciler) enforceHistoryLimit(ctx context.Context, tr *v1alpha1.TaskRun) (bool, error) {
+	policies, err := c.cleanupPolicyLister.CleanupPolicies(tr.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	cp := cleanupPolicyFor(policies, tr)
+	if !c.limitedBy(tr, cp) {
+		return false, nil
+	}
+	succeeded := tr.Status.GetCondition(apis.ConditionSucceeded)
+	limit := cp.Spec.HistoryLimit(succeeded)
+	key := HistoryKey(cp, succeeded)
+	why := fmt.Sprintf("it is beyond the history limit of CleanupPolicy %s", cp.Name)
+	if kept, over := c.histories.Lookup(key, *limit, tr); kept {
+		return false, nil
+	} else if over {
+		if err := c.throttledDeleteTaskRun(ctx, tr, ReasonHistoryLimitExceeded, why, time.Time{}); err != nil && !errors.IsNotFound(err) && !IsHeld(err) {
+			return false, err
+		}
+		return true, nil
+	}
+
+	trs, err := c.taskRunLister.TaskRuns(tr.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	var runs []FinishedRun
+	for _, other := range trs {
+		otherCp := cleanupPolicyFor(policies, other)
+		if !c.limitedBy(other, otherCp) || other.Status.GetCondition(apis.ConditionSucceeded).Status != succeeded.Status {
+			continue
+		}
+		if otherCp.Name != cp.Name {
+			continue
+		}
+		finishedAt, err := FinishTime(other)
+		if err != nil {
+			continue
+		}
+		runs = append(runs, FinishedRun{Object: other, FinishedAt: finishedAt})
+	}
+	over := OverHistoryLimit(runs, *limit)
+	c.histories.Set(key, *limit, runs, over)
+	var deleted bool
+	for _, run := range over {
+		if err := c.throttledDeleteTaskRun(ctx, run.Object.(*v1alpha1.TaskRun), ReasonHistoryLimitExceeded, why, time.Time{}); err != nil && !errors.IsNotFound(err) && !IsHeld(err) {
+			return deleted, err
+		}
+		deleted = deleted || run.Object.GetName() == tr.Name
+	}
+	return deleted, nil
+}
+
+// archiverFor returns the Archiver of the archive location of the cleanup
+// config in ctx, or nil if there is none.
+func (c *ExpirationReconciler) archiverFor(ctx context.Context) (archive.Archiver, error) {
+	location := config.FromContextOrDefaults(ctx).Cleanup.ArchiveLocation
+	if location == "" {
+		return nil, nil
+	}
+	c.archiverMu.Lock()
+	defer c.archiverMu.Unlock()
+	if c.archiver == nil || c.archiverLocation != location {
+		a, err := c.newArchiver(ctx, location)
+		if err != nil {
+			return nil, err
+		}
+		c.archiver, c.archiverLocation = a, location
+	}
+	return c.archiver, nil
+}
+
+// historyLimited returns whether tr is in scope, and a finished TaskRun
+// counted against the history limit of its CleanupPolicy.
+func (c *ExpirationReconciler) historyLimited(tr *v1alpha1.TaskRun) bool {
+	return c.limitedBy(tr, c.cleanupPolicy(tr))
+}
+
+// limitedBy returns whether tr is in scope, and a finished TaskRun counted
+// against the history limit of cp, its CleanupPolicy.
+func (c *ExpirationReconciler) limitedBy(tr *v1alpha1.TaskRun, cp *v1alpha1.CleanupPolicy) bool {
+	if !c.scope.Matches(tr) || !tr.IsDone() || IsKept(tr) || IsCompacted(tr) {
+		return false
+	}
+	return cp != nil && cp.Spec.HistoryLimit(tr.Status.GetCondition(apis.ConditionSucceeded)) != nil
+}
+
+// cleanupPolicy returns the CleanupPolicy which applies to tr, or nil if
+// none does.
+func (c *ExpirationReconciler) cleanupPolicy(tr *v1alpha1.TaskRun) *v1alpha1.CleanupPolicy {
+	policies, err := c.cleanupPolicyLister.CleanupPolicies(tr.Namespace).List(labels.Everything())
+	if err != nil {
+		c.Logger.Errorf("Failed to list the CleanupPolicies of namespace %s: %v", tr.Namespace, err)
+		return nil
+	}
+	return cleanupPolicyFor(policies, tr)
+}
+
+// cleanupPolicyFor returns the one of policies which applies to tr. None
+// applies to the TaskRuns of PipelineRuns.
+func cleanupPolicyFor(policies []*v1alpha1.CleanupPolicy, tr *v1alpha1.TaskRun) *v1alpha1.CleanupPolicy {
+	if ownedByPipelineRun(tr) {
+		return nil
+	}
+	return CleanupPolicyFor(policies, tr)
+}
+
+// processTrTTL returns the time at which tr expired, or nil if it hasn't
+// expired yet, in which case it is enqueued again for when it will, and the
+// time it will expire at is recorded in its status. The TTLs of its
+// CleanupPolicy apply if it has none of its own. It returns an error, for tr
+// to be checked again with a backoff, if tr finished further in the future
+// than the allowed clock skew.
+func (c *ExpirationReconciler) processTrTTL(ctx context.Context, tr *v1alpha1.TaskRun) (*time.Time, error) {
+	key := tr.Namespace + "/" + tr.Name
+	if !c.scope.Matches(tr) {
+		c.metrics.SetPending(key, false)
+		return nil, nil
+	}
+	if config.FromContextOrDefaults(ctx).Cleanup.ExcludesNamespace(tr.Namespace, c.namespaceLister.Get) {
+		c.metrics.SetPending(key, false)
+		return nil, c.updateExpiration(tr, nil)
+	}
+	expiring := withCleanupPolicy(tr, c.cleanupPolicy(tr))
+	if !Expires(expiring) {
+		c.metrics.SetPending(key, false)
+		return nil, c.updateExpiration(tr, nil)
+	}
+	now := c.clock.Now()
+	if err := c.skewCheck.Check(config.FromContextOrDefaults(ctx).Cleanup, expiring, now); err != nil {
+		return nil, err
+	}
+	remaining, err := TimeLeft(expiring, &now)
+	if err != nil {
+		return nil, err
+	}
+	if held, ownerLeft := c.heldByPipelineRun(tr, now); held {
+		if ownerLeft == nil {
+			// tr is enqueued again once its PipelineRun finishes or is
+			// deleted.
+			c.metrics.SetPending(key, false)
+			return nil, c.updateExpiration(tr, nil)
+		}
+		if *ownerLeft > *remaining {
+			remaining = ownerLeft
+		}
+	}
+	expiresAt := now.Add(*remaining)
+	if *remaining <= 0 {
+		c.metrics.SetPending(key, false)
+		return &expiresAt, nil
+	}
+	c.metrics.SetPending(key, true)
+	if err := c.updateExpiration(tr, &expiresAt); err != nil {
+		return nil, err
+	}
+	c.enqueueAfter(tr, *remaining)
+	return nil, nil
+}
+
+// heldByPipelineRun returns whether tr is held by the PipelineRun owning it,
+// which deletes its TaskRuns along with it, and the time left until that
+// PipelineRun expires, or nil if it can't be told yet. The TaskRuns of a
+// PipelineRun are only cleaned up on their own once it is deleted without
+// them, or has expired itself, e.g. while its own deletion is held off.
+func (c *ExpirationReconciler) heldByPipelineRun(tr *v1alpha1.TaskRun, now time.Time) (bool, *time.Duration) {
+	owner := metav1.GetControllerOf(tr)
+	if owner == nil || owner.Kind != "PipelineRun" {
+		return false, nil
+	}
+	pr, err := c.pipelineRunLister.PipelineRuns(tr.Namespace).Get(owner.Name)
+	if errors.IsNotFound(err) || (err == nil && pr.UID != owner.UID) {
+		return false, nil
+	} else if err != nil {
+		c.Logger.Errorf("Failed to get the PipelineRun of TaskRun %s/%s: %v", tr.Namespace, tr.Name, err)
+		return true, nil
+	}
+	if IsCompacted(pr) {
+		return false, nil
+	}
+	if !Expires(pr) {
+		return true, nil
+	}
+	left, err := TimeLeft(pr, &now)
+	if err != nil {
+		return true, nil
+	}
+	if *left <= 0 {
+		return false, nil
+	}
+	return true, left
+}
+
+// updateExpiration records in the status of tr that it will be cleaned up at
+// expiresAt, or that it won't be if expiresAt is nil, unless its status says
+// so already.
+func (c *ExpirationReconciler) updateExpiration(tr *v1alpha1.TaskRun, expiresAt *time.Time) error {
+	status := tr.Status.DeepCopy()
+	if expiresAt == nil {
+		if status.ExpirationTime == nil && status.GetCondition(v1alpha1.TaskRunConditionExpiring) == nil {
+			return nil
+		}
+		status.ClearExpiring()
+	} else {
+		// The API server only keeps seconds.
+		at := metav1.NewTime(expiresAt.Truncate(time.Second))
+		if status.ExpirationTime.Equal(&at) {
+			return nil
+		}
+		verb := "deleted"
+		if tr.Spec.CleanupMode == v1alpha1.TaskRunCleanupModeCompact {
+			verb = "compacted"
+		}
+		status.MarkExpiring(at, ReasonTTLPending, "TaskRun will be %s at %s, once its TTL elapses", verb, at.UTC().Format(time.RFC3339))
+	}
+	tr = tr.DeepCopy()
+	tr.Status = *status
+	_, err := c.PipelineClientSet.TektonV1alpha1().TaskRuns(tr.Namespace).UpdateStatus(tr)
+	return err
+}
+
+// needsCleanup returns whether tr is in scope and needs to be cleaned up,
+// once its TTL elapses or beyond the history limit of its CleanupPolicy.
+func (c *ExpirationReconciler) needsCleanup(tr *v1alpha1.TaskRun) bool {
+	if !c.scope.Matches(tr) {
+		return false
+	}
+	return Expires(withCleanupPolicy(tr, c.cleanupPolicy(tr))) || c.historyLimited(tr)
+}
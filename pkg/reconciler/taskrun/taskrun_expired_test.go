@@ -0,0 +1,1290 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/pkg/cloudevents"
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/archive"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources/cloudevent"
+	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	"github.com/tektoncd/pipeline/pkg/system"
+	"github.com/tektoncd/pipeline/test"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/configmap"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+// testNow is the time the fake clocks of the expiration tests start at.
+var testNow = time.Date(2019, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+func finishedTaskRun(name string, finishedAgo time.Duration, ops ...tb.TaskRunOp) *v1alpha1.TaskRun {
+	ops = append([]tb.TaskRunOp{
+		tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world"), tb.TaskRunExpirationSecondsTTL(time.Hour)),
+		tb.TaskRunStatus(
+			tb.StatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}),
+			tb.TaskRunCompletionTime(testNow.Add(-finishedAgo)),
+		),
+	}, ops...)
+	return tb.TaskRun(name, "foo", ops...)
+}
+
+func TestExpires(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		tr   *v1alpha1.TaskRun
+		want bool
+	}{{
+		name: "finished with ttl",
+		tr:   finishedTaskRun("test-taskrun", time.Minute),
+		want: true,
+	}, {
+		name: "no ttl",
+		tr:   tb.TaskRun("test-taskrun", "foo", tb.TaskRunStatus(tb.StatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse}))),
+	}, {
+		name: "running",
+		tr: tb.TaskRun("test-taskrun", "foo",
+			tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(time.Hour)),
+			tb.TaskRunStatus(tb.StatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionUnknown})),
+		),
+	}, {
+		name: "kept",
+		tr:   finishedTaskRun("test-taskrun", time.Minute, tb.TaskRunAnnotation(KeepAnnotationKey, "true")),
+	}, {
+		name: "kept by label",
+		tr:   finishedTaskRun("test-taskrun", time.Minute, tb.TaskRunLabel(KeepAnnotationKey, "true")),
+	}, {
+		name: "keep annotation not true",
+		tr:   finishedTaskRun("test-taskrun", time.Minute, tb.TaskRunAnnotation(KeepAnnotationKey, "false")),
+		want: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Expires(tc.tr); got != tc.want {
+				t.Errorf("Expires() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTaskRunTTL(t *testing.T) {
+	status := func(s corev1.ConditionStatus) tb.TaskRunOp {
+		return tb.TaskRunStatus(tb.StatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: s}))
+	}
+	spec := tb.TaskRunSpec(
+		tb.TaskRunExpirationSecondsTTL(time.Hour),
+		tb.TaskRunTTLSecondsAfterSucceeded(time.Minute),
+		tb.TaskRunTTLSecondsAfterFailed(24*time.Hour),
+	)
+	for _, tc := range []struct {
+		name string
+		tr   *v1alpha1.TaskRun
+		want *metav1.Duration
+	}{{
+		name: "succeeded",
+		tr:   tb.TaskRun("test-taskrun", "foo", spec, status(corev1.ConditionTrue)),
+		want: &metav1.Duration{Duration: time.Minute},
+	}, {
+		name: "failed",
+		tr:   tb.TaskRun("test-taskrun", "foo", spec, status(corev1.ConditionFalse)),
+		want: &metav1.Duration{Duration: 24 * time.Hour},
+	}, {
+		name: "failed without ttl after failure",
+		tr: tb.TaskRun("test-taskrun", "foo",
+			tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(time.Hour), tb.TaskRunTTLSecondsAfterSucceeded(time.Minute)),
+			status(corev1.ConditionFalse),
+		),
+		want: &metav1.Duration{Duration: time.Hour},
+	}, {
+		name: "succeeded with only a ttl after failure",
+		tr: tb.TaskRun("test-taskrun", "foo",
+			tb.TaskRunSpec(tb.TaskRunTTLSecondsAfterFailed(time.Hour)),
+			status(corev1.ConditionTrue),
+		),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := cmp.Diff(tc.want, tc.tr.TTL()); d != "" {
+				t.Errorf("TTL() diff -want, +got: %v", d)
+			}
+		})
+	}
+}
+
+func TestTimeLeft(t *testing.T) {
+	now := testNow
+	tr := finishedTaskRun("test-taskrun", 0)
+	tr.Status.CompletionTime = &metav1.Time{Time: now.Add(-20 * time.Minute)}
+	got, err := TimeLeft(tr, &now)
+	if err != nil {
+		t.Fatalf("TimeLeft: %v", err)
+	}
+	if *got != 40*time.Minute {
+		t.Errorf("Expected 40m left, got %s", got)
+	}
+
+	// Without a completion time, the time the TaskRun finished is the one of its condition.
+	tr.Status.CompletionTime = nil
+	tr.Status.Conditions[0].LastTransitionTime = apis.VolatileTime{Inner: metav1.NewTime(now.Add(-2 * time.Hour))}
+	if got, err = TimeLeft(tr, &now); err != nil {
+		t.Fatalf("TimeLeft: %v", err)
+	}
+	if *got != -time.Hour {
+		t.Errorf("Expected -1h left, got %s", got)
+	}
+}
+
+func TestReconcileExpiredTaskRun(t *testing.T) {
+	withoutTTL := func(tr *v1alpha1.TaskRun) { tr.Spec.ExpirationSecondsTTL = nil }
+	hourlyPolicy := tb.CleanupPolicy("hourly", "foo", tb.CleanupPolicyExpirationSecondsTTL(time.Hour))
+	for _, tc := range []struct {
+		name        string
+		tr          *v1alpha1.TaskRun
+		policies    []*v1alpha1.CleanupPolicy
+		wantDeleted bool
+		// wantEnqueue is the delay after which the TaskRun is expected to be
+		// enqueued again.
+		wantEnqueue time.Duration
+	}{{
+		name:        "expired",
+		tr:          finishedTaskRun("test-taskrun", 2*time.Hour),
+		wantDeleted: true,
+	}, {
+		name:        "not expired yet",
+		tr:          finishedTaskRun("test-taskrun", time.Minute),
+		wantEnqueue: 59 * time.Minute,
+	}, {
+		name: "failed and kept longer",
+		tr: tb.TaskRun("test-taskrun", "foo",
+			tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(time.Hour), tb.TaskRunTTLSecondsAfterFailed(24*time.Hour)),
+			tb.TaskRunStatus(
+				tb.StatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse}),
+				tb.TaskRunCompletionTime(testNow.Add(-2*time.Hour)),
+			),
+		),
+		wantEnqueue: 22 * time.Hour,
+	}, {
+		name: "expired but kept",
+		tr:   finishedTaskRun("test-taskrun", 2*time.Hour, tb.TaskRunAnnotation(KeepAnnotationKey, "true")),
+	}, {
+		name:        "expired by the cleanup policy",
+		tr:          finishedTaskRun("test-taskrun", 2*time.Hour, withoutTTL),
+		policies:    []*v1alpha1.CleanupPolicy{hourlyPolicy},
+		wantDeleted: true,
+	}, {
+		name: "default ttl overridden by the cleanup policy",
+		tr: finishedTaskRun("test-taskrun", 2*time.Hour,
+			tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(24*time.Hour)),
+			tb.TaskRunAnnotation(v1alpha1.TTLDefaultedAnnotationKey, "true"),
+		),
+		policies:    []*v1alpha1.CleanupPolicy{hourlyPolicy},
+		wantDeleted: true,
+	}, {
+		name:        "own ttl over the cleanup policy",
+		tr:          finishedTaskRun("test-taskrun", 2*time.Hour, tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(24*time.Hour))),
+		policies:    []*v1alpha1.CleanupPolicy{hourlyPolicy},
+		wantEnqueue: 22 * time.Hour,
+	}, {
+		name:     "not selected by the cleanup policy",
+		tr:       finishedTaskRun("test-taskrun", 2*time.Hour, withoutTTL),
+		policies: []*v1alpha1.CleanupPolicy{tb.CleanupPolicy("nightly", "foo", tb.CleanupPolicySelector(map[string]string{"trigger": "nightly"}), tb.CleanupPolicyExpirationSecondsTTL(time.Hour))},
+	}, {
+		name:     "taskrun of a pipelinerun",
+		tr:       finishedTaskRun("test-taskrun", 2*time.Hour, withoutTTL, tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run", tb.Controller)),
+		policies: []*v1alpha1.CleanupPolicy{hourlyPolicy},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{tc.tr}, CleanupPolicies: tc.policies})
+			q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+			impl := NewExpirationController(images, ExpirationScope{}, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+			r := impl.Reconciler.(*ExpirationReconciler)
+			r.enqueueAfter = q.EnqueueAfter
+
+			if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+				t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+			}
+
+			var deleted bool
+			for _, a := range c.Pipeline.Actions() {
+				if a.GetVerb() == "delete" {
+					deleted = true
+				}
+			}
+			if deleted != tc.wantDeleted {
+				t.Errorf("Expected the TaskRun to be deleted: %t, got %t", tc.wantDeleted, deleted)
+			}
+			var enqueued time.Duration
+			if at, ok := q.NextAt(); ok {
+				enqueued = at.Sub(testNow)
+			}
+			if enqueued != tc.wantEnqueue {
+				t.Errorf("Expected the TaskRun to be enqueued again after %s, got %s", tc.wantEnqueue, enqueued)
+			}
+		})
+	}
+}
+
+func TestReconcileTaskRunOnceExpired(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{finishedTaskRun("test-taskrun", 10*time.Minute)}})
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+	impl := NewExpirationController(images, ExpirationScope{}, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+		t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+	}
+	for _, a := range c.Pipeline.Actions() {
+		if a.GetVerb() == "delete" {
+			t.Fatalf("Expected the TaskRun not to be deleted before its TTL elapses, got actions %v", c.Pipeline.Actions())
+		}
+	}
+
+	due := q.TravelToNext()
+	if len(due) != 1 {
+		t.Fatalf("Expected the TaskRun to be due once its TTL elapses, got %v", due)
+	}
+	if want := testNow.Add(50 * time.Minute); !q.Clock.Now().Equal(want) {
+		t.Errorf("Expected the TaskRun to be due at %s, got %s", want, q.Clock.Now())
+	}
+	if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+		t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+	}
+	var deleted bool
+	for _, a := range c.Pipeline.Actions() {
+		if a.GetVerb() == "delete" {
+			deleted = true
+		}
+	}
+	if !deleted {
+		t.Error("Expected the TaskRun to be deleted once its TTL elapsed")
+	}
+	if q.Len() != 0 {
+		t.Errorf("Expected the deleted TaskRun not to be enqueued again, got %d objects enqueued", q.Len())
+	}
+}
+
+// TestExpirationControllerWorkQueue checks that the TaskRuns enqueued once
+// their TTL elapses stay in the work queue of the controller while other
+// events are handled.
+func TestReconcileTaskRunExpirationTime(t *testing.T) {
+	expiresAt := metav1.NewTime(testNow.Add(50 * time.Minute))
+	expiring := func(s *v1alpha1.TaskRunStatus) {
+		s.MarkExpiring(expiresAt, ReasonTTLPending, "TaskRun will be deleted")
+	}
+	for _, tc := range []struct {
+		name        string
+		tr          *v1alpha1.TaskRun
+		wantUpdate  bool
+		wantAt      *metav1.Time
+		wantMessage string
+	}{{
+		name:        "pending",
+		tr:          finishedTaskRun("test-taskrun", 10*time.Minute),
+		wantUpdate:  true,
+		wantAt:      &expiresAt,
+		wantMessage: "TaskRun will be deleted at 2019-10-01T12:50:00Z, once its TTL elapses",
+	}, {
+		name:        "compact",
+		tr:          finishedTaskRun("test-taskrun", 10*time.Minute, tb.TaskRunSpec(tb.TaskRunCleanupMode(v1alpha1.TaskRunCleanupModeCompact))),
+		wantUpdate:  true,
+		wantAt:      &expiresAt,
+		wantMessage: "TaskRun will be compacted at 2019-10-01T12:50:00Z, once its TTL elapses",
+	}, {
+		name:   "up to date",
+		tr:     finishedTaskRun("test-taskrun", 10*time.Minute, tb.TaskRunStatus(expiring)),
+		wantAt: &expiresAt,
+	}, {
+		name:       "kept",
+		tr:         finishedTaskRun("test-taskrun", 10*time.Minute, tb.TaskRunAnnotation(KeepAnnotationKey, "true"), tb.TaskRunStatus(expiring)),
+		wantUpdate: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{tc.tr}})
+			q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+			impl := NewExpirationController(images, ExpirationScope{}, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+			r := impl.Reconciler.(*ExpirationReconciler)
+			r.enqueueAfter = q.EnqueueAfter
+
+			if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+				t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+			}
+			if updated := len(c.Pipeline.Actions()) != 0; updated != tc.wantUpdate {
+				t.Fatalf("Expected the status to be updated: %t, got actions %v", tc.wantUpdate, c.Pipeline.Actions())
+			}
+			tr, err := c.Pipeline.TektonV1alpha1().TaskRuns("foo").Get("test-taskrun", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Failed to get the TaskRun: %v", err)
+			}
+			if d := cmp.Diff(tc.wantAt, tr.Status.ExpirationTime); d != "" {
+				t.Errorf("Unexpected expiration time (-want, +got): %s", d)
+			}
+			cond := tr.Status.GetCondition(v1alpha1.TaskRunConditionExpiring)
+			if tc.wantAt == nil {
+				if cond != nil {
+					t.Errorf("Expected the Expiring condition to be cleared, got %v", cond)
+				}
+			} else if cond == nil || !cond.IsTrue() || cond.Reason != ReasonTTLPending {
+				t.Errorf("Expected the Expiring condition to be true, got %v", cond)
+			} else if tc.wantMessage != "" && cond.Message != tc.wantMessage {
+				t.Errorf("Expected the Expiring condition message %q, got %q", tc.wantMessage, cond.Message)
+			}
+		})
+	}
+}
+
+func TestExpirationControllerWorkQueue(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tr := finishedTaskRun("test-taskrun", time.Hour-100*time.Millisecond)
+	other := finishedTaskRun("other-taskrun", 2*time.Hour)
+	c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{tr, other}})
+	impl := NewExpirationController(images, ExpirationScope{}, reconciler.WithClock(clock.NewFakeClock(testNow)))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	defer impl.WorkQueue.ShutDown()
+
+	if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+		t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+	}
+	if l := impl.WorkQueue.Len(); l != 0 {
+		t.Fatalf("Expected the TaskRun not to be enqueued before its TTL elapses, got %d objects enqueued", l)
+	}
+	r.UpdateTaskRun(other, other)
+
+	keys := make(chan interface{})
+	go func() {
+		for {
+			key, shutdown := impl.WorkQueue.Get()
+			if shutdown {
+				return
+			}
+			impl.WorkQueue.Done(key)
+			keys <- key
+		}
+	}()
+	for _, want := range []string{"foo/other-taskrun", "foo/test-taskrun"} {
+		select {
+		case key := <-keys:
+			if key != want {
+				t.Errorf("Expected %s to be dequeued, got %v", want, key)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %s to be dequeued", want)
+		}
+	}
+}
+
+type fakeArchiver struct {
+	paths []string
+	err   error
+}
+
+func (a *fakeArchiver) Put(_ context.Context, path string, _ []byte) error {
+	a.paths = append(a.paths, path)
+	return a.err
+}
+
+func TestReconcileTaskRunArchived(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		archiver    *fakeArchiver
+		wantErr     bool
+		wantDeleted bool
+	}{{
+		name:        "archived",
+		archiver:    &fakeArchiver{},
+		wantDeleted: true,
+	}, {
+		name:     "archive failure",
+		archiver: &fakeArchiver{err: errors.New("bucket not found")},
+		wantErr:  true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{finishedTaskRun("test-taskrun", 2*time.Hour)}})
+			store := config.NewStore(logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.CleanupConfigName, Namespace: system.GetNamespace()},
+				Data:       map[string]string{"archive.location": "fake://archive"},
+			})
+			opts := []reconciler.ControllerOption{reconciler.WithClock(clock.NewFakeClock(testNow)), reconciler.WithConfigStore(store)}
+			impl := NewExpirationController(images, ExpirationScope{}, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+			r := impl.Reconciler.(*ExpirationReconciler)
+			var locations []string
+			r.newArchiver = func(_ context.Context, location string) (archive.Archiver, error) {
+				locations = append(locations, location)
+				return tc.archiver, nil
+			}
+
+			if err := r.Reconcile(ctx, "foo/test-taskrun"); (err != nil) != tc.wantErr {
+				t.Fatalf("Reconcile() = %v, wantErr %t", err, tc.wantErr)
+			}
+			if d := cmp.Diff([]string{"fake://archive"}, locations); d != "" {
+				t.Errorf("archive locations diff -want, +got: %v", d)
+			}
+			if d := cmp.Diff([]string{"foo/test-taskrun-/taskrun.yaml"}, tc.archiver.paths); d != "" {
+				t.Errorf("archived paths diff -want, +got: %v", d)
+			}
+			var deleted bool
+			for _, a := range c.Pipeline.Actions() {
+				if a.GetVerb() == "delete" {
+					deleted = true
+				}
+			}
+			if deleted != tc.wantDeleted {
+				t.Errorf("Expected the TaskRun to be deleted: %t, got %t", tc.wantDeleted, deleted)
+			}
+		})
+	}
+}
+
+func TestReconcileTaskRunDryRun(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		scope ExpirationScope
+		cfg   map[string]string
+	}{{
+		name:  "flag",
+		scope: ExpirationScope{DryRun: true},
+	}, {
+		name: "config",
+		cfg:  map[string]string{"dry-run": "true"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{finishedTaskRun("test-taskrun", 2*time.Hour)}})
+			store := config.NewStore(logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.CleanupConfigName, Namespace: system.GetNamespace()},
+				Data:       tc.cfg,
+			})
+			opts := []reconciler.ControllerOption{reconciler.WithClock(clock.NewFakeClock(testNow)), reconciler.WithConfigStore(store)}
+			impl := NewExpirationController(images, tc.scope, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+			r := impl.Reconciler.(*ExpirationReconciler)
+			recorder := record.NewFakeRecorder(10)
+			r.Recorder = recorder
+
+			if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+				t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+			}
+			for _, a := range c.Pipeline.Actions() {
+				if a.GetVerb() == "delete" {
+					t.Errorf("Expected the TaskRun not to be deleted in dry run mode, got action %v", a)
+				}
+			}
+			select {
+			case e := <-recorder.Events:
+				if !strings.Contains(e, ReasonCleanupDryRun) {
+					t.Errorf("Expected a %s event, got %q", ReasonCleanupDryRun, e)
+				}
+			default:
+				t.Errorf("Expected a %s event", ReasonCleanupDryRun)
+			}
+		})
+	}
+}
+
+func TestReconcileTaskRunCleanupResources(t *testing.T) {
+	withPod := func(tr *v1alpha1.TaskRun) { tr.Status.PodName = "test-taskrun-pod" }
+	pod := func(owned bool) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun-pod", Namespace: "foo"},
+			Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+				Name:         "own",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "own-pvc"}},
+			}, {
+				Name:         "shared",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared-pvc"}},
+			}}},
+		}
+		if owned {
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: "TaskRun", Name: "test-taskrun"}}
+		}
+		return p
+	}
+	for _, tc := range []struct {
+		name      string
+		resources string
+		pod       *corev1.Pod
+		// wantDeleted are the pods and PersistentVolumeClaims expected to be
+		// deleted along with the TaskRun.
+		wantDeleted []string
+	}{{
+		name:        "default",
+		pod:         pod(false),
+		wantDeleted: []string{"pods/test-taskrun-pod"},
+	}, {
+		name:      "none",
+		resources: "none",
+		pod:       pod(false),
+	}, {
+		name:      "pod owned by the taskrun",
+		resources: "pods",
+		pod:       pod(true),
+	}, {
+		name:        "pods and pvcs",
+		resources:   "pods-and-pvcs",
+		pod:         pod(true),
+		wantDeleted: []string{"persistentvolumeclaims/own-pvc"},
+	}, {
+		name:      "pod already gone",
+		resources: "pods-and-pvcs",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			d := test.Data{TaskRuns: []*v1alpha1.TaskRun{finishedTaskRun("test-taskrun", 2*time.Hour, withPod)}}
+			if tc.pod != nil {
+				d.Pods = []*corev1.Pod{tc.pod}
+			}
+			c, _ := test.SeedTestData(t, ctx, d)
+			for _, pvc := range []*corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "own-pvc", Namespace: "foo", Labels: map[string]string{"tekton.dev/taskRun": "test-taskrun"}},
+			}, {
+				ObjectMeta: metav1.ObjectMeta{Name: "shared-pvc", Namespace: "foo"},
+			}} {
+				if _, err := c.Kube.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(pvc); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c.Kube.ClearActions()
+			store := config.NewStore(logtesting.TestLogger(t))
+			cfg := map[string]string{}
+			if tc.resources != "" {
+				cfg["cleanup-resources"] = tc.resources
+			}
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.CleanupConfigName, Namespace: system.GetNamespace()},
+				Data:       cfg,
+			})
+			opts := []reconciler.ControllerOption{reconciler.WithClock(clock.NewFakeClock(testNow)), reconciler.WithConfigStore(store)}
+			impl := NewExpirationController(images, ExpirationScope{}, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+			r := impl.Reconciler.(*ExpirationReconciler)
+
+			if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+				t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+			}
+			var deleted []string
+			for _, a := range c.Kube.Actions() {
+				if a, ok := a.(ktesting.DeleteAction); ok && a.GetVerb() == "delete" {
+					deleted = append(deleted, a.GetResource().Resource+"/"+a.GetName())
+				}
+			}
+			if d := cmp.Diff(tc.wantDeleted, deleted); d != "" {
+				t.Errorf("Deleted resources (-want, +got): %s", d)
+			}
+		})
+	}
+}
+
+func TestReconcileTaskRunThrottled(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{
+		finishedTaskRun("first", 2*time.Hour),
+		finishedTaskRun("second", 2*time.Hour),
+		finishedTaskRun("third", 2*time.Hour),
+	}})
+	store := config.NewStore(logtesting.TestLogger(t))
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.CleanupConfigName, Namespace: system.GetNamespace()},
+		Data:       map[string]string{"deletes-per-second": "0.01"},
+	})
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+	opts := []reconciler.ControllerOption{reconciler.WithClock(q.Clock), reconciler.WithConfigStore(store)}
+	impl := NewExpirationController(images, ExpirationScope{}, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	c.Pipeline.ClearActions()
+	for _, key := range []string{"foo/first", "foo/second", "foo/third"} {
+		if err := r.Reconcile(ctx, key); err != nil {
+			t.Fatalf("Unexpected error reconciling %s: %v", key, err)
+		}
+	}
+	// The throttled TaskRuns aren't even fetched.
+	var touched []string
+	for _, a := range c.Pipeline.Actions() {
+		if a.GetResource().Resource == "taskruns" {
+			touched = append(touched, a.GetVerb()+" "+a.(interface{ GetName() string }).GetName())
+		}
+	}
+	if d := cmp.Diff([]string{"get first", "delete first"}, touched); d != "" {
+		t.Errorf("TaskRun actions (-want, +got): %s", d)
+	}
+	// Their retries are spread over the successive slots of the rate limit,
+	// in which they are deleted.
+	for _, want := range []struct {
+		name  string
+		after time.Duration
+	}{{"second", 100 * time.Second}, {"third", 200 * time.Second}} {
+		if at, ok := q.NextAt(); !ok || at.Sub(testNow) != want.after {
+			t.Fatalf("Expected TaskRun %s to be enqueued again after %s, got %v", want.name, want.after, at)
+		}
+		due := q.TravelToNext()
+		if len(due) != 1 || due[0].(*v1alpha1.TaskRun).Name != want.name {
+			t.Fatalf("Expected TaskRun %s to be due, got %v", want.name, due)
+		}
+		c.Pipeline.ClearActions()
+		if err := r.Reconcile(ctx, "foo/"+want.name); err != nil {
+			t.Fatalf("Unexpected error reconciling %s: %v", want.name, err)
+		}
+		if _, err := c.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(want.name, metav1.GetOptions{}); !k8sapierrors.IsNotFound(err) {
+			t.Errorf("Expected TaskRun %s to be deleted in its slot, got %v", want.name, err)
+		}
+	}
+}
+
+func TestReconcileTaskRunMinimumRetention(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		finishedAgo time.Duration
+		wantDeleted bool
+		wantEnqueue time.Duration
+	}{{
+		name:        "retained",
+		finishedAgo: 5 * time.Minute,
+		wantEnqueue: 25 * time.Minute,
+	}, {
+		name:        "retention elapsed",
+		finishedAgo: 45 * time.Minute,
+		wantDeleted: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			tr := finishedTaskRun("test-taskrun", tc.finishedAgo, tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(time.Minute)))
+			c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{tr}})
+			store := config.NewStore(logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.CleanupConfigName, Namespace: system.GetNamespace()},
+				Data:       map[string]string{"minimum-retention": "30m"},
+			})
+			q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+			opts := []reconciler.ControllerOption{reconciler.WithClock(q.Clock), reconciler.WithConfigStore(store)}
+			impl := NewExpirationController(images, ExpirationScope{}, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+			r := impl.Reconciler.(*ExpirationReconciler)
+			r.enqueueAfter = q.EnqueueAfter
+
+			if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+				t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+			}
+			var deleted bool
+			for _, a := range c.Pipeline.Actions() {
+				if a.GetVerb() == "delete" {
+					deleted = true
+				}
+			}
+			if deleted != tc.wantDeleted {
+				t.Errorf("Expected the TaskRun to be deleted: %t, got %t", tc.wantDeleted, deleted)
+			}
+			var enqueued time.Duration
+			if at, ok := q.NextAt(); ok {
+				enqueued = at.Sub(testNow)
+			}
+			if enqueued != tc.wantEnqueue {
+				t.Errorf("Expected the TaskRun to be enqueued again after %s, got %s", tc.wantEnqueue, enqueued)
+			}
+		})
+	}
+}
+
+func TestReconcileTaskRunBlockingFinalizers(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tr := finishedTaskRun("test-taskrun", time.Hour, tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(time.Minute)))
+	tr.Finalizers = []string{"chains.tekton.dev"}
+	c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{tr}})
+	store := config.NewStore(logtesting.TestLogger(t))
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.CleanupConfigName, Namespace: system.GetNamespace()},
+		Data:       map[string]string{"blocking-finalizers": "chains.tekton.dev"},
+	})
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+	opts := []reconciler.ControllerOption{reconciler.WithClock(q.Clock), reconciler.WithConfigStore(store)}
+	impl := NewExpirationController(images, ExpirationScope{}, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	if err := r.Reconcile(ctx, "foo/test-taskrun"); !IsHeld(err) {
+		t.Fatalf("Expected the cleanup of the TaskRun to be held off, got %v", err)
+	}
+	for _, a := range c.Pipeline.Actions() {
+		if a.GetVerb() == "delete" {
+			t.Errorf("Expected the TaskRun not to be deleted while blocking finalizers are set, got %v", a)
+		}
+	}
+}
+
+func TestReconcileTaskRunExcludedNamespace(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		cfg         map[string]string
+		labels      map[string]string
+		wantDeleted bool
+	}{{
+		name: "excluded namespace",
+		cfg:  map[string]string{"excluded-namespaces": "prod-audit,foo"},
+	}, {
+		name:   "excluded by selector",
+		cfg:    map[string]string{"excluded-namespace-selector": "tekton.dev/cleanup=disabled"},
+		labels: map[string]string{"tekton.dev/cleanup": "disabled"},
+	}, {
+		name:        "not excluded by selector",
+		cfg:         map[string]string{"excluded-namespace-selector": "tekton.dev/cleanup=disabled"},
+		labels:      map[string]string{"tekton.dev/cleanup": "enabled"},
+		wantDeleted: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			tr := finishedTaskRun("test-taskrun", time.Hour, tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(time.Minute)))
+			c, _ := test.SeedTestData(t, ctx, test.Data{
+				TaskRuns:   []*v1alpha1.TaskRun{tr},
+				Namespaces: []*corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: tc.labels}}},
+			})
+			store := config.NewStore(logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.CleanupConfigName, Namespace: system.GetNamespace()},
+				Data:       tc.cfg,
+			})
+			q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+			opts := []reconciler.ControllerOption{reconciler.WithClock(q.Clock), reconciler.WithConfigStore(store)}
+			impl := NewExpirationController(images, ExpirationScope{}, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+			r := impl.Reconciler.(*ExpirationReconciler)
+			r.enqueueAfter = q.EnqueueAfter
+
+			if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+				t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+			}
+			var deleted bool
+			for _, a := range c.Pipeline.Actions() {
+				if a.GetVerb() == "delete" {
+					deleted = true
+				}
+			}
+			if deleted != tc.wantDeleted {
+				t.Errorf("Expected the TaskRun to be deleted: %t, got %t", tc.wantDeleted, deleted)
+			}
+		})
+	}
+}
+
+func TestReconcileTaskRunOtherBucket(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tr := finishedTaskRun("test-taskrun", time.Hour, tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(time.Minute)))
+	c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{tr}})
+	// Another replica leads the only bucket.
+	other := "controller-b"
+	renewed := metav1.NewMicroTime(testNow)
+	seconds := int32(15)
+	if _, err := c.Kube.CoordinationV1beta1().Leases(system.GetNamespace()).Create(&coordinationv1beta1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "cleanup.00-of-01"},
+		Spec:       coordinationv1beta1.LeaseSpec{HolderIdentity: &other, LeaseDurationSeconds: &seconds, RenewTime: &renewed},
+	}); err != nil {
+		t.Fatalf("Failed to create the Lease: %v", err)
+	}
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+	elector, err := reconciler.NewBucketElector("cleanup", system.GetNamespace(), "controller-a", 1, 15*time.Second, q.Clock)
+	if err != nil {
+		t.Fatalf("NewBucketElector() = %v", err)
+	}
+	opts := []reconciler.ControllerOption{reconciler.WithClock(q.Clock), reconciler.WithBucketElector(elector)}
+	impl := NewExpirationController(images, ExpirationScope{}, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+		t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+	}
+	if n := r.SweepExpired(); n != 0 {
+		t.Errorf("Sweep enqueued %d TaskRuns of a bucket led by another replica", n)
+	}
+	for _, a := range c.Pipeline.Actions() {
+		if a.GetVerb() == "delete" {
+			t.Errorf("TaskRun of a bucket led by another replica deleted: %v", a)
+		}
+	}
+}
+
+func TestReconcileTaskRunOwnedByPipelineRun(t *testing.T) {
+	running := tb.PipelineRun("test-pipeline-run", "foo", tb.PipelineRunSpec("test-pipeline",
+		tb.PipelineRunExpirationSecondsTTL(2*time.Hour),
+	))
+	finished := func(ago time.Duration) *v1alpha1.PipelineRun {
+		return tb.PipelineRun("test-pipeline-run", "foo",
+			tb.PipelineRunSpec("test-pipeline", tb.PipelineRunExpirationSecondsTTL(2*time.Hour)),
+			tb.PipelineRunStatus(
+				tb.PipelineRunStatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}),
+				tb.PipelineRunCompletionTime(testNow.Add(-ago)),
+			),
+		)
+	}
+	for _, tc := range []struct {
+		name        string
+		pr          *v1alpha1.PipelineRun
+		wantDeleted bool
+		wantEnqueue time.Duration
+	}{{
+		name: "pipelinerun running",
+		pr:   running,
+	}, {
+		name:        "pipelinerun not expired",
+		pr:          finished(90 * time.Minute),
+		wantEnqueue: 30 * time.Minute,
+	}, {
+		name:        "pipelinerun expired",
+		pr:          finished(3 * time.Hour),
+		wantDeleted: true,
+	}, {
+		name:        "pipelinerun deleted",
+		wantDeleted: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			tr := finishedTaskRun("test-taskrun", time.Hour,
+				tb.TaskRunSpec(tb.TaskRunExpirationSecondsTTL(time.Minute)),
+				tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run", tb.Controller),
+				tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, "test-pipeline-run"),
+			)
+			d := test.Data{TaskRuns: []*v1alpha1.TaskRun{tr}}
+			if tc.pr != nil {
+				d.PipelineRuns = []*v1alpha1.PipelineRun{tc.pr}
+			}
+			c, _ := test.SeedTestData(t, ctx, d)
+			q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+			impl := NewExpirationController(images, ExpirationScope{}, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+			r := impl.Reconciler.(*ExpirationReconciler)
+			r.enqueueAfter = q.EnqueueAfter
+
+			if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+				t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+			}
+			var deleted bool
+			for _, a := range c.Pipeline.Actions() {
+				if a.GetVerb() == "delete" {
+					deleted = true
+				}
+			}
+			if deleted != tc.wantDeleted {
+				t.Errorf("Expected the TaskRun to be deleted: %t, got %t", tc.wantDeleted, deleted)
+			}
+			var enqueued time.Duration
+			if at, ok := q.NextAt(); ok {
+				enqueued = at.Sub(testNow)
+			}
+			if enqueued != tc.wantEnqueue {
+				t.Errorf("Expected the TaskRun to be enqueued again after %s, got %s", tc.wantEnqueue, enqueued)
+			}
+		})
+	}
+}
+
+func TestReconcileTaskRunClockSkew(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		cfg         map[string]string
+		wantErr     bool
+		wantEnqueue time.Duration
+	}{{
+		name:    "beyond the default allowed skew",
+		wantErr: true,
+	}, {
+		name:        "within the allowed skew",
+		cfg:         map[string]string{"allowed-clock-skew": "15m"},
+		wantEnqueue: 70 * time.Minute,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer metricstest.Unregister("cleanup_clock_skew_count")
+			ctx, _ := ttesting.SetupFakeContext(t)
+			ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			// The node which ran the TaskRun is 10 minutes ahead of the controller.
+			tr := finishedTaskRun("test-taskrun", -10*time.Minute)
+			c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{tr}})
+			store := config.NewStore(logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.CleanupConfigName, Namespace: system.GetNamespace()},
+				Data:       tc.cfg,
+			})
+			q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+			opts := []reconciler.ControllerOption{reconciler.WithClock(q.Clock), reconciler.WithConfigStore(store)}
+			impl := NewExpirationController(images, ExpirationScope{}, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+			r := impl.Reconciler.(*ExpirationReconciler)
+			r.enqueueAfter = q.EnqueueAfter
+
+			err := r.Reconcile(ctx, "foo/test-taskrun")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected an error reconciling taskrun: %t, got %v", tc.wantErr, err)
+			}
+			if tc.wantErr {
+				metricstest.CheckCountData(t, "cleanup_clock_skew_count", map[string]string{"kind": "TaskRun"}, 1)
+			}
+			var enqueued time.Duration
+			if at, ok := q.NextAt(); ok {
+				enqueued = at.Sub(testNow)
+			}
+			if enqueued != tc.wantEnqueue {
+				t.Errorf("Expected the TaskRun to be enqueued again after %s, got %s", tc.wantEnqueue, enqueued)
+			}
+		})
+	}
+}
+
+func TestReconcileTaskRunCompacted(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tr := finishedTaskRun("test-taskrun", 2*time.Hour,
+		tb.TaskRunSpec(tb.TaskRunCleanupMode(v1alpha1.TaskRunCleanupModeCompact)),
+		tb.TaskRunStatus(
+			tb.PodName("test-taskrun-pod"),
+			tb.StepState(tb.StateTerminated(0)),
+			tb.Retry(v1alpha1.TaskRunStatus{PodName: "test-taskrun-pod-retry1"}),
+		),
+	)
+	c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{tr}})
+	recorder := record.NewFakeRecorder(10)
+	impl := NewExpirationController(images, ExpirationScope{}, reconciler.WithClock(clock.NewFakeClock(testNow)))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	r.Recorder = recorder
+
+	if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+		t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+	}
+	for _, a := range c.Pipeline.Actions() {
+		if a.GetVerb() == "delete" {
+			t.Errorf("Expected the TaskRun to be compacted instead of deleted, got %v", a)
+		}
+	}
+	got, err := c.Pipeline.TektonV1alpha1().TaskRuns("foo").Get("test-taskrun", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the TaskRun to be kept: %v", err)
+	}
+	if !IsCompacted(got) {
+		t.Errorf("Expected the TaskRun to be annotated as compacted, got annotations %v", got.Annotations)
+	}
+	if len(got.Status.Steps) != 0 || len(got.Status.RetriesStatus) != 0 {
+		t.Errorf("Expected the steps and the retries to be stripped from the status, got %v", got.Status)
+	}
+	if got.Status.PodName != "test-taskrun-pod" || !got.IsSuccessful() || got.Status.CompletionTime == nil {
+		t.Errorf("Expected the pod name, the outcome and the completion time to be kept, got %v", got.Status)
+	}
+	select {
+	case e := <-recorder.Events:
+		if !strings.Contains(e, "TaskRun compacted") {
+			t.Errorf("Expected a TaskRun compacted event, got %q", e)
+		}
+	default:
+		t.Error("Expected a TaskRun compacted event")
+	}
+	if Expires(got) {
+		t.Error("Expected the compacted TaskRun not to be cleaned up again")
+	}
+}
+
+func TestReconcileTaskRunToTaskRunArchive(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tr := finishedTaskRun("test-taskrun", 2*time.Hour, tb.TaskRunSpec(tb.TaskRunArchiveOnExpire), tb.TaskRunLabel("app", "hello"))
+	c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{tr}})
+	impl := NewExpirationController(images, ExpirationScope{}, reconciler.WithClock(clock.NewFakeClock(testNow)))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+
+	if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+		t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+	}
+	var verbs []string
+	for _, a := range c.Pipeline.Actions() {
+		if a.GetResource().Resource == "taskrunarchives" || a.GetVerb() == "delete" {
+			verbs = append(verbs, a.GetVerb()+" "+a.GetResource().Resource)
+		}
+	}
+	if d := cmp.Diff([]string{"create taskrunarchives", "delete taskruns"}, verbs); d != "" {
+		t.Errorf("Expected the TaskRun to be archived before being deleted, diff -want, +got: %v", d)
+	}
+	a, err := c.Pipeline.TektonV1alpha1().TaskRunArchives("foo").Get("test-taskrun", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected a TaskRunArchive of the TaskRun: %v", err)
+	}
+	if a.Labels["app"] != "hello" || a.Spec.TaskName != "hello-world" || a.Spec.Succeeded != corev1.ConditionTrue {
+		t.Errorf("Expected the TaskRunArchive to summarize the TaskRun, got %v", a)
+	}
+	got, err := a.TaskRun()
+	if err != nil {
+		t.Fatalf("Unexpected error reading the snapshot of the TaskRunArchive: %v", err)
+	}
+	if d := cmp.Diff(tr.Spec, got.Spec); d != "" {
+		t.Errorf("Snapshot spec diff -want, +got: %v", d)
+	}
+}
+
+// sentCloudEvents is a CloudEvents client recording the events it sends.
+type sentCloudEvents []cloudevents.Event
+
+func (s *sentCloudEvents) Send(_ context.Context, event cloudevents.Event) (*cloudevents.Event, error) {
+	*s = append(*s, event)
+	return &event, nil
+}
+
+func (s *sentCloudEvents) StartReceiver(context.Context, interface{}) error {
+	return nil
+}
+
+func TestReconcileTaskRunDeletedEvents(t *testing.T) {
+	tr := finishedTaskRun("test-taskrun", 2*time.Hour)
+	tr.UID = "1234"
+	tr.SelfLink = "/apis/tekton.dev/v1alpha1/namespaces/foo/taskruns/test-taskrun"
+
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: []*v1alpha1.TaskRun{tr}})
+	store := config.NewStore(logtesting.TestLogger(t))
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.CleanupConfigName, Namespace: system.GetNamespace()},
+		Data:       map[string]string{"events.sink": "http://audit.example.com"},
+	})
+	opts := []reconciler.ControllerOption{reconciler.WithClock(clock.NewFakeClock(testNow)), reconciler.WithConfigStore(store)}
+	impl := NewExpirationController(images, ExpirationScope{}, opts...)(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	recorder := ttesting.NewObjectRecorder(10)
+	r.Recorder = recorder
+	sent := &sentCloudEvents{}
+	r.cloudEventClient = sent
+
+	if err := r.Reconcile(ctx, "foo/test-taskrun"); err != nil {
+		t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+	}
+	select {
+	case e := <-recorder.Events:
+		if !strings.Contains(e, ReasonTTLExpired) || !strings.Contains(e, "TaskRun test-taskrun deleted") {
+			t.Errorf("Expected a %s event naming the deleted TaskRun, got %q", ReasonTTLExpired, e)
+		}
+	default:
+		t.Errorf("Expected a %s event", ReasonTTLExpired)
+	}
+	// The event outlives the TaskRun on its namespace.
+	if d := cmp.Diff([]runtime.Object{NamespaceRef("foo")}, recorder.Objects); d != "" {
+		t.Errorf("event objects diff -want, +got: %v", d)
+	}
+	if len(*sent) != 1 {
+		t.Fatalf("Expected one CloudEvent, got %d", len(*sent))
+	}
+	event := (*sent)[0]
+	if event.Type() != string(cloudevent.TektonTaskRunDeletedV1) || event.ID() != "1234" {
+		t.Errorf("Expected a %s CloudEvent with ID 1234, got %s with ID %s", cloudevent.TektonTaskRunDeletedV1, event.Type(), event.ID())
+	}
+	var data cloudevent.TektonCloudEventData
+	if err := json.Unmarshal(event.Data.([]byte), &data); err != nil {
+		t.Fatalf("Failed to unmarshal the data of the CloudEvent: %v", err)
+	}
+	if data.Reason != ReasonTTLExpired || data.TaskRun.Name != "test-taskrun" {
+		t.Errorf("Expected the CloudEvent to hold TaskRun test-taskrun and reason %s, got %s and %s", ReasonTTLExpired, data.TaskRun.Name, data.Reason)
+	}
+}
+
+func TestReconcileTaskRunHistoryLimit(t *testing.T) {
+	failed := func(tr *v1alpha1.TaskRun) {
+		tr.Status.Conditions[0].Status = corev1.ConditionFalse
+	}
+	trs := []*v1alpha1.TaskRun{
+		finishedTaskRun("succeeded-1", 3*time.Minute),
+		finishedTaskRun("succeeded-2", 2*time.Minute),
+		finishedTaskRun("succeeded-3", time.Minute),
+		finishedTaskRun("succeeded-kept", 4*time.Minute, tb.TaskRunAnnotation(KeepAnnotationKey, "true")),
+		finishedTaskRun("failed-1", 3*time.Minute, failed),
+		finishedTaskRun("failed-2", 2*time.Minute, failed),
+		finishedTaskRun("other", 5*time.Minute, tb.TaskRunLabel("trigger", "nightly")),
+	}
+	policies := []*v1alpha1.CleanupPolicy{
+		tb.CleanupPolicy("a-nightly", "foo", tb.CleanupPolicySelector(map[string]string{"trigger": "nightly"})),
+		tb.CleanupPolicy("b-everything", "foo", tb.CleanupPolicyHistoryLimits(1, 2)),
+	}
+
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c, _ := test.SeedTestData(t, ctx, test.Data{TaskRuns: trs, CleanupPolicies: policies})
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+	impl := NewExpirationController(images, ExpirationScope{}, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	if err := r.Reconcile(ctx, "foo/succeeded-3"); err != nil {
+		t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+	}
+	if err := r.Reconcile(ctx, "foo/failed-2"); err != nil {
+		t.Fatalf("Unexpected error reconciling taskrun: %v", err)
+	}
+
+	var deleted []string
+	for _, a := range c.Pipeline.Actions() {
+		if a, ok := a.(ktesting.DeleteAction); ok {
+			deleted = append(deleted, a.GetName())
+		}
+	}
+	if d := cmp.Diff([]string{"succeeded-2", "succeeded-1"}, deleted); d != "" {
+		t.Errorf("deleted TaskRuns diff -want, +got: %v", d)
+	}
+}
+
+func TestExpirationScope(t *testing.T) {
+	selector, err := labels.Parse("cleanup=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name  string
+		scope ExpirationScope
+		tr    *v1alpha1.TaskRun
+		want  bool
+	}{{
+		name: "everything",
+		tr:   finishedTaskRun("test-taskrun", 0),
+		want: true,
+	}, {
+		name:  "matching selector",
+		scope: ExpirationScope{Selector: selector},
+		tr:    finishedTaskRun("test-taskrun", 0, tb.TaskRunLabel("cleanup", "true")),
+		want:  true,
+	}, {
+		name:  "not matching selector",
+		scope: ExpirationScope{Selector: selector},
+		tr:    finishedTaskRun("test-taskrun", 0),
+	}, {
+		name:  "excluded namespace",
+		scope: ExpirationScope{ExcludedNamespaces: sets.NewString("audited", "foo")},
+		tr:    finishedTaskRun("test-taskrun", 0),
+	}, {
+		name:  "other namespace excluded",
+		scope: ExpirationScope{ExcludedNamespaces: sets.NewString("audited")},
+		tr:    finishedTaskRun("test-taskrun", 0),
+		want:  true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.scope.Matches(tc.tr); got != tc.want {
+				t.Errorf("Matches() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSweepExpiredTaskRuns(t *testing.T) {
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	excluded := finishedTaskRun("excluded", 2*time.Hour)
+	excluded.Namespace = "kube-system"
+	// There is no CleanupPolicy in namespace bar, only the TaskRuns whose own
+	// TTL elapsed are checked there.
+	inBar := func(tr *v1alpha1.TaskRun) { tr.Namespace = "bar" }
+	c, _ := test.SeedTestData(t, ctx, test.Data{
+		TaskRuns: []*v1alpha1.TaskRun{
+			finishedTaskRun("expired", 2*time.Hour),
+			finishedTaskRun("not-expired", 30*time.Minute),
+			finishedTaskRun("expired-by-policy", 30*time.Minute, func(tr *v1alpha1.TaskRun) {
+				tr.Spec.ExpirationSecondsTTL = nil
+			}),
+			excluded,
+			finishedTaskRun("expired-in-bar", 2*time.Hour, inBar),
+			finishedTaskRun("not-expired-in-bar", 30*time.Minute, inBar),
+		},
+		CleanupPolicies: []*v1alpha1.CleanupPolicy{
+			tb.CleanupPolicy("short", "foo", tb.CleanupPolicyExpirationSecondsTTL(time.Minute)),
+		},
+	})
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(testNow))
+	scope := ExpirationScope{ExcludedNamespaces: sets.NewString("kube-system")}
+	impl := NewExpirationController(images, scope, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*ExpirationReconciler)
+	var enqueued []string
+	r.enqueue = func(obj interface{}) {
+		enqueued = append(enqueued, obj.(*v1alpha1.TaskRun).Name)
+	}
+
+	if n := r.SweepExpired(); n != 3 {
+		t.Errorf("Expected the sweep to find 3 expired TaskRuns, got %d", n)
+	}
+	if d := cmp.Diff([]string{"expired", "expired-by-policy", "expired-in-bar"}, sets.NewString(enqueued...).List()); d != "" {
+		t.Errorf("Enqueued TaskRuns diff -want, +got: %s", d)
+	}
+}
@@ -27,8 +27,10 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/tektoncd/pipeline/pkg/apis/config"
+	cfgtesting "github.com/tektoncd/pipeline/pkg/apis/config/testing"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/entrypoint"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources/cloudevent"
@@ -47,9 +49,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	k8sruntimeschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
 	ktesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/configmap"
 )
@@ -112,6 +116,11 @@ var (
 		tb.TaskOutputs(tb.OutputsResource(gitResource.Name, v1alpha1.PipelineResourceTypeGit)),
 	))
 
+	taskWithWorkspace = tb.Task("test-task-with-workspace", "foo", tb.TaskSpec(
+		tb.TaskWorkspace("source", "", false),
+		tb.Step("build", "foo", tb.StepCommand("/mycmd"), tb.StepWorkingDir("$(workspaces.source.path)")),
+	))
+
 	saTask = tb.Task("test-with-sa", "foo", tb.TaskSpec(tb.Step("sa-step", "foo", tb.StepCommand("/mycmd"))))
 
 	taskEnvTask = tb.Task("test-task-env", "foo", tb.TaskSpec(
@@ -392,8 +401,8 @@ func TestReconcile_ExplicitDefaultSA(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			ctx := config.ToContext(context.Background(), defaultCfg)
-			if err := c.Reconciler.Reconcile(ctx, getRunName(tc.taskRun)); err != nil {
+			c.Reconciler.(*Reconciler).configStore = cfgtesting.StaticConfigStore{Config: defaultCfg}
+			if err := c.Reconciler.Reconcile(context.Background(), getRunName(tc.taskRun)); err != nil {
 				t.Errorf("expected no error. Got error %v", err)
 			}
 			if len(clients.Kube.Actions()) == 0 {
@@ -1309,6 +1318,21 @@ func TestReconcile(t *testing.T) {
 			if d := cmp.Diff(tc.wantPod.Spec, pod.Spec, resourceQuantityCmp); d != "" {
 				t.Errorf("Pod spec doesn't match (-want, +got): %s", d)
 			}
+
+			// The step containers are in the status before they report a
+			// state, sorted in the order of the steps of the Task.
+			var wantContainers, gotContainers []string
+			for _, c := range pod.Spec.Containers {
+				if resources.IsContainerStep(c.Name) {
+					wantContainers = append(wantContainers, c.Name)
+				}
+			}
+			for _, s := range tr.Status.Steps {
+				gotContainers = append(gotContainers, s.ContainerName)
+			}
+			if d := cmp.Diff(wantContainers, gotContainers, cmpopts.SortSlices(func(x, y string) bool { return x < y })); d != "" {
+				t.Errorf("Step container names in status don't match (-want, +got): %s", d)
+			}
 			if len(clients.Kube.Actions()) == 0 {
 				t.Fatalf("Expected actions to be logged in the kubeclient, got none")
 			}
@@ -1449,8 +1473,17 @@ func TestReconcileInvalidTaskRuns(t *testing.T) {
 	withWrongRef := tb.TaskRun("taskrun-with-wrong-ref", "foo", tb.TaskRunSpec(
 		tb.TaskRunTaskRef("taskrun-with-wrong-ref", tb.TaskRefKind(v1alpha1.ClusterTaskKind)),
 	))
-	taskRuns := []*v1alpha1.TaskRun{noTaskRun, withWrongRef}
-	tasks := []*v1alpha1.Task{simpleTask}
+	withMissingStepAction := tb.TaskRun("taskrun-with-missing-stepaction", "foo", tb.TaskRunSpec(
+		tb.TaskRunTaskSpec(tb.Step("build", "", tb.StepRef("missing"))),
+	))
+	withWrongChecksum := tb.TaskRun("taskrun-with-wrong-checksum", "foo", tb.TaskRunSpec(
+		tb.TaskRunTaskRef(simpleTask.Name, tb.TaskRefChecksum("sha256:"+strings.Repeat("0", 64))),
+	))
+	withUnboundWorkspace := tb.TaskRun("taskrun-with-unbound-workspace", "foo", tb.TaskRunSpec(
+		tb.TaskRunTaskRef(taskWithWorkspace.Name),
+	))
+	taskRuns := []*v1alpha1.TaskRun{noTaskRun, withWrongRef, withMissingStepAction, withWrongChecksum, withUnboundWorkspace}
+	tasks := []*v1alpha1.Task{simpleTask, taskWithWorkspace}
 
 	d := test.Data{
 		TaskRuns: taskRuns,
@@ -1472,6 +1505,21 @@ func TestReconcileInvalidTaskRuns(t *testing.T) {
 			taskRun: withWrongRef,
 			reason:  status.ReasonFailedResolution,
 		},
+		{
+			name:    "task run with missing step action",
+			taskRun: withMissingStepAction,
+			reason:  status.ReasonFailedResolution,
+		},
+		{
+			name:    "task run with wrong task checksum",
+			taskRun: withWrongChecksum,
+			reason:  status.ReasonTaskVerificationFailed,
+		},
+		{
+			name:    "task run with unbound workspace",
+			taskRun: withUnboundWorkspace,
+			reason:  status.ReasonFailedValidation,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -1607,6 +1655,56 @@ func TestReconcilePodUpdateStatus(t *testing.T) {
 	}
 }
 
+func TestReconcilePodProgress(t *testing.T) {
+	taskRun := tb.TaskRun("test-taskrun-progress", "foo", tb.TaskRunSpec(tb.TaskRunTaskRef("test-task")))
+	pod, err := makePod(taskRun, simpleTask)
+	if err != nil {
+		t.Fatalf("MakePod: %v", err)
+	}
+	progress := `{"step":"simple-step","percent":42,"message":"compiling"}`
+	terminated := func(progress string, finishedAgo time.Duration) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			Message:    fmt.Sprintf(`[{"key":"StepProgress","value":%q}]`, progress),
+			FinishedAt: metav1.NewTime(time.Now().Add(-finishedAgo)),
+		}}
+	}
+	// The progress of the last finished step is reported.
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "step-later",
+		State: terminated(progress, time.Minute),
+	}, {
+		Name:  "step-earlier",
+		State: terminated(`{"step":"earlier","percent":100}`, time.Hour),
+	}, {
+		Name:  "step-running",
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}}
+	taskRun.Status = v1alpha1.TaskRunStatus{
+		PodName: pod.Name,
+	}
+	d := test.Data{
+		TaskRuns: []*v1alpha1.TaskRun{taskRun},
+		Tasks:    []*v1alpha1.Task{simpleTask},
+		Pods:     []*corev1.Pod{pod},
+	}
+
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if err := c.Reconciler.Reconcile(context.Background(), fmt.Sprintf("%s/%s", taskRun.Namespace, taskRun.Name)); err != nil {
+		t.Fatalf("Unexpected error when Reconcile() : %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1alpha1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	if got := newTr.Annotations[v1alpha1.ProgressAnnotationKey]; got != progress {
+		t.Errorf("Expected the progress %s to be copied from the termination message of the step, got %q", progress, got)
+	}
+}
+
 func TestCreateRedirectedTaskSpec(t *testing.T) {
 	tr := tb.TaskRun("tr", "tr", tb.TaskRunSpec(
 		tb.TaskRunServiceAccountName("sa"),
@@ -1623,7 +1721,7 @@ func TestCreateRedirectedTaskSpec(t *testing.T) {
 	observer, _ := observer.New(zap.InfoLevel)
 	entrypointCache, _ := entrypoint.NewCache()
 	c := fakekubeclientset.NewSimpleClientset()
-	ts, err := createRedirectedTaskSpec(c, "override-with-entrypoint:latest", &task.Spec, tr, entrypointCache, zap.New(observer).Sugar())
+	ts, err := createRedirectedTaskSpec(c, "override-with-entrypoint:latest", &task.Spec, tr, entrypointCache, &config.Defaults{}, zap.New(observer).Sugar())
 	if err != nil {
 		t.Errorf("expected createRedirectedTaskSpec to pass: %v", err)
 	}
@@ -1832,6 +1930,24 @@ func TestHandlePodCreationError(t *testing.T) {
 		expectedType:   apis.ConditionSucceeded,
 		expectedStatus: corev1.ConditionFalse,
 		expectedReason: status.ReasonCouldntGetTask,
+	}, {
+		description:    "images without a command fail the taskrun validation",
+		err:            xerrors.Errorf("couldn't create redirected TaskSpec: %w", &entrypoint.UnresolvableEntrypointError{Image: "busybox", Reason: "no command"}),
+		expectedType:   apis.ConditionSucceeded,
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: status.ReasonFailedValidation,
+	}, {
+		description:    "images breaking the image policy fail the taskrun validation",
+		err:            &resources.ImagePolicyError{Err: apis.ErrInvalidValue("busybox", "steps[0].image")},
+		expectedType:   apis.ConditionSucceeded,
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: status.ReasonFailedValidation,
+	}, {
+		description:    "other validation errors aren't about the images",
+		err:            xerrors.Errorf("invalid workspace: %w", apis.ErrMissingField("workspaces")),
+		expectedType:   apis.ConditionSucceeded,
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: status.ReasonCouldntGetTask,
 	}}
 	for _, tc := range testcases {
 		t.Run(tc.description, func(t *testing.T) {
@@ -2087,6 +2203,29 @@ func TestUpdateTaskRunStatus_withValidJson(t *testing.T) {
 	}
 }
 
+func TestUpdateTaskRunStatus_withTaskResults(t *testing.T) {
+	tr := tb.TaskRun("test-taskrun-results", "foo", tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+	})))
+	// Each step reports the results written so far.
+	for _, msg := range []string{
+		`[{"key":"commit","value":"abc","type":"TaskRunResult"}]`,
+		`[{"key":"commit","value":"abc","type":"TaskRunResult"},{"key":"digest","value":"sha256:1234","type":"TaskRunResult"},{"key":"StepSkipped","value":"78"}]`,
+	} {
+		if err := updateTaskRunStatusWithResourceResult(tr, []byte(msg)); err != nil {
+			t.Fatalf("UpdateTaskRunStatusWithResourceResult failed with error: %s", err)
+		}
+	}
+	want := []v1alpha1.TaskRunResult{{Name: "commit", Value: "abc"}, {Name: "digest", Value: "sha256:1234"}}
+	if d := cmp.Diff(want, tr.Status.TaskResults); d != "" {
+		t.Errorf("task results mismatch (-want, +got): %s", d)
+	}
+	if len(tr.Status.ResourcesResult) != 0 {
+		t.Errorf("Expected no resource results, got %v", tr.Status.ResourcesResult)
+	}
+}
+
 func TestUpdateTaskRunStatus_withInvalidJson(t *testing.T) {
 	for _, c := range []struct {
 		desc    string
@@ -2151,3 +2290,575 @@ func TestUpdateTaskRunStatus_withInvalidJson(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcilePrivilegedStepForbidden(t *testing.T) {
+	privileged := true
+	privilegedTask := tb.Task("test-privileged-task", "foo", tb.TaskSpec(
+		tb.Step("dind", "docker:dind", tb.StepCommand("/mycmd"), func(s *v1alpha1.Step) {
+			s.SecurityContext = &corev1.SecurityContext{Privileged: &privileged}
+		}),
+		tb.Step("unprivileged", "foo", tb.StepCommand("/mycmd")),
+	))
+	for _, tc := range []struct {
+		name       string
+		forbidden  bool
+		wantEvents int
+	}{{
+		name:       "forbidden",
+		forbidden:  true,
+		wantEvents: 1,
+	}, {
+		name:       "allowed",
+		forbidden:  false,
+		wantEvents: 0,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := tb.TaskRun("test-taskrun-privileged", "foo", tb.TaskRunSpec(
+				tb.TaskRunTaskRef(privilegedTask.Name),
+			))
+			testAssets, cancel := getTaskRunController(t, test.Data{
+				TaskRuns: []*v1alpha1.TaskRun{tr},
+				Tasks:    []*v1alpha1.Task{privilegedTask},
+			})
+			defer cancel()
+			clients := testAssets.Clients
+			if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			recorder := record.NewFakeRecorder(10)
+			testAssets.Controller.Reconciler.(*Reconciler).Recorder = recorder
+
+			testAssets.Controller.Reconciler.(*Reconciler).configStore = cfgtesting.StaticConfigStore{Config: &config.Config{
+				Defaults: &config.Defaults{
+					DefaultTimeoutMinutes: 60,
+					ForbidPrivilegedSteps: tc.forbidden,
+				},
+			}}
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(tr)); err != nil {
+				t.Fatalf("Unexpected error reconciling: %v", err)
+			}
+
+			var warnings []string
+			for len(recorder.Events) > 0 {
+				if e := <-recorder.Events; strings.Contains(e, status.ReasonPrivilegedStepForbidden) {
+					warnings = append(warnings, e)
+				}
+			}
+			if len(warnings) != tc.wantEvents {
+				t.Fatalf("Expected %d %s events, got %v", tc.wantEvents, status.ReasonPrivilegedStepForbidden, warnings)
+			}
+			if tc.wantEvents > 0 && !strings.Contains(warnings[0], "step dind request") {
+				t.Errorf("Expected the warning to name the privileged step, got %q", warnings[0])
+			}
+		})
+	}
+}
+
+func TestReconcileExecutionWindow(t *testing.T) {
+	defer unregisterMetrics()
+	saturday := time.Date(2019, time.November, 16, 10, 30, 0, 0, time.UTC)
+	monday := time.Date(2019, time.November, 18, 9, 0, 0, 0, time.UTC)
+	tr := tb.TaskRun("test-taskrun-window", "foo", tb.TaskRunSpec(
+		tb.TaskRunTaskRef(simpleTask.Name),
+		tb.TaskRunExecutionWindow("UTC", "* 9-17 * * mon-fri"),
+	))
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	entrypointCache, _ = entrypoint.NewCache()
+	c, i := test.SeedTestData(t, ctx, test.Data{
+		TaskRuns: []*v1alpha1.TaskRun{tr},
+		Tasks:    []*v1alpha1.Task{simpleTask},
+	})
+	if _, err := c.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(saturday))
+	impl := NewController(images, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*Reconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	if err := r.Reconcile(context.Background(), getRunName(tr)); err != nil {
+		t.Fatalf("Unexpected error reconciling the TaskRun: %v", err)
+	}
+	got, err := c.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(tr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Couldn't get the TaskRun: %v", err)
+	}
+	if cond := got.Status.GetCondition(apis.ConditionSucceeded); cond == nil || !cond.IsUnknown() || cond.Reason != reconciler.ReasonWaitingForExecutionWindow {
+		t.Errorf("Expected the TaskRun to wait for its execution window, got condition %v", cond)
+	}
+	if got.Status.StartTime != nil || got.Status.PodName != "" {
+		t.Errorf("Expected the TaskRun not to start before its execution window opens, got start time %v and pod %q", got.Status.StartTime, got.Status.PodName)
+	}
+	if next, ok := q.NextAt(); !ok || !next.Equal(monday) {
+		t.Fatalf("Expected the TaskRun to be enqueued again at %s, got %s", monday, next)
+	}
+
+	q.TravelToNext()
+	if err := i.TaskRun.Informer().GetIndexer().Update(got); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reconcile(context.Background(), getRunName(tr)); err != nil {
+		t.Fatalf("Unexpected error reconciling the TaskRun: %v", err)
+	}
+	got, err = c.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(tr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Couldn't get the TaskRun: %v", err)
+	}
+	if got.Status.StartTime == nil || got.Status.PodName == "" {
+		t.Errorf("Expected the TaskRun to start once its execution window opens, got start time %v and pod %q", got.Status.StartTime, got.Status.PodName)
+	}
+	if cond := got.Status.GetCondition(apis.ConditionSucceeded); cond != nil && cond.Reason == reconciler.ReasonWaitingForExecutionWindow {
+		t.Errorf("Expected the TaskRun to stop waiting for its execution window, got condition %v", cond)
+	}
+}
+
+func TestReconcileQuota(t *testing.T) {
+	defer unregisterMetrics()
+	now := time.Date(2019, time.November, 16, 10, 30, 0, 0, time.UTC)
+	running := tb.TaskRun("test-taskrun-running", "foo",
+		tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)),
+		tb.TaskRunStatus(tb.TaskRunStartTime(now.Add(-time.Minute)), tb.StatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionUnknown,
+		})),
+	)
+	tr := tb.TaskRun("test-taskrun-quota", "foo", tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)))
+	owned := tb.TaskRun("test-taskrun-owned", "foo",
+		tb.TaskRunOwnerReference("PipelineRun", "test-pipelinerun", tb.Controller),
+		tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)),
+	)
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	entrypointCache, _ = entrypoint.NewCache()
+	c, i := test.SeedTestData(t, ctx, test.Data{
+		TaskRuns:       []*v1alpha1.TaskRun{running, tr, owned},
+		Tasks:          []*v1alpha1.Task{simpleTask},
+		PipelineQuotas: []*v1alpha1.PipelineQuota{tb.PipelineQuota("quota", "foo", tb.PipelineQuotaMaxConcurrentRuns(1))},
+	})
+	if _, err := c.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(now))
+	impl := NewController(images, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*Reconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	for _, run := range []*v1alpha1.TaskRun{tr, owned} {
+		if err := r.Reconcile(context.Background(), getRunName(run)); err != nil {
+			t.Fatalf("Unexpected error reconciling the TaskRun %q: %v", run.Name, err)
+		}
+	}
+	got, err := c.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(tr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Couldn't get the TaskRun: %v", err)
+	}
+	if cond := got.Status.GetCondition(apis.ConditionSucceeded); cond == nil || !cond.IsUnknown() || cond.Reason != reconciler.ReasonQuotaExceeded {
+		t.Errorf("Expected the TaskRun to wait for its quota, got condition %v", cond)
+	}
+	if got.Status.StartTime != nil || got.Status.PodName != "" {
+		t.Errorf("Expected the TaskRun not to start over its quota, got start time %v and pod %q", got.Status.StartTime, got.Status.PodName)
+	}
+	if next, ok := q.NextAt(); !ok || !next.Equal(now.Add(reconciler.QuotaRecheckInterval)) {
+		t.Fatalf("Expected the TaskRun to be enqueued again at %s, got %s", now.Add(reconciler.QuotaRecheckInterval), next)
+	}
+	gotOwned, err := c.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(owned.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Couldn't get the TaskRun: %v", err)
+	}
+	if gotOwned.Status.StartTime == nil || gotOwned.Status.PodName == "" {
+		t.Errorf("Expected the TaskRun of a PipelineRun not to be counted against quotas, got start time %v and pod %q", gotOwned.Status.StartTime, gotOwned.Status.PodName)
+	}
+
+	// Once the running TaskRun is done the waiting one fits in.
+	done := running.DeepCopy()
+	done.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+	for _, run := range []*v1alpha1.TaskRun{done, got} {
+		if err := i.TaskRun.Informer().GetIndexer().Update(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.TravelToNext()
+	if err := r.Reconcile(context.Background(), getRunName(tr)); err != nil {
+		t.Fatalf("Unexpected error reconciling the TaskRun: %v", err)
+	}
+	got, err = c.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(tr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Couldn't get the TaskRun: %v", err)
+	}
+	if got.Status.StartTime == nil || got.Status.PodName == "" {
+		t.Errorf("Expected the TaskRun to start once its quota frees up, got start time %v and pod %q", got.Status.StartTime, got.Status.PodName)
+	}
+}
+
+// TestReconcileQuotaBurst reconciles TaskRuns created together while the
+// cache doesn't show the ones admitted started, which start in the order
+// they were created.
+func TestReconcileQuotaBurst(t *testing.T) {
+	defer unregisterMetrics()
+	now := time.Date(2019, time.November, 16, 10, 30, 0, 0, time.UTC)
+	var trs []*v1alpha1.TaskRun
+	for _, name := range []string{"test-taskrun-c", "test-taskrun-b", "test-taskrun-a"} {
+		trs = append(trs, tb.TaskRun(name, "foo", tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name))))
+	}
+	ctx, _ := ttesting.SetupFakeContext(t)
+	ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	entrypointCache, _ = entrypoint.NewCache()
+	c, _ := test.SeedTestData(t, ctx, test.Data{
+		TaskRuns:       trs,
+		Tasks:          []*v1alpha1.Task{simpleTask},
+		PipelineQuotas: []*v1alpha1.PipelineQuota{tb.PipelineQuota("quota", "foo", tb.PipelineQuotaMaxConcurrentRuns(2))},
+	})
+	if _, err := c.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	q := ttesting.NewFakeQueue(clock.NewFakeClock(now))
+	impl := NewController(images, reconciler.WithClock(q.Clock))(ctx, configmap.NewInformedWatcher(c.Kube, system.GetNamespace()))
+	r := impl.Reconciler.(*Reconciler)
+	r.enqueueAfter = q.EnqueueAfter
+
+	var started []string
+	for _, run := range trs {
+		if err := r.Reconcile(context.Background(), getRunName(run)); err != nil {
+			t.Fatalf("Unexpected error reconciling the TaskRun %q: %v", run.Name, err)
+		}
+		got, err := c.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(run.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Couldn't get the TaskRun: %v", err)
+		}
+		if got.Status.StartTime != nil {
+			started = append(started, got.Name)
+		}
+	}
+	if d := cmp.Diff([]string{"test-taskrun-b", "test-taskrun-a"}, started); d != "" {
+		t.Errorf("Started TaskRuns -want, +got: %s", d)
+	}
+}
+
+func TestReconcileRunIdentityLabels(t *testing.T) {
+	tr := tb.TaskRun("test-taskrun-identity", "foo",
+		tb.TaskRunLabel("tekton.dev/pipeline", "release"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)),
+	)
+	tr.UID = "5f3c1a2e"
+	testAssets, cancel := getTaskRunController(t, test.Data{
+		TaskRuns: []*v1alpha1.TaskRun{tr},
+		Tasks:    []*v1alpha1.Task{simpleTask},
+	})
+	defer cancel()
+	clients := testAssets.Clients
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	testAssets.Controller.Reconciler.(*Reconciler).configStore = cfgtesting.StaticConfigStore{Config: &config.Config{
+		Defaults: &config.Defaults{
+			DefaultTimeoutMinutes: 60,
+			ClusterName:           "ci-east",
+		},
+	}}
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(tr)); err != nil {
+		t.Fatalf("Unexpected error reconciling: %v", err)
+	}
+	reconciled, err := clients.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(tr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod, err := clients.Kube.CoreV1().Pods("foo").Get(reconciled.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the pod of the TaskRun to be created: %v", err)
+	}
+	for k, v := range map[string]string{
+		"tekton.dev/pipeline":   "release",
+		"tekton.dev/cluster":    "ci-east",
+		"tekton.dev/runUID":     "5f3c1a2e",
+		"tekton.dev/runAttempt": "0",
+	} {
+		if got := pod.Labels[k]; got != v {
+			t.Errorf("Expected the pod to be labeled with %s=%s, got %q", k, v, got)
+		}
+	}
+}
+
+func TestReconcileImagePolicy(t *testing.T) {
+	task := tb.Task("test-task-image", "foo", tb.TaskSpec(
+		tb.TaskInputs(tb.InputsParamSpec("image", v1alpha1.ParamTypeString)),
+		tb.Step("build", "$(inputs.params.image)"),
+	))
+	tr := tb.TaskRun("test-taskrun-image", "foo", tb.TaskRunSpec(
+		tb.TaskRunTaskRef(task.Name),
+		tb.TaskRunInputs(tb.TaskRunInputsParam("image", "gcr.io/build/builder:v1")),
+	))
+	testAssets, cancel := getTaskRunController(t, test.Data{
+		TaskRuns: []*v1alpha1.TaskRun{tr},
+		Tasks:    []*v1alpha1.Task{task},
+	})
+	defer cancel()
+	clients := testAssets.Clients
+
+	testAssets.Controller.Reconciler.(*Reconciler).configStore = cfgtesting.StaticConfigStore{Config: &config.Config{
+		Defaults: &config.Defaults{
+			DefaultTimeoutMinutes: 60,
+			RequireImageDigests:   true,
+		},
+	}}
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(tr)); err != nil {
+		t.Fatalf("Unexpected error reconciling: %v", err)
+	}
+	reconciled, err := clients.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(tr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	condition := reconciled.Status.GetCondition(apis.ConditionSucceeded)
+	if condition == nil || condition.Status != corev1.ConditionFalse || condition.Reason != status.ReasonFailedValidation {
+		t.Errorf("Expected the TaskRun to fail validation, got %v", condition)
+	}
+	if !strings.Contains(condition.Message, `image "gcr.io/build/builder:v1" isn't pinned to a digest`) {
+		t.Errorf("Expected the message to name the image, got %q", condition.Message)
+	}
+	if reconciled.Status.PodName != "" {
+		t.Errorf("Expected no pod to be created, got %q", reconciled.Status.PodName)
+	}
+}
+
+func TestReconcileFiles(t *testing.T) {
+	tr := tb.TaskRun("test-taskrun-files", "foo",
+		tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)),
+	)
+	tr.Spec.Files = []v1alpha1.InlineFile{{Name: "settings.xml", Content: "<settings/>"}}
+	testAssets, cancel := getTaskRunController(t, test.Data{
+		TaskRuns: []*v1alpha1.TaskRun{tr},
+		Tasks:    []*v1alpha1.Task{simpleTask},
+	})
+	defer cancel()
+	clients := testAssets.Clients
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(tr)); err != nil {
+		t.Fatalf("Unexpected error reconciling: %v", err)
+	}
+	cm, err := clients.Kube.CoreV1().ConfigMaps("foo").Get("test-taskrun-files-files", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the ConfigMap of the files to be created: %v", err)
+	}
+	if d := cmp.Diff(map[string]string{"settings.xml": "<settings/>"}, cm.Data); d != "" {
+		t.Errorf("ConfigMap data (-want, +got): %s", d)
+	}
+	if owner := metav1.GetControllerOf(cm); owner == nil || owner.Kind != "TaskRun" || owner.Name != tr.Name {
+		t.Errorf("Expected the ConfigMap to be owned by the TaskRun, got %v", cm.OwnerReferences)
+	}
+	reconciled, err := clients.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(tr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reconciled.Status.PodName == "" {
+		t.Error("Expected the pod of the TaskRun to be created")
+	}
+}
+
+func TestReconcileFiles_NotOwnedConfigMap(t *testing.T) {
+	tr := tb.TaskRun("test-taskrun-files", "foo",
+		tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)),
+	)
+	tr.Spec.Files = []v1alpha1.InlineFile{{Name: "settings.xml", Content: "<settings/>"}}
+	// The ConfigMap of the same name was created by someone else.
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun-files-files", Namespace: "foo"},
+		Data:       map[string]string{"settings.xml": "<settings><mirrors/></settings>"},
+	}
+	testAssets, cancel := getTaskRunController(t, test.Data{
+		TaskRuns:   []*v1alpha1.TaskRun{tr},
+		Tasks:      []*v1alpha1.Task{simpleTask},
+		ConfigMaps: []*corev1.ConfigMap{cm},
+	})
+	defer cancel()
+	clients := testAssets.Clients
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(tr)); err != nil {
+		t.Fatalf("Unexpected error reconciling: %v", err)
+	}
+	reconciled, err := clients.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(tr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	condition := reconciled.Status.GetCondition(apis.ConditionSucceeded)
+	if condition == nil || condition.Status != corev1.ConditionFalse || condition.Reason != status.ReasonFilesConfigMapConflict {
+		t.Errorf("Expected the TaskRun to fail with reason %s, got %v", status.ReasonFilesConfigMapConflict, condition)
+	}
+	if reconciled.Status.PodName != "" {
+		t.Errorf("Expected no pod to be created, got %s", reconciled.Status.PodName)
+	}
+}
+
+func TestReconcileCapabilities(t *testing.T) {
+	gpuTask := tb.Task("test-task-gpu", "foo", tb.TaskSpec(simpleStep))
+	gpuTask.Spec.Requires = []string{"gpu"}
+	gpuNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-node", Labels: map[string]string{"accelerator": "nvidia-tesla-t4"}},
+		Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "nvidia.com/gpu", Effect: corev1.TaintEffectNoSchedule}}},
+	}
+	capabilities := &config.Capabilities{Capabilities: map[string]config.Capability{
+		"gpu": {
+			NodeSelector: map[string]string{"accelerator": "nvidia-tesla-t4"},
+			Tolerations:  []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}},
+			CheckNodes:   true,
+		},
+	}}
+	// The nodes of the capabilities autoscaled from zero aren't checked.
+	autoscaled := &config.Capabilities{Capabilities: map[string]config.Capability{
+		"gpu": {NodeSelector: map[string]string{"accelerator": "nvidia-tesla-t4"}},
+	}}
+
+	for _, tc := range []struct {
+		name         string
+		capabilities *config.Capabilities
+		nodes        []*corev1.Node
+		wantReason   string
+	}{{
+		name:         "satisfiable",
+		capabilities: capabilities,
+		nodes:        []*corev1.Node{gpuNode},
+	}, {
+		name:         "not configured",
+		capabilities: &config.Capabilities{},
+		nodes:        []*corev1.Node{gpuNode},
+		wantReason:   status.ReasonCapabilitiesUnsatisfiable,
+	}, {
+		name:         "no node",
+		capabilities: capabilities,
+		nodes: []*corev1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: map[string]string{"accelerator": "nvidia-tesla-t4"}},
+			Spec:       corev1.NodeSpec{Unschedulable: true},
+		}},
+		wantReason: status.ReasonCapabilitiesUnsatisfiable,
+	}, {
+		name:         "no node autoscaled",
+		capabilities: autoscaled,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := tb.TaskRun("test-taskrun-gpu", "foo",
+				tb.TaskRunSpec(tb.TaskRunTaskRef(gpuTask.Name)),
+			)
+			testAssets, cancel := getTaskRunController(t, test.Data{
+				TaskRuns: []*v1alpha1.TaskRun{tr},
+				Tasks:    []*v1alpha1.Task{gpuTask},
+				Nodes:    tc.nodes,
+			})
+			defer cancel()
+			clients := testAssets.Clients
+			if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			testAssets.Controller.Reconciler.(*Reconciler).configStore = cfgtesting.StaticConfigStore{Config: &config.Config{
+				Defaults:     &config.Defaults{DefaultTimeoutMinutes: 60},
+				Capabilities: tc.capabilities,
+			}}
+			if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(tr)); err != nil {
+				t.Fatalf("Unexpected error reconciling: %v", err)
+			}
+			reconciled, err := clients.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(tr.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.wantReason != "" {
+				condition := reconciled.Status.GetCondition(apis.ConditionSucceeded)
+				if condition == nil || condition.Status != corev1.ConditionFalse || condition.Reason != tc.wantReason {
+					t.Errorf("Expected the TaskRun to fail with reason %s, got %v", tc.wantReason, condition)
+				}
+				if reconciled.Status.PodName != "" {
+					t.Errorf("Expected no pod to be created, got %s", reconciled.Status.PodName)
+				}
+				return
+			}
+			pod, err := clients.Kube.CoreV1().Pods("foo").Get(reconciled.Status.PodName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected the pod of the TaskRun to be created: %v", err)
+			}
+			if got := pod.Annotations[resources.CapabilitiesAnnotation]; got != "gpu" {
+				t.Errorf("Expected the pod to be annotated with its capabilities, got %q", got)
+			}
+			if got := pod.Spec.NodeSelector["accelerator"]; got != "nvidia-tesla-t4" {
+				t.Errorf("Expected the pod to select the GPU nodes, got %v", pod.Spec.NodeSelector)
+			}
+		})
+	}
+}
+
+func TestReconcileDefaultResourceRequirements(t *testing.T) {
+	tr := tb.TaskRun("test-taskrun-requirements", "foo",
+		tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)),
+	)
+	testAssets, cancel := getTaskRunController(t, test.Data{
+		TaskRuns: []*v1alpha1.TaskRun{tr},
+		Tasks:    []*v1alpha1.Task{simpleTask},
+	})
+	defer cancel()
+	clients := testAssets.Clients
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	testAssets.Controller.Reconciler.(*Reconciler).configStore = cfgtesting.StaticConfigStore{Config: &config.Config{
+		Defaults: &config.Defaults{
+			DefaultTimeoutMinutes: 60,
+			DefaultContainerResourceRequirements: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+			},
+			NamespaceContainerResourceRequirements: map[string]corev1.ResourceRequirements{
+				"foo": {Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}},
+			},
+		},
+	}}
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(tr)); err != nil {
+		t.Fatalf("Unexpected error reconciling: %v", err)
+	}
+	reconciled, err := clients.Pipeline.TektonV1alpha1().TaskRuns("foo").Get(tr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod, err := clients.Kube.CoreV1().Pods("foo").Get(reconciled.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the pod of the TaskRun to be created: %v", err)
+	}
+	// The requests of the steps run one after the other are those of the
+	// step requesting the most.
+	var requested resource.Quantity
+	for _, c := range pod.Spec.Containers {
+		if q := c.Resources.Requests[corev1.ResourceCPU]; q.Cmp(requested) > 0 {
+			requested = q
+		}
+	}
+	if requested.Cmp(resource.MustParse("2")) != 0 {
+		t.Errorf("Expected the steps to request the CPU of their namespace, got %s", requested.String())
+	}
+}
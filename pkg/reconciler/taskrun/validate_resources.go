@@ -17,6 +17,10 @@ limitations under the License.
 package taskrun
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/list"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
@@ -65,12 +69,12 @@ func validateResources(requiredResources []v1alpha1.TaskResource, providedResour
 
 func validateParams(inputs *v1alpha1.Inputs, params []v1alpha1.Param) error {
 	var neededParams []string
-	paramTypes := make(map[string]v1alpha1.ParamType)
+	paramSpecs := make(map[string]v1alpha1.ParamSpec)
 	if inputs != nil {
 		neededParams = make([]string, 0, len(inputs.Params))
 		for _, inputResourceParam := range inputs.Params {
 			neededParams = append(neededParams, inputResourceParam.Name)
-			paramTypes[inputResourceParam.Name] = inputResourceParam.Type
+			paramSpecs[inputResourceParam.Name] = inputResourceParam
 		}
 	}
 	providedParams := make([]string, 0, len(params))
@@ -98,7 +102,14 @@ func validateParams(inputs *v1alpha1.Inputs, params []v1alpha1.Param) error {
 	// the user-specified type.
 	var wrongTypeParamNames []string
 	for _, param := range params {
-		if param.Value.Type != paramTypes[param.Name] {
+		// The values read from Secrets are strings.
+		if param.ValueFrom != nil {
+			if paramSpecs[param.Name].Type != v1alpha1.ParamTypeString {
+				wrongTypeParamNames = append(wrongTypeParamNames, param.Name)
+			}
+			continue
+		}
+		if param.Value.Type != paramSpecs[param.Name].Type {
 			wrongTypeParamNames = append(wrongTypeParamNames, param.Name)
 		}
 	}
@@ -106,6 +117,59 @@ func validateParams(inputs *v1alpha1.Inputs, params []v1alpha1.Param) error {
 		return xerrors.Errorf("param types don't match the user-specified type: %s", wrongTypeParamNames)
 	}
 
+	// Object params must provide every key their spec declares.
+	for _, param := range params {
+		if param.Value.Type != v1alpha1.ParamTypeObject {
+			continue
+		}
+		if missing := v1alpha1.MissingObjectKeys(paramSpecs[param.Name], param.Value.ObjectVal); len(missing) != 0 {
+			return xerrors.Errorf("missing keys for object param %q: %s", param.Name, missing)
+		}
+	}
+
+	if inputs != nil {
+		if err := v1alpha1.ValidateParamValues(inputs.Params, params, "spec.inputs.params"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateSecretParams checks that the params read from Secrets are only used
+// in the command, args and env of the steps, the fields the kubelet expands
+// their environment variables in.
+func validateSecretParams(ts *v1alpha1.TaskSpec, params []v1alpha1.Param) error {
+	var names []string
+	for _, p := range params {
+		if p.ValueFrom != nil {
+			names = append(names, p.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	rest := ts.DeepCopy()
+	for i := range rest.Steps {
+		rest.Steps[i].Command = nil
+		rest.Steps[i].Args = nil
+		for j := range rest.Steps[i].Env {
+			rest.Steps[i].Env[j].Value = ""
+		}
+	}
+	b, err := json.Marshal(rest)
+	if err != nil {
+		return err
+	}
+	var misused []string
+	for _, name := range names {
+		if bytes.Contains(b, []byte(fmt.Sprintf("$(inputs.params.%s)", name))) {
+			misused = append(misused, name)
+		}
+	}
+	if len(misused) != 0 {
+		return xerrors.Errorf("params read from Secrets can only be used in the command, args and env of the steps: %s", misused)
+	}
 	return nil
 }
 
@@ -114,6 +178,9 @@ func ValidateResolvedTaskResources(params []v1alpha1.Param, rtr *resources.Resol
 	if err := validateParams(rtr.TaskSpec.Inputs, params); err != nil {
 		return xerrors.Errorf("invalid input params: %w", err)
 	}
+	if err := validateSecretParams(rtr.TaskSpec, params); err != nil {
+		return xerrors.Errorf("invalid input params: %w", err)
+	}
 	if err := validateInputResources(rtr.TaskSpec.Inputs, rtr.Inputs); err != nil {
 		return xerrors.Errorf("invalid input resources: %w", err)
 	}
@@ -23,6 +23,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestValidateResolvedTaskResources_ValidResources(t *testing.T) {
@@ -61,6 +62,38 @@ func TestValidateResolvedTaskResources_ValidParams(t *testing.T) {
 	}
 }
 
+func TestValidateResolvedTaskResources_ValidSecretParams(t *testing.T) {
+	rtr := tb.ResolvedTaskResources(tb.ResolvedTaskResourcesTaskSpec(
+		tb.Step("mystep", "myimage", tb.StepCommand("login"), tb.StepArgs("--token", "$(inputs.params.token)"),
+			tb.StepEnvVar("HEADER", "Bearer $(inputs.params.token)")),
+		tb.TaskInputs(tb.InputsParamSpec("token", v1alpha1.ParamTypeString)),
+	))
+	p := []v1alpha1.Param{{
+		Name: "token",
+		ValueFrom: &v1alpha1.ParamValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "registry"},
+			Key:                  "token",
+		}},
+	}}
+	if err := taskrun.ValidateResolvedTaskResources(p, rtr); err != nil {
+		t.Fatalf("Did not expect to see error when validating TaskRun with a param read from a Secret but saw %v", err)
+	}
+}
+
+func TestValidateResolvedTaskResources_ValidObjectParams(t *testing.T) {
+	rtr := tb.ResolvedTaskResources(tb.ResolvedTaskResourcesTaskSpec(
+		tb.Step("mystep", "myimage", tb.StepCommand("mycmd")),
+		tb.TaskInputs(tb.InputsParamSpec("repo", v1alpha1.ParamTypeObject, tb.ParamSpecProperties("url", "revision"))),
+	))
+	p := []v1alpha1.Param{{
+		Name:  "repo",
+		Value: *tb.ObjectValue(map[string]string{"url": "https://github.com/tektoncd/pipeline", "revision": "master", "extra": "ignored"}),
+	}}
+	if err := taskrun.ValidateResolvedTaskResources(p, rtr); err != nil {
+		t.Fatalf("Did not expect to see error when validating TaskRun with correct object params but saw %v", err)
+	}
+}
+
 func TestValidateResolvedTaskResources_InvalidParams(t *testing.T) {
 	tcs := []struct {
 		name   string
@@ -89,6 +122,50 @@ func TestValidateResolvedTaskResources_InvalidParams(t *testing.T) {
 			Name:  "extra",
 			Value: *tb.ArrayOrString("i am an extra param"),
 		}},
+	}, {
+		name: "object-param-missing-keys",
+		rtr: tb.ResolvedTaskResources(tb.ResolvedTaskResourcesTaskSpec(
+			tb.Step("mystep", "myimage", tb.StepCommand("mycmd")),
+			tb.TaskInputs(tb.InputsParamSpec("repo", v1alpha1.ParamTypeObject, tb.ParamSpecProperties("url", "revision"))),
+		)),
+		params: []v1alpha1.Param{{
+			Name:  "repo",
+			Value: *tb.ObjectValue(map[string]string{"url": "https://github.com/tektoncd/pipeline"}),
+		}},
+	}, {
+		name: "param-not-in-enum",
+		rtr: tb.ResolvedTaskResources(tb.ResolvedTaskResourcesTaskSpec(
+			tb.Step("mystep", "myimage", tb.StepCommand("mycmd")),
+			tb.TaskInputs(tb.InputsParamSpec("env", v1alpha1.ParamTypeString, tb.ParamSpecEnum("dev", "prod"))),
+		)),
+		params: []v1alpha1.Param{{
+			Name:  "env",
+			Value: *tb.ArrayOrString("staging"),
+		}},
+	}, {
+		name: "param-not-matching-pattern",
+		rtr: tb.ResolvedTaskResources(tb.ResolvedTaskResourcesTaskSpec(
+			tb.Step("mystep", "myimage", tb.StepCommand("mycmd")),
+			tb.TaskInputs(tb.InputsParamSpec("tags", v1alpha1.ParamTypeArray, tb.ParamSpecPattern("^v[0-9]+$"))),
+		)),
+		params: []v1alpha1.Param{{
+			Name:  "tags",
+			Value: *tb.ArrayOrString("v1", "latest"),
+		}},
+	}, {
+		name: "secret-param-in-image",
+		rtr: tb.ResolvedTaskResources(tb.ResolvedTaskResourcesTaskSpec(
+			tb.Step("mystep", "registry/$(inputs.params.token)", tb.StepArgs("$(inputs.params.token)")),
+			tb.TaskInputs(tb.InputsParamSpec("token", v1alpha1.ParamTypeString)),
+		)),
+		params: []v1alpha1.Param{{
+			Name:  "token",
+			Value: v1alpha1.ArrayOrString{Type: v1alpha1.ParamTypeString},
+			ValueFrom: &v1alpha1.ParamValueSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "registry"},
+				Key:                  "token",
+			}},
+		}},
 	}}
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// FakeQueue records the objects a reconciler enqueues with a delay as due
+// at the time of Clock plus that delay, so that tests can travel in time to
+// when they are due instead of sleeping.
+type FakeQueue struct {
+	Clock *clock.FakeClock
+
+	items []delayedItem
+}
+
+type delayedItem struct {
+	obj interface{}
+	at  time.Time
+}
+
+// NewFakeQueue returns an empty FakeQueue telling the time with c.
+func NewFakeQueue(c *clock.FakeClock) *FakeQueue {
+	return &FakeQueue{Clock: c}
+}
+
+// EnqueueAfter records obj as due after the given delay. It can replace
+// controller.Impl.EnqueueAfter.
+func (q *FakeQueue) EnqueueAfter(obj interface{}, after time.Duration) {
+	q.items = append(q.items, delayedItem{obj: obj, at: q.Clock.Now().Add(after)})
+	sort.SliceStable(q.items, func(i, j int) bool { return q.items[i].at.Before(q.items[j].at) })
+}
+
+// Len returns the number of objects waiting in the queue.
+func (q *FakeQueue) Len() int {
+	return len(q.items)
+}
+
+// NextAt returns the time at which the next object is due, or false if the
+// queue is empty.
+func (q *FakeQueue) NextAt() (time.Time, bool) {
+	if len(q.items) == 0 {
+		return time.Time{}, false
+	}
+	return q.items[0].at, true
+}
+
+// Travel sets the clock to t, and removes from the queue and returns the
+// objects due by then.
+func (q *FakeQueue) Travel(t time.Time) []interface{} {
+	q.Clock.SetTime(t)
+	var due []interface{}
+	for len(q.items) > 0 && !q.items[0].at.After(t) {
+		due = append(due, q.items[0].obj)
+		q.items = q.items[1:]
+	}
+	return due
+}
+
+// TravelToNext sets the clock to the time at which the next object is due,
+// and removes from the queue and returns the objects due by then. It
+// returns nil if the queue is empty.
+func (q *FakeQueue) TravelToNext() []interface{} {
+	at, ok := q.NextAt()
+	if !ok {
+		return nil
+	}
+	return q.Travel(at)
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// ObjectRecorder is a record.FakeRecorder which also records the objects
+// the events are recorded on, in order.
+type ObjectRecorder struct {
+	*record.FakeRecorder
+	Objects []runtime.Object
+}
+
+// NewObjectRecorder returns an ObjectRecorder buffering up to bufferSize
+// events.
+func NewObjectRecorder(bufferSize int) *ObjectRecorder {
+	return &ObjectRecorder{FakeRecorder: record.NewFakeRecorder(bufferSize)}
+}
+
+// Event records eventtype, reason and message for object.
+func (r *ObjectRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.Objects = append(r.Objects, object)
+	r.FakeRecorder.Event(object, eventtype, reason, message)
+}
+
+// Eventf records eventtype, reason and the formatted message for object.
+func (r *ObjectRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Objects = append(r.Objects, object)
+	r.FakeRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// VolumeName is the name of the in-memory volume holding the secrets
+	// referenced by the steps.
+	VolumeName = "tekton-secrets"
+	// MountPoint is the directory the secrets volume is mounted at.
+	MountPoint = "/builder/secrets"
+)
+
+// Provider fetches the secrets referenced by steps from an external secrets
+// store, from within the pod running the TaskRun.
+type Provider interface {
+	// Containers returns the init container which writes the secrets
+	// referenced by refs to files in the volume mounted by mount. If the
+	// provider leases secrets, it also returns a sidecar which keeps the
+	// leases alive while the steps run.
+	Containers(refs []v1alpha1.SecretRef, mount corev1.VolumeMount) (corev1.Container, *corev1.Container, error)
+	// Volumes returns the volumes mounted by the containers of the provider,
+	// besides the secrets volume. They are never mounted in the steps.
+	Volumes() []corev1.Volume
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{}
+)
+
+// Register makes a provider available to the steps under name. It is meant
+// to be called when the controller starts.
+func Register(name string, p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[name] = p
+}
+
+// Unregister removes the provider registered under name.
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(providers, name)
+}
+
+// Get returns the provider registered under name.
+func Get(name string) (Provider, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[name]
+	if !ok {
+		return nil, xerrors.Errorf("secrets provider %q is not configured", name)
+	}
+	return p, nil
+}
+
+// Volume returns the in-memory volume the secrets are written to, so that
+// they never reach the disk of the node.
+func Volume() corev1.Volume {
+	return corev1.Volume{
+		Name: VolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+		},
+	}
+}
+
+// VolumeMount returns the mount of the secrets volume.
+func VolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{Name: VolumeName, MountPath: MountPoint}
+}
+
+// ContainersForSteps returns the containers which fetch the secrets
+// referenced by steps, grouped by provider and ordered by provider name,
+// along with the volumes they mount.
+func ContainersForSteps(steps []v1alpha1.Step) ([]corev1.Container, []corev1.Container, []corev1.Volume, error) {
+	refs := map[string][]v1alpha1.SecretRef{}
+	for _, s := range steps {
+		for _, ref := range s.SecretRefs {
+			refs[ref.Provider] = append(refs[ref.Provider], ref)
+		}
+	}
+	var names []string
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var initContainers, sidecars []corev1.Container
+	var volumes []corev1.Volume
+	for _, name := range names {
+		p, err := Get(name)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		init, sidecar, err := p.Containers(refs[name], VolumeMount())
+		if err != nil {
+			return nil, nil, nil, xerrors.Errorf("couldn't configure secrets provider %q: %w", name, err)
+		}
+		initContainers = append(initContainers, init)
+		if sidecar != nil {
+			sidecars = append(sidecars, *sidecar)
+		}
+		volumes = append(volumes, p.Volumes()...)
+	}
+	return initContainers, sidecars, volumes, nil
+}
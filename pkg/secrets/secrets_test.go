@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type fakeProvider struct {
+	name    string
+	leasing bool
+}
+
+func (p *fakeProvider) Containers(refs []v1alpha1.SecretRef, mount corev1.VolumeMount) (corev1.Container, *corev1.Container, error) {
+	var args []string
+	for _, ref := range refs {
+		args = append(args, ref.Path+"="+ref.Key)
+	}
+	init := corev1.Container{Name: p.name, Args: args, VolumeMounts: []corev1.VolumeMount{mount}}
+	if !p.leasing {
+		return init, nil, nil
+	}
+	return init, &corev1.Container{Name: p.name + "-renew"}, nil
+}
+
+func (p *fakeProvider) Volumes() []corev1.Volume {
+	if !p.leasing {
+		return nil
+	}
+	return []corev1.Volume{{Name: p.name + "-leases"}}
+}
+
+func TestContainersForSteps(t *testing.T) {
+	Register("static", &fakeProvider{name: "static"})
+	Register("leasing", &fakeProvider{name: "leasing", leasing: true})
+	defer Unregister("static")
+	defer Unregister("leasing")
+
+	steps := []v1alpha1.Step{{
+		SecretRefs: []v1alpha1.SecretRef{
+			{Provider: "static", Key: "a", Path: "a"},
+			{Provider: "leasing", Key: "b", Path: "b"},
+		},
+	}, {}, {
+		SecretRefs: []v1alpha1.SecretRef{{Provider: "static", Key: "c", Path: "c"}},
+	}}
+	initContainers, sidecars, volumes, err := ContainersForSteps(steps)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantInit := []corev1.Container{{
+		Name:         "leasing",
+		Args:         []string{"b=b"},
+		VolumeMounts: []corev1.VolumeMount{VolumeMount()},
+	}, {
+		Name:         "static",
+		Args:         []string{"a=a", "c=c"},
+		VolumeMounts: []corev1.VolumeMount{VolumeMount()},
+	}}
+	if d := cmp.Diff(wantInit, initContainers); d != "" {
+		t.Errorf("init containers diff -want, +got: %v", d)
+	}
+	if d := cmp.Diff([]corev1.Container{{Name: "leasing-renew"}}, sidecars); d != "" {
+		t.Errorf("sidecars diff -want, +got: %v", d)
+	}
+	if d := cmp.Diff([]corev1.Volume{{Name: "leasing-leases"}}, volumes); d != "" {
+		t.Errorf("volumes diff -want, +got: %v", d)
+	}
+}
+
+func TestContainersForStepsUnknownProvider(t *testing.T) {
+	steps := []v1alpha1.Step{{
+		SecretRefs: []v1alpha1.SecretRef{{Provider: "unknown", Key: "a", Path: "a"}},
+	}}
+	if _, _, _, err := ContainersForSteps(steps); err == nil {
+		t.Error("Expected an error for a provider which isn't registered")
+	}
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// leasesFile is where the init container leaves the Vault token and the
+// leases of the secrets, for the sidecar to renew them.
+const leasesFile = ".vault-leases.json"
+
+// Client is a minimal client of the Vault HTTP API.
+type Client struct {
+	// Address is the URL of the Vault server.
+	Address string
+	// Token is the Vault token sent with the requests, set by Login.
+	Token string
+	// HTTPClient is used to send the requests.
+	HTTPClient *http.Client
+}
+
+// Secret is the part of a Vault response we use.
+type Secret struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+// Leases holds what the sidecar needs to keep the secrets alive.
+type Leases struct {
+	Token          string   `json:"token"`
+	TokenRenewable bool     `json:"tokenRenewable"`
+	TTLSeconds     int      `json:"ttlSeconds"`
+	LeaseIDs       []string `json:"leaseIDs,omitempty"`
+}
+
+// Login logs into Vault with the Kubernetes auth method mounted at authPath
+// and sets the token of the client.
+func (c *Client) Login(authPath, role, jwt string) (*Secret, error) {
+	body := map[string]string{"role": role, "jwt": jwt}
+	s, err := c.do(http.MethodPost, fmt.Sprintf("auth/%s/login", strings.Trim(authPath, "/")), body)
+	if err != nil {
+		return nil, err
+	}
+	if s.Auth == nil || s.Auth.ClientToken == "" {
+		return nil, xerrors.New("vault login response doesn't contain a token")
+	}
+	c.Token = s.Auth.ClientToken
+	return s, nil
+}
+
+// Read reads the secret at path.
+func (c *Client) Read(path string) (*Secret, error) {
+	return c.do(http.MethodGet, path, nil)
+}
+
+// RenewSelf renews the token of the client.
+func (c *Client) RenewSelf() (*Secret, error) {
+	return c.do(http.MethodPost, "auth/token/renew-self", map[string]string{})
+}
+
+// RenewLease renews the lease of a secret.
+func (c *Client) RenewLease(leaseID string) (*Secret, error) {
+	return c.do(http.MethodPut, "sys/leases/renew", map[string]string{"lease_id": leaseID})
+}
+
+func (c *Client) do(method, path string, body interface{}) (*Secret, error) {
+	var r *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(b)
+	} else {
+		r = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(c.Address, "/"), path), r)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Vault-Token", c.Token)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("vault request %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, xerrors.Errorf("vault request %s %s failed with status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	s := &Secret{}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, s); err != nil {
+			return nil, xerrors.Errorf("couldn't parse vault response to %s %s: %w", method, path, err)
+		}
+	}
+	return s, nil
+}
+
+// Field returns the field of the secret, looking into the nested data of
+// secrets of the version 2 of the KV secrets engine.
+func (s *Secret) Field(name string) (string, error) {
+	data := s.Data
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+	v, ok := data[name]
+	if !ok {
+		return "", xerrors.Errorf("secret has no field %q", name)
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	default:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+}
+
+// Fetch reads the secrets, whose files and keys are given as
+// <file>=<path>#<field>, writes them to their file in dir and records the
+// leases to renew in leasesDir.
+func Fetch(c *Client, loginAuth *Secret, secretArgs []string, dir, leasesDir string) error {
+	leases := Leases{
+		Token:          c.Token,
+		TokenRenewable: loginAuth.Auth.Renewable,
+		TTLSeconds:     loginAuth.Auth.LeaseDuration,
+	}
+	// The same secret is only read once, so that all its fields come from
+	// the same lease.
+	read := map[string]*Secret{}
+	for _, arg := range secretArgs {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return xerrors.Errorf("invalid secret %q, expected <file>=<path>#<field>", arg)
+		}
+		file := parts[0]
+		path, field, err := ParseKey(parts[1])
+		if err != nil {
+			return err
+		}
+		s, ok := read[path]
+		if !ok {
+			if s, err = c.Read(path); err != nil {
+				return err
+			}
+			read[path] = s
+			if s.LeaseID != "" && s.Renewable {
+				leases.LeaseIDs = append(leases.LeaseIDs, s.LeaseID)
+				leases.TTLSeconds = shortest(leases.TTLSeconds, s.LeaseDuration)
+			}
+		}
+		v, err := s.Field(field)
+		if err != nil {
+			return xerrors.Errorf("couldn't get %s: %w", parts[1], err)
+		}
+		// Steps may not run as the same user as this container.
+		if err := writeFile(filepath.Join(dir, file), []byte(v), 0444); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(leases)
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(leasesDir, leasesFile), b, 0400)
+}
+
+// ReadLeases reads the leases recorded by Fetch in dir.
+func ReadLeases(dir string) (*Leases, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, leasesFile))
+	if err != nil {
+		return nil, err
+	}
+	leases := &Leases{}
+	if err := json.Unmarshal(b, leases); err != nil {
+		return nil, err
+	}
+	return leases, nil
+}
+
+// Renew renews the token and the leases once, and returns how long to wait
+// before renewing them again.
+func Renew(c *Client, leases *Leases) (time.Duration, error) {
+	ttl := leases.TTLSeconds
+	if leases.TokenRenewable {
+		s, err := c.RenewSelf()
+		if err != nil {
+			return 0, err
+		}
+		if s.Auth != nil {
+			ttl = shortest(ttl, s.Auth.LeaseDuration)
+		}
+	}
+	for _, id := range leases.LeaseIDs {
+		s, err := c.RenewLease(id)
+		if err != nil {
+			return 0, err
+		}
+		ttl = shortest(ttl, s.LeaseDuration)
+	}
+	// Renew halfway through the shortest lease.
+	return time.Duration(ttl) * time.Second / 2, nil
+}
+
+// shortest returns the shortest of two lease durations, 0 meaning the lease
+// doesn't expire.
+func shortest(a, b int) int {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+func writeFile(path string, content []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, perm)
+}
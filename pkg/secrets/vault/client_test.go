@@ -0,0 +1,179 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeVault serves the few Vault endpoints the client uses.
+func fakeVault(t *testing.T, renewed *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/auth/kubernetes/login" && r.Header.Get("X-Vault-Token") != "s.token" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		var resp interface{}
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["jwt"] != "jwt" || body["role"] != "ci" {
+				http.Error(w, `{"errors":["invalid role or jwt"]}`, http.StatusBadRequest)
+				return
+			}
+			resp = map[string]interface{}{"auth": map[string]interface{}{"client_token": "s.token", "lease_duration": 3600, "renewable": true}}
+		case "/v1/secret/data/ci":
+			resp = map[string]interface{}{"data": map[string]interface{}{
+				"data":     map[string]interface{}{"token": "hunter2", "user": "ci"},
+				"metadata": map[string]interface{}{"version": 1},
+			}}
+		case "/v1/database/creds/ci":
+			resp = map[string]interface{}{
+				"lease_id":       "database/creds/ci/1234",
+				"lease_duration": 600,
+				"renewable":      true,
+				"data":           map[string]interface{}{"username": "v-ci", "password": "s3cr3t"},
+			}
+		case "/v1/auth/token/renew-self":
+			*renewed = append(*renewed, "token")
+			resp = map[string]interface{}{"auth": map[string]interface{}{"client_token": "s.token", "lease_duration": 3600, "renewable": true}}
+		case "/v1/sys/leases/renew":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			*renewed = append(*renewed, body["lease_id"])
+			resp = map[string]interface{}{"lease_id": body["lease_id"], "lease_duration": 300, "renewable": true}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestFetchAndRenew(t *testing.T) {
+	var renewed []string
+	s := fakeVault(t, &renewed)
+	defer s.Close()
+	dir, err := ioutil.TempDir("", "vault-secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	leasesDir, err := ioutil.TempDir("", "vault-leases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(leasesDir)
+
+	c := &Client{Address: s.URL}
+	auth, err := c.Login("/kubernetes/", "ci", "jwt")
+	if err != nil {
+		t.Fatalf("Unexpected error logging in: %v", err)
+	}
+	if err := Fetch(c, auth, []string{
+		"token=secret/data/ci#token",
+		"db/user=database/creds/ci#username",
+		"db/password=database/creds/ci#password",
+	}, dir, leasesDir); err != nil {
+		t.Fatalf("Unexpected error fetching secrets: %v", err)
+	}
+	for file, want := range map[string]string{
+		"token":       "hunter2",
+		"db/user":     "v-ci",
+		"db/password": "s3cr3t",
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", file, err)
+		}
+		if string(b) != want {
+			t.Errorf("%s = %q, want %q", file, b, want)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, leasesFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected the leases to be kept out of the secrets dir, got %v", err)
+	}
+	leases, err := ReadLeases(leasesDir)
+	if err != nil {
+		t.Fatalf("Unexpected error reading leases: %v", err)
+	}
+	wantLeases := &Leases{
+		Token:          "s.token",
+		TokenRenewable: true,
+		TTLSeconds:     600,
+		LeaseIDs:       []string{"database/creds/ci/1234"},
+	}
+	if d := cmp.Diff(wantLeases, leases); d != "" {
+		t.Errorf("leases diff -want, +got: %v", d)
+	}
+
+	wait, err := Renew(&Client{Address: s.URL, Token: leases.Token}, leases)
+	if err != nil {
+		t.Fatalf("Unexpected error renewing: %v", err)
+	}
+	if wait != 150*time.Second {
+		t.Errorf("Expected to renew again in half the shortest lease, got %v", wait)
+	}
+	if d := cmp.Diff([]string{"token", "database/creds/ci/1234"}, renewed); d != "" {
+		t.Errorf("renewed diff -want, +got: %v", d)
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	var renewed []string
+	s := fakeVault(t, &renewed)
+	defer s.Close()
+	dir, err := ioutil.TempDir("", "vault-secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := (&Client{Address: s.URL}).Login("kubernetes", "other", "jwt"); err == nil {
+		t.Error("Expected an error logging in with an unknown role")
+	}
+	c := &Client{Address: s.URL}
+	auth, err := c.Login("kubernetes", "ci", "jwt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"secret/data/ci#token"},
+		{"token=secret/data/ci#missing"},
+		{"token=secret/data/unknown#token"},
+	} {
+		if err := Fetch(c, auth, args, dir, dir); err == nil {
+			t.Errorf("Expected an error fetching %v", args)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/secrets"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ProviderName is the name secretRefs use to reference Vault secrets.
+	ProviderName = "vault"
+
+	// DefaultAuthPath is the mount path of the Kubernetes auth method in Vault.
+	DefaultAuthPath = "kubernetes"
+
+	initContainerName = "vault-secrets"
+	sidecarName       = "vault-renew"
+
+	// The token of the service account is projected into a volume of its
+	// own, since the service account token secret isn't mounted in pods
+	// using projected tokens.
+	tokenVolumeName = "tekton-vault-token"
+	tokenMountPoint = "/tekton/vault/token"
+	tokenPath       = "token"
+
+	// The Vault token and the leases are kept in a volume which is only
+	// mounted in the containers of the provider, so that the steps can't
+	// read or tamper with them.
+	leasesVolumeName = "tekton-vault-leases"
+	leasesMountPoint = "/tekton/vault/leases"
+)
+
+// Provider fetches secrets from HashiCorp Vault, authenticating with a
+// projected token of the service account of the pod through the Kubernetes
+// auth method.
+type Provider struct {
+	// Image is the container image containing the vault-secrets binary.
+	Image string
+	// Address is the URL of the Vault server.
+	Address string
+	// AuthPath is the mount path of the Kubernetes auth method.
+	AuthPath string
+	// Role is the Vault role to log in with.
+	Role string
+}
+
+var _ secrets.Provider = (*Provider)(nil)
+
+// Containers implements secrets.Provider. The init container logs into
+// Vault and writes the secrets, and the sidecar renews the Vault token and
+// the leases of the secrets until the steps are done, or exits as soon as
+// none of them expires.
+func (p *Provider) Containers(refs []v1alpha1.SecretRef, mount corev1.VolumeMount) (corev1.Container, *corev1.Container, error) {
+	authPath := p.AuthPath
+	if authPath == "" {
+		authPath = DefaultAuthPath
+	}
+	args := []string{
+		"-addr", p.Address,
+		"-auth-path", authPath,
+		"-role", p.Role,
+		"-leases-dir", leasesMountPoint,
+	}
+	var secretArgs []string
+	for _, ref := range refs {
+		if _, _, err := ParseKey(ref.Key); err != nil {
+			return corev1.Container{}, nil, err
+		}
+		secretArgs = append(secretArgs, fmt.Sprintf("%s=%s", ref.Path, ref.Key))
+	}
+
+	leasesMount := corev1.VolumeMount{Name: leasesVolumeName, MountPath: leasesMountPoint}
+	init := corev1.Container{
+		Name:    initContainerName,
+		Image:   p.Image,
+		Command: []string{"/ko-app/vault-secrets"},
+		Args: append(append(append([]string{}, args...),
+			"-jwt-path", filepath.Join(tokenMountPoint, tokenPath),
+			"-dir", mount.MountPath,
+		), secretArgs...),
+		VolumeMounts: []corev1.VolumeMount{
+			mount,
+			leasesMount,
+			{Name: tokenVolumeName, MountPath: tokenMountPoint, ReadOnly: true},
+		},
+	}
+	sidecar := &corev1.Container{
+		Name:         sidecarName,
+		Image:        p.Image,
+		Command:      []string{"/ko-app/vault-secrets"},
+		Args:         append(append([]string{}, args...), "-renew"),
+		VolumeMounts: []corev1.VolumeMount{leasesMount},
+	}
+	return init, sidecar, nil
+}
+
+// Volumes implements secrets.Provider.
+func (p *Provider) Volumes() []corev1.Volume {
+	return []corev1.Volume{{
+		Name: tokenVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{
+					ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Path: tokenPath},
+				}},
+			},
+		},
+	}, {
+		Name: leasesVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+		},
+	}}
+}
+
+// ParseKey splits the key of a Vault secretRef into the path of the secret
+// and the name of its field.
+func ParseKey(key string) (string, string, error) {
+	i := strings.LastIndex(key, "#")
+	if i <= 0 || i == len(key)-1 {
+		return "", "", xerrors.Errorf("invalid vault secret key %q, expected <path>#<field>", key)
+	}
+	return strings.Trim(key[:i], "/"), key[i+1:], nil
+}
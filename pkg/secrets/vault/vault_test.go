@@ -0,0 +1,134 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/secrets"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestContainers(t *testing.T) {
+	p := &Provider{Image: "vault-secrets", Address: "https://vault:8200", Role: "ci"}
+	refs := []v1alpha1.SecretRef{
+		{Provider: ProviderName, Key: "secret/data/ci#token", Path: "token"},
+		{Provider: ProviderName, Key: "database/creds/ci#password", Path: "db/password"},
+	}
+	init, sidecar, err := p.Containers(refs, secrets.VolumeMount())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	wantArgs := []string{
+		"-addr", "https://vault:8200",
+		"-auth-path", DefaultAuthPath,
+		"-role", "ci",
+		"-leases-dir", leasesMountPoint,
+	}
+	wantInitArgs := append(append([]string{}, wantArgs...),
+		"-jwt-path", "/tekton/vault/token/token",
+		"-dir", secrets.MountPoint,
+		"token=secret/data/ci#token",
+		"db/password=database/creds/ci#password",
+	)
+	if d := cmp.Diff(wantInitArgs, init.Args); d != "" {
+		t.Errorf("init container args diff -want, +got: %v", d)
+	}
+	leasesMount := corev1.VolumeMount{Name: leasesVolumeName, MountPath: leasesMountPoint}
+	wantInitMounts := []corev1.VolumeMount{
+		secrets.VolumeMount(),
+		leasesMount,
+		{Name: tokenVolumeName, MountPath: tokenMountPoint, ReadOnly: true},
+	}
+	if d := cmp.Diff(wantInitMounts, init.VolumeMounts); d != "" {
+		t.Errorf("init container volume mounts diff -want, +got: %v", d)
+	}
+	if sidecar == nil {
+		t.Fatal("Expected a sidecar renewing the leases")
+	}
+	if d := cmp.Diff(append(append([]string{}, wantArgs...), "-renew"), sidecar.Args); d != "" {
+		t.Errorf("sidecar args diff -want, +got: %v", d)
+	}
+	// The sidecar only needs the leases, not the secrets.
+	if d := cmp.Diff([]corev1.VolumeMount{leasesMount}, sidecar.VolumeMounts); d != "" {
+		t.Errorf("sidecar volume mounts diff -want, +got: %v", d)
+	}
+
+	if _, _, err := p.Containers([]v1alpha1.SecretRef{{Provider: ProviderName, Key: "secret/data/ci", Path: "token"}}, secrets.VolumeMount()); err == nil {
+		t.Error("Expected an error for a key without a field")
+	}
+}
+
+func TestVolumes(t *testing.T) {
+	var got []string
+	for _, v := range (&Provider{}).Volumes() {
+		switch {
+		case v.Projected != nil:
+			// The token of the service account of the pod, whether or not
+			// it is automounted.
+			if len(v.Projected.Sources) != 1 || v.Projected.Sources[0].ServiceAccountToken == nil {
+				t.Errorf("Expected %s to project the service account token, got %v", v.Name, v.Projected.Sources)
+			}
+		case v.EmptyDir != nil:
+			if v.EmptyDir.Medium != corev1.StorageMediumMemory {
+				t.Errorf("Expected %s to be in memory, got %q", v.Name, v.EmptyDir.Medium)
+			}
+		}
+		got = append(got, v.Name)
+	}
+	if d := cmp.Diff([]string{tokenVolumeName, leasesVolumeName}, got); d != "" {
+		t.Errorf("volumes diff -want, +got: %v", d)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	for _, tc := range []struct {
+		key       string
+		wantPath  string
+		wantField string
+		wantErr   bool
+	}{{
+		key:       "secret/data/ci#token",
+		wantPath:  "secret/data/ci",
+		wantField: "token",
+	}, {
+		key:       "/secret/my#app#token",
+		wantPath:  "secret/my#app",
+		wantField: "token",
+	}, {
+		key:     "secret/data/ci",
+		wantErr: true,
+	}, {
+		key:     "#token",
+		wantErr: true,
+	}, {
+		key:     "secret/data/ci#",
+		wantErr: true,
+	}} {
+		t.Run(tc.key, func(t *testing.T) {
+			path, field, err := ParseKey(tc.key)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseKey(%q) error = %v, wantErr %t", tc.key, err, tc.wantErr)
+			}
+			if path != tc.wantPath || field != tc.wantField {
+				t.Errorf("ParseKey(%q) = %q, %q, want %q, %q", tc.key, path, field, tc.wantPath, tc.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,573 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shlint checks the syntax of POSIX shell scripts, so that the
+// obviously broken scripts of steps can be rejected before they run. It
+// doesn't run anything: it checks the quoting, the here-documents, the
+// expansions and the nesting of the compound commands.
+package shlint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is a syntax error of a script.
+type Error struct {
+	// Line is the line of the script the error is at, starting at 1.
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Check returns the first syntax error of the POSIX sh script, if any.
+func Check(script string) error {
+	c := &checker{src: script, line: 1}
+	if err := c.list(false); err != nil {
+		return err
+	}
+	return nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenOperator
+	tokenNewline
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// operators are the control and redirection operators, the longest first.
+var operators = []string{"<<<", "<<-", ";;", "&&", "||", "<<", ">>", "<&", ">&", "<>", ">|", ";", "&", "|", "(", ")", "<", ">"}
+
+// closers are the keywords closing the compound commands.
+var closers = map[string]string{
+	"if":    "fi",
+	"while": "done",
+	"until": "done",
+	"for":   "done",
+	"case":  "esac",
+	"{":     "}",
+	"(":     ")",
+}
+
+type heredoc struct {
+	delimiter string
+	stripTabs bool
+	line      int
+}
+
+type checker struct {
+	src  string
+	pos  int
+	line int
+	// heredocs are the here-documents whose bodies start on the next line.
+	heredocs []heredoc
+}
+
+// frame is a compound command being checked.
+type frame struct {
+	keyword string
+	line    int
+	// phase is the part of the compound command being checked, e.g. the
+	// condition or the body.
+	phase string
+}
+
+// list checks the commands up to the end of the script or, in a command
+// substitution, up to its closing parenthesis.
+func (c *checker) list(substitution bool) error {
+	startLine := c.line
+	var stack []*frame
+	cmdPos := true
+	// needCmd is the operator or keyword which has to be followed by a
+	// command.
+	needCmd := ""
+	// redirect is the redirection operator whose target is the next word.
+	redirect := ""
+	afterWord := false
+	for {
+		tok, err := c.next()
+		if err != nil {
+			return err
+		}
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if redirect != "" && tok.kind != tokenWord {
+			return &Error{tok.line, fmt.Sprintf("missing word after %q", redirect)}
+		}
+		wasWord := afterWord
+		afterWord = false
+
+		switch tok.kind {
+		case tokenEOF:
+			if needCmd != "" {
+				return &Error{tok.line, fmt.Sprintf("missing command after %q", needCmd)}
+			}
+			if top != nil {
+				return &Error{top.line, fmt.Sprintf("%q isn't closed with %q", top.keyword, closers[top.keyword])}
+			}
+			if len(c.heredocs) > 0 {
+				return &Error{c.heredocs[0].line, fmt.Sprintf("here-document isn't closed with %q", c.heredocs[0].delimiter)}
+			}
+			if substitution {
+				return &Error{startLine, "command substitution isn't closed"}
+			}
+			return nil
+
+		case tokenNewline:
+			if top != nil && top.keyword == "for" && top.phase == "words" {
+				top.phase = "afterWords"
+			}
+			cmdPos = true
+
+		case tokenOperator:
+			inPattern := top != nil && top.keyword == "case" && top.phase == "pattern"
+			switch tok.text {
+			case "<<", "<<-", "<", ">", ">>", "<&", ">&", "<>", ">|", "<<<":
+				redirect = tok.text
+			case ";;":
+				if top == nil || top.keyword != "case" || top.phase != "body" {
+					return &Error{tok.line, fmt.Sprintf("unexpected %q", tok.text)}
+				}
+				if needCmd != "" {
+					return &Error{tok.line, fmt.Sprintf("missing command after %q", needCmd)}
+				}
+				top.phase = "pattern"
+				cmdPos = false
+			case "(":
+				switch {
+				case inPattern:
+				case cmdPos:
+					stack = append(stack, &frame{keyword: "(", line: tok.line})
+					needCmd = "("
+				case wasWord:
+					// A function definition, e.g. name() { ...; }.
+					next, err := c.next()
+					if err != nil {
+						return err
+					}
+					if next.kind != tokenOperator || next.text != ")" {
+						return &Error{tok.line, fmt.Sprintf("unexpected %q", tok.text)}
+					}
+					cmdPos = true
+					needCmd = "()"
+				default:
+					return &Error{tok.line, fmt.Sprintf("unexpected %q", tok.text)}
+				}
+			case ")":
+				switch {
+				case inPattern:
+					top.phase = "body"
+					cmdPos = true
+				case needCmd != "":
+					return &Error{tok.line, fmt.Sprintf("missing command after %q", needCmd)}
+				case top != nil && top.keyword == "(":
+					stack = stack[:len(stack)-1]
+					cmdPos = false
+				case top == nil && substitution:
+					return nil
+				case top != nil && substitution:
+					return &Error{top.line, fmt.Sprintf("%q isn't closed with %q", top.keyword, closers[top.keyword])}
+				default:
+					return &Error{tok.line, fmt.Sprintf("unexpected %q", tok.text)}
+				}
+			case "|":
+				if inPattern {
+					continue
+				}
+				fallthrough
+			default:
+				// ;, &, && and ||
+				if needCmd != "" {
+					return &Error{tok.line, fmt.Sprintf("missing command after %q", needCmd)}
+				}
+				if cmdPos || inPattern {
+					return &Error{tok.line, fmt.Sprintf("unexpected %q", tok.text)}
+				}
+				if top != nil && top.keyword == "for" && top.phase == "words" {
+					top.phase = "afterWords"
+				}
+				cmdPos = true
+				if tok.text != ";" && tok.text != "&" {
+					needCmd = tok.text
+				}
+			}
+
+		case tokenWord:
+			if redirect != "" {
+				if redirect == "<<" || redirect == "<<-" {
+					c.heredocs = append(c.heredocs, heredoc{
+						delimiter: strings.NewReplacer("'", "", "\"", "", "\\", "").Replace(tok.text),
+						stripTabs: redirect == "<<-",
+						line:      tok.line,
+					})
+				}
+				redirect = ""
+				continue
+			}
+			if top != nil && top.keyword == "case" {
+				switch top.phase {
+				case "subject":
+					top.phase = "in"
+					continue
+				case "in":
+					if tok.text != "in" {
+						return &Error{tok.line, fmt.Sprintf("expected \"in\" instead of %q", tok.text)}
+					}
+					top.phase = "pattern"
+					continue
+				case "pattern":
+					if tok.text == "esac" {
+						stack = stack[:len(stack)-1]
+						cmdPos = false
+					}
+					continue
+				}
+			}
+			if top != nil && top.keyword == "for" {
+				switch top.phase {
+				case "name":
+					top.phase = "afterName"
+					continue
+				case "afterName":
+					switch tok.text {
+					case "in":
+						top.phase = "words"
+						continue
+					case "do":
+						top.phase = "do"
+						needCmd = "do"
+						cmdPos = true
+						continue
+					}
+					return &Error{tok.line, fmt.Sprintf("expected \"in\" or \"do\" instead of %q", tok.text)}
+				}
+			}
+			if !cmdPos {
+				afterWord = true
+				continue
+			}
+			switch tok.text {
+			case "if", "while", "until":
+				stack = append(stack, &frame{keyword: tok.text, line: tok.line, phase: "condition"})
+				needCmd = tok.text
+			case "for":
+				stack = append(stack, &frame{keyword: tok.text, line: tok.line, phase: "name"})
+				cmdPos = false
+			case "case":
+				stack = append(stack, &frame{keyword: tok.text, line: tok.line, phase: "subject"})
+				cmdPos = false
+			case "{":
+				stack = append(stack, &frame{keyword: tok.text, line: tok.line})
+				needCmd = tok.text
+			case "!":
+				needCmd = tok.text
+			case "then", "elif", "else", "fi", "do", "done", "esac", "}":
+				if needCmd != "" {
+					return &Error{tok.line, fmt.Sprintf("missing command after %q", needCmd)}
+				}
+				if !closes(top, tok.text) {
+					return &Error{tok.line, fmt.Sprintf("unexpected %q", tok.text)}
+				}
+				switch tok.text {
+				case "then", "else", "do":
+					top.phase = tok.text
+					needCmd = tok.text
+				case "elif":
+					top.phase = "condition"
+					needCmd = tok.text
+				default:
+					stack = stack[:len(stack)-1]
+					cmdPos = false
+				}
+			default:
+				cmdPos = false
+				needCmd = ""
+				afterWord = true
+			}
+		}
+	}
+}
+
+// closes returns whether keyword continues or closes the compound command
+// of top.
+func closes(top *frame, keyword string) bool {
+	if top == nil {
+		return false
+	}
+	switch keyword {
+	case "then":
+		return top.keyword == "if" && top.phase == "condition"
+	case "elif", "else":
+		return top.keyword == "if" && top.phase == "then"
+	case "fi":
+		return top.keyword == "if" && (top.phase == "then" || top.phase == "else")
+	case "do":
+		return (top.keyword == "while" || top.keyword == "until") && top.phase == "condition" ||
+			top.keyword == "for" && top.phase == "afterWords"
+	case "done":
+		return closers[top.keyword] == "done" && top.phase == "do"
+	case "esac":
+		return top.keyword == "case" && top.phase == "body"
+	case "}":
+		return top.keyword == "{"
+	}
+	return false
+}
+
+// next returns the next token of the script.
+func (c *checker) next() (token, error) {
+	for c.pos < len(c.src) {
+		switch ch := c.src[c.pos]; {
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			c.pos++
+		case ch == '\\' && c.pos+1 < len(c.src) && c.src[c.pos+1] == '\n':
+			c.pos += 2
+			c.line++
+		case ch == '#':
+			for c.pos < len(c.src) && c.src[c.pos] != '\n' {
+				c.pos++
+			}
+		case ch == '\n':
+			tok := token{kind: tokenNewline, text: "\n", line: c.line}
+			c.pos++
+			c.line++
+			if err := c.heredocBodies(); err != nil {
+				return token{}, err
+			}
+			return tok, nil
+		default:
+			for _, op := range operators {
+				if strings.HasPrefix(c.src[c.pos:], op) {
+					tok := token{kind: tokenOperator, text: op, line: c.line}
+					c.pos += len(op)
+					return tok, nil
+				}
+			}
+			return c.word()
+		}
+	}
+	return token{kind: tokenEOF, line: c.line}, nil
+}
+
+// heredocBodies skips the bodies of the pending here-documents.
+func (c *checker) heredocBodies() error {
+	for _, h := range c.heredocs {
+		for {
+			if c.pos >= len(c.src) {
+				return &Error{h.line, fmt.Sprintf("here-document isn't closed with %q", h.delimiter)}
+			}
+			end := strings.IndexByte(c.src[c.pos:], '\n')
+			if end < 0 {
+				end = len(c.src) - c.pos
+			}
+			line := strings.TrimSuffix(c.src[c.pos:c.pos+end], "\r")
+			c.pos += end
+			if c.pos < len(c.src) {
+				c.pos++
+				c.line++
+			}
+			if h.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == h.delimiter {
+				break
+			}
+		}
+	}
+	c.heredocs = nil
+	return nil
+}
+
+// word returns the word starting at the current position.
+func (c *checker) word() (token, error) {
+	start, line := c.pos, c.line
+	for c.pos < len(c.src) {
+		var err error
+		switch c.src[c.pos] {
+		case ' ', '\t', '\r', '\n', ';', '&', '|', '(', ')', '<', '>':
+			return token{kind: tokenWord, text: c.src[start:c.pos], line: line}, nil
+		case '\\':
+			c.escape()
+		case '\'':
+			err = c.singleQuoted()
+		case '"':
+			err = c.doubleQuoted()
+		case '`':
+			err = c.backquoted()
+		case '$':
+			err = c.dollar()
+		default:
+			c.pos++
+		}
+		if err != nil {
+			return token{}, err
+		}
+	}
+	return token{kind: tokenWord, text: c.src[start:c.pos], line: line}, nil
+}
+
+// escape skips a backslash and the character it escapes.
+func (c *checker) escape() {
+	c.pos++
+	if c.pos < len(c.src) {
+		if c.src[c.pos] == '\n' {
+			c.line++
+		}
+		c.pos++
+	}
+}
+
+func (c *checker) singleQuoted() error {
+	line := c.line
+	end := strings.IndexByte(c.src[c.pos+1:], '\'')
+	if end < 0 {
+		return &Error{line, "single quote isn't closed"}
+	}
+	c.line += strings.Count(c.src[c.pos:c.pos+1+end], "\n")
+	c.pos += end + 2
+	return nil
+}
+
+func (c *checker) doubleQuoted() error {
+	line := c.line
+	c.pos++
+	for c.pos < len(c.src) {
+		var err error
+		switch c.src[c.pos] {
+		case '"':
+			c.pos++
+			return nil
+		case '\\':
+			c.escape()
+		case '`':
+			err = c.backquoted()
+		case '$':
+			err = c.dollar()
+		case '\n':
+			c.line++
+			c.pos++
+		default:
+			c.pos++
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return &Error{line, "double quote isn't closed"}
+}
+
+func (c *checker) backquoted() error {
+	line := c.line
+	c.pos++
+	for c.pos < len(c.src) {
+		switch c.src[c.pos] {
+		case '`':
+			c.pos++
+			return nil
+		case '\\':
+			c.escape()
+		case '\n':
+			c.line++
+			c.pos++
+		default:
+			c.pos++
+		}
+	}
+	return &Error{line, "backquote isn't closed"}
+}
+
+// dollar checks the expansion starting at the current position, if any.
+func (c *checker) dollar() error {
+	line := c.line
+	c.pos++
+	switch {
+	case strings.HasPrefix(c.src[c.pos:], "(("):
+		return c.arithmetic(line)
+	case strings.HasPrefix(c.src[c.pos:], "("):
+		c.pos++
+		return c.list(true)
+	case strings.HasPrefix(c.src[c.pos:], "{"):
+		c.pos++
+		for c.pos < len(c.src) {
+			var err error
+			switch c.src[c.pos] {
+			case '}':
+				c.pos++
+				return nil
+			case '\\':
+				c.escape()
+			case '"':
+				err = c.doubleQuoted()
+			case '`':
+				err = c.backquoted()
+			case '$':
+				err = c.dollar()
+			case '\n':
+				c.line++
+				c.pos++
+			default:
+				c.pos++
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return &Error{line, "parameter expansion isn't closed"}
+	}
+	return nil
+}
+
+// arithmetic skips the arithmetic expansion starting at the current
+// position, after its dollar.
+func (c *checker) arithmetic(line int) error {
+	c.pos += 2
+	depth := 0
+	for c.pos < len(c.src) {
+		switch c.src[c.pos] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				// $((a) ) is a command substitution of a subshell
+				// rather than an arithmetic expansion, which is fine too.
+				c.pos++
+				if c.pos < len(c.src) && c.src[c.pos] == ')' {
+					c.pos++
+				}
+				return nil
+			}
+			depth--
+		case '\n':
+			c.line++
+		}
+		c.pos++
+	}
+	return &Error{line, "arithmetic expansion isn't closed"}
+}
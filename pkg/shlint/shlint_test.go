@@ -0,0 +1,162 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shlint
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		script string
+	}{{
+		name:   "empty",
+		script: "",
+	}, {
+		name:   "shebang and commands",
+		script: "#!/bin/sh\nset -e\necho hello > /tmp/out 2>&1\nls -l | grep foo && echo found || echo missing &\n",
+	}, {
+		name:   "quotes and expansions",
+		script: "echo 'it''s' \"$HOME ${FOO:-bar} $(date) `pwd`\" $((1 + (2 * 3))) \\$x\necho \"$(echo \")\")\"\n",
+	}, {
+		name:   "compound commands",
+		script: "if [ -f x ]; then\n  echo yes\nelif true; then :\nelse\n  echo no\nfi\nwhile false; do :; done\nuntil true\ndo\n  break\ndone\nfor i in a b; do echo $i; done\nfor i\ndo echo $i\ndone\n{ echo a; echo b; } | (cat; cat)\n! false\n",
+	}, {
+		name:   "case",
+		script: "case \"$1\" in\n  a|b) echo ab ;;\n  (c) echo c\n    echo more;;\n  *) ;;\nesac\nx=$(case y in y) echo y;; esac)\n",
+	}, {
+		name:   "function",
+		script: "greet() {\n  echo \"hello $1\"\n}\ngreet world\n",
+	}, {
+		name:   "here-documents",
+		script: "cat <<EOF > file\nif then \"'\nEOF\ncat <<-'END'\n\t(unbalanced\n\tEND\necho done\n",
+	}, {
+		name:   "keywords as arguments",
+		script: "echo if then fi done esac }\n",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := Check(tc.script); err != nil {
+				t.Errorf("Check() = %v, want no error", err)
+			}
+		})
+	}
+}
+
+func TestCheck_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		script string
+		want   string
+	}{{
+		name:   "unclosed single quote",
+		script: "echo ok\necho 'hello\n",
+		want:   "line 2: single quote isn't closed",
+	}, {
+		name:   "unclosed double quote",
+		script: "echo \"hello\n",
+		want:   "line 1: double quote isn't closed",
+	}, {
+		name:   "unclosed backquote",
+		script: "echo `pwd\n",
+		want:   "line 1: backquote isn't closed",
+	}, {
+		name:   "unclosed command substitution",
+		script: "echo $(pwd\n",
+		want:   "line 1: command substitution isn't closed",
+	}, {
+		name:   "unclosed parameter expansion",
+		script: "echo ${HOME\n",
+		want:   "line 1: parameter expansion isn't closed",
+	}, {
+		name:   "unclosed if",
+		script: "if true; then\n  echo yes\n",
+		want:   "line 1: \"if\" isn't closed with \"fi\"",
+	}, {
+		name:   "unclosed loop",
+		script: "\nfor i in a b; do\n  echo $i\n",
+		want:   "line 2: \"for\" isn't closed with \"done\"",
+	}, {
+		name:   "unclosed case",
+		script: "case x in\n  x) echo x ;;\n",
+		want:   "line 1: \"case\" isn't closed with \"esac\"",
+	}, {
+		name:   "unclosed brace group",
+		script: "{ echo a\n",
+		want:   "line 1: \"{\" isn't closed with \"}\"",
+	}, {
+		name:   "unclosed here-document",
+		script: "cat <<EOF\nhello\n",
+		want:   "line 1: here-document isn't closed with \"EOF\"",
+	}, {
+		name:   "stray fi",
+		script: "echo a\nfi\n",
+		want:   "line 2: unexpected \"fi\"",
+	}, {
+		name:   "done closing an if",
+		script: "if true; then echo; done\n",
+		want:   "line 1: unexpected \"done\"",
+	}, {
+		name:   "missing then",
+		script: "if true; echo yes; fi\n",
+		want:   "line 1: unexpected \"fi\"",
+	}, {
+		name:   "empty then",
+		script: "if true; then\nfi\n",
+		want:   "line 2: missing command after \"then\"",
+	}, {
+		name:   "missing command after pipe",
+		script: "echo a |\n",
+		want:   "line 2: missing command after \"|\"",
+	}, {
+		name:   "missing command before and",
+		script: "&& echo a\n",
+		want:   "line 1: unexpected \"&&\"",
+	}, {
+		name:   "stray semicolon",
+		script: "echo a;;\n",
+		want:   "line 1: unexpected \";;\"",
+	}, {
+		name:   "stray parenthesis",
+		script: "echo a)\n",
+		want:   "line 1: unexpected \")\"",
+	}, {
+		name:   "missing redirection target",
+		script: "echo a >\n",
+		want:   "line 1: missing word after \">\"",
+	}, {
+		name:   "missing in",
+		script: "case x of\nesac\n",
+		want:   "line 1: expected \"in\" instead of \"of\"",
+	}, {
+		name:   "error in command substitution",
+		script: "echo $(if true; then)\n",
+		want:   "line 1: missing command after \"then\"",
+	}, {
+		name:   "unclosed if in command substitution",
+		script: "echo $(if true; then echo)\n",
+		want:   "line 1: \"if\" isn't closed with \"fi\"",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Check(tc.script)
+			if err == nil {
+				t.Fatalf("Check() = nil, want %q", tc.want)
+			}
+			if d := err.Error(); d != tc.want {
+				t.Errorf("Check() = %q, want %q", d, tc.want)
+			}
+		})
+	}
+}
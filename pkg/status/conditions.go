@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+// ConditionAccessor is implemented by the statuses of TaskRuns and
+// PipelineRuns. Custom tasks can implement it to use the helpers of this
+// package on their own statuses.
+type ConditionAccessor interface {
+	GetCondition(t apis.ConditionType) *apis.Condition
+	SetCondition(newCond *apis.Condition)
+}
+
+// GetCondition returns the Succeeded condition of s, or nil if it isn't set
+// yet.
+func GetCondition(s ConditionAccessor) *apis.Condition {
+	return s.GetCondition(apis.ConditionSucceeded)
+}
+
+// IsDone returns true if the Succeeded condition of s is either true or
+// false, i.e. the run finished.
+func IsDone(s ConditionAccessor) bool {
+	c := GetCondition(s)
+	return c != nil && !c.IsUnknown()
+}
+
+// MarkRunning sets the Succeeded condition of s to unknown, with the given
+// reason and message.
+func MarkRunning(s ConditionAccessor, reason, messageFormat string, messageA ...interface{}) {
+	mark(s, corev1.ConditionUnknown, reason, messageFormat, messageA...)
+}
+
+// MarkSucceeded sets the Succeeded condition of s to true, with the given
+// reason and message.
+func MarkSucceeded(s ConditionAccessor, reason, messageFormat string, messageA ...interface{}) {
+	mark(s, corev1.ConditionTrue, reason, messageFormat, messageA...)
+}
+
+// MarkFailed sets the Succeeded condition of s to false, with the given
+// reason and message.
+func MarkFailed(s ConditionAccessor, reason, messageFormat string, messageA ...interface{}) {
+	mark(s, corev1.ConditionFalse, reason, messageFormat, messageA...)
+}
+
+func mark(s ConditionAccessor, st corev1.ConditionStatus, reason, messageFormat string, messageA ...interface{}) {
+	s.SetCondition(&apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  st,
+		Reason:  reason,
+		Message: fmt.Sprintf(messageFormat, messageA...),
+	})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestMarkConditions(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		mark     func(s ConditionAccessor)
+		want     *apis.Condition
+		wantDone bool
+	}{{
+		desc: "not started",
+		mark: func(ConditionAccessor) {},
+	}, {
+		desc: "running",
+		mark: func(s ConditionAccessor) { MarkRunning(s, ReasonRunning, "Running step %d", 1) },
+		want: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionUnknown,
+			Reason:  ReasonRunning,
+			Message: "Running step 1",
+		},
+	}, {
+		desc: "succeeded",
+		mark: func(s ConditionAccessor) { MarkSucceeded(s, ReasonSucceeded, "All Steps have completed executing") },
+		want: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionTrue,
+			Reason:  ReasonSucceeded,
+			Message: "All Steps have completed executing",
+		},
+		wantDone: true,
+	}, {
+		desc: "failed after running",
+		mark: func(s ConditionAccessor) {
+			MarkRunning(s, ReasonRunning, "Running")
+			MarkFailed(s, ReasonFailed, "Step %q failed: %s", "build", "exit status 1")
+		},
+		want: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionFalse,
+			Reason:  ReasonFailed,
+			Message: `Step "build" failed: exit status 1`,
+		},
+		wantDone: true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			for _, s := range []ConditionAccessor{&v1alpha1.TaskRunStatus{}, &v1alpha1.PipelineRunStatus{}} {
+				tc.mark(s)
+				if d := cmp.Diff(tc.want, GetCondition(s), ignoreVolatileTime); d != "" {
+					t.Errorf("GetCondition() diff -want, +got: %v", d)
+				}
+				if got := IsDone(s); got != tc.wantDone {
+					t.Errorf("IsDone() = %t, want %t", got, tc.wantDone)
+				}
+			}
+		})
+	}
+}
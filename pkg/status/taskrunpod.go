@@ -17,46 +17,70 @@ limitations under the License.
 package status
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/entrypoint"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
-	"knative.dev/pkg/apis"
 )
 
 // UpdateStatusFromPod modifies the task run status based on the pod and then returns true if the pod is running and
 // all sidecars are ready
 func UpdateStatusFromPod(taskRun *v1alpha1.TaskRun, pod *corev1.Pod, resourceLister listers.PipelineResourceLister, kubeclient kubernetes.Interface, logger *zap.SugaredLogger) bool {
-	if taskRun.Status.GetCondition(apis.ConditionSucceeded) == nil || taskRun.Status.GetCondition(apis.ConditionSucceeded).Status == corev1.ConditionUnknown {
+	if !IsDone(&taskRun.Status) {
 		// If the taskRunStatus doesn't exist yet, it's because we just started running
-		taskRun.Status.SetCondition(&apis.Condition{
-			Type:    apis.ConditionSucceeded,
-			Status:  corev1.ConditionUnknown,
-			Reason:  ReasonRunning,
-			Message: "Not all Steps in the Task have finished executing",
-		})
+		MarkRunning(&taskRun.Status, ReasonRunning, "Not all Steps in the Task have finished executing")
 	}
 
 	taskRun.Status.PodName = pod.Name
 
+	taskRun.Status.InitSteps = nil
+	for _, s := range pod.Status.InitContainerStatuses {
+		if resources.IsContainerInitStep(s.Name) {
+			taskRun.Status.InitSteps = append(taskRun.Status.InitSteps, v1alpha1.StepState{
+				ContainerState: *s.State.DeepCopy(),
+				Name:           resources.TrimInitStepNamePrefix(s.Name),
+				ContainerName:  s.Name,
+				ImageID:        s.ImageID,
+			})
+		}
+	}
+	taskRun.Status.InitSteps = appendPendingStepStates(taskRun.Status.InitSteps, pod.Spec.InitContainers, resources.IsContainerInitStep, resources.TrimInitStepNamePrefix)
+
 	taskRun.Status.Steps = []v1alpha1.StepState{}
 	for _, s := range pod.Status.ContainerStatuses {
 		if resources.IsContainerStep(s.Name) {
-			taskRun.Status.Steps = append(taskRun.Status.Steps, v1alpha1.StepState{
+			state := v1alpha1.StepState{
 				ContainerState: *s.State.DeepCopy(),
 				Name:           resources.TrimContainerNamePrefix(s.Name),
 				ContainerName:  s.Name,
 				ImageID:        s.ImageID,
-			})
+				Skipped:        getStepSkipped(s),
+			}
+			if _, ok := getEntrypointResult(s, entrypoint.TimedOutResultKey); ok {
+				state.Terminated.Reason = ReasonStepTimeout
+			}
+			// The container of a step which continued on error exits
+			// with 0, the exit code of its command is reported instead.
+			if code, ok := getStepExitCode(s); ok {
+				state.Terminated.ExitCode = code
+			}
+			taskRun.Status.Steps = append(taskRun.Status.Steps, state)
 		}
 	}
+	// The container names of the steps are known as soon as the pod is
+	// created, before the kubelet reports their state, so tools can attach
+	// to their logs right away.
+	taskRun.Status.Steps = appendPendingStepStates(taskRun.Status.Steps, pod.Spec.Containers, resources.IsContainerStep, resources.TrimContainerNamePrefix)
 
 	// Complete if we did not find a step that is not complete, or the pod is in a definitely complete phase
 	complete := areStepsComplete(pod) || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
@@ -71,22 +95,112 @@ func UpdateStatusFromPod(taskRun *v1alpha1.TaskRun, pod *corev1.Pod, resourceLis
 	return pod.Status.Phase == corev1.PodRunning && readyOrTerminatedSidecarsCount == sidecarsCount
 }
 
+// DescribeTaskRunSteps sets the display name of the Task, whose spec is
+// taskSpec, in the status of taskRun, and the display names and descriptions
+// of its steps in the states of the steps named after them. The unnamed steps
+// can't be told apart from the steps the resources add.
+func DescribeTaskRunSteps(taskRun *v1alpha1.TaskRun, taskSpec *v1alpha1.TaskSpec) {
+	taskRun.Status.DisplayName = taskSpec.DisplayName
+	steps := map[string]v1alpha1.Step{}
+	for _, s := range taskSpec.Steps {
+		if s.Name != "" {
+			steps[s.Name] = s
+		}
+	}
+	for i, state := range taskRun.Status.Steps {
+		s := steps[state.Name]
+		taskRun.Status.Steps[i].DisplayName = s.DisplayName
+		taskRun.Status.Steps[i].Description = s.Description
+	}
+}
+
+// appendPendingStepStates appends to states a state without details for each
+// of the containers matching isStep which isn't in states yet.
+func appendPendingStepStates(states []v1alpha1.StepState, containers []corev1.Container, isStep func(string) bool, stepName func(string) string) []v1alpha1.StepState {
+	reported := map[string]bool{}
+	for _, s := range states {
+		reported[s.ContainerName] = true
+	}
+	for _, c := range containers {
+		if isStep(c.Name) && !reported[c.Name] {
+			states = append(states, v1alpha1.StepState{
+				Name:          stepName(c.Name),
+				ContainerName: c.Name,
+			})
+		}
+	}
+	return states
+}
+
+// getStepSkipped returns the skipped state the entrypoint recorded in the
+// termination message of the step container, if any.
+func getStepSkipped(s corev1.ContainerStatus) *v1alpha1.StepSkipped {
+	value, ok := getEntrypointResult(s, entrypoint.SkippedResultKey)
+	if !ok {
+		return nil
+	}
+	if code, err := strconv.Atoi(value); err == nil {
+		return &v1alpha1.StepSkipped{ExitCode: int32(code)}
+	}
+	return nil
+}
+
+// getStepExitCode returns the exit code of the command of a step which
+// continued on error, which the entrypoint recorded in the termination
+// message of the step container, if any.
+func getStepExitCode(s corev1.ContainerStatus) (int32, bool) {
+	value, ok := getEntrypointResult(s, entrypoint.ExitCodeResultKey)
+	if !ok {
+		return 0, false
+	}
+	code, err := strconv.Atoi(value)
+	return int32(code), err == nil
+}
+
+// getEntrypointResult returns the value of the result key the entrypoint
+// recorded in the termination message of the step container, if any.
+func getEntrypointResult(s corev1.ContainerStatus, key string) (string, bool) {
+	if s.State.Terminated == nil || s.State.Terminated.Message == "" {
+		return "", false
+	}
+	var results []v1alpha1.PipelineResourceResult
+	if err := json.Unmarshal([]byte(s.State.Terminated.Message), &results); err != nil {
+		return "", false
+	}
+	for _, r := range results {
+		if r.Key == key {
+			return r.Value, true
+		}
+	}
+	return "", false
+}
+
+// getTimeoutMessage returns the message of the first step whose entrypoint
+// recorded the timed out result key, if any.
+func getTimeoutMessage(pod *corev1.Pod, key string) (string, bool) {
+	for _, s := range pod.Status.ContainerStatuses {
+		if !resources.IsContainerStep(s.Name) {
+			continue
+		}
+		if value, ok := getEntrypointResult(s, key); ok {
+			return fmt.Sprintf("%q %s", resources.TrimContainerNamePrefix(s.Name), value), true
+		}
+	}
+	return "", false
+}
+
 func updateCompletedTaskRun(taskRun *v1alpha1.TaskRun, pod *corev1.Pod) {
 	if didTaskRunFail(pod) {
-		msg := getFailureMessage(pod)
-		taskRun.Status.SetCondition(&apis.Condition{
-			Type:    apis.ConditionSucceeded,
-			Status:  corev1.ConditionFalse,
-			Reason:  ReasonFailed,
-			Message: msg,
-		})
+		if msg, ok := getTimeoutMessage(pod, entrypoint.TimedOutResultKey); ok {
+			MarkFailed(&taskRun.Status, ReasonStepTimeout, "Step %s", msg)
+		} else if msg, ok := getTimeoutMessage(pod, entrypoint.WaitTimedOutResultKey); ok {
+			MarkFailed(&taskRun.Status, ReasonStepWaitTimeout, "Step %s", msg)
+		} else {
+			msg := getFailureMessage(pod)
+			MarkFailed(&taskRun.Status, ReasonFailed, "%s", msg)
+		}
 	} else {
-		taskRun.Status.SetCondition(&apis.Condition{
-			Type:    apis.ConditionSucceeded,
-			Status:  corev1.ConditionTrue,
-			Reason:  ReasonSucceeded,
-			Message: "All Steps have completed executing",
-		})
+		MarkSucceeded(&taskRun.Status, ReasonSucceeded, "All Steps have completed executing")
 	}
 	// update tr completed time
 	taskRun.Status.CompletionTime = &metav1.Time{Time: time.Now()}
@@ -95,27 +209,20 @@ func updateCompletedTaskRun(taskRun *v1alpha1.TaskRun, pod *corev1.Pod) {
 func updateIncompleteTaskRun(taskRun *v1alpha1.TaskRun, pod *corev1.Pod) {
 	switch pod.Status.Phase {
 	case corev1.PodRunning:
-		taskRun.Status.SetCondition(&apis.Condition{
-			Type:    apis.ConditionSucceeded,
-			Status:  corev1.ConditionUnknown,
-			Reason:  ReasonRunning,
-			Message: "Not all Steps in the Task have finished executing",
-		})
+		MarkRunning(&taskRun.Status, ReasonRunning, "Not all Steps in the Task have finished executing")
 	case corev1.PodPending:
 		var reason, msg string
 		if IsPodExceedingNodeResources(pod) {
 			reason = ReasonExceededNodeResources
 			msg = GetExceededResourcesMessage(taskRun)
+		} else if c := unschedulableCondition(pod); c != nil {
+			reason = ReasonUnschedulable
+			msg = fmt.Sprintf("TaskRun pod %q is waiting to be scheduled: %s", pod.Name, c.Message)
 		} else {
-			reason = "Pending"
+			reason = ReasonPending
 			msg = GetWaitingMessage(pod)
 		}
-		taskRun.Status.SetCondition(&apis.Condition{
-			Type:    apis.ConditionSucceeded,
-			Status:  corev1.ConditionUnknown,
-			Reason:  reason,
-			Message: msg,
-		})
+		MarkRunning(&taskRun.Status, reason, "%s", msg)
 	}
 }
 
@@ -158,8 +265,10 @@ func countSidecars(pod *corev1.Pod) (total int, readyOrTerminated int) {
 }
 
 func getFailureMessage(pod *corev1.Pod) string {
-	// First, try to surface an error about the actual build step that failed.
-	for _, status := range pod.Status.ContainerStatuses {
+	// First, try to surface an error about the actual build step that failed,
+	// or the init step which prevented the steps from running.
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.ContainerStatuses...), initStepStatuses(pod)...)
+	for _, status := range statuses {
 		term := status.State.Terminated
 		if term != nil && term.ExitCode != 0 {
 			return fmt.Sprintf("%q exited with code %d (image: %q); for logs run: kubectl -n %s logs %s -c %s",
@@ -175,6 +284,16 @@ func getFailureMessage(pod *corev1.Pod) string {
 	return "build failed for unspecified reasons."
 }
 
+func initStepStatuses(pod *corev1.Pod) []corev1.ContainerStatus {
+	var statuses []corev1.ContainerStatus
+	for _, s := range pod.Status.InitContainerStatuses {
+		if resources.IsContainerInitStep(s.Name) {
+			statuses = append(statuses, s)
+		}
+	}
+	return statuses
+}
+
 func IsPodExceedingNodeResources(pod *corev1.Pod) bool {
 	for _, podStatus := range pod.Status.Conditions {
 		if podStatus.Reason == corev1.PodReasonUnschedulable && strings.Contains(podStatus.Message, "Insufficient") {
@@ -184,6 +303,17 @@ func IsPodExceedingNodeResources(pod *corev1.Pod) bool {
 	return false
 }
 
+// unschedulableCondition returns the PodScheduled condition of pod if the
+// scheduler couldn't find a node for it, nil otherwise.
+func unschedulableCondition(pod *corev1.Pod) *corev1.PodCondition {
+	for i, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason == corev1.PodReasonUnschedulable {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
 func GetExceededResourcesMessage(tr *v1alpha1.TaskRun) string {
 	return fmt.Sprintf("TaskRun pod %q exceeded available resources", tr.Name)
 }
@@ -51,6 +51,7 @@ func TestUpdateStatusFromPod(t *testing.T) {
 	}
 	for _, c := range []struct {
 		desc      string
+		podSpec   corev1.PodSpec
 		podStatus corev1.PodStatus
 		want      v1alpha1.TaskRunStatus
 	}{{
@@ -63,6 +64,59 @@ func TestUpdateStatusFromPod(t *testing.T) {
 			},
 			Steps: []v1alpha1.StepState{},
 		},
+	}, {
+		desc: "just-created",
+		podSpec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "step-credential-initializer"}, {Name: "init-step-setup"}},
+			Containers:     []corev1.Container{{Name: "step-build"}, {Name: "step-push"}, {Name: "sidecar"}},
+		},
+		podStatus: corev1.PodStatus{Phase: corev1.PodPending},
+		want: v1alpha1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionUnknown,
+					Reason:  "Pending",
+					Message: "Pending",
+				}},
+			},
+			InitSteps: []v1alpha1.StepState{{Name: "setup", ContainerName: "init-step-setup"}},
+			Steps: []v1alpha1.StepState{
+				{Name: "build", ContainerName: "step-build"},
+				{Name: "push", ContainerName: "step-push"},
+			},
+		},
+	}, {
+		desc: "some-steps-reported",
+		podSpec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "step-build"}, {Name: "step-push"}},
+		},
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    "step-build",
+				ImageID: "image-id",
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{},
+				},
+			}},
+		},
+		want: v1alpha1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{conditionRunning},
+			},
+			Steps: []v1alpha1.StepState{{
+				ContainerState: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{},
+				},
+				Name:          "build",
+				ContainerName: "step-build",
+				ImageID:       "image-id",
+			}, {
+				Name:          "push",
+				ContainerName: "step-push",
+			}},
+		},
 	}, {
 		desc: "ignore-creds-init",
 		podStatus: corev1.PodStatus{
@@ -157,6 +211,71 @@ func TestUpdateStatusFromPod(t *testing.T) {
 			// We don't actually care about the time, just that it's not nil
 			CompletionTime: &metav1.Time{Time: time.Now()},
 		},
+	}, {
+		desc: "skipped step",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-step-push",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+						Message:  `[{"key":"StepSkipped","value":"78"}]`,
+					},
+				},
+				ImageID: "image-id",
+			}},
+		},
+		want: v1alpha1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{conditionTrue},
+			},
+			Steps: []v1alpha1.StepState{{
+				ContainerState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+						Message:  `[{"key":"StepSkipped","value":"78"}]`,
+					}},
+				Name:          "step-push",
+				ContainerName: "step-step-push",
+				ImageID:       "image-id",
+				Skipped:       &v1alpha1.StepSkipped{ExitCode: 78},
+			}},
+			// We don't actually care about the time, just that it's not nil
+			CompletionTime: &metav1.Time{Time: time.Now()},
+		},
+	}, {
+		desc: "step continued on error",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-step-lint",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+						Message:  `[{"key":"StepExitCode","value":"2"}]`,
+					},
+				},
+				ImageID: "image-id",
+			}},
+		},
+		want: v1alpha1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{conditionTrue},
+			},
+			Steps: []v1alpha1.StepState{{
+				ContainerState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 2,
+						Message:  `[{"key":"StepExitCode","value":"2"}]`,
+					}},
+				Name:          "step-lint",
+				ContainerName: "step-step-lint",
+				ImageID:       "image-id",
+			}},
+			// We don't actually care about the time, just that it's not nil
+			CompletionTime: &metav1.Time{Time: time.Now()},
+		},
 	}, {
 		desc: "running",
 		podStatus: corev1.PodStatus{
@@ -220,6 +339,133 @@ func TestUpdateStatusFromPod(t *testing.T) {
 			// We don't actually care about the time, just that it's not nil
 			CompletionTime: &metav1.Time{Time: time.Now()},
 		},
+	}, {
+		desc: "step wait timeout",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    "step-build",
+				ImageID: "image-id",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Message:  `[{"key":"StepWaitTimedOut","value":"timed out after 5m0s waiting for \"/builder/downward/ready\""}]`,
+					},
+				},
+			}},
+		},
+		want: v1alpha1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  ReasonStepWaitTimeout,
+					Message: `Step "build" timed out after 5m0s waiting for "/builder/downward/ready"`,
+				}},
+			},
+			Steps: []v1alpha1.StepState{{
+				ContainerState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Message:  `[{"key":"StepWaitTimedOut","value":"timed out after 5m0s waiting for \"/builder/downward/ready\""}]`,
+					}},
+				Name:          "build",
+				ContainerName: "step-build",
+				ImageID:       "image-id",
+			}},
+			// We don't actually care about the time, just that it's not nil
+			CompletionTime: &metav1.Time{Time: time.Now()},
+		},
+	}, {
+		desc: "step timeout",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    "step-build",
+				ImageID: "image-id",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Reason:   "Error",
+						Message:  `[{"key":"StepTimedOut","value":"timed out after 5m0s"}]`,
+					},
+				},
+			}},
+		},
+		want: v1alpha1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  ReasonStepTimeout,
+					Message: `Step "build" timed out after 5m0s`,
+				}},
+			},
+			Steps: []v1alpha1.StepState{{
+				ContainerState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Reason:   ReasonStepTimeout,
+						Message:  `[{"key":"StepTimedOut","value":"timed out after 5m0s"}]`,
+					}},
+				Name:          "build",
+				ContainerName: "step-build",
+				ImageID:       "image-id",
+			}},
+			// We don't actually care about the time, just that it's not nil
+			CompletionTime: &metav1.Time{Time: time.Now()},
+		},
+	}, {
+		desc: "failure-init-step",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				// creds-init status; ignored
+				ImageID: "ignore-me",
+			}, {
+				Name:    "init-step-setup",
+				ImageID: "image-id",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+					},
+				},
+			}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-build",
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"},
+				},
+			}},
+		},
+		want: v1alpha1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  ReasonFailed,
+					Message: `"init-step-setup" exited with code 1 (image: "image-id"); for logs run: kubectl -n foo logs pod -c init-step-setup`,
+				}},
+			},
+			InitSteps: []v1alpha1.StepState{{
+				ContainerState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+					}},
+				Name:          "setup",
+				ContainerName: "init-step-setup",
+				ImageID:       "image-id",
+			}},
+			Steps: []v1alpha1.StepState{{
+				ContainerState: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"},
+				},
+				Name:          "build",
+				ContainerName: "step-build",
+			}},
+			// We don't actually care about the time, just that it's not nil
+			CompletionTime: &metav1.Time{Time: time.Now()},
+		},
 	}, {
 		desc: "failure-message",
 		podStatus: corev1.PodStatus{
@@ -362,6 +608,28 @@ func TestUpdateStatusFromPod(t *testing.T) {
 			},
 			Steps: []v1alpha1.StepState{},
 		},
+	}, {
+		desc: "pending-unschedulable",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 node(s) didn't match node selector.",
+			}},
+		},
+		want: v1alpha1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionUnknown,
+					Reason:  ReasonUnschedulable,
+					Message: `TaskRun pod "pod" is waiting to be scheduled: 0/3 nodes are available: 3 node(s) didn't match node selector.`,
+				}},
+			},
+			Steps: []v1alpha1.StepState{},
+		},
 	}, {
 		desc: "with-running-sidecar",
 		podStatus: corev1.PodStatus{
@@ -428,6 +696,7 @@ func TestUpdateStatusFromPod(t *testing.T) {
 					Namespace:         "foo",
 					CreationTimestamp: now,
 				},
+				Spec:   c.podSpec,
 				Status: c.podStatus,
 			}
 			startTime := time.Date(2010, 1, 1, 1, 1, 1, 1, time.UTC)
@@ -535,3 +804,39 @@ func TestCountSidecars(t *testing.T) {
 		})
 	}
 }
+
+func TestDescribeTaskRunSteps(t *testing.T) {
+	taskSpec := &v1alpha1.TaskSpec{
+		DisplayName: "Build and push",
+		Steps: []v1alpha1.Step{{
+			Container:   corev1.Container{Name: "build"},
+			DisplayName: "Build the image",
+			Description: "Builds the image with kaniko.",
+		}, {
+			Container: corev1.Container{Name: "push"},
+		}, {
+			DisplayName: "Unnamed",
+		}},
+	}
+	tr := &v1alpha1.TaskRun{Status: v1alpha1.TaskRunStatus{Steps: []v1alpha1.StepState{
+		{Name: "git-source-repo-xyz"},
+		{Name: "build"},
+		{Name: "push"},
+		{Name: "unnamed-3"},
+	}}}
+
+	DescribeTaskRunSteps(tr, taskSpec)
+
+	want := v1alpha1.TaskRunStatus{
+		DisplayName: "Build and push",
+		Steps: []v1alpha1.StepState{
+			{Name: "git-source-repo-xyz"},
+			{Name: "build", DisplayName: "Build the image", Description: "Builds the image with kaniko."},
+			{Name: "push"},
+			{Name: "unnamed-3"},
+		},
+	}
+	if d := cmp.Diff(want, tr.Status); d != "" {
+		t.Errorf("TaskRun status (-want, +got): %s", d)
+	}
+}
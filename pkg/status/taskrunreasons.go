@@ -25,6 +25,24 @@ const (
 	// that references within the TaskRun could not be resolved
 	ReasonFailedResolution = "TaskRunResolutionFailed"
 
+	// ReasonClusterTaskAccessDenied indicates that the TaskRun failed
+	// because the user on behalf of whom it runs isn't allowed to use the
+	// ClusterTask it references
+	ReasonClusterTaskAccessDenied = "ClusterTaskAccessDenied"
+
+	// ReasonTaskVerificationFailed indicates that the TaskRun failed because
+	// the Task it references doesn't match the checksum of its TaskRef
+	ReasonTaskVerificationFailed = "TaskVerificationFailed"
+
+	// ReasonCapabilitiesUnsatisfiable indicates that the TaskRun failed
+	// because its pod can't run on nodes with the capabilities its Task
+	// requires
+	ReasonCapabilitiesUnsatisfiable = "CapabilitiesUnsatisfiable"
+
+	// ReasonFilesConfigMapConflict indicates that the TaskRun failed because
+	// the ConfigMap of its inline files exists but isn't owned by it
+	ReasonFilesConfigMapConflict = "FilesConfigMapConflict"
+
 	// reasonFailedValidation indicated that the reason for failure status is
 	// that taskrun failed runtime validation
 	ReasonFailedValidation = "TaskRunValidationFailed"
@@ -44,10 +62,30 @@ const (
 	// to resource constraints on the node
 	ReasonExceededNodeResources = "ExceededNodeResources"
 
+	// ReasonPending indicates that the TaskRun's pod is pending, e.g. while
+	// its images are pulled
+	ReasonPending = "Pending"
+
+	// ReasonUnschedulable indicates that the TaskRun's pod is waiting for the
+	// scheduler to find a node it fits on
+	ReasonUnschedulable = "Unschedulable"
+
 	// ReasonSucceeded indicates that the reason for the finished status is that all of the steps
 	// completed successfully
 	ReasonSucceeded = "Succeeded"
 
 	// ReasonFailed indicates that the reason for the failure status is unknown or that one of the steps failed
 	ReasonFailed = "Failed"
+
+	// ReasonStepWaitTimeout indicates that a step of the TaskRun timed out waiting for its
+	// pod to be ready, or for the previous step to finish
+	ReasonStepWaitTimeout = "StepWaitTimeout"
+
+	// ReasonStepTimeout indicates that a step of the TaskRun ran longer than
+	// its own timeout
+	ReasonStepTimeout = "StepTimeout"
+
+	// ReasonPrivilegedStepForbidden indicates that the TaskRun has privileged steps or
+	// sidecars while the cluster forbids privileged containers
+	ReasonPrivilegedStepForbidden = "PrivilegedStepForbidden"
 )
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+// CleanupPolicyOp is an operation which modifies a CleanupPolicy struct.
+type CleanupPolicyOp func(*v1alpha1.CleanupPolicy)
+
+// CleanupPolicy creates a CleanupPolicy with default values.
+// Any number of CleanupPolicy modifiers can be passed to transform it.
+func CleanupPolicy(name, namespace string, ops ...CleanupPolicyOp) *v1alpha1.CleanupPolicy {
+	cp := &v1alpha1.CleanupPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}
+	for _, op := range ops {
+		op(cp)
+	}
+	return cp
+}
+
+// CleanupPolicySelector sets the labels the runs the CleanupPolicy applies
+// to must have.
+func CleanupPolicySelector(matchLabels map[string]string) CleanupPolicyOp {
+	return func(cp *v1alpha1.CleanupPolicy) {
+		cp.Spec.Selector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	}
+}
+
+// CleanupPolicyExpirationSecondsTTL sets the TTL of the CleanupPolicy.
+func CleanupPolicyExpirationSecondsTTL(duration time.Duration) CleanupPolicyOp {
+	return func(cp *v1alpha1.CleanupPolicy) {
+		cp.Spec.ExpirationSecondsTTL = &metav1.Duration{Duration: duration}
+	}
+}
+
+// CleanupPolicyTTLSecondsAfterSucceeded sets the TTL of the succeeded runs.
+func CleanupPolicyTTLSecondsAfterSucceeded(duration time.Duration) CleanupPolicyOp {
+	return func(cp *v1alpha1.CleanupPolicy) {
+		cp.Spec.TTLSecondsAfterSucceeded = &metav1.Duration{Duration: duration}
+	}
+}
+
+// CleanupPolicyTTLSecondsAfterFailed sets the TTL of the failed runs.
+func CleanupPolicyTTLSecondsAfterFailed(duration time.Duration) CleanupPolicyOp {
+	return func(cp *v1alpha1.CleanupPolicy) {
+		cp.Spec.TTLSecondsAfterFailed = &metav1.Duration{Duration: duration}
+	}
+}
+
+// CleanupPolicyHistoryLimits sets the number of succeeded and of failed runs
+// the CleanupPolicy keeps.
+func CleanupPolicyHistoryLimits(successful, failed int32) CleanupPolicyOp {
+	return func(cp *v1alpha1.CleanupPolicy) {
+		cp.Spec.SuccessfulHistoryLimit = &successful
+		cp.Spec.FailedHistoryLimit = &failed
+	}
+}
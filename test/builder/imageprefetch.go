@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+// ImagePrefetchOp is an operation which modifies an ImagePrefetch struct.
+type ImagePrefetchOp func(*v1alpha1.ImagePrefetch)
+
+// ImagePrefetch creates an ImagePrefetch of images with default values.
+// Any number of ImagePrefetch modifiers can be passed to transform it.
+func ImagePrefetch(name, namespace string, images []string, ops ...ImagePrefetchOp) *v1alpha1.ImagePrefetch {
+	p := &v1alpha1.ImagePrefetch{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Spec: v1alpha1.ImagePrefetchSpec{
+			Images: images,
+		},
+	}
+	for _, op := range ops {
+		op(p)
+	}
+	return p
+}
+
+// ImagePrefetchServiceAccountName sets the service account whose pull
+// secrets are used to resolve the images.
+func ImagePrefetchServiceAccountName(sa string) ImagePrefetchOp {
+	return func(p *v1alpha1.ImagePrefetch) {
+		p.Spec.ServiceAccountName = sa
+	}
+}
+
+// ImagePrefetchRefreshInterval sets how often the images are resolved again.
+func ImagePrefetchRefreshInterval(interval time.Duration) ImagePrefetchOp {
+	return func(p *v1alpha1.ImagePrefetch) {
+		p.Spec.RefreshInterval = &metav1.Duration{Duration: interval}
+	}
+}
+
+// ImagePrefetchGeneration sets the generation of the ImagePrefetch.
+func ImagePrefetchGeneration(generation int64) ImagePrefetchOp {
+	return func(p *v1alpha1.ImagePrefetch) {
+		p.Generation = generation
+	}
+}
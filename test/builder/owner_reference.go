@@ -18,6 +18,7 @@ package builder
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // OwnerReferenceOp is an operation which modifies an OwnerReference struct.
@@ -29,3 +30,10 @@ func OwnerReferenceAPIVersion(version string) OwnerReferenceOp {
 		o.APIVersion = version
 	}
 }
+
+// OwnerReferenceUID sets the UID of the owner to the OwnerReference.
+func OwnerReferenceUID(uid types.UID) OwnerReferenceOp {
+	return func(o *metav1.OwnerReference) {
+		o.UID = uid
+	}
+}
@@ -48,3 +48,42 @@ func ParamSpecDefault(value string, additionalValues ...string) ParamSpecOp {
 		ps.Default = arrayOrString
 	}
 }
+
+// ObjectValue creates an ArrayOrString of type ParamTypeObject.
+func ObjectValue(values map[string]string) *v1alpha1.ArrayOrString {
+	return &v1alpha1.ArrayOrString{
+		Type:      v1alpha1.ParamTypeObject,
+		ObjectVal: values,
+	}
+}
+
+// ParamSpecProperties declares the string keys of an object ParamSpec.
+func ParamSpecProperties(keys ...string) ParamSpecOp {
+	return func(ps *v1alpha1.ParamSpec) {
+		ps.Properties = map[string]v1alpha1.PropertySpec{}
+		for _, key := range keys {
+			ps.Properties[key] = v1alpha1.PropertySpec{Type: v1alpha1.ParamTypeString}
+		}
+	}
+}
+
+// ParamSpecObjectDefault sets the object default value of a ParamSpec.
+func ParamSpecObjectDefault(values map[string]string) ParamSpecOp {
+	return func(ps *v1alpha1.ParamSpec) {
+		ps.Default = ObjectValue(values)
+	}
+}
+
+// ParamSpecEnum restricts the values of a ParamSpec to values.
+func ParamSpecEnum(values ...string) ParamSpecOp {
+	return func(ps *v1alpha1.ParamSpec) {
+		ps.Enum = values
+	}
+}
+
+// ParamSpecPattern sets the pattern the values of a ParamSpec must match.
+func ParamSpecPattern(pattern string) ParamSpecOp {
+	return func(ps *v1alpha1.ParamSpec) {
+		ps.Pattern = pattern
+	}
+}
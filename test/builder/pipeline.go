@@ -149,6 +149,13 @@ func Retries(retries int) PipelineTaskOp {
 	}
 }
 
+// RetryPodTemplates sets the pod templates of the retries of the Pipeline Task.
+func RetryPodTemplates(templates ...v1alpha1.PodTemplate) PipelineTaskOp {
+	return func(pt *v1alpha1.PipelineTask) {
+		pt.RetryPodTemplates = templates
+	}
+}
+
 // RunAfter will update the provided Pipeline Task to indicate that it
 // should be run after the provided list of Pipeline Task names.
 func RunAfter(tasks ...string) PipelineTaskOp {
@@ -157,6 +164,13 @@ func RunAfter(tasks ...string) PipelineTaskOp {
 	}
 }
 
+// PipelineTaskStage sets the stage the PipelineTask is grouped in.
+func PipelineTaskStage(stage string) PipelineTaskOp {
+	return func(pt *v1alpha1.PipelineTask) {
+		pt.Stage = stage
+	}
+}
+
 // PipelineTaskRefKind sets the TaskKind to the PipelineTaskRef.
 func PipelineTaskRefKind(kind v1alpha1.TaskKind) PipelineTaskOp {
 	return func(pt *v1alpha1.PipelineTask) {
@@ -164,6 +178,14 @@ func PipelineTaskRefKind(kind v1alpha1.TaskKind) PipelineTaskOp {
 	}
 }
 
+// PipelineTaskRefChecksum sets the expected checksum of the Task to the
+// PipelineTaskRef.
+func PipelineTaskRefChecksum(checksum string) PipelineTaskOp {
+	return func(pt *v1alpha1.PipelineTask) {
+		pt.TaskRef.Checksum = checksum
+	}
+}
+
 // PipelineTaskParam adds a ResourceParam, with specified name and value, to the PipelineTask.
 func PipelineTaskParam(name string, value string, additionalValues ...string) PipelineTaskOp {
 	arrayOrString := ArrayOrString(value, additionalValues...)
@@ -387,6 +409,37 @@ func PipelineRunParam(name string, value string, additionalValues ...string) Pip
 	}
 }
 
+// PipelineRunParamsFrom adds a ConfigMap or Secret to read params from to the PipelineRunSpec.
+func PipelineRunParamsFrom(pf v1alpha1.ParamsFromSource) PipelineRunSpecOp {
+	return func(prs *v1alpha1.PipelineRunSpec) {
+		prs.ParamsFrom = append(prs.ParamsFrom, pf)
+	}
+}
+
+// PipelineRunExpirationSecondsTTL sets the time after which the finished
+// PipelineRun is deleted.
+func PipelineRunExpirationSecondsTTL(d time.Duration) PipelineRunSpecOp {
+	return func(prs *v1alpha1.PipelineRunSpec) {
+		prs.ExpirationSecondsTTL = &metav1.Duration{Duration: d}
+	}
+}
+
+// PipelineRunTaskRunTTL sets the expirationSecondsTTL of the TaskRuns of
+// the PipelineRun.
+func PipelineRunTaskRunTTL(d time.Duration) PipelineRunSpecOp {
+	return func(prs *v1alpha1.PipelineRunSpec) {
+		prs.TaskRunTTL = &metav1.Duration{Duration: d}
+	}
+}
+
+// PipelineRunExecutionWindow sets the execution window of the PipelineRun
+// to the given cron schedules in the given time zone.
+func PipelineRunExecutionWindow(timeZone string, schedules ...string) PipelineRunSpecOp {
+	return func(prs *v1alpha1.PipelineRunSpec) {
+		prs.ExecutionWindow = &v1alpha1.ExecutionWindow{Schedules: schedules, TimeZone: timeZone}
+	}
+}
+
 // PipelineRunTimeout sets the timeout to the PipelineRunSpec.
 func PipelineRunTimeout(duration time.Duration) PipelineRunSpecOp {
 	return func(prs *v1alpha1.PipelineRunSpec) {
@@ -463,6 +516,17 @@ func PipelineRunTaskRunsStatus(taskRunName string, status *v1alpha1.PipelineRunT
 	}
 }
 
+// PipelineRunStatusPipelineSpec pins the spec of the Pipeline, built from
+// the PipelineSpec modifiers, in the PipelineRunStatus.
+func PipelineRunStatusPipelineSpec(ops ...PipelineSpecOp) PipelineRunStatusOp {
+	return func(s *v1alpha1.PipelineRunStatus) {
+		s.PipelineSpec = &v1alpha1.PipelineSpec{}
+		for _, op := range ops {
+			op(s.PipelineSpec)
+		}
+	}
+}
+
 // PipelineResource creates a PipelineResource with default values.
 // Any number of PipelineResource modifier can be passed to transform it.
 func PipelineResource(name, namespace string, ops ...PipelineResourceOp) *v1alpha1.PipelineResource {
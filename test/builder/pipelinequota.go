@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+// PipelineQuotaOp is an operation which modifies a PipelineQuota struct.
+type PipelineQuotaOp func(*v1alpha1.PipelineQuota)
+
+// PipelineQuota creates a PipelineQuota with default values.
+// Any number of PipelineQuota modifiers can be passed to transform it.
+func PipelineQuota(name, namespace string, ops ...PipelineQuotaOp) *v1alpha1.PipelineQuota {
+	pq := &v1alpha1.PipelineQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}
+	for _, op := range ops {
+		op(pq)
+	}
+	return pq
+}
+
+// PipelineQuotaSelector sets the labels the runs the PipelineQuota applies
+// to must have.
+func PipelineQuotaSelector(matchLabels map[string]string) PipelineQuotaOp {
+	return func(pq *v1alpha1.PipelineQuota) {
+		pq.Spec.Selector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	}
+}
+
+// PipelineQuotaMaxConcurrentRuns sets the number of runs which may be
+// running at the same time.
+func PipelineQuotaMaxConcurrentRuns(max int32) PipelineQuotaOp {
+	return func(pq *v1alpha1.PipelineQuota) {
+		pq.Spec.MaxConcurrentRuns = &max
+	}
+}
+
+// PipelineQuotaMaxRunsPerHour sets the number of runs which may start in
+// any hour.
+func PipelineQuotaMaxRunsPerHour(max int32) PipelineQuotaOp {
+	return func(pq *v1alpha1.PipelineQuota) {
+		pq.Spec.MaxRunsPerHour = &max
+	}
+}
@@ -14,9 +14,12 @@ limitations under the License.
 package builder
 
 import (
+	"time"
+
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // StepOp is an operation which modifies a Container struct.
@@ -134,3 +137,28 @@ func StepTerminationMessagePolicy(terminationMessagePolicy corev1.TerminationMes
 		step.TerminationMessagePolicy = terminationMessagePolicy
 	}
 }
+
+// StepTimeout sets how long the step may run before it fails.
+func StepTimeout(d time.Duration) StepOp {
+	return func(step *v1alpha1.Step) {
+		step.Timeout = &metav1.Duration{Duration: d}
+	}
+}
+
+// StepRef sets the StepAction referenced by the step.
+func StepRef(name string) StepOp {
+	return func(step *v1alpha1.Step) {
+		step.Ref = &v1alpha1.StepActionRef{Name: name}
+	}
+}
+
+// StepParam binds a param, with the specified name and value, of the
+// StepAction referenced by the step.
+func StepParam(name, value string, additionalValues ...string) StepOp {
+	return func(step *v1alpha1.Step) {
+		step.Params = append(step.Params, v1alpha1.Param{
+			Name:  name,
+			Value: *ArrayOrString(value, additionalValues...),
+		})
+	}
+}
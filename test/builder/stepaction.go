@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+// StepActionOp is an operation which modifies a StepAction struct.
+type StepActionOp func(*v1alpha1.StepAction)
+
+// StepActionSpecOp is an operation which modifies a StepActionSpec struct.
+type StepActionSpecOp func(spec *v1alpha1.StepActionSpec)
+
+// StepAction creates a StepAction with default values.
+// Any number of StepAction modifiers can be passed to transform it.
+func StepAction(name, namespace string, ops ...StepActionOp) *v1alpha1.StepAction {
+	sa := &v1alpha1.StepAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}
+	for _, op := range ops {
+		op(sa)
+	}
+	return sa
+}
+
+// StepActionSpec sets the specified image to the StepAction Spec.
+// Any number of StepActionSpec modifiers can be passed to transform it.
+func StepActionSpec(image string, ops ...StepActionSpecOp) StepActionOp {
+	return func(sa *v1alpha1.StepAction) {
+		spec := &sa.Spec
+		spec.Image = image
+		for _, op := range ops {
+			op(spec)
+		}
+		sa.Spec = *spec
+	}
+}
+
+// StepActionCommand sets the command of the StepAction.
+func StepActionCommand(command ...string) StepActionSpecOp {
+	return func(spec *v1alpha1.StepActionSpec) {
+		spec.Command = command
+	}
+}
+
+// StepActionArgs sets the args of the StepAction.
+func StepActionArgs(args ...string) StepActionSpecOp {
+	return func(spec *v1alpha1.StepActionSpec) {
+		spec.Args = args
+	}
+}
+
+// StepActionScript sets the script of the StepAction.
+func StepActionScript(script string) StepActionSpecOp {
+	return func(spec *v1alpha1.StepActionSpec) {
+		spec.Script = script
+	}
+}
+
+// StepActionParamSpec adds a param, with specified name and type, to the Spec.
+// Any number of ParamSpec modifiers can be passed to transform it.
+func StepActionParamSpec(name string, pt v1alpha1.ParamType, ops ...ParamSpecOp) StepActionSpecOp {
+	return func(spec *v1alpha1.StepActionSpec) {
+		pp := &v1alpha1.ParamSpec{Name: name, Type: pt}
+		for _, op := range ops {
+			op(pp)
+		}
+		spec.Params = append(spec.Params, *pp)
+	}
+}
@@ -154,6 +154,21 @@ func Step(name, image string, ops ...StepOp) TaskSpecOp {
 	}
 }
 
+// InitStep adds an init step with the specified name and image to the
+// TaskSpec. Any number of Container modifier can be passed to transform it.
+func InitStep(name, image string, ops ...ContainerOp) TaskSpecOp {
+	return func(spec *v1alpha1.TaskSpec) {
+		c := corev1.Container{
+			Name:  name,
+			Image: image,
+		}
+		for _, op := range ops {
+			op(&c)
+		}
+		spec.InitSteps = append(spec.InitSteps, c)
+	}
+}
+
 func Sidecar(name, image string, ops ...ContainerOp) TaskSpecOp {
 	return func(spec *v1alpha1.TaskSpec) {
 		c := corev1.Container{
@@ -190,6 +205,18 @@ func TaskVolume(name string, ops ...VolumeOp) TaskSpecOp {
 	}
 }
 
+// TaskWorkspace adds a workspace with the specified name and mount path,
+// which defaults when empty, to the TaskSpec.
+func TaskWorkspace(name, mountPath string, readOnly bool) TaskSpecOp {
+	return func(spec *v1alpha1.TaskSpec) {
+		spec.Workspaces = append(spec.Workspaces, v1alpha1.WorkspaceDeclaration{
+			Name:      name,
+			MountPath: mountPath,
+			ReadOnly:  readOnly,
+		})
+	}
+}
+
 // VolumeSource sets the VolumeSource to the Volume.
 func VolumeSource(s corev1.VolumeSource) VolumeOp {
 	return func(v *corev1.Volume) {
@@ -367,6 +394,52 @@ func TaskRunTimeout(d time.Duration) TaskRunSpecOp {
 	}
 }
 
+// TaskRunExpirationSecondsTTL sets the time after which the finished TaskRun
+// is deleted.
+func TaskRunExpirationSecondsTTL(d time.Duration) TaskRunSpecOp {
+	return func(spec *v1alpha1.TaskRunSpec) {
+		spec.ExpirationSecondsTTL = &metav1.Duration{Duration: d}
+	}
+}
+
+// TaskRunTTLSecondsAfterSucceeded sets the time after which the TaskRun is
+// deleted if it succeeded.
+func TaskRunTTLSecondsAfterSucceeded(d time.Duration) TaskRunSpecOp {
+	return func(spec *v1alpha1.TaskRunSpec) {
+		spec.TTLSecondsAfterSucceeded = &metav1.Duration{Duration: d}
+	}
+}
+
+// TaskRunTTLSecondsAfterFailed sets the time after which the TaskRun is
+// deleted if it failed.
+func TaskRunTTLSecondsAfterFailed(d time.Duration) TaskRunSpecOp {
+	return func(spec *v1alpha1.TaskRunSpec) {
+		spec.TTLSecondsAfterFailed = &metav1.Duration{Duration: d}
+	}
+}
+
+// TaskRunCleanupMode sets how the TaskRun is cleaned up once its TTL
+// elapses.
+func TaskRunCleanupMode(mode v1alpha1.TaskRunCleanupMode) TaskRunSpecOp {
+	return func(spec *v1alpha1.TaskRunSpec) {
+		spec.CleanupMode = mode
+	}
+}
+
+// TaskRunArchiveOnExpire sets the TaskRun to be archived to a TaskRunArchive
+// before it is cleaned up.
+func TaskRunArchiveOnExpire(spec *v1alpha1.TaskRunSpec) {
+	spec.ArchiveOnExpire = true
+}
+
+// TaskRunExecutionWindow sets the execution window of the TaskRun to the
+// given cron schedules in the given time zone.
+func TaskRunExecutionWindow(timeZone string, schedules ...string) TaskRunSpecOp {
+	return func(spec *v1alpha1.TaskRunSpec) {
+		spec.ExecutionWindow = &v1alpha1.ExecutionWindow{Schedules: schedules, TimeZone: timeZone}
+	}
+}
+
 // TaskRunNilTimeout sets the timeout duration to nil on the TaskRunSpec.
 func TaskRunNilTimeout(spec *v1alpha1.TaskRunSpec) {
 	spec.Timeout = nil
@@ -495,6 +568,13 @@ func TaskRefKind(kind v1alpha1.TaskKind) TaskRefOp {
 	}
 }
 
+// TaskRefChecksum sets the expected checksum of the Task to the TaskRef.
+func TaskRefChecksum(checksum string) TaskRefOp {
+	return func(ref *v1alpha1.TaskRef) {
+		ref.Checksum = checksum
+	}
+}
+
 // TaskRefAPIVersion sets the specified api version to the TaskRef.
 func TaskRefAPIVersion(version string) TaskRefOp {
 	return func(ref *v1alpha1.TaskRef) {
@@ -514,6 +594,32 @@ func TaskRunTaskSpec(ops ...TaskSpecOp) TaskRunSpecOp {
 	}
 }
 
+// TaskRunWorkspaceEmptyDir binds the workspace with the specified name to
+// an emptyDir volume.
+func TaskRunWorkspaceEmptyDir(name, subPath string) TaskRunSpecOp {
+	return func(spec *v1alpha1.TaskRunSpec) {
+		spec.Workspaces = append(spec.Workspaces, v1alpha1.WorkspaceBinding{
+			Name:     name,
+			SubPath:  subPath,
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		})
+	}
+}
+
+// TaskRunWorkspacePVC binds the workspace with the specified name to the
+// specified PersistentVolumeClaim.
+func TaskRunWorkspacePVC(name, subPath, claimName string) TaskRunSpecOp {
+	return func(spec *v1alpha1.TaskRunSpec) {
+		spec.Workspaces = append(spec.Workspaces, v1alpha1.WorkspaceBinding{
+			Name:    name,
+			SubPath: subPath,
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+			},
+		})
+	}
+}
+
 // TaskRunServiceAccount sets the serviceAccount to the TaskRunSpec.
 func TaskRunServiceAccountName(sa string) TaskRunSpecOp {
 	return func(trs *v1alpha1.TaskRunSpec) {
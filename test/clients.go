@@ -49,6 +49,8 @@ import (
 // clients holds instances of interfaces for making requests to the Pipeline controllers.
 type clients struct {
 	KubeClient *knativetest.KubeClient
+	// PipelineClientset isn't restricted to the namespace of the test.
+	PipelineClientset versioned.Interface
 
 	PipelineClient         v1alpha1.PipelineInterface
 	TaskClient             v1alpha1.TaskInterface
@@ -80,6 +82,7 @@ func newClients(t *testing.T, configPath, clusterName, namespace string) *client
 	if err != nil {
 		t.Fatalf("failed to create pipeline clientset from config file at %s: %s", configPath, err)
 	}
+	c.PipelineClientset = cs
 	c.PipelineClient = cs.TektonV1alpha1().Pipelines(namespace)
 	c.TaskClient = cs.TektonV1alpha1().Tasks(namespace)
 	c.TaskRunClient = cs.TektonV1alpha1().TaskRuns(namespace)
@@ -25,18 +25,26 @@ import (
 	fakepipelineclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
 	informersv1alpha1 "github.com/tektoncd/pipeline/pkg/client/informers/externalversions/pipeline/v1alpha1"
 	fakepipelineclient "github.com/tektoncd/pipeline/pkg/client/injection/client/fake"
+	fakecleanuppolicyinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/cleanuppolicy/fake"
 	fakeclustertaskinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/clustertask/fake"
 	fakeconditioninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/condition/fake"
+	fakeimageprefetchinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/imageprefetch/fake"
 	fakepipelineinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipeline/fake"
+	fakepipelinequotainformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelinequota/fake"
 	fakeresourceinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelineresource/fake"
 	fakepipelineruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/pipelinerun/fake"
+	fakestepactioninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/stepaction/fake"
 	faketaskinformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/task/fake"
 	faketaskruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1alpha1/taskrun/fake"
 	corev1 "k8s.io/api/core/v1"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
 	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+	fakeconfigmapinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap/fake"
+	fakenamespaceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/namespace/fake"
+	fakenodeinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/node/fake"
 	fakepodinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/fake"
+	fakesecretinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/secret/fake"
 	"knative.dev/pkg/controller"
 )
 
@@ -50,8 +58,15 @@ type Data struct {
 	ClusterTasks      []*v1alpha1.ClusterTask
 	PipelineResources []*v1alpha1.PipelineResource
 	Conditions        []*v1alpha1.Condition
+	StepActions       []*v1alpha1.StepAction
+	CleanupPolicies   []*v1alpha1.CleanupPolicy
+	ImagePrefetches   []*v1alpha1.ImagePrefetch
+	PipelineQuotas    []*v1alpha1.PipelineQuota
 	Pods              []*corev1.Pod
 	Namespaces        []*corev1.Namespace
+	ConfigMaps        []*corev1.ConfigMap
+	Secrets           []*corev1.Secret
+	Nodes             []*corev1.Node
 }
 
 // Clients holds references to clients which are useful for reconciler tests.
@@ -69,7 +84,15 @@ type Informers struct {
 	ClusterTask      informersv1alpha1.ClusterTaskInformer
 	PipelineResource informersv1alpha1.PipelineResourceInformer
 	Condition        informersv1alpha1.ConditionInformer
+	StepAction       informersv1alpha1.StepActionInformer
+	CleanupPolicy    informersv1alpha1.CleanupPolicyInformer
+	ImagePrefetch    informersv1alpha1.ImagePrefetchInformer
+	PipelineQuota    informersv1alpha1.PipelineQuotaInformer
 	Pod              coreinformers.PodInformer
+	Namespace        coreinformers.NamespaceInformer
+	ConfigMap        coreinformers.ConfigMapInformer
+	Secret           coreinformers.SecretInformer
+	Node             coreinformers.NodeInformer
 }
 
 // TestAssets holds references to the controller, logs, clients, and informers.
@@ -94,7 +117,15 @@ func SeedTestData(t *testing.T, ctx context.Context, d Data) (Clients, Informers
 		ClusterTask:      fakeclustertaskinformer.Get(ctx),
 		PipelineResource: fakeresourceinformer.Get(ctx),
 		Condition:        fakeconditioninformer.Get(ctx),
+		StepAction:       fakestepactioninformer.Get(ctx),
+		CleanupPolicy:    fakecleanuppolicyinformer.Get(ctx),
+		ImagePrefetch:    fakeimageprefetchinformer.Get(ctx),
+		PipelineQuota:    fakepipelinequotainformer.Get(ctx),
 		Pod:              fakepodinformer.Get(ctx),
+		Namespace:        fakenamespaceinformer.Get(ctx),
+		ConfigMap:        fakeconfigmapinformer.Get(ctx),
+		Secret:           fakesecretinformer.Get(ctx),
+		Node:             fakenodeinformer.Get(ctx),
 	}
 
 	for _, pr := range d.PipelineRuns {
@@ -153,6 +184,38 @@ func SeedTestData(t *testing.T, ctx context.Context, d Data) (Clients, Informers
 			t.Fatal(err)
 		}
 	}
+	for _, sa := range d.StepActions {
+		if err := i.StepAction.Informer().GetIndexer().Add(sa); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Pipeline.TektonV1alpha1().StepActions(sa.Namespace).Create(sa); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, cp := range d.CleanupPolicies {
+		if err := i.CleanupPolicy.Informer().GetIndexer().Add(cp); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Pipeline.TektonV1alpha1().CleanupPolicies(cp.Namespace).Create(cp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, ip := range d.ImagePrefetches {
+		if err := i.ImagePrefetch.Informer().GetIndexer().Add(ip); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Pipeline.TektonV1alpha1().ImagePrefetches(ip.Namespace).Create(ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, pq := range d.PipelineQuotas {
+		if err := i.PipelineQuota.Informer().GetIndexer().Add(pq); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Pipeline.TektonV1alpha1().PipelineQuotas(pq.Namespace).Create(pq); err != nil {
+			t.Fatal(err)
+		}
+	}
 	for _, p := range d.Pods {
 		if err := i.Pod.Informer().GetIndexer().Add(p); err != nil {
 			t.Fatal(err)
@@ -162,10 +225,37 @@ func SeedTestData(t *testing.T, ctx context.Context, d Data) (Clients, Informers
 		}
 	}
 	for _, n := range d.Namespaces {
+		if err := i.Namespace.Informer().GetIndexer().Add(n); err != nil {
+			t.Fatal(err)
+		}
 		if _, err := c.Kube.CoreV1().Namespaces().Create(n); err != nil {
 			t.Fatal(err)
 		}
 	}
+	for _, cm := range d.ConfigMaps {
+		if err := i.ConfigMap.Informer().GetIndexer().Add(cm); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Kube.CoreV1().ConfigMaps(cm.Namespace).Create(cm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, s := range d.Secrets {
+		if err := i.Secret.Informer().GetIndexer().Add(s); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Kube.CoreV1().Secrets(s.Namespace).Create(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, n := range d.Nodes {
+		if err := i.Node.Informer().GetIndexer().Add(n); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Kube.CoreV1().Nodes().Create(n); err != nil {
+			t.Fatal(err)
+		}
+	}
 	c.Pipeline.ClearActions()
 	c.Kube.ClearActions()
 	return c, i
@@ -24,6 +24,9 @@
 # Scenario 2: install the previous release, create the pipelines and tasks, upgrade
 # to the current release, and validate whether the Tekton pipeline works.
 
+# Scenario 3: install the previous release, create runs, upgrade to the current
+# release while they run, and validate whether they complete as expected.
+
 source $(dirname $0)/e2e-common.sh
 PREVIOUS_PIPELINE_VERSION=v0.5.2
 
@@ -97,6 +100,19 @@ for test in taskrun pipelinerun; do
   fi
 done
 
+# Remove all the pipeline CRDs, and clean up the environment for next Scenario.
+uninstall_pipeline_crd
+uninstall_pipeline_crd_version $PREVIOUS_PIPELINE_VERSION
+
+# Finally, we will verify if Scenario 3 works.
+# Install the previous release.
+header "Install the previous release of Tekton pipeline $PREVIOUS_PIPELINE_VERSION"
+install_pipeline_crd_version $PREVIOUS_PIPELINE_VERSION
+
+# Create runs, and upgrade to the current release while they run.
+header "Upgrade to the current release of Tekton pipeline with runs in flight"
+go_test_e2e -timeout=20m ./test -run TestUpgradeRunsInFlight -upgrade-command="ko apply -f config/" || failed=1
+
 (( failed )) && fail_test
 
 success
@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"fmt"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"golang.org/x/xerrors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+// Snapshot is the state of the TaskRuns and PipelineRuns of a namespace at
+// some point, e.g. right before an upgrade.
+type Snapshot struct {
+	TaskRuns     map[string]*v1alpha1.TaskRun
+	PipelineRuns map[string]*v1alpha1.PipelineRun
+}
+
+// Take returns a Snapshot of the runs of namespace.
+func Take(c versioned.Interface, namespace string) (*Snapshot, error) {
+	trs, err := c.TektonV1alpha1().TaskRuns(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't list the TaskRuns of %s: %w", namespace, err)
+	}
+	prs, err := c.TektonV1alpha1().PipelineRuns(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("couldn't list the PipelineRuns of %s: %w", namespace, err)
+	}
+	s := &Snapshot{
+		TaskRuns:     make(map[string]*v1alpha1.TaskRun, len(trs.Items)),
+		PipelineRuns: make(map[string]*v1alpha1.PipelineRun, len(prs.Items)),
+	}
+	for i := range trs.Items {
+		s.TaskRuns[trs.Items[i].Name] = &trs.Items[i]
+	}
+	for i := range prs.Items {
+		s.PipelineRuns[prs.Items[i].Name] = &prs.Items[i]
+	}
+	return s, nil
+}
+
+// Diff returns, one per line, the changes from s to after that an upgrade
+// must not make to runs in flight: their specs must stay the same, as must
+// the pods they started, the steps which terminated, the TaskRuns of the
+// PipelineRuns and the outcome of the runs which completed. The runs created
+// since s are ignored.
+func (s *Snapshot) Diff(after *Snapshot) []string {
+	var diffs []string
+	for name, tr := range s.TaskRuns {
+		got, ok := after.TaskRuns[name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("TaskRun %s is gone", name))
+			continue
+		}
+		diffs = append(diffs, diffTaskRun(tr, got)...)
+	}
+	for name, pr := range s.PipelineRuns {
+		got, ok := after.PipelineRuns[name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("PipelineRun %s is gone", name))
+			continue
+		}
+		diffs = append(diffs, diffPipelineRun(pr, got)...)
+	}
+	return diffs
+}
+
+func diffTaskRun(before, after *v1alpha1.TaskRun) []string {
+	var diffs []string
+	if !equality.Semantic.DeepEqual(before.Spec, after.Spec) {
+		diffs = append(diffs, fmt.Sprintf("TaskRun %s spec changed (-before, +after): %s", before.Name, cmp.Diff(before.Spec, after.Spec)))
+	}
+	if !equality.Semantic.DeepEqual(before.Status.StartTime, after.Status.StartTime) {
+		diffs = append(diffs, fmt.Sprintf("TaskRun %s start time changed from %v to %v", before.Name, before.Status.StartTime, after.Status.StartTime))
+	}
+	if before.Status.PodName != "" && before.Status.PodName != after.Status.PodName {
+		diffs = append(diffs, fmt.Sprintf("TaskRun %s pod changed from %s to %q", before.Name, before.Status.PodName, after.Status.PodName))
+	}
+	steps := make(map[string]v1alpha1.StepState, len(after.Status.Steps))
+	for _, step := range after.Status.Steps {
+		steps[step.Name] = step
+	}
+	for _, step := range before.Status.Steps {
+		if step.Terminated == nil {
+			continue
+		}
+		if got := steps[step.Name]; !equality.Semantic.DeepEqual(step.Terminated, got.Terminated) {
+			diffs = append(diffs, fmt.Sprintf("TaskRun %s terminated step %s changed (-before, +after): %s", before.Name, step.Name, cmp.Diff(step.Terminated, got.Terminated)))
+		}
+	}
+	diffs = append(diffs, diffOutcome("TaskRun", before.Name, before.Status.GetCondition(apis.ConditionSucceeded), after.Status.GetCondition(apis.ConditionSucceeded))...)
+	return diffs
+}
+
+func diffPipelineRun(before, after *v1alpha1.PipelineRun) []string {
+	var diffs []string
+	if !equality.Semantic.DeepEqual(before.Spec, after.Spec) {
+		diffs = append(diffs, fmt.Sprintf("PipelineRun %s spec changed (-before, +after): %s", before.Name, cmp.Diff(before.Spec, after.Spec)))
+	}
+	if !equality.Semantic.DeepEqual(before.Status.StartTime, after.Status.StartTime) {
+		diffs = append(diffs, fmt.Sprintf("PipelineRun %s start time changed from %v to %v", before.Name, before.Status.StartTime, after.Status.StartTime))
+	}
+	for name, tr := range before.Status.TaskRuns {
+		got, ok := after.Status.TaskRuns[name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("PipelineRun %s lost TaskRun %s", before.Name, name))
+			continue
+		}
+		if got.PipelineTaskName != tr.PipelineTaskName {
+			diffs = append(diffs, fmt.Sprintf("PipelineRun %s TaskRun %s changed from pipeline task %s to %s", before.Name, name, tr.PipelineTaskName, got.PipelineTaskName))
+		}
+	}
+	diffs = append(diffs, diffOutcome("PipelineRun", before.Name, before.Status.GetCondition(apis.ConditionSucceeded), after.Status.GetCondition(apis.ConditionSucceeded))...)
+	return diffs
+}
+
+// diffOutcome returns how the outcome of the run of the given kind and name
+// changed from before to after, if it had completed already.
+func diffOutcome(kind, name string, before, after *apis.Condition) []string {
+	if before == nil || before.IsUnknown() {
+		return nil
+	}
+	if after == nil || after.Status != before.Status || after.Reason != before.Reason {
+		return []string{fmt.Sprintf("%s %s outcome changed from %s to %s", kind, name, outcome(before), outcome(after))}
+	}
+	return nil
+}
+
+func outcome(c *apis.Condition) string {
+	if c == nil {
+		return "none"
+	}
+	if c.Reason == "" {
+		return string(c.Status)
+	}
+	return fmt.Sprintf("%s (%s)", c.Status, c.Reason)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+var (
+	startTime = time.Date(2019, time.November, 1, 12, 0, 0, 0, time.UTC)
+	running   = apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionUnknown, Reason: "Running"}
+	succeeded = apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue, Reason: "Succeeded"}
+	failed    = apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: "Failed"}
+)
+
+func taskRun(ops ...tb.TaskRunStatusOp) *v1alpha1.TaskRun {
+	return tb.TaskRun("build", "foo",
+		tb.TaskRunSpec(tb.TaskRunTaskRef("build-task")),
+		tb.TaskRunStatus(append([]tb.TaskRunStatusOp{tb.TaskRunStartTime(startTime), tb.PodName("build-pod")}, ops...)...),
+	)
+}
+
+func pipelineRun(ops ...tb.PipelineRunStatusOp) *v1alpha1.PipelineRun {
+	return tb.PipelineRun("release", "foo",
+		tb.PipelineRunSpec("release-pipeline"),
+		tb.PipelineRunStatus(append([]tb.PipelineRunStatusOp{
+			tb.PipelineRunStartTime(startTime),
+			tb.PipelineRunTaskRunsStatus("release-build", &v1alpha1.PipelineRunTaskRunStatus{PipelineTaskName: "build"}),
+		}, ops...)...),
+	)
+}
+
+// step returns the state of the step called name, terminated with exitCode.
+func step(name string, exitCode int) tb.TaskRunStatusOp {
+	return func(s *v1alpha1.TaskRunStatus) {
+		tb.StepState(tb.StateTerminated(exitCode))(s)
+		s.Steps[len(s.Steps)-1].Name = name
+	}
+}
+
+func TestTake(t *testing.T) {
+	c := fake.NewSimpleClientset(taskRun(), pipelineRun(), tb.TaskRun("other", "bar"))
+	s, err := Take(c, "foo")
+	if err != nil {
+		t.Fatalf("Take() = %v", err)
+	}
+	if len(s.TaskRuns) != 1 || s.TaskRuns["build"] == nil {
+		t.Errorf("Expected the TaskRuns of foo only, got %v", s.TaskRuns)
+	}
+	if len(s.PipelineRuns) != 1 || s.PipelineRuns["release"] == nil {
+		t.Errorf("Expected the PipelineRuns of foo, got %v", s.PipelineRuns)
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		before *Snapshot
+		after  *Snapshot
+		want   []string
+	}{{
+		name:   "completed",
+		before: &Snapshot{TaskRuns: map[string]*v1alpha1.TaskRun{"build": taskRun(tb.StatusCondition(running), step("compile", 0))}},
+		after: &Snapshot{
+			TaskRuns: map[string]*v1alpha1.TaskRun{"build": taskRun(tb.StatusCondition(failed), step("compile", 0), step("test", 1))},
+			// The runs created after the snapshot don't matter.
+			PipelineRuns: map[string]*v1alpha1.PipelineRun{"release": pipelineRun()},
+		},
+	}, {
+		name:   "deleted",
+		before: &Snapshot{TaskRuns: map[string]*v1alpha1.TaskRun{"build": taskRun()}, PipelineRuns: map[string]*v1alpha1.PipelineRun{"release": pipelineRun()}},
+		after:  &Snapshot{},
+		want:   []string{"TaskRun build is gone", "PipelineRun release is gone"},
+	}, {
+		name:   "pod replaced",
+		before: &Snapshot{TaskRuns: map[string]*v1alpha1.TaskRun{"build": taskRun()}},
+		after:  &Snapshot{TaskRuns: map[string]*v1alpha1.TaskRun{"build": taskRun(tb.PodName("build-pod-2"))}},
+		want:   []string{"TaskRun build pod changed from build-pod to \"build-pod-2\""},
+	}, {
+		name:   "terminated step changed",
+		before: &Snapshot{TaskRuns: map[string]*v1alpha1.TaskRun{"build": taskRun(step("compile", 0))}},
+		after:  &Snapshot{TaskRuns: map[string]*v1alpha1.TaskRun{"build": taskRun(step("compile", 1))}},
+		want:   []string{"TaskRun build terminated step compile changed"},
+	}, {
+		name:   "outcome changed",
+		before: &Snapshot{TaskRuns: map[string]*v1alpha1.TaskRun{"build": taskRun(tb.StatusCondition(succeeded))}},
+		after:  &Snapshot{TaskRuns: map[string]*v1alpha1.TaskRun{"build": taskRun(tb.StatusCondition(failed))}},
+		want:   []string{"TaskRun build outcome changed from True (Succeeded) to False (Failed)"},
+	}, {
+		name:   "pipelinerun lost taskrun",
+		before: &Snapshot{PipelineRuns: map[string]*v1alpha1.PipelineRun{"release": pipelineRun()}},
+		after: &Snapshot{PipelineRuns: map[string]*v1alpha1.PipelineRun{"release": tb.PipelineRun("release", "foo",
+			tb.PipelineRunSpec("release-pipeline"),
+			tb.PipelineRunStatus(tb.PipelineRunStartTime(startTime)),
+		)}},
+		want: []string{"PipelineRun release lost TaskRun release-build"},
+	}, {
+		name:   "pipelinerun restarted",
+		before: &Snapshot{PipelineRuns: map[string]*v1alpha1.PipelineRun{"release": pipelineRun()}},
+		after:  &Snapshot{PipelineRuns: map[string]*v1alpha1.PipelineRun{"release": pipelineRun(tb.PipelineRunStartTime(startTime.Add(time.Minute)))}},
+		want:   []string{"PipelineRun release start time changed"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.before.Diff(tc.after)
+			if len(got) != len(tc.want) {
+				t.Fatalf("Diff() = %q, want %d changes", got, len(tc.want))
+			}
+			for _, want := range tc.want {
+				if !containsPrefix(got, want) {
+					t.Errorf("Diff() = %q, want a change starting with %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func containsPrefix(diffs []string, prefix string) bool {
+	for _, d := range diffs {
+		if strings.HasPrefix(d, prefix) {
+			return true
+		}
+	}
+	return false
+}
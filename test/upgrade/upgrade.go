@@ -0,0 +1,227 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package upgrade tests upgrades of Tekton Pipelines against runs in flight.
+
+A Harness creates TaskRuns and PipelineRuns under the installed release,
+waits for them to start, takes a Snapshot of them and upgrades the
+components while they run. Once they complete, the runs must have the
+expected outcome, and the parts of their status written before the upgrade
+must be preserved:
+
+	h := &upgrade.Harness{
+		Client:    pipelineClient,
+		Namespace: namespace,
+		Upgrade: func() error {
+			return exec.Command("ko", "apply", "-f", "config/").Run()
+		},
+	}
+	if err := h.Test(upgrade.Run{
+		TaskRun:       tb.TaskRun("sleep", namespace, ...),
+		WantSucceeded: corev1.ConditionTrue,
+	}); err != nil {
+		t.Error(err)
+	}
+*/
+package upgrade
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"golang.org/x/xerrors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// DefaultInterval is how often the runs are polled, unless the Harness
+	// sets another interval.
+	DefaultInterval = time.Second
+	// DefaultTimeout is how long the runs have to start, and then to
+	// complete, unless the Harness sets another timeout.
+	DefaultTimeout = 10 * time.Minute
+)
+
+// Run is a TaskRun or a PipelineRun created before the upgrade, and the
+// outcome it must complete with after it.
+type Run struct {
+	TaskRun     *v1alpha1.TaskRun
+	PipelineRun *v1alpha1.PipelineRun
+
+	// WantSucceeded is the status of the Succeeded condition of the run
+	// once it completed.
+	WantSucceeded corev1.ConditionStatus
+	// WantReason, if set, is the reason of that condition.
+	WantReason string
+}
+
+func (r Run) name() string {
+	if r.TaskRun != nil {
+		return "TaskRun " + r.TaskRun.Name
+	}
+	return "PipelineRun " + r.PipelineRun.Name
+}
+
+// Harness upgrades the components of Tekton Pipelines while runs are in
+// flight.
+type Harness struct {
+	Client    versioned.Interface
+	Namespace string
+	// Upgrade installs the components being tested over the ones the runs
+	// are created with, e.g. by applying their release.
+	Upgrade func() error
+
+	// Interval and Timeout default to DefaultInterval and DefaultTimeout.
+	Interval time.Duration
+	Timeout  time.Duration
+	// Logf, if set, logs the progress of the test, e.g. testing.T.Logf.
+	Logf func(format string, args ...interface{})
+}
+
+// Test creates runs in the namespace of h, waits for all of them to have
+// started, upgrades the components and waits for the runs to complete. It
+// returns an error listing the runs which didn't complete with their
+// expected outcome, and the changes the upgrade made to the runs it
+// shouldn't have, as reported by Snapshot.Diff.
+func (h *Harness) Test(runs ...Run) error {
+	for _, r := range runs {
+		if err := h.create(r); err != nil {
+			return xerrors.Errorf("couldn't create %s: %w", r.name(), err)
+		}
+	}
+	h.logf("Waiting for %d runs to start", len(runs))
+	if err := h.poll(func(s *Snapshot) bool { return allRuns(runs, s, runState.started) }); err != nil {
+		return xerrors.Errorf("runs didn't start before the upgrade: %w", err)
+	}
+	before, err := Take(h.Client, h.Namespace)
+	if err != nil {
+		return err
+	}
+	h.logf("Upgrading the components")
+	if err := h.Upgrade(); err != nil {
+		return xerrors.Errorf("couldn't upgrade the components: %w", err)
+	}
+	h.logf("Waiting for %d runs to complete", len(runs))
+	var after *Snapshot
+	if err := h.poll(func(s *Snapshot) bool {
+		after = s
+		return allRuns(runs, s, runState.completed)
+	}); err != nil {
+		return xerrors.Errorf("runs didn't complete after the upgrade: %w", err)
+	}
+
+	var problems []string
+	for _, r := range runs {
+		state, _ := stateOf(r, after)
+		if c := state.succeeded; c.Status != r.WantSucceeded || (r.WantReason != "" && c.Reason != r.WantReason) {
+			problems = append(problems, fmt.Sprintf("%s completed with %s, want %s", r.name(), outcome(c), outcome(&apis.Condition{Status: r.WantSucceeded, Reason: r.WantReason})))
+		}
+	}
+	problems = append(problems, before.Diff(after)...)
+	if len(problems) > 0 {
+		return xerrors.Errorf("the upgrade broke runs in flight:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+func (h *Harness) create(r Run) error {
+	if r.TaskRun != nil {
+		_, err := h.Client.TektonV1alpha1().TaskRuns(h.Namespace).Create(r.TaskRun)
+		return err
+	}
+	_, err := h.Client.TektonV1alpha1().PipelineRuns(h.Namespace).Create(r.PipelineRun)
+	return err
+}
+
+// poll takes a Snapshot of the runs every interval, until done returns true
+// for it, or the timeout elapses.
+func (h *Harness) poll(done func(*Snapshot) bool) error {
+	interval, timeout := h.Interval, h.Timeout
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return wait.PollImmediate(interval, timeout, func() (bool, error) {
+		s, err := Take(h.Client, h.Namespace)
+		if err != nil {
+			return false, err
+		}
+		return done(s), nil
+	})
+}
+
+func (h *Harness) logf(format string, args ...interface{}) {
+	if h.Logf != nil {
+		h.Logf(format, args...)
+	}
+}
+
+// runState is what the Harness waits on in the status of a run.
+type runState struct {
+	// running is whether the run has started and created its pod, or its
+	// TaskRuns.
+	running   bool
+	succeeded *apis.Condition
+}
+
+func (s runState) started() bool {
+	return s.running || s.completed()
+}
+
+func (s runState) completed() bool {
+	return s.succeeded != nil && !s.succeeded.IsUnknown()
+}
+
+// stateOf returns the state of r in s, and whether r is in s.
+func stateOf(r Run, s *Snapshot) (runState, bool) {
+	if r.TaskRun != nil {
+		tr, ok := s.TaskRuns[r.TaskRun.Name]
+		if !ok {
+			return runState{}, false
+		}
+		return runState{
+			running:   tr.Status.StartTime != nil && tr.Status.PodName != "",
+			succeeded: tr.Status.GetCondition(apis.ConditionSucceeded),
+		}, true
+	}
+	pr, ok := s.PipelineRuns[r.PipelineRun.Name]
+	if !ok {
+		return runState{}, false
+	}
+	return runState{
+		running:   pr.Status.StartTime != nil && len(pr.Status.TaskRuns) > 0,
+		succeeded: pr.Status.GetCondition(apis.ConditionSucceeded),
+	}, true
+}
+
+// allRuns returns whether all runs are in s, in the state checked by
+// inState.
+func allRuns(runs []Run, s *Snapshot, inState func(runState) bool) bool {
+	for _, r := range runs {
+		if state, ok := stateOf(r, s); !ok || !inState(state) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/scheme"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// fakeHarness returns a Harness whose runs start as soon as they are
+// created, and which upgrades the components with upgrade.
+func fakeHarness(t *testing.T, upgrade func(c *fake.Clientset) error) *Harness {
+	// Each reactor gets a copy of the action, so the runs are started and
+	// stored by the same one, in a tracker of its own.
+	tracker := ktesting.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder())
+	track := ktesting.ObjectReaction(tracker)
+	c := fake.NewSimpleClientset()
+	c.PrependReactor("*", "*", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetVerb() != "create" {
+			return track(action)
+		}
+		now := metav1.NewTime(startTime)
+		switch run := action.(ktesting.CreateAction).GetObject().(type) {
+		case *v1alpha1.TaskRun:
+			run.Status.StartTime = &now
+			run.Status.PodName = run.Name + "-pod"
+			run.Status.SetCondition(&running)
+		case *v1alpha1.PipelineRun:
+			run.Status.StartTime = &now
+			run.Status.TaskRuns = map[string]*v1alpha1.PipelineRunTaskRunStatus{run.Name + "-build": {PipelineTaskName: "build"}}
+			run.Status.SetCondition(&running)
+		}
+		return track(action)
+	})
+	return &Harness{
+		Client:    c,
+		Namespace: "foo",
+		Upgrade:   func() error { return upgrade(c) },
+		Interval:  time.Millisecond,
+		Timeout:   time.Second,
+		Logf:      t.Logf,
+	}
+}
+
+// complete completes the TaskRun and the PipelineRun of the test, or only
+// the TaskRun if the PipelineRun is stuck, or not part of the test.
+func complete(c *fake.Clientset, stuck bool, trOps ...tb.TaskRunStatusOp) error {
+	tr, err := c.TektonV1alpha1().TaskRuns("foo").Get("build", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	tr.Status.SetCondition(&succeeded)
+	for _, op := range trOps {
+		op(&tr.Status)
+	}
+	if _, err := c.TektonV1alpha1().TaskRuns("foo").UpdateStatus(tr); err != nil {
+		return err
+	}
+	if stuck {
+		return nil
+	}
+	pr, err := c.TektonV1alpha1().PipelineRuns("foo").Get("release", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	pr.Status.SetCondition(&failed)
+	_, err = c.TektonV1alpha1().PipelineRuns("foo").UpdateStatus(pr)
+	return err
+}
+
+func runs() []Run {
+	return []Run{{
+		TaskRun:       tb.TaskRun("build", "foo", tb.TaskRunSpec(tb.TaskRunTaskRef("build-task"))),
+		WantSucceeded: corev1.ConditionTrue,
+	}, {
+		PipelineRun:   tb.PipelineRun("release", "foo", tb.PipelineRunSpec("release-pipeline")),
+		WantSucceeded: corev1.ConditionFalse,
+		WantReason:    "Failed",
+	}}
+}
+
+func TestHarness(t *testing.T) {
+	h := fakeHarness(t, func(c *fake.Clientset) error { return complete(c, false) })
+	if err := h.Test(runs()...); err != nil {
+		t.Errorf("Test() = %v", err)
+	}
+}
+
+func TestHarnessBrokenRuns(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		upgrade func(c *fake.Clientset) error
+		runs    []Run
+		want    string
+	}{{
+		name:    "pod replaced",
+		upgrade: func(c *fake.Clientset) error { return complete(c, false, tb.PodName("build-pod-2")) },
+		runs:    runs(),
+		want:    "TaskRun build pod changed",
+	}, {
+		name:    "unexpected outcome",
+		upgrade: func(c *fake.Clientset) error { return complete(c, true) },
+		runs: []Run{{
+			TaskRun:       tb.TaskRun("build", "foo", tb.TaskRunSpec(tb.TaskRunTaskRef("build-task"))),
+			WantSucceeded: corev1.ConditionFalse,
+		}},
+		want: "TaskRun build completed with True (Succeeded), want False",
+	}, {
+		name:    "stuck",
+		upgrade: func(c *fake.Clientset) error { return complete(c, true) },
+		runs:    runs(),
+		want:    "runs didn't complete after the upgrade",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := fakeHarness(t, tc.upgrade)
+			err := h.Test(tc.runs...)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Test() = %v, want an error containing %q", err, tc.want)
+			}
+		})
+	}
+}
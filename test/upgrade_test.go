@@ -0,0 +1,92 @@
+// +build e2e
+
+/*
+Copyright 2019 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"testing"
+
+	tb "github.com/tektoncd/pipeline/test/builder"
+	"github.com/tektoncd/pipeline/test/upgrade"
+	corev1 "k8s.io/api/core/v1"
+	knativetest "knative.dev/pkg/test"
+)
+
+var upgradeCommand = flag.String("upgrade-command", "",
+	"If set, the shell command upgrading the installed Tekton Pipelines components, run by TestUpgradeRunsInFlight.")
+
+// TestUpgradeRunsInFlight creates runs under the installed release, upgrades
+// the components with -upgrade-command while they run, and checks that the
+// runs complete as they would have without the upgrade.
+func TestUpgradeRunsInFlight(t *testing.T) {
+	if *upgradeCommand == "" {
+		t.Skip("-upgrade-command isn't set")
+	}
+	c, namespace := setup(t)
+	knativetest.CleanupOnInterrupt(func() { tearDown(t, c, namespace) }, t.Logf)
+	defer tearDown(t, c, namespace)
+
+	t.Logf("Creating Tasks and Pipeline in namespace %s", namespace)
+	sleep := tb.Task("sleep", namespace, tb.TaskSpec(
+		tb.Step("sleep", "ubuntu", tb.StepCommand("/bin/bash"), tb.StepArgs("-c", "sleep 60")),
+		tb.Step("echo", "ubuntu", tb.StepCommand("/bin/bash"), tb.StepArgs("-c", "echo done")),
+	))
+	if _, err := c.TaskClient.Create(sleep); err != nil {
+		t.Fatalf("Failed to create Task `sleep`: %s", err)
+	}
+	fail := tb.Task("fail", namespace, tb.TaskSpec(
+		tb.Step("fail", "ubuntu", tb.StepCommand("/bin/bash"), tb.StepArgs("-c", "exit 1")),
+	))
+	if _, err := c.TaskClient.Create(fail); err != nil {
+		t.Fatalf("Failed to create Task `fail`: %s", err)
+	}
+	pipeline := tb.Pipeline("sleep-then-fail", namespace, tb.PipelineSpec(
+		tb.PipelineTask("sleep", "sleep"),
+		tb.PipelineTask("fail", "fail", tb.RunAfter("sleep")),
+	))
+	if _, err := c.PipelineClient.Create(pipeline); err != nil {
+		t.Fatalf("Failed to create Pipeline `sleep-then-fail`: %s", err)
+	}
+
+	h := &upgrade.Harness{
+		Client:    c.PipelineClientset,
+		Namespace: namespace,
+		Upgrade: func() error {
+			t.Logf("Running %s", *upgradeCommand)
+			cmd := exec.Command("/bin/bash", "-c", *upgradeCommand)
+			cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+			return cmd.Run()
+		},
+		Interval: interval,
+		Timeout:  timeout,
+		Logf:     t.Logf,
+	}
+	if err := h.Test(upgrade.Run{
+		TaskRun:       tb.TaskRun("sleep-run", namespace, tb.TaskRunSpec(tb.TaskRunTaskRef("sleep"))),
+		WantSucceeded: corev1.ConditionTrue,
+	}, upgrade.Run{
+		PipelineRun:   tb.PipelineRun("sleep-then-fail-run", namespace, tb.PipelineRunSpec("sleep-then-fail")),
+		WantSucceeded: corev1.ConditionFalse,
+		WantReason:    "Failed",
+	}); err != nil {
+		t.Error(err)
+	}
+}